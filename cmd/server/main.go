@@ -0,0 +1,46 @@
+// Command server serves a market to external clients: the WebSocket stream
+// and order entry at /ws, and the REST control API beside it.
+//
+//	server -addr :8080 -tickers AAPL,MSFT,TSLA
+//
+// The books start empty; clients trade with each other.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/market/rest"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/server"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "listen address")
+	tickerList := flag.String("tickers", "AAPL,GOOGL,MSFT,AMZN,TSLA", "comma-separated ticker names, numbered from 1")
+	decimals := flag.Int("decimals", 2, "price decimals of every ticker")
+	buffer := flag.Int("buffer", server.DefaultConfig().Buffer, "per-connection outbound queue, in messages")
+	disconnectSlow := flag.Bool("disconnect-slow", false, "close connections that fall a full queue behind instead of dropping their lines")
+	flag.Parse()
+
+	var tickers []market.Ticker
+	for i, name := range strings.Split(*tickerList, ",") {
+		tickers = append(tickers, market.Ticker{ID: int64(i + 1), Name: strings.TrimSpace(name), Decimals: int8(*decimals)})
+	}
+
+	m := marketservice.NewMarketService(tickers, marketservice.DefaultConfig())
+	defer m.Close()
+
+	cfg := server.DefaultConfig()
+	cfg.Buffer = *buffer
+	cfg.DropSlowConsumers = !*disconnectSlow
+
+	mux := http.NewServeMux()
+	mux.Handle("/ws", server.NewServer(m, cfg))
+	mux.Handle("/", rest.NewHandler(m))
+	log.Printf("serving %d tickers on %s", len(tickers), *addr)
+	log.Fatal(http.ListenAndServe(*addr, mux))
+}
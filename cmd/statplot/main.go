@@ -0,0 +1,139 @@
+// Command statplot prints per-ticker min/max/mean summaries of the CSV files
+// written by the stats recorder.
+//
+//	statplot run.csv run.csv.1
+//	statplot run-AAPL.csv run-MSFT.csv
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+type summary struct {
+	min, max, sum float64
+	n             int
+}
+
+func (s *summary) add(v float64) {
+	if s.n == 0 || v < s.min {
+		s.min = v
+	}
+	if s.n == 0 || v > s.max {
+		s.max = v
+	}
+	s.sum += v
+	s.n++
+}
+
+// series is keyed by ticker, then column.
+type series map[string]map[string]*summary
+
+func (s series) add(ticker, column string, v float64) {
+	cols, ok := s[ticker]
+	if !ok {
+		cols = map[string]*summary{}
+		s[ticker] = cols
+	}
+	sum, ok := cols[column]
+	if !ok {
+		sum = &summary{}
+		cols[column] = sum
+	}
+	sum.add(v)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: statplot FILE.csv...")
+		os.Exit(2)
+	}
+
+	all := series{}
+	for _, name := range os.Args[1:] {
+		if err := readFile(name, all); err != nil {
+			fmt.Fprintf(os.Stderr, "statplot: %s: %v\n", name, err)
+			os.Exit(1)
+		}
+	}
+	report(os.Stdout, all)
+}
+
+// readFile adds every numeric cell of a wide or per-ticker file to s. Wide
+// files name columns TICKER.field; per-ticker files carry a ticker column.
+// Columns without a ticker are reported under "market".
+func readFile(name string, s series) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	header, err := r.Read()
+	if err != nil {
+		return err
+	}
+	tickerCol := -1
+	for i, h := range header {
+		if h == "ticker" {
+			tickerCol = i
+		}
+	}
+
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for i, cell := range record {
+			if i == 0 || i == tickerCol || cell == "" {
+				continue
+			}
+			v, err := strconv.ParseFloat(cell, 64)
+			if err != nil {
+				continue
+			}
+			ticker, column := "market", header[i]
+			if tickerCol >= 0 && column != "market_queue" {
+				ticker = record[tickerCol]
+			} else if dot := strings.IndexByte(column, '.'); dot >= 0 {
+				ticker, column = column[:dot], column[dot+1:]
+			}
+			s.add(ticker, column, v)
+		}
+	}
+}
+
+func report(w io.Writer, s series) {
+	tickers := make([]string, 0, len(s))
+	for t := range s {
+		tickers = append(tickers, t)
+	}
+	sort.Strings(tickers)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "ticker\tcolumn\tmin\tmax\tmean\tn\t")
+	for _, t := range tickers {
+		cols := make([]string, 0, len(s[t]))
+		for c := range s[t] {
+			cols = append(cols, c)
+		}
+		sort.Strings(cols)
+		for _, c := range cols {
+			sum := s[t][c]
+			fmt.Fprintf(tw, "%s\t%s\t%.2f\t%.2f\t%.2f\t%d\t\n",
+				t, c, sum.min, sum.max, sum.sum/float64(sum.n), sum.n)
+		}
+	}
+	tw.Flush()
+}
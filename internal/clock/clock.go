@@ -0,0 +1,196 @@
+// Package clock abstracts wall time so services can be driven by a manual
+// clock in tests.
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock provides the current time, periodic tickers and one-shot timers.
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+	NewTimer(d time.Duration) Timer
+	// After is NewTimer(d).C(), for waits that are never stopped.
+	After(d time.Duration) <-chan time.Time
+}
+
+// Ticker delivers ticks on C, like time.Ticker. Ticks are dropped if the
+// receiver falls behind.
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// Timer delivers a single tick on C once its duration passes, like
+// time.Timer. Stop reports whether it stopped the timer before it fired.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// Real returns a Clock backed by the time package.
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+type realTicker struct {
+	t *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time { return t.t.C }
+func (t realTicker) Stop()               { t.t.Stop() }
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time { return t.t.C }
+func (t realTimer) Stop() bool          { return t.t.Stop() }
+
+// Manual is a Clock that only moves when Advance is called.
+// It is safe for concurrent use.
+type Manual struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*manualTicker
+	timers  []*manualTimer
+}
+
+// NewManual creates a Manual clock starting at start.
+func NewManual(start time.Time) *Manual {
+	return &Manual{now: start}
+}
+
+// Now returns the manual clock's current time.
+func (m *Manual) Now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.now
+}
+
+// NewTicker creates a ticker that fires each time the clock is advanced past
+// a multiple of d from now.
+func (m *Manual) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("clock: non-positive ticker interval")
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := &manualTicker{
+		clock: m,
+		ch:    make(chan time.Time, 1),
+		d:     d,
+		next:  m.now.Add(d),
+	}
+	m.tickers = append(m.tickers, t)
+	return t
+}
+
+// NewTimer creates a timer that fires once the clock is advanced d past now.
+// A non-positive d fires at once.
+func (m *Manual) NewTimer(d time.Duration) Timer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t := &manualTimer{
+		clock: m,
+		ch:    make(chan time.Time, 1),
+		at:    m.now.Add(d),
+	}
+	if d <= 0 {
+		t.ch <- m.now
+		return t
+	}
+	m.timers = append(m.timers, t)
+	return t
+}
+
+// After is NewTimer(d).C().
+func (m *Manual) After(d time.Duration) <-chan time.Time {
+	return m.NewTimer(d).C()
+}
+
+// Advance moves the clock forward by d, firing any tickers and timers that
+// come due. Like time.Ticker, a ticker that is not drained keeps only one
+// pending tick.
+func (m *Manual) Advance(d time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.now = m.now.Add(d)
+	for _, t := range m.tickers {
+		for !t.next.After(m.now) {
+			select {
+			case t.ch <- t.next:
+			default:
+			}
+			t.next = t.next.Add(t.d)
+		}
+	}
+	pending := m.timers[:0]
+	for _, t := range m.timers {
+		if t.at.After(m.now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.ch <- t.at
+	}
+	clear(m.timers[len(pending):])
+	m.timers = pending
+}
+
+type manualTicker struct {
+	clock *Manual
+	ch    chan time.Time
+	d     time.Duration
+	next  time.Time
+}
+
+func (t *manualTicker) C() <-chan time.Time { return t.ch }
+
+func (t *manualTicker) Stop() {
+	m := t.clock
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, other := range m.tickers {
+		if other == t {
+			m.tickers = append(m.tickers[:i], m.tickers[i+1:]...)
+			break
+		}
+	}
+}
+
+type manualTimer struct {
+	clock *Manual
+	ch    chan time.Time
+	at    time.Time
+}
+
+func (t *manualTimer) C() <-chan time.Time { return t.ch }
+
+func (t *manualTimer) Stop() bool {
+	m := t.clock
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, other := range m.timers {
+		if other == t {
+			m.timers = append(m.timers[:i], m.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,190 @@
+// Package displayid maps raw OrderIDs to short, human-readable IDs for the UI.
+//
+// OrderIDs are nanosecond-derived and unreadable in status lines and entry
+// fields. An Allocator hands out per-session IDs like "A-1042" and resolves
+// either form back to the real order. Mappings for closed orders are evicted
+// after a retention window so the table stays bounded.
+package displayid
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+var ErrUnknownID = errors.New("unknown or expired order id")
+
+// Config holds configuration for an Allocator.
+type Config struct {
+	// Prefix starts every display ID, e.g. "A" gives "A-1".
+	Prefix string
+	// Retention is how long a closed order stays resolvable.
+	Retention time.Duration
+	// Clock timestamps closes for eviction.
+	Clock clock.Clock
+}
+
+// DefaultConfig returns a Config with reasonable defaults.
+func DefaultConfig() Config {
+	return Config{
+		Prefix:    "A",
+		Retention: 10 * time.Minute,
+		Clock:     clock.Real(),
+	}
+}
+
+// Entry is a resolved display ID.
+type Entry struct {
+	Display string
+	OrderID core.OrderID
+	Ticker  market.TickerID
+}
+
+type entry struct {
+	Entry
+	closed   bool
+	closedAt time.Time
+}
+
+// Allocator assigns display IDs. It is safe for concurrent use.
+type Allocator struct {
+	mu        sync.Mutex
+	cfg       Config
+	next      int64
+	byOrder   map[core.OrderID]*entry
+	byDisplay map[string]*entry
+	closed    []closeMark // close order, oldest first
+}
+
+// closeMark records one close; it is stale if the entry was reopened since.
+type closeMark struct {
+	e  *entry
+	at time.Time
+}
+
+// NewAllocator creates a new Allocator.
+func NewAllocator(cfg Config) *Allocator {
+	def := DefaultConfig()
+	if cfg.Prefix == "" {
+		cfg.Prefix = def.Prefix
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = def.Retention
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = def.Clock
+	}
+	return &Allocator{
+		cfg:       cfg,
+		byOrder:   make(map[core.OrderID]*entry),
+		byDisplay: make(map[string]*entry),
+	}
+}
+
+// Assign returns the display ID for an order, allocating one on first use.
+// Assigning a closed order reopens it (e.g. an amend that re-rests it).
+// Display IDs are never reused within a session.
+func (a *Allocator) Assign(id core.OrderID, tid market.TickerID) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.evictLocked()
+
+	if e, ok := a.byOrder[id]; ok {
+		e.closed = false
+		return e.Display
+	}
+	a.next++
+	e := &entry{Entry: Entry{
+		Display: fmt.Sprintf("%s-%d", a.cfg.Prefix, a.next),
+		OrderID: id,
+		Ticker:  tid,
+	}}
+	a.byOrder[id] = e
+	a.byDisplay[e.Display] = e
+	return e.Display
+}
+
+// MarkClosed starts the retention window for a filled or canceled order.
+func (a *Allocator) MarkClosed(id core.OrderID) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	e, ok := a.byOrder[id]
+	if !ok || e.closed {
+		return
+	}
+	e.closed = true
+	e.closedAt = a.cfg.Clock.Now()
+	a.closed = append(a.closed, closeMark{e: e, at: e.closedAt})
+	a.evictLocked()
+}
+
+// Display returns the short form of id, or the raw number if it has none.
+func (a *Allocator) Display(id core.OrderID) string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if e, ok := a.byOrder[id]; ok {
+		return e.Display
+	}
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// Lookup resolves a display ID (case-insensitive) or a raw OrderID that has
+// a mapping.
+func (a *Allocator) Lookup(s string) (Entry, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.evictLocked()
+
+	s = strings.TrimSpace(s)
+	if e, ok := a.byDisplay[strings.ToUpper(s)]; ok {
+		return e.Entry, nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if e, ok := a.byOrder[core.OrderID(n)]; ok {
+			return e.Entry, nil
+		}
+	}
+	return Entry{}, fmt.Errorf("%w: %q", ErrUnknownID, s)
+}
+
+// ResolveDisplayID returns the OrderID for either form. Raw numeric IDs are
+// accepted even without a mapping; short IDs must be known.
+func (a *Allocator) ResolveDisplayID(s string) (core.OrderID, error) {
+	if e, err := a.Lookup(s); err == nil {
+		return e.OrderID, nil
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%w: %q", ErrUnknownID, s)
+	}
+	return core.OrderID(n), nil
+}
+
+// Len returns the number of live mappings.
+func (a *Allocator) Len() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.byOrder)
+}
+
+func (a *Allocator) evictLocked() {
+	cutoff := a.cfg.Clock.Now().Add(-a.cfg.Retention)
+	for len(a.closed) > 0 {
+		m := a.closed[0]
+		if m.at.After(cutoff) {
+			break
+		}
+		a.closed = a.closed[1:]
+		if m.e.closed && m.e.closedAt.Equal(m.at) {
+			delete(a.byOrder, m.e.OrderID)
+			delete(a.byDisplay, m.e.Display)
+		}
+	}
+}
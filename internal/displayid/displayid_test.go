@@ -0,0 +1,97 @@
+package displayid
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestAssignCollisionFree(t *testing.T) {
+	a := NewAllocator(DefaultConfig())
+
+	seen := map[string]core.OrderID{}
+	for i := 1; i <= 1000; i++ {
+		id := core.OrderID(1718822345123456789 + int64(i))
+		d := a.Assign(id, 1)
+		if prev, ok := seen[d]; ok {
+			t.Fatalf("display ID %s assigned to both %d and %d", d, prev, id)
+		}
+		seen[d] = id
+		if again := a.Assign(id, 1); again != d {
+			t.Errorf("expected Assign to be idempotent, got %s then %s", d, again)
+		}
+	}
+	if got := a.Display(1718822345123456790); got != "A-1" {
+		t.Errorf("expected A-1, got %s", got)
+	}
+}
+
+func TestLookupBothForms(t *testing.T) {
+	a := NewAllocator(DefaultConfig())
+	d := a.Assign(1718822345123456789, 3)
+
+	for _, s := range []string{d, "a-1", " A-1 ", "1718822345123456789"} {
+		e, err := a.Lookup(s)
+		if err != nil {
+			t.Fatalf("Lookup(%q): unexpected error: %v", s, err)
+		}
+		if e.OrderID != 1718822345123456789 || e.Ticker != 3 {
+			t.Errorf("Lookup(%q): unexpected entry %+v", s, e)
+		}
+	}
+
+	if id, err := a.ResolveDisplayID("42"); err != nil || id != 42 {
+		t.Errorf("expected unmapped raw ID to pass through, got %d, %v", id, err)
+	}
+	if _, err := a.ResolveDisplayID("A-99"); !errors.Is(err, ErrUnknownID) {
+		t.Errorf("expected ErrUnknownID for unallocated short ID, got %v", err)
+	}
+}
+
+func TestEvictionAfterRetention(t *testing.T) {
+	clk := clock.NewManual(time.Unix(1000, 0))
+	a := NewAllocator(Config{Retention: time.Minute, Clock: clk})
+
+	closed := a.Assign(1, 1)
+	open := a.Assign(2, 1)
+	a.MarkClosed(1)
+
+	clk.Advance(30 * time.Second)
+	if _, err := a.Lookup(closed); err != nil {
+		t.Errorf("expected closed order to resolve within retention: %v", err)
+	}
+
+	clk.Advance(31 * time.Second)
+	if _, err := a.Lookup(closed); !errors.Is(err, ErrUnknownID) {
+		t.Errorf("expected evicted ID to fail with ErrUnknownID, got %v", err)
+	}
+	if _, err := a.Lookup(open); err != nil {
+		t.Errorf("expected open order to survive eviction: %v", err)
+	}
+	if a.Len() != 1 {
+		t.Errorf("expected 1 live mapping, got %d", a.Len())
+	}
+	if got := a.Display(1); got != "1" {
+		t.Errorf("expected evicted order to display raw ID, got %s", got)
+	}
+	if next := a.Assign(3, 1); next != "A-3" {
+		t.Errorf("expected display IDs not to be reused after eviction, got %s", next)
+	}
+}
+
+func TestReopenedOrderIsNotEvicted(t *testing.T) {
+	clk := clock.NewManual(time.Unix(1000, 0))
+	a := NewAllocator(Config{Retention: time.Minute, Clock: clk})
+
+	d := a.Assign(1, 1)
+	a.MarkClosed(1)
+	a.Assign(1, 1)
+
+	clk.Advance(2 * time.Minute)
+	if _, err := a.Lookup(d); err != nil {
+		t.Errorf("expected reopened order to stay resolvable: %v", err)
+	}
+}
@@ -0,0 +1,109 @@
+// Package fee computes trading fees from a basis-point schedule.
+//
+// Fees are charged on trade notional (price × size, in ticks). Every user pays
+// the base schedule unless they have been assigned a tier with its own maker
+// and taker rates.
+package fee
+
+import (
+	"sync"
+
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// Schedule is a pair of fee rates in basis points (1 bps = 0.01%).
+// A negative rate is a rebate.
+type Schedule struct {
+	MakerBps int64
+	TakerBps int64
+}
+
+// Config holds configuration for a Model.
+type Config struct {
+	// Base is the schedule for users without a tier.
+	Base Schedule
+	// Tiers maps tier names to schedules.
+	Tiers map[string]Schedule
+}
+
+// DefaultConfig returns a Config with reasonable defaults.
+func DefaultConfig() Config {
+	return Config{
+		Base: Schedule{MakerBps: 10, TakerBps: 20},
+	}
+}
+
+// Model looks up per-user fee schedules. It is safe for concurrent use.
+type Model struct {
+	mu    sync.RWMutex
+	base  Schedule
+	tiers map[string]Schedule
+	users map[core.UserID]string
+}
+
+// NewModel creates a fee model.
+func NewModel(cfg Config) *Model {
+	tiers := make(map[string]Schedule, len(cfg.Tiers))
+	for name, s := range cfg.Tiers {
+		tiers[name] = s
+	}
+	return &Model{
+		base:  cfg.Base,
+		tiers: tiers,
+		users: make(map[core.UserID]string),
+	}
+}
+
+// SetUserTier assigns a user to a tier. An empty or unknown tier name puts the
+// user back on the base schedule.
+func (m *Model) SetUserTier(userID core.UserID, tier string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tiers[tier]; !ok {
+		delete(m.users, userID)
+		return
+	}
+	m.users[userID] = tier
+}
+
+// ScheduleFor returns the schedule a user pays.
+func (m *Model) ScheduleFor(userID core.UserID) Schedule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if tier, ok := m.users[userID]; ok {
+		return m.tiers[tier]
+	}
+	return m.base
+}
+
+// Fee returns the fee in ticks a user pays on a fill, rounded toward zero.
+// It returns money.ErrOverflow if price × size × rate does not fit in an
+// int64.
+func (m *Model) Fee(userID core.UserID, maker bool, price core.PriceTicks, size core.Size) (int64, error) {
+	s := m.ScheduleFor(userID)
+	bps := s.TakerBps
+	if maker {
+		bps = s.MakerBps
+	}
+	notional, err := money.Notional(int64(price), int64(size))
+	if err != nil {
+		return 0, err
+	}
+	scaled, ok := money.MulChecked(notional, bps)
+	if !ok {
+		return 0, money.ErrOverflow
+	}
+	return scaled / 10000, nil
+}
+
+// TradeFees returns the maker's and taker's fees for a trade.
+func (m *Model) TradeFees(tr core.TradeEvent) (maker, taker int64, err error) {
+	if maker, err = m.Fee(tr.MakerUserID, true, tr.Price, tr.Size); err != nil {
+		return 0, 0, err
+	}
+	if taker, err = m.Fee(tr.TakerUserID, false, tr.Price, tr.Size); err != nil {
+		return 0, 0, err
+	}
+	return maker, taker, nil
+}
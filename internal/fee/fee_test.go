@@ -0,0 +1,70 @@
+package fee
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestDiscountedTierPaysLess(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tiers = map[string]Schedule{
+		"vip": {MakerBps: 0, TakerBps: 5},
+	}
+	m := NewModel(cfg)
+	m.SetUserTier(2, "vip")
+
+	// Identical fills: user 1 on the base schedule, user 2 on the vip tier
+	base := core.TradeEvent{Price: 10000, Size: 50, MakerUserID: 1, TakerUserID: 1}
+	vip := core.TradeEvent{Price: 10000, Size: 50, MakerUserID: 2, TakerUserID: 2}
+
+	baseMaker, baseTaker, err := m.TradeFees(base)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vipMaker, vipTaker, err := m.TradeFees(vip)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if baseMaker != 500 || baseTaker != 1000 {
+		t.Errorf("expected base fees 500/1000, got %d/%d", baseMaker, baseTaker)
+	}
+	if vipMaker >= baseMaker || vipTaker >= baseTaker {
+		t.Errorf("expected vip fees below base, got maker %d vs %d, taker %d vs %d",
+			vipMaker, baseMaker, vipTaker, baseTaker)
+	}
+	if vipTaker != 250 {
+		t.Errorf("expected vip taker fee 250, got %d", vipTaker)
+	}
+}
+
+func TestUnknownTierFallsBackToBase(t *testing.T) {
+	m := NewModel(DefaultConfig())
+	m.SetUserTier(1, "missing")
+	if got := m.ScheduleFor(1); got != DefaultConfig().Base {
+		t.Errorf("expected base schedule, got %+v", got)
+	}
+}
+
+func TestFeeOverflowIsAnError(t *testing.T) {
+	m := NewModel(DefaultConfig())
+
+	// The notional fits, but times the 20 bps taker rate it does not
+	price := core.PriceTicks(math.MaxInt64 / 10)
+	if _, err := m.Fee(1, false, price, 1); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("expected ErrOverflow on the rate, got %v", err)
+	}
+	// The notional itself overflows
+	if _, _, err := m.TradeFees(core.TradeEvent{Price: price, Size: 11}); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("expected ErrOverflow on the notional, got %v", err)
+	}
+	// The largest notional that still fits at 20 bps
+	n := int64(math.MaxInt64 / 20)
+	if fee, err := m.Fee(1, false, core.PriceTicks(n), 1); err != nil || fee != n*20/10000 {
+		t.Errorf("expected %d, got %d, %v", n*20/10000, fee, err)
+	}
+}
@@ -0,0 +1,114 @@
+package grpc
+
+import (
+	"github.com/zappabad/stockcraft/internal/feed/grpc/marketpb"
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// sideFromPB converts a wire side. SIDE_UNSPECIFIED maps to a side the order
+// package rejects, so the client gets INVALID_SIDE.
+func sideFromPB(s marketpb.Side) core.Side {
+	switch s {
+	case marketpb.Side_SIDE_BUY:
+		return core.SideBuy
+	case marketpb.Side_SIDE_SELL:
+		return core.SideSell
+	}
+	return core.Side(255)
+}
+
+func sideToPB(s core.Side) marketpb.Side {
+	switch s {
+	case core.SideBuy:
+		return marketpb.Side_SIDE_BUY
+	case core.SideSell:
+		return marketpb.Side_SIDE_SELL
+	}
+	return marketpb.Side_SIDE_UNSPECIFIED
+}
+
+func submitReportToPB(r core.SubmitReport) *marketpb.SubmitReport {
+	pb := &marketpb.SubmitReport{
+		OrderId:          int64(r.OrderID),
+		Remaining:        int64(r.Remaining),
+		RestedSize:       int64(r.RestedSize),
+		Rested:           r.Rested,
+		StpTakerCanceled: r.STPTakerCanceled,
+	}
+	for _, f := range r.Fills {
+		pb.Fills = append(pb.Fills, &marketpb.Fill{
+			MakerOrderId: int64(f.MakerOrderID),
+			Price:        int64(f.Price),
+			Size:         int64(f.Size),
+		})
+	}
+	for _, id := range r.STPCanceled {
+		pb.StpCanceled = append(pb.StpCanceled, int64(id))
+	}
+	return pb
+}
+
+// eventToPB converts a book event, or returns nil for events the API does
+// not carry (cross resolutions, which follow their trades, and iceberg
+// refreshes, which the API has no message for yet).
+func eventToPB(t market.Ticker, ev core.Event) *marketpb.Event {
+	pb := &marketpb.Event{TickerId: int64(t.TickerID()), Ticker: t.Name}
+	switch e := ev.(type) {
+	case core.TradeEvent:
+		pb.Event = &marketpb.Event_Trade{Trade: &marketpb.Trade{
+			Price:        int64(e.Price),
+			Size:         int64(e.Size),
+			TakerSide:    sideToPB(e.TakerSide),
+			Time:         e.Time,
+			TakerOrderId: int64(e.TakerOrderID),
+			TakerUserId:  int64(e.TakerUserID),
+			MakerOrderId: int64(e.MakerOrderID),
+			MakerUserId:  int64(e.MakerUserID),
+		}}
+	case core.OrderRestedEvent:
+		pb.Event = &marketpb.Event_Rested{Rested: &marketpb.OrderRested{
+			OrderId: int64(e.OrderID),
+			UserId:  int64(e.UserID),
+			Side:    sideToPB(e.Side),
+			Price:   int64(e.Price),
+			Size:    int64(e.Size),
+			Time:    e.Time,
+		}}
+	case core.OrderReducedEvent:
+		pb.Event = &marketpb.Event_Reduced{Reduced: &marketpb.OrderReduced{
+			OrderId:   int64(e.OrderID),
+			Delta:     int64(e.Delta),
+			Remaining: int64(e.Remaining),
+			Price:     int64(e.Price),
+			Side:      sideToPB(e.Side),
+			UserId:    int64(e.UserID),
+			Time:      e.MatchTime,
+		}}
+	case core.OrderRemovedEvent:
+		pb.Event = &marketpb.Event_Removed{Removed: &marketpb.OrderRemoved{
+			OrderId:   int64(e.OrderID),
+			Reason:    removeReasonToPB(e.Reason),
+			Remaining: int64(e.Remaining),
+			Price:     int64(e.Price),
+			Side:      sideToPB(e.Side),
+			UserId:    int64(e.UserID),
+			Time:      e.Time,
+		}}
+	default:
+		return nil
+	}
+	return pb
+}
+
+func removeReasonToPB(r core.RemoveReason) marketpb.RemoveReason {
+	switch r {
+	case core.RemoveReasonCanceled:
+		return marketpb.RemoveReason_REMOVE_REASON_CANCELED
+	case core.RemoveReasonAmended:
+		return marketpb.RemoveReason_REMOVE_REASON_AMENDED
+	case core.RemoveReasonSelfTrade:
+		return marketpb.RemoveReason_REMOVE_REASON_SELF_TRADE
+	}
+	return marketpb.RemoveReason_REMOVE_REASON_FILLED
+}
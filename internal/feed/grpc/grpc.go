@@ -0,0 +1,209 @@
+// Package grpc serves the market over gRPC for clients outside Go, with the
+// messages and stubs in marketpb (see marketpb/market.proto):
+//
+//	SubmitLimit, SubmitMarket, Cancel   unary order entry
+//	Events                              server stream of book events
+//
+// Tickers are given by name or ID and prices in integer ticks. Orders are
+// validated by the order package like every other entry point; rejections
+// carry the order.Error text under a gRPC status code.
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/zappabad/stockcraft/internal/feed/grpc/marketpb"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/order"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/pubsub"
+)
+
+// EventBuffer is each Events stream's per-ticker buffer. A client that falls
+// further behind misses events rather than stalling the books.
+const EventBuffer = 1024
+
+// Market is the surface the server drives. MarketService implements it.
+type Market interface {
+	order.Sender
+	Cancel(ctx context.Context, tid market.TickerID, orderID core.OrderID) (core.CancelReport, error)
+	Registry() *market.Registry
+	SubscribeBook(tid market.TickerID, policy pubsub.Policy, buffer int) (*pubsub.Subscription[core.Event], error)
+}
+
+// Server implements marketpb.MarketServer.
+type Server struct {
+	marketpb.UnimplementedMarketServer
+	m Market
+}
+
+// NewServer creates a Server for a market.
+func NewServer(m Market) *Server {
+	return &Server{m: m}
+}
+
+// Register adds the server to a gRPC server.
+func (s *Server) Register(g *grpc.Server) {
+	marketpb.RegisterMarketServer(g, s)
+}
+
+// SubmitLimit implements marketpb.MarketServer.
+func (s *Server) SubmitLimit(ctx context.Context, in *marketpb.SubmitLimitRequest) (*marketpb.SubmitReport, error) {
+	t, err := s.m.Registry().Resolve(in.GetTicker())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	req := order.Request{
+		TickerID: t.TickerID(),
+		UserID:   core.UserID(in.GetUserId()),
+		Kind:     core.OrderKindLimit,
+		Side:     sideFromPB(in.GetSide()),
+		Price:    core.PriceTicks(in.GetPrice()),
+		Size:     core.Size(in.GetSize()),
+	}
+	switch in.GetTif() {
+	case marketpb.TimeInForce_TIME_IN_FORCE_GTC:
+		req.TIF = order.TIFGTC
+	case marketpb.TimeInForce_TIME_IN_FORCE_IOC:
+		req.TIF = order.TIFIOC
+	case marketpb.TimeInForce_TIME_IN_FORCE_FOK:
+		req.TIF = order.TIFFOK
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "%s: unknown time in force %d", order.CodeInvalidTIF, in.GetTif())
+	}
+	report, err := order.Submit(ctx, s.m, t, req)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	return submitReportToPB(report), nil
+}
+
+// SubmitMarket implements marketpb.MarketServer.
+func (s *Server) SubmitMarket(ctx context.Context, in *marketpb.SubmitMarketRequest) (*marketpb.SubmitReport, error) {
+	t, err := s.m.Registry().Resolve(in.GetTicker())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	req := order.Request{
+		TickerID: t.TickerID(),
+		UserID:   core.UserID(in.GetUserId()),
+		Kind:     core.OrderKindMarket,
+		Side:     sideFromPB(in.GetSide()),
+		Size:     core.Size(in.GetSize()),
+	}
+	report, err := order.Submit(ctx, s.m, t, req)
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	return submitReportToPB(report), nil
+}
+
+// Cancel implements marketpb.MarketServer.
+func (s *Server) Cancel(ctx context.Context, in *marketpb.CancelRequest) (*marketpb.CancelReport, error) {
+	if in.GetOrderId() <= 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "order ID must be positive, got %d", in.GetOrderId())
+	}
+	t, err := s.m.Registry().Resolve(in.GetTicker())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	report, err := s.m.Cancel(ctx, t.TickerID(), core.OrderID(in.GetOrderId()))
+	if err != nil {
+		return nil, statusOf(err)
+	}
+	return &marketpb.CancelReport{OrderId: int64(report.OrderID), CanceledSize: int64(report.CanceledSize)}, nil
+}
+
+// Events implements marketpb.MarketServer. It subscribes to every requested
+// book before sending the response headers, so a client that has read them
+// sees every later event.
+func (s *Server) Events(in *marketpb.EventsRequest, stream grpc.ServerStreamingServer[marketpb.Event]) error {
+	reg := s.m.Registry()
+	tickers := reg.Tickers()
+	if len(in.GetTickers()) > 0 {
+		tickers = nil
+		for _, ref := range in.GetTickers() {
+			t, err := reg.Resolve(ref)
+			if err != nil {
+				return status.Error(codes.NotFound, err.Error())
+			}
+			tickers = append(tickers, t)
+		}
+	}
+
+	ctx := stream.Context()
+	merged := make(chan *marketpb.Event)
+	for _, t := range tickers {
+		sub, err := s.m.SubscribeBook(t.TickerID(), pubsub.Drop, EventBuffer)
+		if err != nil {
+			return statusOf(err)
+		}
+		defer sub.Unsubscribe()
+		go forward(ctx, t, sub, merged)
+	}
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev := <-merged:
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// forward converts a book's events until the subscription closes or the
+// stream ends.
+func forward(ctx context.Context, t market.Ticker, sub *pubsub.Subscription[core.Event], out chan<- *marketpb.Event) {
+	for ev := range sub.C() {
+		pb := eventToPB(t, ev)
+		if pb == nil {
+			continue
+		}
+		select {
+		case out <- pb:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// statusOf maps a rejection to a gRPC status, as the REST API maps them to
+// HTTP statuses.
+func statusOf(err error) error {
+	var oe *order.Error
+	if errors.As(err, &oe) {
+		switch oe.Code {
+		case order.CodeUnknownTicker:
+			return status.Error(codes.NotFound, oe.Error())
+		case order.CodeUnsupported:
+			return status.Error(codes.Unimplemented, oe.Error())
+		}
+		return status.Error(codes.InvalidArgument, oe.Error())
+	}
+
+	switch {
+	case errors.Is(err, marketservice.ErrUnknownTicker), errors.Is(err, core.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, marketservice.ErrTickerHalted), errors.Is(err, core.ErrAuction),
+		errors.Is(err, marketservice.ErrInsufficientFunds), errors.Is(err, marketservice.ErrPositionLimit):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, core.ErrInvalidOrder):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	}
+	return status.Error(codes.Internal, err.Error())
+}
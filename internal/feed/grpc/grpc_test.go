@@ -0,0 +1,127 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/zappabad/stockcraft/internal/feed/grpc/marketpb"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// newTestClient serves a synchronous market in process and returns a client
+// connected to it.
+func newTestClient(t *testing.T) (marketpb.MarketClient, *marketservice.MarketService) {
+	t.Helper()
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	m := marketservice.NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	t.Cleanup(m.Close)
+
+	lis := bufconn.Listen(1 << 20)
+	g := grpc.NewServer()
+	NewServer(m).Register(g)
+	go g.Serve(lis)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return marketpb.NewMarketClient(conn), m
+}
+
+func TestSubmitStreamsEvents(t *testing.T) {
+	client, m := newTestClient(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Events(ctx, &marketpb.EventsRequest{Tickers: []string{"aapl"}})
+	if err != nil {
+		t.Fatalf("events: %v", err)
+	}
+	// The server subscribes before sending headers
+	if _, err := stream.Header(); err != nil {
+		t.Fatalf("events header: %v", err)
+	}
+
+	report, err := client.SubmitLimit(ctx, &marketpb.SubmitLimitRequest{
+		Ticker: "AAPL", UserId: 1000, Side: marketpb.Side_SIDE_BUY, Price: 15025, Size: 10,
+	})
+	if err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+	if !report.GetRested() || report.GetRestedSize() != 10 || report.GetOrderId() == 0 {
+		t.Fatalf("expected 10 resting, got %v", report)
+	}
+
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("recv: %v", err)
+	}
+	rested := ev.GetRested()
+	if ev.GetTicker() != "AAPL" || rested == nil || rested.GetOrderId() != report.GetOrderId() ||
+		rested.GetPrice() != 15025 || rested.GetSize() != 10 || rested.GetSide() != marketpb.Side_SIDE_BUY {
+		t.Fatalf("expected the order's rested event, got %v", ev)
+	}
+
+	// A market order trades against it and the stream carries the trade
+	if _, err := client.SubmitMarket(ctx, &marketpb.SubmitMarketRequest{Ticker: "1", UserId: 1001, Side: marketpb.Side_SIDE_SELL, Size: 4}); err != nil {
+		t.Fatalf("submit market: %v", err)
+	}
+	if ev, err = stream.Recv(); err != nil || ev.GetTrade().GetSize() != 4 || ev.GetTrade().GetMakerOrderId() != report.GetOrderId() {
+		t.Fatalf("expected a trade of 4, got %v (%v)", ev, err)
+	}
+
+	cr, err := client.Cancel(ctx, &marketpb.CancelRequest{Ticker: "AAPL", OrderId: report.GetOrderId()})
+	if err != nil || cr.GetCanceledSize() != 6 {
+		t.Fatalf("expected 6 canceled, got %v (%v)", cr, err)
+	}
+	if levels, _ := m.GetLevels(1, core.SideBuy); len(levels) != 0 {
+		t.Errorf("expected the bid gone, got %+v", levels)
+	}
+}
+
+func TestRejectionsCarryStatusCodes(t *testing.T) {
+	client, _ := newTestClient(t)
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{"zero size", func() error {
+			_, err := client.SubmitLimit(ctx, &marketpb.SubmitLimitRequest{Ticker: "AAPL", UserId: 1, Side: marketpb.Side_SIDE_BUY, Price: 100})
+			return err
+		}, codes.InvalidArgument},
+		{"no side", func() error {
+			_, err := client.SubmitMarket(ctx, &marketpb.SubmitMarketRequest{Ticker: "AAPL", UserId: 1, Size: 1})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown ticker", func() error {
+			_, err := client.SubmitLimit(ctx, &marketpb.SubmitLimitRequest{Ticker: "ZZZZ", UserId: 1, Side: marketpb.Side_SIDE_BUY, Price: 100, Size: 1})
+			return err
+		}, codes.NotFound},
+		{"unknown order", func() error {
+			_, err := client.Cancel(ctx, &marketpb.CancelRequest{Ticker: "AAPL", OrderId: 42})
+			return err
+		}, codes.NotFound},
+	} {
+		if got := status.Code(tt.call()); got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
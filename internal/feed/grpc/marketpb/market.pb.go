@@ -0,0 +1,1320 @@
+// Market control and event API for non-Go clients. Regenerate the Go code
+// with protoc-gen-go and protoc-gen-go-grpc:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative market.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: market.proto
+
+package marketpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Side int32
+
+const (
+	Side_SIDE_UNSPECIFIED Side = 0
+	Side_SIDE_BUY         Side = 1
+	Side_SIDE_SELL        Side = 2
+)
+
+// Enum value maps for Side.
+var (
+	Side_name = map[int32]string{
+		0: "SIDE_UNSPECIFIED",
+		1: "SIDE_BUY",
+		2: "SIDE_SELL",
+	}
+	Side_value = map[string]int32{
+		"SIDE_UNSPECIFIED": 0,
+		"SIDE_BUY":         1,
+		"SIDE_SELL":        2,
+	}
+)
+
+func (x Side) Enum() *Side {
+	p := new(Side)
+	*p = x
+	return p
+}
+
+func (x Side) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Side) Descriptor() protoreflect.EnumDescriptor {
+	return file_market_proto_enumTypes[0].Descriptor()
+}
+
+func (Side) Type() protoreflect.EnumType {
+	return &file_market_proto_enumTypes[0]
+}
+
+func (x Side) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Side.Descriptor instead.
+func (Side) EnumDescriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{0}
+}
+
+type TimeInForce int32
+
+const (
+	TimeInForce_TIME_IN_FORCE_GTC TimeInForce = 0
+	TimeInForce_TIME_IN_FORCE_IOC TimeInForce = 1
+	TimeInForce_TIME_IN_FORCE_FOK TimeInForce = 2
+)
+
+// Enum value maps for TimeInForce.
+var (
+	TimeInForce_name = map[int32]string{
+		0: "TIME_IN_FORCE_GTC",
+		1: "TIME_IN_FORCE_IOC",
+		2: "TIME_IN_FORCE_FOK",
+	}
+	TimeInForce_value = map[string]int32{
+		"TIME_IN_FORCE_GTC": 0,
+		"TIME_IN_FORCE_IOC": 1,
+		"TIME_IN_FORCE_FOK": 2,
+	}
+)
+
+func (x TimeInForce) Enum() *TimeInForce {
+	p := new(TimeInForce)
+	*p = x
+	return p
+}
+
+func (x TimeInForce) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TimeInForce) Descriptor() protoreflect.EnumDescriptor {
+	return file_market_proto_enumTypes[1].Descriptor()
+}
+
+func (TimeInForce) Type() protoreflect.EnumType {
+	return &file_market_proto_enumTypes[1]
+}
+
+func (x TimeInForce) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TimeInForce.Descriptor instead.
+func (TimeInForce) EnumDescriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{1}
+}
+
+type RemoveReason int32
+
+const (
+	RemoveReason_REMOVE_REASON_FILLED     RemoveReason = 0
+	RemoveReason_REMOVE_REASON_CANCELED   RemoveReason = 1
+	RemoveReason_REMOVE_REASON_AMENDED    RemoveReason = 2
+	RemoveReason_REMOVE_REASON_SELF_TRADE RemoveReason = 3
+)
+
+// Enum value maps for RemoveReason.
+var (
+	RemoveReason_name = map[int32]string{
+		0: "REMOVE_REASON_FILLED",
+		1: "REMOVE_REASON_CANCELED",
+		2: "REMOVE_REASON_AMENDED",
+		3: "REMOVE_REASON_SELF_TRADE",
+	}
+	RemoveReason_value = map[string]int32{
+		"REMOVE_REASON_FILLED":     0,
+		"REMOVE_REASON_CANCELED":   1,
+		"REMOVE_REASON_AMENDED":    2,
+		"REMOVE_REASON_SELF_TRADE": 3,
+	}
+)
+
+func (x RemoveReason) Enum() *RemoveReason {
+	p := new(RemoveReason)
+	*p = x
+	return p
+}
+
+func (x RemoveReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RemoveReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_market_proto_enumTypes[2].Descriptor()
+}
+
+func (RemoveReason) Type() protoreflect.EnumType {
+	return &file_market_proto_enumTypes[2]
+}
+
+func (x RemoveReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RemoveReason.Descriptor instead.
+func (RemoveReason) EnumDescriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{2}
+}
+
+// Tickers are given by name or decimal ID; prices are in integer ticks of
+// the ticker's precision.
+type SubmitLimitRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticker        string                 `protobuf:"bytes,1,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Side          Side                   `protobuf:"varint,3,opt,name=side,proto3,enum=stockcraft.market.v1.Side" json:"side,omitempty"`
+	Price         int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	Tif           TimeInForce            `protobuf:"varint,6,opt,name=tif,proto3,enum=stockcraft.market.v1.TimeInForce" json:"tif,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitLimitRequest) Reset() {
+	*x = SubmitLimitRequest{}
+	mi := &file_market_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitLimitRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitLimitRequest) ProtoMessage() {}
+
+func (x *SubmitLimitRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitLimitRequest.ProtoReflect.Descriptor instead.
+func (*SubmitLimitRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SubmitLimitRequest) GetTicker() string {
+	if x != nil {
+		return x.Ticker
+	}
+	return ""
+}
+
+func (x *SubmitLimitRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SubmitLimitRequest) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *SubmitLimitRequest) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *SubmitLimitRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *SubmitLimitRequest) GetTif() TimeInForce {
+	if x != nil {
+		return x.Tif
+	}
+	return TimeInForce_TIME_IN_FORCE_GTC
+}
+
+type SubmitMarketRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticker        string                 `protobuf:"bytes,1,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Side          Side                   `protobuf:"varint,3,opt,name=side,proto3,enum=stockcraft.market.v1.Side" json:"side,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubmitMarketRequest) Reset() {
+	*x = SubmitMarketRequest{}
+	mi := &file_market_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitMarketRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitMarketRequest) ProtoMessage() {}
+
+func (x *SubmitMarketRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitMarketRequest.ProtoReflect.Descriptor instead.
+func (*SubmitMarketRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SubmitMarketRequest) GetTicker() string {
+	if x != nil {
+		return x.Ticker
+	}
+	return ""
+}
+
+func (x *SubmitMarketRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *SubmitMarketRequest) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *SubmitMarketRequest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type Fill struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MakerOrderId  int64                  `protobuf:"varint,1,opt,name=maker_order_id,json=makerOrderId,proto3" json:"maker_order_id,omitempty"`
+	Price         int64                  `protobuf:"varint,2,opt,name=price,proto3" json:"price,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fill) Reset() {
+	*x = Fill{}
+	mi := &file_market_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fill) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fill) ProtoMessage() {}
+
+func (x *Fill) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fill.ProtoReflect.Descriptor instead.
+func (*Fill) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Fill) GetMakerOrderId() int64 {
+	if x != nil {
+		return x.MakerOrderId
+	}
+	return 0
+}
+
+func (x *Fill) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Fill) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+type SubmitReport struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	OrderId          int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Remaining        int64                  `protobuf:"varint,2,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	RestedSize       int64                  `protobuf:"varint,3,opt,name=rested_size,json=restedSize,proto3" json:"rested_size,omitempty"`
+	Fills            []*Fill                `protobuf:"bytes,4,rep,name=fills,proto3" json:"fills,omitempty"`
+	Rested           bool                   `protobuf:"varint,5,opt,name=rested,proto3" json:"rested,omitempty"`
+	StpCanceled      []int64                `protobuf:"varint,6,rep,packed,name=stp_canceled,json=stpCanceled,proto3" json:"stp_canceled,omitempty"`
+	StpTakerCanceled bool                   `protobuf:"varint,7,opt,name=stp_taker_canceled,json=stpTakerCanceled,proto3" json:"stp_taker_canceled,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SubmitReport) Reset() {
+	*x = SubmitReport{}
+	mi := &file_market_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubmitReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubmitReport) ProtoMessage() {}
+
+func (x *SubmitReport) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubmitReport.ProtoReflect.Descriptor instead.
+func (*SubmitReport) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *SubmitReport) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *SubmitReport) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *SubmitReport) GetRestedSize() int64 {
+	if x != nil {
+		return x.RestedSize
+	}
+	return 0
+}
+
+func (x *SubmitReport) GetFills() []*Fill {
+	if x != nil {
+		return x.Fills
+	}
+	return nil
+}
+
+func (x *SubmitReport) GetRested() bool {
+	if x != nil {
+		return x.Rested
+	}
+	return false
+}
+
+func (x *SubmitReport) GetStpCanceled() []int64 {
+	if x != nil {
+		return x.StpCanceled
+	}
+	return nil
+}
+
+func (x *SubmitReport) GetStpTakerCanceled() bool {
+	if x != nil {
+		return x.StpTakerCanceled
+	}
+	return false
+}
+
+type CancelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ticker        string                 `protobuf:"bytes,1,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	OrderId       int64                  `protobuf:"varint,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRequest) Reset() {
+	*x = CancelRequest{}
+	mi := &file_market_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRequest) ProtoMessage() {}
+
+func (x *CancelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRequest.ProtoReflect.Descriptor instead.
+func (*CancelRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CancelRequest) GetTicker() string {
+	if x != nil {
+		return x.Ticker
+	}
+	return ""
+}
+
+func (x *CancelRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type CancelReport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	CanceledSize  int64                  `protobuf:"varint,2,opt,name=canceled_size,json=canceledSize,proto3" json:"canceled_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelReport) Reset() {
+	*x = CancelReport{}
+	mi := &file_market_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelReport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelReport) ProtoMessage() {}
+
+func (x *CancelReport) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelReport.ProtoReflect.Descriptor instead.
+func (*CancelReport) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CancelReport) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *CancelReport) GetCanceledSize() int64 {
+	if x != nil {
+		return x.CanceledSize
+	}
+	return 0
+}
+
+// EventsRequest names the tickers to stream; none streams every ticker.
+type EventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tickers       []string               `protobuf:"bytes,1,rep,name=tickers,proto3" json:"tickers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventsRequest) Reset() {
+	*x = EventsRequest{}
+	mi := &file_market_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventsRequest) ProtoMessage() {}
+
+func (x *EventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventsRequest.ProtoReflect.Descriptor instead.
+func (*EventsRequest) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *EventsRequest) GetTickers() []string {
+	if x != nil {
+		return x.Tickers
+	}
+	return nil
+}
+
+type Event struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TickerId int64                  `protobuf:"varint,1,opt,name=ticker_id,json=tickerId,proto3" json:"ticker_id,omitempty"`
+	Ticker   string                 `protobuf:"bytes,2,opt,name=ticker,proto3" json:"ticker,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*Event_Trade
+	//	*Event_Rested
+	//	*Event_Reduced
+	//	*Event_Removed
+	Event         isEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_market_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Event) GetTickerId() int64 {
+	if x != nil {
+		return x.TickerId
+	}
+	return 0
+}
+
+func (x *Event) GetTicker() string {
+	if x != nil {
+		return x.Ticker
+	}
+	return ""
+}
+
+func (x *Event) GetEvent() isEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *Event) GetTrade() *Trade {
+	if x != nil {
+		if x, ok := x.Event.(*Event_Trade); ok {
+			return x.Trade
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRested() *OrderRested {
+	if x != nil {
+		if x, ok := x.Event.(*Event_Rested); ok {
+			return x.Rested
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetReduced() *OrderReduced {
+	if x != nil {
+		if x, ok := x.Event.(*Event_Reduced); ok {
+			return x.Reduced
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRemoved() *OrderRemoved {
+	if x != nil {
+		if x, ok := x.Event.(*Event_Removed); ok {
+			return x.Removed
+		}
+	}
+	return nil
+}
+
+type isEvent_Event interface {
+	isEvent_Event()
+}
+
+type Event_Trade struct {
+	Trade *Trade `protobuf:"bytes,3,opt,name=trade,proto3,oneof"`
+}
+
+type Event_Rested struct {
+	Rested *OrderRested `protobuf:"bytes,4,opt,name=rested,proto3,oneof"`
+}
+
+type Event_Reduced struct {
+	Reduced *OrderReduced `protobuf:"bytes,5,opt,name=reduced,proto3,oneof"`
+}
+
+type Event_Removed struct {
+	Removed *OrderRemoved `protobuf:"bytes,6,opt,name=removed,proto3,oneof"`
+}
+
+func (*Event_Trade) isEvent_Event() {}
+
+func (*Event_Rested) isEvent_Event() {}
+
+func (*Event_Reduced) isEvent_Event() {}
+
+func (*Event_Removed) isEvent_Event() {}
+
+type Trade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         int64                  `protobuf:"varint,1,opt,name=price,proto3" json:"price,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	TakerSide     Side                   `protobuf:"varint,3,opt,name=taker_side,json=takerSide,proto3,enum=stockcraft.market.v1.Side" json:"taker_side,omitempty"`
+	Time          int64                  `protobuf:"varint,4,opt,name=time,proto3" json:"time,omitempty"`
+	TakerOrderId  int64                  `protobuf:"varint,5,opt,name=taker_order_id,json=takerOrderId,proto3" json:"taker_order_id,omitempty"`
+	TakerUserId   int64                  `protobuf:"varint,6,opt,name=taker_user_id,json=takerUserId,proto3" json:"taker_user_id,omitempty"`
+	MakerOrderId  int64                  `protobuf:"varint,7,opt,name=maker_order_id,json=makerOrderId,proto3" json:"maker_order_id,omitempty"`
+	MakerUserId   int64                  `protobuf:"varint,8,opt,name=maker_user_id,json=makerUserId,proto3" json:"maker_user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trade) Reset() {
+	*x = Trade{}
+	mi := &file_market_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trade) ProtoMessage() {}
+
+func (x *Trade) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trade.ProtoReflect.Descriptor instead.
+func (*Trade) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Trade) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Trade) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Trade) GetTakerSide() Side {
+	if x != nil {
+		return x.TakerSide
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *Trade) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Trade) GetTakerOrderId() int64 {
+	if x != nil {
+		return x.TakerOrderId
+	}
+	return 0
+}
+
+func (x *Trade) GetTakerUserId() int64 {
+	if x != nil {
+		return x.TakerUserId
+	}
+	return 0
+}
+
+func (x *Trade) GetMakerOrderId() int64 {
+	if x != nil {
+		return x.MakerOrderId
+	}
+	return 0
+}
+
+func (x *Trade) GetMakerUserId() int64 {
+	if x != nil {
+		return x.MakerUserId
+	}
+	return 0
+}
+
+type OrderRested struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Side          Side                   `protobuf:"varint,3,opt,name=side,proto3,enum=stockcraft.market.v1.Side" json:"side,omitempty"`
+	Price         int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`
+	Time          int64                  `protobuf:"varint,6,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderRested) Reset() {
+	*x = OrderRested{}
+	mi := &file_market_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderRested) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderRested) ProtoMessage() {}
+
+func (x *OrderRested) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderRested.ProtoReflect.Descriptor instead.
+func (*OrderRested) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *OrderRested) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderRested) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderRested) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *OrderRested) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderRested) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *OrderRested) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+type OrderReduced struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Delta         int64                  `protobuf:"varint,2,opt,name=delta,proto3" json:"delta,omitempty"`
+	Remaining     int64                  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	Price         int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Side          Side                   `protobuf:"varint,5,opt,name=side,proto3,enum=stockcraft.market.v1.Side" json:"side,omitempty"`
+	UserId        int64                  `protobuf:"varint,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Time          int64                  `protobuf:"varint,7,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderReduced) Reset() {
+	*x = OrderReduced{}
+	mi := &file_market_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderReduced) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderReduced) ProtoMessage() {}
+
+func (x *OrderReduced) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderReduced.ProtoReflect.Descriptor instead.
+func (*OrderReduced) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *OrderReduced) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderReduced) GetDelta() int64 {
+	if x != nil {
+		return x.Delta
+	}
+	return 0
+}
+
+func (x *OrderReduced) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *OrderReduced) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderReduced) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *OrderReduced) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderReduced) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+type OrderRemoved struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Reason        RemoveReason           `protobuf:"varint,2,opt,name=reason,proto3,enum=stockcraft.market.v1.RemoveReason" json:"reason,omitempty"`
+	Remaining     int64                  `protobuf:"varint,3,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	Price         int64                  `protobuf:"varint,4,opt,name=price,proto3" json:"price,omitempty"`
+	Side          Side                   `protobuf:"varint,5,opt,name=side,proto3,enum=stockcraft.market.v1.Side" json:"side,omitempty"`
+	UserId        int64                  `protobuf:"varint,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Time          int64                  `protobuf:"varint,7,opt,name=time,proto3" json:"time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderRemoved) Reset() {
+	*x = OrderRemoved{}
+	mi := &file_market_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderRemoved) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderRemoved) ProtoMessage() {}
+
+func (x *OrderRemoved) ProtoReflect() protoreflect.Message {
+	mi := &file_market_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderRemoved.ProtoReflect.Descriptor instead.
+func (*OrderRemoved) Descriptor() ([]byte, []int) {
+	return file_market_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *OrderRemoved) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderRemoved) GetReason() RemoveReason {
+	if x != nil {
+		return x.Reason
+	}
+	return RemoveReason_REMOVE_REASON_FILLED
+}
+
+func (x *OrderRemoved) GetRemaining() int64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *OrderRemoved) GetPrice() int64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderRemoved) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *OrderRemoved) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *OrderRemoved) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+var File_market_proto protoreflect.FileDescriptor
+
+const file_market_proto_rawDesc = "" +
+	"\n" +
+	"\fmarket.proto\x12\x14stockcraft.market.v1\"\xd4\x01\n" +
+	"\x12SubmitLimitRequest\x12\x16\n" +
+	"\x06ticker\x18\x01 \x01(\tR\x06ticker\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12.\n" +
+	"\x04side\x18\x03 \x01(\x0e2\x1a.stockcraft.market.v1.SideR\x04side\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x03R\x05price\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x123\n" +
+	"\x03tif\x18\x06 \x01(\x0e2!.stockcraft.market.v1.TimeInForceR\x03tif\"\x8a\x01\n" +
+	"\x13SubmitMarketRequest\x12\x16\n" +
+	"\x06ticker\x18\x01 \x01(\tR\x06ticker\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12.\n" +
+	"\x04side\x18\x03 \x01(\x0e2\x1a.stockcraft.market.v1.SideR\x04side\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"V\n" +
+	"\x04Fill\x12$\n" +
+	"\x0emaker_order_id\x18\x01 \x01(\x03R\fmakerOrderId\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x03R\x05price\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\"\x83\x02\n" +
+	"\fSubmitReport\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x1c\n" +
+	"\tremaining\x18\x02 \x01(\x03R\tremaining\x12\x1f\n" +
+	"\vrested_size\x18\x03 \x01(\x03R\n" +
+	"restedSize\x120\n" +
+	"\x05fills\x18\x04 \x03(\v2\x1a.stockcraft.market.v1.FillR\x05fills\x12\x16\n" +
+	"\x06rested\x18\x05 \x01(\bR\x06rested\x12!\n" +
+	"\fstp_canceled\x18\x06 \x03(\x03R\vstpCanceled\x12,\n" +
+	"\x12stp_taker_canceled\x18\a \x01(\bR\x10stpTakerCanceled\"B\n" +
+	"\rCancelRequest\x12\x16\n" +
+	"\x06ticker\x18\x01 \x01(\tR\x06ticker\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\x03R\aorderId\"N\n" +
+	"\fCancelReport\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12#\n" +
+	"\rcanceled_size\x18\x02 \x01(\x03R\fcanceledSize\")\n" +
+	"\rEventsRequest\x12\x18\n" +
+	"\atickers\x18\x01 \x03(\tR\atickers\"\xb7\x02\n" +
+	"\x05Event\x12\x1b\n" +
+	"\tticker_id\x18\x01 \x01(\x03R\btickerId\x12\x16\n" +
+	"\x06ticker\x18\x02 \x01(\tR\x06ticker\x123\n" +
+	"\x05trade\x18\x03 \x01(\v2\x1b.stockcraft.market.v1.TradeH\x00R\x05trade\x12;\n" +
+	"\x06rested\x18\x04 \x01(\v2!.stockcraft.market.v1.OrderRestedH\x00R\x06rested\x12>\n" +
+	"\areduced\x18\x05 \x01(\v2\".stockcraft.market.v1.OrderReducedH\x00R\areduced\x12>\n" +
+	"\aremoved\x18\x06 \x01(\v2\".stockcraft.market.v1.OrderRemovedH\x00R\aremovedB\a\n" +
+	"\x05event\"\x94\x02\n" +
+	"\x05Trade\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\x03R\x05price\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x129\n" +
+	"\n" +
+	"taker_side\x18\x03 \x01(\x0e2\x1a.stockcraft.market.v1.SideR\ttakerSide\x12\x12\n" +
+	"\x04time\x18\x04 \x01(\x03R\x04time\x12$\n" +
+	"\x0etaker_order_id\x18\x05 \x01(\x03R\ftakerOrderId\x12\"\n" +
+	"\rtaker_user_id\x18\x06 \x01(\x03R\vtakerUserId\x12$\n" +
+	"\x0emaker_order_id\x18\a \x01(\x03R\fmakerOrderId\x12\"\n" +
+	"\rmaker_user_id\x18\b \x01(\x03R\vmakerUserId\"\xaf\x01\n" +
+	"\vOrderRested\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12.\n" +
+	"\x04side\x18\x03 \x01(\x0e2\x1a.stockcraft.market.v1.SideR\x04side\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x03R\x05price\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04time\x18\x06 \x01(\x03R\x04time\"\xd0\x01\n" +
+	"\fOrderReduced\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12\x14\n" +
+	"\x05delta\x18\x02 \x01(\x03R\x05delta\x12\x1c\n" +
+	"\tremaining\x18\x03 \x01(\x03R\tremaining\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x03R\x05price\x12.\n" +
+	"\x04side\x18\x05 \x01(\x0e2\x1a.stockcraft.market.v1.SideR\x04side\x12\x17\n" +
+	"\auser_id\x18\x06 \x01(\x03R\x06userId\x12\x12\n" +
+	"\x04time\x18\a \x01(\x03R\x04time\"\xf6\x01\n" +
+	"\fOrderRemoved\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\x12:\n" +
+	"\x06reason\x18\x02 \x01(\x0e2\".stockcraft.market.v1.RemoveReasonR\x06reason\x12\x1c\n" +
+	"\tremaining\x18\x03 \x01(\x03R\tremaining\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x03R\x05price\x12.\n" +
+	"\x04side\x18\x05 \x01(\x0e2\x1a.stockcraft.market.v1.SideR\x04side\x12\x17\n" +
+	"\auser_id\x18\x06 \x01(\x03R\x06userId\x12\x12\n" +
+	"\x04time\x18\a \x01(\x03R\x04time*9\n" +
+	"\x04Side\x12\x14\n" +
+	"\x10SIDE_UNSPECIFIED\x10\x00\x12\f\n" +
+	"\bSIDE_BUY\x10\x01\x12\r\n" +
+	"\tSIDE_SELL\x10\x02*R\n" +
+	"\vTimeInForce\x12\x15\n" +
+	"\x11TIME_IN_FORCE_GTC\x10\x00\x12\x15\n" +
+	"\x11TIME_IN_FORCE_IOC\x10\x01\x12\x15\n" +
+	"\x11TIME_IN_FORCE_FOK\x10\x02*}\n" +
+	"\fRemoveReason\x12\x18\n" +
+	"\x14REMOVE_REASON_FILLED\x10\x00\x12\x1a\n" +
+	"\x16REMOVE_REASON_CANCELED\x10\x01\x12\x19\n" +
+	"\x15REMOVE_REASON_AMENDED\x10\x02\x12\x1c\n" +
+	"\x18REMOVE_REASON_SELF_TRADE\x10\x032\xe5\x02\n" +
+	"\x06Market\x12[\n" +
+	"\vSubmitLimit\x12(.stockcraft.market.v1.SubmitLimitRequest\x1a\".stockcraft.market.v1.SubmitReport\x12]\n" +
+	"\fSubmitMarket\x12).stockcraft.market.v1.SubmitMarketRequest\x1a\".stockcraft.market.v1.SubmitReport\x12Q\n" +
+	"\x06Cancel\x12#.stockcraft.market.v1.CancelRequest\x1a\".stockcraft.market.v1.CancelReport\x12L\n" +
+	"\x06Events\x12#.stockcraft.market.v1.EventsRequest\x1a\x1b.stockcraft.market.v1.Event0\x01B<Z:github.com/zappabad/stockcraft/internal/feed/grpc/marketpbb\x06proto3"
+
+var (
+	file_market_proto_rawDescOnce sync.Once
+	file_market_proto_rawDescData []byte
+)
+
+func file_market_proto_rawDescGZIP() []byte {
+	file_market_proto_rawDescOnce.Do(func() {
+		file_market_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_market_proto_rawDesc), len(file_market_proto_rawDesc)))
+	})
+	return file_market_proto_rawDescData
+}
+
+var file_market_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_market_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_market_proto_goTypes = []any{
+	(Side)(0),                   // 0: stockcraft.market.v1.Side
+	(TimeInForce)(0),            // 1: stockcraft.market.v1.TimeInForce
+	(RemoveReason)(0),           // 2: stockcraft.market.v1.RemoveReason
+	(*SubmitLimitRequest)(nil),  // 3: stockcraft.market.v1.SubmitLimitRequest
+	(*SubmitMarketRequest)(nil), // 4: stockcraft.market.v1.SubmitMarketRequest
+	(*Fill)(nil),                // 5: stockcraft.market.v1.Fill
+	(*SubmitReport)(nil),        // 6: stockcraft.market.v1.SubmitReport
+	(*CancelRequest)(nil),       // 7: stockcraft.market.v1.CancelRequest
+	(*CancelReport)(nil),        // 8: stockcraft.market.v1.CancelReport
+	(*EventsRequest)(nil),       // 9: stockcraft.market.v1.EventsRequest
+	(*Event)(nil),               // 10: stockcraft.market.v1.Event
+	(*Trade)(nil),               // 11: stockcraft.market.v1.Trade
+	(*OrderRested)(nil),         // 12: stockcraft.market.v1.OrderRested
+	(*OrderReduced)(nil),        // 13: stockcraft.market.v1.OrderReduced
+	(*OrderRemoved)(nil),        // 14: stockcraft.market.v1.OrderRemoved
+}
+var file_market_proto_depIdxs = []int32{
+	0,  // 0: stockcraft.market.v1.SubmitLimitRequest.side:type_name -> stockcraft.market.v1.Side
+	1,  // 1: stockcraft.market.v1.SubmitLimitRequest.tif:type_name -> stockcraft.market.v1.TimeInForce
+	0,  // 2: stockcraft.market.v1.SubmitMarketRequest.side:type_name -> stockcraft.market.v1.Side
+	5,  // 3: stockcraft.market.v1.SubmitReport.fills:type_name -> stockcraft.market.v1.Fill
+	11, // 4: stockcraft.market.v1.Event.trade:type_name -> stockcraft.market.v1.Trade
+	12, // 5: stockcraft.market.v1.Event.rested:type_name -> stockcraft.market.v1.OrderRested
+	13, // 6: stockcraft.market.v1.Event.reduced:type_name -> stockcraft.market.v1.OrderReduced
+	14, // 7: stockcraft.market.v1.Event.removed:type_name -> stockcraft.market.v1.OrderRemoved
+	0,  // 8: stockcraft.market.v1.Trade.taker_side:type_name -> stockcraft.market.v1.Side
+	0,  // 9: stockcraft.market.v1.OrderRested.side:type_name -> stockcraft.market.v1.Side
+	0,  // 10: stockcraft.market.v1.OrderReduced.side:type_name -> stockcraft.market.v1.Side
+	2,  // 11: stockcraft.market.v1.OrderRemoved.reason:type_name -> stockcraft.market.v1.RemoveReason
+	0,  // 12: stockcraft.market.v1.OrderRemoved.side:type_name -> stockcraft.market.v1.Side
+	3,  // 13: stockcraft.market.v1.Market.SubmitLimit:input_type -> stockcraft.market.v1.SubmitLimitRequest
+	4,  // 14: stockcraft.market.v1.Market.SubmitMarket:input_type -> stockcraft.market.v1.SubmitMarketRequest
+	7,  // 15: stockcraft.market.v1.Market.Cancel:input_type -> stockcraft.market.v1.CancelRequest
+	9,  // 16: stockcraft.market.v1.Market.Events:input_type -> stockcraft.market.v1.EventsRequest
+	6,  // 17: stockcraft.market.v1.Market.SubmitLimit:output_type -> stockcraft.market.v1.SubmitReport
+	6,  // 18: stockcraft.market.v1.Market.SubmitMarket:output_type -> stockcraft.market.v1.SubmitReport
+	8,  // 19: stockcraft.market.v1.Market.Cancel:output_type -> stockcraft.market.v1.CancelReport
+	10, // 20: stockcraft.market.v1.Market.Events:output_type -> stockcraft.market.v1.Event
+	17, // [17:21] is the sub-list for method output_type
+	13, // [13:17] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
+}
+
+func init() { file_market_proto_init() }
+func file_market_proto_init() {
+	if File_market_proto != nil {
+		return
+	}
+	file_market_proto_msgTypes[7].OneofWrappers = []any{
+		(*Event_Trade)(nil),
+		(*Event_Rested)(nil),
+		(*Event_Reduced)(nil),
+		(*Event_Removed)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_market_proto_rawDesc), len(file_market_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_market_proto_goTypes,
+		DependencyIndexes: file_market_proto_depIdxs,
+		EnumInfos:         file_market_proto_enumTypes,
+		MessageInfos:      file_market_proto_msgTypes,
+	}.Build()
+	File_market_proto = out.File
+	file_market_proto_goTypes = nil
+	file_market_proto_depIdxs = nil
+}
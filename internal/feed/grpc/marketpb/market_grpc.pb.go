@@ -0,0 +1,253 @@
+// Market control and event API for non-Go clients. Regenerate the Go code
+// with protoc-gen-go and protoc-gen-go-grpc:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//	  --go-grpc_out=. --go-grpc_opt=paths=source_relative market.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: market.proto
+
+package marketpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Market_SubmitLimit_FullMethodName  = "/stockcraft.market.v1.Market/SubmitLimit"
+	Market_SubmitMarket_FullMethodName = "/stockcraft.market.v1.Market/SubmitMarket"
+	Market_Cancel_FullMethodName       = "/stockcraft.market.v1.Market/Cancel"
+	Market_Events_FullMethodName       = "/stockcraft.market.v1.Market/Events"
+)
+
+// MarketClient is the client API for Market service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Market submits and cancels orders and streams book events.
+type MarketClient interface {
+	SubmitLimit(ctx context.Context, in *SubmitLimitRequest, opts ...grpc.CallOption) (*SubmitReport, error)
+	SubmitMarket(ctx context.Context, in *SubmitMarketRequest, opts ...grpc.CallOption) (*SubmitReport, error)
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelReport, error)
+	// Events streams book events for the requested tickers, from the moment
+	// of the call, until the client cancels.
+	Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type marketClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMarketClient(cc grpc.ClientConnInterface) MarketClient {
+	return &marketClient{cc}
+}
+
+func (c *marketClient) SubmitLimit(ctx context.Context, in *SubmitLimitRequest, opts ...grpc.CallOption) (*SubmitReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitReport)
+	err := c.cc.Invoke(ctx, Market_SubmitLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) SubmitMarket(ctx context.Context, in *SubmitMarketRequest, opts ...grpc.CallOption) (*SubmitReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SubmitReport)
+	err := c.cc.Invoke(ctx, Market_SubmitMarket_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelReport, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelReport)
+	err := c.cc.Invoke(ctx, Market_Cancel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *marketClient) Events(ctx context.Context, in *EventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Market_ServiceDesc.Streams[0], Market_Events_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[EventsRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Market_EventsClient = grpc.ServerStreamingClient[Event]
+
+// MarketServer is the server API for Market service.
+// All implementations must embed UnimplementedMarketServer
+// for forward compatibility.
+//
+// Market submits and cancels orders and streams book events.
+type MarketServer interface {
+	SubmitLimit(context.Context, *SubmitLimitRequest) (*SubmitReport, error)
+	SubmitMarket(context.Context, *SubmitMarketRequest) (*SubmitReport, error)
+	Cancel(context.Context, *CancelRequest) (*CancelReport, error)
+	// Events streams book events for the requested tickers, from the moment
+	// of the call, until the client cancels.
+	Events(*EventsRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedMarketServer()
+}
+
+// UnimplementedMarketServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMarketServer struct{}
+
+func (UnimplementedMarketServer) SubmitLimit(context.Context, *SubmitLimitRequest) (*SubmitReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitLimit not implemented")
+}
+func (UnimplementedMarketServer) SubmitMarket(context.Context, *SubmitMarketRequest) (*SubmitReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method SubmitMarket not implemented")
+}
+func (UnimplementedMarketServer) Cancel(context.Context, *CancelRequest) (*CancelReport, error) {
+	return nil, status.Error(codes.Unimplemented, "method Cancel not implemented")
+}
+func (UnimplementedMarketServer) Events(*EventsRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method Events not implemented")
+}
+func (UnimplementedMarketServer) mustEmbedUnimplementedMarketServer() {}
+func (UnimplementedMarketServer) testEmbeddedByValue()                {}
+
+// UnsafeMarketServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MarketServer will
+// result in compilation errors.
+type UnsafeMarketServer interface {
+	mustEmbedUnimplementedMarketServer()
+}
+
+func RegisterMarketServer(s grpc.ServiceRegistrar, srv MarketServer) {
+	// If the following call panics, it indicates UnimplementedMarketServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Market_ServiceDesc, srv)
+}
+
+func _Market_SubmitLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).SubmitLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_SubmitLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).SubmitLimit(ctx, req.(*SubmitLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_SubmitMarket_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitMarketRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).SubmitMarket(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_SubmitMarket_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).SubmitMarket(ctx, req.(*SubmitMarketRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_Cancel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MarketServer).Cancel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Market_Cancel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MarketServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Market_Events_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(EventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MarketServer).Events(m, &grpc.GenericServerStream[EventsRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Market_EventsServer = grpc.ServerStreamingServer[Event]
+
+// Market_ServiceDesc is the grpc.ServiceDesc for Market service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Market_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "stockcraft.market.v1.Market",
+	HandlerType: (*MarketServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "SubmitLimit",
+			Handler:    _Market_SubmitLimit_Handler,
+		},
+		{
+			MethodName: "SubmitMarket",
+			Handler:    _Market_SubmitMarket_Handler,
+		},
+		{
+			MethodName: "Cancel",
+			Handler:    _Market_Cancel_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Events",
+			Handler:       _Market_Events_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "market.proto",
+}
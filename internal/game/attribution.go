@@ -0,0 +1,166 @@
+package game
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// AttributionBucket aggregates a user's fills against one counterparty
+// role. P&L is in price ticks times size, marked to the report's marks.
+type AttributionBucket struct {
+	Fills       int
+	Volume      core.Size
+	PnL         int64
+	MakerVolume core.Size // the user's resting order was hit
+	MakerPnL    int64
+	TakerVolume core.Size // the user's incoming order crossed
+	TakerPnL    int64
+}
+
+// add counts a fill, returning money.ErrOverflow if a P&L sum overflows.
+func (b *AttributionBucket) add(f marketview.Fill, pnl int64) error {
+	total, ok := money.AddChecked(b.PnL, pnl)
+	if !ok {
+		return money.ErrOverflow
+	}
+	side := &b.TakerPnL
+	if f.Role == marketview.RoleMaker {
+		side = &b.MakerPnL
+	}
+	sideTotal, ok := money.AddChecked(*side, pnl)
+	if !ok {
+		return money.ErrOverflow
+	}
+
+	b.Fills++
+	b.Volume += f.Size
+	b.PnL = total
+	*side = sideTotal
+	if f.Role == marketview.RoleMaker {
+		b.MakerVolume += f.Size
+	} else {
+		b.TakerVolume += f.Size
+	}
+	return nil
+}
+
+// AttributionReport breaks a user's fills down by who was on the other side.
+type AttributionReport struct {
+	UserID core.UserID
+	Marks  map[market.TickerID]core.PriceTicks
+	ByRole map[Role]AttributionBucket
+	Total  AttributionBucket
+	// TotalValue is Total.PnL in currency, each ticker's share converted at
+	// its TickValue.
+	TotalValue float64
+}
+
+// FillPnL is the P&L of a fill marked at mark: a buy gains when the mark is
+// above the fill price and a sell when it is below. It returns
+// money.ErrOverflow if the P&L does not fit in an int64.
+func FillPnL(f marketview.Fill, mark core.PriceTicks) (int64, error) {
+	diff, ok := money.SubChecked(int64(mark), int64(f.Price))
+	if f.Side == core.SideSell {
+		diff, ok = money.SubChecked(int64(f.Price), int64(mark))
+	}
+	if !ok {
+		return 0, money.ErrOverflow
+	}
+	pnl, ok := money.MulChecked(diff, int64(f.Size))
+	if !ok {
+		return 0, money.ErrOverflow
+	}
+	return pnl, nil
+}
+
+// Attribution reports the fills of userID still held by the market view (see
+// marketservice.Config.UserFillCapacity), bucketed by the registered role of
+// each counterparty. Each ticker's fills are marked at marks[ticker], or at
+// its last trade (the session close) if marks has no entry. Tickers with
+// neither a mark nor a trade have no fills and are skipped.
+func (g *Game) Attribution(userID core.UserID, marks map[market.TickerID]core.PriceTicks) (AttributionReport, error) {
+	report := AttributionReport{
+		UserID: userID,
+		Marks:  make(map[market.TickerID]core.PriceTicks),
+		ByRole: make(map[Role]AttributionBucket),
+	}
+	snap := g.Market.Snapshot()
+
+	for _, t := range g.Market.GetTickers() {
+		tid := t.TickerID()
+		mark, ok := marks[tid]
+		if !ok {
+			bp := snap.ByTicker[tid]
+			if !bp.HasLast {
+				continue
+			}
+			mark = bp.LastPrice
+		}
+
+		fills, err := g.Market.UserFills(tid, userID, math.MaxInt) // all retained
+		if err != nil {
+			return AttributionReport{}, err
+		}
+		if len(fills) == 0 {
+			continue
+		}
+		report.Marks[tid] = mark
+
+		var tickerPnL int64
+		for _, f := range fills {
+			pnl, err := FillPnL(f, mark)
+			if err != nil {
+				return AttributionReport{}, err
+			}
+			tickerPnL += pnl // bounded by Total, checked below
+			role := g.UserRole(f.Counterparty)
+			b := report.ByRole[role]
+			if err := b.add(f, pnl); err != nil {
+				return AttributionReport{}, err
+			}
+			report.ByRole[role] = b
+			if err := report.Total.add(f, pnl); err != nil {
+				return AttributionReport{}, err
+			}
+		}
+		report.TotalValue += t.Currency(tickerPnL)
+	}
+	return report, nil
+}
+
+// WriteCSV exports the report with one row per counterparty role that
+// traded, in Roles order, followed by a TOTAL row.
+func (r AttributionReport) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{
+		"counterparty", "fills", "volume", "pnl",
+		"maker_volume", "maker_pnl", "taker_volume", "taker_pnl",
+	})
+	row := func(name string, b AttributionBucket) {
+		_ = cw.Write([]string{
+			name,
+			strconv.Itoa(b.Fills),
+			strconv.FormatInt(int64(b.Volume), 10),
+			strconv.FormatInt(b.PnL, 10),
+			strconv.FormatInt(int64(b.MakerVolume), 10),
+			strconv.FormatInt(b.MakerPnL, 10),
+			strconv.FormatInt(int64(b.TakerVolume), 10),
+			strconv.FormatInt(b.TakerPnL, 10),
+		})
+	}
+	for _, role := range Roles {
+		if b, ok := r.ByRole[role]; ok {
+			row(role.String(), b)
+		}
+	}
+	row("TOTAL", r.Total)
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,193 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestAttributionBucketsSumToTotals(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tickers = []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+		{ID: 2, Name: "GOOGL", Decimals: 2},
+	}
+	cfg.TraderConfigs = nil
+	cfg.EnableBroker = false
+	g := NewGame(cfg)
+	defer g.Close()
+
+	const (
+		liquidity core.UserID = 10
+		maker     core.UserID = 11
+		momentum  core.UserID = 12
+		player    core.UserID = 1000
+		rival     core.UserID = 1001
+		stranger  core.UserID = 50 // never registered
+	)
+	g.RegisterUser(liquidity, RoleLiquidityBot)
+	g.RegisterUser(maker, RoleMarketMaker)
+	g.RegisterUser(momentum, RoleMomentumBot)
+	g.RegisterUser(player, RolePlayer)
+	g.RegisterUser(rival, RolePlayer)
+
+	ctx := context.Background()
+	must := func(_ core.SubmitReport, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	m := g.Market
+
+	// Ticker 1, marked at its last trade (102)
+	must(m.SubmitLimit(ctx, 1, maker, core.SideSell, 100, 4))
+	must(m.SubmitMarket(ctx, 1, player, core.SideBuy, 4)) // taker buy 4@100
+	must(m.SubmitLimit(ctx, 1, player, core.SideBuy, 98, 5))
+	must(m.SubmitMarket(ctx, 1, momentum, core.SideSell, 5)) // maker buy 5@98
+	must(m.SubmitLimit(ctx, 1, liquidity, core.SideBuy, 97, 3))
+	must(m.SubmitMarket(ctx, 1, player, core.SideSell, 3)) // taker sell 3@97
+	must(m.SubmitLimit(ctx, 1, rival, core.SideSell, 102, 2))
+	must(m.SubmitLimit(ctx, 1, player, core.SideBuy, 102, 2)) // taker buy 2@102
+	// Ticker 2, marked explicitly at 210
+	must(m.SubmitLimit(ctx, 2, stranger, core.SideSell, 200, 1))
+	must(m.SubmitMarket(ctx, 2, player, core.SideBuy, 1)) // taker buy 1@200
+
+	// Wait for view update
+	time.Sleep(50 * time.Millisecond)
+
+	report, err := g.Attribution(player, map[market.TickerID]core.PriceTicks{2: 210})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Marks[1] != 102 || report.Marks[2] != 210 {
+		t.Errorf("unexpected marks %v", report.Marks)
+	}
+
+	want := map[Role]AttributionBucket{
+		RoleMarketMaker:  {Fills: 1, Volume: 4, PnL: 8, TakerVolume: 4, TakerPnL: 8},
+		RoleMomentumBot:  {Fills: 1, Volume: 5, PnL: 20, MakerVolume: 5, MakerPnL: 20},
+		RoleLiquidityBot: {Fills: 1, Volume: 3, PnL: -15, TakerVolume: 3, TakerPnL: -15},
+		RolePlayer:       {Fills: 1, Volume: 2, PnL: 0, TakerVolume: 2},
+		RoleUnknown:      {Fills: 1, Volume: 1, PnL: 10, TakerVolume: 1, TakerPnL: 10},
+	}
+	if len(report.ByRole) != len(want) {
+		t.Errorf("expected %d buckets, got %+v", len(want), report.ByRole)
+	}
+	var sum AttributionBucket
+	for role, w := range want {
+		got := report.ByRole[role]
+		if got != w {
+			t.Errorf("%s: expected %+v, got %+v", role, w, got)
+		}
+		sum.Fills += got.Fills
+		sum.Volume += got.Volume
+		sum.PnL += got.PnL
+		sum.MakerVolume += got.MakerVolume
+		sum.MakerPnL += got.MakerPnL
+		sum.TakerVolume += got.TakerVolume
+		sum.TakerPnL += got.TakerPnL
+	}
+	if sum != report.Total {
+		t.Errorf("buckets sum to %+v, total is %+v", sum, report.Total)
+	}
+	if report.Total.MakerVolume+report.Total.TakerVolume != report.Total.Volume ||
+		report.Total.MakerPnL+report.Total.TakerPnL != report.Total.PnL {
+		t.Errorf("maker/taker split does not add up: %+v", report.Total)
+	}
+	if report.Total.Volume != 15 || report.Total.PnL != 23 {
+		t.Errorf("unexpected total %+v", report.Total)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2+len(want) || lines[len(lines)-1] != "TOTAL,5,15,23,5,20,10,3" {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
+
+func TestCurrencyPnLUsesTickValue(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tickers = []market.Ticker{
+		{ID: 1, Name: "ES", Decimals: 2, TickValue: 12.5},
+		{ID: 2, Name: "AAPL", Decimals: 2},
+	}
+	cfg.MarketConfig.Synchronous = true
+	cfg.TraderConfigs = nil
+	cfg.EnableBroker = false
+	g := NewGame(cfg)
+	defer g.Close()
+
+	const maker, player core.UserID = 11, 1000
+	ctx := context.Background()
+	must := func(_ core.SubmitReport, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	m := g.Market
+	must(m.SubmitLimit(ctx, 1, maker, core.SideSell, 100, 4))
+	must(m.SubmitMarket(ctx, 1, player, core.SideBuy, 4)) // long 4@100
+	must(m.SubmitLimit(ctx, 2, maker, core.SideSell, 100, 4))
+	must(m.SubmitMarket(ctx, 2, player, core.SideBuy, 4)) // long 4@1.00
+
+	// Marked 3 ticks up: 12 ticks of P&L on each
+	marks := map[market.TickerID]core.PriceTicks{1: 103, 2: 103}
+	report, err := g.Attribution(player, marks)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Total.PnL != 24 {
+		t.Fatalf("expected 24 ticks of P&L, got %d", report.Total.PnL)
+	}
+	// 12 × $12.50 on ES; AAPL's price is in dollars, so 12 × $0.01
+	if want := 12*12.5 + 12*0.01; math.Abs(report.TotalValue-want) > 1e-9 {
+		t.Errorf("expected $%v, got $%v", want, report.TotalValue)
+	}
+
+	es := cfg.Tickers[0]
+	if es.Currency(12) != 12*es.TickValue || es.CurrencyPerTick() != 12.5 {
+		t.Errorf("unexpected ES conversion %v, %v", es.Currency(12), es.CurrencyPerTick())
+	}
+	if bad := (market.Ticker{Name: "X", TickValue: -1}); !errors.Is(bad.Validate(), market.ErrInvalidTickValue) {
+		t.Errorf("expected a negative tick value rejected, got %v", bad.Validate())
+	}
+}
+
+func TestFillPnLDetectsOverflow(t *testing.T) {
+	buy := marketview.Fill{Side: core.SideBuy, Price: 100, Size: 2}
+	if pnl, err := FillPnL(buy, 103); err != nil || pnl != 6 {
+		t.Errorf("expected 6, got %d, %v", pnl, err)
+	}
+	if pnl, err := FillPnL(marketview.Fill{Side: core.SideSell, Price: 100, Size: 2}, 103); err != nil || pnl != -6 {
+		t.Errorf("expected -6, got %d, %v", pnl, err)
+	}
+
+	// The largest size that fits at a 3 tick move, and one more
+	buy.Size = core.Size(math.MaxInt64 / 3)
+	if pnl, err := FillPnL(buy, 103); err != nil || pnl != 3*int64(buy.Size) {
+		t.Errorf("expected %d, got %d, %v", 3*int64(buy.Size), pnl, err)
+	}
+	buy.Size++
+	if _, err := FillPnL(buy, 103); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+	// The mark difference itself overflows
+	deep := marketview.Fill{Side: core.SideSell, Price: math.MaxInt64, Size: 1}
+	if _, err := FillPnL(deep, -10); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("expected ErrOverflow on the price difference, got %v", err)
+	}
+}
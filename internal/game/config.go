@@ -7,6 +7,7 @@ import (
 	"github.com/zappabad/stockcraft/internal/market"
 	marketservice "github.com/zappabad/stockcraft/internal/market/service"
 	newsservice "github.com/zappabad/stockcraft/internal/news/service"
+	"github.com/zappabad/stockcraft/internal/objective"
 	"github.com/zappabad/stockcraft/internal/trader/runner"
 )
 
@@ -22,8 +23,22 @@ type Config struct {
 	BrokerConfig brokerservice.Config
 	// TraderConfigs is the configuration for each trader runner.
 	TraderConfigs []runner.Config
+	// Population is the mix of strategy traders to spawn after the
+	// TraderConfigs traders (see BuildPopulation).
+	Population []PopulationGroup
 	// EnableBroker determines whether the broker service is enabled.
 	EnableBroker bool
+	// FlattenOnClose makes Close flatten every registered user's positions
+	// to market before tearing down services, so end-of-session P&L is
+	// realized.
+	FlattenOnClose bool
+	// Objectives are the player's goals, evaluated by StartObjectives.
+	Objectives []objective.Objective
+	// StartingEquity is the player's equity before any fills, in currency
+	// units.
+	StartingEquity float64
+	// ObjectiveConfig is the configuration for objective evaluation.
+	ObjectiveConfig objective.Config
 }
 
 // DefaultConfig returns a Config with reasonable defaults.
@@ -44,6 +59,8 @@ func DefaultConfig() Config {
 				DropEvents:   true,
 			},
 		},
-		EnableBroker: true,
+		EnableBroker:    true,
+		StartingEquity:  100000,
+		ObjectiveConfig: objective.DefaultConfig(),
 	}
 }
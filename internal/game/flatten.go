@@ -0,0 +1,129 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// FlattenOrder is one closing order placed by Flatten.
+type FlattenOrder struct {
+	Ticker   market.TickerID
+	UserID   core.UserID
+	Side     core.Side
+	Position core.Size // signed position before the order: long > 0
+	Filled   core.Size
+	Unfilled core.Size // not filled for lack of liquidity
+}
+
+// FlattenReport summarizes a Flatten.
+type FlattenReport struct {
+	Orders         []FlattenOrder // by ticker, then user
+	CanceledOrders int
+}
+
+// Position returns the user's net position on a ticker over every trade
+// (see marketservice.MarketService.UserPosition): buys add, sells subtract.
+func (g *Game) Position(tid market.TickerID, userID core.UserID) (core.Size, error) {
+	return g.Market.UserPosition(tid, userID)
+}
+
+// Flatten closes out the position of every registered user with market
+// orders, realizing their P&L. Each user's resting orders on a ticker are
+// canceled first, so a user flattened earlier cannot be filled again by a
+// later user's closing order. Fills against other registered users adjust
+// their positions before their turn. A position is left partly open if the
+// book runs out of liquidity; the remainder is reported as Unfilled.
+//
+// Positions come from the market view, which trails the books slightly, so
+// stop order flow before calling Flatten.
+func (g *Game) Flatten(ctx context.Context) (FlattenReport, error) {
+	g.rolesMu.RLock()
+	users := make([]core.UserID, 0, len(g.roles))
+	for u := range g.roles {
+		users = append(users, u)
+	}
+	g.rolesMu.RUnlock()
+	sort.Slice(users, func(i, j int) bool { return users[i] < users[j] })
+
+	var report FlattenReport
+	var errs []error
+	for _, t := range g.Market.GetTickers() {
+		if err := g.flattenTicker(ctx, t.TickerID(), users, &report); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+func (g *Game) flattenTicker(ctx context.Context, tid market.TickerID, users []core.UserID, report *FlattenReport) error {
+	positions := make(map[core.UserID]core.Size, len(users))
+	for _, u := range users {
+		pos, err := g.Position(tid, u)
+		if err != nil {
+			return err
+		}
+		positions[u] = pos
+	}
+
+	// Who owns each resting order, to credit fills to makers
+	owners := make(map[core.OrderID]core.UserID)
+	for _, side := range []core.Side{core.SideBuy, core.SideSell} {
+		orders, err := g.Market.GetOrders(tid, side)
+		if err != nil {
+			return err
+		}
+		for _, o := range orders {
+			owners[o.ID] = o.UserID
+		}
+	}
+
+	for _, u := range users {
+		pos := positions[u]
+		if pos == 0 {
+			continue
+		}
+		for id, owner := range owners {
+			if owner != u {
+				continue
+			}
+			delete(owners, id)
+			_, err := g.Market.Cancel(ctx, tid, id)
+			if errors.Is(err, core.ErrNotFound) {
+				continue // filled since the orders were listed
+			}
+			if err != nil {
+				return err
+			}
+			report.CanceledOrders++
+		}
+
+		side, size := core.SideSell, pos
+		if pos < 0 {
+			side, size = core.SideBuy, -pos
+		}
+		sub, err := g.Market.SubmitMarket(ctx, tid, u, side, size)
+		if err != nil {
+			return err
+		}
+		order := FlattenOrder{Ticker: tid, UserID: u, Side: side, Position: pos, Unfilled: sub.Remaining}
+		for _, f := range sub.Fills {
+			order.Filled += f.Size
+			maker, ok := owners[f.MakerOrderID]
+			if !ok {
+				continue
+			}
+			// The maker took the other side
+			if side == core.SideSell {
+				positions[maker] += f.Size
+			} else {
+				positions[maker] -= f.Size
+			}
+		}
+		report.Orders = append(report.Orders, order)
+	}
+	return nil
+}
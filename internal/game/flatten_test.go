@@ -0,0 +1,133 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestCloseFlattensPositions(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tickers = []market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}
+	cfg.TraderConfigs = nil
+	cfg.EnableBroker = false
+	cfg.FlattenOnClose = true
+	g := NewGame(cfg)
+
+	const (
+		momentum core.UserID = 12
+		player   core.UserID = 1000
+		rival    core.UserID = 1001
+		stranger core.UserID = 50 // never registered, so never flattened
+	)
+	g.RegisterUser(momentum, RoleMomentumBot)
+	g.RegisterUser(player, RolePlayer)
+	g.RegisterUser(rival, RoleMarketMaker)
+
+	ctx := context.Background()
+	must := func(_ core.SubmitReport, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	m := g.Market
+	must(m.SubmitLimit(ctx, 1, stranger, core.SideSell, 101, 10))
+	must(m.SubmitLimit(ctx, 1, stranger, core.SideBuy, 99, 10))
+	must(m.SubmitMarket(ctx, 1, player, core.SideBuy, 4)) // player +4
+	must(m.SubmitLimit(ctx, 1, rival, core.SideBuy, 100, 5))
+	must(m.SubmitMarket(ctx, 1, momentum, core.SideSell, 3)) // rival +3, momentum -3
+	must(m.SubmitLimit(ctx, 1, rival, core.SideSell, 102, 5))
+
+	// Wait for view update
+	time.Sleep(50 * time.Millisecond)
+
+	g.Close()
+	report := g.Flattened()
+
+	// Users go in ID order. The player's sell hits the rest of the rival's
+	// bid, which adds to the rival's position before its turn.
+	want := []FlattenOrder{
+		{Ticker: 1, UserID: momentum, Side: core.SideBuy, Position: -3, Filled: 3},
+		{Ticker: 1, UserID: player, Side: core.SideSell, Position: 4, Filled: 4},
+		{Ticker: 1, UserID: rival, Side: core.SideSell, Position: 5, Filled: 5},
+	}
+	if len(report.Orders) != len(want) {
+		t.Fatalf("expected %d closing orders, got %+v", len(want), report.Orders)
+	}
+	for i, w := range want {
+		if report.Orders[i] != w {
+			t.Errorf("order %d: expected %+v, got %+v", i, w, report.Orders[i])
+		}
+	}
+	if report.CanceledOrders != 1 {
+		t.Errorf("expected the rival's resting ask to be canceled, got %d cancels", report.CanceledOrders)
+	}
+}
+
+func TestCloseWithoutFlattenLeavesPositions(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tickers = []market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}
+	cfg.TraderConfigs = nil
+	cfg.EnableBroker = false
+	g := NewGame(cfg)
+	g.RegisterUser(1000, RolePlayer)
+
+	ctx := context.Background()
+	if _, err := g.Market.SubmitLimit(ctx, 1, 50, core.SideSell, 101, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.Market.SubmitMarket(ctx, 1, 1000, core.SideBuy, 4); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	g.Close()
+	if report := g.Flattened(); len(report.Orders) != 0 {
+		t.Errorf("expected no closing orders, got %+v", report.Orders)
+	}
+	if pos, _ := g.Position(1, 1000); pos != 4 {
+		t.Errorf("expected the position left open, got %d", pos)
+	}
+}
+
+func TestFlattenPastFillCapacity(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tickers = []market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}
+	cfg.TraderConfigs = nil
+	cfg.EnableBroker = false
+	cfg.MarketConfig.Synchronous = true
+	cfg.MarketConfig.UserFillCapacity = 3
+	g := NewGame(cfg)
+	defer g.Close()
+	g.RegisterUser(1000, RolePlayer)
+
+	// A long of 10 from ten fills, of which the ring holds three
+	ctx := context.Background()
+	for range 10 {
+		if _, err := g.Market.SubmitLimit(ctx, 1, 50, core.SideSell, 101, 1); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.Market.SubmitMarket(ctx, 1, 1000, core.SideBuy, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := g.Market.SubmitLimit(ctx, 1, 50, core.SideBuy, 99, 20); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := g.Flatten(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := FlattenOrder{Ticker: 1, UserID: 1000, Side: core.SideSell, Position: 10, Filled: 10}
+	if len(report.Orders) != 1 || report.Orders[0] != want {
+		t.Fatalf("expected %+v, got %+v", want, report.Orders)
+	}
+	if pos, _ := g.Position(1, 1000); pos != 0 {
+		t.Errorf("expected the position closed, got %d", pos)
+	}
+}
@@ -1,11 +1,15 @@
 package game
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	brokerservice "github.com/zappabad/stockcraft/internal/broker/service"
 	marketservice "github.com/zappabad/stockcraft/internal/market/service"
 	newsservice "github.com/zappabad/stockcraft/internal/news/service"
+	"github.com/zappabad/stockcraft/internal/objective"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
 	"github.com/zappabad/stockcraft/internal/trader"
 	"github.com/zappabad/stockcraft/internal/trader/runner"
 	"github.com/zappabad/stockcraft/internal/trader/strategy"
@@ -20,17 +24,29 @@ type Game struct {
 
 	cfg Config
 	mu  sync.Mutex
+
+	rolesMu sync.RWMutex
+	roles   map[core.UserID]Role
+
+	evaluators []*objective.Evaluator
+	flattened  FlattenReport
 }
 
-// NewGame creates a new Game with the given configuration.
+// NewGame creates a new Game with the given configuration. It panics if
+// cfg.Population is invalid.
 func NewGame(cfg Config) *Game {
-	g := &Game{cfg: cfg}
+	g := &Game{cfg: cfg, roles: make(map[core.UserID]Role)}
 
 	// Create market service
 	g.Market = marketservice.NewMarketService(cfg.Tickers, cfg.MarketConfig)
 
-	// Create news service
+	// Create news service. News and traders follow the market's clock
+	// unless given their own, so a manual clock drives the whole game.
+	if cfg.NewsConfig.Clock == nil {
+		cfg.NewsConfig.Clock = cfg.MarketConfig.Book.Clock
+	}
 	g.News = newsservice.NewNewsService(cfg.NewsConfig)
+	g.Market.SetNewsPublisher(g.News)
 
 	// Create broker service if enabled
 	if cfg.EnableBroker {
@@ -40,22 +56,15 @@ func NewGame(cfg Config) *Game {
 	// Create traders
 	for i, tcfg := range cfg.TraderConfigs {
 		traderID := trader.TraderID(i + 1)
-		strat := strategy.NewExampleStrategy(traderID)
-
-		r := runner.NewRunner(
-			tcfg,
-			traderID,
-			strat,
-			g.Market, // MarketReader
-			g.News,   // NewsReader
-			g.Market, // OrderSender
-		)
-		g.Traders = append(g.Traders, r)
-
-		// Attach trader events to broker if enabled
-		if g.Broker != nil {
-			g.Broker.AttachTraderEvents(r.Events())
-		}
+		// The example strategy only quotes into the spread
+		g.spawnTrader(tcfg, traderID, strategy.NewExampleStrategy(traderID), RoleLiquidityBot)
+	}
+	population, err := BuildPopulation(cfg.Population, trader.TraderID(len(cfg.TraderConfigs)+1))
+	if err != nil {
+		panic(err)
+	}
+	for _, pt := range population {
+		g.spawnTrader(pt.Runner, pt.ID, pt.Strategy, pt.Kind.Role())
 	}
 
 	// Attach news events to broker if enabled
@@ -66,16 +75,60 @@ func NewGame(cfg Config) *Game {
 	return g
 }
 
+// spawnTrader starts a trader's runner and registers its role.
+func (g *Game) spawnTrader(cfg runner.Config, traderID trader.TraderID, strat strategy.Strategy, role Role) {
+	if cfg.Clock == nil {
+		cfg.Clock = g.cfg.MarketConfig.Book.Clock
+	}
+	r := runner.NewRunner(
+		cfg,
+		traderID,
+		strat,
+		g.Market, // MarketReader
+		g.News,   // NewsReader
+		g.Market, // OrderSender
+	)
+	g.Traders = append(g.Traders, r)
+	g.RegisterUser(core.UserID(traderID), role)
+
+	// Attach trader events to broker if enabled
+	if g.Broker != nil {
+		g.Broker.AttachTraderEvents(r.Events())
+	}
+}
+
+// flattenSettle is how long Close waits after stopping the traders before
+// reading positions for FlattenOnClose.
+const flattenSettle = 50 * time.Millisecond
+
+// Flattened returns the report of the flatten Close ran for FlattenOnClose.
+// Positions it could not close are left as Unfilled; errors are not reported.
+func (g *Game) Flattened() FlattenReport {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.flattened
+}
+
 // Close shuts down all game subsystems in reverse dependency order.
 func (g *Game) Close() {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
-	// Stop traders first
+	// Stop objective checks and traders first
+	for _, ev := range g.evaluators {
+		ev.Close()
+	}
 	for _, t := range g.Traders {
 		t.Close()
 	}
 
+	// Close out positions while the market is still up
+	if g.cfg.FlattenOnClose && g.Market != nil {
+		// Let the market view catch up with the traders' last fills
+		time.Sleep(flattenSettle)
+		g.flattened, _ = g.Flatten(context.Background())
+	}
+
 	// Stop news
 	if g.News != nil {
 		g.News.Close()
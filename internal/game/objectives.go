@@ -0,0 +1,149 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+
+	brokerservice "github.com/zappabad/stockcraft/internal/broker/service"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/objective"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// Objective metric names.
+const (
+	MetricEquity          = "equity"
+	MetricPnL             = "pnl"
+	MetricFills           = "fills"
+	MetricMakerShare      = "maker_share"
+	MetricDrawdown        = "drawdown"
+	MetricPendingRequests = "pending_requests"
+)
+
+// PlayerMetrics returns the objective metrics for one user:
+//
+//   - equity ($): startingEquity plus pnl
+//   - pnl ($): the user's fills marked at each ticker's last trade, at the
+//     ticker's TickValue
+//   - fills: the user's fill count
+//   - maker_share:TICKER (%): the user's maker volume as a share of the
+//     ticker's session volume
+//   - drawdown (%): the largest fall of equity from its peak, over the
+//     readings taken so far
+//   - pending_requests: broker requests awaiting attention; unavailable when
+//     broker is nil
+//
+// Fill metrics count the fills the market view still holds (see
+// marketservice.Config.UserFillCapacity).
+func PlayerMetrics(m *marketservice.MarketService, broker *brokerservice.BrokerService, userID core.UserID, startingEquity float64) *objective.Registry {
+	reg := objective.NewRegistry()
+
+	pnl := func(string) (float64, error) {
+		var total float64
+		var pnlErr error
+		err := eachFill(m, userID, func(t market.Ticker, mark core.PriceTicks, f marketview.Fill) {
+			p, err := FillPnL(f, mark)
+			if err != nil {
+				pnlErr = err
+			}
+			total += t.Currency(p)
+		})
+		return total, errors.Join(err, pnlErr)
+	}
+	equity := func(string) (float64, error) {
+		p, err := pnl("")
+		return startingEquity + p, err
+	}
+	reg.Register(objective.Metric{Name: MetricPnL, Unit: "$", Value: pnl})
+	reg.Register(objective.Metric{Name: MetricEquity, Unit: "$", Value: equity})
+
+	reg.Register(objective.Metric{Name: MetricFills, Value: func(string) (float64, error) {
+		n := 0
+		err := eachFill(m, userID, func(market.Ticker, core.PriceTicks, marketview.Fill) { n++ })
+		return float64(n), err
+	}})
+
+	reg.Register(objective.Metric{Name: MetricMakerShare, Unit: "%", PerTicker: true, Value: func(name string) (float64, error) {
+		t, err := m.Registry().ByName(name)
+		if err != nil {
+			return 0, err
+		}
+		volume := m.Snapshot().ByTicker[t.TickerID()].Volume
+		if volume == 0 {
+			return 0, nil
+		}
+		fills, err := m.UserFills(t.TickerID(), userID, math.MaxInt)
+		if err != nil {
+			return 0, err
+		}
+		var maker core.Size
+		for _, f := range fills {
+			if f.Role == marketview.RoleMaker {
+				maker += f.Size
+			}
+		}
+		return 100 * float64(maker) / float64(volume), nil
+	}})
+
+	var ddMu sync.Mutex
+	var peak, worst float64
+	reg.Register(objective.Metric{Name: MetricDrawdown, Unit: "%", Value: func(string) (float64, error) {
+		e, err := equity("")
+		if err != nil {
+			return 0, err
+		}
+		ddMu.Lock()
+		defer ddMu.Unlock()
+		peak = max(peak, e)
+		if peak > 0 {
+			worst = max(worst, 100*(peak-e)/peak)
+		}
+		return worst, nil
+	}})
+
+	reg.Register(objective.Metric{Name: MetricPendingRequests, Value: func(string) (float64, error) {
+		if broker == nil {
+			return 0, fmt.Errorf("%w: the broker is not enabled", objective.ErrUnavailable)
+		}
+		return float64(len(broker.PendingRequests())), nil
+	}})
+	return reg
+}
+
+// eachFill calls fn for every retained fill of userID with its ticker's last
+// trade price.
+func eachFill(m *marketservice.MarketService, userID core.UserID, fn func(market.Ticker, core.PriceTicks, marketview.Fill)) error {
+	snap := m.Snapshot()
+	for _, t := range m.GetTickers() {
+		bp := snap.ByTicker[t.TickerID()]
+		if !bp.HasLast {
+			continue // no trades, so no fills
+		}
+		fills, err := m.UserFills(t.TickerID(), userID, math.MaxInt)
+		if err != nil {
+			return err
+		}
+		for _, f := range fills {
+			fn(t, bp.LastPrice, f)
+		}
+	}
+	return nil
+}
+
+// StartObjectives starts evaluating Config.Objectives for userID against
+// PlayerMetrics. The evaluator is closed with the game.
+func (g *Game) StartObjectives(userID core.UserID) (*objective.Evaluator, error) {
+	reg := PlayerMetrics(g.Market, g.Broker, userID, g.cfg.StartingEquity)
+	ev, err := objective.NewEvaluator(reg, g.cfg.Objectives, g.cfg.ObjectiveConfig)
+	if err != nil {
+		return nil, err
+	}
+	g.mu.Lock()
+	g.evaluators = append(g.evaluators, ev)
+	g.mu.Unlock()
+	return ev, nil
+}
@@ -0,0 +1,126 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/objective"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestObjectivesResolveAtDeadlines(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	cfg := DefaultConfig()
+	cfg.Tickers = []market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}
+	cfg.TraderConfigs = nil
+	cfg.EnableBroker = false
+	cfg.ObjectiveConfig = objective.Config{Interval: 1000 * time.Hour, Clock: clk}
+	cfg.Objectives = []objective.Objective{
+		{Name: "rich", Expr: "equity >= 100010 at 1h"},
+		{Name: "survive", Expr: "equity >= 99000 at 1h"},
+		{Name: "maker", Expr: "maker_share:AAPL >= 25 by 30m"},
+		{Name: "busy", Expr: "fills >= 3 by 30m"},
+		{Name: "steady", Expr: "drawdown <= 0.01 throughout 1h"},
+		{Name: "calm", Expr: "drawdown <= 1 throughout 1h"},
+		{Name: "queue", Expr: "pending_requests <= 0 throughout 1h"},
+	}
+	g := NewGame(cfg)
+	defer g.Close()
+
+	const (
+		player   core.UserID = 1000
+		maker    core.UserID = 11
+		momentum core.UserID = 12
+	)
+	ev, err := g.StartObjectives(player)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	at := func(elapsed time.Duration) map[string]objective.Status {
+		t.Helper()
+		clk.Advance(elapsed - clk.Now().Sub(time.Unix(0, 0)))
+		ev.Evaluate()
+		out := make(map[string]objective.Status)
+		for _, s := range ev.Statuses() {
+			out[s.Objective.Name] = s
+		}
+		return out
+	}
+	expect := func(statuses map[string]objective.Status, want map[string]objective.State) {
+		t.Helper()
+		for name, state := range want {
+			if got := statuses[name].State; got != state {
+				t.Errorf("%s: expected %s, got %s (%+v)", name, state, got, statuses[name])
+			}
+		}
+	}
+
+	// Before any trading equity is at its starting value and sets the peak
+	s := at(time.Minute)
+	if s["survive"].Value != 100000 {
+		t.Errorf("expected starting equity, got %v", s["survive"].Value)
+	}
+	expect(s, map[string]objective.State{
+		"rich": objective.StatePending, "maker": objective.StatePending,
+		"steady": objective.StatePending, "queue": objective.StateUnavailable,
+	})
+
+	ctx := context.Background()
+	must := func(_ core.SubmitReport, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	m := g.Market
+	must(m.SubmitLimit(ctx, 1, maker, core.SideSell, 100, 1000))
+	must(m.SubmitMarket(ctx, 1, player, core.SideBuy, 1000)) // taker buy 1000@100
+	must(m.SubmitLimit(ctx, 1, player, core.SideBuy, 98, 500))
+	must(m.SubmitMarket(ctx, 1, momentum, core.SideSell, 500)) // maker buy 500@98
+
+	// Wait for view update
+	time.Sleep(50 * time.Millisecond)
+
+	// Marked at 98 the player is down $20: a 0.02% drawdown. Their 500 of
+	// the 1500 traded is a third of the volume.
+	s = at(10 * time.Minute)
+	if v := s["survive"].Value; v != 99980 {
+		t.Errorf("expected equity 99980, got %v", v)
+	}
+	if v := s["maker"].Value; v < 33.3 || v > 33.4 {
+		t.Errorf("expected a third maker share, got %v", v)
+	}
+	expect(s, map[string]objective.State{
+		"maker": objective.StateMet, "busy": objective.StatePending,
+		"steady": objective.StateFailed, "calm": objective.StatePending,
+		"rich": objective.StatePending,
+	})
+
+	s = at(30 * time.Minute)
+	expect(s, map[string]objective.State{"busy": objective.StateFailed, "rich": objective.StatePending})
+	if ev.Done() {
+		t.Error("expected objectives still pending at 30m")
+	}
+
+	s = at(time.Hour)
+	expect(s, map[string]objective.State{
+		"rich": objective.StateFailed, "survive": objective.StateMet,
+		"maker": objective.StateMet, "busy": objective.StateFailed,
+		"steady": objective.StateFailed, "calm": objective.StateMet,
+		"queue": objective.StateUnavailable,
+	})
+	for name, elapsed := range map[string]time.Duration{
+		"rich": time.Hour, "survive": time.Hour, "calm": time.Hour,
+		"maker": 10 * time.Minute, "steady": 10 * time.Minute, "busy": 30 * time.Minute,
+	} {
+		if s[name].Elapsed != elapsed {
+			t.Errorf("%s: expected to resolve at %v, got %v", name, elapsed, s[name].Elapsed)
+		}
+	}
+	if !ev.Done() {
+		t.Error("expected every objective to be resolved")
+	}
+}
@@ -0,0 +1,115 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/zappabad/stockcraft/internal/trader"
+	"github.com/zappabad/stockcraft/internal/trader/runner"
+	"github.com/zappabad/stockcraft/internal/trader/strategy"
+)
+
+var ErrUnknownStrategy = errors.New("unknown strategy")
+
+// StrategyKind is a simulated trading strategy a population can run.
+type StrategyKind uint8
+
+const (
+	// StrategyMarketMaker quotes both sides inside the spread.
+	StrategyMarketMaker StrategyKind = iota
+	// StrategyMomentum follows recent price moves.
+	StrategyMomentum
+	// StrategyMeanReversion fades moves away from the recent average.
+	StrategyMeanReversion
+	// StrategyNews trades on headlines.
+	StrategyNews
+)
+
+func (k StrategyKind) String() string {
+	switch k {
+	case StrategyMarketMaker:
+		return "MARKET_MAKER"
+	case StrategyMomentum:
+		return "MOMENTUM"
+	case StrategyMeanReversion:
+		return "MEAN_REVERSION"
+	case StrategyNews:
+		return "NEWS"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Role returns the role traders running the strategy are registered with.
+func (k StrategyKind) Role() Role {
+	switch k {
+	case StrategyMarketMaker:
+		return RoleMarketMaker
+	case StrategyMomentum:
+		return RoleMomentumBot
+	case StrategyMeanReversion:
+		return RoleMeanReversionBot
+	case StrategyNews:
+		return RoleNewsBot
+	default:
+		return RoleUnknown
+	}
+}
+
+// PopulationGroup is a number of simulated traders running one strategy.
+type PopulationGroup struct {
+	Strategy StrategyKind
+	Count    int
+	// Seed seeds the group: its traders get Seed, Seed+1, ..., skipping
+	// any seed an earlier trader of the population already has.
+	Seed uint64
+	// Runner configures each trader's runner.
+	Runner runner.Config
+}
+
+// PopulationTrader is one trader of a built population.
+type PopulationTrader struct {
+	ID       trader.TraderID
+	Kind     StrategyKind
+	Seed     uint64
+	Strategy strategy.Strategy
+	Runner   runner.Config
+}
+
+// BuildPopulation instantiates the strategies of each group in order,
+// numbering the traders from first. Every trader gets a distinct seed, so
+// the same groups always build the same population.
+func BuildPopulation(groups []PopulationGroup, first trader.TraderID) ([]PopulationTrader, error) {
+	var out []PopulationTrader
+	used := make(map[uint64]bool)
+	id := first
+	for i, grp := range groups {
+		if grp.Count < 0 {
+			return nil, fmt.Errorf("game: population group %d: negative count %d", i, grp.Count)
+		}
+		seed := grp.Seed
+		for range grp.Count {
+			for used[seed] {
+				seed++
+			}
+			used[seed] = true
+
+			var strat strategy.Strategy
+			switch grp.Strategy {
+			case StrategyMarketMaker:
+				strat = strategy.NewMarketMaker(id, seed)
+			case StrategyMomentum:
+				strat = strategy.NewMomentum(id, seed)
+			case StrategyMeanReversion:
+				strat = strategy.NewMeanReversion(id, seed)
+			case StrategyNews:
+				strat = strategy.NewNewsTrader(id, seed)
+			default:
+				return nil, fmt.Errorf("game: population group %d: %w %d", i, ErrUnknownStrategy, grp.Strategy)
+			}
+			out = append(out, PopulationTrader{ID: id, Kind: grp.Strategy, Seed: seed, Strategy: strat, Runner: grp.Runner})
+			id++
+		}
+	}
+	return out, nil
+}
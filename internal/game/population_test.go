@@ -0,0 +1,91 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/trader/runner"
+	"github.com/zappabad/stockcraft/internal/trader/strategy"
+)
+
+func TestBuildPopulationCountsAndSeeds(t *testing.T) {
+	// The momentum and news groups overlap the market makers' seeds
+	groups := []PopulationGroup{
+		{Strategy: StrategyMarketMaker, Count: 3, Seed: 10},
+		{Strategy: StrategyMomentum, Count: 2, Seed: 11},
+		{Strategy: StrategyMeanReversion, Count: 1, Seed: 100},
+		{Strategy: StrategyNews, Count: 4, Seed: 10},
+	}
+	pop, err := BuildPopulation(groups, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pop) != 10 {
+		t.Fatalf("expected 10 traders, got %d", len(pop))
+	}
+
+	counts := make(map[StrategyKind]int)
+	seeds := make(map[uint64]bool)
+	for i, pt := range pop {
+		if want := 5 + i; int(pt.ID) != want {
+			t.Errorf("trader %d: ID %d, want %d", i, pt.ID, want)
+		}
+		if seeds[pt.Seed] {
+			t.Errorf("trader %d reuses seed %d", pt.ID, pt.Seed)
+		}
+		seeds[pt.Seed] = true
+		counts[pt.Kind]++
+
+		var ok bool
+		switch pt.Kind {
+		case StrategyMarketMaker:
+			_, ok = pt.Strategy.(*strategy.MarketMaker)
+		case StrategyMomentum:
+			_, ok = pt.Strategy.(*strategy.Momentum)
+		case StrategyMeanReversion:
+			_, ok = pt.Strategy.(*strategy.MeanReversion)
+		case StrategyNews:
+			_, ok = pt.Strategy.(*strategy.NewsTrader)
+		}
+		if !ok {
+			t.Errorf("trader %d: %v group built %T", pt.ID, pt.Kind, pt.Strategy)
+		}
+	}
+	for _, grp := range groups {
+		if counts[grp.Strategy] != grp.Count {
+			t.Errorf("%v: %d traders, want %d", grp.Strategy, counts[grp.Strategy], grp.Count)
+		}
+	}
+
+	if _, err := BuildPopulation([]PopulationGroup{{Strategy: 99, Count: 1}}, 1); !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("expected ErrUnknownStrategy, got %v", err)
+	}
+}
+
+func TestNewGameSpawnsPopulation(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Tickers = []market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}
+	cfg.EnableBroker = false
+	idle := runner.Config{TickInterval: time.Hour}
+	cfg.TraderConfigs = []runner.Config{idle}
+	cfg.Population = []PopulationGroup{
+		{Strategy: StrategyMomentum, Count: 2, Runner: idle},
+		{Strategy: StrategyNews, Count: 1, Runner: idle},
+	}
+	g := NewGame(cfg)
+	defer g.Close()
+
+	if len(g.Traders) != 4 {
+		t.Fatalf("expected 4 traders, got %d", len(g.Traders))
+	}
+	// The population is numbered after the configured traders
+	want := []Role{RoleLiquidityBot, RoleMomentumBot, RoleMomentumBot, RoleNewsBot}
+	for i, role := range want {
+		if got := g.UserRole(core.UserID(i + 1)); got != role {
+			t.Errorf("user %d: role %v, want %v", i+1, got, role)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package game
+
+import "github.com/zappabad/stockcraft/internal/orderbook/core"
+
+// Role is the kind of participant behind a UserID.
+type Role uint8
+
+const (
+	// RoleUnknown is any user the game did not register.
+	RoleUnknown Role = iota
+	// RoleLiquidityBot is a simulated trader that quotes into the spread.
+	RoleLiquidityBot
+	// RoleMarketMaker is a simulated two-sided market maker.
+	RoleMarketMaker
+	// RoleMomentumBot is a simulated trader that follows price moves.
+	RoleMomentumBot
+	// RolePlayer is a human player account.
+	RolePlayer
+	// RoleMeanReversionBot is a simulated trader that fades moves away from
+	// the recent average.
+	RoleMeanReversionBot
+	// RoleNewsBot is a simulated trader that reacts to headlines.
+	RoleNewsBot
+)
+
+// Roles lists every role in report order.
+var Roles = []Role{RoleLiquidityBot, RoleMarketMaker, RoleMomentumBot, RoleMeanReversionBot, RoleNewsBot, RolePlayer, RoleUnknown}
+
+func (r Role) String() string {
+	switch r {
+	case RoleLiquidityBot:
+		return "LIQUIDITY_BOT"
+	case RoleMarketMaker:
+		return "MARKET_MAKER"
+	case RoleMomentumBot:
+		return "MOMENTUM_BOT"
+	case RoleMeanReversionBot:
+		return "MEAN_REVERSION_BOT"
+	case RoleNewsBot:
+		return "NEWS_BOT"
+	case RolePlayer:
+		return "PLAYER"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// RegisterUser records the role of a user. NewGame registers the traders it
+// spawns; callers register player accounts and any traders they run
+// themselves. Registering a user again replaces its role.
+func (g *Game) RegisterUser(userID core.UserID, role Role) {
+	g.rolesMu.Lock()
+	defer g.rolesMu.Unlock()
+	g.roles[userID] = role
+}
+
+// UserRole returns the registered role of a user, or RoleUnknown.
+func (g *Game) UserRole(userID core.UserID) Role {
+	g.rolesMu.RLock()
+	defer g.rolesMu.RUnlock()
+	return g.roles[userID]
+}
@@ -0,0 +1,141 @@
+package game
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/orderbook/codec"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// saveVersion is the checkpoint format Save writes and Load accepts.
+const saveVersion = 2
+
+// savedGame is the checkpoint document.
+type savedGame struct {
+	Version int              `json:"version"`
+	Tickers []savedBook      `json:"tickers"`
+	View    marketview.State `json:"view"`
+	News    []news.NewsItem  `json:"news"`
+	Roles   []savedRole      `json:"roles"`
+}
+
+// savedBook is a ticker's resting orders, each a codec rested-event line in
+// time priority, and the last order ID its book assigned.
+type savedBook struct {
+	Ticker      market.TickerID   `json:"ticker"`
+	LastOrderID core.OrderID      `json:"last_order_id"`
+	Orders      []json.RawMessage `json:"orders"`
+}
+
+type savedRole struct {
+	User core.UserID `json:"user"`
+	Role Role        `json:"role"`
+}
+
+// Save writes a checkpoint of the game: every ticker's resting orders, the
+// session totals, positions and fills that P&L and equity derive from, the news
+// tape and the registered roles. The trade tape, BBO history, candles,
+// trading status and objectives are not saved, and an iceberg is saved as
+// its displayed slice only, as its reserve is not in the book view. For an
+// exact checkpoint, save while nothing is trading, e.g. with the traders
+// stopped.
+func (g *Game) Save(w io.Writer) error {
+	doc := savedGame{Version: saveVersion}
+
+	for _, t := range g.Market.GetTickers() {
+		tid := t.TickerID()
+		last, err := g.Market.LastOrderID(tid)
+		if err != nil {
+			return err
+		}
+		book := savedBook{Ticker: tid, LastOrderID: last}
+		for _, side := range []core.Side{core.SideBuy, core.SideSell} {
+			orders, err := g.Market.GetOrders(tid, side)
+			if err != nil {
+				return err
+			}
+			for _, o := range orders {
+				line, err := codec.Marshal(core.OrderRestedEvent{
+					OrderID: o.ID, UserID: o.UserID, Side: o.Side,
+					Price: o.Price, Size: o.Size, Time: o.Time,
+				})
+				if err != nil {
+					return err
+				}
+				book.Orders = append(book.Orders, line)
+			}
+		}
+		doc.Tickers = append(doc.Tickers, book)
+	}
+
+	doc.View = g.Market.ViewState()
+	doc.News = g.News.Latest(math.MaxInt)
+
+	g.rolesMu.RLock()
+	for user, role := range g.roles {
+		doc.Roles = append(doc.Roles, savedRole{User: user, Role: role})
+	}
+	g.rolesMu.RUnlock()
+	slices.SortFunc(doc.Roles, func(a, b savedRole) int { return cmp.Compare(a.User, b.User) })
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// Load resumes a checkpoint written by Save into a freshly created game
+// with the same tickers, before anything trades or publishes news. Restored
+// orders keep their IDs and times and new orders are numbered after the
+// saved ones, so snapshots, positions and equity match the saved game.
+func (g *Game) Load(r io.Reader) error {
+	var doc savedGame
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return fmt.Errorf("game: load: %w", err)
+	}
+	if doc.Version != saveVersion {
+		return fmt.Errorf("game: load: unsupported checkpoint version %d", doc.Version)
+	}
+
+	// Totals first: resting the orders only moves LastUpdateTime forward
+	g.Market.RestoreViewState(doc.View)
+
+	ctx := context.Background()
+	for _, book := range doc.Tickers {
+		orders := make([]core.Order, 0, len(book.Orders))
+		for _, line := range book.Orders {
+			ev, err := codec.Unmarshal(line)
+			if err != nil {
+				return fmt.Errorf("game: load ticker %d: %w", book.Ticker, err)
+			}
+			e, ok := ev.(core.OrderRestedEvent)
+			if !ok {
+				return fmt.Errorf("game: load ticker %d: unexpected %T", book.Ticker, ev)
+			}
+			orders = append(orders, core.Order{
+				ID: e.OrderID, UserID: e.UserID, Side: e.Side, Kind: core.OrderKindLimit,
+				Price: e.Price, Size: e.Size, Time: e.Time,
+			})
+		}
+		if _, err := g.Market.RestoreOrders(ctx, book.Ticker, orders); err != nil {
+			return fmt.Errorf("game: load ticker %d: %w", book.Ticker, err)
+		}
+		if err := g.Market.ReserveOrderIDs(book.Ticker, book.LastOrderID); err != nil {
+			return fmt.Errorf("game: load ticker %d: %w", book.Ticker, err)
+		}
+	}
+
+	g.News.Restore(doc.News)
+	for _, sr := range doc.Roles {
+		g.RegisterUser(sr.User, sr.Role)
+	}
+	return nil
+}
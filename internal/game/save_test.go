@@ -0,0 +1,119 @@
+package game
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestSaveLoadReproducesGame(t *testing.T) {
+	newGame := func() *Game {
+		cfg := DefaultConfig()
+		cfg.Tickers = []market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}, {ID: 2, Name: "MSFT", Decimals: 2}}
+		cfg.MarketConfig.Synchronous = true
+		cfg.TraderConfigs = nil
+		cfg.EnableBroker = false
+		g := NewGame(cfg)
+		t.Cleanup(g.Close)
+		return g
+	}
+
+	const (
+		player core.UserID = 1000
+		maker  core.UserID = 1001
+	)
+	g := newGame()
+	g.RegisterUser(player, RolePlayer)
+	g.RegisterUser(maker, RoleMarketMaker)
+
+	ctx := context.Background()
+	must := func(_ core.SubmitReport, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	m := g.Market
+	must(m.SubmitLimit(ctx, 1, maker, core.SideSell, 101, 10))
+	must(m.SubmitLimit(ctx, 1, maker, core.SideSell, 101, 5))
+	must(m.SubmitLimit(ctx, 1, maker, core.SideBuy, 99, 10))
+	must(m.SubmitMarket(ctx, 1, player, core.SideBuy, 12)) // player +12 @ 101
+	must(m.SubmitLimit(ctx, 2, maker, core.SideBuy, 250, 8))
+	must(m.SubmitMarket(ctx, 2, player, core.SideSell, 3)) // player -3 @ 250
+	g.News.Publish(news.NewsItem{ID: 7, Time: 42, Ticker: 1, Headline: "AAPL beats"})
+	waitForNews(t, g, 1)
+
+	var buf bytes.Buffer
+	if err := g.Save(&buf); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	loaded := newGame()
+	if err := loaded.Load(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	if got, want := loaded.Market.Snapshot(), g.Market.Snapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("snapshots differ:\n got %+v\nwant %+v", got, want)
+	}
+	for _, tid := range []market.TickerID{1, 2} {
+		for _, side := range []core.Side{core.SideBuy, core.SideSell} {
+			got, _ := loaded.Market.GetOrders(tid, side)
+			want, _ := g.Market.GetOrders(tid, side)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("ticker %d %v orders differ:\n got %+v\nwant %+v", tid, side, got, want)
+			}
+		}
+		for _, user := range []core.UserID{player, maker} {
+			got, _ := loaded.Position(tid, user)
+			want, _ := g.Position(tid, user)
+			if got != want {
+				t.Errorf("ticker %d user %d: position %d, want %d", tid, user, got, want)
+			}
+		}
+	}
+	equity := func(g *Game) float64 {
+		metric, _ := PlayerMetrics(g.Market, nil, player, 100000).Lookup(MetricEquity)
+		v, err := metric.Value("")
+		if err != nil {
+			t.Fatalf("equity: %v", err)
+		}
+		return v
+	}
+	if got, want := equity(loaded), equity(g); got != want {
+		t.Errorf("equity %v, want %v", got, want)
+	}
+	if got, want := loaded.News.Latest(10), g.News.Latest(10); !reflect.DeepEqual(got, want) {
+		t.Errorf("news differs:\n got %+v\nwant %+v", got, want)
+	}
+	if role := loaded.UserRole(maker); role != RoleMarketMaker {
+		t.Errorf("expected the maker's role restored, got %v", role)
+	}
+
+	// New orders are numbered after every saved one
+	last, _ := g.Market.LastOrderID(1)
+	report, err := loaded.Market.SubmitLimit(ctx, 1, maker, core.SideBuy, 98, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.OrderID <= last {
+		t.Errorf("order ID %d after load reuses the saved range (last %d)", report.OrderID, last)
+	}
+}
+
+// waitForNews waits until the game's news tape holds n items.
+func waitForNews(t *testing.T, g *Game, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for len(g.News.Latest(n)) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("news tape never reached %d items", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
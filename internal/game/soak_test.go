@@ -0,0 +1,112 @@
+package game
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/displayid"
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/stats"
+)
+
+// TestSoakMemoryPlateaus trades hundreds of tickers through several simulated
+// hours, rotating through more taker accounts than the fill log cap holds,
+// and checks the Go heap levels off once every bounded accumulator has
+// filled. Positions are kept for every account that trades, so the takers
+// are a fixed pool rather than a new account every round.
+func TestSoakMemoryPlateaus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("soak test")
+	}
+	const (
+		numTickers = 200
+		numTakers  = 4                // 4 × 200 logs against a cap of 400
+		phase      = 150              // rounds between heap samples
+		step       = 30 * time.Second // 4 phases ≈ 5 simulated hours
+	)
+
+	cfg := DefaultConfig()
+	cfg.Tickers = nil
+	for i := 1; i <= numTickers; i++ {
+		cfg.Tickers = append(cfg.Tickers, market.Ticker{ID: int64(i), Name: fmt.Sprintf("T%03d", i), Decimals: 2})
+	}
+	cfg.TraderConfigs = nil
+	cfg.EnableBroker = false
+	cfg.MarketConfig.Book.TradeTapeSize = 50
+	cfg.MarketConfig.UserFillCapacity = 20
+	cfg.MarketConfig.MaxUserFillLogs = 2 * numTickers
+	cfg.MarketConfig.BBOHistoryCapacity = 64
+	g := NewGame(cfg)
+	defer g.Close()
+
+	clk := clock.NewManual(time.Unix(0, 0))
+	ids := displayid.NewAllocator(displayid.Config{Retention: 10 * time.Minute, Clock: clk})
+	bbo := stats.NewBBOHistory(64, time.Second)
+
+	ctx := context.Background()
+	round := 0
+	run := func(n int) {
+		for end := round + n; round < end; round++ {
+			taker := core.UserID(10000 + round%numTakers)
+			for _, tk := range cfg.Tickers {
+				tid := tk.TickerID()
+				price := core.PriceTicks(10000 + round%50)
+
+				ask, err := g.Market.SubmitLimit(ctx, tid, 1, core.SideSell, price, 5)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				ids.Assign(ask.OrderID, tid)
+				if _, err := g.Market.SubmitMarket(ctx, tid, taker, core.SideBuy, 5); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				ids.MarkClosed(ask.OrderID)
+
+				bid, err := g.Market.SubmitLimit(ctx, tid, 2, core.SideBuy, price-10, 3)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if _, err := g.Market.Cancel(ctx, tid, bid.OrderID); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				bbo.Record(tid, stats.BBOSample{
+					Time:     clk.Now().UnixNano(),
+					BidPrice: price - 10, BidOK: true,
+					AskPrice: price, AskOK: true,
+				})
+			}
+			g.News.Publish(news.NewsItem{Headline: fmt.Sprintf("round %d", round)})
+			clk.Advance(step)
+		}
+	}
+	heap := func() uint64 {
+		time.Sleep(50 * time.Millisecond) // let the views drain
+		runtime.GC()
+		runtime.GC()
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		return ms.HeapAlloc
+	}
+
+	run(phase) // fill every ring and reach the fill log cap
+	samples := []uint64{heap()}
+	for i := 0; i < 3; i++ {
+		run(phase)
+		samples = append(samples, heap())
+	}
+	t.Logf("heap after each phase: %v", samples)
+
+	// Linear growth would add about the warm-up's worth per phase. Allow
+	// noise of 10% of the warm heap plus 2 MiB across all later phases.
+	base, last := samples[0], samples[len(samples)-1]
+	if slack := base/10 + 2<<20; last > base+slack {
+		t.Errorf("heap grew from %d to %d bytes after warm-up (slack %d)", base, last, slack)
+	}
+}
@@ -0,0 +1,127 @@
+// Package feed encodes market events for external JSON clients and parses
+// their subscription requests.
+//
+// Each event is one line carrying both the numeric ticker ID and its name:
+//
+//	{"ticker_id":1,"ticker":"AAPL","type":"trade","event":{...}}
+//
+// The type and event fields are the orderbook codec's envelope, plus
+// "status" for trading status changes. Clients subscribe by name or ID:
+//
+//	{"op":"subscribe","tickers":["AAPL","MSFT",3]}
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/codec"
+)
+
+const (
+	// TypeStatus tags trading status events.
+	TypeStatus = "status"
+	// TypeImbalance tags top-of-book imbalance alerts.
+	TypeImbalance = "imbalance"
+)
+
+var ErrBadRequest = errors.New("malformed request")
+
+// Message is one feed line.
+type Message struct {
+	TickerID market.TickerID `json:"ticker_id"`
+	Ticker   string          `json:"ticker"`
+	Type     string          `json:"type"`
+	Event    json.RawMessage `json:"event"`
+	// Seq numbers the line within a Replay; 0 if it was not numbered.
+	Seq uint64 `json:"seq,omitempty"`
+}
+
+// Marshal encodes a market event, naming its ticker from the registry.
+func Marshal(ev marketview.MarketEvent, reg *market.Registry) ([]byte, error) {
+	msg, err := message(ev, reg)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(msg)
+}
+
+func message(ev marketview.MarketEvent, reg *market.Registry) (Message, error) {
+	msg := Message{TickerID: ev.Ticker, Ticker: reg.Name(ev.Ticker)}
+	var err error
+	if ev.Status != nil {
+		msg.Type = TypeStatus
+		msg.Event, err = json.Marshal(ev.Status)
+	} else if ev.Imbalance != nil {
+		msg.Type = TypeImbalance
+		msg.Event, err = json.Marshal(ev.Imbalance)
+	} else if msg.Type, err = codec.TypeOf(ev.Event); err == nil {
+		msg.Event, err = json.Marshal(ev.Event)
+	}
+	return msg, err
+}
+
+// Op is a client request kind.
+type Op string
+
+const (
+	OpSubscribe   Op = "subscribe"
+	OpUnsubscribe Op = "unsubscribe"
+)
+
+// Request is a parsed client request, with ticker references resolved.
+type Request struct {
+	Op      Op
+	Tickers []market.TickerID
+	// LastSeq is the last sequence a reconnecting client saw, for
+	// Replay.Resume; 0 on a fresh connect.
+	LastSeq uint64
+}
+
+// ParseRequest decodes a client request and resolves each ticker, given as
+// a name or an ID, through the registry. Unknown or ambiguous tickers fail
+// the whole request.
+func ParseRequest(data []byte, reg *market.Registry) (Request, error) {
+	var raw struct {
+		Op      Op                `json:"op"`
+		Tickers []json.RawMessage `json:"tickers"`
+		LastSeq uint64            `json:"last_seq"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Request{}, fmt.Errorf("%w: %v", ErrBadRequest, err)
+	}
+	if raw.Op != OpSubscribe && raw.Op != OpUnsubscribe {
+		return Request{}, fmt.Errorf("%w: unknown op %q", ErrBadRequest, raw.Op)
+	}
+
+	req := Request{Op: raw.Op, LastSeq: raw.LastSeq}
+	for _, r := range raw.Tickers {
+		t, err := Resolve(r, reg)
+		if err != nil {
+			return Request{}, err
+		}
+		req.Tickers = append(req.Tickers, t.TickerID())
+	}
+	return req, nil
+}
+
+// Resolve looks up a JSON ticker reference: a string name (or decimal ID),
+// or a number ID.
+func Resolve(r json.RawMessage, reg *market.Registry) (market.Ticker, error) {
+	var ref string
+	if err := json.Unmarshal(r, &ref); err == nil {
+		return reg.Resolve(ref)
+	}
+	var id int64
+	if err := json.Unmarshal(r, &id); err != nil {
+		return market.Ticker{}, fmt.Errorf("%w: ticker %s is neither a name nor an ID", ErrBadRequest, r)
+	}
+	t, ok := reg.Lookup(market.TickerID(id))
+	if !ok {
+		return market.Ticker{}, fmt.Errorf("%w: %d", market.ErrUnknownTicker, id)
+	}
+	return t, nil
+}
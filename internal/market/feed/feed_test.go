@@ -0,0 +1,84 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/codec"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+var reg = market.NewRegistry([]market.Ticker{
+	{ID: 1, Name: "AAPL", Decimals: 2},
+	{ID: 2, Name: "GOOGL", Decimals: 2},
+	{ID: 3, Name: "MSFT", Decimals: 2},
+})
+
+func TestMarshalIncludesTickerName(t *testing.T) {
+	trade := core.TradeEvent{TakerOrderID: 5, MakerOrderID: 4, TakerSide: core.SideBuy, Price: 15025, Size: 10, Time: 1}
+	line, err := Marshal(marketview.MarketEvent{Ticker: 2, Event: trade}, reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.TickerID != 2 || msg.Ticker != "GOOGL" || msg.Type != codec.TypeTrade {
+		t.Errorf("unexpected message %s", line)
+	}
+	var got core.TradeEvent
+	if err := json.Unmarshal(msg.Event, &got); err != nil || got != trade {
+		t.Errorf("expected %+v in the event, got %+v (%v)", trade, got, err)
+	}
+
+	status := &marketview.StatusEvent{Status: market.StatusHalted, Reason: "breaker", Time: 2}
+	line, err = Marshal(marketview.MarketEvent{Ticker: 3, Status: status}, reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := json.Unmarshal(line, &msg); err != nil || msg.Ticker != "MSFT" || msg.Type != TypeStatus {
+		t.Errorf("unexpected status message %s (%v)", line, err)
+	}
+
+	// Tickers missing from the registry are named by ID
+	line, _ = Marshal(marketview.MarketEvent{Ticker: 9, Event: trade}, reg)
+	if err := json.Unmarshal(line, &msg); err != nil || msg.Ticker != "9" {
+		t.Errorf("unexpected message for an unregistered ticker %s", line)
+	}
+}
+
+func TestParseRequest(t *testing.T) {
+	req, err := ParseRequest([]byte(`{"op":"subscribe","tickers":["AAPL","msft",2,"2"]}`), reg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if req.Op != OpSubscribe || !slices.Equal(req.Tickers, []market.TickerID{1, 3, 2, 2}) {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	req, err = ParseRequest([]byte(`{"op":"subscribe","tickers":["AAPL"],"last_seq":41}`), reg)
+	if err != nil || req.LastSeq != 41 {
+		t.Errorf("expected last_seq 41, got %+v (%v)", req, err)
+	}
+
+	tests := []struct {
+		in   string
+		want error
+	}{
+		{`{"op":"subscribe","tickers":["TSLA"]}`, market.ErrUnknownTicker},
+		{`{"op":"subscribe","tickers":[9]}`, market.ErrUnknownTicker},
+		{`{"op":"subscribe","tickers":[true]}`, ErrBadRequest},
+		{`{"op":"publish","tickers":["AAPL"]}`, ErrBadRequest},
+		{`not json`, ErrBadRequest},
+	}
+	for _, tt := range tests {
+		if _, err := ParseRequest([]byte(tt.in), reg); !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.in, tt.want, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package feed
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+)
+
+// TypeSnapshot tags a ticker's best prices, sent instead of a replay when a
+// resuming client's gap is older than the retained history.
+const TypeSnapshot = "snapshot"
+
+// Replay numbers feed lines and keeps the most recent ones, so a client that
+// reconnects with the last sequence it saw resumes without a gap. It is safe
+// for concurrent use.
+type Replay struct {
+	reg *market.Registry
+
+	mu    sync.Mutex
+	lines [][]byte // ring of the last len(lines) lines
+	start int
+	count int
+	seq   uint64 // last sequence assigned
+}
+
+// NewReplay creates a Replay that retains the last capacity lines.
+func NewReplay(reg *market.Registry, capacity int) *Replay {
+	return &Replay{reg: reg, lines: make([][]byte, max(capacity, 1))}
+}
+
+// Append numbers and encodes an event, retains the line and returns it.
+func (r *Replay) Append(ev marketview.MarketEvent) ([]byte, error) {
+	msg, err := message(ev, r.reg)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	msg.Seq = r.seq + 1
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	r.seq = msg.Seq
+
+	size := len(r.lines)
+	if r.count < size {
+		r.lines[(r.start+r.count)%size] = line
+		r.count++
+	} else {
+		// overwrite oldest
+		r.lines[r.start] = line
+		r.start = (r.start + 1) % size
+	}
+	return line, nil
+}
+
+// Seq returns the last sequence assigned.
+func (r *Replay) Seq() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seq
+}
+
+// Since returns the retained lines after seq, oldest first. It returns false
+// when lines after seq have already been overwritten, or seq was never
+// assigned (a client of an earlier run), so replaying would leave a gap.
+func (r *Replay) Since(seq uint64) ([][]byte, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	oldest := r.seq - uint64(r.count) // the sequence just before the ring
+	if seq < oldest || seq > r.seq {
+		return nil, false
+	}
+	n := int(r.seq - seq)
+	out := make([][]byte, n)
+	for i := range out {
+		out[i] = r.lines[(r.start+r.count-n+i)%len(r.lines)]
+	}
+	return out, true
+}
+
+// Snapshot encodes one TypeSnapshot line per ticker in snap, in ticker ID
+// order, each carrying the last sequence assigned. A client starting from a
+// snapshot ignores replayed lines at or below that sequence.
+func (r *Replay) Snapshot(snap marketview.MarketSnapshot) ([][]byte, error) {
+	seq := r.Seq()
+	var out [][]byte
+	for _, tid := range slices.Sorted(maps.Keys(snap.ByTicker)) {
+		body, err := json.Marshal(snap.ByTicker[tid])
+		if err != nil {
+			return nil, err
+		}
+		line, err := json.Marshal(Message{TickerID: tid, Ticker: r.reg.Name(tid), Type: TypeSnapshot, Event: body, Seq: seq})
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, line)
+	}
+	return out, nil
+}
+
+// Resume returns what a client reconnecting after lastSeq needs before the
+// live lines: the lines it missed, or, when they are no longer retained, a
+// fresh snapshot taken from snapshot. fresh reports which it got.
+func (r *Replay) Resume(lastSeq uint64, snapshot func() marketview.MarketSnapshot) (lines [][]byte, fresh bool, err error) {
+	if lines, ok := r.Since(lastSeq); ok {
+		return lines, false, nil
+	}
+	lines, err = r.Snapshot(snapshot())
+	return lines, true, err
+}
@@ -0,0 +1,69 @@
+package feed
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestReplayResume(t *testing.T) {
+	r := NewReplay(reg, 4)
+	for i := 1; i <= 10; i++ {
+		trade := core.TradeEvent{Price: core.PriceTicks(15000 + i), Size: 1, Time: int64(i)}
+		if _, err := r.Append(marketview.MarketEvent{Ticker: 1, Event: trade}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	snapshot := func() marketview.MarketSnapshot {
+		return marketview.MarketSnapshot{ByTicker: map[market.TickerID]marketview.BestPrices{
+			2: {BidPrice: 99, BidOK: true},
+			1: {LastPrice: 15010, HasLast: true},
+		}}
+	}
+	decode := func(line []byte) Message {
+		t.Helper()
+		var msg Message
+		if err := json.Unmarshal(line, &msg); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return msg
+	}
+
+	// A recent sequence replays the missed lines back to back
+	lines, fresh, err := r.Resume(7, snapshot)
+	if err != nil || fresh || len(lines) != 3 {
+		t.Fatalf("expected 3 replayed lines, got %d (fresh %v, %v)", len(lines), fresh, err)
+	}
+	for i, line := range lines {
+		if msg := decode(line); msg.Seq != uint64(8+i) || msg.Type != "trade" {
+			t.Errorf("line %d: expected trade seq %d, got %s", i, 8+i, line)
+		}
+	}
+
+	// The oldest resumable sequence is just before the ring
+	if lines, ok := r.Since(6); !ok || len(lines) != 4 {
+		t.Errorf("expected 4 lines after seq 6, got %d (%v)", len(lines), ok)
+	}
+	if lines, ok := r.Since(10); !ok || len(lines) != 0 {
+		t.Errorf("expected nothing after the latest seq, got %d (%v)", len(lines), ok)
+	}
+
+	// A stale sequence, or one this replay never assigned, gets a snapshot
+	for _, seq := range []uint64{5, 11} {
+		lines, fresh, err = r.Resume(seq, snapshot)
+		if err != nil || !fresh || len(lines) != 2 {
+			t.Fatalf("seq %d: expected a 2-line snapshot, got %d (fresh %v, %v)", seq, len(lines), fresh, err)
+		}
+		first, second := decode(lines[0]), decode(lines[1])
+		if first.Type != TypeSnapshot || first.Ticker != "AAPL" || first.Seq != 10 || second.TickerID != 2 {
+			t.Errorf("seq %d: unexpected snapshot %s %s", seq, lines[0], lines[1])
+		}
+		var bp marketview.BestPrices
+		if err := json.Unmarshal(first.Event, &bp); err != nil || bp.LastPrice != 15010 {
+			t.Errorf("seq %d: expected the AAPL best prices, got %+v (%v)", seq, bp, err)
+		}
+	}
+}
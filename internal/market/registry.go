@@ -0,0 +1,93 @@
+package market
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUnknownTicker   = errors.New("unknown ticker")
+	ErrAmbiguousTicker = errors.New("ticker name is shared by several IDs")
+)
+
+// Registry maps ticker IDs to names and back, for feeds and clients that
+// refer to tickers by name. It is immutable and safe for concurrent use.
+type Registry struct {
+	tickers []Ticker // by ID
+	byID    map[TickerID]Ticker
+	byName  map[string][]TickerID
+}
+
+// NewRegistry indexes a ticker list, such as a market's GetTickers. Later
+// duplicates of an ID are ignored.
+func NewRegistry(tickers []Ticker) *Registry {
+	r := &Registry{
+		byID:   make(map[TickerID]Ticker, len(tickers)),
+		byName: make(map[string][]TickerID, len(tickers)),
+	}
+	for _, t := range tickers {
+		if _, ok := r.byID[t.TickerID()]; ok {
+			continue
+		}
+		r.byID[t.TickerID()] = t
+		r.tickers = append(r.tickers, t)
+	}
+	sort.Slice(r.tickers, func(i, j int) bool { return r.tickers[i].ID < r.tickers[j].ID })
+	for _, t := range r.tickers {
+		key := strings.ToUpper(t.Name)
+		r.byName[key] = append(r.byName[key], t.TickerID())
+	}
+	return r
+}
+
+// Tickers returns the registered tickers sorted by ID.
+func (r *Registry) Tickers() []Ticker {
+	return append([]Ticker(nil), r.tickers...)
+}
+
+// Lookup returns the ticker with the given ID.
+func (r *Registry) Lookup(tid TickerID) (Ticker, bool) {
+	t, ok := r.byID[tid]
+	return t, ok
+}
+
+// Name returns the ticker's name, or its ID in decimal if it is not
+// registered, so a feed always has something to show.
+func (r *Registry) Name(tid TickerID) string {
+	if t, ok := r.byID[tid]; ok {
+		return t.Name
+	}
+	return strconv.FormatInt(int64(tid), 10)
+}
+
+// ByName returns the ticker with the given name, ignoring case.
+func (r *Registry) ByName(name string) (Ticker, error) {
+	ids := r.byName[strings.ToUpper(name)]
+	switch len(ids) {
+	case 0:
+		return Ticker{}, fmt.Errorf("%w: %q", ErrUnknownTicker, name)
+	case 1:
+		return r.byID[ids[0]], nil
+	default:
+		return Ticker{}, fmt.Errorf("%w: %q", ErrAmbiguousTicker, name)
+	}
+}
+
+// Resolve returns the ticker a client reference names: a ticker name, or a
+// decimal ID for clients that still send IDs. Names win, so a ticker named
+// "42" is found by name before ID 42 is tried.
+func (r *Registry) Resolve(ref string) (Ticker, error) {
+	t, err := r.ByName(ref)
+	if !errors.Is(err, ErrUnknownTicker) {
+		return t, err
+	}
+	if id, perr := strconv.ParseInt(ref, 10, 64); perr == nil {
+		if t, ok := r.byID[TickerID(id)]; ok {
+			return t, nil
+		}
+	}
+	return Ticker{}, err
+}
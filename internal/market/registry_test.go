@@ -0,0 +1,45 @@
+package market
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry([]Ticker{
+		{ID: 3, Name: "MSFT", Decimals: 2},
+		{ID: 1, Name: "AAPL", Decimals: 2},
+		{ID: 7, Name: "42", Decimals: 2},
+		{ID: 8, Name: "DUP", Decimals: 2},
+		{ID: 9, Name: "dup", Decimals: 2},
+		{ID: 1, Name: "IGNORED", Decimals: 2},
+	})
+
+	if got := r.Tickers(); len(got) != 5 || got[0].Name != "AAPL" || got[1].Name != "MSFT" {
+		t.Errorf("expected tickers sorted by ID without the duplicate ID, got %+v", got)
+	}
+	if r.Name(3) != "MSFT" || r.Name(99) != "99" {
+		t.Errorf("unexpected names %q, %q", r.Name(3), r.Name(99))
+	}
+
+	tests := []struct {
+		ref  string
+		want int64
+		err  error
+	}{
+		{"AAPL", 1, nil},
+		{"aapl", 1, nil},
+		{"3", 3, nil},
+		{"42", 7, nil}, // names win over IDs
+		{"7", 7, nil},
+		{"DUP", 0, ErrAmbiguousTicker},
+		{"NOPE", 0, ErrUnknownTicker},
+		{"99", 0, ErrUnknownTicker},
+	}
+	for _, tt := range tests {
+		got, err := r.Resolve(tt.ref)
+		if !errors.Is(err, tt.err) || got.ID != tt.want {
+			t.Errorf("Resolve(%q) = %d, %v; want %d, %v", tt.ref, got.ID, err, tt.want, tt.err)
+		}
+	}
+}
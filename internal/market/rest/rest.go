@@ -0,0 +1,201 @@
+// Package rest serves a minimal HTTP control API for scripting orders
+// against a market:
+//
+//	POST   /order            {"ticker":"AAPL","side":"buy","type":"limit","price":150.25,"size":10,"user":1000}
+//	DELETE /order/{id}?ticker=AAPL
+//
+// Tickers are given by name or ID and prices as decimals in the ticker's
+// precision. A successful call returns the submit or cancel report as JSON;
+// a rejection returns {"code","field","message"} with an error status.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/order"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// Codes for rejections that come from the transport or the market rather
+// than order validation.
+const (
+	// CodeBadRequest rejects a body or path that does not parse.
+	CodeBadRequest order.Code = "BAD_REQUEST"
+	// CodeOrderNotFound rejects a cancel of an order not on the book.
+	CodeOrderNotFound order.Code = "ORDER_NOT_FOUND"
+	// CodeNotTrading rejects an order the ticker cannot take right now,
+	// e.g. while halted.
+	CodeNotTrading order.Code = "NOT_TRADING"
+	// CodeRiskLimit rejects an order beyond the user's cash or position.
+	CodeRiskLimit order.Code = "RISK_LIMIT"
+	// CodeInvalidOrder is an order the matching engine refused.
+	CodeInvalidOrder order.Code = "INVALID_ORDER"
+	// CodeInternal is any other failure.
+	CodeInternal order.Code = "INTERNAL"
+)
+
+// Market is the order entry surface the handlers drive. MarketService
+// implements it.
+type Market interface {
+	order.Sender
+	Cancel(ctx context.Context, tid market.TickerID, orderID core.OrderID) (core.CancelReport, error)
+	Registry() *market.Registry
+}
+
+// Handler serves the control API.
+type Handler struct {
+	m   Market
+	mux *http.ServeMux
+}
+
+// NewHandler creates a Handler for a market.
+func NewHandler(m Market) *Handler {
+	h := &Handler{m: m, mux: http.NewServeMux()}
+	h.mux.HandleFunc("POST /order", h.submit)
+	h.mux.HandleFunc("DELETE /order/{id}", h.cancel)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// submitRequest is the POST /order body.
+type submitRequest struct {
+	Ticker string      `json:"ticker"`
+	Side   string      `json:"side"`
+	Type   string      `json:"type"`
+	Price  json.Number `json:"price"`
+	Size   core.Size   `json:"size"`
+	User   core.UserID `json:"user"`
+}
+
+func (h *Handler) submit(w http.ResponseWriter, r *http.Request) {
+	var body submitRequest
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&body); err != nil {
+		writeError(w, reject(CodeBadRequest, "", "malformed request: %v", err))
+		return
+	}
+
+	t, err := h.m.Registry().Resolve(body.Ticker)
+	if err != nil {
+		writeError(w, reject(order.CodeUnknownTicker, "ticker", "%v", err))
+		return
+	}
+	req := order.Request{TickerID: t.TickerID(), UserID: body.User, Size: body.Size}
+	switch strings.ToLower(body.Side) {
+	case "buy":
+		req.Side = core.SideBuy
+	case "sell":
+		req.Side = core.SideSell
+	default:
+		writeError(w, reject(order.CodeInvalidSide, "side", "side must be buy or sell, got %q", body.Side))
+		return
+	}
+	switch strings.ToLower(body.Type) {
+	case "limit":
+		req.Kind = core.OrderKindLimit
+	case "market":
+		req.Kind = core.OrderKindMarket
+	default:
+		writeError(w, reject(order.CodeInvalidKind, "type", "type must be limit or market, got %q", body.Type))
+		return
+	}
+	if body.Price != "" {
+		price, err := money.ParsePrice(body.Price.String(), t.Decimals)
+		if err != nil {
+			writeError(w, reject(order.CodeInvalidPrice, "price", "%v", err))
+			return
+		}
+		req.Price = core.PriceTicks(price)
+	}
+
+	report, err := order.Submit(r.Context(), h.m, t, req)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (h *Handler) cancel(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		writeError(w, reject(CodeBadRequest, "id", "order ID must be a positive integer, got %q", r.PathValue("id")))
+		return
+	}
+	t, err := h.m.Registry().Resolve(r.URL.Query().Get("ticker"))
+	if err != nil {
+		writeError(w, reject(order.CodeUnknownTicker, "ticker", "%v", err))
+		return
+	}
+
+	report, err := h.m.Cancel(r.Context(), t.TickerID(), core.OrderID(id))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+func reject(code order.Code, field, format string, args ...any) *order.Error {
+	return &order.Error{Code: code, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// errorBody is the JSON body of a rejection.
+type errorBody struct {
+	Code    order.Code `json:"code"`
+	Field   string     `json:"field,omitempty"`
+	Message string     `json:"message"`
+}
+
+// writeError maps a rejection to its status: unknown tickers and orders are
+// 404, orders the market cannot take right now or the user's risk limits
+// refuse 409, features the engine lacks 501 and other request errors 400.
+func writeError(w http.ResponseWriter, err error) {
+	var oe *order.Error
+	if errors.As(err, &oe) {
+		status := http.StatusBadRequest
+		switch oe.Code {
+		case order.CodeUnknownTicker:
+			status = http.StatusNotFound
+		case order.CodeUnsupported:
+			status = http.StatusNotImplemented
+		}
+		writeJSON(w, status, errorBody{Code: oe.Code, Field: oe.Field, Message: oe.Message})
+		return
+	}
+
+	status, code := http.StatusInternalServerError, CodeInternal
+	switch {
+	case errors.Is(err, marketservice.ErrUnknownTicker):
+		status, code = http.StatusNotFound, order.CodeUnknownTicker
+	case errors.Is(err, core.ErrNotFound):
+		status, code = http.StatusNotFound, CodeOrderNotFound
+	case errors.Is(err, marketservice.ErrTickerHalted), errors.Is(err, core.ErrAuction):
+		status, code = http.StatusConflict, CodeNotTrading
+	case errors.Is(err, marketservice.ErrInsufficientFunds), errors.Is(err, marketservice.ErrPositionLimit):
+		status, code = http.StatusConflict, CodeRiskLimit
+	case errors.Is(err, core.ErrInvalidOrder):
+		status, code = http.StatusBadRequest, CodeInvalidOrder
+	}
+	writeJSON(w, status, errorBody{Code: code, Message: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/order"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func newTestHandler(t *testing.T) (*Handler, *marketservice.MarketService) {
+	t.Helper()
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	m := marketservice.NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	t.Cleanup(m.Close)
+	return NewHandler(m), m
+}
+
+func serve(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestSubmitLimitAndCancel(t *testing.T) {
+	h, m := newTestHandler(t)
+
+	rec := serve(h, http.MethodPost, "/order", `{"ticker":"aapl","side":"buy","type":"limit","price":150.25,"size":10,"user":1000}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var report core.SubmitReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("decoding report: %v", err)
+	}
+	if !report.Rested || report.RestedSize != 10 || report.OrderID == 0 {
+		t.Fatalf("expected 10 resting, got %+v", report)
+	}
+	orders, _ := m.GetOrders(1, core.SideBuy)
+	if len(orders) != 1 || orders[0].Price != 15025 || orders[0].UserID != 1000 {
+		t.Fatalf("expected a bid of 10 at 150.25 for user 1000, got %+v", orders)
+	}
+
+	target := "/order/" + strconv.FormatInt(int64(report.OrderID), 10) + "?ticker=AAPL"
+	rec = serve(h, http.MethodDelete, target, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 for the cancel, got %d: %s", rec.Code, rec.Body)
+	}
+	var cancel core.CancelReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &cancel); err != nil || cancel.CanceledSize != 10 {
+		t.Errorf("expected 10 canceled, got %+v (%v)", cancel, err)
+	}
+
+	rec = serve(h, http.MethodDelete, target, "")
+	if rec.Code != http.StatusNotFound || !strings.Contains(rec.Body.String(), string(CodeOrderNotFound)) {
+		t.Errorf("expected a 404 canceling again, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestSubmitValidationFailure(t *testing.T) {
+	h, m := newTestHandler(t)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		code   order.Code
+		field  string
+	}{
+		{"zero size", `{"ticker":"AAPL","side":"sell","type":"limit","price":150,"size":0,"user":1000}`, http.StatusBadRequest, order.CodeInvalidSize, "size"},
+		{"too many decimals", `{"ticker":"AAPL","side":"sell","type":"limit","price":150.255,"size":1,"user":1000}`, http.StatusBadRequest, order.CodeInvalidPrice, "price"},
+		{"priced market order", `{"ticker":"AAPL","side":"sell","type":"market","price":150,"size":1,"user":1000}`, http.StatusBadRequest, order.CodeInvalidPrice, "price"},
+		{"unknown side", `{"ticker":"AAPL","side":"short","type":"limit","price":150,"size":1,"user":1000}`, http.StatusBadRequest, order.CodeInvalidSide, "side"},
+		{"unknown ticker", `{"ticker":"TSLA","side":"buy","type":"limit","price":150,"size":1,"user":1000}`, http.StatusNotFound, order.CodeUnknownTicker, "ticker"},
+		{"malformed", `{"ticker":`, http.StatusBadRequest, CodeBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(h, http.MethodPost, "/order", tt.body)
+			if rec.Code != tt.status {
+				t.Fatalf("expected %d, got %d: %s", tt.status, rec.Code, rec.Body)
+			}
+			var body errorBody
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("decoding error: %v", err)
+			}
+			if body.Code != tt.code || body.Field != tt.field {
+				t.Errorf("expected %s on %q, got %+v", tt.code, tt.field, body)
+			}
+		})
+	}
+	if n, _ := m.GetOrderCount(1, core.SideSell); n != 0 {
+		t.Errorf("rejected orders reached the book: %d resting", n)
+	}
+}
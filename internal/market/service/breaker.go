@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// tickerState is the per-ticker trading state guarded by MarketService.statusMu.
+type tickerState struct {
+	status market.TradingStatus
+	// refPrice is the price limit moves are measured from. Zero until set
+	// explicitly or by the first trade of the session.
+	refPrice core.PriceTicks
+	// haltGen counts halts so a pending breaker resume can tell whether the
+	// ticker was halted again (or stopped) since it was scheduled.
+	haltGen uint64
+	// last is the status event that entered the current status; zero while
+	// the ticker has been open since it was added.
+	last marketview.StatusEvent
+	// imbalanced is the heavy side of an imbalance alert still in effect,
+	// or 0 if none is.
+	imbalanced int8
+}
+
+// set changes the status and records the event that changed it.
+func (st *tickerState) set(ev marketview.StatusEvent) {
+	st.status = ev.Status
+	st.last = ev
+}
+
+// SetReferencePrice sets the price the circuit breaker measures moves from,
+// typically the previous close. Without it, the first trade sets the reference.
+func (s *MarketService) SetReferencePrice(tid market.TickerID, price core.PriceTicks) error {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	st, ok := s.states[tid]
+	if !ok {
+		return ErrUnknownTicker
+	}
+	st.refPrice = price
+	return nil
+}
+
+// GetTradingStatus returns the trading status of a ticker.
+func (s *MarketService) GetTradingStatus(tid market.TickerID) (market.TradingStatus, error) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	st, ok := s.states[tid]
+	if !ok {
+		return 0, ErrUnknownTicker
+	}
+	return st.status, nil
+}
+
+// GetTradingState returns the event that put a ticker in its current
+// trading status, with the reason and, for circuit breaker halts, the
+// expected resume time. A ticker that has been open since it was added
+// reports a zero event with StatusOpen.
+func (s *MarketService) GetTradingState(tid market.TickerID) (marketview.StatusEvent, error) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	st, ok := s.states[tid]
+	if !ok {
+		return marketview.StatusEvent{}, ErrUnknownTicker
+	}
+	return st.last, nil
+}
+
+// PriceBand is the range of prices a ticker can trade at without tripping
+// the circuit breaker. The zero PriceBand means there is no band.
+type PriceBand struct {
+	Low, High core.PriceTicks
+}
+
+// Contains reports whether price is inside the band. Every price is inside
+// the zero band.
+func (b PriceBand) Contains(price core.PriceTicks) bool {
+	if b == (PriceBand{}) {
+		return true
+	}
+	return price >= b.Low && price <= b.High
+}
+
+// GetPriceBand returns the circuit breaker band around a ticker's reference
+// price. It is zero if the breaker is disabled or there is no reference
+// price yet.
+func (s *MarketService) GetPriceBand(tid market.TickerID) (PriceBand, error) {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	st, ok := s.states[tid]
+	if !ok {
+		return PriceBand{}, ErrUnknownTicker
+	}
+	limit := s.cfg.CircuitBreaker.LimitPct
+	if limit <= 0 || st.refPrice <= 0 {
+		return PriceBand{}, nil
+	}
+	ref := float64(st.refPrice)
+	return PriceBand{
+		Low:  core.PriceTicks(math.Ceil(ref * (1 - limit/100))),
+		High: core.PriceTicks(math.Floor(ref * (1 + limit/100))),
+	}, nil
+}
+
+// checkTradable returns ErrTickerHalted if the ticker is not accepting new
+// orders (halted, or mid-auction).
+func (s *MarketService) checkTradable(tid market.TickerID) error {
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	if st := s.states[tid]; st != nil && (st.status == market.StatusHalted || st.status == market.StatusAuction) {
+		return ErrTickerHalted
+	}
+	return nil
+}
+
+// checkLimitMove halts the ticker if the trade moved beyond the band around
+// the reference price. Called from the ticker's event forwarder, or in
+// synchronous mode from the book's command.
+func (s *MarketService) checkLimitMove(tid market.TickerID, trade core.TradeEvent) {
+	limit := s.cfg.CircuitBreaker.LimitPct
+	if limit <= 0 {
+		return
+	}
+
+	s.statusMu.Lock()
+	st := s.states[tid]
+	if st.refPrice <= 0 {
+		st.refPrice = trade.Price
+	}
+	if st.status == market.StatusHalted {
+		s.statusMu.Unlock()
+		return
+	}
+	move := float64(trade.Price-st.refPrice) / float64(st.refPrice) * 100
+	if move <= limit && move >= -limit {
+		s.statusMu.Unlock()
+		return
+	}
+	ev := marketview.StatusEvent{
+		Status:   market.StatusHalted,
+		Reason:   fmt.Sprintf("limit move %+.1f%%", move),
+		Time:     trade.Time,
+		ResumeAt: trade.Time + s.cfg.CircuitBreaker.Cooldown.Nanoseconds(),
+	}
+	st.set(ev)
+	st.haltGen++
+	gen := st.haltGen
+	s.statusMu.Unlock()
+
+	// Start the cooldown before the halt is published, so a manual clock
+	// advanced on seeing the halt always reaches the timer.
+	timer := s.clock.NewTimer(s.cfg.CircuitBreaker.Cooldown)
+	s.emit(marketview.MarketEvent{Ticker: tid, Status: &ev})
+	s.publishStatusNews(tid, ev)
+
+	// The forwarder holds a wg slot, so adding here cannot race Close's Wait.
+	// In synchronous mode Close closes the book, which waits for this
+	// command, before it waits.
+	s.wg.Add(1)
+	go s.resumeAfter(tid, gen, trade.Price, timer)
+}
+
+// resumeAfter reopens a halted ticker once the cooldown timer fires,
+// re-centering the band on the price that triggered the halt. It does nothing
+// if the ticker has been halted again or reopened since.
+func (s *MarketService) resumeAfter(tid market.TickerID, gen uint64, price core.PriceTicks, timer clock.Timer) {
+	defer s.wg.Done()
+	defer timer.Stop()
+
+	select {
+	case <-s.closed:
+		return
+	case <-timer.C():
+	}
+
+	s.statusMu.Lock()
+	st := s.states[tid]
+	if st.haltGen != gen || st.status != market.StatusHalted {
+		s.statusMu.Unlock()
+		return
+	}
+	ev := marketview.StatusEvent{
+		Status: market.StatusOpen,
+		Reason: "cooldown elapsed",
+		Time:   s.clock.Now().UnixNano(),
+	}
+	st.set(ev)
+	st.refPrice = price
+	s.statusMu.Unlock()
+
+	// The band moved; repair any cross before trading resumes.
+	if book, ok := s.book(tid); ok {
+		book.ResolveCross(context.Background(), s.cfg.Book.CrossPolicy)
+	}
+
+	s.emit(marketview.MarketEvent{Ticker: tid, Status: &ev})
+	s.publishStatusNews(tid, ev)
+}
+
+// NewsPublisher receives the headlines the market generates about itself.
+// *newsservice.NewsService satisfies it.
+type NewsPublisher interface {
+	Publish(item news.NewsItem)
+}
+
+// SetNewsPublisher reports circuit breaker halts and resumes to p as
+// SourceSystem news, or stops reporting them when p is nil. The impact
+// engine and news traders ignore system news, so a halt headline cannot
+// move the price that tripped it.
+func (s *MarketService) SetNewsPublisher(p NewsPublisher) {
+	s.newsMu.Lock()
+	defer s.newsMu.Unlock()
+	s.news = p
+}
+
+// publishStatusNews publishes a breaker halt or resume as system news.
+func (s *MarketService) publishStatusNews(tid market.TickerID, ev marketview.StatusEvent) {
+	s.newsMu.RLock()
+	pub := s.news
+	s.newsMu.RUnlock()
+	if pub == nil {
+		return
+	}
+	s.booksMu.RLock()
+	name := s.tickers[tid].Name
+	s.booksMu.RUnlock()
+
+	item := news.NewsItem{Time: ev.Time, Ticker: tid, Source: news.SourceSystem}
+	if ev.Status == market.StatusHalted {
+		item.Headline = fmt.Sprintf("%s halted: %s", name, ev.Reason)
+		item.Severity = 1
+	} else {
+		item.Headline = fmt.Sprintf("%s resumes trading: %s", name, ev.Reason)
+	}
+	pub.Publish(item)
+}
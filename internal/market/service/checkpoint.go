@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// RestoreOrders rests orders on a ticker's book without matching, keeping
+// their IDs and times (see orderbookservice.Service.Restore). Trading status
+// is not checked.
+func (s *MarketService) RestoreOrders(ctx context.Context, tid market.TickerID, orders []core.Order) (core.CrossReport, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return core.CrossReport{}, ErrUnknownTicker
+	}
+	return book.Restore(ctx, orders)
+}
+
+// LastOrderID returns the highest order ID a ticker's book has assigned.
+func (s *MarketService) LastOrderID(tid market.TickerID) (core.OrderID, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return 0, ErrUnknownTicker
+	}
+	return book.LastOrderID(), nil
+}
+
+// ReserveOrderIDs makes a ticker's book assign IDs above id from now on.
+func (s *MarketService) ReserveOrderIDs(tid market.TickerID, id core.OrderID) error {
+	book, ok := s.book(tid)
+	if !ok {
+		return ErrUnknownTicker
+	}
+	book.ReserveOrderIDs(id)
+	return nil
+}
+
+// ViewState returns the market view's session totals and fill logs.
+func (s *MarketService) ViewState() marketview.State {
+	return s.mview.Export()
+}
+
+// RestoreViewState replaces the market view's session totals and fill logs,
+// which positions are derived from.
+func (s *MarketService) RestoreViewState(st marketview.State) {
+	s.mview.Restore(st)
+}
@@ -1,6 +1,10 @@
 package service
 
 import (
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
 	orderbookservice "github.com/zappabad/stockcraft/internal/orderbook/service"
 )
 
@@ -8,17 +12,71 @@ import (
 type Config struct {
 	// Book is the configuration for each orderbook service.
 	Book orderbookservice.Config
+	// BookRecorder, if set, returns the recorder for a ticker's book as
+	// it is added, overriding Book.Recorder, so each ticker can log to its
+	// own file. The caller owns and closes the recorders.
+	BookRecorder func(market.Ticker) *orderbookservice.TradeRecorder
+	// MaxTickers caps how many tickers the service manages (0 = unlimited).
+	MaxTickers int
 	// MarketEventBuffer is the size of the consolidated market events channel.
 	MarketEventBuffer int
 	// DropMarketEvents determines whether the market events channel drops on overflow.
 	DropMarketEvents bool
+	// UserFillCapacity is how many fills are kept per user and ticker.
+	UserFillCapacity int
+	// MaxUserFillLogs caps how many (ticker, user) fill logs are kept; the
+	// least recently filled is dropped first (0 = unlimited).
+	MaxUserFillLogs int
+	// BBOHistoryCapacity is how many best bid/offer changes are kept per
+	// ticker for BBOAt.
+	BBOHistoryCapacity int
+	// Candles configures the OHLCV candles kept per ticker for GetCandles.
+	Candles marketview.CandleConfig
+	// CircuitBreaker configures the limit-move halt.
+	CircuitBreaker CircuitBreakerConfig
+	// ImbalanceAlert configures the top-of-book imbalance alert.
+	ImbalanceAlert ImbalanceAlertConfig
+	// SnapshotCache configures the cached book snapshots for new
+	// subscribers.
+	SnapshotCache SnapshotCacheConfig
+	// Synchronous runs every orderbook synchronously (see
+	// orderbookservice.Config.Synchronous) and applies market view updates
+	// on the caller's goroutine, so reads after a call see its effects
+	// without waiting. Market events drop on overflow. Breaker cooldowns
+	// still resume on a timer. Intended for tests only.
+	Synchronous bool
+}
+
+// CircuitBreakerConfig configures the LULD-style halt on a limit move.
+type CircuitBreakerConfig struct {
+	// LimitPct halts a ticker when a trade prints more than this percentage
+	// away from its reference price (0 = disabled).
+	LimitPct float64
+	// Cooldown is how long a halt lasts before trading resumes.
+	Cooldown time.Duration
+}
+
+// ImbalanceAlertConfig configures the top-of-book imbalance alert.
+type ImbalanceAlertConfig struct {
+	// Threshold emits an imbalance event when the absolute imbalance of the
+	// best bid and offer reaches it, between 0 and 1 (0 = disabled). The
+	// alert fires once per crossing and re-arms when the imbalance falls
+	// back below the threshold or flips to the other side.
+	Threshold float64
 }
 
 // DefaultConfig returns a Config with reasonable defaults.
 func DefaultConfig() Config {
 	return Config{
-		Book:              orderbookservice.DefaultConfig(),
-		MarketEventBuffer: 1024,
-		DropMarketEvents:  true,
+		Book:               orderbookservice.DefaultConfig(),
+		MarketEventBuffer:  1024,
+		DropMarketEvents:   true,
+		UserFillCapacity:   200,
+		MaxUserFillLogs:    10000,
+		BBOHistoryCapacity: 4096,
+		Candles:            marketview.DefaultCandleConfig(),
+		CircuitBreaker: CircuitBreakerConfig{
+			Cooldown: 5 * time.Minute,
+		},
 	}
 }
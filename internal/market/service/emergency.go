@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// EmergencyStopReport summarizes an EmergencyStop.
+type EmergencyStopReport struct {
+	Halted         []market.TickerID // every ticker, ascending
+	CanceledOrders int
+	CanceledSize   core.Size
+}
+
+// EmergencyStop halts every ticker and cancels every resting order, emitting
+// the usual removal events. Tickers stay halted, with no breaker cooldown,
+// until they are reopened with OpenSession. Useful for scenario resets.
+func (s *MarketService) EmergencyStop(ctx context.Context) (EmergencyStopReport, error) {
+	books := s.allBooks()
+	tids := make([]market.TickerID, 0, len(books))
+	for tid := range books {
+		tids = append(tids, tid)
+	}
+	sort.Slice(tids, func(i, j int) bool { return tids[i] < tids[j] })
+
+	// Halt first so nothing new rests while the books are cleared.
+	now := s.clock.Now().UnixNano()
+	for _, tid := range tids {
+		ev := marketview.StatusEvent{
+			Status: market.StatusHalted,
+			Reason: "emergency stop",
+			Time:   now,
+		}
+		s.statusMu.Lock()
+		st := s.states[tid]
+		st.set(ev)
+		st.haltGen++
+		s.statusMu.Unlock()
+
+		s.emit(marketview.MarketEvent{Ticker: tid, Status: &ev})
+	}
+
+	report := EmergencyStopReport{Halted: tids}
+	for _, tid := range tids {
+		canceled, err := books[tid].CancelAll(ctx)
+		if err != nil {
+			return report, err
+		}
+		report.CanceledOrders += len(canceled)
+		for _, c := range canceled {
+			report.CanceledSize += c.CanceledSize
+		}
+	}
+	return report, nil
+}
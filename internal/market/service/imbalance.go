@@ -0,0 +1,56 @@
+package service
+
+import (
+	"math"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// checkImbalance emits an imbalance event when the ticker's latest best bid
+// and offer cross the alert threshold. It is edge-triggered: the alert
+// fires once and re-arms when the imbalance falls below the threshold, a
+// side empties, or the heavy side flips.
+func (s *MarketService) checkImbalance(tid market.TickerID) {
+	threshold := s.cfg.ImbalanceAlert.Threshold
+	if threshold <= 0 {
+		return
+	}
+	bbo, ok := s.mview.LastBBO(tid)
+	if !ok {
+		return
+	}
+	imb, ok := bbo.Imbalance()
+
+	var heavy int8
+	if ok && math.Abs(imb) >= threshold {
+		heavy = 1
+		if imb < 0 {
+			heavy = -1
+		}
+	}
+
+	s.statusMu.Lock()
+	st := s.states[tid]
+	prev := st.imbalanced
+	st.imbalanced = heavy
+	s.statusMu.Unlock()
+	if heavy == 0 || heavy == prev {
+		return
+	}
+
+	ev := marketview.ImbalanceEvent{
+		Imbalance: imb,
+		Side:      core.SideBuy,
+		BidPrice:  bbo.BidPrice,
+		BidSize:   bbo.BidSize,
+		AskPrice:  bbo.AskPrice,
+		AskSize:   bbo.AskSize,
+		Time:      bbo.Time,
+	}
+	if heavy < 0 {
+		ev.Side = core.SideSell
+	}
+	s.emit(marketview.MarketEvent{Ticker: tid, Imbalance: &ev})
+}
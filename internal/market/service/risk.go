@@ -0,0 +1,221 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// Risk rejections. A RiskChecker returns one of these, possibly wrapped.
+var (
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	ErrPositionLimit     = errors.New("position limit")
+	// ErrLiquidationCooldown is also an ErrPositionLimit, so callers that map
+	// position limits handle it too.
+	ErrLiquidationCooldown = fmt.Errorf("%w: liquidation cooldown", ErrPositionLimit)
+)
+
+// RiskChecker vets an order before it is sent to the book. The price is 0
+// for a market order. A non-nil error rejects the order unchanged.
+type RiskChecker interface {
+	CheckOrder(userID core.UserID, tid market.TickerID, side core.Side, price core.PriceTicks, size core.Size) error
+}
+
+// SetRiskChecker installs a risk check on SubmitLimit, SubmitLimitTIF,
+// SubmitLimitWith and SubmitMarket, or removes it when rc is nil. Cancels
+// and amends are not checked.
+func (s *MarketService) SetRiskChecker(rc RiskChecker) {
+	s.riskMu.Lock()
+	defer s.riskMu.Unlock()
+	s.risk = rc
+}
+
+// checkRisk runs the installed risk check, if any.
+func (s *MarketService) checkRisk(userID core.UserID, tid market.TickerID, side core.Side, price core.PriceTicks, size core.Size) error {
+	s.riskMu.RLock()
+	rc := s.risk
+	s.riskMu.RUnlock()
+	if rc == nil {
+		return nil
+	}
+	return rc.CheckOrder(userID, tid, side, price, size)
+}
+
+// PortfolioRiskConfig configures PortfolioRisk.
+type PortfolioRiskConfig struct {
+	// StartingCash is each checked user's cash before any fills, in
+	// currency.
+	StartingCash float64
+	// MarginMultiplier allows selling short: the short position an order
+	// leaves, at its price, may be worth up to this multiple of the user's
+	// cash (0 = no shorting).
+	MarginMultiplier float64
+	// Users are the users checked (empty = every user).
+	Users []core.UserID
+	// LiquidationCooldown is how long after Liquidated a user's orders that
+	// would grow their position are rejected (0 = no cooldown).
+	LiquidationCooldown time.Duration
+}
+
+// PortfolioRisk is the default RiskChecker. A user's cash is StartingCash
+// less what their trades bought plus what they sold, and their position is
+// UserPosition; both come from the exact totals of UserTotals, not the fill
+// logs. If a total is not exact, the order is rejected with its error. A buy is rejected with ErrInsufficientFunds when
+// its cost and the user's resting bids on every ticker exceed their cash. A
+// sell is rejected with ErrPositionLimit when it and the user's resting
+// offers exceed their position, beyond what MarginMultiplier allows. A
+// market order is costed at the price it would fill at now. After
+// Liquidated, LiquidationCooldown also blocks orders that grow a position.
+type PortfolioRisk struct {
+	m   *MarketService
+	cfg PortfolioRiskConfig
+
+	mu         sync.Mutex
+	liquidated map[core.UserID]time.Time // end of each user's cooldown
+}
+
+// NewPortfolioRisk returns a PortfolioRisk over a market's fills and books.
+func NewPortfolioRisk(m *MarketService, cfg PortfolioRiskConfig) *PortfolioRisk {
+	cfg.Users = slices.Clone(cfg.Users)
+	return &PortfolioRisk{m: m, cfg: cfg, liquidated: make(map[core.UserID]time.Time)}
+}
+
+// Liquidated records a forced liquidation of a user, starting their
+// LiquidationCooldown on the market's clock. Until it ends, CheckOrder
+// rejects the user's orders that would grow their position on a ticker,
+// counting their resting orders, with ErrLiquidationCooldown; orders that
+// only reduce it are checked as usual.
+func (r *PortfolioRisk) Liquidated(userID core.UserID) {
+	if r.cfg.LiquidationCooldown <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liquidated[userID] = r.m.clock.Now().Add(r.cfg.LiquidationCooldown)
+}
+
+// coolingDown returns when a user's cooldown ends, or false if they are not
+// in one.
+func (r *PortfolioRisk) coolingDown(userID core.UserID) (time.Time, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	until, ok := r.liquidated[userID]
+	if !ok {
+		return time.Time{}, false
+	}
+	if !r.m.clock.Now().Before(until) {
+		delete(r.liquidated, userID)
+		return time.Time{}, false
+	}
+	return until, true
+}
+
+// checkCooldown rejects an order that would grow a cooling-down user's
+// position, taken from the exact totals of UserPosition so that a reducing
+// order is told apart however many fills the user has.
+func (r *PortfolioRisk) checkCooldown(userID core.UserID, tid market.TickerID, side core.Side, size core.Size) error {
+	until, ok := r.coolingDown(userID)
+	if !ok {
+		return nil
+	}
+	pos, err := r.m.UserPosition(tid, userID)
+	if err != nil {
+		return err
+	}
+	exp, err := r.m.GetUserExposure(tid, userID)
+	if err != nil {
+		return err
+	}
+	if side == core.SideBuy && pos < 0 && exp.BuySize+size <= -pos {
+		return nil
+	}
+	if side == core.SideSell && pos > 0 && exp.SellSize+size <= pos {
+		return nil
+	}
+	return fmt.Errorf("%w until %s", ErrLiquidationCooldown, until.Format(time.TimeOnly))
+}
+
+// CheckOrder implements RiskChecker.
+func (r *PortfolioRisk) CheckOrder(userID core.UserID, tid market.TickerID, side core.Side, price core.PriceTicks, size core.Size) error {
+	if len(r.cfg.Users) > 0 && !slices.Contains(r.cfg.Users, userID) {
+		return nil
+	}
+	t, ok := r.m.Registry().Lookup(tid)
+	if !ok {
+		return ErrUnknownTicker
+	}
+	if err := r.checkCooldown(userID, tid, side, size); err != nil {
+		return err
+	}
+	if price == 0 {
+		est, err := r.m.EstimateMarket(tid, side, size)
+		if err != nil {
+			return err
+		}
+		if est.Filled == 0 {
+			return nil // nothing would fill, so nothing is spent or sold
+		}
+		price, size = core.PriceTicks(math.Round(est.AvgPrice)), est.Filled
+	}
+	cash, err := r.Cash(userID)
+	if err != nil {
+		return err
+	}
+
+	if side == core.SideBuy {
+		committed := 0.0
+		for _, other := range r.m.GetTickers() {
+			exp, err := r.m.GetUserExposure(other.TickerID(), userID)
+			if err != nil {
+				return err
+			}
+			committed += other.Currency(exp.BuyNotional)
+		}
+		cost := t.Currency(int64(price) * int64(size))
+		if cost+committed > cash {
+			return fmt.Errorf("%w: order costs %.2f with %.2f already bid, cash is %.2f", ErrInsufficientFunds, cost, committed, cash)
+		}
+		return nil
+	}
+
+	pos, err := r.m.UserPosition(tid, userID)
+	if err != nil {
+		return err
+	}
+	exp, err := r.m.GetUserExposure(tid, userID)
+	if err != nil {
+		return err
+	}
+	left := pos - exp.SellSize - size
+	if left >= 0 {
+		return nil
+	}
+	if r.cfg.MarginMultiplier <= 0 {
+		return fmt.Errorf("%w: selling %d with %d already offered, position is %d", ErrPositionLimit, size, exp.SellSize, pos)
+	}
+	short := t.Currency(int64(price) * int64(-left))
+	if limit := cash * r.cfg.MarginMultiplier; short > limit {
+		return fmt.Errorf("%w: short of %.2f exceeds margin of %.2f", ErrPositionLimit, short, limit)
+	}
+	return nil
+}
+
+// Cash returns a user's cash: StartingCash less what their trades bought,
+// plus what they sold. It returns an error rather than an inexact figure.
+func (r *PortfolioRisk) Cash(userID core.UserID) (float64, error) {
+	cash := r.cfg.StartingCash
+	for _, t := range r.m.GetTickers() {
+		p, err := r.m.UserTotals(t.TickerID(), userID)
+		if err != nil {
+			return 0, fmt.Errorf("cash on %s: %w", t.Name, err)
+		}
+		cash += t.Currency(p.Cash)
+	}
+	return cash, nil
+}
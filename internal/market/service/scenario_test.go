@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// seedOrder is one limit order a scenario rests on the book.
+type seedOrder struct {
+	user  core.UserID
+	side  core.Side
+	price core.PriceTicks
+	size  core.Size
+}
+
+// crossedOpen is pre-open interest that crosses: bids of 10 at 102 and 101
+// against asks of 5 at 99, 10 at 100 and 5 at 103. It uncrosses 15 @ 101,
+// leaving 5 bid at 101 and 5 offered at 103.
+var crossedOpen = []seedOrder{
+	{100, core.SideBuy, 102, 10},
+	{100, core.SideBuy, 101, 10},
+	{200, core.SideSell, 99, 5},
+	{200, core.SideSell, 100, 10},
+	{200, core.SideSell, 103, 5},
+}
+
+// scenario is a synchronous one-ticker market (AAPL, ID 1) that tests drive
+// through a known sequence, so auction, halt and STP tests share setup.
+type scenario struct {
+	t   *testing.T
+	ctx context.Context
+	svc *MarketService
+	tid market.TickerID
+}
+
+// newScenario creates the market. Each hook adjusts the config first, for
+// example to set a circuit breaker band or an STP mode.
+func newScenario(t *testing.T, hooks ...func(*Config)) *scenario {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	for _, h := range hooks {
+		h(&cfg)
+	}
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	t.Cleanup(svc.Close)
+	return &scenario{t: t, ctx: context.Background(), svc: svc, tid: 1}
+}
+
+// preOpen puts the ticker into pre-open and rests the orders, failing the
+// test if any of them matches.
+func (s *scenario) preOpen(orders ...seedOrder) *scenario {
+	s.t.Helper()
+	if err := s.svc.BeginPreOpen(s.ctx, s.tid); err != nil {
+		s.t.Fatalf("pre-open: %v", err)
+	}
+	s.seed(orders...)
+	return s
+}
+
+// seed rests limit orders and fails the test if any of them matches.
+func (s *scenario) seed(orders ...seedOrder) *scenario {
+	s.t.Helper()
+	for _, o := range orders {
+		report, err := s.svc.SubmitLimit(s.ctx, s.tid, o.user, o.side, o.price, o.size)
+		if err != nil {
+			s.t.Fatalf("seeding %+v: %v", o, err)
+		}
+		if len(report.Fills) != 0 {
+			s.t.Fatalf("seeding %+v matched: %+v", o, report.Fills)
+		}
+	}
+	return s
+}
+
+// imbalances takes the imbalance alerts emitted so far, discarding the
+// other market events.
+func (s *scenario) imbalances() []marketview.ImbalanceEvent {
+	var out []marketview.ImbalanceEvent
+	for len(s.svc.Events()) > 0 {
+		if me := <-s.svc.Events(); me.Imbalance != nil {
+			out = append(out, *me.Imbalance)
+		}
+	}
+	return out
+}
+
+// open runs the opening auction and returns its print.
+func (s *scenario) open() core.UncrossReport {
+	s.t.Helper()
+	report, err := s.svc.OpenSession(s.ctx, s.tid)
+	if err != nil {
+		s.t.Fatalf("open: %v", err)
+	}
+	return report
+}
+
+func TestScenarioOpeningAuctionPrint(t *testing.T) {
+	s := newScenario(t, func(cfg *Config) {
+		cfg.CircuitBreaker.LimitPct = 10
+	}).preOpen(crossedOpen...)
+
+	report := s.open()
+	if report.Price != 101 || report.Volume != 15 || report.Trades != 3 {
+		t.Fatalf("expected opening print 15 @ 101 in 3 trades, got %+v", report)
+	}
+	if status, _ := s.svc.GetTradingStatus(s.tid); status != market.StatusOpen {
+		t.Errorf("expected open, got %v", status)
+	}
+	bp := s.svc.Snapshot().ByTicker[s.tid]
+	if bp.LastPrice != 101 || bp.Volume != 15 || bp.BidPrice != 101 || bp.BidSize != 5 || bp.AskPrice != 103 || bp.AskSize != 5 {
+		t.Errorf("unexpected book after the open: %+v", bp)
+	}
+	// The print is the circuit breaker's reference
+	if band, _ := s.svc.GetPriceBand(s.tid); band != (PriceBand{Low: 91, High: 111}) {
+		t.Errorf("expected a 10%% band around 101, got %+v", band)
+	}
+}
+
+func TestScenarioSTPAfterOpen(t *testing.T) {
+	s := newScenario(t, func(cfg *Config) {
+		cfg.Book.SelfTradePrevention = core.STPCancelResting
+	}).preOpen(crossedOpen...)
+	s.open()
+
+	// User 100 still bids 5 at 101; its crossing sell cancels that bid
+	// instead of trading with it
+	report, err := s.svc.SubmitLimit(s.ctx, s.tid, 100, core.SideSell, 101, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.STPCanceled) != 1 || len(report.Fills) != 0 || !report.Rested {
+		t.Errorf("expected the resting bid STP-canceled and the sell to rest, got %+v", report)
+	}
+	if bp := s.svc.Snapshot().ByTicker[s.tid]; bp.BidOK || bp.AskPrice != 101 {
+		t.Errorf("expected no bids and the new ask at 101, got %+v", bp)
+	}
+}
+
+func TestScenarioImbalanceAlertIsEdgeTriggered(t *testing.T) {
+	s := newScenario(t, func(cfg *Config) {
+		cfg.ImbalanceAlert.Threshold = 0.5
+	}).seed(seedOrder{100, core.SideBuy, 99, 10}, seedOrder{200, core.SideSell, 101, 10})
+	if got := s.imbalances(); len(got) != 0 {
+		t.Fatalf("balanced book alerted: %+v", got)
+	}
+
+	// 40 × 10 crosses 0.5 and alerts once; growing to 50 × 10 stays quiet
+	s.seed(seedOrder{100, core.SideBuy, 99, 30})
+	got := s.imbalances()
+	if len(got) != 1 || got[0].Side != core.SideBuy || got[0].BidSize != 40 || got[0].AskSize != 10 || got[0].Imbalance != 0.6 {
+		t.Fatalf("expected one bid-heavy alert at 40 × 10, got %+v", got)
+	}
+	s.seed(seedOrder{100, core.SideBuy, 99, 10})
+	if got := s.imbalances(); len(got) != 0 {
+		t.Fatalf("expected no repeat while still imbalanced, got %+v", got)
+	}
+
+	// Falling back to 50 × 50 re-arms it; 100 × 310 alerts on the offer side
+	s.seed(seedOrder{200, core.SideSell, 101, 40})
+	s.seed(seedOrder{100, core.SideBuy, 99, 50})
+	if got := s.imbalances(); len(got) != 0 {
+		t.Fatalf("expected no alert below the threshold, got %+v", got)
+	}
+	s.seed(seedOrder{200, core.SideSell, 101, 260})
+	if got := s.imbalances(); len(got) != 1 || got[0].Side != core.SideSell || got[0].AskSize != 310 {
+		t.Fatalf("expected one offer-heavy alert, got %+v", got)
+	}
+}
+
+func TestScenarioSnapshotCarriesStatus(t *testing.T) {
+	s := newScenario(t, func(cfg *Config) {
+		cfg.CircuitBreaker = CircuitBreakerConfig{LimitPct: 10, Cooldown: time.Hour}
+	})
+	status := func() market.TradingStatus {
+		return s.svc.Snapshot().ByTicker[s.tid].Status
+	}
+	if got := status(); got != market.StatusOpen {
+		t.Errorf("expected a new ticker open, got %v", got)
+	}
+
+	s.preOpen(crossedOpen...)
+	if got := status(); got != market.StatusPreOpen {
+		t.Errorf("expected pre-open, got %v", got)
+	}
+	s.open()
+	if got := status(); got != market.StatusOpen {
+		t.Errorf("expected open after the auction, got %v", got)
+	}
+
+	// A sweep through the 10% band from the 101 print halts the ticker
+	s.seed(seedOrder{200, core.SideSell, 120, 5})
+	if _, err := s.svc.SubmitMarket(s.ctx, s.tid, 300, core.SideBuy, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := status(); got != market.StatusHalted {
+		t.Errorf("expected halted after the sweep, got %v", got)
+	}
+}
@@ -3,27 +3,56 @@ package service
 import (
 	"context"
 	"errors"
+	"maps"
+	"sort"
 	"sync"
-	"sync/atomic"
+	"time"
 
+	"github.com/zappabad/stockcraft/internal/clock"
 	"github.com/zappabad/stockcraft/internal/market"
 	marketview "github.com/zappabad/stockcraft/internal/market/view"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 	orderbookservice "github.com/zappabad/stockcraft/internal/orderbook/service"
 	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/internal/pubsub"
 )
 
-var ErrUnknownTicker = errors.New("unknown ticker")
+var (
+	ErrUnknownTicker = errors.New("unknown ticker")
+	ErrTickerHalted  = errors.New("ticker halted")
+	ErrNoBBOHistory  = errors.New("no BBO history at time")
+	ErrNoCandles     = errors.New("candle interval not kept")
+
+	ErrDuplicateTicker = errors.New("duplicate ticker")
+	ErrTooManyTickers  = errors.New("too many tickers")
+)
 
 // MarketService manages multiple orderbooks and provides aggregated market data.
 type MarketService struct {
-	cfg     Config
+	cfg   Config
+	mview *marketview.MarketView
+	clock clock.Clock
+
+	// booksMu guards tickers and books, which grow with AddTicker.
+	booksMu sync.RWMutex
 	tickers map[market.TickerID]market.Ticker
 	books   map[market.TickerID]*orderbookservice.Service
-	mview   *marketview.MarketView
 
-	externalEvents chan marketview.MarketEvent
-	droppedEvents  atomic.Int64
+	statusMu sync.Mutex
+	states   map[market.TickerID]*tickerState
+
+	snapshots snapshotCache
+
+	riskMu sync.RWMutex
+	risk   RiskChecker
+
+	newsMu sync.RWMutex
+	news   NewsPublisher
+
+	// bus fans market events out to subscribers; external is the
+	// subscription behind Events.
+	bus      *pubsub.Bus[marketview.MarketEvent]
+	external *pubsub.Subscription[marketview.MarketEvent]
 
 	closed    chan struct{}
 	closeOnce sync.Once
@@ -31,36 +60,148 @@ type MarketService struct {
 }
 
 // NewMarketService creates a new MarketService with the given tickers.
+// It panics if a ticker fails validation or there are more than
+// cfg.MaxTickers.
 func NewMarketService(tickers []market.Ticker, cfg Config) *MarketService {
+	for _, t := range tickers {
+		if err := t.Validate(); err != nil {
+			panic("market service: " + err.Error())
+		}
+	}
+	if cfg.MaxTickers > 0 && len(tickers) > cfg.MaxTickers {
+		panic("market service: " + ErrTooManyTickers.Error())
+	}
 	if cfg.MarketEventBuffer <= 0 {
 		cfg.MarketEventBuffer = DefaultConfig().MarketEventBuffer
 	}
+	if cfg.UserFillCapacity <= 0 {
+		cfg.UserFillCapacity = DefaultConfig().UserFillCapacity
+	}
+	if cfg.BBOHistoryCapacity <= 0 {
+		cfg.BBOHistoryCapacity = DefaultConfig().BBOHistoryCapacity
+	}
 
 	s := &MarketService{
-		cfg:            cfg,
-		tickers:        make(map[market.TickerID]market.Ticker, len(tickers)),
-		books:          make(map[market.TickerID]*orderbookservice.Service, len(tickers)),
-		mview:          marketview.NewMarketView(),
-		externalEvents: make(chan marketview.MarketEvent, cfg.MarketEventBuffer),
-		closed:         make(chan struct{}),
+		cfg:     cfg,
+		tickers: make(map[market.TickerID]market.Ticker, len(tickers)),
+		books:   make(map[market.TickerID]*orderbookservice.Service, len(tickers)),
+		mview: marketview.NewMarketView(marketview.Config{
+			FillCapacity: cfg.UserFillCapacity,
+			MaxFillLogs:  cfg.MaxUserFillLogs,
+			BBOCapacity:  cfg.BBOHistoryCapacity,
+			Candles:      cfg.Candles,
+		}),
+		states:    make(map[market.TickerID]*tickerState, len(tickers)),
+		snapshots: snapshotCache{byTicker: make(map[market.TickerID]BookSnapshot, len(tickers))},
+		bus:       pubsub.New[marketview.MarketEvent](),
+		closed:    make(chan struct{}),
+	}
+	policy := pubsub.Block
+	if cfg.DropMarketEvents || cfg.Synchronous {
+		policy = pubsub.Drop
+	}
+	s.external = s.bus.Subscribe(policy, cfg.MarketEventBuffer)
+	s.clock = cfg.Book.Clock
+	if s.clock == nil {
+		s.clock = clock.Real()
 	}
 
-	// Create orderbook service for each ticker
 	for _, t := range tickers {
-		tid := t.TickerID()
-		s.tickers[tid] = t
-		s.books[tid] = orderbookservice.NewService(cfg.Book)
+		s.addBook(t)
 	}
 
-	// Start event forwarders for each book
-	for tid, book := range s.books {
+	if cfg.SnapshotCache.MaxStaleness > 0 {
 		s.wg.Add(1)
-		go s.runBookEventForwarder(tid, book)
+		go s.runSnapshotRefresher()
 	}
 
 	return s
 }
 
+// AddTicker adds a ticker with an empty orderbook to a running service. It
+// returns ErrDuplicateTicker if the ID is taken and ErrTooManyTickers if the
+// service already has Config.MaxTickers.
+func (s *MarketService) AddTicker(t market.Ticker) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	s.booksMu.Lock()
+	defer s.booksMu.Unlock()
+	select {
+	case <-s.closed:
+		return context.Canceled
+	default:
+	}
+	if _, ok := s.books[t.TickerID()]; ok {
+		return ErrDuplicateTicker
+	}
+	if s.cfg.MaxTickers > 0 && len(s.books) >= s.cfg.MaxTickers {
+		return ErrTooManyTickers
+	}
+	s.addBook(t)
+	return nil
+}
+
+// addBook creates a ticker's orderbook and starts its event forwarder, or in
+// synchronous mode hooks the book's events instead. Callers must hold booksMu
+// or own the service exclusively.
+func (s *MarketService) addBook(t market.Ticker) {
+	tid := t.TickerID()
+	s.statusMu.Lock()
+	s.states[tid] = &tickerState{}
+	s.statusMu.Unlock()
+
+	bookCfg := s.cfg.Book
+	if s.cfg.BookRecorder != nil {
+		bookCfg.Recorder = s.cfg.BookRecorder(t)
+	}
+	// Positions are recorded on the book's dispatcher, which sees every
+	// trade, rather than from its events channel, which can drop them
+	var book *orderbookservice.Service
+	onEvent := bookCfg.OnEvent
+	bookCfg.OnEvent = func(ev core.Event) {
+		if onEvent != nil {
+			onEvent(ev)
+		}
+		if tr, ok := ev.(core.TradeEvent); ok {
+			s.mview.RecordPosition(tid, tr)
+		}
+		if s.cfg.Synchronous {
+			s.handleBookEvent(tid, book, ev)
+		}
+	}
+	if s.cfg.Synchronous {
+		bookCfg.Synchronous = true
+		book = orderbookservice.NewService(bookCfg)
+		s.tickers[tid] = t
+		s.books[tid] = book
+		return
+	}
+
+	book = orderbookservice.NewService(bookCfg)
+	s.tickers[tid] = t
+	s.books[tid] = book
+
+	s.wg.Add(1)
+	go s.runBookEventForwarder(tid, book)
+}
+
+// book returns the orderbook of a ticker.
+func (s *MarketService) book(tid market.TickerID) (*orderbookservice.Service, bool) {
+	s.booksMu.RLock()
+	defer s.booksMu.RUnlock()
+	book, ok := s.books[tid]
+	return book, ok
+}
+
+// allBooks returns a copy of the ticker→orderbook map.
+func (s *MarketService) allBooks() map[market.TickerID]*orderbookservice.Service {
+	s.booksMu.RLock()
+	defer s.booksMu.RUnlock()
+	return maps.Clone(s.books)
+}
+
 func (s *MarketService) runBookEventForwarder(tid market.TickerID, book *orderbookservice.Service) {
 	defer s.wg.Done()
 
@@ -73,108 +214,420 @@ func (s *MarketService) runBookEventForwarder(tid market.TickerID, book *orderbo
 			if !ok {
 				return
 			}
+			s.handleBookEvent(tid, book, ev)
+		}
+	}
+}
 
-			// Update market view
-			s.mview.Apply(tid, ev, book)
+// handleBookEvent applies a book event to the market view, passes it on and
+// checks it against the circuit breaker and the imbalance alert.
+func (s *MarketService) handleBookEvent(tid market.TickerID, book *orderbookservice.Service, ev core.Event) {
+	// Update market view
+	s.mview.Apply(tid, ev, book)
 
-			// Emit to external channel
-			me := marketview.MarketEvent{
-				Ticker: tid,
-				Event:  ev,
-			}
+	// Publish to subscribers
+	s.emit(marketview.MarketEvent{
+		Ticker: tid,
+		Event:  ev,
+	})
 
-			if s.cfg.DropMarketEvents {
-				select {
-				case s.externalEvents <- me:
-				default:
-					s.droppedEvents.Add(1)
-				}
-			} else {
-				select {
-				case s.externalEvents <- me:
-				case <-s.closed:
-					return
-				}
-			}
-		}
+	switch e := ev.(type) {
+	case core.TradeEvent:
+		s.checkLimitMove(tid, e)
+	case core.OrderRestedEvent, core.OrderReducedEvent, core.OrderRemovedEvent, core.OrderRefreshedEvent:
+		s.checkImbalance(tid)
 	}
 }
 
-// SubmitLimit submits a limit order to the specified ticker's orderbook.
+// emit publishes a market event to the subscribers, Events included.
+func (s *MarketService) emit(me marketview.MarketEvent) {
+	s.bus.Publish(me)
+}
+
+// SubmitLimit submits a GTC limit order to the specified ticker's orderbook.
 func (s *MarketService) SubmitLimit(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size) (core.SubmitReport, error) {
-	book, ok := s.books[tid]
+	return s.SubmitLimitTIF(ctx, tid, userID, side, price, size, core.TIFGTC)
+}
+
+// SubmitLimitTIF submits a limit order with a time in force to the specified
+// ticker's orderbook. IOC and FOK orders are rejected with core.ErrAuction
+// during pre-open.
+func (s *MarketService) SubmitLimitTIF(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size, tif core.TimeInForce) (core.SubmitReport, error) {
+	return s.SubmitLimitWith(ctx, tid, userID, side, price, size, orderbookservice.LimitOptions{TIF: tif})
+}
+
+// SubmitLimitWith submits a limit order with options to the specified
+// ticker's orderbook (see orderbookservice.Service.SubmitLimitWith). It is
+// checked like SubmitLimitTIF.
+func (s *MarketService) SubmitLimitWith(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size, opts orderbookservice.LimitOptions) (core.SubmitReport, error) {
+	book, ok := s.book(tid)
 	if !ok {
 		return core.SubmitReport{}, ErrUnknownTicker
 	}
-	return book.SubmitLimit(ctx, userID, side, price, size)
+	if err := s.checkTradable(tid); err != nil {
+		return core.SubmitReport{}, err
+	}
+	if err := s.checkRisk(userID, tid, side, price, size); err != nil {
+		return core.SubmitReport{}, err
+	}
+	return book.SubmitLimitWith(ctx, userID, side, price, size, opts)
 }
 
 // SubmitMarket submits a market order to the specified ticker's orderbook.
 func (s *MarketService) SubmitMarket(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, size core.Size) (core.SubmitReport, error) {
-	book, ok := s.books[tid]
+	book, ok := s.book(tid)
 	if !ok {
 		return core.SubmitReport{}, ErrUnknownTicker
 	}
+	if err := s.checkTradable(tid); err != nil {
+		return core.SubmitReport{}, err
+	}
+	if err := s.checkRisk(userID, tid, side, 0, size); err != nil {
+		return core.SubmitReport{}, err
+	}
 	return book.SubmitMarket(ctx, userID, side, size)
 }
 
-// Cancel cancels an order in the specified ticker's orderbook.
+// SubmitStop holds a stop order on a ticker until a trade reaches its
+// trigger (see orderbookservice.Service.SubmitStop). A limitPrice of 0 makes
+// it a stop-market order. The risk check runs now, at the limit price or as
+// a market order; the triggered order is not checked again.
+func (s *MarketService) SubmitStop(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, triggerPrice core.PriceTicks, size core.Size, limitPrice core.PriceTicks) (core.OrderID, error) {
+	return s.SubmitStopWith(ctx, tid, userID, side, triggerPrice, size, orderbookservice.StopOptions{LimitPrice: limitPrice})
+}
+
+// SubmitStopWith holds a stop order with options on a ticker, such as an
+// expiry (see orderbookservice.Service.SubmitStopWith). The risk check is
+// SubmitStop's.
+func (s *MarketService) SubmitStopWith(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, triggerPrice core.PriceTicks, size core.Size, opts orderbookservice.StopOptions) (core.OrderID, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return 0, ErrUnknownTicker
+	}
+	if err := s.checkTradable(tid); err != nil {
+		return 0, err
+	}
+	if err := s.checkRisk(userID, tid, side, opts.LimitPrice, size); err != nil {
+		return 0, err
+	}
+	return book.SubmitStopWith(ctx, userID, side, triggerPrice, size, opts)
+}
+
+// Cancel cancels an order or pending stop in the specified ticker's
+// orderbook.
 func (s *MarketService) Cancel(ctx context.Context, tid market.TickerID, orderID core.OrderID) (core.CancelReport, error) {
-	book, ok := s.books[tid]
+	book, ok := s.book(tid)
 	if !ok {
 		return core.CancelReport{}, ErrUnknownTicker
 	}
 	return book.Cancel(ctx, orderID)
 }
 
+// CancelAllForUser cancels every resting order of a user on a ticker (see
+// orderbookservice.Service.CancelAllForUser). Like Cancel, it works while
+// the ticker is halted.
+func (s *MarketService) CancelAllForUser(ctx context.Context, tid market.TickerID, userID core.UserID) ([]core.CancelReport, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return nil, ErrUnknownTicker
+	}
+	return book.CancelAllForUser(ctx, userID)
+}
+
+// Modify changes the price and/or size of an order in the specified
+// ticker's orderbook (see orderbookservice.Service.Modify). A halted ticker
+// rejects it, since a new price may trade.
+func (s *MarketService) Modify(ctx context.Context, tid market.TickerID, orderID core.OrderID, newPrice core.PriceTicks, newSize core.Size) (core.AmendReport, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return core.AmendReport{}, ErrUnknownTicker
+	}
+	if err := s.checkTradable(tid); err != nil {
+		return core.AmendReport{}, err
+	}
+	return book.Modify(ctx, orderID, newPrice, newSize)
+}
+
+// Reduce takes reduceBy off a resting order's size, keeping its priority
+// (see orderbookservice.Service.Reduce), and reports the new remaining
+// size. Reducing by the whole size is rejected with core.ErrInvalidOrder;
+// use Cancel. Like Cancel, it works while the ticker is halted.
+func (s *MarketService) Reduce(ctx context.Context, tid market.TickerID, orderID core.OrderID, reduceBy core.Size) (core.AmendReport, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return core.AmendReport{}, ErrUnknownTicker
+	}
+	return book.Reduce(ctx, orderID, reduceBy)
+}
+
+// SubscribeBook subscribes to a ticker's orderbook events (see
+// orderbookservice.Service.Subscribe).
+func (s *MarketService) SubscribeBook(tid market.TickerID, policy pubsub.Policy, buffer int) (*pubsub.Subscription[core.Event], error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return nil, ErrUnknownTicker
+	}
+	return book.Subscribe(policy, buffer), nil
+}
+
+// SubscribeLevel2 subscribes to a ticker's level deltas (see
+// orderbookservice.Service.SubscribeLevel2), capped by Config.Book.Level2.
+func (s *MarketService) SubscribeLevel2(tid market.TickerID, policy pubsub.Policy, buffer int) (*pubsub.Subscription[orderbookview.LevelDelta], error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return nil, ErrUnknownTicker
+	}
+	return book.SubscribeLevel2(policy, buffer), nil
+}
+
 // GetLevels returns the price levels for a ticker and side.
 func (s *MarketService) GetLevels(tid market.TickerID, side core.Side) ([]orderbookview.Level, error) {
-	book, ok := s.books[tid]
+	book, ok := s.book(tid)
 	if !ok {
 		return nil, ErrUnknownTicker
 	}
 	return book.GetLevels(side), nil
 }
 
+// GetDepthSnapshot returns at most maxLevels of a ticker's best levels on a
+// side, each with its orders in time priority (0 = every level).
+func (s *MarketService) GetDepthSnapshot(tid market.TickerID, side core.Side, maxLevels int) ([]orderbookview.LevelDetail, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return nil, ErrUnknownTicker
+	}
+	return book.GetDepthSnapshot(side, maxLevels), nil
+}
+
+// GetLevelsTopN returns at most the n best price levels for a ticker and
+// side.
+func (s *MarketService) GetLevelsTopN(tid market.TickerID, side core.Side, n int) ([]orderbookview.Level, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return nil, ErrUnknownTicker
+	}
+	return book.GetLevelsTopN(side, n), nil
+}
+
+// GetLevelsBucketed returns the orderbook levels for a ticker and side,
+// aggregated into price buckets of the given width in ticks.
+func (s *MarketService) GetLevelsBucketed(tid market.TickerID, side core.Side, bucket core.PriceTicks) ([]orderbookview.Level, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return nil, ErrUnknownTicker
+	}
+	return book.GetLevelsBucketed(side, bucket), nil
+}
+
+// EstimateMarket estimates what a market order on a ticker would fill if it
+// arrived now.
+func (s *MarketService) EstimateMarket(tid market.TickerID, side core.Side, size core.Size) (orderbookview.Estimate, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return orderbookview.Estimate{}, ErrUnknownTicker
+	}
+	return book.EstimateMarket(side, size), nil
+}
+
+// EstimateLimit estimates the part of a limit order on a ticker that would
+// match if it arrived now.
+func (s *MarketService) EstimateLimit(tid market.TickerID, side core.Side, price core.PriceTicks, size core.Size) (orderbookview.Estimate, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return orderbookview.Estimate{}, ErrUnknownTicker
+	}
+	return book.EstimateLimit(side, price, size), nil
+}
+
 // GetOrders returns the resting orders for a ticker and side.
 func (s *MarketService) GetOrders(tid market.TickerID, side core.Side) ([]orderbookview.RestingOrder, error) {
-	book, ok := s.books[tid]
+	book, ok := s.book(tid)
 	if !ok {
 		return nil, ErrUnknownTicker
 	}
 	return book.GetOrders(side), nil
 }
 
+// GetStops returns the pending stop orders on a ticker.
+func (s *MarketService) GetStops(tid market.TickerID) ([]orderbookview.PendingStop, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return nil, ErrUnknownTicker
+	}
+	return book.GetStops(), nil
+}
+
+// GetOrder returns a resting order on a ticker by ID, or false if it is not
+// on the book.
+func (s *MarketService) GetOrder(tid market.TickerID, id core.OrderID) (orderbookview.RestingOrder, bool, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return orderbookview.RestingOrder{}, false, ErrUnknownTicker
+	}
+	o, ok := book.GetOrder(id)
+	return o, ok, nil
+}
+
+// GetUserOrders returns a user's resting orders on a ticker, bids first.
+func (s *MarketService) GetUserOrders(tid market.TickerID, userID core.UserID) ([]orderbookview.RestingOrder, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return nil, ErrUnknownTicker
+	}
+	return book.GetOrdersByUser(userID), nil
+}
+
 // GetTradesLast returns the last n trades for a ticker.
 func (s *MarketService) GetTradesLast(tid market.TickerID, n int) ([]core.TradeEvent, error) {
-	book, ok := s.books[tid]
+	book, ok := s.book(tid)
 	if !ok {
 		return nil, ErrUnknownTicker
 	}
 	return book.GetTradesLast(n), nil
 }
 
-// Snapshot returns the current market snapshot across all tickers.
+// GetVWAPLast returns the volume-weighted average price of a ticker's last
+// n trades, or false if it has none.
+func (s *MarketService) GetVWAPLast(tid market.TickerID, n int) (core.PriceTicks, bool, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return 0, false, ErrUnknownTicker
+	}
+	vwap, ok := book.GetVWAPLast(n)
+	return vwap, ok, nil
+}
+
+// GetOrderCount returns the number of resting orders for a ticker and side.
+func (s *MarketService) GetOrderCount(tid market.TickerID, side core.Side) (int, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return 0, ErrUnknownTicker
+	}
+	return book.GetOrderCount(side), nil
+}
+
+// GetUserExposure returns a user's resting exposure for a ticker.
+func (s *MarketService) GetUserExposure(tid market.TickerID, userID core.UserID) (orderbookview.Exposure, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return orderbookview.Exposure{}, ErrUnknownTicker
+	}
+	return book.GetUserExposure(userID), nil
+}
+
+// UserFills returns a user's last n fills on a ticker, oldest first, as
+// either taker or maker.
+func (s *MarketService) UserFills(tid market.TickerID, userID core.UserID, n int) ([]marketview.Fill, error) {
+	if _, ok := s.book(tid); !ok {
+		return nil, ErrUnknownTicker
+	}
+	return s.mview.UserFills(tid, userID, n), nil
+}
+
+// UserPosition returns a user's net position on a ticker over every trade:
+// buys add, sells subtract. Unlike UserFills it is exact however many fills
+// the user has.
+func (s *MarketService) UserPosition(tid market.TickerID, userID core.UserID) (core.Size, error) {
+	p, err := s.UserTotals(tid, userID)
+	return p.Net, err
+}
+
+// UserTotals returns a user's net position and cash on a ticker over every
+// trade. It returns money.ErrOverflow if a total no longer fits, rather than
+// a wrong figure.
+func (s *MarketService) UserTotals(tid market.TickerID, userID core.UserID) (marketview.Position, error) {
+	if _, ok := s.book(tid); !ok {
+		return marketview.Position{}, ErrUnknownTicker
+	}
+	return s.mview.UserPosition(tid, userID)
+}
+
+// GetCandles returns up to the last n finished candles of a ticker at an
+// interval, followed by the candle the latest trade went into, oldest
+// first. Intervals in which the ticker did not trade have no candle. It
+// returns ErrNoCandles unless the interval is one of Config.Candles.
+func (s *MarketService) GetCandles(tid market.TickerID, interval time.Duration, n int) ([]marketview.Candle, error) {
+	if _, ok := s.book(tid); !ok {
+		return nil, ErrUnknownTicker
+	}
+	candles, ok := s.mview.Candles(tid, interval, n)
+	if !ok {
+		return nil, ErrNoCandles
+	}
+	return candles, nil
+}
+
+// BBOAt returns the best bid and offer in effect on a ticker at time t
+// (Unix nanoseconds, as in event times). It returns ErrNoBBOHistory if t is
+// before the oldest change still held.
+func (s *MarketService) BBOAt(tid market.TickerID, t int64) (marketview.BBO, error) {
+	if _, ok := s.book(tid); !ok {
+		return marketview.BBO{}, ErrUnknownTicker
+	}
+	b, ok := s.mview.BBOAt(tid, t)
+	if !ok {
+		return marketview.BBO{}, ErrNoBBOHistory
+	}
+	return b, nil
+}
+
+// GetQueueDepths returns the channel depths of a ticker's orderbook service.
+func (s *MarketService) GetQueueDepths(tid market.TickerID) (orderbookservice.QueueDepths, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return orderbookservice.QueueDepths{}, ErrUnknownTicker
+	}
+	return book.QueueDepths(), nil
+}
+
+// QueueDepth returns the number of events waiting in the market events channel.
+func (s *MarketService) QueueDepth() int {
+	return s.external.Len()
+}
+
+// Snapshot returns the current market snapshot across all tickers,
+// including each ticker's trading status.
 func (s *MarketService) Snapshot() marketview.MarketSnapshot {
-	return s.mview.SnapshotWithBooks(s.books)
+	snap := s.mview.SnapshotWithBooks(s.allBooks())
+	s.statusMu.Lock()
+	defer s.statusMu.Unlock()
+	for tid, bp := range snap.ByTicker {
+		if st, ok := s.states[tid]; ok {
+			bp.Status = st.status
+			snap.ByTicker[tid] = bp
+		}
+	}
+	return snap
 }
 
-// Events returns the consolidated market events channel.
+// Events returns the consolidated market events channel, sized by
+// Config.MarketEventBuffer with the Config.DropMarketEvents policy. It is
+// one subscription among any made with Subscribe.
 func (s *MarketService) Events() <-chan marketview.MarketEvent {
-	return s.externalEvents
+	return s.external.C()
 }
 
-// DroppedEvents returns the count of dropped market events.
+// DroppedEvents returns the count of market events dropped from Events.
 func (s *MarketService) DroppedEvents() int64 {
-	return s.droppedEvents.Load()
+	return s.external.Dropped()
 }
 
-// GetTickers returns all registered tickers.
+// Registry returns a name index of the tickers registered now. Tickers
+// added later need a fresh Registry.
+func (s *MarketService) Registry() *market.Registry {
+	return market.NewRegistry(s.GetTickers())
+}
+
+// GetTickers returns all registered tickers sorted by ID. The slice is a
+// copy the caller may modify.
 func (s *MarketService) GetTickers() []market.Ticker {
+	s.booksMu.RLock()
+	defer s.booksMu.RUnlock()
 	tickers := make([]market.Ticker, 0, len(s.tickers))
 	for _, t := range s.tickers {
 		tickers = append(tickers, t)
 	}
+	sort.Slice(tickers, func(i, j int) bool { return tickers[i].ID < tickers[j].ID })
 	return tickers
 }
 
@@ -183,15 +636,16 @@ func (s *MarketService) Close() {
 	s.closeOnce.Do(func() {
 		close(s.closed)
 	})
+	// Release forwarders blocked on a full subscriber and close every
+	// subscription; buffered events can still be read
+	s.bus.Close()
 
-	// Close all books
-	for _, book := range s.books {
+	// Close all books. AddTicker sees closed under booksMu, so no book is
+	// added after this copy.
+	for _, book := range s.allBooks() {
 		book.Close()
 	}
 
 	// Wait for forwarders to finish
 	s.wg.Wait()
-
-	// Close external events channel
-	close(s.externalEvents)
 }
@@ -2,11 +2,19 @@ package service
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/zappabad/stockcraft/internal/clock"
 	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/order"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/pubsub"
 )
 
 func TestMarketServiceBasic(t *testing.T) {
@@ -94,11 +102,54 @@ func TestMarketServiceSnapshot(t *testing.T) {
 	}
 }
 
+func TestMarketServiceSnapshotActivity(t *testing.T) {
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+		{ID: 2, Name: "MSFT", Decimals: 2},
+	}
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewMarketService(tickers, cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	if bp := svc.Snapshot().ByTicker[2]; bp.TradeCount != 0 || bp.LastUpdateTime != 0 {
+		t.Errorf("expected no activity before any order, got %+v", bp)
+	}
+
+	before := time.Now().UnixNano()
+	if _, err := svc.SubmitLimit(ctx, 1, 200, core.SideSell, 101, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rested := svc.Snapshot().ByTicker[1]
+	if rested.TradeCount != 0 || rested.LastUpdateTime < before {
+		t.Errorf("expected a book update and no trades, got %+v", rested)
+	}
+
+	for range 3 {
+		if _, err := svc.SubmitMarket(ctx, 1, 100, core.SideBuy, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	bp := svc.Snapshot().ByTicker[1]
+	if bp.TradeCount != 3 {
+		t.Errorf("expected 3 trades, got %d", bp.TradeCount)
+	}
+	if bp.LastUpdateTime < bp.LastTime || bp.LastUpdateTime < rested.LastUpdateTime {
+		t.Errorf("expected the update time to follow the last trade at %d, got %d", bp.LastTime, bp.LastUpdateTime)
+	}
+	if other := svc.Snapshot().ByTicker[2]; other.TradeCount != 0 || other.LastUpdateTime != 0 {
+		t.Errorf("expected MSFT untouched, got %+v", other)
+	}
+}
+
 func TestMarketServiceTrade(t *testing.T) {
 	tickers := []market.Ticker{
 		{ID: 1, Name: "AAPL", Decimals: 2},
 	}
+	// Synchronous, so views are current as soon as a call returns
 	cfg := DefaultConfig()
+	cfg.Synchronous = true
 	svc := NewMarketService(tickers, cfg)
 	defer svc.Close()
 
@@ -119,9 +170,6 @@ func TestMarketServiceTrade(t *testing.T) {
 		t.Fatalf("expected 1 fill, got %d", len(report.Fills))
 	}
 
-	// Wait for view update
-	time.Sleep(20 * time.Millisecond)
-
 	// Check last trade
 	trades, err := svc.GetTradesLast(1, 1)
 	if err != nil {
@@ -143,4 +191,1183 @@ func TestMarketServiceTrade(t *testing.T) {
 	if bp.LastPrice != 100 {
 		t.Errorf("expected last price 100, got %d", bp.LastPrice)
 	}
+
+	// The fill log and event channel are current too
+	if fills, _ := svc.UserFills(1, 200, 10); len(fills) != 1 {
+		t.Errorf("expected the taker's fill, got %v", fills)
+	}
+	var trade bool
+	for len(svc.Events()) > 0 {
+		if _, ok := (<-svc.Events()).Event.(core.TradeEvent); ok {
+			trade = true
+		}
+	}
+	if !trade {
+		t.Error("expected the trade on the events channel")
+	}
+}
+
+func TestMarketServicePortfolioRisk(t *testing.T) {
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+	}
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewMarketService(tickers, cfg)
+	defer svc.Close()
+	svc.SetRiskChecker(NewPortfolioRisk(svc, PortfolioRiskConfig{StartingCash: 50, Users: []core.UserID{1000}}))
+
+	ctx := context.Background()
+	expect := func(what string, err, want error) {
+		t.Helper()
+		if !errors.Is(err, want) {
+			t.Errorf("%s: expected %v, got %v", what, want, err)
+		}
+	}
+
+	// $60 of a $50 account is refused; $30 rests and then counts against
+	// the next bid
+	_, err := svc.SubmitLimit(ctx, 1, 1000, core.SideBuy, 100, 60)
+	expect("oversized buy", err, ErrInsufficientFunds)
+	_, err = svc.SubmitLimit(ctx, 1, 1000, core.SideBuy, 100, 30)
+	expect("buy within cash", err, nil)
+	_, err = svc.SubmitLimit(ctx, 1, 1000, core.SideBuy, 99, 30)
+	expect("buy beyond resting bids", err, ErrInsufficientFunds)
+
+	// Nothing to sell yet, and unchecked users are not limited
+	_, err = svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 110, 1)
+	expect("sell without position", err, ErrPositionLimit)
+	_, err = svc.SubmitLimit(ctx, 1, 200, core.SideSell, 100, 30)
+	expect("unchecked user", err, nil)
+
+	// The fill moved $30 into 30 shares
+	risk := NewPortfolioRisk(svc, PortfolioRiskConfig{StartingCash: 50, Users: []core.UserID{1000}})
+	if cash, _ := risk.Cash(1000); cash != 20 {
+		t.Errorf("expected $20 cash after the fill, got %v", cash)
+	}
+	_, err = svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 110, 30)
+	expect("sell the position", err, nil)
+	_, err = svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 110, 1)
+	expect("sell beyond resting offers", err, ErrPositionLimit)
+
+	// A margin multiplier allows a short worth up to that multiple of cash
+	svc.SetRiskChecker(NewPortfolioRisk(svc, PortfolioRiskConfig{StartingCash: 50, MarginMultiplier: 1, Users: []core.UserID{1000}}))
+	_, err = svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 100, 21)
+	expect("short beyond margin", err, ErrPositionLimit)
+	_, err = svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 100, 20)
+	expect("short within margin", err, nil)
+
+	// Market orders are costed at what they would fill at now
+	svc.SetRiskChecker(NewPortfolioRisk(svc, PortfolioRiskConfig{StartingCash: 50, Users: []core.UserID{300}}))
+	_, err = svc.SubmitMarket(ctx, 1, 300, core.SideBuy, 60)
+	expect("oversized market buy", err, ErrInsufficientFunds)
+	_, err = svc.SubmitMarket(ctx, 1, 300, core.SideBuy, 10)
+	expect("market buy within cash", err, nil)
+
+	svc.SetRiskChecker(nil)
+	_, err = svc.SubmitLimit(ctx, 1, 1000, core.SideBuy, 100, 1000)
+	expect("checker removed", err, nil)
+}
+
+func TestMarketServicePortfolioRiskPastFillCapacity(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.UserFillCapacity = 2
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	risk := NewPortfolioRisk(svc, PortfolioRiskConfig{StartingCash: 100, Users: []core.UserID{1000}})
+	svc.SetRiskChecker(risk)
+	ctx := context.Background()
+
+	// Ten buys of 5 at $1; the ring holds only the last two
+	for range 10 {
+		svc.SubmitLimit(ctx, 1, 200, core.SideSell, 100, 5)
+		if _, err := svc.SubmitMarket(ctx, 1, 1000, core.SideBuy, 5); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if cash, err := risk.Cash(1000); err != nil || cash != 50 {
+		t.Errorf("expected $50 cash after $50 of buys, got %v (%v)", cash, err)
+	}
+	// Every dollar spent counts, not just the fills still held
+	svc.SubmitLimit(ctx, 1, 200, core.SideSell, 100, 60)
+	if _, err := svc.SubmitMarket(ctx, 1, 1000, core.SideBuy, 51); !errors.Is(err, ErrInsufficientFunds) {
+		t.Errorf("expected a buy beyond the $50 left to be rejected, got %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 120, 50); err != nil {
+		t.Errorf("expected the whole 50-share position to be sellable, got %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 120, 1); !errors.Is(err, ErrPositionLimit) {
+		t.Errorf("expected a sell past the position to be rejected, got %v", err)
+	}
+}
+
+func TestMarketServicePortfolioRiskFailsClosed(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	// A trade whose notional overflows leaves no exact cash figure
+	svc.SubmitLimit(ctx, 1, 200, core.SideSell, 1<<40, 1<<30)
+	if _, err := svc.SubmitMarket(ctx, 1, 1000, core.SideBuy, 1<<30); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.UserTotals(1, 1000); !errors.Is(err, money.ErrOverflow) {
+		t.Fatalf("expected the totals to overflow, got %v", err)
+	}
+
+	svc.SetRiskChecker(NewPortfolioRisk(svc, PortfolioRiskConfig{StartingCash: 1e12, Users: []core.UserID{1000}}))
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideBuy, 100, 1); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("expected the buy rejected for lack of an exact figure, got %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 100, 1); !errors.Is(err, money.ErrOverflow) {
+		t.Errorf("expected the sell rejected for lack of an exact figure, got %v", err)
+	}
+}
+
+func TestMarketServiceLiquidationCooldown(t *testing.T) {
+	clk := clock.NewManual(time.Unix(1_700_000_000, 0))
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Book.Clock = clk
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	risk := NewPortfolioRisk(svc, PortfolioRiskConfig{StartingCash: 1000, Users: []core.UserID{1000}, LiquidationCooldown: time.Minute})
+	svc.SetRiskChecker(risk)
+	ctx := context.Background()
+
+	// A long of 10 shares, then a forced liquidation of part of it
+	svc.SubmitLimit(ctx, 1, 200, core.SideSell, 100, 10)
+	if _, err := svc.SubmitMarket(ctx, 1, 1000, core.SideBuy, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	risk.Liquidated(1000)
+
+	_, err := svc.SubmitLimit(ctx, 1, 1000, core.SideBuy, 90, 1)
+	if !errors.Is(err, ErrLiquidationCooldown) || !errors.Is(err, ErrPositionLimit) {
+		t.Errorf("expected a buy during the cooldown to be rejected, got %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 110, 6); err != nil {
+		t.Errorf("expected a sell that reduces the position, got %v", err)
+	}
+	// The 6 resting count: selling 5 more would go short
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 110, 5); !errors.Is(err, ErrLiquidationCooldown) {
+		t.Errorf("expected a sell past the position to be rejected, got %v", err)
+	}
+
+	clk.Advance(time.Minute)
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideBuy, 90, 1); err != nil {
+		t.Errorf("expected buys to be allowed after the cooldown, got %v", err)
+	}
+}
+
+func TestMarketServiceLiquidationCooldownPastFillCapacity(t *testing.T) {
+	clk := clock.NewManual(time.Unix(1_700_000_000, 0))
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Book.Clock = clk
+	cfg.UserFillCapacity = 1
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	risk := NewPortfolioRisk(svc, PortfolioRiskConfig{StartingCash: 1000, Users: []core.UserID{1000}, LiquidationCooldown: time.Minute})
+	svc.SetRiskChecker(risk)
+	ctx := context.Background()
+
+	// A long of 10 built from five fills, of which the ring holds one
+	for range 5 {
+		svc.SubmitLimit(ctx, 1, 200, core.SideSell, 100, 2)
+		if _, err := svc.SubmitMarket(ctx, 1, 1000, core.SideBuy, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	risk.Liquidated(1000)
+
+	// Selling all 10 only reduces the position
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 110, 10); err != nil {
+		t.Errorf("expected a sell of the whole position, got %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 1000, core.SideSell, 110, 1); !errors.Is(err, ErrLiquidationCooldown) {
+		t.Errorf("expected a sell past the position to be rejected, got %v", err)
+	}
+}
+
+func TestMarketServicePostOnly(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	aapl := market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}
+	svc := NewMarketService([]market.Ticker{aapl}, cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideSell, 101, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	postOnly := func(price core.PriceTicks) order.Request {
+		return order.Request{TickerID: 1, UserID: 200, Kind: core.OrderKindLimit, Side: core.SideBuy, Price: price, Size: 5, PostOnly: true}
+	}
+
+	// Below the offer it rests; at the offer it would take, so it is refused
+	report, err := order.Submit(ctx, svc, aapl, postOnly(100))
+	if err != nil || report.RestedSize != 5 {
+		t.Fatalf("expected the post-only bid to rest, got %+v (%v)", report, err)
+	}
+	if _, err := order.Submit(ctx, svc, aapl, postOnly(101)); !errors.Is(err, core.ErrWouldCross) {
+		t.Errorf("expected ErrWouldCross, got %v", err)
+	}
+	if trades, _ := svc.GetTradesLast(1, 10); len(trades) != 0 {
+		t.Errorf("expected no trades, got %+v", trades)
+	}
+	if asks, _ := svc.GetLevels(1, core.SideSell); len(asks) != 1 || asks[0].Size != 10 {
+		t.Errorf("expected the offer untouched, got %+v", asks)
+	}
+}
+
+func TestMarketServiceIceberg(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	aapl := market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}
+	svc := NewMarketService([]market.Ticker{aapl}, cfg)
+	defer svc.Close()
+	book := svc.Subscribe(EventFilter{Kinds: EventBook}, pubsub.Drop, 100)
+	ctx := context.Background()
+
+	// 30 to sell, 10 shown at a time
+	r := order.Request{TickerID: 1, UserID: 100, Kind: core.OrderKindLimit, Side: core.SideSell, Price: 101, Size: 30, DisplaySize: 10}
+	placed, err := order.Submit(ctx, svc, aapl, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if asks, _ := svc.GetLevels(1, core.SideSell); len(asks) != 1 || asks[0].Size != 10 {
+		t.Fatalf("expected only the 10 slice displayed, got %+v", asks)
+	}
+
+	// Taking the slice refreshes the next one from reserve
+	if _, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if asks, _ := svc.GetLevels(1, core.SideSell); len(asks) != 1 || asks[0].Size != 10 {
+		t.Errorf("expected a fresh 10 slice displayed, got %+v", asks)
+	}
+	var refreshed []core.OrderRefreshedEvent
+	for book.Len() > 0 {
+		if e, ok := (<-book.C()).Event.(core.OrderRefreshedEvent); ok {
+			refreshed = append(refreshed, e)
+		}
+	}
+	if len(refreshed) != 1 || refreshed[0].OrderID != placed.OrderID || refreshed[0].Size != 10 || refreshed[0].Hidden != 10 {
+		t.Errorf("expected one refresh of 10 with 10 hidden, got %+v", refreshed)
+	}
+
+	// A sweep takes the display and the reserve
+	report, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 25)
+	if err != nil || report.Remaining != 5 {
+		t.Errorf("expected 20 filled and 5 left, got %+v (%v)", report, err)
+	}
+}
+
+func TestMarketServiceCircuitBreaker(t *testing.T) {
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+	}
+	cfg := DefaultConfig()
+	cfg.DropMarketEvents = false
+	cfg.CircuitBreaker = CircuitBreakerConfig{LimitPct: 10, Cooldown: 50 * time.Millisecond}
+	svc := NewMarketService(tickers, cfg)
+	defer svc.Close()
+
+	ctx := context.Background()
+	if err := svc.SetReferencePrice(1, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Thin ask side: a sweep prints 20% above the reference
+	if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideSell, 101, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideSell, 120, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideSell, 125, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Wait for the halt event
+	var halt *marketview.StatusEvent
+	timeout := time.After(time.Second)
+	for halt == nil {
+		select {
+		case me := <-svc.Events():
+			if me.Status != nil {
+				halt = me.Status
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for halt")
+		}
+	}
+	if halt.Status != market.StatusHalted {
+		t.Fatalf("expected halted status, got %v", halt.Status)
+	}
+
+	// Aggressive orders are rejected while halted
+	if halt.ResumeAt != halt.Time+cfg.CircuitBreaker.Cooldown.Nanoseconds() {
+		t.Errorf("expected the resume time one cooldown after the halt, got %+v", halt)
+	}
+	if state, _ := svc.GetTradingState(1); state != *halt {
+		t.Errorf("expected the trading state to be the halt, got %+v", state)
+	}
+	if band, _ := svc.GetPriceBand(1); band != (PriceBand{Low: 90, High: 110}) {
+		t.Errorf("expected a 10%% band around 100, got %+v", band)
+	}
+
+	// Aggressive orders are rejected while halted
+	if _, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 1); err != ErrTickerHalted {
+		t.Errorf("expected ErrTickerHalted, got %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 200, core.SideBuy, 125, 1); err != ErrTickerHalted {
+		t.Errorf("expected ErrTickerHalted, got %v", err)
+	}
+
+	// Trading resumes after the cooldown
+	var resume *marketview.StatusEvent
+	for resume == nil {
+		select {
+		case me := <-svc.Events():
+			if me.Status != nil {
+				resume = me.Status
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for resume")
+		}
+	}
+	if resume.Status != market.StatusOpen {
+		t.Fatalf("expected open status, got %v", resume.Status)
+	}
+	// The band re-centers on the price that tripped the halt
+	band, _ := svc.GetPriceBand(1)
+	if band.Contains(100) || !band.Contains(125) {
+		t.Errorf("expected the band to move to the halt price, got %+v", band)
+	}
+	if _, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 1); err != nil {
+		t.Errorf("expected order to be accepted after resume, got %v", err)
+	}
+}
+
+func TestMarketServiceCircuitBreakerResumesOnClock(t *testing.T) {
+	clk := clock.NewManual(time.Unix(1_700_000_000, 0))
+	cfg := DefaultConfig()
+	cfg.DropMarketEvents = false
+	cfg.Book.Clock = clk
+	cfg.CircuitBreaker = CircuitBreakerConfig{LimitPct: 10, Cooldown: time.Minute}
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+
+	ctx := context.Background()
+	if err := svc.SetReferencePrice(1, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideSell, 120, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	nextStatus := func(within time.Duration) *marketview.StatusEvent {
+		timeout := time.After(within)
+		for {
+			select {
+			case me := <-svc.Events():
+				if me.Status != nil {
+					return me.Status
+				}
+			case <-timeout:
+				return nil
+			}
+		}
+	}
+	halt := nextStatus(time.Second)
+	if halt == nil || halt.Status != market.StatusHalted {
+		t.Fatalf("expected a halt, got %+v", halt)
+	}
+	if halt.Time != clk.Now().UnixNano() {
+		t.Errorf("expected the halt stamped by the manual clock, got %+v", halt)
+	}
+
+	// Wall time passing does not end the cooldown; only the clock does
+	clk.Advance(time.Minute - time.Second)
+	if ev := nextStatus(50 * time.Millisecond); ev != nil {
+		t.Fatalf("expected no resume before the cooldown, got %+v", ev)
+	}
+	clk.Advance(time.Second)
+	resume := nextStatus(time.Second)
+	if resume == nil || resume.Status != market.StatusOpen {
+		t.Fatalf("expected a resume, got %+v", resume)
+	}
+	if resume.Time != halt.ResumeAt {
+		t.Errorf("expected the resume at %d on the manual clock, got %d", halt.ResumeAt, resume.Time)
+	}
+}
+
+// newsRecorder collects published news items.
+type newsRecorder chan news.NewsItem
+
+func (r newsRecorder) Publish(item news.NewsItem) { r <- item }
+
+func TestMarketServiceCircuitBreakerPublishesSystemNews(t *testing.T) {
+	clk := clock.NewManual(time.Unix(1_700_000_000, 0))
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Book.Clock = clk
+	cfg.CircuitBreaker = CircuitBreakerConfig{LimitPct: 10, Cooldown: time.Minute}
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	headlines := make(newsRecorder, 4)
+	svc.SetNewsPublisher(headlines)
+
+	ctx := context.Background()
+	if err := svc.SetReferencePrice(1, 100); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.SubmitLimit(ctx, 1, 100, core.SideSell, 120, 5)
+	if _, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	next := func() news.NewsItem {
+		select {
+		case item := <-headlines:
+			return item
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a headline")
+			return news.NewsItem{}
+		}
+	}
+	halt := next()
+	if halt.Source != news.SourceSystem || halt.Ticker != 1 || halt.Headline != "AAPL halted: limit move +20.0%" {
+		t.Errorf("expected a system halt headline for AAPL, got %+v", halt)
+	}
+	if halt.Time != clk.Now().UnixNano() {
+		t.Errorf("expected the headline at the halt time, got %d", halt.Time)
+	}
+
+	clk.Advance(time.Minute)
+	resume := next()
+	if resume.Source != news.SourceSystem || resume.Headline != "AAPL resumes trading: cooldown elapsed" {
+		t.Errorf("expected a system resume headline, got %+v", resume)
+	}
+
+	// Without a publisher the breaker stays quiet
+	svc.SetNewsPublisher(nil)
+	svc.SubmitLimit(ctx, 1, 100, core.SideSell, 150, 5)
+	svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 5)
+	if status, _ := svc.GetTradingStatus(1); status != market.StatusHalted {
+		t.Fatalf("expected a second halt, got %v", status)
+	}
+	select {
+	case item := <-headlines:
+		t.Errorf("expected no headline without a publisher, got %+v", item)
+	default:
+	}
+}
+
+func TestMarketServiceOpenSession(t *testing.T) {
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+	}
+	cfg := DefaultConfig()
+	cfg.DropMarketEvents = false
+	svc := NewMarketService(tickers, cfg)
+	defer svc.Close()
+
+	ctx := context.Background()
+	if err := svc.BeginPreOpen(ctx, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if status, _ := svc.GetTradingStatus(1); status != market.StatusPreOpen {
+		t.Fatalf("expected pre-open, got %v", status)
+	}
+
+	// Crossed pre-open interest rests without matching
+	seed := []struct {
+		user  core.UserID
+		side  core.Side
+		price core.PriceTicks
+		size  core.Size
+	}{
+		{100, core.SideBuy, 102, 10},
+		{100, core.SideBuy, 101, 10},
+		{200, core.SideSell, 99, 5},
+		{200, core.SideSell, 100, 10},
+		{200, core.SideSell, 103, 5},
+	}
+	for _, o := range seed {
+		report, err := svc.SubmitLimit(ctx, 1, o.user, o.side, o.price, o.size)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(report.Fills) != 0 {
+			t.Fatalf("expected no fills during pre-open, got %+v", report.Fills)
+		}
+	}
+	if _, err := svc.SubmitMarket(ctx, 1, 300, core.SideBuy, 1); err != core.ErrAuction {
+		t.Errorf("expected ErrAuction for market order in pre-open, got %v", err)
+	}
+
+	report, err := svc.OpenSession(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Price != 101 || report.Volume != 15 {
+		t.Fatalf("expected opening print 15 @ 101, got %+v", report)
+	}
+	if _, err := svc.OpenSession(ctx, 1); err == nil {
+		t.Error("expected opening an open session to fail")
+	}
+
+	// Phase events arrive in order
+	var phases []market.TradingStatus
+	timeout := time.After(time.Second)
+	for len(phases) < 3 {
+		select {
+		case me := <-svc.Events():
+			if me.Status != nil {
+				phases = append(phases, me.Status.Status)
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for phase events, got %v", phases)
+		}
+	}
+	want := []market.TradingStatus{market.StatusPreOpen, market.StatusAuction, market.StatusOpen}
+	for i := range want {
+		if phases[i] != want[i] {
+			t.Errorf("phase %d: expected %v, got %v", i, want[i], phases[i])
+		}
+	}
+
+	// Continuous matching works after the open
+	mkt, err := svc.SubmitMarket(ctx, 1, 300, core.SideSell, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mkt.Fills) != 1 || mkt.Fills[0].Price != 101 || mkt.Fills[0].Size != 5 {
+		t.Errorf("expected market sell to fill 5 @ 101, got %+v", mkt.Fills)
+	}
+	crossing, err := svc.SubmitLimit(ctx, 1, 300, core.SideBuy, 103, 5)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(crossing.Fills) != 1 || crossing.Fills[0].Price != 103 {
+		t.Errorf("expected crossing limit to match at 103, got %+v", crossing.Fills)
+	}
+}
+
+func TestMarketServiceRejectsInvalidDecimals(t *testing.T) {
+	for _, d := range []int8{0, 8} {
+		ms := NewMarketService([]market.Ticker{{ID: 1, Name: "OK", Decimals: d}}, DefaultConfig())
+		ms.Close()
+	}
+
+	for _, d := range []int8{-1, 9, 19} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for decimals %d", d)
+				}
+			}()
+			ms := NewMarketService([]market.Ticker{{ID: 1, Name: "BAD", Decimals: d}}, DefaultConfig())
+			ms.Close()
+		}()
+	}
+}
+
+func TestMarketServiceEmergencyStop(t *testing.T) {
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+		{ID: 2, Name: "GOOG", Decimals: 2},
+	}
+	svc := NewMarketService(tickers, DefaultConfig())
+	defer svc.Close()
+
+	ctx := context.Background()
+	for _, tid := range []market.TickerID{1, 2} {
+		for i := 0; i < 3; i++ {
+			if _, err := svc.SubmitLimit(ctx, tid, 100, core.SideBuy, core.PriceTicks(99-i), 10); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := svc.SubmitLimit(ctx, tid, 200, core.SideSell, core.PriceTicks(101+i), 5); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	report, err := svc.EmergencyStop(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Halted) != 2 || report.CanceledOrders != 12 || report.CanceledSize != 90 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	time.Sleep(20 * time.Millisecond) // wait for event dispatchers
+
+	for _, tid := range []market.TickerID{1, 2} {
+		for _, side := range []core.Side{core.SideBuy, core.SideSell} {
+			if levels, _ := svc.GetLevels(tid, side); len(levels) != 0 {
+				t.Errorf("ticker %d %v: expected empty book, got %+v", tid, side, levels)
+			}
+		}
+		if status, _ := svc.GetTradingStatus(tid); status != market.StatusHalted {
+			t.Errorf("ticker %d: expected halted, got %v", tid, status)
+		}
+		if _, err := svc.SubmitLimit(ctx, tid, 100, core.SideBuy, 99, 1); err != ErrTickerHalted {
+			t.Errorf("ticker %d: expected ErrTickerHalted, got %v", tid, err)
+		}
+	}
+}
+
+func TestMarketServiceReduce(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	first, _ := svc.SubmitLimit(ctx, 1, 100, core.SideSell, 101, 10)
+	second, _ := svc.SubmitLimit(ctx, 1, 200, core.SideSell, 101, 5)
+
+	report, err := svc.Reduce(ctx, 1, first.OrderID, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Remaining != 6 || !report.KeptPriority {
+		t.Errorf("expected 6 left in place, got %+v", report)
+	}
+	if levels, _ := svc.GetLevels(1, core.SideSell); len(levels) != 1 || levels[0].Size != 11 {
+		t.Errorf("expected 11 at 101, got %+v", levels)
+	}
+
+	// The reduced order still trades first
+	fill, _ := svc.SubmitMarket(ctx, 1, 300, core.SideBuy, 2)
+	if len(fill.Fills) != 1 || fill.Fills[0].MakerOrderID != first.OrderID {
+		t.Errorf("expected order %d to fill first, got %+v", first.OrderID, fill.Fills)
+	}
+
+	// Reducing by everything left is rejected; Cancel pulls the rest
+	if _, err := svc.Reduce(ctx, 1, second.OrderID, 5); !errors.Is(err, core.ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder for an over-reduce, got %v", err)
+	}
+	if o, ok, _ := svc.GetOrder(1, second.OrderID); !ok || o.Size != 5 {
+		t.Errorf("expected order %d untouched at 5, got %+v", second.OrderID, o)
+	}
+	if _, err := svc.Reduce(ctx, 9, first.OrderID, 1); err != ErrUnknownTicker {
+		t.Errorf("expected ErrUnknownTicker, got %v", err)
+	}
+}
+
+func TestMarketServiceUserFills(t *testing.T) {
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+		{ID: 2, Name: "GOOGL", Decimals: 2},
+	}
+	svc := NewMarketService(tickers, DefaultConfig())
+	defer svc.Close()
+
+	ctx := context.Background()
+	mustSubmit := func(_ core.SubmitReport, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// User 100 rests an ask; 200 lifts part of it, then 300 takes the rest.
+	mustSubmit(svc.SubmitLimit(ctx, 1, 100, core.SideSell, 100, 10))
+	mustSubmit(svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 4))
+	mustSubmit(svc.SubmitMarket(ctx, 1, 300, core.SideBuy, 6))
+	// 200 rests a bid that 300 hits; user 100 is not involved.
+	mustSubmit(svc.SubmitLimit(ctx, 1, 200, core.SideBuy, 98, 5))
+	mustSubmit(svc.SubmitLimit(ctx, 1, 300, core.SideSell, 98, 2))
+	// A trade for user 100 on another ticker.
+	mustSubmit(svc.SubmitLimit(ctx, 2, 400, core.SideBuy, 50, 1))
+	mustSubmit(svc.SubmitMarket(ctx, 2, 100, core.SideSell, 1))
+
+	// Wait for view update
+	time.Sleep(20 * time.Millisecond)
+
+	fills := func(tid market.TickerID, user core.UserID) []marketview.Fill {
+		t.Helper()
+		out, err := svc.UserFills(tid, user, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return out
+	}
+	type want struct {
+		side  core.Side
+		role  marketview.FillRole
+		price core.PriceTicks
+		size  core.Size
+	}
+	check := func(name string, got []marketview.Fill, expected []want) {
+		t.Helper()
+		if len(got) != len(expected) {
+			t.Fatalf("%s: expected %d fills, got %+v", name, len(expected), got)
+		}
+		for i, w := range expected {
+			g := got[i]
+			if g.Side != w.side || g.Role != w.role || g.Price != w.price || g.Size != w.size {
+				t.Errorf("%s fill %d: expected %v %v %d@%d, got %v %v %d@%d",
+					name, i, w.side, w.role, w.size, w.price, g.Side, g.Role, g.Size, g.Price)
+			}
+		}
+	}
+
+	check("maker", fills(1, 100), []want{
+		{core.SideSell, marketview.RoleMaker, 100, 4},
+		{core.SideSell, marketview.RoleMaker, 100, 6},
+	})
+	check("mixed", fills(1, 200), []want{
+		{core.SideBuy, marketview.RoleTaker, 100, 4},
+		{core.SideBuy, marketview.RoleMaker, 98, 2},
+	})
+	check("taker", fills(1, 300), []want{
+		{core.SideBuy, marketview.RoleTaker, 100, 6},
+		{core.SideSell, marketview.RoleTaker, 98, 2},
+	})
+	check("other ticker", fills(2, 100), []want{
+		{core.SideSell, marketview.RoleTaker, 50, 1},
+	})
+	check("no trades", fills(1, 400), nil)
+
+	if last, _ := svc.UserFills(1, 100, 1); len(last) != 1 || last[0].Size != 6 {
+		t.Errorf("expected only the latest fill, got %+v", last)
+	}
+	if _, err := svc.UserFills(999, 100, 10); err != ErrUnknownTicker {
+		t.Errorf("expected ErrUnknownTicker, got %v", err)
+	}
+}
+
+func TestMarketServiceBBOAt(t *testing.T) {
+	tickers := []market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}
+	cfg := DefaultConfig()
+	cfg.BBOHistoryCapacity = 2
+	svc := NewMarketService(tickers, cfg)
+	defer svc.Close()
+
+	ctx := context.Background()
+	// submit rests an order and returns a time after the view has caught up
+	submit := func(side core.Side, price core.PriceTicks, size core.Size) int64 {
+		t.Helper()
+		if _, err := svc.SubmitLimit(ctx, 1, 100, side, price, size); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		time.Sleep(20 * time.Millisecond)
+		return time.Now().UnixNano()
+	}
+
+	start := time.Now().UnixNano()
+	afterBid := submit(core.SideBuy, 99, 5)
+	afterAsk := submit(core.SideSell, 101, 3)
+
+	if _, err := svc.BBOAt(1, start); err != ErrNoBBOHistory {
+		t.Errorf("expected ErrNoBBOHistory before the first change, got %v", err)
+	}
+
+	// Between the two changes the earlier quote is in effect
+	b, err := svc.BBOAt(1, afterBid)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !b.BidOK || b.BidPrice != 99 || b.BidSize != 5 || b.AskOK {
+		t.Errorf("expected bid-only quote between changes, got %+v", b)
+	}
+	if b.Time > afterBid {
+		t.Errorf("sample time %d after query time %d", b.Time, afterBid)
+	}
+
+	b, err = svc.BBOAt(1, afterAsk)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !b.BidOK || b.BidPrice != 99 || !b.AskOK || b.AskPrice != 101 || b.AskSize != 3 {
+		t.Errorf("expected two-sided quote, got %+v", b)
+	}
+
+	// A worse bid leaves the BBO unchanged and records nothing
+	submit(core.SideBuy, 98, 5)
+	if b, err := svc.BBOAt(1, afterBid); err != nil || b.AskOK {
+		t.Errorf("expected the first change to survive, got %+v, %v", b, err)
+	}
+
+	// A third change evicts the oldest from a ring of two
+	submit(core.SideBuy, 100, 1)
+	if _, err := svc.BBOAt(1, afterBid); err != ErrNoBBOHistory {
+		t.Errorf("expected the oldest change to be evicted, got %v", err)
+	}
+
+	if _, err := svc.BBOAt(99, afterAsk); err != ErrUnknownTicker {
+		t.Errorf("expected ErrUnknownTicker, got %v", err)
+	}
+}
+
+func TestMarketServiceAddTickerLimit(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxTickers = 3
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+
+	if err := svc.AddTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}); err != ErrDuplicateTicker {
+		t.Errorf("expected ErrDuplicateTicker, got %v", err)
+	}
+	for _, tk := range []market.Ticker{
+		{ID: 2, Name: "GOOGL", Decimals: 2},
+		{ID: 3, Name: "MSFT", Decimals: 2},
+	} {
+		if err := svc.AddTicker(tk); err != nil {
+			t.Fatalf("adding %s: unexpected error: %v", tk.Name, err)
+		}
+	}
+	if err := svc.AddTicker(market.Ticker{ID: 4, Name: "AMZN", Decimals: 2}); err != ErrTooManyTickers {
+		t.Errorf("expected ErrTooManyTickers, got %v", err)
+	}
+	if n := len(svc.GetTickers()); n != 3 {
+		t.Errorf("expected 3 tickers, got %d", n)
+	}
+
+	// An added ticker trades like any other
+	ctx := context.Background()
+	if _, err := svc.SubmitLimit(ctx, 3, 100, core.SideSell, 100, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SubmitMarket(ctx, 3, 200, core.SideBuy, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if bp := svc.Snapshot().ByTicker[3]; !bp.HasLast || bp.LastPrice != 100 {
+		t.Errorf("expected a trade on the added ticker, got %+v", bp)
+	}
+}
+
+func TestMarketServiceGetTickersSorted(t *testing.T) {
+	svc := NewMarketService([]market.Ticker{
+		{ID: 5, Name: "TSLA", Decimals: 2},
+		{ID: 2, Name: "GOOGL", Decimals: 2},
+		{ID: 9, Name: "NVDA", Decimals: 2},
+		{ID: 1, Name: "AAPL", Decimals: 2},
+	}, DefaultConfig())
+	defer svc.Close()
+	if err := svc.AddTicker(market.Ticker{ID: 3, Name: "MSFT", Decimals: 2}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []int64{1, 2, 3, 5, 9}
+	for i := 0; i < 20; i++ {
+		tickers := svc.GetTickers()
+		if len(tickers) != len(want) {
+			t.Fatalf("expected %d tickers, got %d", len(want), len(tickers))
+		}
+		for j, tk := range tickers {
+			if tk.ID != want[j] {
+				t.Fatalf("call %d: expected IDs %v, got %+v", i, want, tickers)
+			}
+		}
+	}
+
+	// The result is a copy
+	tickers := svc.GetTickers()
+	tickers[0].Name = "XXXX"
+	if got := svc.GetTickers()[0].Name; got != "AAPL" {
+		t.Errorf("caller edit leaked into the service: %s", got)
+	}
+}
+
+func TestMarketServiceMaxUserFillLogs(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.MaxUserFillLogs = 3
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+
+	// Maker 100 trades with takers 200, 300 and 400 in turn. Each trade
+	// touches the maker's log, so the least recently filled is a taker's.
+	ctx := context.Background()
+	for _, taker := range []core.UserID{200, 300, 400} {
+		if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideSell, 100, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := svc.SubmitMarket(ctx, 1, taker, core.SideBuy, 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	for user, want := range map[core.UserID]int{100: 3, 200: 0, 300: 1, 400: 1} {
+		fills, err := svc.UserFills(1, user, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(fills) != want {
+			t.Errorf("user %d: expected %d fills, got %d", user, want, len(fills))
+		}
+	}
+}
+
+func TestMarketServiceUserPositionPastFillCapacity(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.MaxUserFillLogs = 2
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	// More buys of 2 at 100 than the ring holds
+	n := cfg.UserFillCapacity + 50
+	for i := 0; i < n; i++ {
+		if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideSell, 100, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, 2); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if fills, _ := svc.UserFills(1, 200, n); len(fills) != cfg.UserFillCapacity {
+		t.Fatalf("expected the ring to hold %d fills, got %d", cfg.UserFillCapacity, len(fills))
+	}
+	want := marketview.Position{Net: core.Size(2 * n), Cash: -int64(200 * n)}
+	if got, err := svc.UserTotals(1, 200); err != nil || got != want {
+		t.Errorf("expected totals %+v, got %+v (%v)", want, got, err)
+	}
+
+	// Two other users trading evict both fill logs; the totals stay
+	svc.SubmitLimit(ctx, 1, 300, core.SideSell, 100, 1)
+	svc.SubmitMarket(ctx, 1, 400, core.SideBuy, 1)
+	if fills, _ := svc.UserFills(1, 200, n); len(fills) != 0 {
+		t.Fatalf("expected the taker's fill log evicted, got %d fills", len(fills))
+	}
+	if pos, err := svc.UserPosition(1, 200); err != nil || pos != core.Size(2*n) {
+		t.Errorf("expected position %d after eviction, got %d (%v)", 2*n, pos, err)
+	}
+	if pos, _ := svc.UserPosition(1, 100); pos != -core.Size(2*n) {
+		t.Errorf("expected the maker short %d, got %d", 2*n, pos)
+	}
+
+	// A checkpoint carries the totals
+	restored := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer restored.Close()
+	restored.RestoreViewState(svc.ViewState())
+	if got, _ := restored.UserTotals(1, 200); got != want {
+		t.Errorf("expected restored totals %+v, got %+v", want, got)
+	}
+}
+
+func TestMarketServiceCandles(t *testing.T) {
+	start := time.Unix(1_700_000_000, 0)
+	clk := clock.NewManual(start)
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Book.Clock = clk
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	if got, err := svc.GetCandles(1, time.Second, 10); err != nil || len(got) != 0 {
+		t.Fatalf("expected no candles before trading, got %v, %v", got, err)
+	}
+	trade := func(after time.Duration, price core.PriceTicks, size core.Size) {
+		t.Helper()
+		clk.Advance(after)
+		if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideSell, price, size); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if _, err := svc.SubmitMarket(ctx, 1, 200, core.SideBuy, size); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	trade(0, 100, 5)
+	trade(400*time.Millisecond, 102, 3)
+	trade(700*time.Millisecond, 99, 2) // crosses into the next second
+	trade(3*time.Second, 101, 4)       // after two seconds without trades
+
+	at := func(d time.Duration) int64 { return start.Add(d).UnixNano() }
+	want := []marketview.Candle{
+		{Time: at(0), Open: 100, High: 102, Low: 100, Close: 102, Volume: 8},
+		{Time: at(time.Second), Open: 99, High: 99, Low: 99, Close: 99, Volume: 2},
+		{Time: at(4 * time.Second), Open: 101, High: 101, Low: 101, Close: 101, Volume: 4},
+	}
+	got, err := svc.GetCandles(1, time.Second, 10)
+	if err != nil || !slices.Equal(got, want) {
+		t.Errorf("expected 1s candles %+v, got %+v, %v", want, got, err)
+	}
+
+	// n counts finished candles; the newest is always included
+	if got, _ := svc.GetCandles(1, time.Second, 1); !slices.Equal(got, want[1:]) {
+		t.Errorf("expected the last finished and newest candles, got %+v", got)
+	}
+
+	// Every trade so far is inside one 5s candle
+	got, _ = svc.GetCandles(1, 5*time.Second, 10)
+	if w := (marketview.Candle{Time: at(0), Open: 100, High: 102, Low: 99, Close: 101, Volume: 14}); len(got) != 1 || got[0] != w {
+		t.Errorf("expected 5s candle %+v, got %+v", w, got)
+	}
+
+	if _, err := svc.GetCandles(1, 2*time.Second, 10); err != ErrNoCandles {
+		t.Errorf("expected ErrNoCandles for an interval not kept, got %v", err)
+	}
+	if _, err := svc.GetCandles(9, time.Second, 10); err != ErrUnknownTicker {
+		t.Errorf("expected ErrUnknownTicker, got %v", err)
+	}
+}
+
+func TestMarketServiceBookSnapshotCache(t *testing.T) {
+	clk := clock.NewManual(time.Unix(1_700_000_000, 0))
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Book.Clock = clk
+	cfg.SnapshotCache.MaxStaleness = time.Second
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	computed := func() int {
+		svc.snapshots.mu.Lock()
+		defer svc.snapshots.mu.Unlock()
+		return svc.snapshots.computed
+	}
+	matches := func(snap BookSnapshot) bool {
+		bids, _ := svc.GetLevels(1, core.SideBuy)
+		asks, _ := svc.GetLevels(1, core.SideSell)
+		return slices.Equal(snap.Bids, bids) && slices.Equal(snap.Asks, asks)
+	}
+
+	if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideBuy, 99, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	first, err := svc.BookSnapshot(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !matches(first) || len(first.Bids) != 1 {
+		t.Fatalf("expected the first snapshot to match the book, got %+v", first)
+	}
+	n := computed()
+
+	// Within the bound the cached snapshot is served as is, even though the
+	// book has moved on
+	if _, err := svc.SubmitLimit(ctx, 1, 200, core.SideSell, 101, 5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for range 3 {
+		snap, err := svc.BookSnapshot(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if snap.Time != first.Time || len(snap.Asks) != 0 {
+			t.Fatalf("expected the cached snapshot, got %+v", snap)
+		}
+	}
+	if got := computed(); got != n {
+		t.Errorf("expected no recomputation within the bound, computed %d -> %d", n, got)
+	}
+
+	// Past the bound, whether the refresher or the read recomputes, the
+	// snapshot is fresh
+	clk.Advance(2 * time.Second)
+	snap, err := svc.BookSnapshot(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !matches(snap) || len(snap.Asks) != 1 {
+		t.Errorf("expected a fresh snapshot, got %+v", snap)
+	}
+	if age := clk.Now().UnixNano() - snap.Time; age > int64(time.Second) {
+		t.Errorf("expected a snapshot at most 1s old, got %v", time.Duration(age))
+	}
+
+	if _, err := svc.BookSnapshot(999); err != ErrUnknownTicker {
+		t.Errorf("expected ErrUnknownTicker, got %v", err)
+	}
+}
+
+func TestMarketServiceBookSnapshotUncached(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer svc.Close()
+
+	for i := range 3 {
+		if _, err := svc.SubmitLimit(context.Background(), 1, 100, core.SideBuy, core.PriceTicks(90+i), 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		snap, err := svc.BookSnapshot(1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(snap.Bids) != i+1 {
+			t.Errorf("expected %d bid levels, got %d", i+1, len(snap.Bids))
+		}
+	}
+	if svc.snapshots.computed != 3 {
+		t.Errorf("expected every read to compute, got %d", svc.snapshots.computed)
+	}
+}
+
+func TestMarketServiceSubscribeFilters(t *testing.T) {
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+		{ID: 2, Name: "MSFT", Decimals: 2},
+	}
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewMarketService(tickers, cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	slow := svc.Subscribe(EventFilter{}, pubsub.Drop, 1)
+	trades := svc.Subscribe(EventFilter{Tickers: []market.TickerID{1}, Kinds: EventTrades}, pubsub.Block, 100)
+	book := svc.Subscribe(EventFilter{Kinds: EventBook}, pubsub.Drop, 100)
+
+	for _, tid := range []market.TickerID{1, 2} {
+		if _, err := svc.SubmitLimit(ctx, tid, 200, core.SideSell, 101, 10); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for range 3 {
+			svc.SubmitMarket(ctx, tid, 100, core.SideBuy, 2)
+		}
+	}
+
+	// The slow subscriber fills up and drops; the others are unaffected
+	if slow.Len() != 1 || slow.Dropped() == 0 {
+		t.Errorf("expected the slow subscriber full with drops, got %d queued and %d dropped", slow.Len(), slow.Dropped())
+	}
+	var n int
+	for len(trades.C()) > 0 {
+		me := <-trades.C()
+		if _, ok := me.Event.(core.TradeEvent); !ok || me.Ticker != 1 {
+			t.Errorf("expected only AAPL trades, got %+v", me)
+		}
+		n++
+	}
+	if n != 3 || trades.Dropped() != 0 {
+		t.Errorf("expected 3 trades and no drops, got %d and %d", n, trades.Dropped())
+	}
+	n = 0
+	for len(book.C()) > 0 {
+		if me := <-book.C(); KindOf(me) != EventBook {
+			t.Errorf("expected only book changes, got %+v", me)
+		}
+		n++
+	}
+	if n != 8 {
+		t.Errorf("expected a rest and three reductions per ticker, got %d", n)
+	}
+
+	slow.Unsubscribe()
+	<-slow.C()
+	if _, ok := <-slow.C(); ok {
+		t.Error("expected the channel closed after Unsubscribe")
+	}
 }
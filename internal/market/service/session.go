@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+var ErrSessionPhase = errors.New("invalid session phase")
+
+// BeginPreOpen puts a ticker into pre-open: limit orders are accepted and
+// rest without matching so the opening auction can build up interest.
+// Market orders are rejected until the session opens.
+func (s *MarketService) BeginPreOpen(ctx context.Context, tid market.TickerID) error {
+	book, ok := s.book(tid)
+	if !ok {
+		return ErrUnknownTicker
+	}
+	if err := book.BeginAuction(ctx); err != nil {
+		return err
+	}
+	s.setStatus(tid, market.StatusPreOpen, "pre-open")
+	return nil
+}
+
+// OpenSession runs the opening sequence for a ticker: the auction uncross
+// executes the crossed pre-open interest at a single equilibrium price, then
+// continuous matching is enabled. It also releases a halted ticker through
+// the same auction. A phase event is emitted for each step.
+func (s *MarketService) OpenSession(ctx context.Context, tid market.TickerID) (core.UncrossReport, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return core.UncrossReport{}, ErrUnknownTicker
+	}
+
+	s.statusMu.Lock()
+	st := s.states[tid]
+	if st.status == market.StatusOpen || st.status == market.StatusAuction {
+		s.statusMu.Unlock()
+		return core.UncrossReport{}, fmt.Errorf("%w: %s", ErrSessionPhase, st.status)
+	}
+	s.statusMu.Unlock()
+
+	s.setStatus(tid, market.StatusAuction, "opening auction")
+	report, err := book.Uncross(ctx)
+	if err != nil {
+		return core.UncrossReport{}, err
+	}
+
+	reason := "open"
+	if report.Volume > 0 {
+		reason = fmt.Sprintf("opened %d @ %d", report.Volume, report.Price)
+		// The opening print is the reference for the circuit breaker.
+		s.statusMu.Lock()
+		s.states[tid].refPrice = report.Price
+		s.statusMu.Unlock()
+	}
+	s.setStatus(tid, market.StatusOpen, reason)
+	return report, nil
+}
+
+// setStatus changes a ticker's trading status and emits a phase event.
+func (s *MarketService) setStatus(tid market.TickerID, status market.TradingStatus, reason string) {
+	ev := marketview.StatusEvent{
+		Status: status,
+		Reason: reason,
+		Time:   s.clock.Now().UnixNano(),
+	}
+	s.statusMu.Lock()
+	s.states[tid].set(ev)
+	s.statusMu.Unlock()
+
+	s.emit(marketview.MarketEvent{Ticker: tid, Status: &ev})
+}
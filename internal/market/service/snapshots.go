@@ -0,0 +1,102 @@
+package service
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookservice "github.com/zappabad/stockcraft/internal/orderbook/service"
+	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
+)
+
+// SnapshotCacheConfig configures the warm cache of aggregated book
+// snapshots served by BookSnapshot.
+type SnapshotCacheConfig struct {
+	// MaxStaleness bounds how old a cached snapshot may be when served. The
+	// cache refreshes every ticker in the background at half this interval,
+	// and a read that still finds an older snapshot computes a fresh one
+	// (0 = no cache; every read computes).
+	MaxStaleness time.Duration
+}
+
+// BookSnapshot is a ticker's aggregated levels at one moment, as a new
+// subscriber needs them before following deltas.
+type BookSnapshot struct {
+	Ticker market.TickerID
+	Bids   []orderbookview.Level // best first
+	Asks   []orderbookview.Level // best first
+	Time   int64                 // Unix nanoseconds when the levels were read
+}
+
+// snapshotCache holds the last snapshot computed per ticker.
+type snapshotCache struct {
+	mu       sync.Mutex
+	byTicker map[market.TickerID]BookSnapshot
+	computed int // snapshots computed, for tests
+}
+
+// BookSnapshot returns a ticker's aggregated levels. With
+// Config.SnapshotCache set it serves the cached snapshot, which is at most
+// MaxStaleness old, so bootstrapping many subscribers does not sort the book
+// for each. Subscribers that follow Level2 deltas should subscribe first:
+// deltas carry absolute sizes, so applying ones the snapshot already
+// reflects is harmless.
+func (s *MarketService) BookSnapshot(tid market.TickerID) (BookSnapshot, error) {
+	book, ok := s.book(tid)
+	if !ok {
+		return BookSnapshot{}, ErrUnknownTicker
+	}
+	maxAge := s.cfg.SnapshotCache.MaxStaleness
+	if maxAge <= 0 {
+		return s.computeSnapshot(tid, book), nil
+	}
+
+	now := s.clock.Now().UnixNano()
+	s.snapshots.mu.Lock()
+	snap, ok := s.snapshots.byTicker[tid]
+	s.snapshots.mu.Unlock()
+	if !ok || now-snap.Time > int64(maxAge) {
+		snap = s.computeSnapshot(tid, book)
+	}
+	snap.Bids = slices.Clone(snap.Bids)
+	snap.Asks = slices.Clone(snap.Asks)
+	return snap, nil
+}
+
+// computeSnapshot reads a book's levels and caches them.
+func (s *MarketService) computeSnapshot(tid market.TickerID, book *orderbookservice.Service) BookSnapshot {
+	snap := BookSnapshot{
+		Ticker: tid,
+		Time:   s.clock.Now().UnixNano(),
+		Bids:   book.GetLevels(core.SideBuy),
+		Asks:   book.GetLevels(core.SideSell),
+	}
+	s.snapshots.mu.Lock()
+	defer s.snapshots.mu.Unlock()
+	s.snapshots.computed++
+	if prev, ok := s.snapshots.byTicker[tid]; !ok || prev.Time <= snap.Time {
+		s.snapshots.byTicker[tid] = snap
+	}
+	return snap
+}
+
+// runSnapshotRefresher recomputes every ticker's snapshot at half the
+// staleness bound until the service closes.
+func (s *MarketService) runSnapshotRefresher() {
+	defer s.wg.Done()
+
+	ticker := s.clock.NewTicker(max(s.cfg.SnapshotCache.MaxStaleness/2, 1))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case <-ticker.C():
+			for tid, book := range s.allBooks() {
+				s.computeSnapshot(tid, book)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/pubsub"
+)
+
+// EventKind is a class of market event, for filtering subscriptions. Kinds
+// combine as a bit set.
+type EventKind uint8
+
+const (
+	// EventTrades is trades.
+	EventTrades EventKind = 1 << iota
+	// EventBook is book changes: orders resting, reduced, refreshed and
+	// removed.
+	EventBook
+	// EventStatus is trading status changes and imbalance alerts.
+	EventStatus
+	// EventOther is every other book event, such as stops and cross
+	// resolutions.
+	EventOther
+)
+
+// KindOf returns the kind of a market event.
+func KindOf(me marketview.MarketEvent) EventKind {
+	if me.Status != nil || me.Imbalance != nil {
+		return EventStatus
+	}
+	switch me.Event.(type) {
+	case core.TradeEvent:
+		return EventTrades
+	case core.OrderRestedEvent, core.OrderReducedEvent, core.OrderRemovedEvent, core.OrderRefreshedEvent:
+		return EventBook
+	default:
+		return EventOther
+	}
+}
+
+// EventFilter selects the market events a subscription receives. The zero
+// value selects every event.
+type EventFilter struct {
+	// Tickers restricts it to these tickers (empty = every ticker).
+	Tickers []market.TickerID
+	// Kinds restricts it to these kinds (0 = every kind).
+	Kinds EventKind
+}
+
+// matcher returns the filter as a pubsub keep function, or nil if it keeps
+// everything.
+func (f EventFilter) matcher() func(marketview.MarketEvent) bool {
+	if len(f.Tickers) == 0 && f.Kinds == 0 {
+		return nil
+	}
+	var tickers map[market.TickerID]struct{}
+	if len(f.Tickers) > 0 {
+		tickers = make(map[market.TickerID]struct{}, len(f.Tickers))
+		for _, tid := range f.Tickers {
+			tickers[tid] = struct{}{}
+		}
+	}
+	kinds := f.Kinds
+	return func(me marketview.MarketEvent) bool {
+		if tickers != nil {
+			if _, ok := tickers[me.Ticker]; !ok {
+				return false
+			}
+		}
+		return kinds == 0 || KindOf(me)&kinds != 0
+	}
+}
+
+// Subscribe adds a subscriber to the consolidated market events that pass
+// filter, with its own buffered channel, overflow policy and drop counter.
+// Use Drop for a subscriber that may fall behind: a Block subscriber that
+// stops reading stalls every book's event forwarder. The subscription closes
+// with the service; call Unsubscribe to leave earlier.
+func (s *MarketService) Subscribe(filter EventFilter, policy pubsub.Policy, buffer int) *pubsub.Subscription[marketview.MarketEvent] {
+	return s.bus.SubscribeFunc(policy, buffer, filter.matcher())
+}
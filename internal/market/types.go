@@ -1,16 +1,105 @@
 package market
 
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/zappabad/stockcraft/internal/money"
+)
+
 // TickerID uniquely identifies a ticker.
 type TickerID int64
 
+var (
+	ErrInvalidTickValue = errors.New("tick value must be a finite non-negative number")
+	ErrInvalidLotSize   = errors.New("lot size must not be negative")
+)
+
 // Ticker represents a tradeable instrument.
 type Ticker struct {
 	ID       int64
 	Name     string
 	Decimals int8
+	// TickValue is the currency one tick of price is worth per unit of size,
+	// as for a futures contract. Zero means the price is itself in currency,
+	// so a tick is worth 10^-Decimals.
+	TickValue float64
+	// LotSize is the units of size in one lot, for showing sizes in lots.
+	// Zero means 1.
+	LotSize int64
 }
 
 // TickerID returns the TickerID for this Ticker.
 func (t Ticker) TickerID() TickerID {
 	return TickerID(t.ID)
 }
+
+// Validate checks that the ticker's Decimals is in the supported range and
+// its TickValue and LotSize are usable.
+func (t Ticker) Validate() error {
+	if err := money.ValidateDecimals(t.Decimals); err != nil {
+		return fmt.Errorf("ticker %q: %w", t.Name, err)
+	}
+	if t.TickValue < 0 || math.IsNaN(t.TickValue) || math.IsInf(t.TickValue, 0) {
+		return fmt.Errorf("ticker %q: %w: got %v", t.Name, ErrInvalidTickValue, t.TickValue)
+	}
+	if t.LotSize < 0 {
+		return fmt.Errorf("ticker %q: %w: got %d", t.Name, ErrInvalidLotSize, t.LotSize)
+	}
+	return nil
+}
+
+// CurrencyPerTick returns TickValue, or 10^-Decimals if it is unset.
+func (t Ticker) CurrencyPerTick() float64 {
+	if t.TickValue > 0 {
+		return t.TickValue
+	}
+	return money.ToFloat(1, t.Decimals)
+}
+
+// Lot returns LotSize, or 1 if it is unset.
+func (t Ticker) Lot() int64 {
+	if t.LotSize > 0 {
+		return t.LotSize
+	}
+	return 1
+}
+
+// Currency converts an amount in ticks times size, such as a P&L, to
+// currency.
+func (t Ticker) Currency(ticks int64) float64 {
+	if t.TickValue > 0 {
+		return float64(ticks) * t.TickValue
+	}
+	return money.ToFloat(ticks, t.Decimals)
+}
+
+// TradingStatus is the trading state of a ticker.
+type TradingStatus uint8
+
+const (
+	// StatusOpen accepts new orders.
+	StatusOpen TradingStatus = iota
+	// StatusHalted rejects new orders; cancels are still accepted.
+	StatusHalted
+	// StatusPreOpen accepts limit orders without matching them.
+	StatusPreOpen
+	// StatusAuction is the opening uncross; new orders are rejected.
+	StatusAuction
+)
+
+func (s TradingStatus) String() string {
+	switch s {
+	case StatusOpen:
+		return "OPEN"
+	case StatusHalted:
+		return "HALTED"
+	case StatusPreOpen:
+		return "PRE-OPEN"
+	case StatusAuction:
+		return "AUCTION"
+	default:
+		return "UNKNOWN"
+	}
+}
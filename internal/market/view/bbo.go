@@ -0,0 +1,119 @@
+package view
+
+import (
+	"sort"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookservice "github.com/zappabad/stockcraft/internal/orderbook/service"
+)
+
+// BBO is the best bid and offer of a ticker from Time until the next change.
+type BBO struct {
+	Time     int64
+	BidPrice core.PriceTicks
+	BidSize  core.Size
+	BidOK    bool
+	AskPrice core.PriceTicks
+	AskSize  core.Size
+	AskOK    bool
+}
+
+// Imbalance is the top-of-book size imbalance, (bid - ask) / (bid + ask):
+// +1 is all bid, -1 all offer. It returns false unless both sides are quoted.
+func (b BBO) Imbalance() (float64, bool) {
+	if !b.BidOK || !b.AskOK || b.BidSize+b.AskSize <= 0 {
+		return 0, false
+	}
+	return float64(b.BidSize-b.AskSize) / float64(b.BidSize+b.AskSize), true
+}
+
+func (b BBO) sameQuote(o BBO) bool {
+	return b.BidPrice == o.BidPrice && b.BidSize == o.BidSize && b.BidOK == o.BidOK &&
+		b.AskPrice == o.AskPrice && b.AskSize == o.AskSize && b.AskOK == o.AskOK
+}
+
+// bboRing is a ring buffer of a ticker's BBO changes (bounded memory). Times
+// are non-decreasing from oldest to newest.
+type bboRing struct {
+	buf   []BBO
+	start int
+	count int
+}
+
+func (r *bboRing) at(i int) BBO {
+	return r.buf[(r.start+i)%len(r.buf)]
+}
+
+func (r *bboRing) append(b BBO) {
+	if r.count < len(r.buf) {
+		r.buf[(r.start+r.count)%len(r.buf)] = b
+		r.count++
+		return
+	}
+	// overwrite oldest
+	r.buf[r.start] = b
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+// recordBBO samples the book's best levels after an event that may have
+// moved them and stores the result if the quote changed. Callers must hold
+// the write lock.
+func (v *MarketView) recordBBO(tid market.TickerID, t int64, book *orderbookservice.Service) {
+	b := BBO{Time: t}
+	if l, ok := book.GetBest(core.SideBuy); ok {
+		b.BidPrice, b.BidSize, b.BidOK = l.Price, l.Size, true
+	}
+	if l, ok := book.GetBest(core.SideSell); ok {
+		b.AskPrice, b.AskSize, b.AskOK = l.Price, l.Size, true
+	}
+
+	r, ok := v.bbo[tid]
+	if !ok {
+		r = &bboRing{buf: make([]BBO, v.bboCapacity)}
+		v.bbo[tid] = r
+	}
+	if r.count > 0 {
+		last := r.at(r.count - 1)
+		if last.sameQuote(b) {
+			return
+		}
+		// keep the ring sorted if event times step backwards (e.g. Restore)
+		if b.Time < last.Time {
+			b.Time = last.Time
+		}
+	}
+	r.append(b)
+}
+
+// LastBBO returns the ticker's latest best bid and offer, or false before
+// its book has changed.
+func (v *MarketView) LastBBO(tid market.TickerID) (BBO, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	r, ok := v.bbo[tid]
+	if !ok || r.count == 0 {
+		return BBO{}, false
+	}
+	return r.at(r.count - 1), true
+}
+
+// BBOAt returns the best bid and offer in effect at time t: the last change
+// at or before t. It returns false if t is earlier than the oldest change
+// still held for the ticker.
+func (v *MarketView) BBOAt(tid market.TickerID, t int64) (BBO, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	r, ok := v.bbo[tid]
+	if !ok {
+		return BBO{}, false
+	}
+	// first change after t; the one before it is in effect
+	i := sort.Search(r.count, func(i int) bool { return r.at(i).Time > t })
+	if i == 0 {
+		return BBO{}, false
+	}
+	return r.at(i - 1), true
+}
@@ -0,0 +1,132 @@
+package view
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// DefaultCandleIntervals are the candle intervals DefaultCandleConfig keeps.
+var DefaultCandleIntervals = []time.Duration{time.Second, 5 * time.Second, time.Minute}
+
+// Candle is a ticker's open, high, low, close and volume over one interval.
+type Candle struct {
+	Time   int64 // start of the interval, Unix nanoseconds
+	Open   core.PriceTicks
+	High   core.PriceTicks
+	Low    core.PriceTicks
+	Close  core.PriceTicks
+	Volume core.Size
+}
+
+// CandleConfig configures a CandleAggregator.
+type CandleConfig struct {
+	// Intervals are the candle widths kept for every ticker (none = no
+	// candles).
+	Intervals []time.Duration
+	// Capacity is how many finished candles are kept per ticker and
+	// interval.
+	Capacity int
+}
+
+// DefaultCandleConfig returns a CandleConfig keeping 500 candles at each of
+// DefaultCandleIntervals.
+func DefaultCandleConfig() CandleConfig {
+	return CandleConfig{Intervals: slices.Clone(DefaultCandleIntervals), Capacity: 500}
+}
+
+// candleKey identifies one ticker's candles at one interval.
+type candleKey struct {
+	ticker   market.TickerID
+	interval time.Duration
+}
+
+// candleSeries is one ticker's candles at one interval: the finished ones,
+// oldest first, and the one the latest trade went into.
+type candleSeries struct {
+	done    []Candle
+	current Candle
+}
+
+// CandleAggregator builds OHLCV candles from trades. An interval in which a
+// ticker did not trade has no candle, so candle times can skip. It is safe
+// for concurrent use.
+type CandleAggregator struct {
+	mu     sync.RWMutex
+	cfg    CandleConfig
+	series map[candleKey]*candleSeries
+}
+
+// NewCandleAggregator creates a CandleAggregator. Intervals of zero or less
+// are ignored.
+func NewCandleAggregator(cfg CandleConfig) *CandleAggregator {
+	var intervals []time.Duration
+	for _, d := range cfg.Intervals {
+		if d > 0 && !slices.Contains(intervals, d) {
+			intervals = append(intervals, d)
+		}
+	}
+	cfg.Intervals = intervals
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = 1
+	}
+	return &CandleAggregator{cfg: cfg, series: make(map[candleKey]*candleSeries)}
+}
+
+// Add folds a trade into the ticker's candle at every interval. A trade in
+// a later interval than the current candle finishes it; trades older than
+// the current candle are ignored.
+func (a *CandleAggregator) Add(tid market.TickerID, tr core.TradeEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, d := range a.cfg.Intervals {
+		start := tr.Time - tr.Time%int64(d)
+		key := candleKey{ticker: tid, interval: d}
+		s := a.series[key]
+		switch {
+		case s == nil:
+			a.series[key] = &candleSeries{current: newCandle(start, tr)}
+		case start > s.current.Time:
+			s.done = append(s.done, s.current)
+			if over := len(s.done) - a.cfg.Capacity; over > 0 {
+				s.done = slices.Delete(s.done, 0, over)
+			}
+			s.current = newCandle(start, tr)
+		case start == s.current.Time:
+			c := &s.current
+			c.High = max(c.High, tr.Price)
+			c.Low = min(c.Low, tr.Price)
+			c.Close = tr.Price
+			c.Volume += tr.Size
+		}
+	}
+}
+
+func newCandle(start int64, tr core.TradeEvent) Candle {
+	return Candle{Time: start, Open: tr.Price, High: tr.Price, Low: tr.Price, Close: tr.Price, Volume: tr.Size}
+}
+
+// Candles returns up to the last n finished candles of a ticker at an
+// interval followed by the newest one, oldest first. The newest is the
+// candle the latest trade went into, which may still be receiving trades.
+// It returns false if the interval is not kept.
+func (a *CandleAggregator) Candles(tid market.TickerID, interval time.Duration, n int) ([]Candle, bool) {
+	if !slices.Contains(a.cfg.Intervals, interval) {
+		return nil, false
+	}
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	s := a.series[candleKey{ticker: tid, interval: interval}]
+	if s == nil {
+		return []Candle{}, true
+	}
+	done := s.done[len(s.done)-min(max(n, 0), len(s.done)):]
+	out := make([]Candle, 0, len(done)+1)
+	out = append(out, done...)
+	return append(out, s.current), true
+}
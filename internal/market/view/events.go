@@ -5,8 +5,35 @@ import (
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 )
 
+// StatusEvent reports a change in a ticker's trading status.
+type StatusEvent struct {
+	Status market.TradingStatus
+	Reason string
+	Time   int64
+	// ResumeAt is when trading is expected to resume, in Unix nanoseconds,
+	// or 0 if unknown.
+	ResumeAt int64
+}
+
+// ImbalanceEvent reports that a ticker's top-of-book imbalance reached the
+// alert threshold (see BBO.Imbalance).
+type ImbalanceEvent struct {
+	// Imbalance is the imbalance that crossed the threshold.
+	Imbalance float64
+	// Side is the heavy side: SideBuy when bids outweigh offers.
+	Side     core.Side
+	BidPrice core.PriceTicks
+	BidSize  core.Size
+	AskPrice core.PriceTicks
+	AskSize  core.Size
+	Time     int64
+}
+
 // MarketEvent wraps a core event with its associated ticker.
+// Exactly one of Event, Status and Imbalance is set.
 type MarketEvent struct {
-	Ticker market.TickerID
-	Event  core.Event
+	Ticker    market.TickerID
+	Event     core.Event
+	Status    *StatusEvent
+	Imbalance *ImbalanceEvent
 }
@@ -0,0 +1,133 @@
+package view
+
+import (
+	"container/list"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// FillRole is the part a user's order played in a trade.
+type FillRole uint8
+
+const (
+	// RoleTaker is the incoming order that crossed the book.
+	RoleTaker FillRole = iota
+	// RoleMaker is the resting order that was hit.
+	RoleMaker
+)
+
+func (r FillRole) String() string {
+	switch r {
+	case RoleTaker:
+		return "TAKER"
+	case RoleMaker:
+		return "MAKER"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Fill is one user's side of a trade.
+type Fill struct {
+	OrderID      core.OrderID
+	Side         core.Side
+	Role         FillRole
+	Price        core.PriceTicks
+	Size         core.Size
+	Time         int64
+	Counterparty core.UserID // the user on the other side of the trade
+}
+
+// fillKey identifies a user's fill log on one ticker.
+type fillKey struct {
+	ticker market.TickerID
+	user   core.UserID
+}
+
+// fillLog is a ring buffer of a user's most recent fills (bounded memory).
+type fillLog struct {
+	buf   []Fill
+	start int
+	count int
+	use   *list.Element // in MarketView.fillOrder, holding the fillKey
+}
+
+func (l *fillLog) append(f Fill) {
+	if l.count < len(l.buf) {
+		l.buf[(l.start+l.count)%len(l.buf)] = f
+		l.count++
+		return
+	}
+	// overwrite oldest
+	l.buf[l.start] = f
+	l.start = (l.start + 1) % len(l.buf)
+}
+
+// last returns the last n fills in chronological order.
+func (l *fillLog) last(n int) []Fill {
+	if n > l.count {
+		n = l.count
+	}
+	out := make([]Fill, n)
+	first := l.start + l.count - n
+	for i := range out {
+		out[i] = l.buf[(first+i)%len(l.buf)]
+	}
+	return out
+}
+
+// recordFills appends both sides of a trade to their users' fill logs. A
+// self-trade records a taker and a maker fill for the same user.
+// Callers must hold the write lock.
+func (v *MarketView) recordFills(tid market.TickerID, tr core.TradeEvent) {
+	v.logFor(tid, tr.TakerUserID).append(Fill{
+		OrderID:      tr.TakerOrderID,
+		Side:         tr.TakerSide,
+		Role:         RoleTaker,
+		Price:        tr.Price,
+		Size:         tr.Size,
+		Time:         tr.Time,
+		Counterparty: tr.MakerUserID,
+	})
+	v.logFor(tid, tr.MakerUserID).append(Fill{
+		OrderID:      tr.MakerOrderID,
+		Side:         tr.TakerSide.Opposite(),
+		Role:         RoleMaker,
+		Price:        tr.Price,
+		Size:         tr.Size,
+		Time:         tr.Time,
+		Counterparty: tr.TakerUserID,
+	})
+}
+
+// logFor returns the fill log of a user on a ticker, creating it (and
+// evicting the least recently filled log if at MaxFillLogs) if needed. The
+// log is marked as just used.
+func (v *MarketView) logFor(tid market.TickerID, user core.UserID) *fillLog {
+	k := fillKey{ticker: tid, user: user}
+	if l, ok := v.fills[k]; ok {
+		v.fillOrder.MoveToBack(l.use)
+		return l
+	}
+	if v.maxFillLogs > 0 && len(v.fills) >= v.maxFillLogs {
+		oldest := v.fillOrder.Front()
+		delete(v.fills, v.fillOrder.Remove(oldest).(fillKey))
+	}
+	l := &fillLog{buf: make([]Fill, v.fillCapacity), use: v.fillOrder.PushBack(k)}
+	v.fills[k] = l
+	return l
+}
+
+// UserFills returns a user's last n fills on a ticker in chronological
+// order. Returns a copy (not internal references).
+func (v *MarketView) UserFills(tid market.TickerID, user core.UserID, n int) []Fill {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	l, ok := v.fills[fillKey{ticker: tid, user: user}]
+	if !ok || n <= 0 {
+		return nil
+	}
+	return l.last(n)
+}
@@ -0,0 +1,107 @@
+package view
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// Position is a user's running totals on a ticker over every trade recorded,
+// unlike the fill logs, which hold only the latest fills.
+type Position struct {
+	// Net is the shares bought less the shares sold: long > 0.
+	Net core.Size
+	// Cash is the notional sold less the notional bought, in ticks.
+	Cash int64
+}
+
+// positionTotals is a Position that stops being exact once a total
+// overflows.
+type positionTotals struct {
+	Position
+	overflow bool
+}
+
+func (p *positionTotals) add(side core.Side, price core.PriceTicks, size core.Size) {
+	if p.overflow {
+		return
+	}
+	notional, ok := money.MulChecked(int64(price), int64(size))
+	if !ok {
+		p.overflow = true
+		return
+	}
+	net, cash := int64(p.Net), p.Cash
+	if side == core.SideBuy {
+		net, ok = money.AddChecked(net, int64(size))
+		if ok {
+			cash, ok = money.SubChecked(cash, notional)
+		}
+	} else {
+		net, ok = money.SubChecked(net, int64(size))
+		if ok {
+			cash, ok = money.AddChecked(cash, notional)
+		}
+	}
+	if !ok {
+		p.overflow = true
+		return
+	}
+	p.Net, p.Cash = core.Size(net), cash
+}
+
+// RecordPosition adds both sides of a trade to their users' positions. It
+// must see every trade exactly once, so the market service calls it from
+// each book's dispatcher rather than through Apply, whose events can drop.
+func (v *MarketView) RecordPosition(tid market.TickerID, tr core.TradeEvent) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.positionFor(tid, tr.TakerUserID).add(tr.TakerSide, tr.Price, tr.Size)
+	v.positionFor(tid, tr.MakerUserID).add(tr.TakerSide.Opposite(), tr.Price, tr.Size)
+}
+
+// positionFor returns a user's totals on a ticker, creating them if needed.
+// Callers must hold the write lock.
+func (v *MarketView) positionFor(tid market.TickerID, user core.UserID) *positionTotals {
+	k := fillKey{ticker: tid, user: user}
+	p, ok := v.positions[k]
+	if !ok {
+		p = &positionTotals{}
+		v.positions[k] = p
+	}
+	return p
+}
+
+// UserPosition returns a user's totals on a ticker, zero if they have not
+// traded it. It returns money.ErrOverflow once a total has overflowed, as
+// the figures are no longer exact.
+func (v *MarketView) UserPosition(tid market.TickerID, user core.UserID) (Position, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	p, ok := v.positions[fillKey{ticker: tid, user: user}]
+	if !ok {
+		return Position{}, nil
+	}
+	if p.overflow {
+		return Position{}, money.ErrOverflow
+	}
+	return p.Position, nil
+}
+
+// exportPositions returns every user's totals, by ticker then user.
+// Callers must hold the read lock.
+func (v *MarketView) exportPositions() []PositionState {
+	out := make([]PositionState, 0, len(v.positions))
+	for k, p := range v.positions {
+		out = append(out, PositionState{Ticker: k.ticker, User: k.user, Position: p.Position, Overflow: p.overflow})
+	}
+	slices.SortFunc(out, func(a, b PositionState) int {
+		return cmp.Or(cmp.Compare(a.Ticker, b.Ticker), cmp.Compare(a.User, b.User))
+	})
+	return out
+}
@@ -0,0 +1,110 @@
+package view
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// State is the part of a MarketView a checkpoint carries: the session
+// totals of every ticker, every user's positions and their fill logs. BBO
+// history is not included.
+type State struct {
+	Tickers []TickerState
+	// Fills are ordered least recently filled first, so restoring them
+	// keeps MaxFillLogs eviction order.
+	Fills     []FillLogState
+	Positions []PositionState
+}
+
+// TickerState is one ticker's session totals.
+type TickerState struct {
+	Ticker    market.TickerID
+	LastTrade core.TradeEvent
+	HasLast   bool
+	Volume    core.Size
+	Trades    int
+	Updated   int64
+}
+
+// PositionState is one user's totals on a ticker. Overflow marks totals
+// that are no longer exact.
+type PositionState struct {
+	Ticker   market.TickerID
+	User     core.UserID
+	Position Position
+	Overflow bool
+}
+
+// FillLogState is one user's held fills on a ticker, oldest first.
+type FillLogState struct {
+	Ticker market.TickerID
+	User   core.UserID
+	Fills  []Fill
+}
+
+// Export returns a copy of the view's state.
+func (v *MarketView) Export() State {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var st State
+	for tid, updated := range v.updated {
+		trade, ok := v.lastTrade[tid]
+		st.Tickers = append(st.Tickers, TickerState{
+			Ticker:    tid,
+			LastTrade: trade,
+			HasLast:   ok,
+			Volume:    v.volume[tid],
+			Trades:    v.trades[tid],
+			Updated:   updated,
+		})
+	}
+	slices.SortFunc(st.Tickers, func(a, b TickerState) int { return cmp.Compare(a.Ticker, b.Ticker) })
+
+	for e := v.fillOrder.Front(); e != nil; e = e.Next() {
+		k := e.Value.(fillKey)
+		l := v.fills[k]
+		st.Fills = append(st.Fills, FillLogState{Ticker: k.ticker, User: k.user, Fills: l.last(l.count)})
+	}
+	st.Positions = v.exportPositions()
+	return st
+}
+
+// Restore replaces the view's session totals, positions and fill logs with
+// st. Fill
+// logs are trimmed to the view's Config. Book events applied afterwards
+// only move LastUpdateTime forward, so resting restored orders keeps it.
+func (v *MarketView) Restore(st State) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	clear(v.lastTrade)
+	clear(v.volume)
+	clear(v.trades)
+	clear(v.updated)
+	for _, ts := range st.Tickers {
+		if ts.HasLast {
+			v.lastTrade[ts.Ticker] = ts.LastTrade
+		}
+		v.volume[ts.Ticker] = ts.Volume
+		v.trades[ts.Ticker] = ts.Trades
+		v.updated[ts.Ticker] = ts.Updated
+	}
+
+	clear(v.fills)
+	v.fillOrder.Init()
+	for _, fs := range st.Fills {
+		l := v.logFor(fs.Ticker, fs.User)
+		for _, f := range fs.Fills {
+			l.append(f)
+		}
+	}
+
+	clear(v.positions)
+	for _, ps := range st.Positions {
+		*v.positionFor(ps.Ticker, ps.User) = positionTotals{Position: ps.Position, overflow: ps.Overflow}
+	}
+}
@@ -1,7 +1,9 @@
 package view
 
 import (
+	"container/list"
 	"sync"
+	"time"
 
 	"github.com/zappabad/stockcraft/internal/market"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
@@ -19,6 +21,15 @@ type BestPrices struct {
 	LastPrice core.PriceTicks
 	LastTime  int64
 	HasLast   bool
+	Volume    core.Size // traded this session
+	// TradeCount is the number of trades this session.
+	TradeCount int
+	// LastUpdateTime is the time of the latest trade or book change, 0 if
+	// the ticker has had neither.
+	LastUpdateTime int64
+	// Status is the ticker's trading status. The view does not track it;
+	// MarketService.Snapshot fills it in.
+	Status market.TradingStatus
 }
 
 // MarketSnapshot is a point-in-time snapshot of all tickers.
@@ -30,26 +41,96 @@ type MarketSnapshot struct {
 type MarketView struct {
 	mu        sync.RWMutex
 	lastTrade map[market.TickerID]core.TradeEvent
+	volume    map[market.TickerID]core.Size
+	trades    map[market.TickerID]int
+	updated   map[market.TickerID]int64 // time of the latest trade or book change
+
+	fills        map[fillKey]*fillLog
+	fillCapacity int
+	maxFillLogs  int
+	fillOrder    *list.List // fillKeys, least recently filled first
+
+	positions map[fillKey]*positionTotals // never evicted
+
+	bbo         map[market.TickerID]*bboRing
+	bboCapacity int
+
+	candles *CandleAggregator
+}
+
+// Config bounds the per-user and per-ticker history a MarketView keeps.
+type Config struct {
+	// FillCapacity is how many fills are kept per user and ticker.
+	FillCapacity int
+	// MaxFillLogs caps how many (ticker, user) fill logs are kept; the log
+	// least recently filled is dropped first (0 = unlimited).
+	MaxFillLogs int
+	// BBOCapacity is how many best bid/offer changes are kept per ticker.
+	BBOCapacity int
+	// Candles configures the OHLCV candles built from trades.
+	Candles CandleConfig
 }
 
 // NewMarketView creates a new MarketView.
-func NewMarketView() *MarketView {
+func NewMarketView(cfg Config) *MarketView {
+	if cfg.FillCapacity <= 0 {
+		cfg.FillCapacity = 1
+	}
+	if cfg.BBOCapacity <= 0 {
+		cfg.BBOCapacity = 1
+	}
 	return &MarketView{
-		lastTrade: make(map[market.TickerID]core.TradeEvent),
+		lastTrade:    make(map[market.TickerID]core.TradeEvent),
+		volume:       make(map[market.TickerID]core.Size),
+		trades:       make(map[market.TickerID]int),
+		updated:      make(map[market.TickerID]int64),
+		fills:        make(map[fillKey]*fillLog),
+		fillCapacity: cfg.FillCapacity,
+		maxFillLogs:  cfg.MaxFillLogs,
+		fillOrder:    list.New(),
+		positions:    make(map[fillKey]*positionTotals),
+		bbo:          make(map[market.TickerID]*bboRing),
+		bboCapacity:  cfg.BBOCapacity,
+		candles:      NewCandleAggregator(cfg.Candles),
 	}
 }
 
 // Apply updates the view with an event from a specific ticker's orderbook.
+// Positions are not updated here; see RecordPosition.
 func (v *MarketView) Apply(tid market.TickerID, ev core.Event, book *orderbookservice.Service) {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
-	// Update last trade from TradeEvent
-	if trade, ok := ev.(core.TradeEvent); ok {
-		v.lastTrade[tid] = trade
+	switch e := ev.(type) {
+	case core.TradeEvent:
+		v.lastTrade[tid] = e
+		v.volume[tid] += e.Size
+		v.trades[tid]++
+		v.updated[tid] = max(v.updated[tid], e.Time)
+		v.recordFills(tid, e)
+		v.candles.Add(tid, e)
+	// Level changes always follow a trade as reduced/removed/refreshed events
+	case core.OrderRestedEvent:
+		v.updated[tid] = max(v.updated[tid], e.Time)
+		v.recordBBO(tid, e.Time, book)
+	case core.OrderReducedEvent:
+		v.updated[tid] = max(v.updated[tid], e.MatchTime)
+		v.recordBBO(tid, e.MatchTime, book)
+	case core.OrderRemovedEvent:
+		v.updated[tid] = max(v.updated[tid], e.Time)
+		v.recordBBO(tid, e.Time, book)
+	case core.OrderRefreshedEvent:
+		v.updated[tid] = max(v.updated[tid], e.Time)
+		v.recordBBO(tid, e.Time, book)
 	}
 }
 
+// Candles returns a ticker's candles at an interval (see
+// CandleAggregator.Candles).
+func (v *MarketView) Candles(tid market.TickerID, interval time.Duration, n int) ([]Candle, bool) {
+	return v.candles.Candles(tid, interval, n)
+}
+
 // Snapshot returns a deep copy of the current market state.
 // For best bid/ask, it queries each book's levels (acceptable for TUI scale).
 func (v *MarketView) Snapshot() MarketSnapshot {
@@ -66,6 +147,10 @@ func (v *MarketView) Snapshot() MarketSnapshot {
 			LastPrice: trade.Price,
 			LastTime:  trade.Time,
 			HasLast:   true,
+			Volume:    v.volume[tid],
+
+			TradeCount:     v.trades[tid],
+			LastUpdateTime: v.updated[tid],
 		}
 		snap.ByTicker[tid] = bp
 	}
@@ -107,6 +192,9 @@ func (v *MarketView) SnapshotWithBooks(books map[market.TickerID]*orderbookservi
 			bp.LastTime = trade.Time
 			bp.HasLast = true
 		}
+		bp.Volume = v.volume[tid]
+		bp.TradeCount = v.trades[tid]
+		bp.LastUpdateTime = v.updated[tid]
 
 		snap.ByTicker[tid] = bp
 	}
@@ -0,0 +1,49 @@
+package money
+
+import (
+	"errors"
+	"math"
+)
+
+// ErrOverflow is returned when a notional, fee or P&L does not fit in an
+// int64.
+var ErrOverflow = errors.New("int64 overflow")
+
+// MulChecked returns a × b and whether it fit in an int64.
+func MulChecked(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	if (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) || c/b != a {
+		return 0, false
+	}
+	return c, true
+}
+
+// AddChecked returns a + b and whether it fit in an int64.
+func AddChecked(a, b int64) (int64, bool) {
+	c := a + b
+	if (b > 0 && c < a) || (b < 0 && c > a) {
+		return 0, false
+	}
+	return c, true
+}
+
+// SubChecked returns a - b and whether it fit in an int64.
+func SubChecked(a, b int64) (int64, bool) {
+	c := a - b
+	if (b > 0 && c > a) || (b < 0 && c < a) {
+		return 0, false
+	}
+	return c, true
+}
+
+// Notional returns price × size in ticks, or ErrOverflow.
+func Notional(price, size int64) (int64, error) {
+	n, ok := MulChecked(price, size)
+	if !ok {
+		return 0, ErrOverflow
+	}
+	return n, nil
+}
@@ -0,0 +1,80 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestMulChecked(t *testing.T) {
+	tests := []struct {
+		a, b int64
+		want int64
+		ok   bool
+	}{
+		{0, math.MaxInt64, 0, true},
+		{math.MinInt64, 0, 0, true},
+		{15025, 1000, 15025000, true},
+		{-3, 7, -21, true},
+		{math.MaxInt64, 1, math.MaxInt64, true},
+		{math.MaxInt64, -1, -math.MaxInt64, true},
+		{math.MinInt64, 1, math.MinInt64, true},
+		{math.MaxInt64 / 2, 2, math.MaxInt64 - 1, true},
+		{1 << 31, 1 << 31, 1 << 62, true},
+		{math.MinInt64 / 2, 2, math.MinInt64, true},
+
+		// One past the boundary in every direction
+		{math.MaxInt64/2 + 1, 2, 0, false},
+		{math.MinInt64/2 - 1, 2, 0, false},
+		{math.MaxInt64, 2, 0, false},
+		{math.MaxInt64, -2, 0, false},
+		{math.MinInt64, -1, 0, false},
+		{-1, math.MinInt64, 0, false},
+		{1 << 32, 1 << 31, 0, false},
+		{3037000500, 3037000500, 0, false}, // just over sqrt(MaxInt64)
+	}
+	for _, tt := range tests {
+		got, ok := MulChecked(tt.a, tt.b)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("MulChecked(%d, %d) = %d, %v, want %d, %v", tt.a, tt.b, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	// Just under sqrt(MaxInt64) still fits
+	if got, ok := MulChecked(3037000499, 3037000499); !ok || got != 3037000499*3037000499 {
+		t.Errorf("expected 3037000499² to fit, got %d, %v", got, ok)
+	}
+}
+
+func TestAddSubChecked(t *testing.T) {
+	if got, ok := AddChecked(math.MaxInt64-1, 1); !ok || got != math.MaxInt64 {
+		t.Errorf("expected MaxInt64, got %d, %v", got, ok)
+	}
+	if _, ok := AddChecked(math.MaxInt64, 1); ok {
+		t.Error("expected MaxInt64 + 1 to overflow")
+	}
+	if _, ok := AddChecked(math.MinInt64, -1); ok {
+		t.Error("expected MinInt64 - 1 to overflow")
+	}
+	if got, ok := SubChecked(math.MinInt64+1, 1); !ok || got != math.MinInt64 {
+		t.Errorf("expected MinInt64, got %d, %v", got, ok)
+	}
+	if _, ok := SubChecked(math.MinInt64, 1); ok {
+		t.Error("expected MinInt64 - 1 to overflow")
+	}
+	if _, ok := SubChecked(0, math.MinInt64); ok {
+		t.Error("expected 0 - MinInt64 to overflow")
+	}
+	if got, ok := SubChecked(-1, math.MinInt64); !ok || got != math.MaxInt64 {
+		t.Errorf("expected MaxInt64, got %d, %v", got, ok)
+	}
+}
+
+func TestNotional(t *testing.T) {
+	if n, err := Notional(15025, 100); err != nil || n != 1502500 {
+		t.Errorf("expected 1502500, got %d, %v", n, err)
+	}
+	if _, err := Notional(math.MaxInt64/1000, 1001); !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package money
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Display is how prices are shown to the player. The zero value is
+// DisplayDecimal, the FormatPrice rendering.
+type Display uint8
+
+const (
+	// DisplayDecimal shows the decimal price, e.g. "150.25".
+	DisplayDecimal Display = iota
+	// DisplayTicks shows the raw tick count, e.g. "15025".
+	DisplayTicks
+	// DisplayCurrency shows the decimal price with a currency sign, e.g.
+	// "$150.25".
+	DisplayCurrency
+)
+
+// CurrencySign prefixes prices under DisplayCurrency.
+const CurrencySign = "$"
+
+var displayNames = [...]string{
+	DisplayDecimal:  "decimal",
+	DisplayTicks:    "ticks",
+	DisplayCurrency: "currency",
+}
+
+func (d Display) String() string {
+	if int(d) < len(displayNames) {
+		return displayNames[d]
+	}
+	return "unknown"
+}
+
+// Next returns the mode after d, cycling decimal, ticks, currency.
+func (d Display) Next() Display {
+	return (d + 1) % Display(len(displayNames))
+}
+
+// FormatPrice renders ticks in the display mode. Decimal and currency
+// follow FormatPrice, so unsupported decimals still show raw ticks.
+func (d Display) FormatPrice(ticks int64, decimals int8) string {
+	switch d {
+	case DisplayTicks:
+		return strconv.FormatInt(ticks, 10)
+	case DisplayCurrency:
+		s := FormatPrice(ticks, decimals)
+		if ticks < 0 {
+			return "-" + CurrencySign + s[1:]
+		}
+		return CurrencySign + s
+	default:
+		return FormatPrice(ticks, decimals)
+	}
+}
+
+// MarshalText encodes the mode by name.
+func (d Display) MarshalText() ([]byte, error) {
+	if int(d) >= len(displayNames) {
+		return nil, fmt.Errorf("money: unknown display %d", d)
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a mode name.
+func (d *Display) UnmarshalText(b []byte) error {
+	for i, name := range displayNames {
+		if name == string(b) {
+			*d = Display(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("money: unknown display %q", b)
+}
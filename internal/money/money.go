@@ -0,0 +1,66 @@
+// Package money converts between integer price ticks and decimal prices.
+//
+// A ticker's Decimals says how many of a price's digits are fractional, so
+// 15025 ticks at 2 decimals is 150.25. All scaling goes through the
+// precomputed Pow10 table here rather than ad hoc loops, which keeps the
+// supported range in one place. Every price shown or typed in the UI goes
+// through FormatPrice and ParsePrice, which round-trip exactly.
+package money
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// MaxDecimals is the largest supported number of fractional digits.
+const MaxDecimals = 8
+
+var ErrInvalidDecimals = fmt.Errorf("decimals must be between 0 and %d", MaxDecimals)
+
+var pow10 = [MaxDecimals + 1]int64{
+	1, 10, 100, 1000, 10000, 100000, 1000000, 10000000, 100000000,
+}
+
+// ValidateDecimals reports whether d is a supported number of decimals.
+func ValidateDecimals(d int8) error {
+	if d < 0 || d > MaxDecimals {
+		return fmt.Errorf("%w: got %d", ErrInvalidDecimals, d)
+	}
+	return nil
+}
+
+// Pow10 returns 10^d, the number of ticks in one whole unit at d decimals.
+// It returns ErrInvalidDecimals outside 0..MaxDecimals.
+func Pow10(d int8) (int64, error) {
+	if err := ValidateDecimals(d); err != nil {
+		return 0, err
+	}
+	return pow10[d], nil
+}
+
+// FormatPrice renders ticks as a decimal string, e.g. 15025 at 2 decimals is
+// "150.25". Unsupported decimals render the raw tick count so a bad ticker
+// never breaks the display.
+func FormatPrice(ticks int64, decimals int8) string {
+	div, err := Pow10(decimals)
+	if err != nil || div == 1 {
+		return strconv.FormatInt(ticks, 10)
+	}
+	sign := ""
+	whole, frac := ticks/div, ticks%div
+	if ticks < 0 {
+		sign = "-"
+		whole, frac = -whole, -frac
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, whole, int(decimals), frac)
+}
+
+// ToFloat converts ticks to a float64 in whole units. Unsupported decimals
+// are treated as 0.
+func ToFloat(ticks int64, decimals int8) float64 {
+	div, err := Pow10(decimals)
+	if err != nil {
+		return float64(ticks)
+	}
+	return float64(ticks) / float64(div)
+}
@@ -0,0 +1,97 @@
+package money
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatPrice(t *testing.T) {
+	tests := []struct {
+		ticks    int64
+		decimals int8
+		want     string
+	}{
+		{15025, 0, "15025"},
+		{15025, 2, "150.25"},
+		{5, 2, "0.05"},
+		{-5, 2, "-0.05"},
+		{-15025, 2, "-150.25"},
+		{123456789, 8, "1.23456789"},
+		{1, 8, "0.00000001"},
+		{15025, 9, "15025"},  // unsupported: raw ticks
+		{15025, 19, "15025"}, // would overflow a 10^d divisor
+		{15025, -1, "15025"},
+	}
+	for _, tt := range tests {
+		if got := FormatPrice(tt.ticks, tt.decimals); got != tt.want {
+			t.Errorf("FormatPrice(%d, %d) = %q, want %q", tt.ticks, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestValidateDecimals(t *testing.T) {
+	for _, d := range []int8{0, 2, 8} {
+		if err := ValidateDecimals(d); err != nil {
+			t.Errorf("decimals %d: unexpected error %v", d, err)
+		}
+	}
+	for _, d := range []int8{-1, 9, 19, 127} {
+		if err := ValidateDecimals(d); !errors.Is(err, ErrInvalidDecimals) {
+			t.Errorf("decimals %d: expected ErrInvalidDecimals, got %v", d, err)
+		}
+	}
+}
+
+func TestPow10(t *testing.T) {
+	want := int64(1)
+	for d := int8(0); d <= MaxDecimals; d++ {
+		got, err := Pow10(d)
+		if err != nil || got != want {
+			t.Errorf("Pow10(%d) = %d, %v; want %d", d, got, err, want)
+		}
+		want *= 10
+	}
+	if _, err := Pow10(MaxDecimals + 1); err == nil {
+		t.Error("expected error beyond MaxDecimals")
+	}
+	if got := ToFloat(15025, 2); got != 150.25 {
+		t.Errorf("ToFloat(15025, 2) = %v, want 150.25", got)
+	}
+}
+
+func TestDisplayFormatPrice(t *testing.T) {
+	tests := []struct {
+		display Display
+		ticks   int64
+		want    string
+	}{
+		{DisplayDecimal, 15025, "150.25"},
+		{DisplayTicks, 15025, "15025"},
+		{DisplayCurrency, 15025, "$150.25"},
+		{DisplayDecimal, -5, "-0.05"},
+		{DisplayTicks, -5, "-5"},
+		{DisplayCurrency, -5, "-$0.05"},
+	}
+	for _, tt := range tests {
+		if got := tt.display.FormatPrice(tt.ticks, 2); got != tt.want {
+			t.Errorf("%v: FormatPrice(%d, 2) = %q, want %q", tt.display, tt.ticks, got, tt.want)
+		}
+	}
+
+	// Cycling visits every mode and round-trips through its name
+	d := DisplayDecimal
+	for range displayNames {
+		text, err := d.MarshalText()
+		if err != nil {
+			t.Fatalf("%v: %v", d, err)
+		}
+		var back Display
+		if err := back.UnmarshalText(text); err != nil || back != d {
+			t.Errorf("%q decoded as %v, %v", text, back, err)
+		}
+		d = d.Next()
+	}
+	if d != DisplayDecimal {
+		t.Errorf("expected the cycle to return to decimal, got %v", d)
+	}
+}
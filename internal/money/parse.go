@@ -0,0 +1,110 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Parse errors. A *ParseError wraps one of these, so callers can test with
+// errors.Is and show the ParseError's message as is.
+var (
+	ErrEmpty     = errors.New("price is empty")
+	ErrSyntax    = errors.New("price is not a decimal number")
+	ErrSeparator = errors.New("',' is ambiguous: use '.' for decimals and no thousands separators")
+	ErrPrecision = errors.New("price has more decimal places than the ticker allows")
+	ErrRange     = errors.New("price is out of range")
+)
+
+// ParseError reports a price string that could not be parsed.
+type ParseError struct {
+	Input    string
+	Decimals int8
+	Err      error
+}
+
+func (e *ParseError) Error() string {
+	if errors.Is(e.Err, ErrPrecision) {
+		return fmt.Sprintf("%q: more than %d decimal places", e.Input, e.Decimals)
+	}
+	return fmt.Sprintf("%q: %v", e.Input, e.Err)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ParsePrice parses a decimal price into ticks at the given decimals, the
+// inverse of FormatPrice: ParsePrice(FormatPrice(t, d), d) is t for every
+// int64 t and supported d.
+//
+// The format is fixed rather than taken from the user's locale: an optional
+// '-', digits, and an optional '.' followed by at least one digit. The
+// leading zero may be left out (".50"), and fractional digits beyond
+// decimals are allowed only if they are zeros ("150.250" at 2 decimals).
+// Surrounding spaces are ignored. Commas are rejected with ErrSeparator,
+// since "1,50" reads as 1.50 in some locales and 150 in others.
+func ParsePrice(s string, decimals int8) (int64, error) {
+	if err := ValidateDecimals(decimals); err != nil {
+		return 0, err
+	}
+	fail := func(err error) (int64, error) {
+		return 0, &ParseError{Input: s, Decimals: decimals, Err: err}
+	}
+
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return fail(ErrEmpty)
+	}
+	if strings.ContainsRune(str, ',') {
+		return fail(ErrSeparator)
+	}
+	neg := false
+	if str[0] == '-' {
+		neg, str = true, str[1:]
+	}
+	whole, frac, hasPoint := strings.Cut(str, ".")
+	if (whole == "" && frac == "") || (hasPoint && frac == "") || !digits(whole) || !digits(frac) {
+		return fail(ErrSyntax)
+	}
+	if len(frac) > int(decimals) {
+		if strings.Trim(frac[decimals:], "0") != "" {
+			return fail(ErrPrecision)
+		}
+		frac = frac[:decimals]
+	}
+
+	// Accumulate the magnitude, which may be one more than MaxInt64 for
+	// MinInt64
+	const limit = uint64(1) << 63
+	var mag uint64
+	for _, part := range []string{whole, frac, strings.Repeat("0", int(decimals)-len(frac))} {
+		for i := 0; i < len(part); i++ {
+			d := uint64(part[i] - '0')
+			if mag > (limit-d)/10 {
+				return fail(ErrRange)
+			}
+			mag = mag*10 + d
+		}
+	}
+	if neg {
+		return int64(-mag), nil // wraps to MinInt64 at the limit
+	}
+	if mag == limit {
+		return fail(ErrRange)
+	}
+	return int64(mag), nil
+}
+
+func digits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseTicks parses a raw tick count, for inputs that take prices in ticks.
+// It is ParsePrice at 0 decimals, so it follows the same rules and errors.
+func ParseTicks(s string) (int64, error) {
+	return ParsePrice(s, 0)
+}
@@ -0,0 +1,146 @@
+package money
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"testing/quick"
+)
+
+func TestParsePrice(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals int8
+		want     int64
+	}{
+		{"150.25", 2, 15025},
+		{"150", 2, 15000},
+		{"150.2", 2, 15020},
+		{"150.250", 2, 15025}, // trailing zeros past the ticker's decimals
+		{"150.00000", 0, 150},
+		{".50", 2, 50}, // missing leading zero
+		{"0.05", 2, 5},
+		{"-0.05", 2, -5},
+		{"-.5", 2, -50},
+		{"-150.25", 2, -15025},
+		{"-0", 2, 0},
+		{"007.5", 2, 750},
+		{"  150.25 ", 2, 15025},
+		{"15025", 0, 15025},
+		{"1.23456789", 8, 123456789},
+		{"92233720368547758.07", 2, math.MaxInt64},
+		{"-92233720368547758.08", 2, math.MinInt64},
+		{"9223372036854775807", 0, math.MaxInt64},
+		{"-9223372036854775808", 0, math.MinInt64},
+	}
+	for _, tt := range tests {
+		got, err := ParsePrice(tt.in, tt.decimals)
+		if err != nil || got != tt.want {
+			t.Errorf("ParsePrice(%q, %d) = %d, %v; want %d", tt.in, tt.decimals, got, err, tt.want)
+		}
+	}
+}
+
+func TestParsePriceRejects(t *testing.T) {
+	tests := []struct {
+		in       string
+		decimals int8
+		want     error
+	}{
+		{"", 2, ErrEmpty},
+		{"   ", 2, ErrEmpty},
+		{"1,50", 2, ErrSeparator},     // decimal comma
+		{"1,500.00", 2, ErrSeparator}, // thousands separator
+		{"150.251", 2, ErrPrecision},
+		{"150.5", 0, ErrPrecision},
+		{"0.000000001", 8, ErrPrecision},
+		{"-", 2, ErrSyntax},
+		{".", 2, ErrSyntax},
+		{"150.", 2, ErrSyntax},
+		{"+150", 2, ErrSyntax},
+		{"--1", 2, ErrSyntax},
+		{"1.2.3", 2, ErrSyntax},
+		{"1e5", 2, ErrSyntax},
+		{"1 000", 0, ErrSyntax},
+		{"$150", 2, ErrSyntax},
+		{"bid+1", 2, ErrSyntax},
+		{"١٢٣", 0, ErrSyntax}, // non-ASCII digits
+		{"92233720368547758.08", 2, ErrRange},
+		{"-92233720368547758.09", 2, ErrRange},
+		{"9223372036854775808", 0, ErrRange},
+		{"99999999999999999999999", 0, ErrRange},
+	}
+	for _, tt := range tests {
+		_, err := ParsePrice(tt.in, tt.decimals)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("ParsePrice(%q, %d): expected %v, got %v", tt.in, tt.decimals, tt.want, err)
+			continue
+		}
+		var pe *ParseError
+		if !errors.As(err, &pe) || pe.Input != tt.in {
+			t.Errorf("ParsePrice(%q, %d): expected a *ParseError for the input, got %#v", tt.in, tt.decimals, err)
+		}
+	}
+
+	if _, err := ParsePrice("1", MaxDecimals+1); !errors.Is(err, ErrInvalidDecimals) {
+		t.Errorf("expected ErrInvalidDecimals, got %v", err)
+	}
+	if _, err := ParsePrice("150.251", 2); err.Error() != `"150.251": more than 2 decimal places` {
+		t.Errorf("unexpected message %q", err)
+	}
+}
+
+func TestParsePriceRoundTrip(t *testing.T) {
+	for d := int8(0); d <= MaxDecimals; d++ {
+		// Every value in a range around zero
+		for ticks := int64(-5000); ticks <= 5000; ticks++ {
+			if got, err := ParsePrice(FormatPrice(ticks, d), d); err != nil || got != ticks {
+				t.Fatalf("decimals %d: %d formatted as %q parsed as %d, %v", d, ticks, FormatPrice(ticks, d), got, err)
+			}
+		}
+		// And near the limits
+		for _, ticks := range []int64{math.MinInt64, math.MinInt64 + 1, math.MaxInt64 - 1, math.MaxInt64} {
+			if got, err := ParsePrice(FormatPrice(ticks, d), d); err != nil || got != ticks {
+				t.Errorf("decimals %d: %d formatted as %q parsed as %d, %v", d, ticks, FormatPrice(ticks, d), got, err)
+			}
+		}
+	}
+}
+
+func TestParsePriceRoundTripQuick(t *testing.T) {
+	roundTrip := func(ticks int64, d uint8) bool {
+		decimals := int8(d % (MaxDecimals + 1))
+		got, err := ParsePrice(FormatPrice(ticks, decimals), decimals)
+		return err == nil && got == ticks
+	}
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 20000}); err != nil {
+		t.Error(err)
+	}
+
+	// Parsing then formatting yields the canonical form, which parses back
+	// to the same value
+	canonical := func(whole uint32, frac uint16, neg bool) bool {
+		s := FormatPrice(int64(whole), 0) + "." + FormatPrice(int64(frac%10000), 0)
+		if neg {
+			s = "-" + s
+		}
+		ticks, err := ParsePrice(s, 4)
+		if err != nil {
+			return false
+		}
+		again, err := ParsePrice(FormatPrice(ticks, 4), 4)
+		return err == nil && again == ticks
+	}
+	if err := quick.Check(canonical, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseTicks(t *testing.T) {
+	if got, err := ParseTicks("17500"); err != nil || got != 17500 {
+		t.Errorf("ParseTicks(17500) = %d, %v", got, err)
+	}
+	if _, err := ParseTicks("175.5"); !errors.Is(err, ErrPrecision) {
+		t.Errorf("expected ErrPrecision for fractional ticks, got %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/news"
+)
+
+// Publisher receives the player's news items. *newsservice.NewsService
+// implements it.
+type Publisher interface {
+	Publish(item news.NewsItem)
+}
+
+// Config holds configuration for a Player.
+type Config struct {
+	// Path is the scenario file.
+	Path string
+	// Tick is how often due items are published and the file is checked.
+	Tick time.Duration
+	// Watch reloads the file whenever its size or modification time changes.
+	Watch bool
+	// OnReload, if set, is called after every reload attempt (for example to
+	// show a status message). It must not block.
+	OnReload func(Diff, error)
+	// Clock times playback.
+	Clock clock.Clock
+}
+
+// DefaultConfig returns a Config with reasonable defaults.
+func DefaultConfig() Config {
+	return Config{
+		Tick:  250 * time.Millisecond,
+		Watch: true,
+		Clock: clock.Real(),
+	}
+}
+
+// Diff describes what a reload changed in the pending schedule.
+type Diff struct {
+	Added    []string // new future items
+	Removed  []string // unpublished items deleted from the file
+	Modified []string // unpublished items with new timing or content
+	Late     []string // new items whose time had already passed; not scheduled
+}
+
+// Empty reports whether the reload changed nothing.
+func (d Diff) Empty() bool {
+	return len(d.Added)+len(d.Removed)+len(d.Modified)+len(d.Late) == 0
+}
+
+func (d Diff) String() string {
+	s := fmt.Sprintf("%d added, %d removed, %d modified", len(d.Added), len(d.Removed), len(d.Modified))
+	if len(d.Late) > 0 {
+		s += fmt.Sprintf(", %d skipped (already past: %s)", len(d.Late), strings.Join(d.Late, ", "))
+	}
+	return s
+}
+
+// Player publishes a scenario's items as their times come due, and can
+// reload the file without disturbing items already published.
+type Player struct {
+	cfg   Config
+	pub   Publisher
+	start time.Time
+
+	mu        sync.Mutex
+	pending   map[string]Item // not yet published, by ID
+	published map[string]bool
+	modTime   time.Time
+	size      int64
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewPlayer loads the scenario at cfg.Path and starts playback. Item times
+// are measured from now.
+func NewPlayer(pub Publisher, cfg Config) (*Player, error) {
+	def := DefaultConfig()
+	if cfg.Tick <= 0 {
+		cfg.Tick = def.Tick
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = def.Clock
+	}
+
+	fi, err := os.Stat(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	sc, err := Load(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Player{
+		cfg:       cfg,
+		pub:       pub,
+		start:     cfg.Clock.Now(),
+		pending:   make(map[string]Item, len(sc.Items)),
+		published: make(map[string]bool),
+		modTime:   fi.ModTime(),
+		size:      fi.Size(),
+		closed:    make(chan struct{}),
+	}
+	for _, it := range sc.Items {
+		p.pending[it.ID] = it
+	}
+
+	// Create the ticker before returning so playback is timed from now.
+	ticker := cfg.Clock.NewTicker(cfg.Tick)
+	p.wg.Add(1)
+	go p.run(ticker)
+	return p, nil
+}
+
+func (p *Player) run(ticker clock.Ticker) {
+	defer p.wg.Done()
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.closed:
+			return
+		case <-ticker.C():
+			if p.cfg.Watch && p.fileChanged() {
+				p.Reload()
+			}
+			p.publishDue()
+		}
+	}
+}
+
+// fileChanged reports whether the scenario file looks different from the
+// last load. A missing file is treated as unchanged.
+func (p *Player) fileChanged() bool {
+	fi, err := os.Stat(p.cfg.Path)
+	if err != nil {
+		return false
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return !fi.ModTime().Equal(p.modTime) || fi.Size() != p.size
+}
+
+func (p *Player) elapsed() time.Duration {
+	return p.cfg.Clock.Now().Sub(p.start)
+}
+
+// publishDue publishes every pending item whose time has come, in time order.
+func (p *Player) publishDue() {
+	now := p.elapsed()
+
+	p.mu.Lock()
+	var due []Item
+	for id, it := range p.pending {
+		if it.At <= now {
+			due = append(due, it)
+			delete(p.pending, id)
+			p.published[id] = true
+		}
+	}
+	p.mu.Unlock()
+
+	sortItems(due)
+	for _, it := range due {
+		p.pub.Publish(news.NewsItem{
+			Time:     p.start.Add(it.At).UnixNano(),
+			Ticker:   it.Ticker,
+			Headline: it.Headline,
+			Body:     it.Body,
+			Severity: it.Severity,
+			Source:   news.SourceScenario,
+		})
+	}
+}
+
+// Reload re-reads the scenario file and merges it into the running schedule:
+// published items are never touched, new future items are added, deleted
+// unpublished items are dropped and edited unpublished items are replaced.
+// If the file fails to load the schedule is left unchanged and the error is
+// returned.
+//
+// Either way the outcome is published as a SourceSystem news item and passed
+// to Config.OnReload.
+func (p *Player) Reload() (Diff, error) {
+	diff, err := p.reload()
+
+	item := news.NewsItem{
+		Headline: "Scenario reloaded: " + diff.String(),
+		Source:   news.SourceSystem,
+	}
+	if err != nil {
+		item.Headline = "Scenario reload failed; schedule unchanged"
+		item.Body = err.Error()
+		item.Severity = 1
+	}
+	p.pub.Publish(item)
+	if p.cfg.OnReload != nil {
+		p.cfg.OnReload(diff, err)
+	}
+	return diff, err
+}
+
+func (p *Player) reload() (Diff, error) {
+	fi, err := os.Stat(p.cfg.Path)
+	if err != nil {
+		return Diff{}, err
+	}
+	sc, err := Load(p.cfg.Path)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Remember this version even if it failed, so a watcher does not retry
+	// the same broken file every tick.
+	p.modTime, p.size = fi.ModTime(), fi.Size()
+	if err != nil {
+		return Diff{}, err
+	}
+
+	now := p.elapsed()
+	var diff Diff
+	inFile := make(map[string]bool, len(sc.Items))
+	for _, it := range sc.Items {
+		inFile[it.ID] = true
+		if p.published[it.ID] {
+			continue
+		}
+		old, ok := p.pending[it.ID]
+		switch {
+		case !ok && it.At < now:
+			diff.Late = append(diff.Late, it.ID)
+		case !ok:
+			p.pending[it.ID] = it
+			diff.Added = append(diff.Added, it.ID)
+		case old != it:
+			p.pending[it.ID] = it
+			diff.Modified = append(diff.Modified, it.ID)
+		}
+	}
+	for id := range p.pending {
+		if !inFile[id] {
+			delete(p.pending, id)
+			diff.Removed = append(diff.Removed, id)
+		}
+	}
+	sort.Strings(diff.Removed)
+	return diff, nil
+}
+
+// Pending returns the items not yet published, in time order.
+func (p *Player) Pending() []Item {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]Item, 0, len(p.pending))
+	for _, it := range p.pending {
+		out = append(out, it)
+	}
+	sortItems(out)
+	return out
+}
+
+// Close stops playback.
+func (p *Player) Close() {
+	p.closeOnce.Do(func() {
+		close(p.closed)
+	})
+	p.wg.Wait()
+}
+
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].At != items[j].At {
+			return items[i].At < items[j].At
+		}
+		return items[i].ID < items[j].ID
+	})
+}
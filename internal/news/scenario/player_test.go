@@ -0,0 +1,230 @@
+package scenario
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/objective"
+)
+
+type recordingPublisher struct {
+	mu    sync.Mutex
+	items []news.NewsItem
+}
+
+func (r *recordingPublisher) Publish(item news.NewsItem) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.items = append(r.items, item)
+}
+
+func (r *recordingPublisher) bySource(src news.Source) []news.NewsItem {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []news.NewsItem
+	for _, it := range r.items {
+		if it.Source == src {
+			out = append(out, it)
+		}
+	}
+	return out
+}
+
+func writeScenario(t *testing.T, path, body string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestPlayer starts a player on a manual clock. Watching is off so tests
+// drive reloads explicitly.
+func newTestPlayer(t *testing.T, body string) (*Player, *recordingPublisher, *clock.Manual, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	writeScenario(t, path, body)
+
+	clk := clock.NewManual(time.Unix(1000, 0))
+	pub := &recordingPublisher{}
+	cfg := DefaultConfig()
+	cfg.Path = path
+	cfg.Watch = false
+	cfg.Clock = clk
+	p, err := NewPlayer(pub, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(p.Close)
+	return p, pub, clk, path
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func pendingIDs(p *Player) string {
+	var ids []string
+	for _, it := range p.Pending() {
+		ids = append(ids, it.ID+"@"+it.At.String())
+	}
+	return strings.Join(ids, " ")
+}
+
+const baseScenario = `{"items": [
+  {"id": "open", "at": "1s", "headline": "Markets open"},
+  {"id": "fed", "at": "10s", "headline": "Fed holds rates", "severity": 1},
+  {"id": "cpi", "at": "20s", "headline": "CPI cools"}
+]}`
+
+func TestPlayerReloadDiff(t *testing.T) {
+	p, pub, clk, path := newTestPlayer(t, baseScenario)
+
+	// Publish "open", leaving fed and cpi pending
+	clk.Advance(2 * time.Second)
+	waitFor(t, func() bool { return len(pub.bySource(news.SourceScenario)) == 1 })
+
+	writeScenario(t, path, `{"items": [
+  {"id": "open", "at": "1s", "headline": "Markets open (edited)"},
+  {"id": "fed", "at": "15s", "headline": "Fed holds rates", "severity": 1},
+  {"id": "earnings", "at": "30s", "headline": "Earnings season"},
+  {"id": "missed", "at": "1s", "headline": "Too late"}
+]}`)
+	diff, err := p.Reload()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if strings.Join(diff.Added, ",") != "earnings" {
+		t.Errorf("expected earnings added, got %v", diff.Added)
+	}
+	if strings.Join(diff.Removed, ",") != "cpi" {
+		t.Errorf("expected cpi removed, got %v", diff.Removed)
+	}
+	if strings.Join(diff.Modified, ",") != "fed" {
+		t.Errorf("expected fed modified (published open untouched), got %v", diff.Modified)
+	}
+	if strings.Join(diff.Late, ",") != "missed" {
+		t.Errorf("expected missed skipped as late, got %v", diff.Late)
+	}
+	if got := pendingIDs(p); got != "fed@15s earnings@30s" {
+		t.Errorf("unexpected pending schedule: %s", got)
+	}
+
+	sys := pub.bySource(news.SourceSystem)
+	if len(sys) != 1 || !strings.Contains(sys[0].Headline, "1 added, 1 removed, 1 modified") {
+		t.Errorf("expected a system news summary, got %+v", sys)
+	}
+
+	// The retimed item publishes at its new time, not the old one
+	clk.Advance(9 * time.Second) // t=11s
+	time.Sleep(10 * time.Millisecond)
+	if n := len(pub.bySource(news.SourceScenario)); n != 1 {
+		t.Errorf("expected fed held until 15s, got %d scenario items", n)
+	}
+	clk.Advance(5 * time.Second) // t=16s
+	waitFor(t, func() bool { return len(pub.bySource(news.SourceScenario)) == 2 })
+	if got := pub.bySource(news.SourceScenario)[1]; got.Headline != "Fed holds rates" || got.Severity != 1 {
+		t.Errorf("unexpected published item: %+v", got)
+	}
+}
+
+func TestPlayerMalformedReloadRejected(t *testing.T) {
+	p, pub, _, path := newTestPlayer(t, baseScenario)
+	before := pendingIDs(p)
+
+	cases := []string{
+		`{"items": [`, // truncated JSON
+		`{"items": [{"id": "fed", "at": "soon", "headline": "x"}]}`,
+		`{"items": [{"id": "a", "at": "1s", "headline": "x"}, {"id": "a", "at": "2s", "headline": ""}]}`,
+		`{"items": [{"id": "a", "at": "1s", "headlin": "typo"}]}`,
+	}
+	for i, body := range cases {
+		writeScenario(t, path, body)
+		diff, err := p.Reload()
+		if !errors.Is(err, ErrInvalidScenario) {
+			t.Errorf("case %d: expected ErrInvalidScenario, got %v", i, err)
+		}
+		if !diff.Empty() {
+			t.Errorf("case %d: expected empty diff, got %+v", i, diff)
+		}
+		if got := pendingIDs(p); got != before {
+			t.Errorf("case %d: schedule changed to %s", i, got)
+		}
+	}
+
+	sys := pub.bySource(news.SourceSystem)
+	if len(sys) != len(cases) || !strings.Contains(sys[0].Headline, "failed") {
+		t.Errorf("expected a failure notice per reload, got %+v", sys)
+	}
+}
+
+func TestPlayerWatchReloadsOnChange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scenario.json")
+	writeScenario(t, path, baseScenario)
+
+	clk := clock.NewManual(time.Unix(1000, 0))
+	reloads := make(chan Diff, 4)
+	cfg := DefaultConfig()
+	cfg.Path = path
+	cfg.Clock = clk
+	cfg.OnReload = func(d Diff, err error) {
+		if err == nil {
+			reloads <- d
+		}
+	}
+	p, err := NewPlayer(&recordingPublisher{}, cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer p.Close()
+
+	writeScenario(t, path, `{"items": [{"id": "fed", "at": "10s", "headline": "Fed holds rates", "severity": 1}]}`)
+	clk.Advance(cfg.Tick)
+
+	select {
+	case d := <-reloads:
+		if len(d.Removed) != 2 {
+			t.Errorf("expected open and cpi removed, got %+v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the watcher to reload the changed file")
+	}
+}
+
+func TestParseObjectives(t *testing.T) {
+	sc, err := Parse(strings.NewReader(`{"items": [], "objectives": [
+		{"name": "Survive", "expr": "drawdown <= 10 throughout 2h"},
+		{"name": "Make markets", "expr": "maker_share:AAPL >= 5 by 1h"}
+	]}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sc.Objectives) != 2 || sc.Objectives[1].Name != "Make markets" {
+		t.Errorf("unexpected objectives %+v", sc.Objectives)
+	}
+
+	_, err = Parse(strings.NewReader(`{"items": [], "objectives": [
+		{"name": "", "expr": "equity >= 1 at 1h"},
+		{"name": "Nonsense", "expr": "equity is high"}
+	]}`))
+	if !errors.Is(err, ErrInvalidScenario) || !errors.Is(err, objective.ErrInvalidExpr) {
+		t.Fatalf("expected an invalid scenario, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "missing name") {
+		t.Errorf("expected every problem to be reported, got %v", err)
+	}
+}
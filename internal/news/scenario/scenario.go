@@ -0,0 +1,134 @@
+// Package scenario plays scripted news schedules and reloads them live.
+//
+// A scenario file is JSON listing headlines and when to publish them,
+// relative to the start of the game:
+//
+//	{"items": [
+//	  {"id": "fed", "at": "30s", "headline": "Fed holds rates", "severity": 1},
+//	  {"id": "aapl-beat", "at": "2m", "ticker": 1, "headline": "AAPL beats"}
+//	]}
+//
+// Item IDs are how a reload matches edited items to the loaded schedule.
+//
+// A scenario may also set the player's objectives, in the objective
+// package's expression syntax:
+//
+//	"objectives": [
+//	  {"name": "Survive the crash", "expr": "drawdown <= 10 throughout 2h"}
+//	]
+//
+// Objectives are read when the game starts; reloads do not change them.
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/objective"
+)
+
+var ErrInvalidScenario = errors.New("invalid scenario")
+
+// Item is one scheduled headline.
+type Item struct {
+	ID       string
+	At       time.Duration // offset from the start of playback
+	Ticker   market.TickerID
+	Headline string
+	Body     string
+	Severity int
+}
+
+// Scenario is a parsed, validated schedule.
+type Scenario struct {
+	Items      []Item
+	Objectives []objective.Objective
+}
+
+type fileItem struct {
+	ID       string          `json:"id"`
+	At       string          `json:"at"`
+	Ticker   market.TickerID `json:"ticker"`
+	Headline string          `json:"headline"`
+	Body     string          `json:"body"`
+	Severity int             `json:"severity"`
+}
+
+type file struct {
+	Items      []fileItem            `json:"items"`
+	Objectives []objective.Objective `json:"objectives"`
+}
+
+// Parse reads and validates a scenario. Every problem found is reported, not
+// just the first.
+func Parse(r io.Reader) (Scenario, error) {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	var f file
+	if err := dec.Decode(&f); err != nil {
+		return Scenario{}, fmt.Errorf("%w: %v", ErrInvalidScenario, err)
+	}
+
+	var errs []error
+	seen := make(map[string]bool, len(f.Items))
+	sc := Scenario{Items: make([]Item, 0, len(f.Items))}
+	for i, fi := range f.Items {
+		bad := func(format string, args ...any) {
+			errs = append(errs, fmt.Errorf("item %d (%q): "+format, append([]any{i, fi.ID}, args...)...))
+		}
+		if fi.ID == "" {
+			bad("missing id")
+		} else if seen[fi.ID] {
+			bad("duplicate id")
+		}
+		seen[fi.ID] = true
+		if fi.Headline == "" {
+			bad("missing headline")
+		}
+		if fi.Severity < 0 {
+			bad("negative severity %d", fi.Severity)
+		}
+		at, err := time.ParseDuration(fi.At)
+		if err != nil {
+			bad("bad time %q", fi.At)
+		} else if at < 0 {
+			bad("negative time %s", at)
+		}
+		sc.Items = append(sc.Items, Item{
+			ID:       fi.ID,
+			At:       at,
+			Ticker:   fi.Ticker,
+			Headline: fi.Headline,
+			Body:     fi.Body,
+			Severity: fi.Severity,
+		})
+	}
+	for i, o := range f.Objectives {
+		if o.Name == "" {
+			errs = append(errs, fmt.Errorf("objective %d: missing name", i))
+		}
+		if _, err := objective.Parse(o.Expr); err != nil {
+			errs = append(errs, fmt.Errorf("objective %d (%q): %w", i, o.Name, err))
+		}
+	}
+	sc.Objectives = f.Objectives
+	if len(errs) > 0 {
+		return Scenario{}, fmt.Errorf("%w: %w", ErrInvalidScenario, errors.Join(errs...))
+	}
+	return sc, nil
+}
+
+// Load reads and validates a scenario file.
+func Load(path string) (Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Scenario{}, err
+	}
+	return Parse(bytes.NewReader(data))
+}
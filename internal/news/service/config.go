@@ -1,5 +1,7 @@
 package service
 
+import "github.com/zappabad/stockcraft/internal/clock"
+
 // Config holds configuration for the news service.
 type Config struct {
 	// TapeSize is the capacity of the news ring buffer.
@@ -10,6 +12,9 @@ type Config struct {
 	ExternalEventBuffer int
 	// DropExternalEvents determines whether external event channel drops on overflow.
 	DropExternalEvents bool
+	// Clock timestamps published items and seeds their IDs. Nil uses the
+	// real clock.
+	Clock clock.Clock
 }
 
 // DefaultConfig returns a Config with reasonable defaults.
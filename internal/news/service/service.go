@@ -3,22 +3,28 @@ package service
 import (
 	"sync"
 	"sync/atomic"
-	"time"
 
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
 	"github.com/zappabad/stockcraft/internal/news"
 	newsview "github.com/zappabad/stockcraft/internal/news/view"
+	"github.com/zappabad/stockcraft/internal/pubsub"
 )
 
 // NewsService manages news publishing and viewing.
 type NewsService struct {
-	cfg  Config
-	view *newsview.NewsView
+	cfg   Config
+	view  *newsview.NewsView
+	clock clock.Clock
 
 	idGen atomic.Int64
 
 	internalEvents chan newsview.NewsEvent
-	externalEvents chan newsview.NewsEvent
-	droppedEvents  atomic.Int64
+
+	// bus fans events out after the view applies them; external is the
+	// subscription behind Events.
+	bus      *pubsub.Bus[newsview.NewsEvent]
+	external *pubsub.Subscription[newsview.NewsEvent]
 
 	closed    chan struct{}
 	closeOnce sync.Once
@@ -36,17 +42,26 @@ func NewNewsService(cfg Config) *NewsService {
 	if cfg.ExternalEventBuffer <= 0 {
 		cfg.ExternalEventBuffer = DefaultConfig().ExternalEventBuffer
 	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
 
 	s := &NewsService{
 		cfg:            cfg,
 		view:           newsview.NewNewsView(cfg.TapeSize),
+		clock:          cfg.Clock,
 		internalEvents: make(chan newsview.NewsEvent, cfg.EventBuffer),
-		externalEvents: make(chan newsview.NewsEvent, cfg.ExternalEventBuffer),
+		bus:            pubsub.New[newsview.NewsEvent](),
 		closed:         make(chan struct{}),
 	}
+	policy := pubsub.Block
+	if cfg.DropExternalEvents {
+		policy = pubsub.Drop
+	}
+	s.external = s.bus.Subscribe(policy, cfg.ExternalEventBuffer)
 
 	// Initialize ID generator
-	s.idGen.Store(time.Now().UnixNano())
+	s.idGen.Store(s.clock.Now().UnixNano())
 
 	// Start event dispatcher
 	s.wg.Add(1)
@@ -61,7 +76,7 @@ func (s *NewsService) nextID() news.NewsID {
 
 func (s *NewsService) runEventDispatcher() {
 	defer s.wg.Done()
-	defer close(s.externalEvents)
+	defer s.bus.Close()
 
 	for {
 		select {
@@ -71,19 +86,8 @@ func (s *NewsService) runEventDispatcher() {
 			// Always update view (authoritative)
 			s.view.Apply(ev)
 
-			// Attempt to send to external channel
-			if s.cfg.DropExternalEvents {
-				select {
-				case s.externalEvents <- ev:
-				default:
-					s.droppedEvents.Add(1)
-				}
-			} else {
-				select {
-				case s.externalEvents <- ev:
-				case <-s.closed:
-					return
-				}
+			if !s.bus.Publish(ev) {
+				return
 			}
 		}
 	}
@@ -95,7 +99,7 @@ func (s *NewsService) Publish(item news.NewsItem) {
 		item.ID = s.nextID()
 	}
 	if item.Time == 0 {
-		item.Time = time.Now().UnixNano()
+		item.Time = s.clock.Now().UnixNano()
 	}
 
 	ev := newsview.NewsEvent{Item: item}
@@ -106,25 +110,50 @@ func (s *NewsService) Publish(item news.NewsItem) {
 	}
 }
 
+// Restore puts items, oldest first, on the tape without publishing them, so
+// subscribers do not react to them again. Call it before publishing, as
+// when resuming a checkpoint.
+func (s *NewsService) Restore(items []news.NewsItem) {
+	for _, item := range items {
+		s.view.Apply(newsview.NewsEvent{Item: item})
+	}
+}
+
 // Latest returns the last n news items (from view).
 func (s *NewsService) Latest(n int) []news.NewsItem {
 	return s.view.Latest(n)
 }
 
-// Events returns the external events channel for subscribers.
+// RankedForTicker returns up to n items relevant to a ticker, most relevant
+// first (from view; see newsview.NewsView.RankedForTicker).
+func (s *NewsService) RankedForTicker(tid market.TickerID, n int) []news.NewsItem {
+	return s.view.RankedForTicker(tid, n)
+}
+
+// Events returns the external events channel, sized by
+// Config.ExternalEventBuffer with the Config.DropExternalEvents policy.
 func (s *NewsService) Events() <-chan newsview.NewsEvent {
-	return s.externalEvents
+	return s.external.C()
+}
+
+// Subscribe adds an independent subscriber to published news, each item
+// delivered after the view applies it. Its channel closes on Unsubscribe or
+// Close.
+func (s *NewsService) Subscribe(policy pubsub.Policy, buffer int) *pubsub.Subscription[newsview.NewsEvent] {
+	return s.bus.Subscribe(policy, buffer)
 }
 
-// DroppedEvents returns the count of dropped external events.
+// DroppedEvents returns the count of events dropped from Events.
 func (s *NewsService) DroppedEvents() int64 {
-	return s.droppedEvents.Load()
+	return s.external.Dropped()
 }
 
-// Close shuts down the news service.
+// Close shuts down the news service, closing every subscription.
 func (s *NewsService) Close() {
 	s.closeOnce.Do(func() {
 		close(s.closed)
 	})
+	// Releases a dispatch blocked on a subscriber
+	s.bus.Close()
 	s.wg.Wait()
 }
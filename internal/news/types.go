@@ -5,6 +5,32 @@ import "github.com/zappabad/stockcraft/internal/market"
 // NewsID uniquely identifies a news item.
 type NewsID int64
 
+// Source attributes a news item to whoever generated it, so consumers that
+// react to news can ignore chatter produced by the simulation itself.
+type Source uint8
+
+const (
+	// SourceExternal is news published by an outside caller (the default).
+	SourceExternal Source = iota
+	// SourceScenario is news from a scripted scenario schedule.
+	SourceScenario
+	// SourceSystem is news generated by the simulation (halts, block trades, ...).
+	SourceSystem
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceExternal:
+		return "EXTERNAL"
+	case SourceScenario:
+		return "SCENARIO"
+	case SourceSystem:
+		return "SYSTEM"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 // NewsItem represents a news event.
 type NewsItem struct {
 	ID       NewsID
@@ -12,5 +38,6 @@ type NewsItem struct {
 	Ticker   market.TickerID // optional; 0 means market-wide news
 	Headline string
 	Body     string
-	Severity int // 0=normal, positive=more severe/important
+	Severity int    // 0=normal, positive=more severe/important
+	Source   Source // who generated the item
 }
@@ -0,0 +1,74 @@
+package view
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/news"
+)
+
+// Relevance scores how closely a news item concerns a ticker.
+type Relevance int
+
+const (
+	// RelevanceNone is news about another ticker.
+	RelevanceNone Relevance = iota
+	// RelevanceMarket is market-wide news, which touches every ticker.
+	RelevanceMarket
+	// RelevanceDirect is news about the ticker itself.
+	RelevanceDirect
+)
+
+func (r Relevance) String() string {
+	switch r {
+	case RelevanceNone:
+		return "NONE"
+	case RelevanceMarket:
+		return "MARKET"
+	case RelevanceDirect:
+		return "DIRECT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// RelevanceFor scores an item for a ticker.
+func RelevanceFor(item news.NewsItem, tid market.TickerID) Relevance {
+	switch item.Ticker {
+	case tid:
+		return RelevanceDirect
+	case 0:
+		return RelevanceMarket
+	default:
+		return RelevanceNone
+	}
+}
+
+// RankedForTicker returns up to n items relevant to a ticker, most relevant
+// first, then most severe, then newest. Items about other tickers are left
+// out.
+func (v *NewsView) RankedForTicker(tid market.TickerID, n int) []news.NewsItem {
+	if n <= 0 {
+		return nil
+	}
+	var out []news.NewsItem
+	for _, item := range v.Latest(v.Count()) {
+		if RelevanceFor(item, tid) != RelevanceNone {
+			out = append(out, item)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b news.NewsItem) int {
+		if c := cmp.Compare(RelevanceFor(b, tid), RelevanceFor(a, tid)); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Severity, a.Severity); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.Time, a.Time)
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
@@ -0,0 +1,41 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/news"
+)
+
+func TestRankedForTicker(t *testing.T) {
+	const aapl, msft market.TickerID = 1, 2
+	v := NewNewsView(10)
+	for _, item := range []news.NewsItem{
+		{ID: 1, Time: 10, Ticker: aapl, Headline: "AAPL beats"},
+		{ID: 2, Time: 20, Ticker: 0, Headline: "Rates cut", Severity: 2},
+		{ID: 3, Time: 30, Ticker: msft, Headline: "MSFT misses"},
+		{ID: 4, Time: 40, Ticker: 0, Headline: "Markets open"},
+		{ID: 5, Time: 50, Ticker: aapl, Headline: "AAPL recall"},
+	} {
+		v.Apply(NewsEvent{Item: item})
+	}
+
+	// Direct news first, newest first; then market-wide, most severe first.
+	// MSFT's item is left out.
+	got := v.RankedForTicker(aapl, 10)
+	want := []news.NewsID{5, 1, 2, 4}
+	if len(got) != len(want) {
+		t.Fatalf("expected items %v, got %+v", want, got)
+	}
+	for i, item := range got {
+		if item.ID != want[i] {
+			t.Errorf("rank %d: expected item %d, got %d", i, want[i], item.ID)
+		}
+	}
+	if got := v.RankedForTicker(aapl, 1); len(got) != 1 || got[0].ID != 5 {
+		t.Errorf("expected only item 5, got %+v", got)
+	}
+	if RelevanceFor(got[0], aapl) != RelevanceDirect || RelevanceFor(got[3], aapl) != RelevanceMarket || RelevanceFor(got[0], msft) != RelevanceNone {
+		t.Error("expected direct, market and no relevance")
+	}
+}
@@ -0,0 +1,286 @@
+package objective
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+)
+
+// State is where an objective stands.
+type State uint8
+
+const (
+	// StatePending is not yet decided.
+	StatePending State = iota
+	// StateMet was achieved.
+	StateMet
+	// StateFailed can no longer be achieved.
+	StateFailed
+	// StateUnavailable cannot be judged because its metric's source is
+	// disabled. It never resolves.
+	StateUnavailable
+)
+
+func (s State) String() string {
+	switch s {
+	case StatePending:
+		return "PENDING"
+	case StateMet:
+		return "MET"
+	case StateFailed:
+		return "FAILED"
+	case StateUnavailable:
+		return "UNAVAILABLE"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// Resolved reports whether the state is final.
+func (s State) Resolved() bool {
+	return s == StateMet || s == StateFailed
+}
+
+// Status is an objective's progress as of the last check.
+type Status struct {
+	Objective Objective
+	Condition Condition
+	Unit      string
+	State     State
+	// Value is the metric at the last check that read it.
+	Value    float64
+	HasValue bool
+	// Progress runs from 0 to 1 towards meeting the objective.
+	Progress float64
+	// Elapsed is the session time of the last check, or of the check that
+	// resolved the objective.
+	Elapsed time.Duration
+}
+
+// Remaining returns the session time left before the deadline.
+func (s Status) Remaining() time.Duration {
+	return max(s.Condition.Deadline-s.Elapsed, 0)
+}
+
+// Config holds configuration for an Evaluator.
+type Config struct {
+	// Interval is how often every objective is checked.
+	Interval time.Duration
+	// Clock drives the checks and measures session time.
+	Clock clock.Clock
+	// OnResolve, if set, is called from the checking goroutine when an
+	// objective is met or fails.
+	OnResolve func(Status)
+}
+
+// DefaultConfig returns a Config with reasonable defaults.
+func DefaultConfig() Config {
+	return Config{
+		Interval: time.Second,
+		Clock:    clock.Real(),
+	}
+}
+
+// Evaluator checks objectives against a Registry's metrics on a cadence.
+// Session time starts when it is created. A deadline is judged at the first
+// check at or after it, so Interval bounds how late that can be.
+type Evaluator struct {
+	cfg   Config
+	reg   *Registry
+	start time.Time
+
+	mu       sync.Mutex
+	statuses []Status
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewEvaluator parses the objectives, checks them against the registry and
+// starts checking. Every problem found is reported, not just the first.
+func NewEvaluator(reg *Registry, objectives []Objective, cfg Config) (*Evaluator, error) {
+	def := DefaultConfig()
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = def.Clock
+	}
+
+	var errs []error
+	statuses := make([]Status, 0, len(objectives))
+	for i, o := range objectives {
+		c, err := Parse(o.Expr)
+		if err == nil {
+			err = reg.Check(c)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("objective %d (%q): %w", i, o.Name, err))
+			continue
+		}
+		m, _ := reg.Lookup(c.Metric)
+		statuses = append(statuses, Status{Objective: o, Condition: c, Unit: m.Unit})
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	e := &Evaluator{
+		cfg:      cfg,
+		reg:      reg,
+		start:    cfg.Clock.Now(),
+		statuses: statuses,
+		closed:   make(chan struct{}),
+	}
+
+	// Create the ticker before returning so callers can advance a manual
+	// clock immediately.
+	ticker := cfg.Clock.NewTicker(cfg.Interval)
+	e.wg.Add(1)
+	go e.run(ticker)
+	return e, nil
+}
+
+func (e *Evaluator) run(ticker clock.Ticker) {
+	defer e.wg.Done()
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.closed:
+			return
+		case <-ticker.C():
+			e.Evaluate()
+		}
+	}
+}
+
+// Evaluate checks every unresolved objective now. The checking goroutine
+// calls it on each tick; tests may call it directly.
+func (e *Evaluator) Evaluate() {
+	e.mu.Lock()
+	elapsed := e.cfg.Clock.Now().Sub(e.start)
+	var resolved []Status
+	for i := range e.statuses {
+		s := &e.statuses[i]
+		if s.State.Resolved() {
+			continue
+		}
+		e.check(s, elapsed)
+		if s.State.Resolved() {
+			resolved = append(resolved, *s)
+		}
+	}
+	e.mu.Unlock()
+
+	if e.cfg.OnResolve != nil {
+		for _, s := range resolved {
+			e.cfg.OnResolve(s)
+		}
+	}
+}
+
+func (e *Evaluator) check(s *Status, elapsed time.Duration) {
+	c := s.Condition
+	s.Elapsed = elapsed
+
+	m, _ := e.reg.Lookup(c.Metric)
+	v, err := m.Value(c.Ticker)
+	if errors.Is(err, ErrUnavailable) {
+		s.State = StateUnavailable
+		return
+	}
+	if err != nil {
+		return // no reading this time; try again next check
+	}
+	s.State, s.Value, s.HasValue = StatePending, v, true
+
+	holds := c.Op.Holds(v, c.Target)
+	switch c.Mode {
+	case ModeAt:
+		if elapsed >= c.Deadline {
+			s.State = resolve(holds)
+		}
+	case ModeBy:
+		if holds && elapsed <= c.Deadline {
+			s.State = StateMet
+		} else if elapsed >= c.Deadline {
+			s.State = StateFailed
+		}
+	case ModeThroughout:
+		if elapsed > c.Deadline || (holds && elapsed == c.Deadline) {
+			s.State = StateMet
+		} else if !holds {
+			s.State = StateFailed
+		}
+	}
+	s.Progress = progress(*s, holds)
+}
+
+func resolve(holds bool) State {
+	if holds {
+		return StateMet
+	}
+	return StateFailed
+}
+
+// progress is the share of the target reached for value conditions, and the
+// share of the session survived for throughout conditions.
+func progress(s Status, holds bool) float64 {
+	c := s.Condition
+	switch {
+	case s.State == StateMet:
+		return 1
+	case c.Mode == ModeThroughout:
+		if s.State == StateFailed {
+			return 0
+		}
+		return clamp(float64(s.Elapsed) / float64(c.Deadline))
+	case holds:
+		return 1
+	case c.Op == GreaterEqual || c.Op == Greater:
+		if c.Target <= 0 {
+			return 0
+		}
+		return clamp(s.Value / c.Target)
+	default:
+		if s.Value <= 0 {
+			return 0
+		}
+		return clamp(c.Target / s.Value)
+	}
+}
+
+func clamp(x float64) float64 {
+	return min(max(x, 0), 1)
+}
+
+// Statuses returns every objective's status, in configuration order.
+func (e *Evaluator) Statuses() []Status {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]Status(nil), e.statuses...)
+}
+
+// Done reports whether every objective that can be judged is resolved.
+func (e *Evaluator) Done() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, s := range e.statuses {
+		if s.State == StatePending {
+			return false
+		}
+	}
+	return true
+}
+
+// Close stops checking. Statuses stay readable.
+func (e *Evaluator) Close() {
+	e.closeOnce.Do(func() {
+		close(e.closed)
+	})
+	e.wg.Wait()
+}
@@ -0,0 +1,81 @@
+package objective
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrUnknownMetric = errors.New("unknown metric")
+	// ErrUnavailable is returned by a metric whose data source is disabled,
+	// such as a broker metric in a game without a broker.
+	ErrUnavailable = errors.New("metric source is disabled")
+)
+
+// Metric is a value objectives can be set on.
+type Metric struct {
+	Name string
+	// Unit is shown after values: "$", "%" or "".
+	Unit string
+	// PerTicker metrics take a ticker name, as in maker_share:AAPL.
+	PerTicker bool
+	// Value reads the metric now. ticker is "" unless PerTicker. It returns
+	// ErrUnavailable if its source is disabled.
+	Value func(ticker string) (float64, error)
+}
+
+// Registry holds the supported metrics. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	metrics map[string]Metric
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]Metric)}
+}
+
+// Register adds a metric, replacing any of the same name.
+func (r *Registry) Register(m Metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics[m.Name] = m
+}
+
+// Lookup returns the named metric.
+func (r *Registry) Lookup(name string) (Metric, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.metrics[name]
+	return m, ok
+}
+
+// Names returns the registered metric names in order.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check reports whether a condition names a registered metric, with a
+// ticker exactly when the metric is per ticker.
+func (r *Registry) Check(c Condition) error {
+	m, ok := r.Lookup(c.Metric)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownMetric, c.Metric)
+	}
+	if m.PerTicker && c.Ticker == "" {
+		return fmt.Errorf("%w: %s needs a ticker, as in %s:AAPL", ErrInvalidExpr, c.Metric, c.Metric)
+	}
+	if !m.PerTicker && c.Ticker != "" {
+		return fmt.Errorf("%w: %s does not take a ticker", ErrInvalidExpr, c.Metric)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package objective defines game objectives and evaluates them live.
+//
+// An objective is a condition on a named metric with a deadline measured
+// from the start of the session, written as a small expression:
+//
+//	equity >= 110000 at 6h        the condition must hold at the deadline
+//	maker_share:AAPL >= 5 by 6h   it must hold at some point before then
+//	drawdown <= 10 throughout 6h  it must hold at every check until then
+//
+// Metrics come from a Registry that the game fills with data sources; an
+// Evaluator checks every objective against them on a cadence.
+package objective
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExpr = errors.New("invalid objective expression")
+
+// Comparator compares a metric value with the target.
+type Comparator string
+
+const (
+	GreaterEqual Comparator = ">="
+	Greater      Comparator = ">"
+	LessEqual    Comparator = "<="
+	Less         Comparator = "<"
+)
+
+// Holds reports whether value compares to target as c requires.
+func (c Comparator) Holds(value, target float64) bool {
+	switch c {
+	case GreaterEqual:
+		return value >= target
+	case Greater:
+		return value > target
+	case LessEqual:
+		return value <= target
+	case Less:
+		return value < target
+	default:
+		return false
+	}
+}
+
+// Mode says when the condition is judged.
+type Mode string
+
+const (
+	// ModeAt judges the condition once, at the deadline.
+	ModeAt Mode = "at"
+	// ModeBy is met as soon as the condition holds, and fails if it has not
+	// by the deadline.
+	ModeBy Mode = "by"
+	// ModeThroughout fails as soon as the condition does not hold, and is
+	// met if it held at every check until the deadline.
+	ModeThroughout Mode = "throughout"
+)
+
+// Condition is a parsed objective expression.
+type Condition struct {
+	Metric   string
+	Ticker   string // for per-ticker metrics, e.g. maker_share:AAPL
+	Op       Comparator
+	Target   float64
+	Mode     Mode
+	Deadline time.Duration // from the start of the session
+}
+
+func (c Condition) String() string {
+	metric := c.Metric
+	if c.Ticker != "" {
+		metric += ":" + c.Ticker
+	}
+	return fmt.Sprintf("%s %s %s %s %s", metric, c.Op, strconv.FormatFloat(c.Target, 'f', -1, 64), c.Mode, c.Deadline)
+}
+
+// Parse parses an objective expression of the form
+// "metric[:TICKER] op target mode deadline".
+func Parse(expr string) (Condition, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return Condition{}, fmt.Errorf("%w: %q: want \"metric op target at|by|throughout deadline\"", ErrInvalidExpr, expr)
+	}
+	var c Condition
+	c.Metric, c.Ticker, _ = strings.Cut(fields[0], ":")
+	if c.Metric == "" || (strings.Contains(fields[0], ":") && c.Ticker == "") {
+		return Condition{}, fmt.Errorf("%w: %q: bad metric %q", ErrInvalidExpr, expr, fields[0])
+	}
+
+	c.Op = Comparator(fields[1])
+	switch c.Op {
+	case GreaterEqual, Greater, LessEqual, Less:
+	default:
+		return Condition{}, fmt.Errorf("%w: %q: unknown comparator %q", ErrInvalidExpr, expr, fields[1])
+	}
+
+	target, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return Condition{}, fmt.Errorf("%w: %q: bad target %q", ErrInvalidExpr, expr, fields[2])
+	}
+	c.Target = target
+
+	c.Mode = Mode(fields[3])
+	switch c.Mode {
+	case ModeAt, ModeBy, ModeThroughout:
+	default:
+		return Condition{}, fmt.Errorf("%w: %q: unknown mode %q", ErrInvalidExpr, expr, fields[3])
+	}
+
+	d, err := time.ParseDuration(fields[4])
+	if err != nil || d <= 0 {
+		return Condition{}, fmt.Errorf("%w: %q: bad deadline %q", ErrInvalidExpr, expr, fields[4])
+	}
+	c.Deadline = d
+	return c, nil
+}
+
+// Objective is a named objective as written in a config or scenario file.
+type Objective struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+}
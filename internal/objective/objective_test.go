@@ -0,0 +1,178 @@
+package objective
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+)
+
+func TestParse(t *testing.T) {
+	c, err := Parse("maker_share:AAPL >= 5 by 6h30m")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := Condition{Metric: "maker_share", Ticker: "AAPL", Op: GreaterEqual, Target: 5, Mode: ModeBy, Deadline: 6*time.Hour + 30*time.Minute}
+	if c != want {
+		t.Errorf("expected %+v, got %+v", want, c)
+	}
+	if c.String() != "maker_share:AAPL >= 5 by 6h30m0s" {
+		t.Errorf("unexpected String %q", c.String())
+	}
+
+	for _, expr := range []string{
+		"",
+		"equity >= 110000 at",
+		"equity => 110000 at 6h",
+		"equity >= lots at 6h",
+		"equity >= 110000 until 6h",
+		"equity >= 110000 at soon",
+		"equity >= 110000 at -1h",
+		"maker_share: >= 5 at 6h",
+		":AAPL >= 5 at 6h",
+	} {
+		if _, err := Parse(expr); !errors.Is(err, ErrInvalidExpr) {
+			t.Errorf("Parse(%q): expected ErrInvalidExpr, got %v", expr, err)
+		}
+	}
+}
+
+// fakeMetrics serves metric values set by the test.
+type fakeMetrics map[string]float64
+
+func (f fakeMetrics) registry() *Registry {
+	reg := NewRegistry()
+	for _, name := range []string{"equity", "drawdown", "fills"} {
+		reg.Register(Metric{Name: name, Value: func(string) (float64, error) { return f[name], nil }})
+	}
+	reg.Register(Metric{Name: "share", Unit: "%", PerTicker: true, Value: func(ticker string) (float64, error) { return f["share:"+ticker], nil }})
+	reg.Register(Metric{Name: "margin", Value: func(string) (float64, error) { return 0, ErrUnavailable }})
+	return reg
+}
+
+func newTestEvaluator(t *testing.T, reg *Registry, objectives ...Objective) (*Evaluator, *clock.Manual) {
+	t.Helper()
+	clk := clock.NewManual(time.Unix(0, 0))
+	// A long interval keeps the goroutine out of the way; the test calls
+	// Evaluate itself.
+	ev, err := NewEvaluator(reg, objectives, Config{Interval: 1000 * time.Hour, Clock: clk})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(ev.Close)
+	return ev, clk
+}
+
+func states(ev *Evaluator) []State {
+	var out []State
+	for _, s := range ev.Statuses() {
+		out = append(out, s.State)
+	}
+	return out
+}
+
+func TestEvaluatorModes(t *testing.T) {
+	f := fakeMetrics{"equity": 100000, "drawdown": 0, "share:AAPL": 1}
+	var resolved []string
+	clk := clock.NewManual(time.Unix(0, 0))
+	ev, err := NewEvaluator(f.registry(), []Objective{
+		{Name: "rich", Expr: "equity >= 110000 at 1h"},
+		{Name: "maker", Expr: "share:AAPL >= 5 by 1h"},
+		{Name: "calm", Expr: "drawdown <= 10 throughout 1h"},
+		{Name: "early", Expr: "equity >= 105000 by 30m"},
+	}, Config{Interval: 1000 * time.Hour, Clock: clk, OnResolve: func(s Status) { resolved = append(resolved, s.Objective.Name) }})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ev.Close()
+
+	clk.Advance(10 * time.Minute)
+	ev.Evaluate()
+	if got := states(ev); got[0] != StatePending || got[1] != StatePending || got[2] != StatePending {
+		t.Fatalf("expected all pending, got %v", got)
+	}
+	if s := ev.Statuses()[0]; s.Progress < 0.9 || s.Progress > 0.91 || s.Remaining() != 50*time.Minute {
+		t.Errorf("unexpected progress %v, remaining %v", s.Progress, s.Remaining())
+	}
+	if s := ev.Statuses()[2]; s.Progress < 0.16 || s.Progress > 0.17 {
+		t.Errorf("expected a throughout objective to progress with time, got %v", s.Progress)
+	}
+
+	// Reaching the target resolves "by" objectives but not "at" ones
+	f["equity"], f["share:AAPL"] = 112000, 6
+	clk.Advance(10 * time.Minute)
+	ev.Evaluate()
+	if got := states(ev); got[0] != StatePending || got[1] != StateMet || got[3] != StateMet {
+		t.Errorf("expected maker and early met, rich pending, got %v", got)
+	}
+
+	// Falling short afterwards does not undo a "by" objective
+	f["share:AAPL"] = 2
+	f["drawdown"] = 4
+	clk.Advance(40 * time.Minute)
+	ev.Evaluate()
+	if got := states(ev); got[0] != StateMet || got[1] != StateMet || got[2] != StateMet {
+		t.Errorf("expected everything met at the deadline, got %v", got)
+	}
+	if s := ev.Statuses()[0]; s.Elapsed != time.Hour || s.Value != 112000 {
+		t.Errorf("expected resolution at the deadline with the value read, got %+v", s)
+	}
+	if !ev.Done() {
+		t.Error("expected the evaluator to be done")
+	}
+	if len(resolved) != 4 || resolved[0] != "maker" || resolved[1] != "early" {
+		t.Errorf("unexpected resolve order %v", resolved)
+	}
+}
+
+func TestEvaluatorFailures(t *testing.T) {
+	f := fakeMetrics{"equity": 100000, "drawdown": 0}
+	ev, clk := newTestEvaluator(t, f.registry(),
+		Objective{Name: "rich", Expr: "equity >= 110000 at 1h"},
+		Objective{Name: "fast", Expr: "equity > 120000 by 30m"},
+		Objective{Name: "calm", Expr: "drawdown < 10 throughout 1h"},
+		Objective{Name: "margin", Expr: "margin <= 0 throughout 1h"},
+	)
+
+	f["drawdown"] = 12
+	clk.Advance(20 * time.Minute)
+	ev.Evaluate()
+	if got := states(ev); got[2] != StateFailed || got[3] != StateUnavailable {
+		t.Errorf("expected calm failed and margin unavailable, got %v", got)
+	}
+
+	// Recovering does not undo a failure
+	f["drawdown"] = 0
+	clk.Advance(40 * time.Minute)
+	ev.Evaluate()
+	want := []State{StateFailed, StateFailed, StateFailed, StateUnavailable}
+	for i, s := range states(ev) {
+		if s != want[i] {
+			t.Errorf("objective %d: expected %s, got %s", i, want[i], s)
+		}
+	}
+	if s := ev.Statuses()[2]; s.Elapsed != 20*time.Minute {
+		t.Errorf("expected calm to have failed at 20m, got %v", s.Elapsed)
+	}
+	if !ev.Done() {
+		t.Error("unavailable objectives should not keep the evaluator running")
+	}
+}
+
+func TestNewEvaluatorRejectsBadObjectives(t *testing.T) {
+	reg := fakeMetrics{}.registry()
+	_, err := NewEvaluator(reg, []Objective{
+		{Name: "a", Expr: "karma >= 1 at 1h"},
+		{Name: "b", Expr: "share >= 5 at 1h"},
+		{Name: "c", Expr: "equity:AAPL >= 5 at 1h"},
+		{Name: "d", Expr: "equity >="},
+		{Name: "ok", Expr: "equity >= 1 at 1h"},
+	}, Config{Clock: clock.NewManual(time.Unix(0, 0))})
+	if !errors.Is(err, ErrUnknownMetric) || !errors.Is(err, ErrInvalidExpr) {
+		t.Fatalf("expected unknown metric and invalid expression errors, got %v", err)
+	}
+	if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 4 {
+		t.Errorf("expected 4 problems, got %d: %v", n, err)
+	}
+}
@@ -0,0 +1,52 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Code classifies why a request was rejected. Codes are stable strings that
+// transports pass through unchanged.
+type Code string
+
+const (
+	CodeUnknownTicker        Code = "UNKNOWN_TICKER"
+	CodeInvalidUser          Code = "INVALID_USER"
+	CodeInvalidSide          Code = "INVALID_SIDE"
+	CodeInvalidKind          Code = "INVALID_KIND"
+	CodeInvalidSize          Code = "INVALID_SIZE"
+	CodeInvalidPrice         Code = "INVALID_PRICE"
+	CodeInvalidTIF           Code = "INVALID_TIF"
+	CodeInvalidExpiry        Code = "INVALID_EXPIRY"
+	CodeInvalidDisplaySize   Code = "INVALID_DISPLAY_SIZE"
+	CodeInvalidTrigger       Code = "INVALID_TRIGGER"
+	CodeInvalidPostOnly      Code = "INVALID_POST_ONLY"
+	CodeInvalidAllOrNone     Code = "INVALID_ALL_OR_NONE"
+	CodeInvalidClientOrderID Code = "INVALID_CLIENT_ORDER_ID"
+	// CodeUnsupported is a valid request for a feature the engine lacks.
+	CodeUnsupported Code = "UNSUPPORTED"
+)
+
+// Error is a structured rejection of a Request.
+type Error struct {
+	Code    Code
+	Field   string // the request field at fault, in snake_case
+	Message string
+}
+
+func (e *Error) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+func errorf(code Code, field, format string, args ...any) *Error {
+	return &Error{Code: code, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// CodeOf returns the Code of a rejection, or "" if err is not an *Error.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
@@ -0,0 +1,250 @@
+// Package order defines the canonical order request every entry point (TUI,
+// strategies, external APIs) builds and validates before anything reaches an
+// orderbook, so the cross-field rules live in one place.
+package order
+
+import (
+	"context"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookservice "github.com/zappabad/stockcraft/internal/orderbook/service"
+)
+
+// TimeInForce says how long an order may work.
+type TimeInForce uint8
+
+const (
+	// TIFGTC rests until filled or canceled (the default).
+	TIFGTC TimeInForce = iota
+	// TIFIOC fills what it can immediately and discards the rest.
+	TIFIOC
+	// TIFFOK fills completely and immediately or not at all.
+	TIFFOK
+	// TIFGTT rests until ExpireAt.
+	TIFGTT
+)
+
+func (t TimeInForce) String() string {
+	switch t {
+	case TIFGTC:
+		return "GTC"
+	case TIFIOC:
+		return "IOC"
+	case TIFFOK:
+		return "FOK"
+	case TIFGTT:
+		return "GTT"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MaxClientOrderIDLen bounds Request.ClientOrderID.
+const MaxClientOrderIDLen = 64
+
+// Request is an order as submitted by a user, before it is given an ID.
+type Request struct {
+	TickerID market.TickerID
+	UserID   core.UserID
+	Kind     core.OrderKind
+	Side     core.Side
+	Price    core.PriceTicks // limit orders only
+	Size     core.Size
+
+	TIF          TimeInForce
+	ExpireAt     int64           // Unix nanoseconds, GTT only
+	DisplaySize  core.Size       // iceberg visible size (0 = fully displayed)
+	TriggerPrice core.PriceTicks // stop trigger (0 = not a stop)
+	PostOnly     bool            // rest without taking liquidity
+	AllOrNone    bool            // fill only the whole size, whenever it fills
+
+	ClientOrderID string // optional caller reference
+}
+
+// Validate checks the request against the ticker it is for. It returns an
+// *Error for the first rule broken.
+func (r Request) Validate(t market.Ticker) error {
+	if r.TickerID != t.TickerID() {
+		return errorf(CodeUnknownTicker, "ticker", "request for ticker %d sent to %s", r.TickerID, t.Name)
+	}
+	if r.UserID == 0 {
+		return errorf(CodeInvalidUser, "user", "user is required")
+	}
+	if r.Side != core.SideBuy && r.Side != core.SideSell {
+		return errorf(CodeInvalidSide, "side", "unknown side %d", r.Side)
+	}
+	if r.Size <= 0 {
+		return errorf(CodeInvalidSize, "size", "size must be positive, got %d", r.Size)
+	}
+
+	switch r.Kind {
+	case core.OrderKindLimit:
+		if r.Price <= 0 {
+			return errorf(CodeInvalidPrice, "price", "limit price must be positive, got %d", r.Price)
+		}
+	case core.OrderKindMarket:
+		if r.Price != 0 {
+			return errorf(CodeInvalidPrice, "price", "market orders take no price")
+		}
+		if r.TIF == TIFGTT {
+			return errorf(CodeInvalidTIF, "tif", "market orders cannot be GTT")
+		}
+		if r.DisplaySize != 0 {
+			return errorf(CodeInvalidDisplaySize, "display_size", "market orders cannot be icebergs")
+		}
+	default:
+		return errorf(CodeInvalidKind, "kind", "unknown order kind %d", r.Kind)
+	}
+
+	switch r.TIF {
+	case TIFGTC, TIFIOC, TIFFOK:
+		if r.ExpireAt != 0 {
+			return errorf(CodeInvalidExpiry, "expire_at", "expiry requires GTT, got %s", r.TIF)
+		}
+	case TIFGTT:
+		if r.ExpireAt <= 0 {
+			return errorf(CodeInvalidExpiry, "expire_at", "GTT requires an expiry")
+		}
+	default:
+		return errorf(CodeInvalidTIF, "tif", "unknown time in force %d", r.TIF)
+	}
+
+	if r.DisplaySize < 0 {
+		return errorf(CodeInvalidDisplaySize, "display_size", "display size cannot be negative")
+	}
+	if r.DisplaySize > 0 {
+		if r.DisplaySize >= r.Size {
+			return errorf(CodeInvalidDisplaySize, "display_size", "display size %d must be below total %d", r.DisplaySize, r.Size)
+		}
+		if r.TIF == TIFIOC || r.TIF == TIFFOK {
+			return errorf(CodeInvalidDisplaySize, "display_size", "icebergs must rest, not %s", r.TIF)
+		}
+	}
+
+	if r.TriggerPrice < 0 {
+		return errorf(CodeInvalidTrigger, "trigger_price", "trigger price cannot be negative")
+	}
+	if r.TriggerPrice > 0 && r.DisplaySize > 0 {
+		return errorf(CodeInvalidTrigger, "trigger_price", "stop orders cannot be icebergs")
+	}
+
+	if r.PostOnly {
+		if r.Kind == core.OrderKindMarket {
+			return errorf(CodeInvalidPostOnly, "post_only", "market orders cannot be post-only")
+		}
+		if r.TIF == TIFIOC || r.TIF == TIFFOK {
+			return errorf(CodeInvalidPostOnly, "post_only", "post-only orders must rest, not %s", r.TIF)
+		}
+	}
+	if r.AllOrNone {
+		if r.DisplaySize > 0 {
+			return errorf(CodeInvalidAllOrNone, "all_or_none", "icebergs cannot be all-or-none")
+		}
+		// Market orders are IOC already
+		if r.TIF == TIFIOC || r.Kind == core.OrderKindMarket {
+			return errorf(CodeInvalidAllOrNone, "all_or_none", "all-or-none IOC is FOK; use TIF FOK")
+		}
+	}
+
+	if len(r.ClientOrderID) > MaxClientOrderIDLen {
+		return errorf(CodeInvalidClientOrderID, "client_order_id", "client order ID longer than %d bytes", MaxClientOrderIDLen)
+	}
+	for _, c := range r.ClientOrderID {
+		if c < 0x21 || c > 0x7e {
+			return errorf(CodeInvalidClientOrderID, "client_order_id", "client order ID must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
+
+// Sender is the order entry surface Submit routes to.
+type Sender interface {
+	SubmitLimit(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size) (core.SubmitReport, error)
+	SubmitMarket(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, size core.Size) (core.SubmitReport, error)
+}
+
+// TIFSender is a Sender that also takes limit orders with a time in force.
+// MarketService implements it.
+type TIFSender interface {
+	Sender
+	SubmitLimitTIF(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size, tif core.TimeInForce) (core.SubmitReport, error)
+}
+
+// OptionsSender is a TIFSender that also takes limit orders with options,
+// such as post-only and icebergs. MarketService implements it.
+type OptionsSender interface {
+	TIFSender
+	SubmitLimitWith(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size, opts orderbookservice.LimitOptions) (core.SubmitReport, error)
+}
+
+// StopSender is a Sender that also takes stop orders. MarketService
+// implements it.
+type StopSender interface {
+	Sender
+	SubmitStop(ctx context.Context, tid market.TickerID, userID core.UserID, side core.Side, triggerPrice core.PriceTicks, size core.Size, limitPrice core.PriceTicks) (core.OrderID, error)
+}
+
+// Submit validates the request and sends it. Valid requests that use a
+// feature the matching engine does not execute yet fail with
+// CodeUnsupported rather than being sent without it. IOC and FOK limit
+// orders need a TIFSender; a market order is IOC already, and cannot be FOK
+// here. Post-only orders and icebergs need an OptionsSender. Stops need a
+// StopSender, and a stop-limit rests GTC once triggered.
+// A stop's report only carries its ID, as nothing trades until it triggers.
+func Submit(ctx context.Context, s Sender, t market.Ticker, r Request) (core.SubmitReport, error) {
+	if err := r.Validate(t); err != nil {
+		return core.SubmitReport{}, err
+	}
+	ts, tifOK := s.(TIFSender)
+	opts, optsOK := s.(OptionsSender)
+	ss, stopOK := s.(StopSender)
+	switch {
+	case r.TriggerPrice > 0 && !stopOK:
+		return core.SubmitReport{}, errorf(CodeUnsupported, "trigger_price", "stop orders are not supported")
+	case r.TriggerPrice > 0 && r.Kind == core.OrderKindLimit && r.TIF != TIFGTC:
+		return core.SubmitReport{}, errorf(CodeUnsupported, "tif", "%s stop-limit orders are not supported", r.TIF)
+	case r.Kind == core.OrderKindMarket && r.TIF == TIFFOK,
+		r.Kind == core.OrderKindLimit && (r.TIF == TIFIOC || r.TIF == TIFFOK) && !tifOK,
+		r.TIF == TIFGTT:
+		return core.SubmitReport{}, errorf(CodeUnsupported, "tif", "%s is not supported", r.TIF)
+	case r.DisplaySize > 0 && !optsOK:
+		return core.SubmitReport{}, errorf(CodeUnsupported, "display_size", "iceberg orders are not supported")
+	case r.PostOnly && (!optsOK || r.TriggerPrice > 0):
+		return core.SubmitReport{}, errorf(CodeUnsupported, "post_only", "post-only orders are not supported")
+	case r.AllOrNone:
+		return core.SubmitReport{}, errorf(CodeUnsupported, "all_or_none", "all-or-none orders are not supported")
+	case r.ClientOrderID != "":
+		return core.SubmitReport{}, errorf(CodeUnsupported, "client_order_id", "client order IDs are not supported")
+	}
+
+	switch {
+	case r.TriggerPrice > 0:
+		var limit core.PriceTicks
+		if r.Kind == core.OrderKindLimit {
+			limit = r.Price
+		}
+		id, err := ss.SubmitStop(ctx, r.TickerID, r.UserID, r.Side, r.TriggerPrice, r.Size, limit)
+		return core.SubmitReport{OrderID: id, Remaining: r.Size}, err
+	case r.Kind == core.OrderKindMarket:
+		return s.SubmitMarket(ctx, r.TickerID, r.UserID, r.Side, r.Size)
+	case r.PostOnly || r.DisplaySize > 0:
+		return opts.SubmitLimitWith(ctx, r.TickerID, r.UserID, r.Side, r.Price, r.Size, orderbookservice.LimitOptions{PostOnly: r.PostOnly, DisplaySize: r.DisplaySize})
+	case r.TIF == TIFIOC:
+		return ts.SubmitLimitTIF(ctx, r.TickerID, r.UserID, r.Side, r.Price, r.Size, core.TIFIOC)
+	case r.TIF == TIFFOK:
+		return ts.SubmitLimitTIF(ctx, r.TickerID, r.UserID, r.Side, r.Price, r.Size, core.TIFFOK)
+	}
+	return s.SubmitLimit(ctx, r.TickerID, r.UserID, r.Side, r.Price, r.Size)
+}
+
+// FindTicker returns the ticker with the given ID from a list, as reported by
+// a market's GetTickers.
+func FindTicker(tickers []market.Ticker, tid market.TickerID) (market.Ticker, error) {
+	for _, t := range tickers {
+		if t.TickerID() == tid {
+			return t, nil
+		}
+	}
+	return market.Ticker{}, errorf(CodeUnknownTicker, "ticker", "unknown ticker %d", tid)
+}
@@ -0,0 +1,271 @@
+package order
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookservice "github.com/zappabad/stockcraft/internal/orderbook/service"
+)
+
+var aapl = market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}
+
+func limit() Request {
+	return Request{TickerID: 1, UserID: 100, Kind: core.OrderKindLimit, Side: core.SideBuy, Price: 100, Size: 10}
+}
+
+func marketOrder() Request {
+	return Request{TickerID: 1, UserID: 100, Kind: core.OrderKindMarket, Side: core.SideSell, Size: 10}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(*Request)
+		base  func() Request
+		code  Code
+		field string
+	}{
+		{"valid limit", func(r *Request) {}, limit, "", ""},
+		{"valid market", func(r *Request) {}, marketOrder, "", ""},
+		{"valid market IOC", func(r *Request) { r.TIF = TIFIOC }, marketOrder, "", ""},
+		{"valid GTT", func(r *Request) { r.TIF, r.ExpireAt = TIFGTT, 1 }, limit, "", ""},
+		{"valid iceberg", func(r *Request) { r.DisplaySize = 2 }, limit, "", ""},
+		{"valid stop", func(r *Request) { r.TriggerPrice = 95 }, marketOrder, "", ""},
+		{"valid client ID", func(r *Request) { r.ClientOrderID = "abc-123" }, limit, "", ""},
+		{"valid post-only", func(r *Request) { r.PostOnly = true }, limit, "", ""},
+		{"valid post-only GTT", func(r *Request) { r.PostOnly, r.TIF, r.ExpireAt = true, TIFGTT, 1 }, limit, "", ""},
+		{"valid post-only stop-limit", func(r *Request) { r.PostOnly, r.TriggerPrice = true, 95 }, limit, "", ""},
+		{"valid AON", func(r *Request) { r.AllOrNone = true }, limit, "", ""},
+		{"valid AON FOK", func(r *Request) { r.AllOrNone, r.TIF = true, TIFFOK }, limit, "", ""},
+		{"valid AON post-only", func(r *Request) { r.AllOrNone, r.PostOnly = true, true }, limit, "", ""},
+
+		{"wrong ticker", func(r *Request) { r.TickerID = 2 }, limit, CodeUnknownTicker, "ticker"},
+		{"no user", func(r *Request) { r.UserID = 0 }, limit, CodeInvalidUser, "user"},
+		{"bad side", func(r *Request) { r.Side = 7 }, limit, CodeInvalidSide, "side"},
+		{"zero size", func(r *Request) { r.Size = 0 }, limit, CodeInvalidSize, "size"},
+		{"negative size", func(r *Request) { r.Size = -1 }, marketOrder, CodeInvalidSize, "size"},
+		{"bad kind", func(r *Request) { r.Kind = 9 }, limit, CodeInvalidKind, "kind"},
+		{"limit without price", func(r *Request) { r.Price = 0 }, limit, CodeInvalidPrice, "price"},
+		{"market with price", func(r *Request) { r.Price = 100 }, marketOrder, CodeInvalidPrice, "price"},
+		{"market GTT", func(r *Request) { r.TIF, r.ExpireAt = TIFGTT, 1 }, marketOrder, CodeInvalidTIF, "tif"},
+		{"market iceberg", func(r *Request) { r.DisplaySize = 2 }, marketOrder, CodeInvalidDisplaySize, "display_size"},
+		{"bad TIF", func(r *Request) { r.TIF = 9 }, limit, CodeInvalidTIF, "tif"},
+		{"GTT without expiry", func(r *Request) { r.TIF = TIFGTT }, limit, CodeInvalidExpiry, "expire_at"},
+		{"expiry without GTT", func(r *Request) { r.ExpireAt = 1 }, limit, CodeInvalidExpiry, "expire_at"},
+		{"negative display", func(r *Request) { r.DisplaySize = -1 }, limit, CodeInvalidDisplaySize, "display_size"},
+		{"display not below total", func(r *Request) { r.DisplaySize = 10 }, limit, CodeInvalidDisplaySize, "display_size"},
+		{"IOC iceberg", func(r *Request) { r.TIF, r.DisplaySize = TIFIOC, 2 }, limit, CodeInvalidDisplaySize, "display_size"},
+		{"FOK iceberg", func(r *Request) { r.TIF, r.DisplaySize = TIFFOK, 2 }, limit, CodeInvalidDisplaySize, "display_size"},
+		{"negative trigger", func(r *Request) { r.TriggerPrice = -1 }, limit, CodeInvalidTrigger, "trigger_price"},
+		{"stop iceberg", func(r *Request) { r.TriggerPrice, r.DisplaySize = 95, 2 }, limit, CodeInvalidTrigger, "trigger_price"},
+		{"post-only market", func(r *Request) { r.PostOnly = true }, marketOrder, CodeInvalidPostOnly, "post_only"},
+		{"post-only IOC", func(r *Request) { r.PostOnly, r.TIF = true, TIFIOC }, limit, CodeInvalidPostOnly, "post_only"},
+		{"post-only FOK", func(r *Request) { r.PostOnly, r.TIF = true, TIFFOK }, limit, CodeInvalidPostOnly, "post_only"},
+		{"AON iceberg", func(r *Request) { r.AllOrNone, r.DisplaySize = true, 2 }, limit, CodeInvalidAllOrNone, "all_or_none"},
+		{"AON IOC", func(r *Request) { r.AllOrNone, r.TIF = true, TIFIOC }, limit, CodeInvalidAllOrNone, "all_or_none"},
+		{"AON market", func(r *Request) { r.AllOrNone = true }, marketOrder, CodeInvalidAllOrNone, "all_or_none"},
+		{"long client ID", func(r *Request) { r.ClientOrderID = strings.Repeat("x", MaxClientOrderIDLen+1) }, limit, CodeInvalidClientOrderID, "client_order_id"},
+		{"client ID with space", func(r *Request) { r.ClientOrderID = "a b" }, limit, CodeInvalidClientOrderID, "client_order_id"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := tc.base()
+			tc.edit(&r)
+			err := r.Validate(aapl)
+			if tc.code == "" {
+				if err != nil {
+					t.Fatalf("expected valid, got %v", err)
+				}
+				return
+			}
+			e, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("expected *Error, got %T %v", err, err)
+			}
+			if e.Code != tc.code || e.Field != tc.field {
+				t.Errorf("expected %s on %s, got %s on %s (%s)", tc.code, tc.field, e.Code, e.Field, e.Message)
+			}
+		})
+	}
+}
+
+type fakeSender struct {
+	limits, markets int
+}
+
+func (f *fakeSender) SubmitLimit(context.Context, market.TickerID, core.UserID, core.Side, core.PriceTicks, core.Size) (core.SubmitReport, error) {
+	f.limits++
+	return core.SubmitReport{}, nil
+}
+
+func (f *fakeSender) SubmitMarket(context.Context, market.TickerID, core.UserID, core.Side, core.Size) (core.SubmitReport, error) {
+	f.markets++
+	return core.SubmitReport{}, nil
+}
+
+func TestSubmit(t *testing.T) {
+	ctx := context.Background()
+	var s fakeSender
+
+	if _, err := Submit(ctx, &s, aapl, limit()); err != nil || s.limits != 1 {
+		t.Errorf("expected a limit submit, got %v (%+v)", err, s)
+	}
+	if _, err := Submit(ctx, &s, aapl, marketOrder()); err != nil || s.markets != 1 {
+		t.Errorf("expected a market submit, got %v (%+v)", err, s)
+	}
+
+	bad := limit()
+	bad.Size = 0
+	if _, err := Submit(ctx, &s, aapl, bad); CodeOf(err) != CodeInvalidSize {
+		t.Errorf("expected INVALID_SIZE, got %v", err)
+	}
+
+	// Valid requests for features the engine lacks are not sent
+	for _, edit := range []func(*Request){
+		func(r *Request) { r.TIF = TIFIOC },
+		func(r *Request) { r.DisplaySize = 2 },
+		func(r *Request) { r.TriggerPrice = 95 },
+		func(r *Request) { r.ClientOrderID = "abc" },
+		func(r *Request) { r.PostOnly = true },
+		func(r *Request) { r.AllOrNone = true },
+	} {
+		r := limit()
+		edit(&r)
+		if _, err := Submit(ctx, &s, aapl, r); CodeOf(err) != CodeUnsupported {
+			t.Errorf("expected UNSUPPORTED for %+v, got %v", r, err)
+		}
+	}
+	if s.limits != 1 || s.markets != 1 {
+		t.Errorf("rejected requests reached the sender: %+v", s)
+	}
+}
+
+type fakeTIFSender struct {
+	fakeSender
+	tifs []core.TimeInForce
+}
+
+func (f *fakeTIFSender) SubmitLimitTIF(_ context.Context, _ market.TickerID, _ core.UserID, _ core.Side, _ core.PriceTicks, _ core.Size, tif core.TimeInForce) (core.SubmitReport, error) {
+	f.tifs = append(f.tifs, tif)
+	return core.SubmitReport{}, nil
+}
+
+func TestSubmitTimeInForce(t *testing.T) {
+	ctx := context.Background()
+	var s fakeTIFSender
+
+	for _, tif := range []TimeInForce{TIFGTC, TIFIOC, TIFFOK} {
+		r := limit()
+		r.TIF = tif
+		if _, err := Submit(ctx, &s, aapl, r); err != nil {
+			t.Errorf("%s: unexpected error: %v", tif, err)
+		}
+	}
+	if s.limits != 1 || !slices.Equal(s.tifs, []core.TimeInForce{core.TIFIOC, core.TIFFOK}) {
+		t.Errorf("expected GTC sent plain and IOC, FOK with their TIF, got %d plain and %v", s.limits, s.tifs)
+	}
+
+	// A market order is IOC already; FOK and GTT are not executed
+	ioc := marketOrder()
+	ioc.TIF = TIFIOC
+	if _, err := Submit(ctx, &s, aapl, ioc); err != nil || s.markets != 1 {
+		t.Errorf("expected an IOC market order sent as a market order, got %v", err)
+	}
+	fok := marketOrder()
+	fok.TIF = TIFFOK
+	if _, err := Submit(ctx, &s, aapl, fok); CodeOf(err) != CodeUnsupported {
+		t.Errorf("expected UNSUPPORTED for a FOK market order, got %v", err)
+	}
+	gtt := limit()
+	gtt.TIF, gtt.ExpireAt = TIFGTT, 1
+	if _, err := Submit(ctx, &s, aapl, gtt); CodeOf(err) != CodeUnsupported {
+		t.Errorf("expected UNSUPPORTED for GTT, got %v", err)
+	}
+}
+
+type fakeOptionsSender struct {
+	fakeTIFSender
+	opts []orderbookservice.LimitOptions
+}
+
+func (f *fakeOptionsSender) SubmitLimitWith(_ context.Context, _ market.TickerID, _ core.UserID, _ core.Side, _ core.PriceTicks, _ core.Size, opts orderbookservice.LimitOptions) (core.SubmitReport, error) {
+	f.opts = append(f.opts, opts)
+	return core.SubmitReport{}, nil
+}
+
+func TestSubmitPostOnly(t *testing.T) {
+	ctx := context.Background()
+	var s fakeOptionsSender
+
+	r := limit()
+	r.PostOnly = true
+	if _, err := Submit(ctx, &s, aapl, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.opts) != 1 || !s.opts[0].PostOnly || s.opts[0].TIF != core.TIFGTC || s.limits != 0 {
+		t.Errorf("expected one post-only GTC submit, got %+v and %d plain", s.opts, s.limits)
+	}
+
+	// Plain limits keep the plain path
+	if _, err := Submit(ctx, &s, aapl, limit()); err != nil || s.limits != 1 || len(s.opts) != 1 {
+		t.Errorf("expected a plain limit submit, got %v (%d plain)", err, s.limits)
+	}
+	stop := r
+	stop.TriggerPrice = 95
+	if _, err := Submit(ctx, &s, aapl, stop); CodeOf(err) != CodeUnsupported {
+		t.Errorf("expected UNSUPPORTED for a post-only stop, got %v", err)
+	}
+}
+
+func TestSubmitIceberg(t *testing.T) {
+	ctx := context.Background()
+	var s fakeOptionsSender
+
+	r := limit()
+	r.DisplaySize = 2
+	if _, err := Submit(ctx, &s, aapl, r); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(s.opts) != 1 || s.opts[0].DisplaySize != 2 || s.opts[0].PostOnly || s.limits != 0 {
+		t.Errorf("expected one iceberg submit showing 2, got %+v and %d plain", s.opts, s.limits)
+	}
+}
+
+type fakeStopSender struct {
+	fakeSender
+	limitPrices []core.PriceTicks
+}
+
+func (f *fakeStopSender) SubmitStop(_ context.Context, _ market.TickerID, _ core.UserID, _ core.Side, _ core.PriceTicks, _ core.Size, limitPrice core.PriceTicks) (core.OrderID, error) {
+	f.limitPrices = append(f.limitPrices, limitPrice)
+	return core.OrderID(len(f.limitPrices)), nil
+}
+
+func TestSubmitStop(t *testing.T) {
+	ctx := context.Background()
+	var s fakeStopSender
+
+	stopMarket := marketOrder()
+	stopMarket.TriggerPrice = 95
+	stopLimit := limit()
+	stopLimit.TriggerPrice = 101
+	for _, r := range []Request{stopMarket, stopLimit} {
+		if report, err := Submit(ctx, &s, aapl, r); err != nil || report.OrderID == 0 || report.Remaining != r.Size {
+			t.Errorf("expected a stop accepted with its size remaining, got %+v (%v)", report, err)
+		}
+	}
+	if !slices.Equal(s.limitPrices, []core.PriceTicks{0, 100}) || s.limits != 0 || s.markets != 0 {
+		t.Errorf("expected a stop-market and a stop-limit at 100 sent as stops, got %v (%+v)", s.limitPrices, s.fakeSender)
+	}
+
+	// A triggered stop-limit rests GTC
+	ioc := stopLimit
+	ioc.TIF = TIFIOC
+	if _, err := Submit(ctx, &s, aapl, ioc); CodeOf(err) != CodeUnsupported {
+		t.Errorf("expected UNSUPPORTED for an IOC stop-limit, got %v", err)
+	}
+}
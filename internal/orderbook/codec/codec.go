@@ -0,0 +1,178 @@
+// Package codec encodes orderbook events as newline-delimited JSON.
+//
+// Each line is an envelope {"type": "...", "event": {...}} so a stream of
+// mixed events can be decoded back into concrete core event values.
+package codec
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// Event type tags used in the envelope.
+const (
+	TypeTrade          = "trade"
+	TypeOrderRested    = "rested"
+	TypeOrderReduced   = "reduced"
+	TypeOrderRemoved   = "removed"
+	TypeOrderRefreshed = "refreshed"
+	TypeCrossResolved  = "cross_resolved"
+	TypeStopAccepted   = "stop_accepted"
+	TypeStopTriggered  = "stop_triggered"
+	TypeStopCanceled   = "stop_canceled"
+	TypeStopExpired    = "stop_expired"
+)
+
+type envelope struct {
+	Type  string          `json:"type"`
+	Event json.RawMessage `json:"event"`
+}
+
+// TypeOf returns the envelope tag for an event.
+func TypeOf(ev core.Event) (string, error) {
+	switch ev.(type) {
+	case core.TradeEvent:
+		return TypeTrade, nil
+	case core.OrderRestedEvent:
+		return TypeOrderRested, nil
+	case core.OrderReducedEvent:
+		return TypeOrderReduced, nil
+	case core.OrderRemovedEvent:
+		return TypeOrderRemoved, nil
+	case core.OrderRefreshedEvent:
+		return TypeOrderRefreshed, nil
+	case core.CrossResolvedEvent:
+		return TypeCrossResolved, nil
+	case core.StopAcceptedEvent:
+		return TypeStopAccepted, nil
+	case core.StopTriggeredEvent:
+		return TypeStopTriggered, nil
+	case core.StopCanceledEvent:
+		return TypeStopCanceled, nil
+	case core.StopExpiredEvent:
+		return TypeStopExpired, nil
+	default:
+		return "", fmt.Errorf("%w: %T", ErrUnknownEventType, ev)
+	}
+}
+
+// Marshal encodes an event as a single JSON line (without trailing newline).
+func Marshal(ev core.Event) ([]byte, error) {
+	typ, err := TypeOf(ev)
+	if err != nil {
+		return nil, err
+	}
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(envelope{Type: typ, Event: body})
+}
+
+// Unmarshal decodes a single JSON line produced by Marshal.
+func Unmarshal(line []byte) (core.Event, error) {
+	var env envelope
+	if err := json.Unmarshal(line, &env); err != nil {
+		return nil, err
+	}
+	switch env.Type {
+	case TypeTrade:
+		var e core.TradeEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeOrderRested:
+		var e core.OrderRestedEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeOrderReduced:
+		var e core.OrderReducedEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeOrderRemoved:
+		var e core.OrderRemovedEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeOrderRefreshed:
+		var e core.OrderRefreshedEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeCrossResolved:
+		var e core.CrossResolvedEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeStopAccepted:
+		var e core.StopAcceptedEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeStopTriggered:
+		var e core.StopTriggeredEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeStopCanceled:
+		var e core.StopCanceledEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	case TypeStopExpired:
+		var e core.StopExpiredEvent
+		err := json.Unmarshal(env.Event, &e)
+		return e, err
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, env.Type)
+	}
+}
+
+// Encoder writes events to a stream, one per line.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder creates an Encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes ev followed by a newline. It returns the number of bytes
+// written.
+func (e *Encoder) Encode(ev core.Event) (int, error) {
+	line, err := Marshal(ev)
+	if err != nil {
+		return 0, err
+	}
+	line = append(line, '\n')
+	return e.w.Write(line)
+}
+
+// Decoder reads events from a stream produced by an Encoder.
+type Decoder struct {
+	sc *bufio.Scanner
+}
+
+// NewDecoder creates a Decoder reading from r.
+func NewDecoder(r io.Reader) *Decoder {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &Decoder{sc: sc}
+}
+
+// Decode returns the next event, or io.EOF at the end of the stream.
+// Blank lines are skipped.
+func (d *Decoder) Decode() (core.Event, error) {
+	for d.sc.Scan() {
+		line := d.sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		return Unmarshal(line)
+	}
+	if err := d.sc.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,173 @@
+package core
+
+import "sort"
+
+// UncrossReport is returned after an auction uncross.
+type UncrossReport struct {
+	Price  PriceTicks // equilibrium price; 0 if the book did not cross
+	Volume Size       // total size executed at Price
+	Trades int
+}
+
+// BeginAuction disables matching. Limit orders rest without matching, even
+// if they cross the opposite side, and market orders are rejected with
+// ErrAuction until Uncross runs.
+func (c *Core) BeginAuction() {
+	c.auction = true
+}
+
+// InAuction reports whether matching is disabled.
+func (c *Core) InAuction() bool {
+	return c.auction
+}
+
+// Uncross executes the crossed part of the book at a single equilibrium
+// price and re-enables continuous matching.
+//
+// The price maximizes executable volume; ties go to the smallest imbalance,
+// then toward the side with surplus (highest price for a buy surplus, lowest
+// for a sell surplus), then to the lowest price. Orders execute in
+// price-time priority, and the later order of each pair is the taker.
+func (c *Core) Uncross(now int64) (UncrossReport, []Event) {
+	c.auction = false
+
+	price, volume := c.equilibrium()
+	report, events := c.executeAt(price, volume, now)
+	return report, c.afterMatch(events, now)
+}
+
+// executeAt trades every bid at or above price against every ask at or
+// below it, all at price, in price-time priority.
+func (c *Core) executeAt(price PriceTicks, volume Size, now int64) (UncrossReport, []Event) {
+	if volume == 0 {
+		return UncrossReport{}, nil
+	}
+
+	report := UncrossReport{Price: price}
+	var events []Event
+	for {
+		bl, al := c.ob.bids.bestLevel(), c.ob.asks.bestLevel()
+		if bl == nil || al == nil || bl.price < price || al.price > price {
+			break
+		}
+		bid, ask := bl.head, al.head
+		traded := min(bid.size, ask.size)
+
+		taker, maker := laterOf(bid, ask)
+		events = append(events, TradeEvent{
+			Price:        price,
+			Size:         traded,
+			TakerSide:    taker.side,
+			Time:         now,
+			TakerOrderID: taker.id,
+			TakerUserID:  taker.userID,
+			MakerOrderID: maker.id,
+			MakerUserID:  maker.userID,
+		})
+		report.Volume += traded
+		report.Trades++
+
+		events = c.fillHead(c.ob.bids, bid, traded, now, events)
+		events = c.fillHead(c.ob.asks, ask, traded, now, events)
+	}
+	return report, events
+}
+
+// fillHead reduces the order at the head of its level by traded, removing it
+// (and the level, if emptied) when filled, or refreshing it when it is an
+// iceberg with reserve left.
+func (c *Core) fillHead(side *bookSide, node *restingOrder, traded Size, now int64, events []Event) []Event {
+	l := node.level
+	node.size -= traded
+	l.totalVolume -= traded
+
+	if node.isFilled() && node.reserve > 0 {
+		c.ob.refresh(node, now)
+		return append(events, refreshedEvent(node))
+	}
+	if node.isFilled() {
+		l.popHead()
+		c.ob.forget(node)
+		if l.totalVolume <= 0 || l.head == nil {
+			side.removeLevel(l)
+		}
+		return append(events, OrderRemovedEvent{
+			OrderID:   node.id,
+			Reason:    RemoveReasonFilled,
+			Remaining: 0,
+			Price:     node.price,
+			Side:      node.side,
+			UserID:    node.userID,
+			Time:      now,
+		})
+	}
+	return append(events, OrderReducedEvent{
+		OrderID:   node.id,
+		Delta:     -traded,
+		Remaining: node.size,
+		Price:     node.price,
+		Side:      node.side,
+		UserID:    node.userID,
+		MatchTime: now,
+	})
+}
+
+// equilibrium returns the uncross price and its executable volume.
+func (c *Core) equilibrium() (PriceTicks, Size) {
+	var prices []PriceTicks
+	for p := range c.ob.bids.levels {
+		prices = append(prices, p)
+	}
+	for p := range c.ob.asks.levels {
+		if _, ok := c.ob.bids.levels[p]; !ok {
+			prices = append(prices, p)
+		}
+	}
+	sort.Slice(prices, func(i, j int) bool { return prices[i] < prices[j] })
+
+	var (
+		bestPrice   PriceTicks
+		bestVolume  Size
+		bestSurplus Size // buy volume minus sell volume at bestPrice
+	)
+	for _, p := range prices {
+		var demand, supply Size
+		for lp, l := range c.ob.bids.levels {
+			if lp >= p {
+				demand += l.totalVolume
+			}
+		}
+		for lp, l := range c.ob.asks.levels {
+			if lp <= p {
+				supply += l.totalVolume
+			}
+		}
+		volume := min(demand, supply)
+		if volume == 0 {
+			continue
+		}
+		surplus := demand - supply
+
+		switch {
+		case volume > bestVolume:
+		case volume < bestVolume:
+			continue
+		case abs(surplus) < abs(bestSurplus):
+		case abs(surplus) > abs(bestSurplus):
+			continue
+		case surplus > 0:
+			// buy pressure: prefer the higher price (prices ascend)
+		default:
+			continue
+		}
+		bestPrice, bestVolume, bestSurplus = p, volume, surplus
+	}
+	return bestPrice, bestVolume
+}
+
+func abs(s Size) Size {
+	if s < 0 {
+		return -s
+	}
+	return s
+}
@@ -0,0 +1,88 @@
+package core
+
+import "testing"
+
+func TestAuctionUncross(t *testing.T) {
+	c := NewCore()
+	c.BeginAuction()
+
+	orders := []Order{
+		{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 102, Size: 10, Time: 1000},
+		{ID: 2, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 101, Size: 10, Time: 1001},
+		{ID: 3, UserID: 200, Side: SideSell, Kind: OrderKindLimit, Price: 99, Size: 5, Time: 1002},
+		{ID: 4, UserID: 200, Side: SideSell, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1003},
+		{ID: 5, UserID: 200, Side: SideSell, Kind: OrderKindLimit, Price: 103, Size: 5, Time: 1004},
+	}
+	for _, o := range orders {
+		report, _, err := c.SubmitLimit(o)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(report.Fills) != 0 || !report.Rested {
+			t.Fatalf("expected order %d to rest without matching during auction", o.ID)
+		}
+	}
+
+	if _, _, err := c.SubmitMarket(Order{ID: 6, UserID: 300, Side: SideBuy, Kind: OrderKindMarket, Size: 1, Time: 1005}); err != ErrAuction {
+		t.Errorf("expected ErrAuction for market order, got %v", err)
+	}
+
+	// 100 and 101 both execute 15 with a buy surplus of 5; the surplus
+	// pushes the price up to 101.
+	report, events := c.Uncross(2000)
+	if report.Price != 101 || report.Volume != 15 {
+		t.Fatalf("expected 15 @ 101, got %+v", report)
+	}
+	var traded Size
+	for _, ev := range events {
+		if tr, ok := ev.(TradeEvent); ok {
+			if tr.Price != 101 {
+				t.Errorf("expected every print at 101, got %d", tr.Price)
+			}
+			traded += tr.Size
+		}
+	}
+	if traded != 15 {
+		t.Errorf("expected 15 traded, got %d", traded)
+	}
+	if c.InAuction() {
+		t.Error("expected continuous matching after uncross")
+	}
+
+	// Leftovers: 5 bid at 101, 5 offered at 103
+	if n := c.ob.orders[2]; n == nil || n.size != 5 {
+		t.Errorf("expected 5 left on order 2, got %+v", n)
+	}
+	for _, id := range []OrderID{1, 3, 4} {
+		if _, ok := c.ob.orders[id]; ok {
+			t.Errorf("expected order %d to be filled", id)
+		}
+	}
+	if best := c.ob.asks.bestLevel(); best == nil || best.price != 103 {
+		t.Errorf("expected best ask 103 after uncross")
+	}
+
+	// Continuous matching works again
+	mkt, _, err := c.SubmitMarket(Order{ID: 7, UserID: 300, Side: SideSell, Kind: OrderKindMarket, Size: 5, Time: 3000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mkt.Fills) != 1 || mkt.Fills[0].Price != 101 {
+		t.Errorf("expected market sell to fill at 101, got %+v", mkt.Fills)
+	}
+}
+
+func TestUncrossWithoutCross(t *testing.T) {
+	c := NewCore()
+	c.BeginAuction()
+	c.SubmitLimit(Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 99, Size: 10, Time: 1000})
+	c.SubmitLimit(Order{ID: 2, UserID: 200, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 10, Time: 1001})
+
+	report, events := c.Uncross(2000)
+	if report.Volume != 0 || len(events) != 0 {
+		t.Errorf("expected no execution for an uncrossed book, got %+v", report)
+	}
+	if c.InAuction() {
+		t.Error("expected continuous matching after uncross")
+	}
+}
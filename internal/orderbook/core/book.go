@@ -11,6 +11,12 @@ type restingOrder struct {
 	size   Size
 	time   int64
 
+	postOnly bool
+	// display is an iceberg's visible slice and reserve its hidden size;
+	// size is only what is on display. Both are 0 for a plain order.
+	display Size
+	reserve Size
+
 	level *level
 	prev  *restingOrder
 	next  *restingOrder
@@ -18,6 +24,9 @@ type restingOrder struct {
 
 func (o *restingOrder) isFilled() bool { return o.size <= 0 }
 
+// remaining is the order's whole unfilled size, visible and in reserve.
+func (o *restingOrder) remaining() Size { return o.size + o.reserve }
+
 type level struct {
 	price       PriceTicks
 	head, tail  *restingOrder
@@ -160,6 +169,7 @@ type orderBook struct {
 	asks *bookSide
 
 	orders map[OrderID]*restingOrder // resting only
+	byUser map[UserID]map[OrderID]*restingOrder
 }
 
 func newOrderBook() *orderBook {
@@ -167,6 +177,7 @@ func newOrderBook() *orderBook {
 		bids:   newBookSide(true),
 		asks:   newBookSide(false),
 		orders: map[OrderID]*restingOrder{},
+		byUser: map[UserID]map[OrderID]*restingOrder{},
 	}
 }
 
@@ -185,15 +196,39 @@ func (ob *orderBook) addResting(o Order) *restingOrder {
 		price:  o.Price,
 		size:   o.Size,
 		time:   o.Time,
+
+		postOnly: o.PostOnly,
+	}
+	if o.DisplaySize > 0 && o.Size > o.DisplaySize {
+		node.size = o.DisplaySize
+		node.display = o.DisplaySize
+		node.reserve = o.Size - o.DisplaySize
 	}
 	side := ob.sideFor(o.Side)
 	l := side.getOrCreate(o.Price)
 	l.append(node)
 	l.totalVolume += node.size
 	ob.orders[node.id] = node
+	mine := ob.byUser[node.userID]
+	if mine == nil {
+		mine = map[OrderID]*restingOrder{}
+		ob.byUser[node.userID] = mine
+	}
+	mine[node.id] = node
 	return node
 }
 
+// forget drops a node that has left its level from the order maps.
+func (ob *orderBook) forget(node *restingOrder) {
+	delete(ob.orders, node.id)
+	if mine, ok := ob.byUser[node.userID]; ok {
+		delete(mine, node.id)
+		if len(mine) == 0 {
+			delete(ob.byUser, node.userID)
+		}
+	}
+}
+
 func (ob *orderBook) cancel(id OrderID) (*restingOrder, bool) {
 	node, ok := ob.orders[id]
 	if !ok {
@@ -208,6 +243,6 @@ func (ob *orderBook) cancel(id OrderID) (*restingOrder, bool) {
 			side.removeLevel(l)
 		}
 	}
-	delete(ob.orders, id)
+	ob.forget(node)
 	return node, true
 }
@@ -1,11 +1,18 @@
 package core
 
-import "errors"
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"time"
+)
 
 var (
 	ErrInvalidOrder = errors.New("invalid order")
 	ErrDuplicateID  = errors.New("duplicate order id")
 	ErrNotFound     = errors.New("order not found")
+	ErrAuction      = errors.New("market, IOC and FOK orders not accepted during auction")
+	ErrWouldCross   = errors.New("post-only order would cross the book")
 )
 
 // Fill represents a single fill from a match.
@@ -16,11 +23,33 @@ type Fill struct {
 }
 
 // SubmitReport is returned after submitting an order.
+//
+// Remaining is the size that did not fill. For a GTC limit order it is the
+// size now resting, so RestedSize == Remaining, unless self-trade prevention
+// canceled it; for a market, IOC or FOK order it is discarded and RestedSize
+// is 0. An iceberg's RestedSize counts its reserve as well as its visible
+// slice. A FOK order that cannot fill completely does nothing: it has no
+// fills and Remaining is its whole size.
 type SubmitReport struct {
-	OrderID   OrderID
-	Remaining Size
-	Fills     []Fill
-	Rested    bool
+	OrderID    OrderID
+	Remaining  Size // unfilled size (resting or discarded)
+	RestedSize Size // size resting on the book after the submit
+	Fills      []Fill
+	Rested     bool
+
+	// STPCanceled lists the submitter's resting orders that self-trade
+	// prevention canceled instead of trading against (STPCancelResting).
+	STPCanceled []OrderID
+	// STPTakerCanceled is set when self-trade prevention discarded the
+	// order's remaining size (STPCancelTaker).
+	STPTakerCanceled bool
+	// FullyUnfilled is set when the order neither filled nor rested any
+	// size, e.g. a market order against an empty side or a killed FOK.
+	FullyUnfilled bool
+
+	// Latency is the service's round-trip time for the submit, when it
+	// records latency; the core leaves it 0.
+	Latency time.Duration
 }
 
 // CancelReport is returned after canceling an order.
@@ -29,15 +58,42 @@ type CancelReport struct {
 	CanceledSize Size
 }
 
+// AmendReport is returned after amending an order.
+type AmendReport struct {
+	OrderID      OrderID
+	Remaining    Size // size left resting after the amend
+	Fills        []Fill
+	Rested       bool
+	KeptPriority bool // true if the order kept its place in the queue
+}
+
 // Core is the deterministic order matching engine.
 // It has no goroutines, mutexes, channels, or time calls.
 type Core struct {
 	ob *orderBook
+	// auction disables matching: limit orders rest even if they cross,
+	// until Uncross runs.
+	auction bool
+	stp     STPMode
+	// stops are parked stop orders, off the book until triggered.
+	stops stopBook
 }
 
-// NewCore creates a new Core instance.
+// CoreConfig holds configuration for a Core. The zero value is the default.
+type CoreConfig struct {
+	// SelfTradePrevention is the self-trade prevention mode (see
+	// SetSelfTradePrevention). The zero value is STPNone.
+	SelfTradePrevention STPMode
+}
+
+// NewCore creates a new Core instance with the default configuration.
 func NewCore() *Core {
-	return &Core{ob: newOrderBook()}
+	return NewCoreWithConfig(CoreConfig{})
+}
+
+// NewCoreWithConfig creates a Core configured by cfg.
+func NewCoreWithConfig(cfg CoreConfig) *Core {
+	return &Core{ob: newOrderBook(), stp: cfg.SelfTradePrevention, stops: newStopBook()}
 }
 
 func validateLimit(o Order) error {
@@ -59,6 +115,18 @@ func validateLimit(o Order) error {
 	if o.Time <= 0 {
 		return ErrInvalidOrder
 	}
+	if !validTIF(o.TIF) {
+		return ErrInvalidOrder
+	}
+	if o.PostOnly && o.TIF != TIFGTC {
+		return ErrInvalidOrder
+	}
+	if o.DisplaySize < 0 || o.DisplaySize > 0 && (o.DisplaySize >= o.Size || o.TIF != TIFGTC) {
+		return ErrInvalidOrder
+	}
+	if o.StopPrice != 0 || o.ExpireAt != 0 {
+		return ErrInvalidOrder
+	}
 	return nil
 }
 
@@ -78,87 +146,353 @@ func validateMarket(o Order) error {
 	if o.Time <= 0 {
 		return ErrInvalidOrder
 	}
+	if !validTIF(o.TIF) || o.PostOnly || o.DisplaySize != 0 || o.StopPrice != 0 || o.ExpireAt != 0 {
+		return ErrInvalidOrder
+	}
 	return nil
 }
 
-// SubmitLimit submits a limit order to the book.
+// SubmitLimit submits a limit order to the book. A GTC order rests whatever
+// does not fill; an IOC order discards it, and a FOK order fills completely
+// or not at all. IOC and FOK orders are rejected with ErrAuction while an
+// auction runs. A post-only order that would meet the opposite best price is
+// rejected with ErrWouldCross, auction or not, before anything on the book
+// changes. An iceberg (DisplaySize > 0) rests only its visible slice; each
+// time that fills, the next is shown from reserve at the tail of its level
+// with an OrderRefreshedEvent. Stops the order's trades trigger follow its
+// own events (see OnTrade).
 func (c *Core) SubmitLimit(o Order) (SubmitReport, []Event, error) {
+	report, evs, err := c.submitLimit(o)
+	if err != nil {
+		return report, evs, err
+	}
+	return report, c.afterMatch(evs, o.Time), nil
+}
+
+// submitLimit is SubmitLimit without the trigger pass.
+func (c *Core) submitLimit(o Order) (SubmitReport, []Event, error) {
 	if err := validateLimit(o); err != nil {
 		return SubmitReport{}, nil, err
 	}
-	if _, exists := c.ob.orders[o.ID]; exists {
+	if c.idTaken(o.ID) {
 		return SubmitReport{}, nil, ErrDuplicateID
 	}
+	if c.auction && o.TIF != TIFGTC {
+		return SubmitReport{}, nil, ErrAuction
+	}
+	if o.PostOnly && c.wouldCross(o.Side, o.Price) {
+		return SubmitReport{}, nil, ErrWouldCross
+	}
 
 	remaining := o.Size
 	limit := o.Price
-	fills, evs := c.match(o, &remaining, &limit)
+	if o.TIF == TIFFOK && !c.fillable(o, &limit) {
+		return SubmitReport{OrderID: o.ID, Remaining: remaining, FullyUnfilled: true}, nil, nil
+	}
+	var (
+		fills []Fill
+		evs   []Event
+		stp   stpResult
+	)
+	if !c.auction {
+		fills, evs, stp = c.match(o, &remaining, &limit)
+	}
 
 	rested := false
-	if remaining > 0 {
+	if remaining > 0 && !stp.takerCancel && o.TIF == TIFGTC {
 		o.Size = remaining
-		c.ob.addResting(o)
+		node := c.ob.addResting(o)
 		rested = true
 		evs = append(evs, OrderRestedEvent{
 			OrderID: o.ID, UserID: o.UserID, Side: o.Side,
-			Price: o.Price, Size: remaining, Time: o.Time,
+			Price: o.Price, Size: node.size, Time: o.Time,
 		})
 	}
 
-	return SubmitReport{
+	report := SubmitReport{
 		OrderID:   o.ID,
 		Remaining: remaining,
 		Fills:     fills,
 		Rested:    rested,
-	}, evs, nil
+
+		STPCanceled:      stp.canceled,
+		STPTakerCanceled: stp.takerCancel,
+		FullyUnfilled:    len(fills) == 0 && !rested,
+	}
+	if rested {
+		report.RestedSize = remaining
+	}
+	return report, evs, nil
 }
 
-// SubmitMarket submits a market order to the book.
+// SubmitMarket submits a market order to the book. Market orders never rest,
+// so GTC and IOC behave alike; a FOK market order fills completely or not
+// at all. Stops its trades trigger follow its own events (see OnTrade).
 func (c *Core) SubmitMarket(o Order) (SubmitReport, []Event, error) {
+	report, evs, err := c.submitMarket(o)
+	if err != nil {
+		return report, evs, err
+	}
+	return report, c.afterMatch(evs, o.Time), nil
+}
+
+// submitMarket is SubmitMarket without the trigger pass.
+func (c *Core) submitMarket(o Order) (SubmitReport, []Event, error) {
 	if err := validateMarket(o); err != nil {
 		return SubmitReport{}, nil, err
 	}
-	if _, exists := c.ob.orders[o.ID]; exists {
+	if c.idTaken(o.ID) {
 		return SubmitReport{}, nil, ErrDuplicateID
 	}
+	if c.auction {
+		return SubmitReport{}, nil, ErrAuction
+	}
 
 	remaining := o.Size
-	fills, evs := c.match(o, &remaining, nil)
+	if o.TIF == TIFFOK && !c.fillable(o, nil) {
+		return SubmitReport{OrderID: o.ID, Remaining: remaining, FullyUnfilled: true}, nil, nil
+	}
+	fills, evs, stp := c.match(o, &remaining, nil)
 
 	return SubmitReport{
 		OrderID:   o.ID,
 		Remaining: remaining,
 		Fills:     fills,
 		Rested:    false,
+
+		STPCanceled:      stp.canceled,
+		STPTakerCanceled: stp.takerCancel,
+		FullyUnfilled:    len(fills) == 0,
 	}, evs, nil
 }
 
-// Cancel cancels a resting order.
+// Cancel cancels a resting order or a pending stop.
 func (c *Core) Cancel(id OrderID, now int64) (CancelReport, []Event, error) {
 	if id == 0 || now <= 0 {
 		return CancelReport{}, nil, ErrInvalidOrder
 	}
 	node, ok := c.ob.cancel(id)
 	if !ok {
-		return CancelReport{}, nil, ErrNotFound
+		st, ok := c.stops.remove(id)
+		if !ok {
+			return CancelReport{}, nil, ErrNotFound
+		}
+		reports, events := cancelStops([]pendingStop{st}, now)
+		return reports[0], events, nil
 	}
 	ev := OrderRemovedEvent{
 		OrderID:   node.id,
 		Reason:    RemoveReasonCanceled,
-		Remaining: node.size,
+		Remaining: node.remaining(),
+		Price:     node.price,
+		Side:      node.side,
+		UserID:    node.userID,
+		Time:      now,
+	}
+	return CancelReport{OrderID: id, CanceledSize: node.remaining()}, []Event{ev}, nil
+}
+
+// CancelAll cancels every resting order: bids then asks, best level first,
+// in queue order within a level. Pending stops follow, buys then sells in
+// trigger order.
+func (c *Core) CancelAll(now int64) ([]CancelReport, []Event) {
+	var reports []CancelReport
+	var events []Event
+	for _, side := range []Side{SideBuy, SideSell} {
+		bs := c.ob.sideFor(side)
+		for l := bs.bestLevel(); l != nil; l = bs.bestLevel() {
+			node, _ := c.ob.cancel(l.head.id)
+			reports = append(reports, CancelReport{OrderID: node.id, CanceledSize: node.remaining()})
+			events = append(events, OrderRemovedEvent{
+				OrderID:   node.id,
+				Reason:    RemoveReasonCanceled,
+				Remaining: node.remaining(),
+				Price:     node.price,
+				Side:      node.side,
+				UserID:    node.userID,
+				Time:      now,
+			})
+		}
+	}
+	stopReports, stopEvents := cancelStops(c.stops.removeIf(func(pendingStop) bool { return true }), now)
+	return append(reports, stopReports...), append(events, stopEvents...)
+}
+
+// CancelUser cancels every resting order of one user: bids then asks, best
+// price first, then by time and ID. It reads the user's entry in the order
+// index, so it costs the user's order count, not the book's. The user's
+// pending stops follow, buys then sells in trigger order.
+func (c *Core) CancelUser(userID UserID, now int64) ([]CancelReport, []Event) {
+	var stops []pendingStop
+	if len(c.stops.ids) > 0 {
+		stops = c.stops.removeIf(func(st pendingStop) bool { return st.userID == userID })
+	}
+	stopReports, stopEvents := cancelStops(stops, now)
+	mine := c.ob.byUser[userID]
+	if len(mine) == 0 {
+		return stopReports, stopEvents
+	}
+	nodes := make([]*restingOrder, 0, len(mine))
+	for _, node := range mine {
+		nodes = append(nodes, node)
+	}
+	slices.SortFunc(nodes, func(a, b *restingOrder) int {
+		if a.side != b.side {
+			return cmp.Compare(a.side, b.side) // SideBuy first
+		}
+		if a.price != b.price {
+			if a.side == SideBuy {
+				return cmp.Compare(b.price, a.price)
+			}
+			return cmp.Compare(a.price, b.price)
+		}
+		if a.time != b.time {
+			return cmp.Compare(a.time, b.time)
+		}
+		return cmp.Compare(a.id, b.id)
+	})
+
+	reports := make([]CancelReport, 0, len(nodes))
+	events := make([]Event, 0, len(nodes))
+	for _, node := range nodes {
+		c.ob.cancel(node.id)
+		reports = append(reports, CancelReport{OrderID: node.id, CanceledSize: node.remaining()})
+		events = append(events, OrderRemovedEvent{
+			OrderID:   node.id,
+			Reason:    RemoveReasonCanceled,
+			Remaining: node.remaining(),
+			Price:     node.price,
+			Side:      node.side,
+			UserID:    node.userID,
+			Time:      now,
+		})
+	}
+	return append(reports, stopReports...), append(events, stopEvents...)
+}
+
+// Amend changes the price and/or size of a resting order.
+//
+// A size-down at the same price is applied in place and keeps time priority.
+// Any other change (new price or larger size) loses priority: the order is
+// pulled and resubmitted under the same ID at time now, so a price that now
+// crosses the opposite side matches immediately and only the remainder rests.
+// A post-only order is instead rejected with ErrWouldCross and left as it was.
+//
+// An iceberg's size is its whole remaining size. A size-down takes from the
+// reserve first, so only a cut into the visible slice emits an
+// OrderReducedEvent, and a resubmitted iceberg keeps its display size.
+func (c *Core) Amend(id OrderID, newPrice PriceTicks, newSize Size, now int64) (AmendReport, []Event, error) {
+	if id == 0 || now <= 0 || newPrice <= 0 || newSize <= 0 {
+		return AmendReport{}, nil, ErrInvalidOrder
+	}
+	node, ok := c.ob.orders[id]
+	if !ok {
+		return AmendReport{}, nil, ErrNotFound
+	}
+
+	if newPrice == node.price && newSize <= node.remaining() {
+		delta := node.remaining() - newSize
+		hidden := min(delta, node.reserve)
+		node.reserve -= hidden
+		delta -= hidden
+		report := AmendReport{OrderID: id, Remaining: newSize, Rested: true, KeptPriority: true}
+		if delta == 0 {
+			return report, nil, nil
+		}
+		node.size -= delta
+		node.level.totalVolume -= delta
+		ev := OrderReducedEvent{
+			OrderID:   node.id,
+			Delta:     -delta,
+			Remaining: node.size,
+			Price:     node.price,
+			Side:      node.side,
+			UserID:    node.userID,
+			MatchTime: now,
+		}
+		return report, []Event{ev}, nil
+	}
+
+	if node.postOnly && c.wouldCross(node.side, newPrice) {
+		return AmendReport{}, nil, ErrWouldCross
+	}
+
+	c.ob.cancel(id)
+	evs := []Event{OrderRemovedEvent{
+		OrderID:   node.id,
+		Reason:    RemoveReasonAmended,
+		Remaining: node.remaining(),
 		Price:     node.price,
 		Side:      node.side,
 		UserID:    node.userID,
 		Time:      now,
+	}}
+
+	o := Order{
+		ID:       node.id,
+		UserID:   node.userID,
+		Side:     node.side,
+		Kind:     OrderKindLimit,
+		Price:    newPrice,
+		Size:     newSize,
+		Time:     now,
+		PostOnly: node.postOnly,
 	}
-	return CancelReport{OrderID: id, CanceledSize: node.size}, []Event{ev}, nil
+	if newSize > node.display {
+		o.DisplaySize = node.display
+	}
+	report, more, err := c.submitLimit(o)
+	if err != nil {
+		return AmendReport{}, nil, err
+	}
+	return AmendReport{
+		OrderID:   id,
+		Remaining: report.Remaining,
+		Fills:     report.Fills,
+		Rested:    report.Rested,
+	}, c.afterMatch(append(evs, more...), now), nil
+}
+
+// AmendSize changes only the size of a resting order, keeping its price
+// (see Amend). A smaller size keeps time priority and emits an
+// OrderReducedEvent; a larger one moves the order to the tail of its level,
+// emitting an OrderRemovedEvent (RemoveReasonAmended) and an
+// OrderRestedEvent under the same ID.
+func (c *Core) AmendSize(id OrderID, newSize Size, now int64) (AmendReport, []Event, error) {
+	if id == 0 || now <= 0 || newSize <= 0 {
+		return AmendReport{}, nil, ErrInvalidOrder
+	}
+	node, ok := c.ob.orders[id]
+	if !ok {
+		return AmendReport{}, nil, ErrNotFound
+	}
+	return c.Amend(id, node.price, newSize, now)
+}
+
+// Reduce takes by off a resting order's size in place, keeping its price
+// and time priority (see Amend). Reducing by the whole size or more is
+// ErrInvalidOrder: cancel the order instead.
+func (c *Core) Reduce(id OrderID, by Size, now int64) (AmendReport, []Event, error) {
+	if id == 0 || now <= 0 || by <= 0 {
+		return AmendReport{}, nil, ErrInvalidOrder
+	}
+	node, ok := c.ob.orders[id]
+	if !ok {
+		return AmendReport{}, nil, ErrNotFound
+	}
+	if by >= node.remaining() {
+		return AmendReport{}, nil, ErrInvalidOrder
+	}
+	return c.Amend(id, node.price, node.remaining()-by, now)
 }
 
-// match consumes from opposite book. It mutates resting makers and emits events.
-func (c *Core) match(taker Order, remaining *Size, limitPrice *PriceTicks) ([]Fill, []Event) {
+// match consumes from opposite book. It mutates resting makers and emits
+// events, applying self-trade prevention to makers of the taker's user.
+func (c *Core) match(taker Order, remaining *Size, limitPrice *PriceTicks) ([]Fill, []Event, stpResult) {
 	var (
 		fills  []Fill
 		events []Event
+		stp    stpResult
 	)
 
 	opp := c.ob.asks
@@ -177,11 +511,11 @@ func (c *Core) match(taker Order, remaining *Size, limitPrice *PriceTicks) ([]Fi
 			switch taker.Side {
 			case SideBuy:
 				if best.price > *limitPrice {
-					return fills, events
+					return fills, events, stp
 				}
 			case SideSell:
 				if best.price < *limitPrice {
-					return fills, events
+					return fills, events, stp
 				}
 			}
 		}
@@ -191,7 +525,28 @@ func (c *Core) match(taker Order, remaining *Size, limitPrice *PriceTicks) ([]Fi
 			if maker.size <= 0 {
 				// defensive: purge broken maker
 				best.popHead()
-				delete(c.ob.orders, maker.id)
+				c.ob.forget(maker)
+				continue
+			}
+
+			if c.stp != STPNone && maker.userID == taker.UserID {
+				if c.stp == STPCancelTaker {
+					stp.takerCancel = true
+					return fills, events, stp
+				}
+				best.popHead()
+				best.totalVolume -= maker.size
+				c.ob.forget(maker)
+				stp.canceled = append(stp.canceled, maker.id)
+				events = append(events, OrderRemovedEvent{
+					OrderID:   maker.id,
+					Reason:    RemoveReasonSelfTrade,
+					Remaining: maker.remaining(),
+					Price:     maker.price,
+					Side:      maker.side,
+					UserID:    maker.userID,
+					Time:      taker.Time,
+				})
 				continue
 			}
 
@@ -202,7 +557,7 @@ func (c *Core) match(taker Order, remaining *Size, limitPrice *PriceTicks) ([]Fi
 			if traded <= 0 {
 				// defensive: avoid infinite loop
 				best.popHead()
-				delete(c.ob.orders, maker.id)
+				c.ob.forget(maker)
 				continue
 			}
 
@@ -228,9 +583,12 @@ func (c *Core) match(taker Order, remaining *Size, limitPrice *PriceTicks) ([]Fi
 				MakerUserID:  maker.userID,
 			})
 
-			if maker.isFilled() {
+			if maker.isFilled() && maker.reserve > 0 {
+				c.ob.refresh(maker, taker.Time)
+				events = append(events, refreshedEvent(maker))
+			} else if maker.isFilled() {
 				best.popHead()
-				delete(c.ob.orders, maker.id)
+				c.ob.forget(maker)
 
 				events = append(events, OrderRemovedEvent{
 					OrderID:   maker.id,
@@ -259,5 +617,5 @@ func (c *Core) match(taker Order, remaining *Size, limitPrice *PriceTicks) ([]Fi
 		}
 	}
 
-	return fills, events
+	return fills, events, stp
 }
@@ -174,3 +174,366 @@ func TestValidation(t *testing.T) {
 		})
 	}
 }
+
+// queue returns the order IDs resting at a price, in priority order.
+func queue(c *Core, side Side, price PriceTicks) []OrderID {
+	l, ok := c.ob.sideFor(side).levels[price]
+	if !ok {
+		return nil
+	}
+	var ids []OrderID
+	for o := l.head; o != nil; o = o.next {
+		ids = append(ids, o.id)
+	}
+	return ids
+}
+
+func restOrders(t *testing.T, c *Core, orders ...Order) {
+	t.Helper()
+	for _, o := range orders {
+		if _, _, err := c.SubmitLimit(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+}
+
+func TestAmendSizeDownKeepsPriority(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1000},
+		Order{ID: 2, UserID: 101, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1001},
+	)
+
+	report, events, err := c.Amend(1, 100, 4, 2000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.KeptPriority || report.Remaining != 4 {
+		t.Errorf("expected size-down to keep priority with remaining 4, got %+v", report)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	reduced, ok := events[0].(OrderReducedEvent)
+	if !ok || reduced.Delta != -6 || reduced.Remaining != 4 {
+		t.Errorf("expected OrderReducedEvent with delta -6, got %+v", events[0])
+	}
+
+	if q := queue(c, SideBuy, 100); len(q) != 2 || q[0] != 1 {
+		t.Errorf("expected order 1 to stay at the head, got %v", q)
+	}
+	if vol := c.ob.bids.levels[100].totalVolume; vol != 14 {
+		t.Errorf("expected level volume 14, got %d", vol)
+	}
+}
+
+func TestAmendSizeUpLosesPriority(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1000},
+		Order{ID: 2, UserID: 101, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1001},
+	)
+
+	report, _, err := c.Amend(1, 100, 15, 2000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.KeptPriority {
+		t.Error("expected size-up to lose priority")
+	}
+	if q := queue(c, SideBuy, 100); len(q) != 2 || q[0] != 2 || q[1] != 1 {
+		t.Errorf("expected order 1 to move behind order 2, got %v", q)
+	}
+}
+
+func TestAmendPriceToPassiveRequeues(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1000},
+		Order{ID: 2, UserID: 101, Side: SideBuy, Kind: OrderKindLimit, Price: 99, Size: 10, Time: 1001},
+		Order{ID: 3, UserID: 102, Side: SideSell, Kind: OrderKindLimit, Price: 105, Size: 10, Time: 1002},
+	)
+
+	report, events, err := c.Amend(1, 99, 10, 2000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.KeptPriority || !report.Rested || len(report.Fills) != 0 {
+		t.Errorf("expected passive re-queue without fills, got %+v", report)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected removed + rested events, got %d", len(events))
+	}
+	if _, ok := events[0].(OrderRemovedEvent); !ok {
+		t.Errorf("expected OrderRemovedEvent, got %T", events[0])
+	}
+	if rested, ok := events[1].(OrderRestedEvent); !ok || rested.Price != 99 || rested.Time != 2000 {
+		t.Errorf("expected OrderRestedEvent at 99, got %+v", events[1])
+	}
+
+	if _, ok := c.ob.bids.levels[100]; ok {
+		t.Error("expected old level to be removed")
+	}
+	if q := queue(c, SideBuy, 99); len(q) != 2 || q[0] != 2 || q[1] != 1 {
+		t.Errorf("expected order 1 at the tail of the new level, got %v", q)
+	}
+}
+
+func TestAmendPriceToCrossingExecutes(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1000},
+		Order{ID: 2, UserID: 101, Side: SideSell, Kind: OrderKindLimit, Price: 105, Size: 4, Time: 1001},
+	)
+
+	report, events, err := c.Amend(1, 105, 10, 2000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Fills) != 1 || report.Fills[0].Size != 4 || report.Fills[0].Price != 105 {
+		t.Fatalf("expected 4 filled at 105, got %+v", report.Fills)
+	}
+	if !report.Rested || report.Remaining != 6 {
+		t.Errorf("expected 6 to rest after the cross, got %+v", report)
+	}
+
+	var trades int
+	for _, ev := range events {
+		if tr, ok := ev.(TradeEvent); ok {
+			trades++
+			if tr.TakerOrderID != 1 || tr.MakerOrderID != 2 {
+				t.Errorf("expected amended order to be the taker, got %+v", tr)
+			}
+		}
+	}
+	if trades != 1 {
+		t.Errorf("expected 1 trade event, got %d", trades)
+	}
+	if q := queue(c, SideBuy, 105); len(q) != 1 || q[0] != 1 {
+		t.Errorf("expected remainder to rest at 105, got %v", q)
+	}
+	if _, ok := c.ob.asks.levels[105]; ok {
+		t.Error("expected ask level to be consumed")
+	}
+}
+
+func TestAmendErrors(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c, Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1000})
+
+	if _, _, err := c.Amend(2, 100, 5, 2000); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if _, _, err := c.Amend(1, 100, 0, 2000); err != ErrInvalidOrder {
+		t.Errorf("expected ErrInvalidOrder for zero size, got %v", err)
+	}
+	if _, _, err := c.Amend(1, 0, 5, 2000); err != ErrInvalidOrder {
+		t.Errorf("expected ErrInvalidOrder for zero price, got %v", err)
+	}
+}
+
+func TestAmendSize(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 100, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 10, Time: 1000},
+		Order{ID: 2, UserID: 101, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 10, Time: 1001},
+	)
+
+	report, events, err := c.AmendSize(1, 6, 2000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.KeptPriority || len(events) != 1 || c.ob.asks.levels[101].totalVolume != 16 {
+		t.Errorf("expected an in-place reduce to 6, got %+v and %v", report, events)
+	}
+
+	report, events, err = c.AmendSize(1, 12, 2001)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.KeptPriority || len(events) != 2 {
+		t.Fatalf("expected a re-queue, got %+v and %v", report, events)
+	}
+	if q := queue(c, SideSell, 101); len(q) != 2 || q[1] != 1 {
+		t.Errorf("expected order 1 at the tail, got %v", q)
+	}
+	if node := c.ob.orders[1]; node.price != 101 || node.size != 12 || node.level.totalVolume != 22 {
+		t.Errorf("expected 12 at 101 in a level of 22, got %+v", node)
+	}
+
+	if _, _, err := c.AmendSize(3, 5, 2002); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	for _, size := range []Size{0, -1} {
+		if _, _, err := c.AmendSize(1, size, 2002); err != ErrInvalidOrder {
+			t.Errorf("expected ErrInvalidOrder for size %d, got %v", size, err)
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1000},
+		Order{ID: 2, UserID: 101, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1001},
+	)
+
+	report, events, err := c.Reduce(1, 3, 2000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.KeptPriority || report.Remaining != 7 {
+		t.Errorf("expected an in-place reduce to 7, got %+v", report)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if reduced, ok := events[0].(OrderReducedEvent); !ok || reduced.Delta != -3 || reduced.Remaining != 7 {
+		t.Errorf("expected OrderReducedEvent with delta -3, got %+v", events[0])
+	}
+	if q := queue(c, SideBuy, 100); len(q) != 2 || q[0] != 1 {
+		t.Errorf("expected order 1 to stay at the head, got %v", q)
+	}
+
+	// Reducing by the whole size or more is a cancel, not a reduce
+	for _, by := range []Size{7, 8, 0, -1} {
+		if _, _, err := c.Reduce(1, by, 2001); err != ErrInvalidOrder {
+			t.Errorf("expected ErrInvalidOrder reducing by %d, got %v", by, err)
+		}
+	}
+	if node := c.ob.orders[1]; node.size != 7 || node.level.totalVolume != 17 {
+		t.Errorf("expected rejected reduces to leave 7 in a level of 17, got %+v", node)
+	}
+	if _, _, err := c.Reduce(3, 1, 2001); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCancelAll(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 99, Size: 10, Time: 1},
+		Order{ID: 2, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 5, Time: 2},
+		Order{ID: 3, UserID: 3, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 7, Time: 3},
+		Order{ID: 4, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 3, Time: 4},
+	)
+
+	reports, events := c.CancelAll(10)
+	var ids []OrderID
+	for _, r := range reports {
+		ids = append(ids, r.OrderID)
+	}
+	// Bids best level first in queue order, then asks
+	want := []OrderID{2, 4, 1, 3}
+	if len(ids) != len(want) {
+		t.Fatalf("expected cancels %v, got %v", want, ids)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Fatalf("expected cancels %v, got %v", want, ids)
+		}
+	}
+	for _, ev := range events {
+		if rm, ok := ev.(OrderRemovedEvent); !ok || rm.Reason != RemoveReasonCanceled || rm.Time != 10 {
+			t.Errorf("unexpected event: %+v", ev)
+		}
+	}
+	if len(c.ob.orders) != 0 || c.ob.bids.bestLevel() != nil || c.ob.asks.bestLevel() != nil {
+		t.Error("expected empty book after CancelAll")
+	}
+	if reports, events := c.CancelAll(11); len(reports) != 0 || len(events) != 0 {
+		t.Error("expected CancelAll on an empty book to do nothing")
+	}
+}
+
+func TestCancelUser(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 99, Size: 10, Time: 1},
+		Order{ID: 2, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 5, Time: 2},
+		Order{ID: 3, UserID: 1, Side: SideSell, Kind: OrderKindLimit, Price: 102, Size: 7, Time: 3},
+		Order{ID: 4, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 3, Time: 4},
+		Order{ID: 5, UserID: 2, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 2, Time: 5},
+		Order{ID: 6, UserID: 1, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 1, Time: 6},
+	)
+
+	reports, events := c.CancelUser(1, 10)
+	// Bids best first, then asks best first, by time within a price
+	want := []OrderID{4, 1, 6, 3}
+	if len(reports) != len(want) || len(events) != len(want) {
+		t.Fatalf("expected %d cancels, got %v and %v", len(want), reports, events)
+	}
+	for i, id := range want {
+		if reports[i].OrderID != id {
+			t.Errorf("cancel %d: expected order %d, got %+v", i, id, reports[i])
+		}
+		rm, ok := events[i].(OrderRemovedEvent)
+		if !ok || rm.OrderID != id || rm.Reason != RemoveReasonCanceled || rm.UserID != 1 || rm.Time != 10 {
+			t.Errorf("event %d: expected order %d canceled, got %+v", i, id, events[i])
+		}
+	}
+
+	// User 2's orders are untouched
+	if len(c.ob.orders) != 2 || c.ob.bids.levels[100].totalVolume != 5 || c.ob.asks.levels[101].totalVolume != 2 {
+		t.Errorf("expected only user 2's orders left, got %d orders", len(c.ob.orders))
+	}
+	if _, ok := c.ob.bids.levels[99]; ok {
+		t.Error("expected the emptied 99 level to be removed")
+	}
+	if _, ok := c.ob.byUser[1]; ok {
+		t.Error("expected user 1 to leave the order index")
+	}
+	if reports, events := c.CancelUser(1, 11); len(reports) != 0 || len(events) != 0 {
+		t.Error("expected CancelUser with no orders to do nothing")
+	}
+}
+
+func TestUserIndexFollowsFills(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 1, Side: SideSell, Kind: OrderKindLimit, Price: 100, Size: 5, Time: 1},
+		Order{ID: 2, UserID: 1, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 5, Time: 2},
+	)
+	if _, _, err := c.SubmitMarket(Order{ID: 3, UserID: 2, Side: SideBuy, Kind: OrderKindMarket, Size: 7, Time: 3}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mine := c.ob.byUser[1]; len(mine) != 1 || mine[2] == nil || mine[2].size != 3 {
+		t.Errorf("expected only order 2 indexed with 3 left, got %v", mine)
+	}
+	if _, _, err := c.SubmitMarket(Order{ID: 4, UserID: 2, Side: SideBuy, Kind: OrderKindMarket, Size: 3, Time: 4}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(c.ob.byUser) != 0 {
+		t.Errorf("expected filled orders to leave the index, got %v", c.ob.byUser)
+	}
+}
+
+func TestAmendRemoveReasonDiffersFromCancel(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1000},
+		Order{ID: 2, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1001},
+	)
+
+	_, events, err := c.Cancel(1, 2000)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rm, ok := events[0].(OrderRemovedEvent); !ok || rm.Reason != RemoveReasonCanceled {
+		t.Errorf("expected cancel to remove with CANCELED, got %+v", events[0])
+	}
+
+	_, events, err = c.Amend(2, 101, 10, 2001)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected removed + rested events, got %d", len(events))
+	}
+	if rm, ok := events[0].(OrderRemovedEvent); !ok || rm.Reason != RemoveReasonAmended || rm.OrderID != 2 {
+		t.Errorf("expected amend to remove with AMENDED, got %+v", events[0])
+	}
+	if rested, ok := events[1].(OrderRestedEvent); !ok || rested.OrderID != 2 || rested.Price != 101 {
+		t.Errorf("expected the amended order to rest at 101, got %+v", events[1])
+	}
+}
@@ -0,0 +1,133 @@
+package core
+
+// CrossPolicy selects how ResolveCross repairs a crossed book.
+type CrossPolicy uint8
+
+const (
+	// CrossPolicyContinuous matches the standing orders pairwise as normal
+	// matching would have: the earlier order of each pair is the maker and
+	// the trade prints at its price.
+	CrossPolicyContinuous CrossPolicy = iota
+	// CrossPolicyAuction executes the crossed part of the book at a single
+	// equilibrium price, as Uncross does.
+	CrossPolicyAuction
+)
+
+func (p CrossPolicy) String() string {
+	switch p {
+	case CrossPolicyContinuous:
+		return "CONTINUOUS"
+	case CrossPolicyAuction:
+		return "AUCTION"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// CrossReport summarizes a ResolveCross run. It is zero if the book was not
+// crossed.
+type CrossReport struct {
+	Policy CrossPolicy
+	Trades int
+	Volume Size
+}
+
+// Crossed reports whether the best bid is at or above the best ask.
+func (c *Core) Crossed() bool {
+	bl, al := c.ob.bids.bestLevel(), c.ob.asks.bestLevel()
+	return bl != nil && al != nil && bl.price >= al.price
+}
+
+// ResolveCross repairs a crossed book left by paths that rest orders without
+// matching (auction release, Restore). It trades the crossed orders under the
+// given policy and ends with a CrossResolvedEvent summarizing them. It does
+// nothing during an auction, where crossing is expected, or if the book is
+// not crossed.
+func (c *Core) ResolveCross(policy CrossPolicy, now int64) (CrossReport, []Event) {
+	if c.auction || !c.Crossed() {
+		return CrossReport{}, nil
+	}
+
+	report := CrossReport{Policy: policy}
+	var events []Event
+	switch policy {
+	case CrossPolicyAuction:
+		price, volume := c.equilibrium()
+		uncross, evs := c.executeAt(price, volume, now)
+		report.Trades, report.Volume = uncross.Trades, uncross.Volume
+		events = evs
+	default:
+		report.Policy = CrossPolicyContinuous
+		for c.Crossed() {
+			bid, ask := c.ob.bids.bestLevel().head, c.ob.asks.bestLevel().head
+			taker, maker := laterOf(bid, ask)
+			traded := min(bid.size, ask.size)
+
+			events = append(events, TradeEvent{
+				Price:        maker.price,
+				Size:         traded,
+				TakerSide:    taker.side,
+				Time:         now,
+				TakerOrderID: taker.id,
+				TakerUserID:  taker.userID,
+				MakerOrderID: maker.id,
+				MakerUserID:  maker.userID,
+			})
+			report.Volume += traded
+			report.Trades++
+
+			events = c.fillHead(c.ob.bids, bid, traded, now, events)
+			events = c.fillHead(c.ob.asks, ask, traded, now, events)
+		}
+	}
+
+	events = append(events, CrossResolvedEvent{
+		Policy: report.Policy,
+		Trades: report.Trades,
+		Volume: report.Volume,
+		Time:   now,
+	})
+	return report, c.afterMatch(events, now)
+}
+
+// laterOf returns the later of two resting orders as the taker and the
+// earlier as the maker. Ties on time go to the higher ID as taker.
+func laterOf(bid, ask *restingOrder) (taker, maker *restingOrder) {
+	if ask.time > bid.time || (ask.time == bid.time && ask.id > bid.id) {
+		return ask, bid
+	}
+	return bid, ask
+}
+
+// Restore rests orders without matching, as when reloading a journal or
+// snapshot. Orders rest in slice order at their own Time, so the book may be
+// left crossed; call ResolveCross afterwards. All orders are validated first
+// and nothing is restored if any is invalid, not GTC, or duplicates an ID.
+func (c *Core) Restore(orders []Order) ([]Event, error) {
+	seen := make(map[OrderID]struct{}, len(orders))
+	for _, o := range orders {
+		if err := validateLimit(o); err != nil {
+			return nil, err
+		}
+		if o.TIF != TIFGTC {
+			return nil, ErrInvalidOrder
+		}
+		if c.idTaken(o.ID) {
+			return nil, ErrDuplicateID
+		}
+		if _, dup := seen[o.ID]; dup {
+			return nil, ErrDuplicateID
+		}
+		seen[o.ID] = struct{}{}
+	}
+
+	events := make([]Event, 0, len(orders))
+	for _, o := range orders {
+		node := c.ob.addResting(o)
+		events = append(events, OrderRestedEvent{
+			OrderID: o.ID, UserID: o.UserID, Side: o.Side,
+			Price: o.Price, Size: node.size, Time: o.Time,
+		})
+	}
+	return events, nil
+}
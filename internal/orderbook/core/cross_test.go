@@ -0,0 +1,138 @@
+package core
+
+import "testing"
+
+// crossedOrders is a journal whose bids and asks overlap between 99 and 102.
+func crossedOrders() []Order {
+	return []Order{
+		{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 102, Size: 5, Time: 1000},
+		{ID: 2, UserID: 101, Side: SideSell, Kind: OrderKindLimit, Price: 99, Size: 4, Time: 1001},
+		{ID: 3, UserID: 102, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 6, Time: 1002},
+		{ID: 4, UserID: 103, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 8, Time: 1003},
+		{ID: 5, UserID: 104, Side: SideBuy, Kind: OrderKindLimit, Price: 98, Size: 3, Time: 1004},
+		{ID: 6, UserID: 105, Side: SideSell, Kind: OrderKindLimit, Price: 103, Size: 2, Time: 1005},
+	}
+}
+
+func restingSize(c *Core) Size {
+	var total Size
+	for _, o := range c.ob.orders {
+		total += o.size
+	}
+	return total
+}
+
+func TestResolveCrossPolicies(t *testing.T) {
+	limits := map[OrderID]Order{}
+	for _, o := range crossedOrders() {
+		limits[o.ID] = o
+	}
+
+	for _, policy := range []CrossPolicy{CrossPolicyContinuous, CrossPolicyAuction} {
+		t.Run(policy.String(), func(t *testing.T) {
+			c := NewCore()
+			if _, err := c.Restore(crossedOrders()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !c.Crossed() {
+				t.Fatal("expected the restored book to be crossed")
+			}
+			before := restingSize(c)
+
+			report, events := c.ResolveCross(policy, 2000)
+			if c.Crossed() {
+				t.Fatal("expected an uncrossed book")
+			}
+			if report.Policy != policy || report.Trades == 0 {
+				t.Errorf("unexpected report %+v", report)
+			}
+			// Each traded unit removes one unit from each side
+			if after := restingSize(c); after != before-2*report.Volume {
+				t.Errorf("volume not conserved: %d resting before, %d after, %d traded", before, after, report.Volume)
+			}
+
+			var volume Size
+			var trades int
+			for _, ev := range events {
+				tr, ok := ev.(TradeEvent)
+				if !ok {
+					continue
+				}
+				trades++
+				volume += tr.Size
+				// Every trade respects both orders' limits
+				for _, id := range []OrderID{tr.TakerOrderID, tr.MakerOrderID} {
+					o := limits[id]
+					if (o.Side == SideBuy && tr.Price > o.Price) || (o.Side == SideSell && tr.Price < o.Price) {
+						t.Errorf("trade %+v violates the limit of order %d", tr, id)
+					}
+				}
+				if policy == CrossPolicyContinuous && tr.Price != limits[tr.MakerOrderID].Price {
+					t.Errorf("continuous trade should print at the maker's price: %+v", tr)
+				}
+			}
+			if trades != report.Trades || volume != report.Volume {
+				t.Errorf("report %+v does not match %d trades of %d", report, trades, volume)
+			}
+
+			last, ok := events[len(events)-1].(CrossResolvedEvent)
+			if !ok {
+				t.Fatalf("expected CrossResolvedEvent last, got %T", events[len(events)-1])
+			}
+			if last.Policy != policy || last.Trades != report.Trades || last.Volume != report.Volume || last.Time != 2000 {
+				t.Errorf("unexpected summary %+v for report %+v", last, report)
+			}
+		})
+	}
+}
+
+func TestResolveCrossContinuousUsesTimePriority(t *testing.T) {
+	c := NewCore()
+	if _, err := c.Restore(crossedOrders()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, events := c.ResolveCross(CrossPolicyContinuous, 2000)
+
+	// Bid 1 (102, t=1000) rested before ask 2 (99, t=1001): bid 1 is the maker.
+	first, ok := events[0].(TradeEvent)
+	if !ok || first.MakerOrderID != 1 || first.TakerOrderID != 2 || first.Price != 102 || first.Size != 4 || first.TakerSide != SideSell {
+		t.Errorf("unexpected first trade %+v", events[0])
+	}
+}
+
+func TestResolveCrossNoop(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c,
+		Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 99, Size: 5, Time: 1000},
+		Order{ID: 2, UserID: 101, Side: SideSell, Kind: OrderKindLimit, Price: 100, Size: 5, Time: 1001},
+	)
+	if report, events := c.ResolveCross(CrossPolicyContinuous, 2000); report != (CrossReport{}) || events != nil {
+		t.Errorf("expected no-op on an uncrossed book, got %+v %v", report, events)
+	}
+
+	c.BeginAuction()
+	restOrders(t, c, Order{ID: 3, UserID: 102, Side: SideBuy, Kind: OrderKindLimit, Price: 101, Size: 5, Time: 1002})
+	if _, events := c.ResolveCross(CrossPolicyContinuous, 2000); events != nil || !c.Crossed() {
+		t.Error("expected no-op during an auction")
+	}
+}
+
+func TestRestoreValidation(t *testing.T) {
+	c := NewCore()
+	restOrders(t, c, Order{ID: 1, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 99, Size: 5, Time: 1000})
+
+	if _, err := c.Restore([]Order{
+		{ID: 2, UserID: 100, Side: SideBuy, Kind: OrderKindLimit, Price: 98, Size: 5, Time: 1000},
+		{ID: 1, UserID: 100, Side: SideSell, Kind: OrderKindLimit, Price: 98, Size: 5, Time: 1000},
+	}); err != ErrDuplicateID {
+		t.Errorf("expected ErrDuplicateID, got %v", err)
+	}
+	if _, err := c.Restore([]Order{
+		{ID: 3, UserID: 100, Side: SideBuy, Kind: OrderKindMarket, Size: 5, Time: 1000},
+	}); err != ErrInvalidOrder {
+		t.Errorf("expected ErrInvalidOrder, got %v", err)
+	}
+	if len(c.ob.orders) != 1 {
+		t.Errorf("expected a failed restore to add nothing, got %d orders", len(c.ob.orders))
+	}
+}
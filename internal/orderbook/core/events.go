@@ -11,6 +11,12 @@ type RemoveReason uint8
 const (
 	RemoveReasonFilled RemoveReason = iota
 	RemoveReasonCanceled
+	// RemoveReasonAmended marks the remove half of an amend that loses
+	// priority; an OrderRestedEvent or fills for the same order follow.
+	RemoveReasonAmended
+	// RemoveReasonSelfTrade marks a resting order canceled by self-trade
+	// prevention (STPCancelResting).
+	RemoveReasonSelfTrade
 )
 
 func (r RemoveReason) String() string {
@@ -19,6 +25,10 @@ func (r RemoveReason) String() string {
 		return "FILLED"
 	case RemoveReasonCanceled:
 		return "CANCELED"
+	case RemoveReasonAmended:
+		return "AMENDED"
+	case RemoveReasonSelfTrade:
+		return "SELF_TRADE"
 	default:
 		return "UNKNOWN"
 	}
@@ -76,3 +86,84 @@ type OrderRemovedEvent struct {
 }
 
 func (OrderRemovedEvent) isEvent() {}
+
+// OrderRefreshedEvent is emitted when an iceberg's visible slice fills and
+// a new one is shown from its reserve, after the trade that filled it. The
+// order keeps its ID and moves to the tail of its level at Time.
+type OrderRefreshedEvent struct {
+	OrderID OrderID
+	UserID  UserID
+	Side    Side
+	Price   PriceTicks
+	Size    Size // the new visible slice
+	Hidden  Size // reserve left behind it
+	Time    int64
+}
+
+func (OrderRefreshedEvent) isEvent() {}
+
+// CrossResolvedEvent is emitted after ResolveCross repairs a crossed book,
+// following the trades it generated.
+type CrossResolvedEvent struct {
+	Policy CrossPolicy
+	Trades int
+	Volume Size
+	Time   int64
+}
+
+func (CrossResolvedEvent) isEvent() {}
+
+// StopAcceptedEvent is emitted when a stop order is held off the book until
+// a trade reaches its trigger.
+type StopAcceptedEvent struct {
+	StopID       OrderID
+	UserID       UserID
+	Side         Side
+	TriggerPrice PriceTicks
+	LimitPrice   PriceTicks // 0 for a stop-market order
+	Size         Size
+	ExpireAt     int64 // 0 if it never expires
+	Time         int64
+}
+
+func (StopAcceptedEvent) isEvent() {}
+
+// StopTriggeredEvent is emitted when a trade reaches a stop's trigger. The
+// events of the order it becomes, which keeps the stop's ID, follow.
+type StopTriggeredEvent struct {
+	StopID       OrderID
+	UserID       UserID
+	Side         Side
+	TriggerPrice PriceTicks
+	TradePrice   PriceTicks
+	Time         int64
+}
+
+func (StopTriggeredEvent) isEvent() {}
+
+// StopExpiredEvent is emitted when a stop reaches its ExpireAt without
+// triggering and leaves without an order.
+type StopExpiredEvent struct {
+	StopID       OrderID
+	UserID       UserID
+	Side         Side
+	TriggerPrice PriceTicks
+	Size         Size
+	ExpireAt     int64
+	Time         int64
+}
+
+func (StopExpiredEvent) isEvent() {}
+
+// StopCanceledEvent is emitted when a stop leaves without an order: canceled
+// before it triggered, or refused by the book when it did.
+type StopCanceledEvent struct {
+	StopID       OrderID
+	UserID       UserID
+	Side         Side
+	TriggerPrice PriceTicks
+	Size         Size
+	Time         int64
+}
+
+func (StopCanceledEvent) isEvent() {}
@@ -0,0 +1,26 @@
+package core
+
+// refresh replenishes a filled iceberg's visible slice from its reserve
+// and moves it to the tail of its level, where it loses time priority.
+func (ob *orderBook) refresh(node *restingOrder, now int64) {
+	l := node.level
+	l.unlink(node)
+	slice := min(node.display, node.reserve)
+	node.reserve -= slice
+	node.size = slice
+	node.time = now
+	l.append(node)
+	l.totalVolume += slice
+}
+
+func refreshedEvent(node *restingOrder) OrderRefreshedEvent {
+	return OrderRefreshedEvent{
+		OrderID: node.id,
+		UserID:  node.userID,
+		Side:    node.side,
+		Price:   node.price,
+		Size:    node.size,
+		Hidden:  node.reserve,
+		Time:    node.time,
+	}
+}
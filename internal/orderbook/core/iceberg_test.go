@@ -0,0 +1,143 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func iceberg(id OrderID, side Side, size, display Size, time int64) Order {
+	return Order{ID: id, UserID: 1, Side: side, Kind: OrderKindLimit, Price: 100, Size: size, Time: time, DisplaySize: display}
+}
+
+func mustRest(t *testing.T, c *Core, o Order) {
+	t.Helper()
+	if _, _, err := c.SubmitLimit(o); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestIcebergFillsAcrossRefreshes(t *testing.T) {
+	c := NewCore()
+	report, events, err := c.SubmitLimit(iceberg(1, SideSell, 10, 2, 1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Rested || report.RestedSize != 10 {
+		t.Errorf("expected all 10 to rest, got %+v", report)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %v", events)
+	}
+	if ev, ok := events[0].(OrderRestedEvent); !ok || ev.Size != 2 {
+		t.Errorf("expected only the 2 on display to rest visibly, got %+v", events[0])
+	}
+	if l := c.ob.asks.levels[100]; l == nil || l.totalVolume != 2 {
+		t.Fatalf("expected 2 showing at 100, got %+v", l)
+	}
+
+	report, events, err = c.SubmitLimit(Order{ID: 2, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Remaining != 0 || len(report.Fills) != 5 {
+		t.Fatalf("expected 10 filled in five slices, got %+v", report)
+	}
+	for _, f := range report.Fills {
+		if f.MakerOrderID != 1 || f.Size != 2 || f.Price != 100 {
+			t.Errorf("expected 2 @ 100 from the iceberg, got %+v", f)
+		}
+	}
+	var refreshes int
+	hidden := Size(8)
+	for _, ev := range events {
+		if e, ok := ev.(OrderRefreshedEvent); ok {
+			hidden -= 2
+			if e.OrderID != 1 || e.Size != 2 || e.Hidden != hidden || e.Time != 5 {
+				t.Errorf("expected 2 shown with %d hidden, got %+v", hidden, e)
+			}
+			refreshes++
+		}
+	}
+	if refreshes != 4 {
+		t.Errorf("expected 4 refreshes, got %d", refreshes)
+	}
+	if e, ok := events[len(events)-1].(OrderRemovedEvent); !ok || e.OrderID != 1 || e.Reason != RemoveReasonFilled {
+		t.Errorf("expected the iceberg removed as filled last, got %+v", events[len(events)-1])
+	}
+	if len(c.ob.asks.levels) != 0 || len(c.ob.orders) != 0 {
+		t.Errorf("expected the book empty, got %d levels and %d orders", len(c.ob.asks.levels), len(c.ob.orders))
+	}
+}
+
+func TestIcebergRefreshLosesPriority(t *testing.T) {
+	c := NewCore()
+	mustRest(t, c, iceberg(1, SideSell, 10, 2, 1))
+	mustRest(t, c, Order{ID: 2, UserID: 2, Side: SideSell, Kind: OrderKindLimit, Price: 100, Size: 3, Time: 2})
+
+	// The first slice fills; the next one queues behind order 2
+	report, _, _ := c.SubmitLimit(Order{ID: 3, UserID: 3, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 4, Time: 5})
+	if len(report.Fills) != 2 || report.Fills[0].MakerOrderID != 1 || report.Fills[1].MakerOrderID != 2 || report.Fills[1].Size != 2 {
+		t.Fatalf("expected 2 from the iceberg then 2 from order 2, got %+v", report.Fills)
+	}
+	l := c.ob.asks.levels[100]
+	if l.head.id != 2 || l.tail.id != 1 || l.totalVolume != 3 {
+		t.Errorf("expected order 2 ahead of the refreshed iceberg with 3 showing, got head %d tail %d total %d", l.head.id, l.tail.id, l.totalVolume)
+	}
+}
+
+func TestIcebergCancelAndAmend(t *testing.T) {
+	c := NewCore()
+	mustRest(t, c, iceberg(1, SideBuy, 10, 2, 1))
+
+	// A size-down comes out of the reserve first, unseen
+	report, events, err := c.AmendSize(1, 7, 2)
+	if err != nil || report.Remaining != 7 || !report.KeptPriority || len(events) != 0 {
+		t.Fatalf("expected 7 left with no events, got %+v %v (%v)", report, events, err)
+	}
+	// then out of the slice on display
+	_, events, _ = c.Reduce(1, 6, 3)
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %v", events)
+	}
+	if e, ok := events[0].(OrderReducedEvent); !ok || e.Delta != -1 || e.Remaining != 1 {
+		t.Errorf("expected the slice reduced by 1 to 1, got %+v", events[0])
+	}
+
+	mustRest(t, c, iceberg(2, SideBuy, 10, 2, 4))
+	cancel, events, err := c.Cancel(2, 5)
+	if err != nil || cancel.CanceledSize != 10 {
+		t.Errorf("expected the whole 10 canceled, got %+v (%v)", cancel, err)
+	}
+	if e, ok := events[0].(OrderRemovedEvent); !ok || e.Remaining != 10 {
+		t.Errorf("expected 10 removed, got %+v", events[0])
+	}
+	if l := c.ob.bids.levels[100]; l.totalVolume != 1 {
+		t.Errorf("expected 1 showing at 100, got %d", l.totalVolume)
+	}
+}
+
+func TestIcebergFOKCountsReserve(t *testing.T) {
+	c := NewCore()
+	mustRest(t, c, iceberg(1, SideSell, 10, 2, 1))
+	report, _, err := c.SubmitLimit(Order{ID: 2, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 9, Time: 2, TIF: TIFFOK})
+	if err != nil || report.Remaining != 0 {
+		t.Errorf("expected the FOK to fill 9 out of the reserve, got %+v (%v)", report, err)
+	}
+}
+
+func TestIcebergRejections(t *testing.T) {
+	c := NewCore()
+	for _, o := range []Order{
+		iceberg(1, SideBuy, 10, -1, 1),
+		iceberg(2, SideBuy, 10, 10, 1),
+		{ID: 3, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 10, Time: 1, DisplaySize: 2, TIF: TIFIOC},
+	} {
+		if _, _, err := c.SubmitLimit(o); !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("expected ErrInvalidOrder for %+v, got %v", o, err)
+		}
+	}
+	market := Order{ID: 4, UserID: 1, Side: SideBuy, Kind: OrderKindMarket, Size: 10, Time: 1, DisplaySize: 2}
+	if _, _, err := c.SubmitMarket(market); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder for an iceberg market order, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package core
+
+import "testing"
+
+// TestPartialFillReports covers how Remaining, RestedSize and Rested relate
+// for each way a submit can end.
+func TestPartialFillReports(t *testing.T) {
+	tests := []struct {
+		name       string
+		taker      Order
+		wantFilled Size
+		wantRemain Size
+		wantRested Size
+	}{
+		{
+			name:       "limit partially fills and rests",
+			taker:      Order{ID: 10, UserID: 200, Side: SideBuy, Kind: OrderKindLimit, Price: 101, Size: 100, Time: 2000},
+			wantFilled: 60,
+			wantRemain: 40,
+			wantRested: 40,
+		},
+		{
+			name:       "limit fully fills",
+			taker:      Order{ID: 10, UserID: 200, Side: SideBuy, Kind: OrderKindLimit, Price: 101, Size: 50, Time: 2000},
+			wantFilled: 50,
+		},
+		{
+			name:       "limit fills up to its price only",
+			taker:      Order{ID: 10, UserID: 200, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 100, Time: 2000},
+			wantFilled: 20,
+			wantRemain: 80,
+			wantRested: 80,
+		},
+		{
+			name:       "market partially fills and dies",
+			taker:      Order{ID: 10, UserID: 200, Side: SideBuy, Kind: OrderKindMarket, Size: 100, Time: 2000},
+			wantFilled: 60,
+			wantRemain: 40,
+		},
+		{
+			name:       "market fully fills",
+			taker:      Order{ID: 10, UserID: 200, Side: SideBuy, Kind: OrderKindMarket, Size: 30, Time: 2000},
+			wantFilled: 30,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCore()
+			// 60 offered across two levels
+			for _, o := range []Order{
+				{ID: 1, UserID: 100, Side: SideSell, Kind: OrderKindLimit, Price: 100, Size: 20, Time: 1000},
+				{ID: 2, UserID: 100, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 40, Time: 1001},
+			} {
+				if _, _, err := c.SubmitLimit(o); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			var (
+				report SubmitReport
+				err    error
+			)
+			if tt.taker.Kind == OrderKindLimit {
+				report, _, err = c.SubmitLimit(tt.taker)
+			} else {
+				report, _, err = c.SubmitMarket(tt.taker)
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var filled Size
+			for _, f := range report.Fills {
+				filled += f.Size
+			}
+			if filled != tt.wantFilled {
+				t.Errorf("expected filled %d, got %d", tt.wantFilled, filled)
+			}
+			if report.Remaining != tt.wantRemain {
+				t.Errorf("expected remaining %d, got %d", tt.wantRemain, report.Remaining)
+			}
+			if report.RestedSize != tt.wantRested {
+				t.Errorf("expected rested size %d, got %d", tt.wantRested, report.RestedSize)
+			}
+			if report.Rested != (tt.wantRested > 0) {
+				t.Errorf("expected Rested=%v, got %v", tt.wantRested > 0, report.Rested)
+			}
+			if report.FullyUnfilled {
+				t.Errorf("expected FullyUnfilled unset after filling %d, got %+v", filled, report)
+			}
+			if filled+report.Remaining != tt.taker.Size {
+				t.Errorf("filled %d + remaining %d != order size %d", filled, report.Remaining, tt.taker.Size)
+			}
+
+			node, resting := c.ob.orders[tt.taker.ID]
+			if resting != (tt.wantRested > 0) {
+				t.Fatalf("expected resting=%v in book, got %v", tt.wantRested > 0, resting)
+			}
+			if resting && node.size != tt.wantRested {
+				t.Errorf("expected %d resting in book, got %d", tt.wantRested, node.size)
+			}
+		})
+	}
+}
+
+// TestMarketOrderNoLiquidity covers the FullyUnfilled signal: set when a
+// market order finds nothing to trade, unset once any of it fills.
+func TestMarketOrderNoLiquidity(t *testing.T) {
+	c := NewCore()
+	report, events, err := c.SubmitMarket(Order{ID: 1, UserID: 200, Side: SideBuy, Kind: OrderKindMarket, Size: 10, Time: 1000})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.FullyUnfilled || report.Remaining != 10 || len(events) != 0 {
+		t.Errorf("expected an empty book to leave it fully unfilled with no events, got %+v and %v", report, events)
+	}
+
+	if _, _, err := c.SubmitLimit(Order{ID: 2, UserID: 100, Side: SideSell, Kind: OrderKindLimit, Price: 100, Size: 6, Time: 1001}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	report, _, err = c.SubmitMarket(Order{ID: 3, UserID: 200, Side: SideBuy, Kind: OrderKindMarket, Size: 10, Time: 1002})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.FullyUnfilled || report.Remaining != 4 || len(report.Fills) != 1 {
+		t.Errorf("expected 6 filled and 4 left over, got %+v", report)
+	}
+
+	// The fill exhausted the offer, so the next one finds nothing
+	report, _, err = c.SubmitMarket(Order{ID: 4, UserID: 200, Side: SideBuy, Kind: OrderKindMarket, Size: 1, Time: 1003})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.FullyUnfilled || report.Remaining != 1 {
+		t.Errorf("expected the exhausted side to leave it fully unfilled, got %+v", report)
+	}
+
+	// A resting limit order is not unfilled
+	report, _, err = c.SubmitLimit(Order{ID: 5, UserID: 200, Side: SideBuy, Kind: OrderKindLimit, Price: 99, Size: 1, Time: 1004})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.FullyUnfilled {
+		t.Errorf("expected a rested order not to be fully unfilled, got %+v", report)
+	}
+}
@@ -0,0 +1,15 @@
+package core
+
+// wouldCross reports whether a limit order on side at price would meet the
+// opposite best price, i.e. take liquidity if submitted. It is checked while
+// the book is untouched, so a rejected post-only order changes nothing.
+func (c *Core) wouldCross(side Side, price PriceTicks) bool {
+	best := c.ob.sideFor(side.Opposite()).bestLevel()
+	if best == nil {
+		return false
+	}
+	if side == SideBuy {
+		return price >= best.price
+	}
+	return price <= best.price
+}
@@ -0,0 +1,74 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPostOnlyRejectsOnCross(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		price PriceTicks
+	}{
+		{"at best ask", 101},
+		{"through best ask", 103},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tifBook(t)
+			report, events, err := c.SubmitLimit(Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: tt.price, Size: 4, Time: 10, PostOnly: true})
+			if !errors.Is(err, ErrWouldCross) {
+				t.Fatalf("expected ErrWouldCross, got %v", err)
+			}
+			if len(events) != 0 || len(report.Fills) != 0 {
+				t.Errorf("expected no events or fills, got %+v and %v", report, events)
+			}
+			if askVolume(c) != 12 || len(c.ob.bids.levels) != 0 {
+				t.Errorf("expected the book untouched, got %d offered and %d bid levels", askVolume(c), len(c.ob.bids.levels))
+			}
+			if _, ok := c.ob.orders[10]; ok {
+				t.Error("expected the order not to rest")
+			}
+		})
+	}
+}
+
+func TestPostOnlyRestsBelowAsk(t *testing.T) {
+	c := tifBook(t)
+	report, events, err := c.SubmitLimit(Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 4, Time: 10, PostOnly: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Rested || report.RestedSize != 4 || len(report.Fills) != 0 {
+		t.Errorf("expected 4 to rest unfilled, got %+v", report)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected one event, got %v", events)
+	}
+	if ev, ok := events[0].(OrderRestedEvent); !ok || ev.OrderID != 10 || ev.Price != 100 || ev.Size != 4 {
+		t.Errorf("expected order 10 rested 4 @ 100, got %+v", events[0])
+	}
+
+	// Amending it up to the ask is rejected and leaves it resting
+	if _, _, err := c.Amend(10, 101, 4, 11); !errors.Is(err, ErrWouldCross) {
+		t.Errorf("expected ErrWouldCross amending to the ask, got %v", err)
+	}
+	if node, ok := c.ob.orders[10]; !ok || node.price != 100 || node.time != 10 {
+		t.Errorf("expected order 10 untouched at 100, got %+v", node)
+	}
+}
+
+func TestPostOnlyRejections(t *testing.T) {
+	c := NewCore()
+	for _, o := range []Order{
+		{ID: 1, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 1, Time: 1, PostOnly: true, TIF: TIFIOC},
+		{ID: 2, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 1, Time: 1, PostOnly: true, TIF: TIFFOK},
+	} {
+		if _, _, err := c.SubmitLimit(o); !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("expected ErrInvalidOrder for post-only %s, got %v", o.TIF, err)
+		}
+	}
+	market := Order{ID: 3, UserID: 1, Side: SideBuy, Kind: OrderKindMarket, Size: 1, Time: 1, PostOnly: true}
+	if _, _, err := c.SubmitMarket(market); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder for a post-only market order, got %v", err)
+	}
+}
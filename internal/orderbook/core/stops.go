@@ -0,0 +1,302 @@
+package core
+
+import (
+	"slices"
+	"sort"
+)
+
+// pendingStop is a stop parked off the book until a trade reaches its
+// trigger.
+type pendingStop struct {
+	id      OrderID
+	userID  UserID
+	side    Side
+	trigger PriceTicks
+	limit   PriceTicks // 0 = stop-market
+	size    Size
+	expire  int64 // 0 = never
+}
+
+func (st pendingStop) expired(now int64) bool {
+	return st.expire != 0 && now >= st.expire
+}
+
+// stopBook holds the untriggered stops, each side in the order a move
+// through the triggers reaches them, ties in arrival order.
+type stopBook struct {
+	buys  []pendingStop // trigger ascending: buy stops fire as price rises
+	sells []pendingStop // trigger descending: sell stops fire as price falls
+	ids   map[OrderID]Side
+
+	// nextExpiry is no later than the earliest expiry pending, 0 if no stop
+	// expires.
+	nextExpiry int64
+}
+
+func newStopBook() stopBook {
+	return stopBook{ids: map[OrderID]Side{}}
+}
+
+func (b *stopBook) has(id OrderID) bool {
+	_, ok := b.ids[id]
+	return ok
+}
+
+func (b *stopBook) add(st pendingStop) {
+	b.ids[st.id] = st.side
+	if st.expire != 0 && (b.nextExpiry == 0 || st.expire < b.nextExpiry) {
+		b.nextExpiry = st.expire
+	}
+	if st.side == SideBuy {
+		i := sort.Search(len(b.buys), func(i int) bool { return b.buys[i].trigger > st.trigger })
+		b.buys = slices.Insert(b.buys, i, st)
+		return
+	}
+	i := sort.Search(len(b.sells), func(i int) bool { return b.sells[i].trigger < st.trigger })
+	b.sells = slices.Insert(b.sells, i, st)
+}
+
+// remove takes a stop out by ID.
+func (b *stopBook) remove(id OrderID) (pendingStop, bool) {
+	side, ok := b.ids[id]
+	if !ok {
+		return pendingStop{}, false
+	}
+	delete(b.ids, id)
+	stops := &b.buys
+	if side == SideSell {
+		stops = &b.sells
+	}
+	i := slices.IndexFunc(*stops, func(st pendingStop) bool { return st.id == id })
+	st := (*stops)[i]
+	*stops = slices.Delete(*stops, i, i+1)
+	return st, true
+}
+
+// removeIf takes out every stop match accepts, buys then sells in trigger
+// order.
+func (b *stopBook) removeIf(match func(pendingStop) bool) []pendingStop {
+	var out []pendingStop
+	for _, side := range []*[]pendingStop{&b.buys, &b.sells} {
+		kept := (*side)[:0]
+		for _, st := range *side {
+			if match(st) {
+				delete(b.ids, st.id)
+				out = append(out, st)
+			} else {
+				kept = append(kept, st)
+			}
+		}
+		*side = kept
+	}
+	return out
+}
+
+// take removes the stops a trade at price triggers: buy stops at or below
+// it, lowest trigger first, then sell stops at or above it, highest first.
+func (b *stopBook) take(price PriceTicks) []pendingStop {
+	nb := sort.Search(len(b.buys), func(i int) bool { return b.buys[i].trigger > price })
+	ns := sort.Search(len(b.sells), func(i int) bool { return b.sells[i].trigger < price })
+	if nb+ns == 0 {
+		return nil
+	}
+	fired := make([]pendingStop, 0, nb+ns)
+	fired = append(fired, b.buys[:nb]...)
+	fired = append(fired, b.sells[:ns]...)
+	b.buys = slices.Delete(b.buys, 0, nb)
+	b.sells = slices.Delete(b.sells, 0, ns)
+	for _, st := range fired {
+		delete(b.ids, st.id)
+	}
+	return fired
+}
+
+func validateStop(o Order) error {
+	if o.Kind != OrderKindStop {
+		return ErrInvalidOrder
+	}
+	if o.ID == 0 || o.UserID == 0 {
+		return ErrInvalidOrder
+	}
+	if o.Size <= 0 || o.StopPrice <= 0 || o.Price < 0 {
+		return ErrInvalidOrder
+	}
+	if o.Side != SideBuy && o.Side != SideSell {
+		return ErrInvalidOrder
+	}
+	if o.Time <= 0 {
+		return ErrInvalidOrder
+	}
+	if o.TIF != TIFGTC || o.PostOnly || o.DisplaySize != 0 {
+		return ErrInvalidOrder
+	}
+	if o.ExpireAt < 0 || o.ExpireAt != 0 && o.ExpireAt <= o.Time {
+		return ErrInvalidOrder
+	}
+	return nil
+}
+
+// SubmitStop parks a stop order off the book until a trade reaches its
+// StopPrice: at or above it for a buy stop, at or below it for a sell stop.
+// The trade must come after the submit; a stop whose trigger the last trade
+// already passed still waits. It then becomes a market order, or a GTC
+// limit order at Price if that is set, under the stop's ID (see OnTrade).
+// A stop with an ExpireAt that has not triggered by then expires instead
+// (see ExpireStops).
+func (c *Core) SubmitStop(o Order) ([]Event, error) {
+	if err := validateStop(o); err != nil {
+		return nil, err
+	}
+	if c.idTaken(o.ID) {
+		return nil, ErrDuplicateID
+	}
+	c.stops.add(pendingStop{id: o.ID, userID: o.UserID, side: o.Side, trigger: o.StopPrice, limit: o.Price, size: o.Size, expire: o.ExpireAt})
+	return []Event{StopAcceptedEvent{
+		StopID:       o.ID,
+		UserID:       o.UserID,
+		Side:         o.Side,
+		TriggerPrice: o.StopPrice,
+		LimitPrice:   o.Price,
+		Size:         o.Size,
+		ExpireAt:     o.ExpireAt,
+		Time:         o.Time,
+	}}, nil
+}
+
+// idTaken reports whether a resting order or a pending stop holds id.
+func (c *Core) idTaken(id OrderID) bool {
+	_, resting := c.ob.orders[id]
+	return resting || c.stops.has(id)
+}
+
+// OnTrade is the trigger pass: it fires the stops a trade at price reaches,
+// then the stops their own trades reach, until no trade triggers any more.
+// Stops one trade triggers fire in trigger order, nearest the move first,
+// with buys before sells. Each emits a StopTriggeredEvent and then the
+// events of its order, timestamped now; a stop the book refuses, e.g. a
+// stop-market during an auction, emits a StopCanceledEvent instead. Stops
+// expired by now leave first, with StopExpiredEvents, and never trigger.
+//
+// Every call that trades runs the pass over its own trades after matching,
+// so callers only need OnTrade for a trade made elsewhere.
+func (c *Core) OnTrade(price PriceTicks, now int64) []Event {
+	return c.fireStops([]PriceTicks{price}, now)
+}
+
+// afterMatch runs the trigger pass over the trades in events and appends
+// what it emits.
+func (c *Core) afterMatch(events []Event, now int64) []Event {
+	if len(c.stops.ids) == 0 {
+		return events
+	}
+	var trades []PriceTicks
+	for _, ev := range events {
+		if tr, ok := ev.(TradeEvent); ok {
+			trades = append(trades, tr.Price)
+		}
+	}
+	return append(events, c.fireStops(trades, now)...)
+}
+
+// fireStops triggers the stops the queued trade prices reach, queueing the
+// trades of the orders they become.
+func (c *Core) fireStops(trades []PriceTicks, now int64) []Event {
+	events := c.ExpireStops(now)
+	for len(trades) > 0 && len(c.stops.ids) > 0 {
+		price := trades[0]
+		trades = trades[1:]
+		for _, st := range c.stops.take(price) {
+			evs := c.triggerStop(st, price, now)
+			for _, ev := range evs {
+				if tr, ok := ev.(TradeEvent); ok {
+					trades = append(trades, tr.Price)
+				}
+			}
+			events = append(events, evs...)
+		}
+	}
+	return events
+}
+
+// triggerStop submits a triggered stop's order, without running the
+// trigger pass itself.
+func (c *Core) triggerStop(st pendingStop, price PriceTicks, now int64) []Event {
+	o := Order{ID: st.id, UserID: st.userID, Side: st.side, Size: st.size, Time: now}
+	var evs []Event
+	var err error
+	if st.limit > 0 {
+		o.Kind, o.Price = OrderKindLimit, st.limit
+		_, evs, err = c.submitLimit(o)
+	} else {
+		o.Kind = OrderKindMarket
+		_, evs, err = c.submitMarket(o)
+	}
+	if err != nil {
+		return []Event{stopCanceledEvent(st, now)}
+	}
+	return append([]Event{StopTriggeredEvent{
+		StopID:       st.id,
+		UserID:       st.userID,
+		Side:         st.side,
+		TriggerPrice: st.trigger,
+		TradePrice:   price,
+		Time:         now,
+	}}, evs...)
+}
+
+// ExpireStops removes the stops whose ExpireAt is at or before now, buys
+// then sells in trigger order, with a StopExpiredEvent each. The trigger
+// pass does this itself; callers run it to expire stops between trades.
+func (c *Core) ExpireStops(now int64) []Event {
+	b := &c.stops
+	if b.nextExpiry == 0 || now < b.nextExpiry {
+		return nil
+	}
+	var events []Event
+	for _, st := range b.removeIf(func(st pendingStop) bool { return st.expired(now) }) {
+		events = append(events, StopExpiredEvent{
+			StopID:       st.id,
+			UserID:       st.userID,
+			Side:         st.side,
+			TriggerPrice: st.trigger,
+			Size:         st.size,
+			ExpireAt:     st.expire,
+			Time:         now,
+		})
+	}
+	b.nextExpiry = 0
+	for _, side := range [][]pendingStop{b.buys, b.sells} {
+		for _, st := range side {
+			if st.expire != 0 && (b.nextExpiry == 0 || st.expire < b.nextExpiry) {
+				b.nextExpiry = st.expire
+			}
+		}
+	}
+	return events
+}
+
+// cancelStops returns the reports and events of stops taken out of the
+// stop book.
+func cancelStops(stops []pendingStop, now int64) ([]CancelReport, []Event) {
+	if len(stops) == 0 {
+		return nil, nil
+	}
+	reports := make([]CancelReport, 0, len(stops))
+	events := make([]Event, 0, len(stops))
+	for _, st := range stops {
+		reports = append(reports, CancelReport{OrderID: st.id, CanceledSize: st.size})
+		events = append(events, stopCanceledEvent(st, now))
+	}
+	return reports, events
+}
+
+func stopCanceledEvent(st pendingStop, now int64) StopCanceledEvent {
+	return StopCanceledEvent{
+		StopID:       st.id,
+		UserID:       st.userID,
+		Side:         st.side,
+		TriggerPrice: st.trigger,
+		Size:         st.size,
+		Time:         now,
+	}
+}
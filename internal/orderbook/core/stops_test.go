@@ -0,0 +1,269 @@
+package core
+
+import (
+	"errors"
+	"testing"
+)
+
+func limit(id OrderID, user UserID, side Side, price PriceTicks, size Size, now int64) Order {
+	return Order{ID: id, UserID: user, Side: side, Kind: OrderKindLimit, Price: price, Size: size, Time: now}
+}
+
+func market(id OrderID, user UserID, side Side, size Size, now int64) Order {
+	return Order{ID: id, UserID: user, Side: side, Kind: OrderKindMarket, Size: size, Time: now}
+}
+
+func TestStopActivatesOnlyAfterTriggeringTrade(t *testing.T) {
+	c := NewCore()
+	for _, o := range []Order{
+		limit(1, 1, SideBuy, 100, 5, 1),
+		limit(2, 1, SideBuy, 98, 10, 2),
+	} {
+		if _, _, err := c.SubmitLimit(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	evs, err := c.SubmitStop(Order{ID: 3, UserID: 2, Side: SideSell, Kind: OrderKindStop, StopPrice: 99, Size: 8, Time: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(evs) != 1 {
+		t.Fatalf("expected one event, got %v", evs)
+	}
+	if ev, ok := evs[0].(StopAcceptedEvent); !ok || ev.StopID != 3 || ev.TriggerPrice != 99 || ev.Size != 8 {
+		t.Errorf("expected stop 3 accepted at 99, got %+v", evs[0])
+	}
+	if _, ok := c.ob.orders[3]; ok {
+		t.Fatal("expected the stop off the book")
+	}
+
+	// A trade at 100 leaves it parked
+	_, evs, _ = c.SubmitMarket(market(4, 3, SideSell, 2, 4))
+	for _, ev := range evs {
+		if _, ok := ev.(StopTriggeredEvent); ok {
+			t.Fatalf("expected a trade at 100 not to trigger a sell stop at 99, got %v", evs)
+		}
+	}
+
+	// Selling through 100 trades at 98, which triggers it
+	report, evs, err := c.SubmitMarket(market(5, 3, SideSell, 4, 5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Fills) != 2 {
+		t.Errorf("expected the report to hold only the submit's own fills, got %+v", report.Fills)
+	}
+	triggered := -1
+	var sold Size
+	for i, ev := range evs {
+		switch e := ev.(type) {
+		case StopTriggeredEvent:
+			if e.StopID != 3 || e.TradePrice != 98 || e.Time != 5 {
+				t.Errorf("expected stop 3 triggered at 98, got %+v", e)
+			}
+			triggered = i
+		case TradeEvent:
+			if e.TakerOrderID == 3 {
+				if triggered < 0 {
+					t.Error("expected the trigger before the stop's fills")
+				}
+				sold += e.Size
+			} else if triggered >= 0 {
+				t.Error("expected the stop's events after the submit's own")
+			}
+		}
+	}
+	if triggered < 0 || sold != 8 {
+		t.Errorf("expected the stop to sell 8 as a market order, sold %d", sold)
+	}
+	if c.stops.has(3) {
+		t.Error("expected the stop gone from the stop book")
+	}
+	if bl := c.ob.bids.bestLevel(); bl == nil || bl.price != 98 || bl.totalVolume != 1 {
+		t.Errorf("expected 1 left at 98, got %+v", bl)
+	}
+}
+
+func TestStopTriggerOrderAndCascade(t *testing.T) {
+	c := NewCore()
+	c.SubmitLimit(limit(1, 1, SideBuy, 95, 100, 1))
+	c.SubmitLimit(limit(2, 1, SideSell, 101, 100, 2))
+	for _, o := range []Order{
+		{ID: 10, UserID: 2, Side: SideSell, StopPrice: 97, Size: 1},
+		{ID: 11, UserID: 3, Side: SideSell, StopPrice: 99, Size: 1},
+		{ID: 12, UserID: 4, Side: SideSell, StopPrice: 99, Size: 1},
+		{ID: 13, UserID: 5, Side: SideBuy, StopPrice: 101, Size: 1},
+		{ID: 14, UserID: 5, Side: SideBuy, StopPrice: 95, Size: 1},
+	} {
+		o.Kind, o.Time = OrderKindStop, 3
+		if _, err := c.SubmitStop(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	// One trade at 95 fires the buy stop at 95, then the sell stops nearest
+	// first, ties in arrival order. The buy stop at 101 needs a trade there,
+	// which the buy stop's own fill at 101 then supplies.
+	_, evs, _ := c.SubmitMarket(market(20, 6, SideSell, 1, 4))
+	var order []OrderID
+	for _, ev := range evs {
+		if e, ok := ev.(StopTriggeredEvent); ok {
+			order = append(order, e.StopID)
+		}
+	}
+	want := []OrderID{14, 11, 12, 10, 13}
+	if len(order) != len(want) {
+		t.Fatalf("expected stops %v to fire in that order, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("expected stops %v to fire in that order, got %v", want, order)
+		}
+	}
+	if len(c.stops.ids) != 0 {
+		t.Errorf("expected no stops pending, got %+v", c.stops)
+	}
+}
+
+func TestOnTradeTriggersStopLimit(t *testing.T) {
+	c := NewCore()
+	c.SubmitStop(Order{ID: 1, UserID: 1, Side: SideBuy, Kind: OrderKindStop, StopPrice: 105, Price: 106, Size: 3, Time: 1})
+
+	if evs := c.OnTrade(104, 2); len(evs) != 0 {
+		t.Fatalf("expected nothing below the trigger, got %v", evs)
+	}
+	evs := c.OnTrade(105, 3)
+	if len(evs) != 2 {
+		t.Fatalf("expected a trigger and a rest, got %v", evs)
+	}
+	if ev, ok := evs[1].(OrderRestedEvent); !ok || ev.OrderID != 1 || ev.Price != 106 || ev.Size != 3 || ev.Time != 3 {
+		t.Errorf("expected the stop-limit to rest 3 @ 106 under its ID, got %+v", evs[1])
+	}
+}
+
+func TestStopRefusedWhenTriggeredCancels(t *testing.T) {
+	c := NewCore()
+	c.SubmitStop(Order{ID: 1, UserID: 1, Side: SideSell, Kind: OrderKindStop, StopPrice: 100, Size: 3, Time: 1})
+	c.BeginAuction()
+
+	evs := c.OnTrade(99, 2)
+	if len(evs) != 1 {
+		t.Fatalf("expected one event, got %v", evs)
+	}
+	if ev, ok := evs[0].(StopCanceledEvent); !ok || ev.StopID != 1 || ev.Size != 3 {
+		t.Errorf("expected the stop-market canceled during the auction, got %+v", evs[0])
+	}
+}
+
+func TestCancelStop(t *testing.T) {
+	c := NewCore()
+	c.SubmitStop(Order{ID: 1, UserID: 1, Side: SideSell, Kind: OrderKindStop, StopPrice: 100, Size: 3, Time: 1})
+	c.SubmitStop(Order{ID: 2, UserID: 2, Side: SideBuy, Kind: OrderKindStop, StopPrice: 110, Size: 4, Time: 1})
+	c.SubmitStop(Order{ID: 3, UserID: 2, Side: SideSell, Kind: OrderKindStop, StopPrice: 90, Size: 5, Time: 1})
+
+	report, evs, err := c.Cancel(1, 2)
+	if err != nil || report.OrderID != 1 || report.CanceledSize != 3 {
+		t.Fatalf("expected stop 1 canceled, got %+v, %v", report, err)
+	}
+	if len(evs) != 1 {
+		t.Fatalf("expected one event, got %v", evs)
+	}
+	if _, ok := evs[0].(StopCanceledEvent); !ok {
+		t.Errorf("expected a StopCanceledEvent, got %+v", evs[0])
+	}
+	if _, _, err := c.Cancel(1, 3); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound canceling it again, got %v", err)
+	}
+	if evs := c.OnTrade(99, 4); len(evs) != 0 {
+		t.Errorf("expected a canceled stop not to trigger, got %v", evs)
+	}
+
+	reports, _ := c.CancelUser(2, 5)
+	if len(reports) != 2 || reports[0].OrderID != 2 || reports[1].OrderID != 3 {
+		t.Errorf("expected user 2's stops canceled, buys first, got %+v", reports)
+	}
+}
+
+func TestSubmitStopRejects(t *testing.T) {
+	c := NewCore()
+	c.SubmitLimit(limit(1, 1, SideBuy, 100, 5, 1))
+	ok := Order{ID: 2, UserID: 1, Side: SideSell, Kind: OrderKindStop, StopPrice: 99, Size: 1, Time: 1}
+	if _, err := c.SubmitStop(ok); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tt := range []struct {
+		name string
+		edit func(*Order)
+		want error
+	}{
+		{"limit kind", func(o *Order) { o.Kind = OrderKindLimit }, ErrInvalidOrder},
+		{"no trigger", func(o *Order) { o.StopPrice = 0 }, ErrInvalidOrder},
+		{"negative limit", func(o *Order) { o.Price = -1 }, ErrInvalidOrder},
+		{"IOC", func(o *Order) { o.TIF = TIFIOC }, ErrInvalidOrder},
+		{"post-only", func(o *Order) { o.PostOnly = true }, ErrInvalidOrder},
+		{"resting ID", func(o *Order) { o.ID = 1 }, ErrDuplicateID},
+		{"pending ID", func(o *Order) { o.ID = 2 }, ErrDuplicateID},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o := ok
+			o.ID = 3
+			tt.edit(&o)
+			if _, err := c.SubmitStop(o); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, _, err := c.SubmitLimit(limit(2, 1, SideBuy, 90, 1, 2)); !errors.Is(err, ErrDuplicateID) {
+		t.Errorf("expected a limit order under a pending stop's ID to be rejected, got %v", err)
+	}
+}
+
+func TestStopExpiry(t *testing.T) {
+	c := NewCore()
+	c.SubmitLimit(limit(1, 1, SideBuy, 100, 10, 1))
+	c.SubmitStop(Order{ID: 2, UserID: 2, Side: SideSell, Kind: OrderKindStop, StopPrice: 90, Size: 1, Time: 1, ExpireAt: 20})
+	c.SubmitStop(Order{ID: 3, UserID: 2, Side: SideSell, Kind: OrderKindStop, StopPrice: 100, Size: 1, Time: 1, ExpireAt: 10})
+	c.SubmitStop(Order{ID: 4, UserID: 2, Side: SideSell, Kind: OrderKindStop, StopPrice: 100, Size: 1, Time: 1})
+
+	if evs := c.ExpireStops(9); len(evs) != 0 {
+		t.Fatalf("expected nothing to expire before 10, got %v", evs)
+	}
+	evs := c.ExpireStops(10)
+	if len(evs) != 1 {
+		t.Fatalf("expected one expiry, got %v", evs)
+	}
+	if e, ok := evs[0].(StopExpiredEvent); !ok || e.StopID != 3 || e.ExpireAt != 10 || e.Time != 10 {
+		t.Errorf("expected stop 3 expired at 10, got %+v", evs[0])
+	}
+	if c.stops.nextExpiry != 20 {
+		t.Errorf("expected the next expiry at 20, got %d", c.stops.nextExpiry)
+	}
+
+	// The trigger pass expires stops before it fires any: stop 2 is past
+	// its expiry when the trade at 100 arrives, stop 4 never expires.
+	_, evs, _ = c.SubmitMarket(market(5, 3, SideSell, 1, 20))
+	var expired, triggered []OrderID
+	for _, ev := range evs {
+		switch e := ev.(type) {
+		case StopExpiredEvent:
+			expired = append(expired, e.StopID)
+		case StopTriggeredEvent:
+			triggered = append(triggered, e.StopID)
+		}
+	}
+	if len(expired) != 1 || expired[0] != 2 || len(triggered) != 1 || triggered[0] != 4 {
+		t.Errorf("expected stop 2 expired and stop 4 triggered, got %v and %v", expired, triggered)
+	}
+	if len(c.stops.ids) != 0 || c.stops.nextExpiry != 0 {
+		t.Errorf("expected no stops pending, got %+v", c.stops)
+	}
+
+	if _, err := c.SubmitStop(Order{ID: 6, UserID: 2, Side: SideSell, Kind: OrderKindStop, StopPrice: 90, Size: 1, Time: 30, ExpireAt: 30}); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder for an expiry at the submit, got %v", err)
+	}
+	if _, _, err := c.SubmitLimit(Order{ID: 7, UserID: 2, Side: SideSell, Kind: OrderKindLimit, Price: 90, Size: 1, Time: 30, ExpireAt: 40}); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder for an expiry on a limit order, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package core
+
+// STPMode selects how matching treats an incoming order that would trade
+// with a resting order of the same user.
+type STPMode uint8
+
+const (
+	// STPNone lets users trade with themselves.
+	STPNone STPMode = iota
+	// STPCancelResting cancels the user's resting order and keeps matching
+	// the incoming order against the rest of the book.
+	STPCancelResting
+	// STPCancelTaker stops the incoming order at the first self-match and
+	// discards its remaining size, so a limit order does not rest.
+	STPCancelTaker
+)
+
+func (m STPMode) String() string {
+	switch m {
+	case STPNone:
+		return "NONE"
+	case STPCancelResting:
+		return "CANCEL_RESTING"
+	case STPCancelTaker:
+		return "CANCEL_TAKER"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// SetSelfTradePrevention sets the self-trade prevention mode for continuous
+// matching. Auction uncrosses and ResolveCross are not affected. The default
+// is STPNone.
+func (c *Core) SetSelfTradePrevention(m STPMode) {
+	c.stp = m
+}
+
+// SelfTradePrevention returns the self-trade prevention mode.
+func (c *Core) SelfTradePrevention() STPMode {
+	return c.stp
+}
+
+// stpResult is what self-trade prevention did during one match.
+type stpResult struct {
+	canceled    []OrderID // resting orders canceled
+	takerCancel bool      // the incoming order's remainder was discarded
+}
@@ -0,0 +1,127 @@
+package core
+
+import (
+	"slices"
+	"testing"
+)
+
+// stpBook rests asks of 5 at 100 from user 1 (id 1), user 2 (id 2) and
+// user 1 again (id 3), in that queue order.
+func stpBook(t *testing.T, mode STPMode) *Core {
+	t.Helper()
+	c := NewCoreWithConfig(CoreConfig{SelfTradePrevention: mode})
+	for i, user := range []UserID{1, 2, 1} {
+		o := Order{ID: OrderID(i + 1), UserID: user, Side: SideSell, Kind: OrderKindLimit, Price: 100, Size: 5, Time: int64(i + 1)}
+		if _, _, err := c.SubmitLimit(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return c
+}
+
+func TestSTPCancelRestingReportsCanceledOrders(t *testing.T) {
+	c := stpBook(t, STPCancelResting)
+
+	report, events, err := c.SubmitLimit(Order{ID: 10, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 8, Time: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(report.STPCanceled, []OrderID{1, 3}) || report.STPTakerCanceled {
+		t.Errorf("expected orders 1 and 3 STP-canceled, got %v (taker %v)", report.STPCanceled, report.STPTakerCanceled)
+	}
+	// Only user 2's order trades; the rest of the taker rests
+	if len(report.Fills) != 1 || report.Fills[0].MakerOrderID != 2 || report.Fills[0].Size != 5 {
+		t.Errorf("expected one fill of 5 against order 2, got %+v", report.Fills)
+	}
+	if !report.Rested || report.RestedSize != 3 {
+		t.Errorf("expected 3 resting, got %+v", report)
+	}
+
+	var removed []OrderID
+	for _, ev := range events {
+		if rm, ok := ev.(OrderRemovedEvent); ok && rm.Reason == RemoveReasonSelfTrade {
+			removed = append(removed, rm.OrderID)
+			if rm.Remaining != 5 {
+				t.Errorf("expected the canceled size 5, got %d", rm.Remaining)
+			}
+		}
+	}
+	if !slices.Equal(removed, []OrderID{1, 3}) {
+		t.Errorf("expected self-trade removals for orders 1 and 3, got %v", removed)
+	}
+	if l := c.ob.asks.bestLevel(); l != nil {
+		t.Errorf("expected the ask side empty, got %d at %d", l.totalVolume, l.price)
+	}
+}
+
+func TestSTPCancelTakerDiscardsRemainder(t *testing.T) {
+	c := stpBook(t, STPCancelTaker)
+
+	// User 2 trades through order 1 of user 1, then stops at its own order
+	report, _, err := c.SubmitLimit(Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 12, Time: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.STPTakerCanceled || len(report.STPCanceled) != 0 {
+		t.Errorf("expected the taker STP-canceled, got %+v", report)
+	}
+	if len(report.Fills) != 1 || report.Fills[0].MakerOrderID != 1 {
+		t.Errorf("expected one fill against order 1, got %+v", report.Fills)
+	}
+	if report.Rested || report.Remaining != 7 {
+		t.Errorf("expected 7 discarded and nothing resting, got %+v", report)
+	}
+	if l := c.ob.asks.bestLevel(); l == nil || l.totalVolume != 10 || l.head.id != 2 {
+		t.Errorf("expected orders 2 and 3 to remain, got %+v", l)
+	}
+}
+
+func TestSTPNoneSelfTrades(t *testing.T) {
+	c := stpBook(t, STPNone)
+	report, _, err := c.SubmitLimit(Order{ID: 10, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 100, Size: 5, Time: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Fills) != 1 || report.Fills[0].MakerOrderID != 1 || report.STPCanceled != nil || report.STPTakerCanceled {
+		t.Errorf("expected a self-trade against order 1, got %+v", report)
+	}
+}
+
+func TestSTPAppliesToMarketOrders(t *testing.T) {
+	market := Order{ID: 10, UserID: 1, Side: SideBuy, Kind: OrderKindMarket, Size: 5, Time: 10}
+	selfTraded := func(events []Event) bool {
+		for _, ev := range events {
+			if tr, ok := ev.(TradeEvent); ok && tr.MakerUserID == tr.TakerUserID {
+				return true
+			}
+		}
+		return false
+	}
+
+	// User 1's own order 1 heads the queue: it is canceled and the order
+	// fills against user 2's order 2 instead
+	c := stpBook(t, STPCancelResting)
+	report, events, err := c.SubmitMarket(market)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(report.STPCanceled, []OrderID{1}) || len(report.Fills) != 1 || report.Fills[0].MakerOrderID != 2 {
+		t.Errorf("expected order 1 STP-canceled and a fill against order 2, got %+v", report)
+	}
+	if selfTraded(events) {
+		t.Errorf("expected no self-trade, got %+v", events)
+	}
+
+	// The market order stops at its own order without trading
+	c = stpBook(t, STPCancelTaker)
+	report, events, err = c.SubmitMarket(market)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.STPTakerCanceled || len(report.Fills) != 0 || report.Remaining != 5 || len(events) != 0 {
+		t.Errorf("expected the market order STP-canceled untouched, got %+v (events %+v)", report, events)
+	}
+	if l := c.ob.asks.bestLevel(); l == nil || l.totalVolume != 15 {
+		t.Errorf("expected the book unchanged, got %+v", l)
+	}
+}
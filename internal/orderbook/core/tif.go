@@ -0,0 +1,79 @@
+package core
+
+import "slices"
+
+// TimeInForce says how long a limit order may work on the book.
+type TimeInForce uint8
+
+const (
+	// TIFGTC rests any unfilled size until it fills or is canceled (the
+	// default).
+	TIFGTC TimeInForce = iota
+	// TIFIOC fills what it can immediately and discards the rest.
+	TIFIOC
+	// TIFFOK fills its whole size immediately or does nothing.
+	TIFFOK
+)
+
+func (t TimeInForce) String() string {
+	switch t {
+	case TIFGTC:
+		return "GTC"
+	case TIFIOC:
+		return "IOC"
+	case TIFFOK:
+		return "FOK"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func validTIF(t TimeInForce) bool {
+	return t == TIFGTC || t == TIFIOC || t == TIFFOK
+}
+
+// fillable reports whether matching would fill the taker's whole size
+// within limitPrice (nil = any price), walking the opposite side in price
+// and time priority as match does. The user's own resting orders count as
+// self-trade prevention would treat them: skipped under STPCancelResting,
+// and under STPCancelTaker the match stops at the first one.
+func (c *Core) fillable(taker Order, limitPrice *PriceTicks) bool {
+	opp := c.ob.asks
+	if taker.Side == SideSell {
+		opp = c.ob.bids
+	}
+
+	prices := make([]PriceTicks, 0, len(opp.levels))
+	for price := range opp.levels {
+		if limitPrice != nil {
+			if taker.Side == SideBuy && price > *limitPrice {
+				continue
+			}
+			if taker.Side == SideSell && price < *limitPrice {
+				continue
+			}
+		}
+		prices = append(prices, price)
+	}
+	slices.Sort(prices)
+	if opp.isBid {
+		slices.Reverse(prices)
+	}
+
+	need := taker.Size
+	for _, price := range prices {
+		for node := opp.levels[price].head; node != nil; node = node.next {
+			if c.stp != STPNone && node.userID == taker.UserID {
+				if c.stp == STPCancelTaker {
+					return false
+				}
+				continue
+			}
+			need -= node.remaining()
+			if need <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package core
+
+import "testing"
+
+// tifBook rests asks of 5 at 101 (id 1), 3 at 102 (id 2) and 4 at 104
+// (id 3), for user 1.
+func tifBook(t *testing.T) *Core {
+	t.Helper()
+	c := NewCore()
+	for i, l := range []struct {
+		price PriceTicks
+		size  Size
+	}{{101, 5}, {102, 3}, {104, 4}} {
+		o := Order{ID: OrderID(i + 1), UserID: 1, Side: SideSell, Kind: OrderKindLimit, Price: l.price, Size: l.size, Time: int64(i + 1)}
+		if _, _, err := c.SubmitLimit(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	return c
+}
+
+func askVolume(c *Core) Size {
+	var total Size
+	for _, l := range c.ob.asks.levels {
+		total += l.totalVolume
+	}
+	return total
+}
+
+func TestFOKFillsAcrossLevels(t *testing.T) {
+	c := tifBook(t)
+
+	report, events, err := c.SubmitLimit(Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 102, Size: 8, Time: 10, TIF: TIFFOK})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Fills) != 2 || report.Remaining != 0 || report.Rested {
+		t.Errorf("expected 8 filled over two levels, got %+v", report)
+	}
+	var trades int
+	for _, ev := range events {
+		switch ev.(type) {
+		case TradeEvent:
+			trades++
+		case OrderRestedEvent:
+			t.Errorf("expected nothing to rest, got %+v", ev)
+		}
+	}
+	if trades != 2 {
+		t.Errorf("expected 2 trade events, got %d", trades)
+	}
+	if _, ok := c.ob.asks.levels[104]; !ok || askVolume(c) != 4 {
+		t.Errorf("expected only the 104 level left, got %d resting", askVolume(c))
+	}
+}
+
+func TestFOKKilledWhenLiquidityRunsOut(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		price PriceTicks
+		size  Size
+	}{
+		// 8 rests within 103, with no level between 102 and 104
+		{"gap before the limit", 103, 9},
+		// 12 is on the book in all, but only 8 within the limit
+		{"beyond the limit", 102, 12},
+		{"more than the book", 110, 13},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tifBook(t)
+			report, events, err := c.SubmitLimit(Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: tt.price, Size: tt.size, Time: 10, TIF: TIFFOK})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(report.Fills) != 0 || report.Remaining != tt.size || report.Rested {
+				t.Errorf("expected the order killed untouched, got %+v", report)
+			}
+			if len(events) != 0 {
+				t.Errorf("expected no events, got %+v", events)
+			}
+			if askVolume(c) != 12 || len(c.ob.orders) != 3 {
+				t.Errorf("expected the book unchanged, got %d resting in %d orders", askVolume(c), len(c.ob.orders))
+			}
+		})
+	}
+}
+
+func TestFOKCountsSelfTradePrevention(t *testing.T) {
+	// User 2's own ask at 101 sits ahead of user 1's
+	c := NewCore()
+	for i, user := range []UserID{2, 1} {
+		o := Order{ID: OrderID(i + 1), UserID: user, Side: SideSell, Kind: OrderKindLimit, Price: 101, Size: 5, Time: int64(i + 1)}
+		if _, _, err := c.SubmitLimit(o); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	buy := Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 101, Size: 5, Time: 10, TIF: TIFFOK}
+
+	// Canceling the taker would stop it at its own order
+	c.SetSelfTradePrevention(STPCancelTaker)
+	if report, _, _ := c.SubmitLimit(buy); len(report.Fills) != 0 {
+		t.Errorf("expected the FOK killed under STPCancelTaker, got %+v", report)
+	}
+
+	// Canceling the resting order leaves 5 from user 1 to fill against
+	c.SetSelfTradePrevention(STPCancelResting)
+	report, _, _ := c.SubmitLimit(buy)
+	if report.Remaining != 0 || len(report.Fills) != 1 || report.Fills[0].MakerOrderID != 2 {
+		t.Errorf("expected a fill against order 2, got %+v", report)
+	}
+	if len(report.STPCanceled) != 1 || report.STPCanceled[0] != 1 {
+		t.Errorf("expected order 1 STP-canceled, got %v", report.STPCanceled)
+	}
+
+	// Without prevention the own order is liquidity too, but 5 is not 6
+	c = tifBook(t)
+	if report, _, _ := c.SubmitLimit(Order{ID: 10, UserID: 1, Side: SideBuy, Kind: OrderKindLimit, Price: 101, Size: 6, Time: 10, TIF: TIFFOK}); len(report.Fills) != 0 {
+		t.Errorf("expected the FOK killed, got %+v", report)
+	}
+}
+
+func TestFOKMarketOrder(t *testing.T) {
+	c := tifBook(t)
+	if report, _, _ := c.SubmitMarket(Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindMarket, Size: 13, Time: 10, TIF: TIFFOK}); len(report.Fills) != 0 || askVolume(c) != 12 {
+		t.Errorf("expected the FOK market order killed, got %+v", report)
+	}
+	if report, _, _ := c.SubmitMarket(Order{ID: 11, UserID: 2, Side: SideBuy, Kind: OrderKindMarket, Size: 12, Time: 11, TIF: TIFFOK}); report.Remaining != 0 || len(report.Fills) != 3 {
+		t.Errorf("expected the whole book taken, got %+v", report)
+	}
+}
+
+func TestIOCDiscardsRemainder(t *testing.T) {
+	c := tifBook(t)
+
+	report, events, err := c.SubmitLimit(Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 102, Size: 10, Time: 10, TIF: TIFIOC})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Fills) != 2 || report.Remaining != 2 || report.Rested || report.RestedSize != 0 {
+		t.Errorf("expected 8 filled and 2 discarded, got %+v", report)
+	}
+	for _, ev := range events {
+		if _, ok := ev.(OrderRestedEvent); ok {
+			t.Errorf("expected no rested event, got %+v", ev)
+		}
+	}
+	if _, ok := c.ob.orders[10]; ok || c.ob.bids.bestLevel() != nil {
+		t.Errorf("expected nothing resting on the bid side")
+	}
+}
+
+func TestTIFRejections(t *testing.T) {
+	c := tifBook(t)
+	if _, _, err := c.SubmitLimit(Order{ID: 10, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 101, Size: 1, Time: 10, TIF: 9}); err != ErrInvalidOrder {
+		t.Errorf("expected ErrInvalidOrder for an unknown TIF, got %v", err)
+	}
+	if _, err := c.Restore([]Order{{ID: 11, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 99, Size: 1, Time: 11, TIF: TIFIOC}}); err != ErrInvalidOrder {
+		t.Errorf("expected ErrInvalidOrder restoring an IOC order, got %v", err)
+	}
+
+	c.BeginAuction()
+	for _, tif := range []TimeInForce{TIFIOC, TIFFOK} {
+		if _, _, err := c.SubmitLimit(Order{ID: 12, UserID: 2, Side: SideBuy, Kind: OrderKindLimit, Price: 101, Size: 1, Time: 12, TIF: tif}); err != ErrAuction {
+			t.Errorf("expected ErrAuction for %s during the auction, got %v", tif, err)
+		}
+	}
+}
@@ -29,12 +29,15 @@ func (s Side) Opposite() Side {
 	return SideBuy
 }
 
-// OrderKind represents the order type: limit or market.
+// OrderKind represents the order type: limit, market or stop.
 type OrderKind uint8
 
 const (
 	OrderKindLimit OrderKind = iota
 	OrderKindMarket
+	// OrderKindStop is parked off the book until a trade reaches its
+	// StopPrice (see Core.SubmitStop).
+	OrderKindStop
 )
 
 func (k OrderKind) String() string {
@@ -43,6 +46,8 @@ func (k OrderKind) String() string {
 		return "LIMIT"
 	case OrderKindMarket:
 		return "MARKET"
+	case OrderKindStop:
+		return "STOP"
 	default:
 		return "UNKNOWN"
 	}
@@ -71,9 +76,22 @@ type Order struct {
 	UserID UserID
 	Side   Side
 	Kind   OrderKind
-	Price  PriceTicks // limit only
+	Price  PriceTicks // limit, or a stop's limit once triggered (0 = market)
 	Size   Size       // requested size (for submits); remaining size (in reports)
 	Time   int64      // unix nanos set by service layer
+	TIF    TimeInForce
+	// PostOnly rejects a limit order with ErrWouldCross rather than let it
+	// take liquidity.
+	PostOnly bool
+	// DisplaySize makes a GTC limit order an iceberg: only DisplaySize of it
+	// is on the book at a time and the rest is held in reserve. 0 shows the
+	// whole order.
+	DisplaySize Size
+	// StopPrice is a stop order's trigger; stops only.
+	StopPrice PriceTicks
+	// ExpireAt is when an untriggered stop expires, in unix nanos; stops
+	// only. 0 keeps it until it triggers or is canceled.
+	ExpireAt int64
 }
 
 // IsFilled returns true if the order has no remaining size.
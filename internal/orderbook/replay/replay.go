@@ -0,0 +1,93 @@
+// Package replay reads back event logs written by the orderbook service's
+// TapeWriter and TradeRecorder.
+//
+// A book's log replays into a fresh view, rebuilding its levels, orders and
+// trade tape:
+//
+//	events, err := replay.LoadEvents("AAPL.jsonl")
+//	v := view.NewBookView(1000)
+//	v.Rebuild(events)
+//
+// Feeding the log's trades to a market view's CandleAggregator.Add rebuilds
+// the ticker's candles the same way.
+package replay
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/codec"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// LoadEvents reads every event logged to path, oldest rotated segment
+// (path.1, path.2, ...) first and the active file last. A missing log is
+// empty, not an error.
+func LoadEvents(path string) ([]core.Event, error) {
+	segs, err := Segments(path)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(segs)+1)
+	for _, n := range segs {
+		files = append(files, SegmentName(path, n))
+	}
+	files = append(files, path)
+
+	var out []core.Event
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+		dec := codec.NewDecoder(f)
+		for {
+			ev, err := dec.Decode()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			out = append(out, ev)
+		}
+		f.Close()
+	}
+	return out, nil
+}
+
+// Segments returns the numbers of a log's rotated segments, oldest first.
+// The TapeWriter names and prunes its segments by it, so rotation and
+// LoadEvents agree on what a segment is. The stats recorder's CSV files
+// rotate the same way.
+func Segments(path string) ([]int, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+	var segs []int
+	for _, m := range matches {
+		n, err := strconv.Atoi(strings.TrimPrefix(m, path+"."))
+		if err != nil {
+			continue
+		}
+		segs = append(segs, n)
+	}
+	sort.Ints(segs)
+	return segs, nil
+}
+
+// SegmentName returns the file name of a log's rotated segment n.
+func SegmentName(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
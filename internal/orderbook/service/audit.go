@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// AuditRecord is one fill as the audit log records it: the trade plus the
+// maker's size left once it filled.
+type AuditRecord struct {
+	Time           int64           `json:"time"`
+	TakerOrderID   core.OrderID    `json:"taker_order_id"`
+	TakerUserID    core.UserID     `json:"taker_user_id"`
+	TakerSide      string          `json:"taker_side"`
+	MakerOrderID   core.OrderID    `json:"maker_order_id"`
+	MakerUserID    core.UserID     `json:"maker_user_id"`
+	Price          core.PriceTicks `json:"price"`
+	Size           core.Size       `json:"size"`
+	MakerRemaining core.Size       `json:"maker_remaining"`
+}
+
+// AuditLog writes one JSON line per fill. It is safe for concurrent use, so
+// one log can be shared by several books.
+type AuditLog struct {
+	mu  sync.Mutex
+	w   *bufio.Writer
+	enc *json.Encoder
+	err error
+}
+
+// NewAuditLog creates an AuditLog writing to w. The caller owns w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	bw := bufio.NewWriter(w)
+	return &AuditLog{w: bw, enc: json.NewEncoder(bw)}
+}
+
+// Record appends a record. After the first failure every call returns that
+// error.
+func (a *AuditLog) Record(r AuditRecord) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return a.err
+	}
+	if err := a.enc.Encode(r); err != nil {
+		a.err = err
+	}
+	return a.err
+}
+
+// Flush writes buffered records to the underlying writer.
+func (a *AuditLog) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return a.err
+	}
+	if err := a.w.Flush(); err != nil {
+		a.err = err
+	}
+	return a.err
+}
+
+// Err returns the first write error, if any.
+func (a *AuditLog) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+// audit records a trade with its maker's remaining size. The view still
+// holds the maker at its size before the fill, since the reduction or
+// removal follows the trade.
+func (s *Service) audit(tr core.TradeEvent) {
+	var remaining core.Size
+	if maker, ok := s.view.Order(tr.MakerOrderID); ok {
+		remaining = maker.Size - tr.Size
+	}
+	s.cfg.Audit.Record(AuditRecord{
+		Time:           tr.Time,
+		TakerOrderID:   tr.TakerOrderID,
+		TakerUserID:    tr.TakerUserID,
+		TakerSide:      tr.TakerSide.String(),
+		MakerOrderID:   tr.MakerOrderID,
+		MakerUserID:    tr.MakerUserID,
+		Price:          tr.Price,
+		Size:           tr.Size,
+		MakerRemaining: remaining,
+	})
+}
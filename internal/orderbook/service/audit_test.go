@@ -0,0 +1,50 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestServiceAuditLog(t *testing.T) {
+	var buf bytes.Buffer
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Audit = NewAuditLog(&buf)
+	svc := NewService(cfg)
+
+	ctx := context.Background()
+	a, _ := svc.SubmitLimit(ctx, 1, core.SideSell, 100, 10)
+	b, _ := svc.SubmitLimit(ctx, 2, core.SideSell, 101, 5)
+	taker, err := svc.SubmitMarket(ctx, 3, core.SideBuy, 12)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	svc.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one record per fill, got %q", buf.String())
+	}
+	want := []AuditRecord{
+		{TakerOrderID: taker.OrderID, TakerUserID: 3, TakerSide: "BUY", MakerOrderID: a.OrderID, MakerUserID: 1, Price: 100, Size: 10, MakerRemaining: 0},
+		{TakerOrderID: taker.OrderID, TakerUserID: 3, TakerSide: "BUY", MakerOrderID: b.OrderID, MakerUserID: 2, Price: 101, Size: 2, MakerRemaining: 3},
+	}
+	for i, line := range lines {
+		var got AuditRecord
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("record %d: %v", i, err)
+		}
+		if got.Time == 0 {
+			t.Errorf("record %d: expected a time", i)
+		}
+		got.Time = 0
+		if got != want[i] {
+			t.Errorf("record %d: expected %+v, got %+v", i, want[i], got)
+		}
+	}
+}
@@ -1,17 +1,71 @@
 package service
 
+import (
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/orderbook/view"
+)
+
 // Config holds configuration for the orderbook service.
 type Config struct {
 	// CommandBuffer is the size of the inbound command channel.
 	CommandBuffer int
 	// EventBuffer is the size of the internal authoritative event channel.
 	EventBuffer int
-	// TradeTapeSize is the capacity of the trade tape ring buffer.
+	// TradeTapeSize is the capacity of the trade tape ring buffer. The tape
+	// is allocated at full capacity, so size it for the trade rate times
+	// the history readers need.
 	TradeTapeSize int
+	// ExpectedBook presizes the view's order and level maps for the book
+	// the ticker is expected to hold. The zero value grows them on demand.
+	ExpectedBook view.SizeHint
 	// DropExternalEvents determines whether external event channel drops on overflow.
 	DropExternalEvents bool
 	// ExternalEventBuffer is the size of the external events channel.
 	ExternalEventBuffer int
+	// TapeWriter, if set, persists every trade applied to the view.
+	// The service flushes it on Close; the caller owns and closes it.
+	TapeWriter *TapeWriter
+	// Recorder, if set, logs every event the book emits, on its own
+	// goroutine. The service flushes it on Close; the caller owns and
+	// closes it.
+	Recorder *TradeRecorder
+	// Audit, if set, records every fill with the maker's remaining size,
+	// finer-grained than the tape. The service flushes it on Close.
+	Audit *AuditLog
+	// CrossPolicy repairs a crossed book after auction release and Restore.
+	// The zero value is core.CrossPolicyContinuous.
+	CrossPolicy core.CrossPolicy
+	// SelfTradePrevention is how the core treats an order that would trade
+	// with a resting order of the same user. The zero value is core.STPNone.
+	SelfTradePrevention core.STPMode
+	// Level2 configures the level deltas sent to SubscribeLevel2.
+	Level2 view.Level2Config
+	// OnEvent, if set, is called with each event right after the view
+	// applies it, on the goroutine that applied it. It must not call back
+	// into the service's commands.
+	OnEvent func(core.Event)
+	// Synchronous runs each command on the caller's goroutine, with no
+	// command processor or event dispatcher, so the view reflects a command
+	// as soon as it returns. External events drop on overflow. Intended for
+	// tests only: concurrent callers serialize on a mutex and nothing is
+	// buffered.
+	Synchronous bool
+	// Clock timestamps orders and events and measures command latency. Nil
+	// uses the real clock.
+	Clock clock.Clock
+	// RecordLatency stamps each command when it is submitted and when its
+	// response is ready, reporting the difference in SubmitReport.Latency
+	// and aggregating it in Stats.
+	RecordLatency bool
+	// LatencySamples is how many recent latencies Stats covers.
+	LatencySamples int
+	// StopExpiryInterval is how often the command processor expires stops
+	// between commands; every command also expires them first. 0 leaves
+	// expiry to commands alone, as does Synchronous.
+	StopExpiryInterval time.Duration
 }
 
 // DefaultConfig returns a Config with reasonable defaults.
@@ -22,5 +76,7 @@ func DefaultConfig() Config {
 		TradeTapeSize:       1000,
 		DropExternalEvents:  true,
 		ExternalEventBuffer: 256,
+		LatencySamples:      1024,
+		StopExpiryInterval:  time.Second,
 	}
 }
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Stats reports round-trip command latency: from the moment a command is
+// submitted to the service until its response is ready, including the time
+// it waits in the command queue. Populated only with Config.RecordLatency.
+type Stats struct {
+	// Commands is the number of commands measured since the service started.
+	Commands int64
+	// Percentiles and maximum over the last Config.LatencySamples commands.
+	P50, P90, P99, Max time.Duration
+}
+
+// latencyRing keeps the most recent latency samples.
+type latencyRing struct {
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	full    bool
+	total   int64
+}
+
+func newLatencyRing(capacity int) *latencyRing {
+	return &latencyRing{samples: make([]time.Duration, capacity)}
+}
+
+func (r *latencyRing) record(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.samples[r.next] = d
+	r.next++
+	if r.next == len(r.samples) {
+		r.next, r.full = 0, true
+	}
+	r.total++
+}
+
+func (r *latencyRing) stats() Stats {
+	r.mu.Lock()
+	n := r.next
+	if r.full {
+		n = len(r.samples)
+	}
+	sorted := slices.Clone(r.samples[:n])
+	total := r.total
+	r.mu.Unlock()
+
+	st := Stats{Commands: total}
+	if len(sorted) == 0 {
+		return st
+	}
+	slices.Sort(sorted)
+	// Nearest rank
+	rank := func(p int) time.Duration {
+		i := (p*len(sorted) + 99) / 100
+		return sorted[max(i, 1)-1]
+	}
+	st.P50, st.P90, st.P99 = rank(50), rank(90), rank(99)
+	st.Max = sorted[len(sorted)-1]
+	return st
+}
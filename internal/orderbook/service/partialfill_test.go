@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestServicePartialFillReports(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("limit partially fills and rests", func(t *testing.T) {
+		svc := NewService(DefaultConfig())
+		defer svc.Close()
+		svc.SubmitLimit(ctx, 1, core.SideSell, 100, 60)
+
+		report, err := svc.SubmitLimit(ctx, 2, core.SideBuy, 100, 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Remaining != 40 || report.RestedSize != 40 || !report.Rested {
+			t.Errorf("expected 40 remaining and resting, got %+v", report)
+		}
+
+		time.Sleep(10 * time.Millisecond) // wait for event dispatcher
+		bids := svc.GetLevels(core.SideBuy)
+		if len(bids) != 1 || bids[0].Size != 40 {
+			t.Errorf("expected 40 resting on the bid in the view, got %+v", bids)
+		}
+		if asks := svc.GetLevels(core.SideSell); len(asks) != 0 {
+			t.Errorf("expected asks to be consumed, got %+v", asks)
+		}
+	})
+
+	t.Run("limit fully fills", func(t *testing.T) {
+		svc := NewService(DefaultConfig())
+		defer svc.Close()
+		svc.SubmitLimit(ctx, 1, core.SideSell, 100, 60)
+
+		report, err := svc.SubmitLimit(ctx, 2, core.SideBuy, 100, 60)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Remaining != 0 || report.RestedSize != 0 || report.Rested {
+			t.Errorf("expected nothing left, got %+v", report)
+		}
+	})
+
+	t.Run("market partially fills and dies", func(t *testing.T) {
+		svc := NewService(DefaultConfig())
+		defer svc.Close()
+		svc.SubmitLimit(ctx, 1, core.SideSell, 100, 60)
+
+		report, err := svc.SubmitMarket(ctx, 2, core.SideBuy, 100)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Remaining != 40 || report.RestedSize != 0 || report.Rested {
+			t.Errorf("expected 40 unfilled and nothing resting, got %+v", report)
+		}
+
+		time.Sleep(10 * time.Millisecond) // wait for event dispatcher
+		if bids := svc.GetLevels(core.SideBuy); len(bids) != 0 {
+			t.Errorf("expected market remainder not to rest, got %+v", bids)
+		}
+	})
+	t.Run("IOC limit partially fills and drops the rest", func(t *testing.T) {
+		var events []core.Event
+		cfg := DefaultConfig()
+		cfg.Synchronous = true
+		cfg.OnEvent = func(ev core.Event) { events = append(events, ev) }
+		svc := NewService(cfg)
+		defer svc.Close()
+		svc.SubmitLimit(ctx, 1, core.SideSell, 100, 60)
+		events = nil
+
+		report, err := svc.SubmitLimitTIF(ctx, 2, core.SideBuy, 100, 100, core.TIFIOC)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Remaining != 40 || report.RestedSize != 0 || report.Rested || len(report.Fills) != 1 {
+			t.Errorf("expected 60 filled and 40 dropped, got %+v", report)
+		}
+		for _, ev := range events {
+			if _, ok := ev.(core.OrderRestedEvent); ok {
+				t.Errorf("expected no rested event, got %+v", ev)
+			}
+		}
+		if bids := svc.GetLevels(core.SideBuy); len(bids) != 0 {
+			t.Errorf("expected IOC remainder not to rest, got %+v", bids)
+		}
+	})
+	t.Run("FOK that cannot fill leaves the book untouched", func(t *testing.T) {
+		var events []core.Event
+		cfg := DefaultConfig()
+		cfg.Synchronous = true
+		cfg.OnEvent = func(ev core.Event) { events = append(events, ev) }
+		svc := NewService(cfg)
+		defer svc.Close()
+		svc.SubmitLimit(ctx, 1, core.SideBuy, 98, 20)
+		svc.SubmitLimit(ctx, 1, core.SideSell, 100, 30)
+		svc.SubmitLimit(ctx, 1, core.SideSell, 101, 30)
+		bestBid, _ := svc.GetBest(core.SideBuy)
+		bestAsk, _ := svc.GetBest(core.SideSell)
+		events = nil
+
+		// 60 rests within 101, one short
+		report, err := svc.SubmitLimitTIF(ctx, 2, core.SideBuy, 101, 61, core.TIFFOK)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if report.Remaining != 61 || report.Rested || len(report.Fills) != 0 {
+			t.Errorf("expected the FOK killed whole, got %+v", report)
+		}
+		if len(events) != 0 {
+			t.Errorf("expected no events, got %+v", events)
+		}
+		if bid, _ := svc.GetBest(core.SideBuy); bid != bestBid {
+			t.Errorf("expected best bid %+v, got %+v", bestBid, bid)
+		}
+		if ask, _ := svc.GetBest(core.SideSell); ask != bestAsk {
+			t.Errorf("expected best ask %+v, got %+v", bestAsk, ask)
+		}
+		if asks := svc.GetLevels(core.SideSell); len(asks) != 2 || asks[1].Size != 30 {
+			t.Errorf("expected both ask levels whole, got %+v", asks)
+		}
+
+		// 60 fills completely across both levels
+		if report, _ := svc.SubmitLimitTIF(ctx, 2, core.SideBuy, 101, 60, core.TIFFOK); report.Remaining != 0 || len(report.Fills) != 2 {
+			t.Errorf("expected 60 filled, got %+v", report)
+		}
+	})
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// TradeRecorderConfig holds configuration for a TradeRecorder.
+type TradeRecorderConfig struct {
+	// Tape is the rotating file the events are written to, one codec line
+	// each (see TapeWriterConfig). Give each book its own Path.
+	Tape TapeWriterConfig
+	// Buffer is how many events can wait for the writer before Record
+	// starts dropping them.
+	Buffer int
+}
+
+// DefaultTradeRecorderConfig returns a TradeRecorderConfig writing to path.
+func DefaultTradeRecorderConfig(path string) TradeRecorderConfig {
+	return TradeRecorderConfig{
+		Tape:   TapeWriterConfig{Path: path, MaxBytes: 64 << 20},
+		Buffer: 4096,
+	}
+}
+
+// recorderItem is an event to write, or a flush request when done is set.
+type recorderItem struct {
+	ev   core.Event
+	done chan error
+}
+
+// TradeRecorder logs every event a book emits to disk on its own goroutine,
+// so a slow disk drops events instead of stalling the book. Read the log
+// back with replay.LoadEvents; a log with drops (see Dropped) cannot
+// rebuild the book exactly.
+type TradeRecorder struct {
+	tw    *TapeWriter
+	items chan recorderItem
+
+	written atomic.Int64
+	dropped atomic.Int64
+
+	mu     sync.RWMutex
+	closed bool
+	done   chan struct{}
+}
+
+// NewTradeRecorder opens the log and starts its writer.
+func NewTradeRecorder(cfg TradeRecorderConfig) (*TradeRecorder, error) {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = DefaultTradeRecorderConfig("").Buffer
+	}
+	tw, err := NewTapeWriter(cfg.Tape)
+	if err != nil {
+		return nil, err
+	}
+	r := &TradeRecorder{
+		tw:    tw,
+		items: make(chan recorderItem, cfg.Buffer),
+		done:  make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+func (r *TradeRecorder) run() {
+	defer close(r.done)
+	for it := range r.items {
+		if it.done != nil {
+			it.done <- r.tw.Flush()
+			continue
+		}
+		// The tape writer keeps its first error; later events are lost
+		if r.tw.WriteEvent(it.ev) == nil {
+			r.written.Add(1)
+		}
+	}
+}
+
+// Record queues an event for writing, dropping it if the writer is behind
+// or the recorder is closed.
+func (r *TradeRecorder) Record(ev core.Event) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		r.dropped.Add(1)
+		return
+	}
+	select {
+	case r.items <- recorderItem{ev: ev}:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Flush waits for the events queued so far to be written and flushed to the
+// file.
+func (r *TradeRecorder) Flush() error {
+	r.mu.RLock()
+	if r.closed {
+		r.mu.RUnlock()
+		return r.tw.Err()
+	}
+	done := make(chan error, 1)
+	r.items <- recorderItem{done: done}
+	r.mu.RUnlock()
+	return <-done
+}
+
+// Written returns the number of events written.
+func (r *TradeRecorder) Written() int64 {
+	return r.written.Load()
+}
+
+// Dropped returns the number of events dropped because the writer fell
+// behind or the recorder was closed.
+func (r *TradeRecorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Err returns the first write error, if any.
+func (r *TradeRecorder) Err() error {
+	return r.tw.Err()
+}
+
+// Close writes the queued events and closes the file.
+func (r *TradeRecorder) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.items)
+	}
+	r.mu.Unlock()
+	<-r.done
+	return r.tw.Close()
+}
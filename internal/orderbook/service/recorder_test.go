@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/orderbook/replay"
+	"github.com/zappabad/stockcraft/internal/orderbook/view"
+)
+
+func TestTradeRecorderReplayRebuildsLevels(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.jsonl")
+	rec, err := NewTradeRecorder(DefaultTradeRecorderConfig(path))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.Recorder = rec
+	cfg.Synchronous = true
+	svc := NewService(cfg)
+	ctx := context.Background()
+
+	for i, price := range []core.PriceTicks{99, 98, 98, 97} {
+		svc.SubmitLimit(ctx, core.UserID(i+1), core.SideBuy, price, 10)
+	}
+	for i, price := range []core.PriceTicks{101, 102, 102} {
+		svc.SubmitLimit(ctx, core.UserID(i+10), core.SideSell, price, 5)
+	}
+	svc.SubmitMarket(ctx, 20, core.SideSell, 14)
+	svc.SubmitLimit(ctx, 21, core.SideBuy, 101, 7)
+	canceled, _ := svc.SubmitLimit(ctx, 22, core.SideSell, 105, 3)
+	svc.Cancel(ctx, canceled.OrderID)
+
+	bids, asks, trades := svc.GetLevels(core.SideBuy), svc.GetLevels(core.SideSell), svc.GetTradesLast(100)
+	svc.Close()
+	if err := rec.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Dropped() != 0 {
+		t.Fatalf("expected nothing dropped, got %d", rec.Dropped())
+	}
+
+	events, err := replay.LoadEvents(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if int64(len(events)) != rec.Written() {
+		t.Errorf("expected %d events read back, got %d", rec.Written(), len(events))
+	}
+	v := view.NewBookView(100)
+	v.Apply(core.OrderRestedEvent{OrderID: 999, UserID: 1, Side: core.SideBuy, Price: 50, Size: 1, Time: 1})
+	v.Rebuild(events)
+	if got := v.Levels(core.SideBuy); !slices.Equal(got, bids) {
+		t.Errorf("expected bids %+v, got %+v", bids, got)
+	}
+	if got := v.Levels(core.SideSell); !slices.Equal(got, asks) {
+		t.Errorf("expected asks %+v, got %+v", asks, got)
+	}
+	if got := v.TradesLast(100); len(trades) == 0 || !slices.Equal(got, trades) {
+		t.Errorf("expected the tape %+v, got %+v", trades, got)
+	}
+}
+
+func TestTradeRecorderRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "book.jsonl")
+	cfg := DefaultTradeRecorderConfig(path)
+	cfg.Tape.MaxRecords = 5
+	rec, err := NewTradeRecorder(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := range 12 {
+		rec.Record(core.OrderRestedEvent{OrderID: core.OrderID(i + 1), UserID: 1, Side: core.SideSell, Price: 100, Size: 1, Time: int64(i + 1)})
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	rec.Record(core.OrderRestedEvent{OrderID: 13})
+	if rec.Written() != 12 || rec.Dropped() != 1 {
+		t.Errorf("expected 12 written and the event after Close dropped, got %d and %d", rec.Written(), rec.Dropped())
+	}
+
+	if segs, _ := replay.Segments(path); len(segs) != 2 {
+		t.Errorf("expected 2 rotated segments, got %v", segs)
+	}
+	events, err := replay.LoadEvents(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 12 {
+		t.Fatalf("expected 12 events, got %d", len(events))
+	}
+	for i, ev := range events {
+		if e, ok := ev.(core.OrderRestedEvent); !ok || e.OrderID != core.OrderID(i+1) {
+			t.Errorf("event %d out of order: %+v", i, ev)
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/zappabad/stockcraft/internal/clock"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 	"github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/internal/pubsub"
 )
 
 // command types
@@ -17,37 +19,72 @@ const (
 	cmdSubmitLimit cmdType = iota
 	cmdSubmitMarket
 	cmdCancel
+	cmdBeginAuction
+	cmdUncross
+	cmdCancelAll
+	cmdRestore
+	cmdResolveCross
+	cmdModify
+	cmdCancelUser
+	cmdSubmitStop
+	cmdReduce
 )
 
 type command struct {
-	typ    cmdType
-	userID core.UserID
-	side   core.Side
-	price  core.PriceTicks
-	size   core.Size
-	id     core.OrderID // for cancel
-	respCh chan<- response
+	typ     cmdType
+	userID  core.UserID
+	side    core.Side
+	price   core.PriceTicks
+	size    core.Size
+	opts    LimitOptions    // for limit orders
+	id      core.OrderID    // for cancel, modify and reduce
+	trigger core.PriceTicks // for stops
+	expire  int64           // for stops
+	orders  []core.Order    // for restore
+	policy  core.CrossPolicy
+	respCh  chan<- response
+
+	submitted time.Time // set when latency is recorded
 }
 
 type response struct {
-	submitReport core.SubmitReport
-	cancelReport core.CancelReport
-	err          error
+	submitReport  core.SubmitReport
+	cancelReport  core.CancelReport
+	amendReport   core.AmendReport
+	uncrossReport core.UncrossReport
+	cancelAll     []core.CancelReport
+	crossReport   core.CrossReport
+	stopID        core.OrderID
+	err           error
 }
 
 // Service owns the orderbook core and view, providing thread-safe access.
 type Service struct {
-	cfg  Config
-	core *core.Core
-	view *view.BookView
+	cfg   Config
+	core  *core.Core
+	view  *view.BookView
+	clock clock.Clock
+
+	// latency is nil unless Config.RecordLatency is set.
+	latency *latencyRing
 
 	idGen atomic.Int64
 
 	cmdCh          chan command
 	internalEvents chan core.Event
-	externalEvents chan core.Event
 
-	droppedExternal atomic.Int64
+	// bus fans events out after the view applies them; external is the
+	// subscription behind Events.
+	bus      *pubsub.Bus[core.Event]
+	external *pubsub.Subscription[core.Event]
+
+	// level2 is only touched by the dispatcher (or under syncMu).
+	level2    *view.Level2
+	level2Bus *pubsub.Bus[view.LevelDelta]
+
+	// syncMu serializes commands in synchronous mode, in place of the
+	// command processor.
+	syncMu sync.Mutex
 
 	closed    chan struct{}
 	closeOnce sync.Once
@@ -68,23 +105,48 @@ func NewService(cfg Config) *Service {
 	if cfg.ExternalEventBuffer <= 0 {
 		cfg.ExternalEventBuffer = DefaultConfig().ExternalEventBuffer
 	}
+	if cfg.LatencySamples <= 0 {
+		cfg.LatencySamples = DefaultConfig().LatencySamples
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
 
 	s := &Service{
 		cfg:            cfg,
-		core:           core.NewCore(),
-		view:           view.NewBookView(cfg.TradeTapeSize),
+		core:           core.NewCoreWithConfig(core.CoreConfig{SelfTradePrevention: cfg.SelfTradePrevention}),
+		view:           view.NewBookViewSized(cfg.TradeTapeSize, cfg.ExpectedBook),
+		clock:          cfg.Clock,
 		cmdCh:          make(chan command, cfg.CommandBuffer),
 		internalEvents: make(chan core.Event, cfg.EventBuffer),
-		externalEvents: make(chan core.Event, cfg.ExternalEventBuffer),
+		bus:            pubsub.New[core.Event](),
+		level2:         view.NewLevel2(cfg.Level2),
+		level2Bus:      pubsub.New[view.LevelDelta](),
 		closed:         make(chan struct{}),
 	}
+	policy := pubsub.Block
+	if cfg.DropExternalEvents || cfg.Synchronous {
+		policy = pubsub.Drop
+	}
+	s.external = s.bus.Subscribe(policy, cfg.ExternalEventBuffer)
+	if cfg.RecordLatency {
+		s.latency = newLatencyRing(cfg.LatencySamples)
+	}
 
 	// Initialize ID generator from current time
-	s.idGen.Store(time.Now().UnixNano())
+	s.idGen.Store(s.clock.Now().UnixNano())
+
+	if cfg.Synchronous {
+		return s
+	}
 
 	// Start command processor
+	var expiry clock.Ticker
+	if cfg.StopExpiryInterval > 0 {
+		expiry = s.clock.NewTicker(cfg.StopExpiryInterval)
+	}
 	s.wg.Add(1)
-	go s.runCommandProcessor()
+	go s.runCommandProcessor(expiry)
 
 	// Start event dispatcher
 	s.wg.Add(1)
@@ -97,15 +159,25 @@ func (s *Service) nextID() core.OrderID {
 	return core.OrderID(s.idGen.Add(1))
 }
 
-func (s *Service) runCommandProcessor() {
+// runCommandProcessor runs commands, and expires stops on each tick of
+// expiry if it is set.
+func (s *Service) runCommandProcessor(expiry clock.Ticker) {
 	defer s.wg.Done()
 
+	var tick <-chan time.Time
+	if expiry != nil {
+		defer expiry.Stop()
+		tick = expiry.C()
+	}
+
 	for {
 		select {
 		case <-s.closed:
 			return
 		case cmd := <-s.cmdCh:
 			s.processCommand(cmd)
+		case <-tick:
+			s.expireStops()
 		}
 	}
 }
@@ -113,16 +185,22 @@ func (s *Service) runCommandProcessor() {
 func (s *Service) processCommand(cmd command) {
 	var resp response
 
+	// Stops past their expiry go before the command can trigger them
+	s.expireStops()
+
 	switch cmd.typ {
 	case cmdSubmitLimit:
 		o := core.Order{
-			ID:     s.nextID(),
-			UserID: cmd.userID,
-			Side:   cmd.side,
-			Kind:   core.OrderKindLimit,
-			Price:  cmd.price,
-			Size:   cmd.size,
-			Time:   time.Now().UnixNano(),
+			ID:          s.nextID(),
+			UserID:      cmd.userID,
+			Side:        cmd.side,
+			Kind:        core.OrderKindLimit,
+			Price:       cmd.price,
+			Size:        cmd.size,
+			Time:        s.now(),
+			TIF:         cmd.opts.TIF,
+			PostOnly:    cmd.opts.PostOnly,
+			DisplaySize: cmd.opts.DisplaySize,
 		}
 		report, events, err := s.core.SubmitLimit(o)
 		resp = response{submitReport: report, err: err}
@@ -137,7 +215,7 @@ func (s *Service) processCommand(cmd command) {
 			Side:   cmd.side,
 			Kind:   core.OrderKindMarket,
 			Size:   cmd.size,
-			Time:   time.Now().UnixNano(),
+			Time:   s.now(),
 		}
 		report, events, err := s.core.SubmitMarket(o)
 		resp = response{submitReport: report, err: err}
@@ -146,11 +224,89 @@ func (s *Service) processCommand(cmd command) {
 		}
 
 	case cmdCancel:
-		report, events, err := s.core.Cancel(cmd.id, time.Now().UnixNano())
+		report, events, err := s.core.Cancel(cmd.id, s.now())
 		resp = response{cancelReport: report, err: err}
 		for _, ev := range events {
 			s.emitEvent(ev)
 		}
+
+	case cmdModify:
+		report, events, err := s.core.Amend(cmd.id, cmd.price, cmd.size, s.now())
+		resp = response{amendReport: report, err: err}
+		for _, ev := range events {
+			s.emitEvent(ev)
+		}
+
+	case cmdReduce:
+		report, events, err := s.core.Reduce(cmd.id, cmd.size, s.now())
+		resp = response{amendReport: report, err: err}
+		for _, ev := range events {
+			s.emitEvent(ev)
+		}
+
+	case cmdBeginAuction:
+		s.core.BeginAuction()
+
+	case cmdUncross:
+		now := s.now()
+		report, events := s.core.Uncross(now)
+		resp = response{uncrossReport: report}
+		for _, ev := range events {
+			s.emitEvent(ev)
+		}
+		resp.crossReport = s.resolveCross(s.cfg.CrossPolicy, now)
+
+	case cmdRestore:
+		events, err := s.core.Restore(cmd.orders)
+		resp = response{err: err}
+		for _, ev := range events {
+			s.emitEvent(ev)
+		}
+		if err == nil {
+			s.reserveIDs(cmd.orders)
+			resp.crossReport = s.resolveCross(s.cfg.CrossPolicy, s.now())
+		}
+
+	case cmdResolveCross:
+		resp = response{crossReport: s.resolveCross(cmd.policy, s.now())}
+
+	case cmdCancelAll:
+		reports, events := s.core.CancelAll(s.now())
+		resp = response{cancelAll: reports}
+		for _, ev := range events {
+			s.emitEvent(ev)
+		}
+
+	case cmdCancelUser:
+		reports, events := s.core.CancelUser(cmd.userID, s.now())
+		resp = response{cancelAll: reports}
+		for _, ev := range events {
+			s.emitEvent(ev)
+		}
+
+	case cmdSubmitStop:
+		o := core.Order{
+			ID:        s.nextID(),
+			UserID:    cmd.userID,
+			Side:      cmd.side,
+			Kind:      core.OrderKindStop,
+			Price:     cmd.price,
+			Size:      cmd.size,
+			Time:      s.now(),
+			StopPrice: cmd.trigger,
+			ExpireAt:  cmd.expire,
+		}
+		events, err := s.core.SubmitStop(o)
+		resp = response{stopID: o.ID, err: err}
+		for _, ev := range events {
+			s.emitEvent(ev)
+		}
+	}
+
+	if s.latency != nil && !cmd.submitted.IsZero() {
+		lat := s.clock.Now().Sub(cmd.submitted)
+		s.latency.record(lat)
+		resp.submitReport.Latency = lat
 	}
 
 	if cmd.respCh != nil {
@@ -158,7 +314,62 @@ func (s *Service) processCommand(cmd command) {
 	}
 }
 
+// now returns the clock's time for orders and events.
+func (s *Service) now() int64 {
+	return s.clock.Now().UnixNano()
+}
+
+// Stats returns command latency statistics. It is zero unless
+// Config.RecordLatency is set.
+func (s *Service) Stats() Stats {
+	if s.latency == nil {
+		return Stats{}
+	}
+	return s.latency.stats()
+}
+
+// resolveCross repairs a crossed book and emits the resulting events.
+// Must run on the command processor goroutine, or under syncMu.
+func (s *Service) resolveCross(policy core.CrossPolicy, now int64) core.CrossReport {
+	report, events := s.core.ResolveCross(policy, now)
+	for _, ev := range events {
+		s.emitEvent(ev)
+	}
+	return report
+}
+
+// reserveIDs moves the ID generator past restored order IDs so new orders
+// cannot collide with them.
+func (s *Service) reserveIDs(orders []core.Order) {
+	for _, o := range orders {
+		s.ReserveOrderIDs(o.ID)
+	}
+}
+
+// LastOrderID returns the highest order ID the service has assigned or
+// reserved.
+func (s *Service) LastOrderID() core.OrderID {
+	return core.OrderID(s.idGen.Load())
+}
+
+// ReserveOrderIDs makes the service assign IDs above id from now on, as when
+// resuming a checkpoint whose filled orders used IDs no resting order holds.
+// It never moves the counter back.
+func (s *Service) ReserveOrderIDs(id core.OrderID) {
+	for {
+		cur := s.idGen.Load()
+		if int64(id) <= cur || s.idGen.CompareAndSwap(cur, int64(id)) {
+			return
+		}
+	}
+}
+
 func (s *Service) emitEvent(ev core.Event) {
+	if s.cfg.Synchronous {
+		s.dispatch(ev)
+		return
+	}
+
 	// Always send to internal channel (blocking is ok, buffer should be sufficient)
 	select {
 	case s.internalEvents <- ev:
@@ -169,118 +380,240 @@ func (s *Service) emitEvent(ev core.Event) {
 
 func (s *Service) runEventDispatcher() {
 	defer s.wg.Done()
-	defer close(s.externalEvents)
+	defer s.bus.Close()
+	defer s.level2Bus.Close()
 
 	for {
 		select {
 		case <-s.closed:
 			return
 		case ev := <-s.internalEvents:
-			// Always update view (authoritative)
-			s.view.Apply(ev)
-
-			// Attempt to send to external channel
-			if s.cfg.DropExternalEvents {
-				select {
-				case s.externalEvents <- ev:
-				default:
-					s.droppedExternal.Add(1)
-				}
-			} else {
-				select {
-				case s.externalEvents <- ev:
-				case <-s.closed:
-					return
-				}
+			if !s.dispatch(ev) {
+				return
 			}
 		}
 	}
 }
 
-// SubmitLimit submits a limit order.
-func (s *Service) SubmitLimit(ctx context.Context, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size) (core.SubmitReport, error) {
-	respCh := make(chan response, 1)
-	cmd := command{
-		typ:    cmdSubmitLimit,
-		userID: userID,
-		side:   side,
-		price:  price,
-		size:   size,
-		respCh: respCh,
+// dispatch applies an event to the view and passes it on to the recorder,
+// tape writer, audit log, OnEvent and subscribers. It returns false once the bus has closed.
+func (s *Service) dispatch(ev core.Event) bool {
+	// Always update view (authoritative)
+	s.view.Apply(ev)
+
+	if s.cfg.Recorder != nil {
+		s.cfg.Recorder.Record(ev)
 	}
 
-	select {
-	case <-s.closed:
-		return core.SubmitReport{}, context.Canceled
-	case <-ctx.Done():
-		return core.SubmitReport{}, ctx.Err()
-	case s.cmdCh <- cmd:
+	// Persist trades if a tape writer is attached, and audit their fills
+	if tr, ok := ev.(core.TradeEvent); ok {
+		if s.cfg.TapeWriter != nil {
+			s.cfg.TapeWriter.WriteTrade(tr)
+		}
+		if s.cfg.Audit != nil {
+			s.audit(tr)
+		}
 	}
 
-	select {
-	case <-s.closed:
-		return core.SubmitReport{}, context.Canceled
-	case <-ctx.Done():
-		return core.SubmitReport{}, ctx.Err()
-	case resp := <-respCh:
-		return resp.submitReport, resp.err
+	if s.cfg.OnEvent != nil {
+		s.cfg.OnEvent(ev)
+	}
+
+	// Level deltas are only worked out while someone listens; a first
+	// subscriber then gets the whole depth on each side's next change.
+	if s.level2Bus.Len() > 0 {
+		for _, d := range s.level2.Update(s.view, ev) {
+			s.level2Bus.Publish(d)
+		}
+	} else {
+		s.level2.Reset()
+	}
+
+	return s.bus.Publish(ev)
+}
+
+// SubmitLimit submits a GTC limit order.
+func (s *Service) SubmitLimit(ctx context.Context, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size) (core.SubmitReport, error) {
+	return s.SubmitLimitTIF(ctx, userID, side, price, size, core.TIFGTC)
+}
+
+// SubmitLimitTIF submits a limit order with a time in force (see
+// core.Core.SubmitLimit).
+func (s *Service) SubmitLimitTIF(ctx context.Context, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size, tif core.TimeInForce) (core.SubmitReport, error) {
+	return s.SubmitLimitWith(ctx, userID, side, price, size, LimitOptions{TIF: tif})
+}
+
+// LimitOptions are the optional attributes of a limit order. The zero value
+// is a plain GTC order.
+type LimitOptions struct {
+	TIF core.TimeInForce
+	// PostOnly rejects the order with core.ErrWouldCross instead of letting
+	// it take liquidity. It must be GTC.
+	PostOnly bool
+	// DisplaySize makes a GTC order an iceberg showing this much at a time
+	// (see core.Order.DisplaySize). 0 shows the whole order.
+	DisplaySize core.Size
+}
+
+// SubmitLimitWith submits a limit order with options (see
+// core.Core.SubmitLimit).
+func (s *Service) SubmitLimitWith(ctx context.Context, userID core.UserID, side core.Side, price core.PriceTicks, size core.Size, opts LimitOptions) (core.SubmitReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdSubmitLimit, userID: userID, side: side, price: price, size: size, opts: opts})
+	if err != nil {
+		return core.SubmitReport{}, err
 	}
+	return resp.submitReport, resp.err
 }
 
 // SubmitMarket submits a market order.
 func (s *Service) SubmitMarket(ctx context.Context, userID core.UserID, side core.Side, size core.Size) (core.SubmitReport, error) {
-	respCh := make(chan response, 1)
-	cmd := command{
-		typ:    cmdSubmitMarket,
-		userID: userID,
-		side:   side,
-		size:   size,
-		respCh: respCh,
+	resp, err := s.do(ctx, command{typ: cmdSubmitMarket, userID: userID, side: side, size: size})
+	if err != nil {
+		return core.SubmitReport{}, err
 	}
+	return resp.submitReport, resp.err
+}
 
-	select {
-	case <-s.closed:
-		return core.SubmitReport{}, context.Canceled
-	case <-ctx.Done():
-		return core.SubmitReport{}, ctx.Err()
-	case s.cmdCh <- cmd:
+// Cancel cancels a resting order or a pending stop.
+func (s *Service) Cancel(ctx context.Context, id core.OrderID) (core.CancelReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdCancel, id: id})
+	if err != nil {
+		return core.CancelReport{}, err
 	}
+	return resp.cancelReport, resp.err
+}
 
-	select {
-	case <-s.closed:
-		return core.SubmitReport{}, context.Canceled
-	case <-ctx.Done():
-		return core.SubmitReport{}, ctx.Err()
-	case resp := <-respCh:
-		return resp.submitReport, resp.err
+// Modify changes a resting order's price and/or size (see core.Core.Amend).
+// Reducing the size at the same price keeps the order's time priority; any
+// other change re-queues it at the current time, matching first if the new
+// price crosses.
+func (s *Service) Modify(ctx context.Context, id core.OrderID, newPrice core.PriceTicks, newSize core.Size) (core.AmendReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdModify, id: id, price: newPrice, size: newSize})
+	if err != nil {
+		return core.AmendReport{}, err
 	}
+	return resp.amendReport, resp.err
 }
 
-// Cancel cancels a resting order.
-func (s *Service) Cancel(ctx context.Context, id core.OrderID) (core.CancelReport, error) {
+// Reduce takes by off a resting order's size, keeping its place in the
+// queue (see core.Core.Reduce). The report's Remaining is the new size.
+func (s *Service) Reduce(ctx context.Context, id core.OrderID, by core.Size) (core.AmendReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdReduce, id: id, size: by})
+	if err != nil {
+		return core.AmendReport{}, err
+	}
+	return resp.amendReport, resp.err
+}
+
+// BeginAuction disables matching until Uncross. Limit orders rest without
+// matching; market orders are rejected with core.ErrAuction.
+func (s *Service) BeginAuction(ctx context.Context) error {
+	_, err := s.do(ctx, command{typ: cmdBeginAuction})
+	return err
+}
+
+// Uncross runs the auction uncross and re-enables continuous matching.
+func (s *Service) Uncross(ctx context.Context) (core.UncrossReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdUncross})
+	if err != nil {
+		return core.UncrossReport{}, err
+	}
+	return resp.uncrossReport, resp.err
+}
+
+// Restore rests orders without matching, as when reloading a journal, then
+// repairs any cross it leaves with the configured CrossPolicy. Orders keep
+// their IDs and times. Nothing is restored if any order is invalid or
+// duplicates an ID.
+func (s *Service) Restore(ctx context.Context, orders []core.Order) (core.CrossReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdRestore, orders: orders})
+	if err != nil {
+		return core.CrossReport{}, err
+	}
+	return resp.crossReport, resp.err
+}
+
+// ResolveCross repairs a crossed book with the given policy. It does nothing
+// if the book is not crossed or an auction is running.
+func (s *Service) ResolveCross(ctx context.Context, policy core.CrossPolicy) (core.CrossReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdResolveCross, policy: policy})
+	if err != nil {
+		return core.CrossReport{}, err
+	}
+	return resp.crossReport, resp.err
+}
+
+// CancelAll cancels every resting order in the book, then every pending
+// stop.
+func (s *Service) CancelAll(ctx context.Context) ([]core.CancelReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdCancelAll})
+	if err != nil {
+		return nil, err
+	}
+	return resp.cancelAll, resp.err
+}
+
+// CancelAllForUser cancels every resting order and pending stop of one user
+// in a single command, so no order of theirs can rest between the lookup and
+// the cancels.
+func (s *Service) CancelAllForUser(ctx context.Context, userID core.UserID) ([]core.CancelReport, error) {
+	resp, err := s.do(ctx, command{typ: cmdCancelUser, userID: userID})
+	if err != nil {
+		return nil, err
+	}
+	return resp.cancelAll, resp.err
+}
+
+// CancelUser is CancelAllForUser.
+func (s *Service) CancelUser(ctx context.Context, userID core.UserID) ([]core.CancelReport, error) {
+	return s.CancelAllForUser(ctx, userID)
+}
+
+// do sends a command and waits for its response.
+func (s *Service) do(ctx context.Context, cmd command) (response, error) {
 	respCh := make(chan response, 1)
-	cmd := command{
-		typ:    cmdCancel,
-		id:     id,
-		respCh: respCh,
+	cmd.respCh = respCh
+	if s.latency != nil {
+		cmd.submitted = s.clock.Now()
+	}
+
+	if s.cfg.Synchronous {
+		return s.doSync(ctx, cmd, respCh)
 	}
 
 	select {
 	case <-s.closed:
-		return core.CancelReport{}, context.Canceled
+		return response{}, context.Canceled
 	case <-ctx.Done():
-		return core.CancelReport{}, ctx.Err()
+		return response{}, ctx.Err()
 	case s.cmdCh <- cmd:
 	}
 
 	select {
 	case <-s.closed:
-		return core.CancelReport{}, context.Canceled
+		return response{}, context.Canceled
 	case <-ctx.Done():
-		return core.CancelReport{}, ctx.Err()
+		return response{}, ctx.Err()
 	case resp := <-respCh:
-		return resp.cancelReport, resp.err
+		return resp, nil
+	}
+}
+
+// doSync runs a command on the calling goroutine. Its events reach the view
+// before it returns.
+func (s *Service) doSync(ctx context.Context, cmd command, respCh <-chan response) (response, error) {
+	if err := ctx.Err(); err != nil {
+		return response{}, err
+	}
+	s.syncMu.Lock()
+	defer s.syncMu.Unlock()
+	select {
+	case <-s.closed:
+		return response{}, context.Canceled
+	default:
 	}
+	s.processCommand(cmd)
+	return <-respCh, nil
 }
 
 // GetLevels returns aggregate levels for a side (from view).
@@ -288,30 +621,144 @@ func (s *Service) GetLevels(side core.Side) []view.Level {
 	return s.view.Levels(side)
 }
 
+// GetLevelsTopN returns at most the n best levels for a side (from view).
+func (s *Service) GetLevelsTopN(side core.Side, n int) []view.Level {
+	return s.view.LevelsTopN(side, n)
+}
+
+// GetBest returns the best level on a side (from view).
+func (s *Service) GetBest(side core.Side) (view.Level, bool) {
+	return s.view.Best(side)
+}
+
+// EstimateMarket estimates what a market order would fill now (from view).
+func (s *Service) EstimateMarket(side core.Side, size core.Size) view.Estimate {
+	return s.view.EstimateMarket(side, size)
+}
+
+// EstimateLimit estimates what a limit order would fill on arrival (from
+// view).
+func (s *Service) EstimateLimit(side core.Side, price core.PriceTicks, size core.Size) view.Estimate {
+	return s.view.EstimateLimit(side, price, size)
+}
+
+// GetLevelsBucketed returns levels for a side aggregated into price buckets
+// (from view).
+func (s *Service) GetLevelsBucketed(side core.Side, bucket core.PriceTicks) []view.Level {
+	return s.view.LevelsBucketed(side, bucket)
+}
+
+// GetDepthSnapshot returns at most maxLevels of a side's best levels, each
+// with its orders in time priority (from view).
+func (s *Service) GetDepthSnapshot(side core.Side, maxLevels int) []view.LevelDetail {
+	return s.view.DepthSnapshot(side, maxLevels)
+}
+
 // GetOrders returns resting orders for a side (from view).
 func (s *Service) GetOrders(side core.Side) []view.RestingOrder {
 	return s.view.Orders(side)
 }
 
+// GetOrder returns a resting order by ID (from view).
+func (s *Service) GetOrder(id core.OrderID) (view.RestingOrder, bool) {
+	return s.view.Order(id)
+}
+
+// GetOrdersByUser returns a user's resting orders (from view).
+func (s *Service) GetOrdersByUser(userID core.UserID) []view.RestingOrder {
+	return s.view.OrdersByUser(userID)
+}
+
+// GetUserOrders is GetOrdersByUser.
+func (s *Service) GetUserOrders(userID core.UserID) []view.RestingOrder {
+	return s.GetOrdersByUser(userID)
+}
+
 // GetTradesLast returns the last n trades (from view).
 func (s *Service) GetTradesLast(n int) []core.TradeEvent {
 	return s.view.TradesLast(n)
 }
 
-// Events returns the external events channel for subscribers.
+// GetVWAPLast returns the VWAP of the last n trades (from view).
+func (s *Service) GetVWAPLast(n int) (core.PriceTicks, bool) {
+	return s.view.VWAPLast(n)
+}
+
+// GetOrderCount returns the number of resting orders on a side (from view).
+func (s *Service) GetOrderCount(side core.Side) int {
+	return s.view.OrderCount(side)
+}
+
+// GetUserExposure returns a user's resting exposure (from view).
+func (s *Service) GetUserExposure(userID core.UserID) view.Exposure {
+	return s.view.UserExposure(userID)
+}
+
+// QueueDepths reports how many items are waiting in a service's channels.
+type QueueDepths struct {
+	Commands int
+	Events   int
+	External int
+}
+
+// QueueDepths returns the current depth of the command and event channels.
+func (s *Service) QueueDepths() QueueDepths {
+	return QueueDepths{
+		Commands: len(s.cmdCh),
+		Events:   len(s.internalEvents),
+		External: s.external.Len(),
+	}
+}
+
+// Events returns the external events channel, sized by
+// Config.ExternalEventBuffer with the Config.DropExternalEvents policy.
 func (s *Service) Events() <-chan core.Event {
-	return s.externalEvents
+	return s.external.C()
+}
+
+// Subscribe adds an independent subscriber to the service's events, each
+// delivered after the view applies it. Its channel closes on Unsubscribe or
+// Close. A Block subscriber that stops reading stalls the book's event
+// dispatch, and in synchronous mode its commands.
+func (s *Service) Subscribe(policy pubsub.Policy, buffer int) *pubsub.Subscription[core.Event] {
+	return s.bus.Subscribe(policy, buffer)
+}
+
+// SubscribeLevel2 adds a subscriber to level deltas within Config.Level2's
+// depth. Read GetLevels after subscribing for the starting book; deltas
+// carry absolute sizes, so overlap with it is harmless.
+func (s *Service) SubscribeLevel2(policy pubsub.Policy, buffer int) *pubsub.Subscription[view.LevelDelta] {
+	return s.level2Bus.Subscribe(policy, buffer)
 }
 
-// DroppedExternalEvents returns the count of dropped external events.
+// DroppedExternalEvents returns the count of events dropped from Events.
 func (s *Service) DroppedExternalEvents() int64 {
-	return s.droppedExternal.Load()
+	return s.external.Dropped()
 }
 
-// Close shuts down the service and waits for goroutines to finish.
+// Close shuts down the service, closing every subscription, and waits for
+// goroutines to finish.
 func (s *Service) Close() {
 	s.closeOnce.Do(func() {
 		close(s.closed)
 	})
+	// Releases a dispatch blocked on a subscriber
+	s.bus.Close()
+	s.level2Bus.Close()
+	if s.cfg.Synchronous {
+		// Wait out a running command
+		s.syncMu.Lock()
+		s.syncMu.Unlock()
+	}
 	s.wg.Wait()
+
+	if s.cfg.Recorder != nil {
+		s.cfg.Recorder.Flush()
+	}
+	if s.cfg.TapeWriter != nil {
+		s.cfg.TapeWriter.Flush()
+	}
+	if s.cfg.Audit != nil {
+		s.cfg.Audit.Flush()
+	}
 }
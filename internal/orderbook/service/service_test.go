@@ -6,7 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/zappabad/stockcraft/internal/clock"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/internal/pubsub"
 )
 
 func TestServiceBasic(t *testing.T) {
@@ -110,6 +113,124 @@ func TestServiceCancel(t *testing.T) {
 	}
 }
 
+func TestServiceModify(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewService(cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	first, _ := svc.SubmitLimit(ctx, 1, core.SideBuy, 100, 10)
+	second, _ := svc.SubmitLimit(ctx, 2, core.SideBuy, 100, 10)
+
+	// Reducing at the same price keeps the first order ahead
+	report, err := svc.Modify(ctx, first.OrderID, 100, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.KeptPriority || report.Remaining != 4 {
+		t.Errorf("expected an in-place reduce to 4, got %+v", report)
+	}
+	if orders := svc.GetOrders(core.SideBuy); len(orders) != 2 || orders[0].ID != first.OrderID || orders[0].Size != 4 {
+		t.Errorf("expected order %d first with 4, got %+v", first.OrderID, orders)
+	}
+	if levels := svc.GetLevels(core.SideBuy); len(levels) != 1 || levels[0].Size != 14 {
+		t.Errorf("expected 14 at 100, got %+v", levels)
+	}
+
+	// Growing it loses priority behind the second order
+	if report, _ = svc.Modify(ctx, first.OrderID, 100, 6); report.KeptPriority {
+		t.Errorf("expected a size increase to lose priority, got %+v", report)
+	}
+	if orders := svc.GetOrders(core.SideBuy); len(orders) != 2 || orders[0].ID != second.OrderID {
+		t.Errorf("expected order %d to lead, got %+v", second.OrderID, orders)
+	}
+
+	// A new price moves it to its own level
+	if _, err := svc.Modify(ctx, second.OrderID, 99, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if levels := svc.GetLevels(core.SideBuy); len(levels) != 2 || levels[0].Size != 6 || levels[1].Price != 99 || levels[1].Size != 10 {
+		t.Errorf("expected 6 at 100 and 10 at 99, got %+v", levels)
+	}
+
+	if _, err := svc.Modify(ctx, 12345, 100, 1); err != core.ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestServiceDepthSnapshotFollowsMatching(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewService(cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	a, _ := svc.SubmitLimit(ctx, 1, core.SideSell, 100, 10)
+	b, _ := svc.SubmitLimit(ctx, 2, core.SideSell, 100, 5)
+	c, _ := svc.SubmitLimit(ctx, 3, core.SideSell, 100, 7)
+	svc.SubmitLimit(ctx, 4, core.SideSell, 101, 3)
+
+	// A partial fill takes from the front; the order keeps its place
+	svc.SubmitMarket(ctx, 9, core.SideBuy, 4)
+	svc.Cancel(ctx, b.OrderID)
+
+	levels := svc.GetDepthSnapshot(core.SideSell, 1)
+	if len(levels) != 1 || levels[0].Price != 100 || levels[0].TotalSize != 13 {
+		t.Fatalf("expected 13 at 100, got %+v", levels)
+	}
+	if q := levels[0].Orders; len(q) != 2 || q[0].ID != a.OrderID || q[0].Size != 6 || q[1].ID != c.OrderID {
+		t.Errorf("expected orders %d (6) then %d, got %+v", a.OrderID, c.OrderID, q)
+	}
+}
+
+func TestServiceCancelAllForUser(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewService(cfg)
+	defer svc.Close()
+	ctx := context.Background()
+
+	sub := svc.Subscribe(pubsub.Block, 16)
+	defer sub.Unsubscribe()
+
+	a, _ := svc.SubmitLimit(ctx, 1, core.SideBuy, 100, 10)
+	b, _ := svc.SubmitLimit(ctx, 1, core.SideSell, 105, 4)
+	other, _ := svc.SubmitLimit(ctx, 2, core.SideBuy, 99, 3)
+	for range 3 {
+		<-sub.C()
+	}
+
+	reports, err := svc.CancelAllForUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reports) != 2 || reports[0].OrderID != a.OrderID || reports[1].OrderID != b.OrderID {
+		t.Fatalf("expected orders %d and %d canceled, got %+v", a.OrderID, b.OrderID, reports)
+	}
+	for _, want := range []core.OrderID{a.OrderID, b.OrderID} {
+		ev := <-sub.C()
+		if rm, ok := ev.(core.OrderRemovedEvent); !ok || rm.OrderID != want || rm.Reason != core.RemoveReasonCanceled {
+			t.Errorf("expected order %d removed as canceled, got %+v", want, ev)
+		}
+	}
+
+	if orders := svc.GetOrdersByUser(1); len(orders) != 0 {
+		t.Errorf("expected user 1 to have no orders, got %+v", orders)
+	}
+	if orders := svc.GetOrdersByUser(2); len(orders) != 1 || orders[0].ID != other.OrderID {
+		t.Errorf("expected user 2's order to remain, got %+v", orders)
+	}
+	if orders := svc.GetUserOrders(2); len(orders) != 1 || orders[0].ID != other.OrderID {
+		t.Errorf("expected GetUserOrders to serve the same view, got %+v", orders)
+	}
+
+	// CancelUser is the same command
+	if reports, err := svc.CancelUser(ctx, 2); err != nil || len(reports) != 1 || reports[0].OrderID != other.OrderID {
+		t.Errorf("expected CancelUser to cancel user 2's order, got %+v (%v)", reports, err)
+	}
+}
+
 func TestServiceEvents(t *testing.T) {
 	cfg := DefaultConfig()
 	svc := NewService(cfg)
@@ -136,3 +257,218 @@ func TestServiceEvents(t *testing.T) {
 		t.Error("timeout waiting for event")
 	}
 }
+
+func TestServiceUserExposure(t *testing.T) {
+	svc := NewService(DefaultConfig())
+	defer svc.Close()
+
+	ctx := context.Background()
+	svc.SubmitLimit(ctx, 1, core.SideBuy, 100, 10)
+	svc.SubmitLimit(ctx, 1, core.SideBuy, 99, 5)
+	svc.SubmitLimit(ctx, 1, core.SideSell, 110, 3)
+	svc.SubmitLimit(ctx, 2, core.SideSell, 120, 50)
+
+	// Partial fill reduces user 1's sell exposure
+	if _, err := svc.SubmitMarket(ctx, 3, core.SideBuy, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(10 * time.Millisecond) // wait for event dispatcher
+	ex := svc.GetUserExposure(1)
+	if ex.BuySize != 15 || ex.BuyNotional != 100*10+99*5 {
+		t.Errorf("unexpected buy exposure: %+v", ex)
+	}
+	if ex.SellSize != 2 || ex.SellNotional != 110*2 {
+		t.Errorf("unexpected sell exposure: %+v", ex)
+	}
+	if ex.Orders != 3 {
+		t.Errorf("expected 3 orders, got %d", ex.Orders)
+	}
+	if other := svc.GetUserExposure(2); other.SellSize != 50 || other.BuySize != 0 {
+		t.Errorf("unexpected exposure for user 2: %+v", other)
+	}
+}
+
+func TestServiceRestoreResolvesCross(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.CrossPolicy = core.CrossPolicyAuction
+	svc := NewService(cfg)
+	defer svc.Close()
+
+	ctx := context.Background()
+	report, err := svc.Restore(ctx, []core.Order{
+		{ID: 1, UserID: 100, Side: core.SideBuy, Kind: core.OrderKindLimit, Price: 102, Size: 5, Time: 1000},
+		{ID: 2, UserID: 101, Side: core.SideSell, Kind: core.OrderKindLimit, Price: 99, Size: 3, Time: 1001},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Policy != core.CrossPolicyAuction || report.Volume != 3 {
+		t.Errorf("expected the restore to be uncrossed by auction, got %+v", report)
+	}
+
+	var resolved bool
+	timeout := time.After(time.Second)
+	for !resolved {
+		select {
+		case ev := <-svc.Events():
+			if e, ok := ev.(core.CrossResolvedEvent); ok {
+				resolved = e.Volume == 3
+			}
+		case <-timeout:
+			t.Fatal("expected a CrossResolvedEvent")
+		}
+	}
+
+	time.Sleep(10 * time.Millisecond) // wait for event dispatcher
+	if bids := svc.GetLevels(core.SideBuy); len(bids) != 1 || bids[0].Size != 2 {
+		t.Errorf("expected 2 left on the bid, got %+v", bids)
+	}
+	if asks := svc.GetLevels(core.SideSell); len(asks) != 0 {
+		t.Errorf("expected the ask to be filled, got %+v", asks)
+	}
+
+	// New orders get IDs past the restored ones
+	next, err := svc.SubmitLimit(ctx, 102, core.SideSell, 110, 1)
+	if err != nil || next.OrderID <= 2 {
+		t.Errorf("expected a fresh order ID, got %d (%v)", next.OrderID, err)
+	}
+}
+
+func TestServiceSynchronous(t *testing.T) {
+	var events []core.Event
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.OnEvent = func(ev core.Event) { events = append(events, ev) }
+	svc := NewService(cfg)
+
+	ctx := context.Background()
+	if _, err := svc.SubmitLimit(ctx, 1, core.SideSell, 100, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SubmitMarket(ctx, 2, core.SideBuy, 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// No waiting: the view and hook saw everything before the calls returned
+	if levels := svc.GetLevels(core.SideSell); len(levels) != 1 || levels[0].Size != 6 {
+		t.Errorf("expected 6 left at 100, got %+v", levels)
+	}
+	if trades := svc.GetTradesLast(1); len(trades) != 1 || trades[0].Size != 4 {
+		t.Errorf("expected a trade of 4, got %+v", trades)
+	}
+	if len(events) != len(svc.Events()) || len(events) == 0 {
+		t.Errorf("expected each event on the hook and channel, got %d and %d", len(events), len(svc.Events()))
+	}
+
+	svc.Close()
+	svc.Close()
+	if _, err := svc.SubmitLimit(ctx, 1, core.SideBuy, 99, 1); err != context.Canceled {
+		t.Errorf("expected context.Canceled after Close, got %v", err)
+	}
+	for range svc.Events() {
+	}
+}
+
+func TestServiceReportsLatency(t *testing.T) {
+	// Each event handled costs 5ms of simulated processing time
+	clk := clock.NewManual(time.Unix(1, 0))
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Clock = clk
+	cfg.RecordLatency = true
+	var events int
+	cfg.OnEvent = func(core.Event) { events++; clk.Advance(5 * time.Millisecond) }
+	svc := NewService(cfg)
+	defer svc.Close()
+
+	ctx := context.Background()
+	report, err := svc.SubmitLimit(ctx, 1, core.SideSell, 100, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if events != 1 || report.Latency != 5*time.Millisecond {
+		t.Errorf("expected 5ms for one event, got %v for %d", report.Latency, events)
+	}
+	if st := svc.Stats(); st.Commands != 1 || st.P50 != 5*time.Millisecond || st.P99 != 5*time.Millisecond || st.Max != 5*time.Millisecond {
+		t.Errorf("unexpected stats after one submit: %+v", st)
+	}
+
+	events = 0
+	report, err = svc.SubmitMarket(ctx, 2, core.SideBuy, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := time.Duration(events) * 5 * time.Millisecond; events == 0 || report.Latency != want {
+		t.Errorf("expected %v for %d events, got %v", want, events, report.Latency)
+	}
+	if st := svc.Stats(); st.Commands != 2 || st.Max != report.Latency || st.P50 != 5*time.Millisecond {
+		t.Errorf("unexpected stats after two submits: %+v", st)
+	}
+}
+
+func TestServiceLevel2DepthCap(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Level2.Depth = 2
+	svc := NewService(cfg)
+	defer svc.Close()
+	sub := svc.SubscribeLevel2(pubsub.Drop, 64)
+
+	ctx := context.Background()
+	submit := func(price core.PriceTicks, size core.Size) core.OrderID {
+		t.Helper()
+		report, err := svc.SubmitLimit(ctx, 1, core.SideBuy, price, size)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return report.OrderID
+	}
+	expect := func(want ...view.LevelDelta) {
+		t.Helper()
+		var got []view.LevelDelta
+		for sub.Len() > 0 {
+			d := <-sub.C()
+			d.Time = 0
+			got = append(got, d)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("expected %v, got %v", want, got)
+			}
+		}
+	}
+	bid := func(price core.PriceTicks, size core.Size) view.LevelDelta {
+		return view.LevelDelta{Side: core.SideBuy, Price: price, Size: size}
+	}
+
+	best := submit(100, 10)
+	expect(bid(100, 10))
+	submit(99, 5)
+	expect(bid(99, 5))
+
+	// Beyond the top two nothing is sent
+	submit(98, 5)
+	submit(97, 5)
+	submit(98, 3)
+	expect()
+
+	// Inside it every change is
+	submit(99, 1)
+	expect(bid(99, 6))
+
+	// Emptying the best level pulls 98 into the depth with its full size
+	if _, err := svc.Cancel(ctx, best); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect(bid(100, 0), bid(98, 8))
+
+	// Fills come through one maker at a time
+	if _, err := svc.SubmitMarket(ctx, 2, core.SideSell, 7); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expect(bid(99, 1), bid(99, 0), bid(97, 5), bid(98, 7))
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/orderbook/view"
+)
+
+// SubmitStop parks a stop order in the core until a later trade reaches
+// triggerPrice: at or above it for a buy stop, at or below it for a sell
+// stop. It then becomes a market order, or a GTC limit order at limitPrice
+// if that is set, under the ID SubmitStop returns (see core.Core.OnTrade).
+// Stops a single trade triggers fire in trigger order, nearest the move
+// first, with buys before sells; trades of a triggered order can trigger
+// further stops in the same command. Cancel, CancelAll and CancelAllForUser
+// also cancel stops.
+func (s *Service) SubmitStop(ctx context.Context, userID core.UserID, side core.Side, triggerPrice core.PriceTicks, size core.Size, limitPrice core.PriceTicks) (core.OrderID, error) {
+	return s.SubmitStopWith(ctx, userID, side, triggerPrice, size, StopOptions{LimitPrice: limitPrice})
+}
+
+// StopOptions are the optional attributes of a stop order. The zero value
+// is a stop-market order that waits until it triggers or is canceled.
+type StopOptions struct {
+	// LimitPrice makes the triggered order a GTC limit order at this price.
+	// 0 makes it a market order.
+	LimitPrice core.PriceTicks
+	// ExpireAt, in unix nanos, expires the stop with a
+	// core.StopExpiredEvent if it has not triggered by then; it must be
+	// after the submit. 0 never expires it. A stop-limit that triggers
+	// first rests GTC.
+	ExpireAt int64
+}
+
+// SubmitStopWith submits a stop order with options (see SubmitStop).
+// Expired stops leave before the next command runs, or on the next
+// Config.StopExpiryInterval tick.
+func (s *Service) SubmitStopWith(ctx context.Context, userID core.UserID, side core.Side, triggerPrice core.PriceTicks, size core.Size, opts StopOptions) (core.OrderID, error) {
+	limitPrice := opts.LimitPrice
+	switch {
+	case userID == 0, side != core.SideBuy && side != core.SideSell:
+		return 0, core.ErrInvalidOrder
+	case triggerPrice <= 0:
+		return 0, fmt.Errorf("%w: trigger price must be positive", core.ErrInvalidOrder)
+	case size <= 0:
+		return 0, fmt.Errorf("%w: size must be positive", core.ErrInvalidOrder)
+	case limitPrice < 0:
+		return 0, fmt.Errorf("%w: limit price cannot be negative", core.ErrInvalidOrder)
+	case opts.ExpireAt < 0:
+		return 0, fmt.Errorf("%w: expiry cannot be negative", core.ErrInvalidOrder)
+	}
+	resp, err := s.do(ctx, command{typ: cmdSubmitStop, userID: userID, side: side, price: limitPrice, trigger: triggerPrice, size: size, expire: opts.ExpireAt})
+	if err != nil {
+		return 0, err
+	}
+	return resp.stopID, resp.err
+}
+
+// expireStops emits the expiry of stops past their ExpireAt. Must run on the
+// command processor goroutine, or under syncMu.
+func (s *Service) expireStops() {
+	for _, ev := range s.core.ExpireStops(s.now()) {
+		s.emitEvent(ev)
+	}
+}
+
+// GetStops returns the pending stop orders (from view).
+func (s *Service) GetStops() []view.PendingStop {
+	return s.view.Stops()
+}
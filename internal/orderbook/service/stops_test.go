@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/pubsub"
+)
+
+func newStopTestService(t *testing.T) (*Service, *pubsub.Subscription[core.Event]) {
+	t.Helper()
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	svc := NewService(cfg)
+	t.Cleanup(svc.Close)
+	sub := svc.Subscribe(pubsub.Block, 256)
+	t.Cleanup(sub.Unsubscribe)
+	return svc, sub
+}
+
+// drain returns the events delivered so far.
+func drain(sub *pubsub.Subscription[core.Event]) []core.Event {
+	var out []core.Event
+	for {
+		select {
+		case ev := <-sub.C():
+			out = append(out, ev)
+		default:
+			return out
+		}
+	}
+}
+
+func TestServiceStopTriggersOnTrade(t *testing.T) {
+	svc, sub := newStopTestService(t)
+	ctx := context.Background()
+
+	svc.SubmitLimit(ctx, 1, core.SideBuy, 100, 5)
+	svc.SubmitLimit(ctx, 1, core.SideBuy, 98, 10)
+	stop, err := svc.SubmitStop(ctx, 2, core.SideSell, 99, 8, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stops := svc.GetStops(); len(stops) != 1 || stops[0].ID != stop || stops[0].TriggerPrice != 99 {
+		t.Fatalf("expected the stop pending, got %+v", stops)
+	}
+
+	// A trade above the trigger leaves it parked
+	svc.SubmitMarket(ctx, 3, core.SideSell, 2)
+	if len(svc.GetStops()) != 1 {
+		t.Fatal("expected a trade at 100 not to trigger a sell stop at 99")
+	}
+	drain(sub)
+
+	// Selling through 100 reaches 98, which triggers it
+	svc.SubmitMarket(ctx, 3, core.SideSell, 4)
+	if len(svc.GetStops()) != 0 {
+		t.Fatalf("expected the stop triggered, got %+v", svc.GetStops())
+	}
+	var triggered bool
+	var sold core.Size
+	for _, ev := range drain(sub) {
+		switch e := ev.(type) {
+		case core.StopTriggeredEvent:
+			if e.StopID != stop || e.TradePrice != 98 {
+				t.Errorf("expected stop %d triggered at 98, got %+v", stop, e)
+			}
+			triggered = true
+		case core.TradeEvent:
+			if e.TakerOrderID == stop {
+				if !triggered {
+					t.Error("expected the trigger before the stop's fills")
+				}
+				sold += e.Size
+			}
+		}
+	}
+	if !triggered || sold != 8 {
+		t.Errorf("expected the stop to sell 8 as a market order, sold %d", sold)
+	}
+	if bids := svc.GetLevels(core.SideBuy); len(bids) != 1 || bids[0].Size != 1 {
+		t.Errorf("expected 1 left at 98, got %+v", bids)
+	}
+}
+
+func TestServiceStopsFireInTriggerOrder(t *testing.T) {
+	svc, sub := newStopTestService(t)
+	ctx := context.Background()
+
+	svc.SubmitLimit(ctx, 1, core.SideBuy, 95, 100)
+	svc.SubmitLimit(ctx, 1, core.SideSell, 101, 100)
+	far, _ := svc.SubmitStop(ctx, 2, core.SideSell, 97, 1, 0)
+	near, _ := svc.SubmitStop(ctx, 3, core.SideSell, 99, 1, 0)
+	tied, _ := svc.SubmitStop(ctx, 4, core.SideSell, 99, 1, 0)
+	buy, _ := svc.SubmitStop(ctx, 5, core.SideBuy, 101, 1, 0)
+	drain(sub)
+
+	// One trade at 95 triggers every sell stop: nearest first, ties in
+	// arrival order. The buy stop waits for 101.
+	svc.SubmitMarket(ctx, 6, core.SideSell, 1)
+	var order []core.OrderID
+	for _, ev := range drain(sub) {
+		if e, ok := ev.(core.StopTriggeredEvent); ok {
+			order = append(order, e.StopID)
+		}
+	}
+	if want := []core.OrderID{near, tied, far}; len(order) != 3 || order[0] != want[0] || order[1] != want[1] || order[2] != want[2] {
+		t.Errorf("expected stops %v to fire in that order, got %v", want, order)
+	}
+	if stops := svc.GetStops(); len(stops) != 1 || stops[0].ID != buy {
+		t.Errorf("expected only the buy stop pending, got %+v", stops)
+	}
+}
+
+func TestServiceStopLimitRests(t *testing.T) {
+	svc, _ := newStopTestService(t)
+	ctx := context.Background()
+
+	svc.SubmitLimit(ctx, 1, core.SideSell, 105, 3)
+	stop, _ := svc.SubmitStop(ctx, 2, core.SideBuy, 105, 10, 106)
+	svc.SubmitMarket(ctx, 3, core.SideBuy, 1)
+
+	// Buys 2 left at 105, then rests 8 at its limit under the stop's ID
+	o, ok := svc.GetOrder(stop)
+	if !ok || o.Price != 106 || o.Size != 8 {
+		t.Errorf("expected 8 resting at 106 as order %d, got %+v (%v)", stop, o, ok)
+	}
+}
+
+func TestServiceStopLimitActivation(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		limit     core.PriceTicks
+		wantPrice core.PriceTicks // of the stop's fills; 0 = none
+		wantRest  core.Size
+	}{
+		// The trigger trade took the only bid at 99, so the limit rests
+		{name: "rests", limit: 99, wantRest: 5},
+		// The bids at 98 beat the 97 limit: it fills there at once
+		{name: "fills better than limit", limit: 97, wantPrice: 98},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			svc, sub := newStopTestService(t)
+			ctx := context.Background()
+
+			svc.SubmitLimit(ctx, 1, core.SideBuy, 99, 1)
+			svc.SubmitLimit(ctx, 1, core.SideBuy, 98, 10)
+			stop, _ := svc.SubmitStop(ctx, 2, core.SideSell, 99, 5, tc.limit)
+			drain(sub)
+			svc.SubmitMarket(ctx, 3, core.SideSell, 1)
+
+			var filled core.Size
+			var rested *core.OrderRestedEvent
+			for _, ev := range drain(sub) {
+				switch e := ev.(type) {
+				case core.TradeEvent:
+					if e.TakerOrderID == stop {
+						if e.Price != tc.wantPrice {
+							t.Errorf("expected the stop to fill at %d, got %d", tc.wantPrice, e.Price)
+						}
+						filled += e.Size
+					}
+				case core.OrderRestedEvent:
+					if e.OrderID == stop {
+						rested = &e
+					}
+				}
+			}
+			if filled+tc.wantRest != 5 {
+				t.Errorf("expected %d filled, got %d", 5-tc.wantRest, filled)
+			}
+			if tc.wantRest == 0 {
+				if rested != nil {
+					t.Errorf("expected nothing to rest, got %+v", *rested)
+				}
+				return
+			}
+			if rested == nil || rested.Price != tc.limit || rested.Size != tc.wantRest {
+				t.Fatalf("expected %d to rest at %d, got %+v", tc.wantRest, tc.limit, rested)
+			}
+			if o, ok := svc.GetOrder(stop); !ok || o.Size != tc.wantRest {
+				t.Errorf("expected order %d on the book, got %+v (%v)", stop, o, ok)
+			}
+		})
+	}
+}
+
+func TestServiceCancelStop(t *testing.T) {
+	svc, sub := newStopTestService(t)
+	ctx := context.Background()
+
+	svc.SubmitLimit(ctx, 1, core.SideBuy, 100, 5)
+	stop, _ := svc.SubmitStop(ctx, 2, core.SideSell, 100, 5, 0)
+	other, _ := svc.SubmitStop(ctx, 3, core.SideSell, 90, 5, 0)
+	drain(sub)
+
+	report, err := svc.Cancel(ctx, stop)
+	if err != nil || report.OrderID != stop || report.CanceledSize != 5 {
+		t.Fatalf("expected stop %d canceled, got %+v (%v)", stop, report, err)
+	}
+	if evs := drain(sub); len(evs) != 1 {
+		t.Errorf("expected one cancel event, got %+v", evs)
+	} else if e, ok := evs[0].(core.StopCanceledEvent); !ok || e.StopID != stop {
+		t.Errorf("expected stop %d canceled, got %+v", stop, evs[0])
+	}
+	if _, err := svc.Cancel(ctx, stop); !errors.Is(err, core.ErrNotFound) {
+		t.Errorf("expected ErrNotFound canceling it again, got %v", err)
+	}
+
+	// The trade no longer triggers it
+	svc.SubmitMarket(ctx, 4, core.SideSell, 1)
+	if _, ok := svc.GetOrder(stop); ok {
+		t.Error("expected the canceled stop not to trade")
+	}
+	if reports, _ := svc.CancelAllForUser(ctx, 3); len(reports) != 1 || reports[0].OrderID != other {
+		t.Errorf("expected CancelAllForUser to cancel stop %d, got %+v", other, reports)
+	}
+	if stops := svc.GetStops(); len(stops) != 0 {
+		t.Errorf("expected no stops pending, got %+v", stops)
+	}
+}
+
+func TestServiceSubmitStopRejects(t *testing.T) {
+	svc, _ := newStopTestService(t)
+	ctx := context.Background()
+	for _, tc := range []struct {
+		side           core.Side
+		trigger, limit core.PriceTicks
+		size           core.Size
+	}{
+		{core.SideBuy, 0, 0, 1},
+		{core.SideBuy, 100, 0, 0},
+		{core.SideSell, 100, -1, 1},
+		{core.Side(9), 100, 0, 1},
+	} {
+		if _, err := svc.SubmitStop(ctx, 1, tc.side, tc.trigger, tc.size, tc.limit); !errors.Is(err, core.ErrInvalidOrder) {
+			t.Errorf("%+v: expected ErrInvalidOrder, got %v", tc, err)
+		}
+	}
+}
+
+func TestServiceStopExpires(t *testing.T) {
+	clk := clock.NewManual(time.Unix(100, 0))
+	cfg := DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Clock = clk
+	svc := NewService(cfg)
+	t.Cleanup(svc.Close)
+	sub := svc.Subscribe(pubsub.Block, 256)
+	t.Cleanup(sub.Unsubscribe)
+	ctx := context.Background()
+
+	expireAt := clk.Now().Add(time.Minute).UnixNano()
+	svc.SubmitLimit(ctx, 1, core.SideBuy, 100, 5)
+	stop, err := svc.SubmitStopWith(ctx, 2, core.SideSell, 100, 3, StopOptions{ExpireAt: expireAt})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stops := svc.GetStops(); len(stops) != 1 || stops[0].ExpireAt != expireAt {
+		t.Fatalf("expected the stop pending until %d, got %+v", expireAt, stops)
+	}
+	drain(sub)
+
+	// Past its expiry, a trade through the trigger finds it gone
+	clk.Advance(time.Minute)
+	svc.SubmitMarket(ctx, 3, core.SideSell, 1)
+	evs := drain(sub)
+	if len(evs) == 0 {
+		t.Fatal("expected events")
+	}
+	if e, ok := evs[0].(core.StopExpiredEvent); !ok || e.StopID != stop || e.ExpireAt != expireAt || e.Size != 3 {
+		t.Errorf("expected stop %d to expire before the trade, got %+v", stop, evs[0])
+	}
+	for _, ev := range evs {
+		if _, ok := ev.(core.StopTriggeredEvent); ok {
+			t.Errorf("expected the expired stop not to trigger, got %+v", ev)
+		}
+	}
+	if stops := svc.GetStops(); len(stops) != 0 {
+		t.Errorf("expected no stops pending, got %+v", stops)
+	}
+
+	// An expiry must be after the submit
+	if _, err := svc.SubmitStopWith(ctx, 2, core.SideSell, 90, 1, StopOptions{ExpireAt: clk.Now().UnixNano()}); !errors.Is(err, core.ErrInvalidOrder) {
+		t.Errorf("expected ErrInvalidOrder for a past expiry, got %v", err)
+	}
+}
+
+func TestServiceStopExpiresOnTick(t *testing.T) {
+	clk := clock.NewManual(time.Unix(100, 0))
+	cfg := DefaultConfig()
+	cfg.Clock = clk
+	cfg.StopExpiryInterval = time.Second
+	svc := NewService(cfg)
+	t.Cleanup(svc.Close)
+	sub := svc.Subscribe(pubsub.Block, 256)
+	t.Cleanup(sub.Unsubscribe)
+	ctx := context.Background()
+
+	stop, err := svc.SubmitStopWith(ctx, 2, core.SideSell, 100, 3, StopOptions{ExpireAt: clk.Now().Add(2 * time.Second).UnixNano()})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// No command runs: the processor's tick expires it
+	clk.Advance(2 * time.Second)
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-sub.C():
+			if e, ok := ev.(core.StopExpiredEvent); ok {
+				if e.StopID != stop {
+					t.Errorf("expected stop %d to expire, got %+v", stop, e)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("expected the stop to expire on the tick")
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"sync"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/codec"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/orderbook/replay"
+)
+
+// TapeWriterConfig holds configuration for a TapeWriter.
+type TapeWriterConfig struct {
+	// Path is the active tape file. Rotated segments are kept next to it as
+	// Path.1, Path.2, ... with higher numbers being newer.
+	Path string
+	// MaxBytes rotates the active file once it reaches this size (0 = no limit).
+	MaxBytes int64
+	// MaxRecords rotates the active file after this many records (0 = no limit).
+	MaxRecords int
+	// MaxSegments is the number of rotated segments to keep (0 = keep all).
+	MaxSegments int
+}
+
+// TapeWriter appends trades, or any events, to a rotating file in the codec
+// format.
+// It is safe for concurrent use, so one writer can be shared by several books.
+type TapeWriter struct {
+	mu      sync.Mutex
+	cfg     TapeWriterConfig
+	f       *os.File
+	w       *bufio.Writer
+	enc     *codec.Encoder
+	bytes   int64
+	records int
+	nextSeg int
+	err     error
+}
+
+// NewTapeWriter opens (or creates) the active tape file for appending.
+func NewTapeWriter(cfg TapeWriterConfig) (*TapeWriter, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("tape writer: empty path")
+	}
+	segs, err := replay.Segments(cfg.Path)
+	if err != nil {
+		return nil, err
+	}
+	tw := &TapeWriter{cfg: cfg, nextSeg: 1}
+	if len(segs) > 0 {
+		tw.nextSeg = segs[len(segs)-1] + 1
+	}
+	if err := tw.open(); err != nil {
+		return nil, err
+	}
+	return tw, nil
+}
+
+func (tw *TapeWriter) open() error {
+	f, err := os.OpenFile(tw.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	tw.f = f
+	tw.w = bufio.NewWriter(f)
+	tw.enc = codec.NewEncoder(tw.w)
+	tw.bytes = info.Size()
+	tw.records = 0
+	return nil
+}
+
+// WriteTrade appends a trade, rotating first if the active file is full.
+// After the first failure every call returns that error.
+func (tw *TapeWriter) WriteTrade(tr core.TradeEvent) error {
+	return tw.WriteEvent(tr)
+}
+
+// WriteEvent appends an event (see WriteTrade).
+func (tw *TapeWriter) WriteEvent(ev core.Event) error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+
+	if tw.err != nil {
+		return tw.err
+	}
+	if tw.full() {
+		if err := tw.rotate(); err != nil {
+			tw.err = err
+			return err
+		}
+	}
+	n, err := tw.enc.Encode(ev)
+	if err != nil {
+		tw.err = err
+		return err
+	}
+	tw.bytes += int64(n)
+	tw.records++
+	return nil
+}
+
+func (tw *TapeWriter) full() bool {
+	if tw.cfg.MaxBytes > 0 && tw.bytes >= tw.cfg.MaxBytes {
+		return true
+	}
+	if tw.cfg.MaxRecords > 0 && tw.records >= tw.cfg.MaxRecords {
+		return true
+	}
+	return false
+}
+
+func (tw *TapeWriter) rotate() error {
+	if err := tw.w.Flush(); err != nil {
+		return err
+	}
+	if err := tw.f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tw.cfg.Path, replay.SegmentName(tw.cfg.Path, tw.nextSeg)); err != nil {
+		return err
+	}
+	tw.nextSeg++
+	if err := tw.prune(); err != nil {
+		return err
+	}
+	return tw.open()
+}
+
+func (tw *TapeWriter) prune() error {
+	if tw.cfg.MaxSegments <= 0 {
+		return nil
+	}
+	segs, err := replay.Segments(tw.cfg.Path)
+	if err != nil {
+		return err
+	}
+	for len(segs) > tw.cfg.MaxSegments {
+		if err := os.Remove(replay.SegmentName(tw.cfg.Path, segs[0])); err != nil {
+			return err
+		}
+		segs = segs[1:]
+	}
+	return nil
+}
+
+// Flush writes buffered trades to the active file.
+func (tw *TapeWriter) Flush() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.err != nil {
+		return tw.err
+	}
+	return tw.w.Flush()
+}
+
+// Err returns the first write error, if any.
+func (tw *TapeWriter) Err() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	return tw.err
+}
+
+// Close flushes and closes the active file.
+func (tw *TapeWriter) Close() error {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	ferr := tw.w.Flush()
+	cerr := tw.f.Close()
+	if ferr != nil {
+		return ferr
+	}
+	return cerr
+}
+
+// ReadTape reads back every trade written to path, oldest segment first,
+// skipping any other events.
+func ReadTape(path string) ([]core.TradeEvent, error) {
+	events, err := replay.LoadEvents(path)
+	if err != nil {
+		return nil, err
+	}
+	var out []core.TradeEvent
+	for _, ev := range events {
+		if tr, ok := ev.(core.TradeEvent); ok {
+			out = append(out, tr)
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/orderbook/replay"
+)
+
+func TestTapeWriterRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tape.jsonl")
+	tw, err := NewTapeWriter(TapeWriterConfig{Path: path, MaxRecords: 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	numTrades := 25
+	for i := 0; i < numTrades; i++ {
+		tr := core.TradeEvent{
+			Price:        core.PriceTicks(100 + i),
+			Size:         core.Size(i + 1),
+			TakerSide:    core.SideBuy,
+			Time:         int64(1000 + i),
+			TakerOrderID: core.OrderID(2*i + 2),
+			MakerOrderID: core.OrderID(2*i + 1),
+		}
+		if err := tw.WriteTrade(tr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	segs, err := replay.Segments(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segs) != 2 {
+		t.Errorf("expected 2 rotated segments, got %d", len(segs))
+	}
+
+	trades, err := ReadTape(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trades) != numTrades {
+		t.Fatalf("expected %d trades, got %d", numTrades, len(trades))
+	}
+	for i, tr := range trades {
+		if tr.Price != core.PriceTicks(100+i) || tr.Size != core.Size(i+1) {
+			t.Errorf("trade %d out of order or corrupted: %+v", i, tr)
+		}
+	}
+}
+
+func TestTapeWriterMaxSegments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tape.jsonl")
+	tw, err := NewTapeWriter(TapeWriterConfig{Path: path, MaxRecords: 5, MaxSegments: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 0; i < 30; i++ {
+		tw.WriteTrade(core.TradeEvent{Price: core.PriceTicks(i + 1), Size: 1, Time: int64(i + 1)})
+	}
+	tw.Close()
+
+	trades, err := ReadTape(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 2 full segments + the active file
+	if len(trades) != 15 {
+		t.Fatalf("expected 15 retained trades, got %d", len(trades))
+	}
+	if trades[0].Price != 16 {
+		t.Errorf("expected oldest retained trade price 16, got %d", trades[0].Price)
+	}
+}
+
+func TestServiceTapeWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tape.jsonl")
+	tw, err := NewTapeWriter(TapeWriterConfig{Path: path})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer tw.Close()
+
+	cfg := DefaultConfig()
+	cfg.TapeWriter = tw
+	svc := NewService(cfg)
+
+	ctx := context.Background()
+	if _, err := svc.SubmitLimit(ctx, 1, core.SideSell, 100, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.SubmitMarket(ctx, 2, core.SideBuy, 4); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond) // wait for event dispatcher
+	svc.Close()
+
+	trades, err := ReadTape(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("expected 1 trade, got %d", len(trades))
+	}
+	if trades[0].Size != 4 || trades[0].Price != 100 {
+		t.Errorf("unexpected trade: %+v", trades[0])
+	}
+}
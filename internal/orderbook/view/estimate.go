@@ -0,0 +1,53 @@
+package view
+
+import "github.com/zappabad/stockcraft/internal/orderbook/core"
+
+// Estimate is what an incoming order would take from the book if it matched
+// now. It ignores self-trade prevention, so the player's own resting orders
+// count as liquidity.
+type Estimate struct {
+	Filled core.Size
+	// Levels is the number of price levels the order would reach.
+	Levels int
+	// Worst is the price of the last level reached; 0 when nothing fills.
+	Worst core.PriceTicks
+	// AvgPrice is the size-weighted fill price in ticks; 0 when nothing
+	// fills. It is a float so a sweep's notional cannot overflow.
+	AvgPrice float64
+}
+
+// EstimateMarket estimates a market order of size on side against the
+// opposite side of the book.
+func (v *BookView) EstimateMarket(side core.Side, size core.Size) Estimate {
+	return v.estimate(side, size, func(core.PriceTicks) bool { return true })
+}
+
+// EstimateLimit estimates the part of a limit order of size on side at price
+// that would match on arrival. Levels beyond the limit are not reached.
+func (v *BookView) EstimateLimit(side core.Side, price core.PriceTicks, size core.Size) Estimate {
+	return v.estimate(side, size, func(p core.PriceTicks) bool {
+		if side == core.SideBuy {
+			return p <= price
+		}
+		return p >= price
+	})
+}
+
+func (v *BookView) estimate(side core.Side, size core.Size, within func(core.PriceTicks) bool) Estimate {
+	var est Estimate
+	var notional float64
+	for _, l := range v.Levels(side.Opposite()) {
+		if est.Filled >= size || !within(l.Price) {
+			break
+		}
+		take := min(l.Size, size-est.Filled)
+		est.Filled += take
+		est.Levels++
+		est.Worst = l.Price
+		notional += float64(l.Price) * float64(take)
+	}
+	if est.Filled > 0 {
+		est.AvgPrice = notional / float64(est.Filled)
+	}
+	return est
+}
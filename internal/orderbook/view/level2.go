@@ -0,0 +1,102 @@
+package view
+
+import (
+	"sort"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// LevelDelta is a change to one aggregated price level. Size is the level's
+// new total, not the change, so applying a delta twice is harmless; 0 means
+// the level emptied or fell out of the tracked depth.
+type LevelDelta struct {
+	Side  core.Side
+	Price core.PriceTicks
+	Size  core.Size
+	Time  int64
+}
+
+// Level2Config configures level2 deltas.
+type Level2Config struct {
+	// Depth caps deltas to the best Depth levels on each side, so changes
+	// far from the market are not sent (0 = every level). A level moving
+	// into the depth, as one above it empties, is sent with its full size.
+	Depth int
+}
+
+// Level2 turns book events into level deltas by diffing a BookView's top
+// levels against those it last reported. It is not safe for concurrent use.
+type Level2 struct {
+	cfg  Level2Config
+	bids map[core.PriceTicks]core.Size
+	asks map[core.PriceTicks]core.Size
+}
+
+// NewLevel2 creates a Level2 that has reported nothing yet.
+func NewLevel2(cfg Level2Config) *Level2 {
+	return &Level2{cfg: cfg}
+}
+
+// Update returns the level changes an event, already applied to v, made
+// within the depth on its side, best price first. Trades return nothing:
+// their level changes arrive as the reduce, remove and refresh events that
+// follow.
+func (l *Level2) Update(v *BookView, ev core.Event) []LevelDelta {
+	var side core.Side
+	var t int64
+	switch e := ev.(type) {
+	case core.OrderRestedEvent:
+		side, t = e.Side, e.Time
+	case core.OrderReducedEvent:
+		side, t = e.Side, e.MatchTime
+	case core.OrderRemovedEvent:
+		side, t = e.Side, e.Time
+	case core.OrderRefreshedEvent:
+		side, t = e.Side, e.Time
+	default:
+		return nil
+	}
+
+	levels := v.Levels(side)
+	if l.cfg.Depth > 0 && len(levels) > l.cfg.Depth {
+		levels = levels[:l.cfg.Depth]
+	}
+
+	prev := l.asks
+	if side == core.SideBuy {
+		prev = l.bids
+	}
+	next := make(map[core.PriceTicks]core.Size, len(levels))
+	var out []LevelDelta
+	for _, lv := range levels {
+		next[lv.Price] = lv.Size
+		if prev[lv.Price] != lv.Size {
+			out = append(out, LevelDelta{Side: side, Price: lv.Price, Size: lv.Size, Time: t})
+		}
+	}
+	for p := range prev {
+		if _, ok := next[p]; !ok {
+			out = append(out, LevelDelta{Side: side, Price: p, Time: t})
+		}
+	}
+	if side == core.SideBuy {
+		l.bids = next
+	} else {
+		l.asks = next
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if side == core.SideBuy {
+			return out[i].Price > out[j].Price
+		}
+		return out[i].Price < out[j].Price
+	})
+	return out
+}
+
+// Reset forgets what was reported, so the next update on each side reports
+// every level in the depth.
+func (l *Level2) Reset() {
+	l.bids = nil
+	l.asks = nil
+}
@@ -1,9 +1,11 @@
 package view
 
 import (
+	"math"
 	"sort"
 	"sync"
 
+	"github.com/zappabad/stockcraft/internal/money"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 )
 
@@ -17,12 +19,44 @@ type RestingOrder struct {
 	Time   int64
 }
 
+// PendingStop is a snapshot of a stop order waiting for its trigger.
+type PendingStop struct {
+	ID           core.OrderID
+	UserID       core.UserID
+	Side         core.Side
+	TriggerPrice core.PriceTicks
+	LimitPrice   core.PriceTicks // 0 for a stop-market order
+	Size         core.Size
+	ExpireAt     int64 // 0 if it never expires
+	Time         int64
+}
+
 // Level represents aggregate size at a price level.
 type Level struct {
 	Price core.PriceTicks
 	Size  core.Size
 }
 
+// LevelDetail is a price level with its resting orders, front of the queue
+// first.
+type LevelDetail struct {
+	Price     core.PriceTicks
+	TotalSize core.Size
+	Orders    []RestingOrder
+}
+
+// Exposure is a user's resting size and notional on each side of the book.
+type Exposure struct {
+	BuySize      core.Size
+	SellSize     core.Size
+	BuyNotional  int64 // sum of price * size, in ticks
+	SellNotional int64
+	Orders       int
+	// NotionalOverflow is set when a notional did not fit in an int64; it
+	// is then clamped to the int64 range.
+	NotionalOverflow bool
+}
+
 type orderState struct {
 	userID core.UserID
 	side   core.Side
@@ -36,17 +70,38 @@ type orderState struct {
 type BookView struct {
 	mu     sync.RWMutex
 	orders map[core.OrderID]orderState
+	byUser map[core.UserID]map[core.OrderID]struct{}
 	bids   map[core.PriceTicks]core.Size
 	asks   map[core.PriceTicks]core.Size
+	stops  map[core.OrderID]PendingStop
 	tape   *TradeTape
 }
 
+// SizeHint is the book a BookView expects to hold. Its maps are allocated
+// at these sizes up front, so a burst of new orders and levels does not
+// rehash them while they grow. Zero fields grow on demand.
+type SizeHint struct {
+	// Orders is how many orders rest at once.
+	Orders int
+	// Levels is how many price levels each side holds.
+	Levels int
+	// Users is how many users have orders resting at once.
+	Users int
+}
+
 // NewBookView creates a new BookView with the given trade tape capacity.
 func NewBookView(tapeCapacity int) *BookView {
+	return NewBookViewSized(tapeCapacity, SizeHint{})
+}
+
+// NewBookViewSized creates a BookView with its maps presized for hint.
+func NewBookViewSized(tapeCapacity int, hint SizeHint) *BookView {
 	return &BookView{
-		orders: map[core.OrderID]orderState{},
-		bids:   map[core.PriceTicks]core.Size{},
-		asks:   map[core.PriceTicks]core.Size{},
+		orders: make(map[core.OrderID]orderState, hint.Orders),
+		byUser: make(map[core.UserID]map[core.OrderID]struct{}, hint.Users),
+		bids:   make(map[core.PriceTicks]core.Size, hint.Levels),
+		asks:   make(map[core.PriceTicks]core.Size, hint.Levels),
+		stops:  map[core.OrderID]PendingStop{},
 		tape:   NewTradeTape(tapeCapacity),
 	}
 }
@@ -68,6 +123,12 @@ func (v *BookView) Apply(ev core.Event) {
 			size:   e.Size,
 			time:   e.Time,
 		}
+		ids := v.byUser[e.UserID]
+		if ids == nil {
+			ids = map[core.OrderID]struct{}{}
+			v.byUser[e.UserID] = ids
+		}
+		ids[e.OrderID] = struct{}{}
 		if e.Side == core.SideBuy {
 			v.bids[e.Price] += e.Size
 		} else {
@@ -92,6 +153,10 @@ func (v *BookView) Apply(ev core.Event) {
 				delete(v.asks, st.price)
 			}
 		}
+		if e.Remaining <= 0 {
+			v.forget(e.OrderID, st.userID)
+			return
+		}
 		st.size = e.Remaining
 		v.orders[e.OrderID] = st
 
@@ -109,11 +174,89 @@ func (v *BookView) Apply(ev core.Event) {
 					delete(v.asks, st.price)
 				}
 			}
-			delete(v.orders, e.OrderID)
+			v.forget(e.OrderID, st.userID)
+		}
+
+	case core.OrderRefreshedEvent:
+		st, ok := v.orders[e.OrderID]
+		if !ok {
+			return
+		}
+		// The trade before it filled the old slice; swap in the new one
+		if st.side == core.SideBuy {
+			v.bids[st.price] += e.Size - st.size
+		} else {
+			v.asks[st.price] += e.Size - st.size
+		}
+		st.size = e.Size
+		st.time = e.Time
+		v.orders[e.OrderID] = st
+
+	case core.StopAcceptedEvent:
+		v.stops[e.StopID] = PendingStop{
+			ID:           e.StopID,
+			UserID:       e.UserID,
+			Side:         e.Side,
+			TriggerPrice: e.TriggerPrice,
+			LimitPrice:   e.LimitPrice,
+			Size:         e.Size,
+			ExpireAt:     e.ExpireAt,
+			Time:         e.Time,
+		}
+
+	case core.StopTriggeredEvent:
+		delete(v.stops, e.StopID)
+
+	case core.StopCanceledEvent:
+		delete(v.stops, e.StopID)
+
+	case core.StopExpiredEvent:
+		delete(v.stops, e.StopID)
+	}
+}
+
+// Rebuild empties the view, keeping its tape capacity, and applies events in
+// order, as when replaying a book's event log (see package replay).
+func (v *BookView) Rebuild(events []core.Event) {
+	v.mu.Lock()
+	clear(v.orders)
+	clear(v.byUser)
+	clear(v.bids)
+	clear(v.asks)
+	clear(v.stops)
+	v.tape = NewTradeTape(v.tape.size)
+	v.mu.Unlock()
+
+	for _, ev := range events {
+		v.Apply(ev)
+	}
+}
+
+// forget drops an order from the order map and its user's index entry,
+// deleting the user's entry once they have no orders left.
+func (v *BookView) forget(id core.OrderID, userID core.UserID) {
+	delete(v.orders, id)
+	if ids, ok := v.byUser[userID]; ok {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(v.byUser, userID)
 		}
 	}
 }
 
+// Stops returns the pending stop orders, by ID. Returns a copy.
+func (v *BookView) Stops() []PendingStop {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	out := make([]PendingStop, 0, len(v.stops))
+	for _, st := range v.stops {
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
 // Levels returns aggregate size at each price level, sorted best->worst.
 // Returns a copy (not internal references).
 func (v *BookView) Levels(side core.Side) []Level {
@@ -141,6 +284,184 @@ func (v *BookView) Levels(side core.Side) []Level {
 	return out
 }
 
+// LevelsTopN returns at most the n best levels on a side, best first. It
+// keeps only n levels while scanning the side, so a shallow read of a deep
+// book does not sort every level. Returns a copy (not internal references).
+func (v *BookView) LevelsTopN(side core.Side, n int) []Level {
+	if n <= 0 {
+		return []Level{}
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.topN(side, n)
+}
+
+// topN is LevelsTopN without the lock.
+func (v *BookView) topN(side core.Side, n int) []Level {
+	src := v.asks
+	if side == core.SideBuy {
+		src = v.bids
+	}
+	better := func(a, b core.PriceTicks) bool {
+		if side == core.SideBuy {
+			return a > b
+		}
+		return a < b
+	}
+
+	out := make([]Level, 0, min(n, len(src)))
+	for p, s := range src {
+		if len(out) == n && !better(p, out[n-1].Price) {
+			continue
+		}
+		i := sort.Search(len(out), func(i int) bool { return better(p, out[i].Price) })
+		if len(out) < n {
+			out = append(out, Level{})
+		}
+		copy(out[i+1:], out[i:len(out)-1])
+		out[i] = Level{Price: p, Size: s}
+	}
+	return out
+}
+
+// DepthSnapshot returns at most maxLevels of a side's best levels, best
+// first, each with its orders in time priority (0 = every level). It reads
+// the levels and orders under one lock, so each level's orders sum to its
+// TotalSize. Returns a copy (not internal references).
+func (v *BookView) DepthSnapshot(side core.Side, maxLevels int) []LevelDetail {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if maxLevels <= 0 {
+		maxLevels = len(v.bids) + len(v.asks)
+	}
+	levels := v.topN(side, maxLevels)
+	out := make([]LevelDetail, len(levels))
+	at := make(map[core.PriceTicks]int, len(levels))
+	for i, l := range levels {
+		out[i] = LevelDetail{Price: l.Price, TotalSize: l.Size}
+		at[l.Price] = i
+	}
+	for id, st := range v.orders {
+		if st.side != side {
+			continue
+		}
+		i, ok := at[st.price]
+		if !ok {
+			continue
+		}
+		out[i].Orders = append(out[i].Orders, RestingOrder{
+			ID:     id,
+			UserID: st.userID,
+			Side:   st.side,
+			Price:  st.price,
+			Size:   st.size,
+			Time:   st.time,
+		})
+	}
+	for _, l := range out {
+		sort.Slice(l.Orders, func(i, j int) bool {
+			if l.Orders[i].Time != l.Orders[j].Time {
+				return l.Orders[i].Time < l.Orders[j].Time
+			}
+			return l.Orders[i].ID < l.Orders[j].ID
+		})
+	}
+	return out
+}
+
+// Best returns the best level on a side without sorting the book, or false
+// if the side is empty.
+func (v *BookView) Best(side core.Side) (Level, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.best(side)
+}
+
+// BestBidAsk returns the best bid and ask, each with false if its side is
+// empty. Both are read together, so they are consistent with each other.
+func (v *BookView) BestBidAsk() (bid, ask Level, bidOK, askOK bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	bid, bidOK = v.best(core.SideBuy)
+	ask, askOK = v.best(core.SideSell)
+	return bid, ask, bidOK, askOK
+}
+
+// MidPrice returns the midpoint of the best bid and ask, rounded down, or
+// false unless both sides are quoted.
+func (v *BookView) MidPrice() (core.PriceTicks, bool) {
+	bid, ask, bidOK, askOK := v.BestBidAsk()
+	if !bidOK || !askOK {
+		return 0, false
+	}
+	return bid.Price + (ask.Price-bid.Price)/2, true
+}
+
+// Spread returns the best ask less the best bid in ticks, or false unless
+// both sides are quoted.
+func (v *BookView) Spread() (core.PriceTicks, bool) {
+	bid, ask, bidOK, askOK := v.BestBidAsk()
+	if !bidOK || !askOK {
+		return 0, false
+	}
+	return ask.Price - bid.Price, true
+}
+
+// best scans a side for its best level. Callers must hold v.mu.
+func (v *BookView) best(side core.Side) (Level, bool) {
+	src := v.asks
+	if side == core.SideBuy {
+		src = v.bids
+	}
+
+	var best Level
+	found := false
+	for p, s := range src {
+		if !found || (side == core.SideBuy && p > best.Price) || (side == core.SideSell && p < best.Price) {
+			best = Level{Price: p, Size: s}
+			found = true
+		}
+	}
+	return best, found
+}
+
+// LevelsBucketed returns levels for a side aggregated into price buckets of
+// the given width, best first. Bids round down and asks round up to the
+// bucket edge, so each bucket's price is the worst price in it. A bucket of
+// 1 or less returns the raw levels.
+func (v *BookView) LevelsBucketed(side core.Side, bucket core.PriceTicks) []Level {
+	levels := v.Levels(side)
+	if bucket <= 1 || len(levels) == 0 {
+		return levels
+	}
+
+	out := levels[:0]
+	for _, l := range levels {
+		p := BucketPrice(side, l.Price, bucket)
+		if n := len(out); n > 0 && out[n-1].Price == p {
+			out[n-1].Size += l.Size
+			continue
+		}
+		out = append(out, Level{Price: p, Size: l.Size})
+	}
+	return out
+}
+
+// BucketPrice is the price of the LevelsBucketed bucket that holds price on
+// a side. A bucket of 1 or less returns price.
+func BucketPrice(side core.Side, price, bucket core.PriceTicks) core.PriceTicks {
+	if bucket <= 1 {
+		return price
+	}
+	p := price / bucket * bucket
+	if side == core.SideSell && p < price {
+		p += bucket
+	}
+	return p
+}
+
 // Orders returns all resting orders on a side, sorted by price (best first), then time, then id.
 // Returns a copy (not internal references).
 func (v *BookView) Orders(side core.Side) []RestingOrder {
@@ -179,6 +500,66 @@ func (v *BookView) Orders(side core.Side) []RestingOrder {
 	return out
 }
 
+// Order returns a resting order by ID, or false if it is not on the book
+// (never rested, or filled or canceled since). Returns a copy (not internal
+// references).
+func (v *BookView) Order(id core.OrderID) (RestingOrder, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	st, ok := v.orders[id]
+	if !ok {
+		return RestingOrder{}, false
+	}
+	return RestingOrder{
+		ID:     id,
+		UserID: st.userID,
+		Side:   st.side,
+		Price:  st.price,
+		Size:   st.size,
+		Time:   st.time,
+	}, true
+}
+
+// OrdersByUser returns a user's resting orders, bids before asks, each side
+// sorted like Orders. It reads the per-user index rather than scanning the
+// book. Returns a copy (not internal references).
+func (v *BookView) OrdersByUser(userID core.UserID) []RestingOrder {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	ids := v.byUser[userID]
+	out := make([]RestingOrder, 0, len(ids))
+	for id := range ids {
+		st := v.orders[id]
+		out = append(out, RestingOrder{
+			ID:     id,
+			UserID: st.userID,
+			Side:   st.side,
+			Price:  st.price,
+			Size:   st.size,
+			Time:   st.time,
+		})
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Side != out[j].Side {
+			return out[i].Side == core.SideBuy
+		}
+		if out[i].Price != out[j].Price {
+			if out[i].Side == core.SideBuy {
+				return out[i].Price > out[j].Price
+			}
+			return out[i].Price < out[j].Price
+		}
+		if out[i].Time != out[j].Time {
+			return out[i].Time < out[j].Time
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out
+}
+
 // TradesLast returns the last n trades in chronological order.
 // Returns a copy (not internal references).
 func (v *BookView) TradesLast(n int) []core.TradeEvent {
@@ -186,3 +567,85 @@ func (v *BookView) TradesLast(n int) []core.TradeEvent {
 	defer v.mu.RUnlock()
 	return v.tape.Last(n)
 }
+
+// VWAPLast returns the volume-weighted average price of the last n trades
+// on the tape, sum(price × size) / sum(size), truncated toward zero. If the
+// notional overflows an int64 it is averaged in floating point instead. It
+// returns false when there are no trades or their total size is not
+// positive.
+func (v *BookView) VWAPLast(n int) (core.PriceTicks, bool) {
+	trades := v.TradesLast(n)
+	var notional, size int64
+	exact := true
+	for _, tr := range trades {
+		size += int64(tr.Size)
+		t, ok := money.MulChecked(int64(tr.Price), int64(tr.Size))
+		if ok {
+			notional, ok = money.AddChecked(notional, t)
+		}
+		if !ok {
+			exact = false
+		}
+	}
+	if size <= 0 {
+		return 0, false
+	}
+	if exact {
+		return core.PriceTicks(notional / size), true
+	}
+	var avg float64
+	for _, tr := range trades {
+		avg += float64(tr.Price) * (float64(tr.Size) / float64(size))
+	}
+	return core.PriceTicks(avg), true
+}
+
+// OrderCount returns the number of resting orders on a side.
+func (v *BookView) OrderCount(side core.Side) int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	n := 0
+	for _, st := range v.orders {
+		if st.side == side {
+			n++
+		}
+	}
+	return n
+}
+
+// UserExposure returns the total resting exposure of a user's orders.
+func (v *BookView) UserExposure(userID core.UserID) Exposure {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	var ex Exposure
+	for id := range v.byUser[userID] {
+		st := v.orders[id]
+		ex.Orders++
+		if st.side == core.SideBuy {
+			ex.BuySize += st.size
+			ex.BuyNotional = ex.addNotional(ex.BuyNotional, st.price, st.size)
+		} else {
+			ex.SellSize += st.size
+			ex.SellNotional = ex.addNotional(ex.SellNotional, st.price, st.size)
+		}
+	}
+	return ex
+}
+
+// addNotional returns total + price × size, clamped to the int64 range and
+// flagged on overflow.
+func (ex *Exposure) addNotional(total int64, price core.PriceTicks, size core.Size) int64 {
+	n, err := money.Notional(int64(price), int64(size))
+	if err == nil {
+		sum, ok := money.AddChecked(total, n)
+		if ok {
+			return sum
+		}
+	}
+	ex.NotionalOverflow = true
+	if (price < 0) != (size < 0) {
+		return math.MinInt64
+	}
+	return math.MaxInt64
+}
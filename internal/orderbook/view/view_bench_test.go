@@ -0,0 +1,62 @@
+package view
+
+import (
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+const benchLevels = 5000
+
+// newDeepView returns a view with benchLevels price levels on each side.
+func newDeepView() *BookView {
+	v := NewBookView(0)
+	for i := range benchLevels {
+		v.Apply(core.OrderRestedEvent{OrderID: core.OrderID(2*i + 1), UserID: 1, Side: core.SideBuy, Price: core.PriceTicks(100_000 - i), Size: 10})
+		v.Apply(core.OrderRestedEvent{OrderID: core.OrderID(2*i + 2), UserID: 2, Side: core.SideSell, Price: core.PriceTicks(100_001 + i), Size: 10})
+	}
+	return v
+}
+
+// BenchmarkLevels reads every bid level, as a full-depth caller does.
+func BenchmarkLevels(b *testing.B) {
+	v := newDeepView()
+	b.ReportAllocs()
+	for b.Loop() {
+		v.Levels(core.SideBuy)
+	}
+}
+
+// BenchmarkLevelsTopN reads the ten best bid levels, as the order book panel
+// does.
+func BenchmarkLevelsTopN(b *testing.B) {
+	v := newDeepView()
+	b.ReportAllocs()
+	for b.Loop() {
+		v.LevelsTopN(core.SideBuy, 10)
+	}
+}
+
+// burst rests benchLevels orders a side from 100 users into a new view, as a
+// busy open does.
+func burst(b *testing.B, hint SizeHint) {
+	b.ReportAllocs()
+	for b.Loop() {
+		v := NewBookViewSized(1000, hint)
+		for i := range benchLevels {
+			user := core.UserID(i%100 + 1)
+			v.Apply(core.OrderRestedEvent{OrderID: core.OrderID(2*i + 1), UserID: user, Side: core.SideBuy, Price: core.PriceTicks(100_000 - i), Size: 10})
+			v.Apply(core.OrderRestedEvent{OrderID: core.OrderID(2*i + 2), UserID: user, Side: core.SideSell, Price: core.PriceTicks(100_001 + i), Size: 10})
+		}
+	}
+}
+
+// BenchmarkBurst grows the view's maps on demand.
+func BenchmarkBurst(b *testing.B) {
+	burst(b, SizeHint{})
+}
+
+// BenchmarkBurstPresized allocates them for the burst up front.
+func BenchmarkBurstPresized(b *testing.B) {
+	burst(b, SizeHint{Orders: 2 * benchLevels, Levels: benchLevels, Users: 100})
+}
@@ -0,0 +1,331 @@
+package view
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestOrdersByUserIndex(t *testing.T) {
+	v := NewBookView(0)
+	rest := func(id core.OrderID, user core.UserID, side core.Side, price core.PriceTicks, size core.Size) {
+		v.Apply(core.OrderRestedEvent{OrderID: id, UserID: user, Side: side, Price: price, Size: size, Time: int64(id)})
+	}
+	rest(1, 100, core.SideBuy, 99, 10)
+	rest(2, 100, core.SideSell, 102, 5)
+	rest(3, 100, core.SideBuy, 100, 4)
+	rest(4, 200, core.SideSell, 101, 7)
+
+	got := v.OrdersByUser(100)
+	want := []core.OrderID{3, 1, 2} // bids best first, then asks
+	if len(got) != len(want) {
+		t.Fatalf("expected %d orders, got %+v", len(want), got)
+	}
+	for i, o := range got {
+		if o.ID != want[i] || o.UserID != 100 {
+			t.Errorf("order %d: expected ID %d, got %+v", i, want[i], o)
+		}
+	}
+
+	// A partial fill updates the size in place
+	v.Apply(core.OrderReducedEvent{OrderID: 1, Delta: -6, Remaining: 4, Price: 99, Side: core.SideBuy, UserID: 100})
+	if o := v.OrdersByUser(100)[1]; o.ID != 1 || o.Size != 4 {
+		t.Errorf("expected order 1 reduced to 4, got %+v", o)
+	}
+
+	// A reduction to nothing drops the order like a removal
+	v.Apply(core.OrderReducedEvent{OrderID: 1, Delta: -4, Remaining: 0, Price: 99, Side: core.SideBuy, UserID: 100})
+	v.Apply(core.OrderRemovedEvent{OrderID: 2, Reason: core.RemoveReasonCanceled, Remaining: 5, Price: 102, Side: core.SideSell, UserID: 100})
+	if got := v.OrdersByUser(100); len(got) != 1 || got[0].ID != 3 {
+		t.Fatalf("expected only order 3 left, got %+v", got)
+	}
+
+	// An amend that loses priority is a removal then a rest under the same ID
+	v.Apply(core.OrderRemovedEvent{OrderID: 3, Reason: core.RemoveReasonAmended, Remaining: 4, Price: 100, Side: core.SideBuy, UserID: 100})
+	rest(3, 100, core.SideBuy, 98, 4)
+	if got := v.OrdersByUser(100); len(got) != 1 || got[0].Price != 98 {
+		t.Fatalf("expected order 3 re-rested at 98, got %+v", got)
+	}
+
+	v.Apply(core.OrderRemovedEvent{OrderID: 3, Reason: core.RemoveReasonFilled, Price: 98, Side: core.SideBuy, UserID: 100})
+	v.Apply(core.OrderRemovedEvent{OrderID: 4, Reason: core.RemoveReasonSelfTrade, Remaining: 7, Price: 101, Side: core.SideSell, UserID: 200})
+	if got := v.OrdersByUser(100); len(got) != 0 {
+		t.Errorf("expected no orders left, got %+v", got)
+	}
+	if len(v.byUser) != 0 || len(v.orders) != 0 {
+		t.Errorf("expected the index to be empty, got %d users and %d orders", len(v.byUser), len(v.orders))
+	}
+	if len(v.bids) != 0 || len(v.asks) != 0 {
+		t.Errorf("expected no levels left, got bids %v asks %v", v.bids, v.asks)
+	}
+
+	// Events for unknown orders leave the index alone
+	v.Apply(core.OrderReducedEvent{OrderID: 9, Delta: -1, Remaining: 0, UserID: 300})
+	v.Apply(core.OrderRemovedEvent{OrderID: 9, UserID: 300})
+	if len(v.byUser) != 0 {
+		t.Errorf("expected unknown orders not to be indexed, got %v", v.byUser)
+	}
+}
+
+// TestAmendSizeEventsKeepLevels applies the events of both AmendSize paths
+// and checks the view's aggregates against the core's.
+func TestAmendSizeEventsKeepLevels(t *testing.T) {
+	c := core.NewCore()
+	v := NewBookView(0)
+	apply := func(evs []core.Event) {
+		for _, ev := range evs {
+			v.Apply(ev)
+		}
+	}
+	for i, size := range []core.Size{10, 10} {
+		_, evs, err := c.SubmitLimit(core.Order{ID: core.OrderID(i + 1), UserID: 100, Side: core.SideBuy, Kind: core.OrderKindLimit, Price: 100, Size: size, Time: int64(i + 1)})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		apply(evs)
+	}
+
+	for _, step := range []struct {
+		size core.Size
+		want core.Size
+	}{
+		{4, 14},  // reduced in place
+		{15, 25}, // re-queued at the tail
+		{1, 11},
+	} {
+		_, evs, err := c.AmendSize(1, step.size, 10)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		apply(evs)
+		if levels := v.Levels(core.SideBuy); len(levels) != 1 || levels[0].Price != 100 || levels[0].Size != step.want {
+			t.Errorf("after amending to %d: expected %d at 100, got %+v", step.size, step.want, levels)
+		}
+	}
+	if orders := v.Orders(core.SideBuy); len(orders) != 2 || orders[0].ID != 2 || orders[1].Size != 1 {
+		t.Errorf("expected order 2 ahead of order 1 with 1 left, got %+v", orders)
+	}
+}
+
+func TestOrderLookup(t *testing.T) {
+	c := core.NewCore()
+	v := NewBookView(0)
+	submit := func(o core.Order) core.SubmitReport {
+		t.Helper()
+		var (
+			report core.SubmitReport
+			evs    []core.Event
+			err    error
+		)
+		if o.Kind == core.OrderKindMarket {
+			report, evs, err = c.SubmitMarket(o)
+		} else {
+			report, evs, err = c.SubmitLimit(o)
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, ev := range evs {
+			v.Apply(ev)
+		}
+		return report
+	}
+
+	submit(core.Order{ID: 1, UserID: 100, Side: core.SideSell, Kind: core.OrderKindLimit, Price: 101, Size: 10, Time: 1})
+	o, ok := v.Order(1)
+	if !ok || o.Size != 10 || o.Price != 101 || o.Side != core.SideSell || o.UserID != 100 {
+		t.Fatalf("expected 10 offered at 101 by user 100, got %+v, %v", o, ok)
+	}
+
+	submit(core.Order{ID: 2, UserID: 200, Side: core.SideBuy, Kind: core.OrderKindMarket, Size: 4, Time: 2})
+	if o, ok := v.Order(1); !ok || o.Size != 6 || o.Time != 1 {
+		t.Errorf("expected the partial fill to leave 6 with its time, got %+v, %v", o, ok)
+	}
+	if _, ok := v.Order(2); ok {
+		t.Error("expected the market order never to be on the book")
+	}
+
+	submit(core.Order{ID: 3, UserID: 200, Side: core.SideBuy, Kind: core.OrderKindMarket, Size: 6, Time: 3})
+	if o, ok := v.Order(1); ok {
+		t.Errorf("expected the filled order gone, got %+v", o)
+	}
+}
+
+func TestLevelsTopN(t *testing.T) {
+	v := NewBookView(0)
+	prices := []core.PriceTicks{103, 99, 101, 105, 100, 98, 104, 102}
+	for i, p := range prices {
+		side := core.SideBuy
+		if p > 101 {
+			side = core.SideSell
+		}
+		v.Apply(core.OrderRestedEvent{OrderID: core.OrderID(i + 1), UserID: 1, Side: side, Price: p, Size: core.Size(p), Time: int64(i)})
+	}
+
+	for _, side := range []core.Side{core.SideBuy, core.SideSell} {
+		all := v.Levels(side)
+		for n := 0; n <= len(all)+1; n++ {
+			got := v.LevelsTopN(side, n)
+			want := all[:min(n, len(all))]
+			if len(got) != len(want) {
+				t.Fatalf("side %v n=%d: expected %d levels, got %+v", side, n, len(want), got)
+			}
+			for i := range want {
+				if got[i] != want[i] {
+					t.Errorf("side %v n=%d: level %d expected %+v, got %+v", side, n, i, want[i], got[i])
+				}
+			}
+		}
+	}
+}
+
+func TestMidPriceAndSpread(t *testing.T) {
+	v := NewBookView(0)
+	if _, _, bidOK, askOK := v.BestBidAsk(); bidOK || askOK {
+		t.Errorf("expected an empty book to have no best bid or ask")
+	}
+	if _, ok := v.MidPrice(); ok {
+		t.Errorf("expected no mid on an empty book")
+	}
+	if _, ok := v.Spread(); ok {
+		t.Errorf("expected no spread on an empty book")
+	}
+
+	// One-sided
+	v.Apply(core.OrderRestedEvent{OrderID: 1, UserID: 1, Side: core.SideBuy, Price: 99, Size: 10})
+	v.Apply(core.OrderRestedEvent{OrderID: 2, UserID: 1, Side: core.SideBuy, Price: 98, Size: 5})
+	if bid, _, bidOK, askOK := v.BestBidAsk(); !bidOK || askOK || bid != (Level{Price: 99, Size: 10}) {
+		t.Errorf("expected only a best bid of 10 at 99, got %+v (%v, %v)", bid, bidOK, askOK)
+	}
+	if _, ok := v.MidPrice(); ok {
+		t.Errorf("expected no mid on a one-sided book")
+	}
+	if _, ok := v.Spread(); ok {
+		t.Errorf("expected no spread on a one-sided book")
+	}
+
+	// Two-sided
+	v.Apply(core.OrderRestedEvent{OrderID: 3, UserID: 2, Side: core.SideSell, Price: 104, Size: 7})
+	v.Apply(core.OrderRestedEvent{OrderID: 4, UserID: 2, Side: core.SideSell, Price: 102, Size: 3})
+	bid, ask, bidOK, askOK := v.BestBidAsk()
+	if !bidOK || !askOK || bid != (Level{Price: 99, Size: 10}) || ask != (Level{Price: 102, Size: 3}) {
+		t.Errorf("expected 10 at 99 / 3 at 102, got %+v / %+v", bid, ask)
+	}
+	if mid, ok := v.MidPrice(); !ok || mid != 100 {
+		t.Errorf("expected mid 100 (rounded down from 100.5), got %d, %v", mid, ok)
+	}
+	if spread, ok := v.Spread(); !ok || spread != 3 {
+		t.Errorf("expected spread 3, got %d, %v", spread, ok)
+	}
+}
+
+func TestVWAPLast(t *testing.T) {
+	v := NewBookView(10)
+	if _, ok := v.VWAPLast(5); ok {
+		t.Errorf("expected no VWAP without trades")
+	}
+
+	for _, tr := range []core.TradeEvent{
+		{Price: 500, Size: 100}, // falls outside the last 3
+		{Price: 100, Size: 10},
+		{Price: 103, Size: 20},
+		{Price: 101, Size: 5},
+	} {
+		v.Apply(tr)
+	}
+	// (100×10 + 103×20 + 101×5) / 35 = 3565 / 35 = 101.857..., truncated
+	if vwap, ok := v.VWAPLast(3); !ok || vwap != 101 {
+		t.Errorf("expected VWAP 101 over the last 3 trades, got %d, %v", vwap, ok)
+	}
+	// n beyond the tape covers every trade: 53565 / 135 = 396.77...
+	if vwap, ok := v.VWAPLast(100); !ok || vwap != 396 {
+		t.Errorf("expected VWAP 396 over all trades, got %d, %v", vwap, ok)
+	}
+	if _, ok := v.VWAPLast(0); ok {
+		t.Errorf("expected no VWAP over 0 trades")
+	}
+}
+
+func TestDepthSnapshotKeepsQueueOrder(t *testing.T) {
+	v := NewBookView(0)
+	rest := func(id core.OrderID, price core.PriceTicks, size core.Size, time int64) {
+		v.Apply(core.OrderRestedEvent{OrderID: id, UserID: core.UserID(id), Side: core.SideSell, Price: price, Size: size, Time: time})
+	}
+	rest(1, 100, 10, 10)
+	rest(2, 100, 5, 20)
+	rest(3, 100, 7, 20) // same time as 2; the ID breaks the tie
+	rest(4, 101, 3, 5)
+	rest(5, 102, 1, 1)
+
+	check := func(levels []LevelDetail, want map[core.PriceTicks][]core.OrderID, prices ...core.PriceTicks) {
+		t.Helper()
+		if len(levels) != len(prices) {
+			t.Fatalf("expected %d levels, got %+v", len(prices), levels)
+		}
+		for i, l := range levels {
+			if l.Price != prices[i] {
+				t.Errorf("level %d: expected price %d, got %d", i, prices[i], l.Price)
+			}
+			var sum core.Size
+			var ids []core.OrderID
+			for _, o := range l.Orders {
+				sum += o.Size
+				ids = append(ids, o.ID)
+			}
+			if sum != l.TotalSize {
+				t.Errorf("level %d: orders sum to %d, total is %d", l.Price, sum, l.TotalSize)
+			}
+			if !slices.Equal(ids, want[l.Price]) {
+				t.Errorf("level %d: expected queue %v, got %v", l.Price, want[l.Price], ids)
+			}
+		}
+	}
+
+	want := map[core.PriceTicks][]core.OrderID{100: {1, 2, 3}, 101: {4}, 102: {5}}
+	check(v.DepthSnapshot(core.SideSell, 0), want, 100, 101, 102)
+	check(v.DepthSnapshot(core.SideSell, 2), want, 100, 101)
+	if got := v.DepthSnapshot(core.SideBuy, 0); len(got) != 0 {
+		t.Errorf("expected no bid levels, got %+v", got)
+	}
+
+	// A partial fill of the front order and a cancel from the middle leave
+	// the rest of the queue where it was
+	v.Apply(core.OrderReducedEvent{OrderID: 1, Delta: -4, Remaining: 6, Price: 100, Side: core.SideSell, UserID: 1})
+	v.Apply(core.OrderRemovedEvent{OrderID: 2, Reason: core.RemoveReasonCanceled, Remaining: 5, Price: 100, Side: core.SideSell, UserID: 2})
+	v.Apply(core.OrderRemovedEvent{OrderID: 4, Reason: core.RemoveReasonCanceled, Remaining: 3, Price: 101, Side: core.SideSell, UserID: 4})
+	levels := v.DepthSnapshot(core.SideSell, 0)
+	check(levels, map[core.PriceTicks][]core.OrderID{100: {1, 3}, 102: {5}}, 100, 102)
+	if levels[0].TotalSize != 13 || levels[0].Orders[0].Size != 6 {
+		t.Errorf("expected the front order reduced to 6 of 13, got %+v", levels[0])
+	}
+}
+
+func TestIcebergLevelsShowDisplayedSize(t *testing.T) {
+	c := core.NewCore()
+	v := NewBookView(0)
+	submit := func(o core.Order) {
+		t.Helper()
+		_, evs, err := c.SubmitLimit(o)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, ev := range evs {
+			v.Apply(ev)
+		}
+	}
+	submit(core.Order{ID: 1, UserID: 100, Side: core.SideSell, Kind: core.OrderKindLimit, Price: 100, Size: 10, Time: 1, DisplaySize: 2})
+	submit(core.Order{ID: 2, UserID: 200, Side: core.SideSell, Kind: core.OrderKindLimit, Price: 100, Size: 3, Time: 2})
+	if levels := v.Levels(core.SideSell); len(levels) != 1 || levels[0].Size != 5 {
+		t.Fatalf("expected 2 of the iceberg and 3 showing at 100, got %+v", levels)
+	}
+
+	// Taking the slice and one more shows a fresh 2 behind order 2
+	submit(core.Order{ID: 3, UserID: 300, Side: core.SideBuy, Kind: core.OrderKindLimit, Price: 100, Size: 3, Time: 5})
+	if levels := v.Levels(core.SideSell); len(levels) != 1 || levels[0].Size != 4 {
+		t.Errorf("expected 2 left of order 2 and 2 refreshed, got %+v", levels)
+	}
+	if orders := v.Orders(core.SideSell); len(orders) != 2 || orders[0].ID != 2 || orders[1].ID != 1 || orders[1].Size != 2 || orders[1].Time != 5 {
+		t.Errorf("expected the refreshed iceberg behind order 2, got %+v", orders)
+	}
+}
@@ -0,0 +1,227 @@
+// Package pubsub fans values out from publishers to any number of
+// subscribers, each with its own buffered channel, overflow policy and drop
+// counter.
+//
+// It replaces the external-channel half of the services' dispatcher loops:
+// a service keeps applying events to its view in order, then publishes them
+// on a Bus instead of hand-rolling a send with a drop-or-block select, a
+// dropped counter and close bookkeeping.
+//
+// Publishes are serialized, so every subscriber receives values in the same
+// order, the order Publish was called in. A Block subscriber that stops
+// reading stalls every publisher until it reads, unsubscribes or the bus
+// closes; use Drop for anything that may fall behind.
+package pubsub
+
+import (
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// Policy is what Publish does when a subscriber's buffer is full.
+type Policy int
+
+const (
+	// Drop discards the value for that subscriber and counts it.
+	Drop Policy = iota
+	// Block waits until the subscriber has room, unsubscribes or the bus
+	// closes.
+	Block
+)
+
+// String returns the policy name.
+func (p Policy) String() string {
+	switch p {
+	case Drop:
+		return "drop"
+	case Block:
+		return "block"
+	default:
+		return "unknown"
+	}
+}
+
+// Bus is a typed publish/subscribe hub. The zero value is not usable; create
+// one with New.
+type Bus[T any] struct {
+	// mu serializes Publish and guards subs and closed. Close and
+	// Unsubscribe signal done before taking it, so a Publish blocked on a
+	// full subscriber releases it.
+	mu     sync.Mutex
+	subs   []*Subscription[T] // in subscription order
+	closed bool
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// New creates an open Bus.
+func New[T any]() *Bus[T] {
+	return &Bus[T]{done: make(chan struct{})}
+}
+
+// Subscribe adds a subscriber with a channel of the given buffer size
+// (negative is treated as 0). A subscription made after Close has its
+// channel already closed.
+func (b *Bus[T]) Subscribe(policy Policy, buffer int) *Subscription[T] {
+	return b.SubscribeFunc(policy, buffer, nil)
+}
+
+// SubscribeFunc is Subscribe for only the values keep returns true for
+// (nil keeps all). Keep runs on the publisher, under the bus lock, so it
+// must be quick and must not call back into the bus. Skipped values are not
+// counted as dropped.
+func (b *Bus[T]) SubscribeFunc(policy Policy, buffer int, keep func(T) bool) *Subscription[T] {
+	s := &Subscription[T]{
+		bus:    b,
+		ch:     make(chan T, max(buffer, 0)),
+		policy: policy,
+		keep:   keep,
+		done:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		s.stop()
+		return s
+	}
+	b.subs = append(b.subs, s)
+	return s
+}
+
+// Publish delivers v to every subscriber in subscription order, applying
+// each one's policy. It returns false, delivering nothing, if the bus is
+// closed or closing.
+func (b *Bus[T]) Publish(v T) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.done:
+		return false
+	default:
+	}
+	for _, s := range b.subs {
+		s.deliver(v, b.done)
+	}
+	return true
+}
+
+// Len returns the number of subscribers.
+func (b *Bus[T]) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
+
+// Close closes the bus and every subscription's channel. Values already
+// buffered can still be received. A Publish blocked on a subscriber gives up
+// on it, and once Close returns no further value is delivered. Close is
+// idempotent.
+func (b *Bus[T]) Close() {
+	b.closeOnce.Do(func() {
+		close(b.done)
+	})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	b.closed = true
+	for _, s := range b.subs {
+		s.stop()
+	}
+	b.subs = nil
+}
+
+// Subscription is one subscriber's view of a Bus.
+type Subscription[T any] struct {
+	bus     *Bus[T]
+	ch      chan T
+	policy  Policy
+	keep    func(T) bool
+	dropped atomic.Int64
+
+	// done is closed by Unsubscribe so a Publish blocked on this
+	// subscriber moves on.
+	done     chan struct{}
+	doneOnce sync.Once
+	stopped  bool // ch closed; guarded by bus.mu
+}
+
+// C returns the channel values are delivered on. It is closed by Unsubscribe
+// and by the bus's Close.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Policy returns the subscription's overflow policy.
+func (s *Subscription[T]) Policy() Policy {
+	return s.policy
+}
+
+// Dropped returns how many values were discarded because the buffer was
+// full. Block subscriptions only drop a value they unsubscribed or closed
+// while waiting for room for.
+func (s *Subscription[T]) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Len returns the number of values waiting in the channel.
+func (s *Subscription[T]) Len() int {
+	return len(s.ch)
+}
+
+// Unsubscribe removes the subscription and closes its channel. Values already
+// buffered can still be received. It is idempotent and safe to call after the
+// bus closes.
+func (s *Subscription[T]) Unsubscribe() {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+
+	b := s.bus
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	if i := slices.Index(b.subs, s); i >= 0 {
+		b.subs = slices.Delete(b.subs, i, i+1)
+	}
+	s.stop()
+}
+
+// stop closes the channel. Callers must hold bus.mu.
+func (s *Subscription[T]) stop() {
+	if !s.stopped {
+		s.stopped = true
+		close(s.ch)
+	}
+}
+
+// deliver sends v under the subscription's policy. Callers must hold bus.mu.
+func (s *Subscription[T]) deliver(v T, closing <-chan struct{}) {
+	if s.keep != nil && !s.keep(v) {
+		return
+	}
+	select {
+	case s.ch <- v:
+		return
+	default:
+	}
+	if s.policy == Block {
+		// Full: wait for room unless the subscriber or bus goes away
+		select {
+		case s.ch <- v:
+		case <-s.done:
+			s.dropped.Add(1)
+		case <-closing:
+			s.dropped.Add(1)
+		}
+		return
+	}
+	s.dropped.Add(1)
+}
@@ -0,0 +1,348 @@
+package pubsub
+
+import (
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// checkNoBlockedPublishers fails the test if any goroutine is still inside
+// Publish once the test is done, standing in for a goleak check: the bus
+// starts no goroutines of its own, so a leak is a publisher Close or
+// Unsubscribe failed to release.
+func checkNoBlockedPublishers(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		deadline := time.Now().Add(time.Second)
+		for {
+			buf := make([]byte, 1<<20)
+			stacks := string(buf[:runtime.Stack(buf, true)])
+			if !strings.Contains(stacks, "pubsub.(*Bus[...]).Publish") {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("goroutines still blocked in Publish:\n%s", stacks)
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
+
+// receive reads everything buffered on a closed or idle channel.
+func receive[T any](ch <-chan T) []T {
+	var out []T
+	for {
+		select {
+		case v, ok := <-ch:
+			if !ok {
+				return out
+			}
+			out = append(out, v)
+		default:
+			return out
+		}
+	}
+}
+
+// blocked reports whether done stays open for a short while.
+func blocked(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return false
+	case <-time.After(20 * time.Millisecond):
+		return true
+	}
+}
+
+func TestDropPolicyCountsOverflow(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[int]()
+	defer b.Close()
+	s := b.Subscribe(Drop, 2)
+	for i := range 5 {
+		if !b.Publish(i) {
+			t.Fatal("expected publish to succeed")
+		}
+	}
+	if got := receive(s.C()); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Errorf("expected the first two values, got %v", got)
+	}
+	if s.Dropped() != 3 {
+		t.Errorf("expected 3 dropped, got %d", s.Dropped())
+	}
+}
+
+func TestBlockPolicyWaitsForRoom(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[int]()
+	defer b.Close()
+	s := b.Subscribe(Block, 1)
+	b.Publish(1)
+
+	done := make(chan struct{})
+	go func() {
+		b.Publish(2)
+		close(done)
+	}()
+	if !blocked(done) {
+		t.Fatal("expected publish to wait for a full block subscriber")
+	}
+	if v := <-s.C(); v != 1 {
+		t.Errorf("expected 1, got %d", v)
+	}
+	<-done
+	if v := <-s.C(); v != 2 {
+		t.Errorf("expected 2, got %d", v)
+	}
+	if s.Dropped() != 0 {
+		t.Errorf("expected nothing dropped, got %d", s.Dropped())
+	}
+}
+
+func TestSlowDropSubscriberDoesNotStallOthers(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[int]()
+	defer b.Close()
+	slow := b.Subscribe(Drop, 0)
+	fast := b.Subscribe(Block, 100)
+	for i := range 100 {
+		b.Publish(i)
+	}
+	if n := len(receive(fast.C())); n != 100 {
+		t.Errorf("expected the fast subscriber to get all 100, got %d", n)
+	}
+	if slow.Dropped() != 100 {
+		t.Errorf("expected the unbuffered subscriber to drop all 100, got %d", slow.Dropped())
+	}
+}
+
+func TestSubscribeFuncFilters(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[int]()
+	defer b.Close()
+	even := b.SubscribeFunc(Drop, 10, func(v int) bool { return v%2 == 0 })
+	all := b.Subscribe(Drop, 10)
+	for i := range 10 {
+		b.Publish(i)
+	}
+	b.Close()
+	if got := receive(even.C()); len(got) != 5 || got[0] != 0 || got[4] != 8 {
+		t.Errorf("expected the even values, got %v", got)
+	}
+	if n := len(receive(all.C())); n != 10 {
+		t.Errorf("expected the unfiltered subscriber to get all 10, got %d", n)
+	}
+	if even.Dropped() != 0 {
+		t.Errorf("expected skipped values not counted as dropped, got %d", even.Dropped())
+	}
+}
+
+func TestOrderedFanOut(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[int]()
+	const publishers, each = 8, 200
+	subs := []*Subscription[int]{
+		b.Subscribe(Block, 0),
+		b.Subscribe(Block, 7),
+		b.Subscribe(Block, publishers*each),
+	}
+
+	got := make([][]int, len(subs))
+	var readers sync.WaitGroup
+	for i, s := range subs {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for v := range s.C() {
+				got[i] = append(got[i], v)
+			}
+		}()
+	}
+
+	var pubs sync.WaitGroup
+	for p := range publishers {
+		pubs.Add(1)
+		go func() {
+			defer pubs.Done()
+			for i := range each {
+				b.Publish(p*each + i)
+			}
+		}()
+	}
+	pubs.Wait()
+	b.Close()
+	readers.Wait()
+
+	// Every subscriber saw the same interleaving, and each publisher's own
+	// values in the order it sent them
+	for i := range subs {
+		if len(got[i]) != publishers*each {
+			t.Fatalf("subscriber %d: expected %d values, got %d", i, publishers*each, len(got[i]))
+		}
+		for j := range got[i] {
+			if got[i][j] != got[0][j] {
+				t.Fatalf("subscriber %d diverges from subscriber 0 at %d", i, j)
+			}
+		}
+	}
+	last := make(map[int]int)
+	for _, v := range got[0] {
+		p := v / each
+		if prev, ok := last[p]; ok && v <= prev {
+			t.Fatalf("publisher %d out of order: %d after %d", p, v, prev)
+		}
+		last[p] = v
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[int]()
+	defer b.Close()
+	a := b.Subscribe(Drop, 4)
+	c := b.Subscribe(Drop, 4)
+	b.Publish(1)
+
+	a.Unsubscribe()
+	a.Unsubscribe()
+	if b.Len() != 1 {
+		t.Errorf("expected one subscriber left, got %d", b.Len())
+	}
+	b.Publish(2)
+
+	// Buffered values survive; the channel is closed after them
+	if got := receive(a.C()); len(got) != 1 || got[0] != 1 {
+		t.Errorf("expected the value buffered before unsubscribing, got %v", got)
+	}
+	if _, ok := <-a.C(); ok {
+		t.Error("expected the channel closed")
+	}
+	if got := receive(c.C()); len(got) != 2 {
+		t.Errorf("expected the other subscriber unaffected, got %v", got)
+	}
+}
+
+func TestUnsubscribeReleasesBlockedPublisher(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[int]()
+	defer b.Close()
+	stuck := b.Subscribe(Block, 0)
+	other := b.Subscribe(Block, 1)
+
+	done := make(chan struct{})
+	go func() {
+		b.Publish(1)
+		close(done)
+	}()
+	if !blocked(done) {
+		t.Fatal("expected publish to wait for the unbuffered subscriber")
+	}
+	stuck.Unsubscribe()
+	<-done
+	if stuck.Dropped() != 1 {
+		t.Errorf("expected the abandoned value counted, got %d", stuck.Dropped())
+	}
+	if v := <-other.C(); v != 1 {
+		t.Errorf("expected later subscribers still served, got %d", v)
+	}
+}
+
+func TestClose(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[string]()
+	s := b.Subscribe(Block, 4)
+	b.Publish("a")
+	b.Close()
+	b.Close()
+
+	if b.Publish("b") {
+		t.Error("expected publish to fail after Close")
+	}
+	if got := receive(s.C()); len(got) != 1 || got[0] != "a" {
+		t.Errorf("expected the buffered value, got %v", got)
+	}
+	if _, ok := <-s.C(); ok {
+		t.Error("expected the channel closed")
+	}
+	s.Unsubscribe()
+
+	late := b.Subscribe(Drop, 4)
+	if _, ok := <-late.C(); ok {
+		t.Error("expected a subscription after Close to be closed")
+	}
+	if b.Len() != 0 {
+		t.Errorf("expected no subscribers, got %d", b.Len())
+	}
+}
+
+func TestCloseReleasesBlockedPublishers(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	b := New[int]()
+	s := b.Subscribe(Block, 0)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b.Publish(1)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	b.Close()
+	wg.Wait()
+	if _, ok := <-s.C(); ok {
+		t.Error("expected the channel closed with nothing delivered")
+	}
+	// One publisher was waiting on the subscriber; the rest on the bus
+	if s.Dropped() != 1 {
+		t.Errorf("expected 1 dropped, got %d", s.Dropped())
+	}
+}
+
+func TestCloseRaces(t *testing.T) {
+	checkNoBlockedPublishers(t)
+	for range 50 {
+		b := New[int]()
+		var wg sync.WaitGroup
+		for i := range 4 {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; b.Publish(j); j++ {
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				policy := Policy(i % 2)
+				for range 20 {
+					s := b.Subscribe(policy, i)
+					go func() {
+						for range s.C() {
+						}
+					}()
+					if i%3 == 0 {
+						s.Unsubscribe()
+					}
+				}
+			}()
+		}
+		time.Sleep(time.Millisecond)
+		b.Close()
+		wg.Wait()
+		if b.Publish(0) {
+			t.Fatal("expected publish to fail after Close")
+		}
+	}
+}
+
+func TestPolicyString(t *testing.T) {
+	for p, want := range map[Policy]string{Drop: "drop", Block: "block", Policy(9): "unknown"} {
+		if p.String() != want {
+			t.Errorf("expected %q, got %q", want, p.String())
+		}
+	}
+}
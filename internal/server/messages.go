@@ -0,0 +1,190 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/market/feed"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/order"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// Order entry ops, alongside feed.OpSubscribe and feed.OpUnsubscribe.
+const (
+	OpSubmitLimit  = "submit_limit"
+	OpSubmitMarket = "submit_market"
+	OpCancel       = "cancel"
+)
+
+// Message types the server sends besides feed lines.
+const (
+	// TypeAck answers a request that succeeded.
+	TypeAck = "ack"
+	// TypeError answers a request that was rejected.
+	TypeError = "error"
+	// TypeBBO tags a ticker's best bid and offer after it changed.
+	TypeBBO = "bbo"
+)
+
+// Codes for rejections that come from the transport or the market rather
+// than order validation, as the REST API reports them.
+const (
+	// CodeBadRequest rejects a message that does not parse.
+	CodeBadRequest order.Code = "BAD_REQUEST"
+	// CodeOrderNotFound rejects a cancel of an order not on the book.
+	CodeOrderNotFound order.Code = "ORDER_NOT_FOUND"
+	// CodeNotTrading rejects an order the ticker cannot take right now,
+	// e.g. while halted.
+	CodeNotTrading order.Code = "NOT_TRADING"
+	// CodeRiskLimit rejects an order beyond the user's cash or position.
+	CodeRiskLimit order.Code = "RISK_LIMIT"
+	// CodeInvalidOrder is an order the matching engine refused.
+	CodeInvalidOrder order.Code = "INVALID_ORDER"
+	// CodeInternal is any other failure.
+	CodeInternal order.Code = "INTERNAL"
+)
+
+// submitRequest is a submit_limit or submit_market message.
+type submitRequest struct {
+	Ticker json.RawMessage `json:"ticker"`
+	User   core.UserID     `json:"user"`
+	Side   string          `json:"side"`
+	Price  core.PriceTicks `json:"price"`
+	Size   core.Size       `json:"size"`
+	TIF    string          `json:"tif"` // submit_limit only; default gtc
+}
+
+func (b submitRequest) request(t market.Ticker, op string) (order.Request, error) {
+	req := order.Request{TickerID: t.TickerID(), UserID: b.User, Size: b.Size}
+	switch strings.ToLower(b.Side) {
+	case "buy":
+		req.Side = core.SideBuy
+	case "sell":
+		req.Side = core.SideSell
+	default:
+		return req, reject(order.CodeInvalidSide, "side", "side must be buy or sell, got %q", b.Side)
+	}
+	if op == OpSubmitMarket {
+		req.Kind = core.OrderKindMarket
+		return req, nil
+	}
+	req.Kind, req.Price = core.OrderKindLimit, b.Price
+	switch strings.ToLower(b.TIF) {
+	case "", "gtc":
+		req.TIF = order.TIFGTC
+	case "ioc":
+		req.TIF = order.TIFIOC
+	case "fok":
+		req.TIF = order.TIFFOK
+	default:
+		return req, reject(order.CodeInvalidTIF, "tif", "tif must be gtc, ioc or fok, got %q", b.TIF)
+	}
+	return req, nil
+}
+
+// cancelRequest is a cancel message.
+type cancelRequest struct {
+	Ticker  json.RawMessage `json:"ticker"`
+	OrderID core.OrderID    `json:"order_id"`
+}
+
+// ack is an ack message; Report is a submitAck, a cancelAck or absent.
+type ack struct {
+	Type   string `json:"type"`
+	ID     string `json:"id,omitempty"`
+	Op     string `json:"op"`
+	Report any    `json:"report,omitempty"`
+}
+
+// submitAck mirrors core.SubmitReport.
+type submitAck struct {
+	OrderID          core.OrderID   `json:"order_id"`
+	Fills            []fillAck      `json:"fills"`
+	Remaining        core.Size      `json:"remaining"`
+	RestedSize       core.Size      `json:"rested_size"`
+	Rested           bool           `json:"rested"`
+	STPCanceled      []core.OrderID `json:"stp_canceled,omitempty"`
+	STPTakerCanceled bool           `json:"stp_taker_canceled,omitempty"`
+	FullyUnfilled    bool           `json:"fully_unfilled,omitempty"`
+}
+
+type fillAck struct {
+	MakerOrderID core.OrderID    `json:"maker_order_id"`
+	Price        core.PriceTicks `json:"price"`
+	Size         core.Size       `json:"size"`
+}
+
+// cancelAck mirrors core.CancelReport.
+type cancelAck struct {
+	OrderID      core.OrderID `json:"order_id"`
+	CanceledSize core.Size    `json:"canceled_size"`
+}
+
+func submitAckOf(r core.SubmitReport) submitAck {
+	a := submitAck{
+		OrderID:          r.OrderID,
+		Fills:            make([]fillAck, 0, len(r.Fills)),
+		Remaining:        r.Remaining,
+		RestedSize:       r.RestedSize,
+		Rested:           r.Rested,
+		STPCanceled:      r.STPCanceled,
+		STPTakerCanceled: r.STPTakerCanceled,
+		FullyUnfilled:    r.FullyUnfilled,
+	}
+	for _, f := range r.Fills {
+		a.Fills = append(a.Fills, fillAck{MakerOrderID: f.MakerOrderID, Price: f.Price, Size: f.Size})
+	}
+	return a
+}
+
+// errorBody is an error message.
+type errorBody struct {
+	Type    string     `json:"type"`
+	ID      string     `json:"id,omitempty"`
+	Code    order.Code `json:"code"`
+	Field   string     `json:"field,omitempty"`
+	Message string     `json:"message"`
+}
+
+func reject(code order.Code, field, format string, args ...any) *order.Error {
+	return &order.Error{Code: code, Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+func ackMessage(id, op string, report any) []byte {
+	data, _ := json.Marshal(ack{Type: TypeAck, ID: id, Op: op, Report: report})
+	return data
+}
+
+// errorMessage encodes a rejection with the codes the REST API uses.
+func errorMessage(id string, err error) []byte {
+	body := errorBody{Type: TypeError, ID: id, Code: CodeInternal, Message: err.Error()}
+	var oe *order.Error
+	switch {
+	case errors.As(err, &oe):
+		body.Code, body.Field, body.Message = oe.Code, oe.Field, oe.Message
+	case errors.Is(err, marketservice.ErrUnknownTicker):
+		body.Code = order.CodeUnknownTicker
+	case errors.Is(err, core.ErrNotFound):
+		body.Code = CodeOrderNotFound
+	case errors.Is(err, marketservice.ErrTickerHalted), errors.Is(err, core.ErrAuction):
+		body.Code = CodeNotTrading
+	case errors.Is(err, marketservice.ErrInsufficientFunds), errors.Is(err, marketservice.ErrPositionLimit):
+		body.Code = CodeRiskLimit
+	case errors.Is(err, core.ErrInvalidOrder):
+		body.Code = CodeInvalidOrder
+	}
+	data, _ := json.Marshal(body)
+	return data
+}
+
+// bboMessage encodes a ticker's best bid and offer as a feed line.
+func bboMessage(t market.Ticker, q marketview.BBO) []byte {
+	event, _ := json.Marshal(q)
+	data, _ := json.Marshal(feed.Message{TickerID: t.TickerID(), Ticker: t.Name, Type: TypeBBO, Event: event})
+	return data
+}
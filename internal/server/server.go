@@ -0,0 +1,334 @@
+// Package server serves the market over WebSocket for JSON clients. One
+// connection both follows tickers and enters orders, one JSON message per
+// frame:
+//
+//	{"op":"subscribe","tickers":["AAPL",2]}
+//	{"op":"unsubscribe","tickers":["AAPL"]}
+//	{"op":"submit_limit","id":"c1","ticker":"AAPL","user":1000,"side":"buy","price":15025,"size":10,"tif":"ioc"}
+//	{"op":"submit_market","id":"c2","ticker":"AAPL","user":1000,"side":"sell","size":5}
+//	{"op":"cancel","id":"c3","ticker":"AAPL","order_id":42}
+//
+// Book events of subscribed tickers arrive as feed lines (see package
+// feed), and a "bbo" line follows each book change that moves the best bid
+// or offer. Every request is answered with an "ack", carrying the submit
+// or cancel report, or an "error" with the order package's codes; both echo
+// the request's id. Prices are integer ticks, as in the events, and tickers
+// are given by name or ID. Orders are validated by the order package like
+// every other entry point.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/market/feed"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/order"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/internal/pubsub"
+)
+
+// Market is the surface the server drives. MarketService implements it.
+type Market interface {
+	order.TIFSender
+	Cancel(ctx context.Context, tid market.TickerID, orderID core.OrderID) (core.CancelReport, error)
+	Registry() *market.Registry
+	SubscribeBook(tid market.TickerID, policy pubsub.Policy, buffer int) (*pubsub.Subscription[core.Event], error)
+	GetLevelsTopN(tid market.TickerID, side core.Side, n int) ([]orderbookview.Level, error)
+}
+
+// Config holds configuration for a Server.
+type Config struct {
+	// Buffer is each connection's outbound queue, in messages, and the
+	// buffer of each of its ticker subscriptions.
+	Buffer int
+	// DropSlowConsumers drops stream lines a connection has no room for,
+	// as orderbookservice.Config.DropExternalEvents does for a book's
+	// events. Otherwise a connection that falls Buffer lines behind is
+	// closed. Acks and errors are never dropped.
+	DropSlowConsumers bool
+	// CheckOrigin, if set, decides whether to accept a connection from a
+	// request's origin. Nil accepts only same-origin requests.
+	CheckOrigin func(r *http.Request) bool
+}
+
+// DefaultConfig returns a Config with reasonable defaults.
+func DefaultConfig() Config {
+	return Config{
+		Buffer:            1024,
+		DropSlowConsumers: true,
+	}
+}
+
+// Server serves WebSocket connections. It implements http.Handler.
+type Server struct {
+	m        Market
+	cfg      Config
+	upgrader websocket.Upgrader
+}
+
+// NewServer creates a Server for a market.
+func NewServer(m Market, cfg Config) *Server {
+	if cfg.Buffer <= 0 {
+		cfg.Buffer = DefaultConfig().Buffer
+	}
+	return &Server{m: m, cfg: cfg, upgrader: websocket.Upgrader{CheckOrigin: cfg.CheckOrigin}}
+}
+
+// ServeHTTP upgrades the request and serves the connection until the
+// client goes away.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ws, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // the upgrader has replied
+	}
+	c := &conn{
+		s:    s,
+		ws:   ws,
+		out:  make(chan []byte, s.cfg.Buffer),
+		done: make(chan struct{}),
+		subs: make(map[market.TickerID]*pubsub.Subscription[core.Event]),
+	}
+	go c.writeLoop()
+	c.readLoop()
+}
+
+// conn is one client connection. The read loop handles requests in order;
+// the write loop is the socket's only writer.
+type conn struct {
+	s  *Server
+	ws *websocket.Conn
+
+	out       chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	dropped   atomic.Int64
+
+	mu   sync.Mutex
+	subs map[market.TickerID]*pubsub.Subscription[core.Event]
+}
+
+func (c *conn) readLoop() {
+	defer c.close()
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		c.handle(data)
+	}
+}
+
+func (c *conn) writeLoop() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg := <-c.out:
+			if err := c.ws.WriteMessage(websocket.TextMessage, msg); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+// close ends the connection and its subscriptions. It is idempotent.
+func (c *conn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.ws.Close()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for tid, sub := range c.subs {
+			sub.Unsubscribe()
+			delete(c.subs, tid)
+		}
+	})
+}
+
+// reply queues an answer to a request, waiting for room.
+func (c *conn) reply(msg []byte) {
+	select {
+	case c.out <- msg:
+	case <-c.done:
+	}
+}
+
+// stream queues a market data line. With no room it is dropped or the
+// connection closed, as Config.DropSlowConsumers says.
+func (c *conn) stream(msg []byte) {
+	select {
+	case c.out <- msg:
+	case <-c.done:
+	default:
+		if c.s.cfg.DropSlowConsumers {
+			c.dropped.Add(1)
+			return
+		}
+		c.close()
+	}
+}
+
+func (c *conn) handle(data []byte) {
+	var head struct {
+		Op string `json:"op"`
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		c.reply(errorMessage("", reject(CodeBadRequest, "", "malformed request: %v", err)))
+		return
+	}
+	switch head.Op {
+	case string(feed.OpSubscribe), string(feed.OpUnsubscribe):
+		c.subscribe(head.ID, data)
+	case OpSubmitLimit, OpSubmitMarket:
+		c.submit(head.ID, head.Op, data)
+	case OpCancel:
+		c.cancel(head.ID, data)
+	default:
+		c.reply(errorMessage(head.ID, reject(CodeBadRequest, "op", "unknown op %q", head.Op)))
+	}
+}
+
+// subscribe starts or stops following tickers. New subscriptions are in
+// place before the ack is queued, so a client that has read it sees every
+// later event.
+func (c *conn) subscribe(id string, data []byte) {
+	reg := c.s.m.Registry()
+	req, err := feed.ParseRequest(data, reg)
+	if err != nil {
+		c.reply(errorMessage(id, tickerError("tickers", err)))
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tid := range req.Tickers {
+		sub, ok := c.subs[tid]
+		if req.Op == feed.OpUnsubscribe {
+			if ok {
+				sub.Unsubscribe()
+				delete(c.subs, tid)
+			}
+			continue
+		}
+		if ok {
+			continue
+		}
+		sub, err := c.s.m.SubscribeBook(tid, pubsub.Drop, c.s.cfg.Buffer)
+		if err != nil {
+			c.reply(errorMessage(id, err))
+			return
+		}
+		c.subs[tid] = sub
+		t, _ := reg.Lookup(tid)
+		go c.forward(t, reg, sub)
+	}
+	c.reply(ackMessage(id, string(req.Op), nil))
+}
+
+// forward streams a ticker's events until the subscription ends, following
+// each book change that moves the best bid or offer with a bbo line.
+func (c *conn) forward(t market.Ticker, reg *market.Registry, sub *pubsub.Subscription[core.Event]) {
+	tid := t.TickerID()
+	var last marketview.BBO
+	for ev := range sub.C() {
+		if line, err := feed.Marshal(marketview.MarketEvent{Ticker: tid, Event: ev}, reg); err == nil {
+			c.stream(line)
+		}
+		if _, ok := ev.(core.TradeEvent); ok {
+			continue // the reduction or removal that follows moves the levels
+		}
+		q := c.quote(tid)
+		if q != last {
+			last = q
+			c.stream(bboMessage(t, q))
+		}
+	}
+}
+
+// quote reads a ticker's best bid and offer.
+func (c *conn) quote(tid market.TickerID) marketview.BBO {
+	var q marketview.BBO
+	if bids, _ := c.s.m.GetLevelsTopN(tid, core.SideBuy, 1); len(bids) > 0 {
+		q.BidPrice, q.BidSize, q.BidOK = bids[0].Price, bids[0].Size, true
+	}
+	if asks, _ := c.s.m.GetLevelsTopN(tid, core.SideSell, 1); len(asks) > 0 {
+		q.AskPrice, q.AskSize, q.AskOK = asks[0].Price, asks[0].Size, true
+	}
+	return q
+}
+
+// resolve looks up a request's ticker, given by name or ID.
+func (c *conn) resolve(ref json.RawMessage) (market.Ticker, error) {
+	t, err := feed.Resolve(ref, c.s.m.Registry())
+	if err != nil {
+		return t, tickerError("ticker", err)
+	}
+	return t, nil
+}
+
+// tickerError rejects a ticker reference that did not resolve.
+func tickerError(field string, err error) error {
+	code := CodeBadRequest
+	if errors.Is(err, market.ErrUnknownTicker) {
+		code = order.CodeUnknownTicker
+	}
+	return reject(code, field, "%v", err)
+}
+
+func (c *conn) submit(id, op string, data []byte) {
+	var body submitRequest
+	if err := json.Unmarshal(data, &body); err != nil {
+		c.reply(errorMessage(id, reject(CodeBadRequest, "", "malformed request: %v", err)))
+		return
+	}
+	t, err := c.resolve(body.Ticker)
+	if err != nil {
+		c.reply(errorMessage(id, err))
+		return
+	}
+	req, err := body.request(t, op)
+	if err != nil {
+		c.reply(errorMessage(id, err))
+		return
+	}
+	report, err := order.Submit(context.Background(), c.s.m, t, req)
+	if err != nil {
+		c.reply(errorMessage(id, err))
+		return
+	}
+	c.reply(ackMessage(id, op, submitAckOf(report)))
+}
+
+func (c *conn) cancel(id string, data []byte) {
+	var body cancelRequest
+	if err := json.Unmarshal(data, &body); err != nil {
+		c.reply(errorMessage(id, reject(CodeBadRequest, "", "malformed request: %v", err)))
+		return
+	}
+	if body.OrderID <= 0 {
+		c.reply(errorMessage(id, reject(CodeBadRequest, "order_id", "order ID must be positive, got %d", body.OrderID)))
+		return
+	}
+	t, err := c.resolve(body.Ticker)
+	if err != nil {
+		c.reply(errorMessage(id, err))
+		return
+	}
+	report, err := c.s.m.Cancel(context.Background(), t.TickerID(), body.OrderID)
+	if err != nil {
+		c.reply(errorMessage(id, err))
+		return
+	}
+	c.reply(ackMessage(id, OpCancel, cancelAck{OrderID: report.OrderID, CanceledSize: report.CanceledSize}))
+}
@@ -0,0 +1,208 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/market/feed"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/order"
+)
+
+// newTestServer serves a synchronous market over httptest and returns its
+// WebSocket URL.
+func newTestServer(t *testing.T) string {
+	t.Helper()
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	m := marketservice.NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	t.Cleanup(m.Close)
+
+	srv := httptest.NewServer(NewServer(m, DefaultConfig()))
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
+
+func dial(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { ws.Close() })
+	return ws
+}
+
+// reply is any message the server sends, loosely decoded.
+type reply struct {
+	Type   string          `json:"type"`
+	ID     string          `json:"id"`
+	Code   order.Code      `json:"code"`
+	Report json.RawMessage `json:"report"`
+	Event  json.RawMessage `json:"event"`
+}
+
+func send(t *testing.T, ws *websocket.Conn, msg string) {
+	t.Helper()
+	if err := ws.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+}
+
+// next reads messages until one of the given type arrives.
+func next(t *testing.T, ws *websocket.Conn, typ string) reply {
+	t.Helper()
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, data, err := ws.ReadMessage()
+		if err != nil {
+			t.Fatalf("waiting for %s: %v", typ, err)
+		}
+		var r reply
+		if err := json.Unmarshal(data, &r); err != nil {
+			t.Fatalf("decode %s: %v", data, err)
+		}
+		if r.Type == typ {
+			return r
+		}
+	}
+}
+
+func TestOrderOnOneConnectionTradesOnAnother(t *testing.T) {
+	url := newTestServer(t)
+	watcher, trader := dial(t, url), dial(t, url)
+
+	send(t, watcher, `{"op":"subscribe","id":"s1","tickers":["aapl"]}`)
+	if r := next(t, watcher, TypeAck); r.ID != "s1" {
+		t.Fatalf("subscribe ack id = %q, want s1", r.ID)
+	}
+
+	send(t, trader, `{"op":"submit_limit","id":"c1","ticker":"AAPL","user":1,"side":"sell","price":15000,"size":10}`)
+	var rested submitAck
+	if err := json.Unmarshal(next(t, trader, TypeAck).Report, &rested); err != nil {
+		t.Fatalf("decode ack: %v", err)
+	}
+	if !rested.Rested || rested.RestedSize != 10 {
+		t.Fatalf("rested ack = %+v, want 10 resting", rested)
+	}
+
+	send(t, trader, `{"op":"submit_market","id":"c2","ticker":1,"user":2,"side":"buy","size":4}`)
+	var filled submitAck
+	if err := json.Unmarshal(next(t, trader, TypeAck).Report, &filled); err != nil {
+		t.Fatalf("decode ack: %v", err)
+	}
+	if len(filled.Fills) != 1 || filled.Fills[0].MakerOrderID != rested.OrderID || filled.Fills[0].Size != 4 {
+		t.Fatalf("market ack fills = %+v, want 4 against order %d", filled.Fills, rested.OrderID)
+	}
+
+	var trade struct {
+		Price        int64
+		Size         int64
+		MakerOrderID int64
+	}
+	if err := json.Unmarshal(next(t, watcher, "trade").Event, &trade); err != nil {
+		t.Fatalf("decode trade: %v", err)
+	}
+	if trade.Price != 15000 || trade.Size != 4 || trade.MakerOrderID != int64(rested.OrderID) {
+		t.Fatalf("trade = %+v, want 4 @ 15000 against order %d", trade, rested.OrderID)
+	}
+
+	send(t, trader, `{"op":"cancel","id":"c3","ticker":"AAPL","order_id":`+jsonInt(rested.OrderID)+`}`)
+	var canceled cancelAck
+	if err := json.Unmarshal(next(t, trader, TypeAck).Report, &canceled); err != nil {
+		t.Fatalf("decode ack: %v", err)
+	}
+	if canceled.CanceledSize != 6 {
+		t.Fatalf("canceled size = %d, want 6", canceled.CanceledSize)
+	}
+}
+
+func TestStreamsBBO(t *testing.T) {
+	url := newTestServer(t)
+	ws := dial(t, url)
+
+	send(t, ws, `{"op":"subscribe","tickers":[1]}`)
+	next(t, ws, TypeAck)
+	send(t, ws, `{"op":"submit_limit","ticker":"AAPL","user":1,"side":"buy","price":14900,"size":3}`)
+
+	var q struct {
+		BidPrice int64
+		BidSize  int64
+		BidOK    bool
+		AskOK    bool
+	}
+	if err := json.Unmarshal(next(t, ws, TypeBBO).Event, &q); err != nil {
+		t.Fatalf("decode bbo: %v", err)
+	}
+	if !q.BidOK || q.BidPrice != 14900 || q.BidSize != 3 || q.AskOK {
+		t.Fatalf("bbo = %+v, want bid 3 @ 14900 and no ask", q)
+	}
+}
+
+func TestRejections(t *testing.T) {
+	url := newTestServer(t)
+	ws := dial(t, url)
+
+	cases := []struct {
+		msg  string
+		code order.Code
+	}{
+		{`not json`, CodeBadRequest},
+		{`{"op":"shout","id":"x"}`, CodeBadRequest},
+		{`{"op":"subscribe","id":"x","tickers":["NOPE"]}`, order.CodeUnknownTicker},
+		{`{"op":"submit_limit","id":"x","ticker":"NOPE","user":1,"side":"buy","price":100,"size":1}`, order.CodeUnknownTicker},
+		{`{"op":"submit_limit","id":"x","ticker":"AAPL","user":1,"side":"up","price":100,"size":1}`, order.CodeInvalidSide},
+		{`{"op":"submit_limit","id":"x","ticker":"AAPL","user":1,"side":"buy","price":100,"size":0}`, order.CodeInvalidSize},
+		{`{"op":"submit_limit","id":"x","ticker":"AAPL","user":1,"side":"buy","price":100,"size":1,"tif":"day"}`, order.CodeInvalidTIF},
+		{`{"op":"cancel","id":"x","ticker":"AAPL","order_id":99}`, CodeOrderNotFound},
+		{`{"op":"cancel","id":"x","ticker":"AAPL","order_id":0}`, CodeBadRequest},
+	}
+	for _, tc := range cases {
+		send(t, ws, tc.msg)
+		if r := next(t, ws, TypeError); r.Code != tc.code {
+			t.Errorf("%s: code = %s, want %s", tc.msg, r.Code, tc.code)
+		}
+	}
+}
+
+func TestSlowConsumerIsClosed(t *testing.T) {
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	m := marketservice.NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	t.Cleanup(m.Close)
+	srv := httptest.NewServer(NewServer(m, Config{Buffer: 1}))
+	t.Cleanup(srv.Close)
+	ws := dial(t, "ws"+strings.TrimPrefix(srv.URL, "http"))
+
+	send(t, ws, `{"op":"`+string(feed.OpSubscribe)+`","tickers":["AAPL"]}`)
+	next(t, ws, TypeAck)
+	// Stop reading; the stream soon outruns a one-line queue
+	for i := range 200 {
+		send(t, ws, `{"op":"submit_limit","ticker":"AAPL","user":1,"side":"buy","price":`+jsonInt(1000+i)+`,"size":1}`)
+	}
+
+	ws.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, _, err := ws.ReadMessage()
+		var ne net.Error
+		if errors.As(err, &ne) && ne.Timeout() {
+			t.Fatal("slow consumer was not disconnected")
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func jsonInt[T ~int64 | ~int](v T) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
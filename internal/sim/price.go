@@ -0,0 +1,198 @@
+// Package sim holds the random processes behind the background market that
+// keeps the demo books moving.
+package sim
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// PriceConfig holds the parameters of a PriceProcess.
+type PriceConfig struct {
+	// Volatility is the standard deviation of a step, in ticks, in calm
+	// markets.
+	Volatility float64
+	// NewsVolatility is how much each point of news severity adds to the
+	// volatility multiplier of the tickers the news is about. Market-wide
+	// news affects every ticker. 0 makes the process ignore news.
+	NewsVolatility float64
+	// NewsHalfLife is how long the added multiplier takes to halve.
+	NewsHalfLife time.Duration
+	// MaxMultiplier caps the volatility multiplier.
+	MaxMultiplier float64
+	// ImpactCooldown is the least time between two news impacts on a
+	// ticker, whatever their source; items inside the window add nothing.
+	// Market-wide news has a window of its own. 0 means no cooldown.
+	ImpactCooldown time.Duration
+	// Seed seeds the random source.
+	Seed uint64
+	// Clock provides the time for decay; nil means the real clock.
+	Clock clock.Clock
+}
+
+// DefaultPriceConfig returns a PriceConfig with reasonable defaults.
+func DefaultPriceConfig() PriceConfig {
+	return PriceConfig{
+		Volatility:     15,
+		NewsVolatility: 1.5,
+		NewsHalfLife:   20 * time.Second,
+		MaxMultiplier:  6,
+		ImpactCooldown: 5 * time.Second,
+	}
+}
+
+// boost is an added volatility multiplier as of a point in time.
+type boost struct {
+	value float64
+	at    time.Time
+}
+
+// ImpactStats counts the news items a PriceProcess observed that could have
+// moved prices: severity above 0, with NewsVolatility on.
+type ImpactStats struct {
+	Applied    int64 // raised volatility
+	System     int64 // ignored as news.SourceSystem
+	CooledDown int64 // ignored inside their ticker's ImpactCooldown
+}
+
+// PriceProcess draws random price moves whose size rises after severe news
+// and decays back. It is safe for concurrent use.
+type PriceProcess struct {
+	cfg PriceConfig
+
+	mu         sync.Mutex
+	rng        *rand.Rand
+	boosts     map[market.TickerID]boost // 0 holds market-wide news
+	lastImpact map[market.TickerID]time.Time
+	lastNews   news.NewsID
+	stats      ImpactStats
+}
+
+// NewPriceProcess creates a PriceProcess.
+func NewPriceProcess(cfg PriceConfig) *PriceProcess {
+	def := DefaultPriceConfig()
+	if cfg.Volatility <= 0 {
+		cfg.Volatility = def.Volatility
+	}
+	if cfg.NewsVolatility < 0 {
+		cfg.NewsVolatility = 0
+	}
+	if cfg.NewsHalfLife <= 0 {
+		cfg.NewsHalfLife = def.NewsHalfLife
+	}
+	if cfg.MaxMultiplier < 1 {
+		cfg.MaxMultiplier = def.MaxMultiplier
+	}
+	if cfg.ImpactCooldown < 0 {
+		cfg.ImpactCooldown = 0
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
+	return &PriceProcess{
+		cfg:        cfg,
+		rng:        rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+		boosts:     make(map[market.TickerID]boost),
+		lastImpact: make(map[market.TickerID]time.Time),
+	}
+}
+
+// Observe raises the volatility of the tickers a news item is about by its
+// severity. Items of severity 0 or below have no effect, and neither do
+// news.SourceSystem items, which the simulation generates about itself
+// (halts, reloads), nor items inside their ticker's ImpactCooldown. This
+// keeps a halt from feeding back into the move that caused it.
+func (p *PriceProcess) Observe(item news.NewsItem) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.observe(item)
+}
+
+// ObserveLatest observes the items of a news feed's Latest that are newer
+// than any seen before, so it can be called with overlapping windows. Items
+// are judged as Observe does: system items and items held back by the
+// cooldown are seen but never applied later.
+func (p *PriceProcess) ObserveLatest(items []news.NewsItem) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, item := range items {
+		if item.ID <= p.lastNews {
+			continue
+		}
+		p.lastNews = item.ID
+		p.observe(item)
+	}
+}
+
+func (p *PriceProcess) observe(item news.NewsItem) {
+	if item.Severity <= 0 || p.cfg.NewsVolatility == 0 {
+		return
+	}
+	if item.Source == news.SourceSystem {
+		p.stats.System++
+		return
+	}
+	now := p.cfg.Clock.Now()
+	if last, ok := p.lastImpact[item.Ticker]; ok && now.Sub(last) < p.cfg.ImpactCooldown {
+		p.stats.CooledDown++
+		return
+	}
+	p.lastImpact[item.Ticker] = now
+	p.stats.Applied++
+
+	b := p.boosts[item.Ticker]
+	b.value = p.decayed(b, now) + p.cfg.NewsVolatility*float64(item.Severity)
+	b.at = now
+	p.boosts[item.Ticker] = b
+}
+
+func (p *PriceProcess) decayed(b boost, now time.Time) float64 {
+	if b.value == 0 {
+		return 0
+	}
+	halvings := float64(now.Sub(b.at)) / float64(p.cfg.NewsHalfLife)
+	return b.value * math.Exp2(-halvings)
+}
+
+// ImpactStats returns the counts of news items applied and ignored so far.
+func (p *PriceProcess) ImpactStats() ImpactStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+// Multiplier returns the ticker's current volatility multiplier, 1 in calm
+// markets.
+func (p *PriceProcess) Multiplier(tid market.TickerID) float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.multiplier(tid, p.cfg.Clock.Now())
+}
+
+func (p *PriceProcess) multiplier(tid market.TickerID, now time.Time) float64 {
+	m := 1 + p.decayed(p.boosts[tid], now)
+	if tid != 0 {
+		m += p.decayed(p.boosts[0], now)
+	}
+	return min(m, p.cfg.MaxMultiplier)
+}
+
+// Volatility returns the ticker's current step standard deviation in ticks.
+func (p *PriceProcess) Volatility(tid market.TickerID) float64 {
+	return p.cfg.Volatility * p.Multiplier(tid)
+}
+
+// Step draws a price move for the ticker, in ticks.
+func (p *PriceProcess) Step(tid market.TickerID) core.PriceTicks {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sd := p.cfg.Volatility * p.multiplier(tid, p.cfg.Clock.Now())
+	return core.PriceTicks(math.Round(p.rng.NormFloat64() * sd))
+}
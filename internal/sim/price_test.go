@@ -0,0 +1,181 @@
+package sim
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/news"
+)
+
+// sampledVolatility returns the standard deviation of n steps.
+func sampledVolatility(p *PriceProcess, tid market.TickerID, n int) float64 {
+	var sum, sumSq float64
+	for i := 0; i < n; i++ {
+		x := float64(p.Step(tid))
+		sum += x
+		sumSq += x * x
+	}
+	mean := sum / float64(n)
+	return math.Sqrt(sumSq/float64(n) - mean*mean)
+}
+
+func TestNewsRaisesVolatilityThenDecays(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	cfg := DefaultPriceConfig()
+	cfg.Seed = 42
+	cfg.Clock = clk
+	p := NewPriceProcess(cfg)
+
+	calm := sampledVolatility(p, 1, 2000)
+	if math.Abs(calm-cfg.Volatility) > 1 {
+		t.Fatalf("expected calm volatility near %v, got %v", cfg.Volatility, calm)
+	}
+
+	p.ObserveLatest([]news.NewsItem{{ID: 1, Ticker: 1, Headline: "FLASH: Merger deal falls through", Severity: 2}})
+	if m := p.Multiplier(1); m != 1+2*cfg.NewsVolatility {
+		t.Errorf("expected multiplier %v, got %v", 1+2*cfg.NewsVolatility, m)
+	}
+	if m := p.Multiplier(2); m != 1 {
+		t.Errorf("news about ticker 1 should not move ticker 2, got %v", m)
+	}
+	turbulent := sampledVolatility(p, 1, 2000)
+	if turbulent < 3*calm {
+		t.Errorf("expected volatility well above %v after news, got %v", calm, turbulent)
+	}
+
+	// Seeing the same item again does not add to it
+	p.ObserveLatest([]news.NewsItem{{ID: 1, Ticker: 1, Severity: 2}})
+	if m := p.Multiplier(1); m != 1+2*cfg.NewsVolatility {
+		t.Errorf("repeated item should be ignored, got multiplier %v", m)
+	}
+
+	clk.Advance(cfg.NewsHalfLife)
+	if m, want := p.Multiplier(1), 1+cfg.NewsVolatility; math.Abs(m-want) > 1e-9 {
+		t.Errorf("expected multiplier %v after one half-life, got %v", want, m)
+	}
+
+	clk.Advance(10 * cfg.NewsHalfLife)
+	settled := sampledVolatility(p, 1, 2000)
+	if math.Abs(settled-calm) > 1 {
+		t.Errorf("expected volatility to decay back to %v, got %v", calm, settled)
+	}
+}
+
+func TestMarketWideNewsAndCap(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	cfg := DefaultPriceConfig()
+	cfg.Clock = clk
+	p := NewPriceProcess(cfg)
+
+	p.Observe(news.NewsItem{Severity: 1})
+	for _, tid := range []market.TickerID{1, 2} {
+		if m := p.Multiplier(tid); m != 1+cfg.NewsVolatility {
+			t.Errorf("ticker %d: expected market-wide multiplier %v, got %v", tid, 1+cfg.NewsVolatility, m)
+		}
+	}
+
+	p.Observe(news.NewsItem{Ticker: 1, Severity: 100})
+	if m := p.Multiplier(1); m != cfg.MaxMultiplier {
+		t.Errorf("expected multiplier capped at %v, got %v", cfg.MaxMultiplier, m)
+	}
+
+	p.Observe(news.NewsItem{Ticker: 2, Severity: 0})
+	if m := p.Multiplier(2); m != 1+cfg.NewsVolatility {
+		t.Errorf("routine news should not add volatility, got %v", m)
+	}
+}
+
+func TestNewsVolatilityOff(t *testing.T) {
+	cfg := DefaultPriceConfig()
+	cfg.NewsVolatility = 0
+	p := NewPriceProcess(cfg)
+
+	p.Observe(news.NewsItem{Ticker: 1, Severity: 5})
+	if m := p.Multiplier(1); m != 1 {
+		t.Errorf("expected news to be ignored, got multiplier %v", m)
+	}
+}
+
+func TestNewsImpactLoopProtection(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	cfg := DefaultPriceConfig()
+	cfg.Clock = clk
+	cfg.ImpactCooldown = 10 * time.Second
+	p := NewPriceProcess(cfg)
+	want := 1 + 2*cfg.NewsVolatility
+
+	// A headline moves ticker 1, the move halts it, and the halt is
+	// announced as system news: no secondary impact
+	p.Observe(news.NewsItem{Ticker: 1, Headline: "FLASH: Guidance cut", Severity: 2})
+	p.Observe(news.NewsItem{Ticker: 1, Headline: "AAPL halted: limit down", Severity: 3, Source: news.SourceSystem})
+	if m := p.Multiplier(1); m != want {
+		t.Errorf("expected the halt headline to add nothing, got multiplier %v", m)
+	}
+
+	// A second headline inside the window is held back whatever its source
+	clk.Advance(5 * time.Second)
+	before := p.Multiplier(1)
+	p.Observe(news.NewsItem{Ticker: 1, Headline: "Analysts react to the cut", Severity: 2, Source: news.SourceScenario})
+	if m := p.Multiplier(1); m != before {
+		t.Errorf("expected no impact inside the cooldown, got %v from %v", m, before)
+	}
+	// Other tickers and market-wide news have windows of their own
+	p.Observe(news.NewsItem{Ticker: 2, Severity: 1})
+	if m := p.Multiplier(2); m != 1+cfg.NewsVolatility {
+		t.Errorf("expected ticker 2 to move, got multiplier %v", m)
+	}
+
+	clk.Advance(5 * time.Second)
+	p.Observe(news.NewsItem{Ticker: 1, Severity: 2})
+	if m := p.Multiplier(1); m <= before {
+		t.Errorf("expected an impact once the cooldown ended, got %v", m)
+	}
+
+	if got, want := p.ImpactStats(), (ImpactStats{Applied: 3, System: 1, CooledDown: 1}); got != want {
+		t.Errorf("expected stats %+v, got %+v", want, got)
+	}
+}
+
+func TestObserveLatestSkipsSystemNewsAndCoolsDown(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	cfg := DefaultPriceConfig()
+	cfg.Clock = clk
+	p := NewPriceProcess(cfg)
+
+	// A scenario reload announces itself as system news
+	feed := []news.NewsItem{{ID: 1, Headline: "Scenario reloaded: 3 added, 1 removed", Severity: 1, Source: news.SourceSystem}}
+	p.ObserveLatest(feed)
+	if m := p.Multiplier(1); m != 1 {
+		t.Errorf("expected the reload to leave volatility alone, got multiplier %v", m)
+	}
+
+	// Two headlines on ticker 1 in one window: only the first applies
+	feed = append(feed,
+		news.NewsItem{ID: 2, Ticker: 1, Severity: 2, Source: news.SourceScenario},
+		news.NewsItem{ID: 3, Ticker: 1, Severity: 2},
+	)
+	p.ObserveLatest(feed)
+	want := 1 + 2*cfg.NewsVolatility
+	if m := p.Multiplier(1); m != want {
+		t.Errorf("expected multiplier %v from the first headline only, got %v", want, m)
+	}
+
+	// The held-back item is not applied when the window ends
+	clk.Advance(cfg.ImpactCooldown)
+	before := p.Multiplier(1)
+	p.ObserveLatest(feed)
+	if m := p.Multiplier(1); m != before {
+		t.Errorf("expected the held-back item to stay dropped, got %v from %v", m, before)
+	}
+	p.ObserveLatest(append(feed, news.NewsItem{ID: 4, Ticker: 1, Severity: 2}))
+	if m := p.Multiplier(1); m <= before {
+		t.Errorf("expected a new item after the window to apply, got %v", m)
+	}
+
+	if got, want := p.ImpactStats(), (ImpactStats{Applied: 2, System: 1, CooledDown: 1}); got != want {
+		t.Errorf("expected stats %+v, got %+v", want, got)
+	}
+}
@@ -0,0 +1,144 @@
+package stats
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// BBOSample is a timestamped best bid/ask observation.
+type BBOSample struct {
+	Time     int64 // unix nanos
+	BidPrice core.PriceTicks
+	BidOK    bool
+	AskPrice core.PriceTicks
+	AskOK    bool
+}
+
+// Mid returns the mid price, or false if either side is missing.
+func (s BBOSample) Mid() (core.PriceTicks, bool) {
+	if !s.BidOK || !s.AskOK {
+		return 0, false
+	}
+	return (s.BidPrice + s.AskPrice) / 2, true
+}
+
+func (s BBOSample) sameQuote(o BBOSample) bool {
+	return s.BidPrice == o.BidPrice && s.BidOK == o.BidOK &&
+		s.AskPrice == o.AskPrice && s.AskOK == o.AskOK
+}
+
+// BBOHistory keeps a bounded ring of best bid/ask samples per ticker.
+//
+// Samples are only stored when the quote changes. Changes that arrive within
+// MinInterval of the last stored sample replace it instead of appending, so
+// a busy book costs at most one slot per interval. It is safe for concurrent
+// use.
+type BBOHistory struct {
+	mu          sync.Mutex
+	capacity    int
+	minInterval time.Duration
+	rings       map[market.TickerID]*bboRing
+}
+
+// NewBBOHistory creates a history keeping up to capacity samples per ticker,
+// stored at most once per minInterval.
+func NewBBOHistory(capacity int, minInterval time.Duration) *BBOHistory {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &BBOHistory{
+		capacity:    capacity,
+		minInterval: minInterval,
+		rings:       make(map[market.TickerID]*bboRing),
+	}
+}
+
+// Record offers a sample for a ticker. It reports whether the history changed.
+func (h *BBOHistory) Record(tid market.TickerID, s BBOSample) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	r, ok := h.rings[tid]
+	if !ok {
+		r = &bboRing{buf: make([]BBOSample, h.capacity)}
+		h.rings[tid] = r
+	}
+	last, ok := r.last()
+	switch {
+	case !ok:
+		r.push(s)
+	case last.sameQuote(s):
+		return false
+	case s.Time-last.Time < int64(h.minInterval):
+		r.replaceLast(s)
+	default:
+		r.push(s)
+	}
+	return true
+}
+
+// Last returns up to n of a ticker's most recent samples, oldest first.
+func (h *BBOHistory) Last(tid market.TickerID, n int) []BBOSample {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	r, ok := h.rings[tid]
+	if !ok {
+		return nil
+	}
+	return r.lastN(n)
+}
+
+// Len returns the number of samples stored for a ticker.
+func (h *BBOHistory) Len(tid market.TickerID) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if r, ok := h.rings[tid]; ok {
+		return r.count
+	}
+	return 0
+}
+
+type bboRing struct {
+	buf   []BBOSample
+	start int
+	count int
+}
+
+func (r *bboRing) push(s BBOSample) {
+	if r.count < len(r.buf) {
+		r.buf[(r.start+r.count)%len(r.buf)] = s
+		r.count++
+		return
+	}
+	r.buf[r.start] = s
+	r.start = (r.start + 1) % len(r.buf)
+}
+
+func (r *bboRing) last() (BBOSample, bool) {
+	if r.count == 0 {
+		return BBOSample{}, false
+	}
+	return r.buf[(r.start+r.count-1)%len(r.buf)], true
+}
+
+func (r *bboRing) replaceLast(s BBOSample) {
+	r.buf[(r.start+r.count-1)%len(r.buf)] = s
+}
+
+func (r *bboRing) lastN(n int) []BBOSample {
+	if n <= 0 || r.count == 0 {
+		return nil
+	}
+	if n > r.count {
+		n = r.count
+	}
+	out := make([]BBOSample, n)
+	first := r.start + r.count - n
+	for i := range out {
+		out[i] = r.buf[(first+i)%len(r.buf)]
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func quote(t int64, bid, ask core.PriceTicks) BBOSample {
+	return BBOSample{Time: t, BidPrice: bid, BidOK: true, AskPrice: ask, AskOK: true}
+}
+
+func TestBBOHistoryBounded(t *testing.T) {
+	h := NewBBOHistory(4, 0)
+	for i := 0; i < 10; i++ {
+		h.Record(1, quote(int64(i+1), core.PriceTicks(100+i), core.PriceTicks(102+i)))
+	}
+	if n := h.Len(1); n != 4 {
+		t.Fatalf("expected 4 samples, got %d", n)
+	}
+	got := h.Last(1, 10)
+	if len(got) != 4 || got[0].BidPrice != 106 || got[3].BidPrice != 109 {
+		t.Errorf("expected the 4 newest samples oldest first, got %+v", got)
+	}
+	if h.Last(2, 5) != nil {
+		t.Error("expected no samples for an unknown ticker")
+	}
+}
+
+func TestBBOHistoryRecordsOnChangeAtMaxCadence(t *testing.T) {
+	sec := int64(time.Second)
+	h := NewBBOHistory(16, time.Second)
+
+	if !h.Record(1, quote(1*sec, 100, 102)) {
+		t.Fatal("expected first sample to be stored")
+	}
+	if h.Record(1, quote(5*sec, 100, 102)) {
+		t.Error("expected an unchanged quote to be skipped")
+	}
+
+	// Two changes inside one interval collapse into one slot holding the latest
+	h.Record(1, quote(6*sec, 101, 103))
+	h.Record(1, quote(6*sec+sec/2, 99, 104))
+	h.Record(1, quote(8*sec, 100, 101))
+
+	got := h.Last(1, 16)
+	if len(got) != 3 {
+		t.Fatalf("expected 3 stored samples, got %d: %+v", len(got), got)
+	}
+	if got[1].BidPrice != 99 || got[1].AskPrice != 104 {
+		t.Errorf("expected the collapsed slot to hold the latest quote, got %+v", got[1])
+	}
+	if mid, ok := got[2].Mid(); !ok || mid != 100 {
+		t.Errorf("expected mid 100, got %d (%v)", mid, ok)
+	}
+	oneSided := BBOSample{Time: 9 * sec, BidPrice: 100, BidOK: true}
+	if _, ok := oneSided.Mid(); ok {
+		t.Error("expected no mid for a one-sided quote")
+	}
+}
@@ -0,0 +1,63 @@
+package stats
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+)
+
+// Layout selects how samples are spread across CSV files.
+type Layout uint8
+
+const (
+	// LayoutWide writes one file with a column group per ticker.
+	LayoutWide Layout = iota
+	// LayoutPerTicker writes one file per ticker.
+	LayoutPerTicker
+)
+
+// Config holds configuration for a Recorder.
+type Config struct {
+	// Dir is the directory CSV files are written to.
+	Dir string
+	// Name is the run name. Files are Name.csv (wide) or Name-TICKER.csv
+	// (per ticker). Defaults to a timestamped name.
+	Name string
+	// Layout selects wide or per-ticker files.
+	Layout Layout
+	// Interval is the sampling period.
+	Interval time.Duration
+	// FlushInterval is how often buffered rows are flushed to disk.
+	FlushInterval time.Duration
+	// MaxBytes rotates a file once it reaches this size (0 = no limit).
+	// Rotated files are kept as NAME.csv.1, NAME.csv.2, ...
+	MaxBytes int64
+	// RowBuffer is the number of samples that may wait for the writer.
+	// Samples are dropped, not blocked on, when it is full.
+	RowBuffer int
+	// Clock drives sampling and flushing.
+	Clock clock.Clock
+	// Create opens a new output file. Defaults to os.Create.
+	Create func(name string) (io.WriteCloser, error)
+	// Rename moves a full file aside as a rotated segment. Defaults to
+	// os.Rename.
+	Rename func(oldpath, newpath string) error
+}
+
+// DefaultConfig returns a Config with reasonable defaults.
+func DefaultConfig() Config {
+	return Config{
+		Dir:           ".",
+		Layout:        LayoutWide,
+		Interval:      5 * time.Second,
+		FlushInterval: 30 * time.Second,
+		RowBuffer:     64,
+		Clock:         clock.Real(),
+		Create: func(name string) (io.WriteCloser, error) {
+			return os.Create(name)
+		},
+		Rename: os.Rename,
+	}
+}
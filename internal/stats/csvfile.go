@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"slices"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/replay"
+)
+
+// csvFile is a rotating CSV output with a fixed header.
+type csvFile struct {
+	path     string
+	maxBytes int64
+	create   func(name string) (io.WriteCloser, error)
+	rename   func(oldpath, newpath string) error
+
+	header  []string
+	f       io.WriteCloser
+	bw      *bufio.Writer
+	cw      *csv.Writer
+	bytes   int64
+	nextSeg int
+}
+
+func newCSVFile(path string, maxBytes int64, create func(string) (io.WriteCloser, error), rename func(string, string) error) (*csvFile, error) {
+	segs, err := replay.Segments(path)
+	if err != nil {
+		return nil, err
+	}
+	cf := &csvFile{path: path, maxBytes: maxBytes, create: create, rename: rename, nextSeg: 1}
+	if len(segs) > 0 {
+		cf.nextSeg = segs[len(segs)-1] + 1
+	}
+	return cf, nil
+}
+
+// countingWriter tracks how many bytes pass through to w.
+type countingWriter struct {
+	w io.Writer
+	n *int64
+}
+
+func (c countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
+
+// write appends a record, (re)opening the file if the header changed or the
+// size limit was reached.
+func (cf *csvFile) write(header, record []string) error {
+	switch {
+	case cf.f == nil:
+		if err := cf.open(header); err != nil {
+			return err
+		}
+	case !slices.Equal(header, cf.header),
+		cf.maxBytes > 0 && cf.bytes >= cf.maxBytes:
+		if err := cf.rotate(header); err != nil {
+			return err
+		}
+	}
+	if err := cf.cw.Write(record); err != nil {
+		return err
+	}
+	cf.cw.Flush()
+	return cf.cw.Error()
+}
+
+func (cf *csvFile) open(header []string) error {
+	f, err := cf.create(cf.path)
+	if err != nil {
+		return err
+	}
+	cf.f = f
+	cf.bw = bufio.NewWriter(f)
+	cf.bytes = 0
+	cf.cw = csv.NewWriter(countingWriter{w: cf.bw, n: &cf.bytes})
+	cf.header = header
+	if err := cf.cw.Write(header); err != nil {
+		return err
+	}
+	cf.cw.Flush()
+	return cf.cw.Error()
+}
+
+func (cf *csvFile) rotate(header []string) error {
+	if err := cf.close(); err != nil {
+		return err
+	}
+	if err := cf.rename(cf.path, replay.SegmentName(cf.path, cf.nextSeg)); err != nil {
+		return err
+	}
+	cf.nextSeg++
+	return cf.open(header)
+}
+
+func (cf *csvFile) flush() error {
+	if cf.bw == nil {
+		return nil
+	}
+	return cf.bw.Flush()
+}
+
+func (cf *csvFile) close() error {
+	if cf.f == nil {
+		return nil
+	}
+	ferr := cf.bw.Flush()
+	cerr := cf.f.Close()
+	cf.f = nil
+	if ferr != nil {
+		return ferr
+	}
+	return cerr
+}
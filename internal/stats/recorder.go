@@ -0,0 +1,268 @@
+// Package stats records per-ticker market statistics as CSV time series.
+//
+// A Recorder samples a Source on a clock interval and hands rows to a
+// separate writer goroutine over a bounded buffer, so a slow disk drops
+// samples instead of stalling the sampler or the market it reads from.
+package stats
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/money"
+)
+
+// tickerColumns are the per-ticker fields written for every sample.
+var tickerColumns = []string{
+	"bid", "ask", "last", "volume",
+	"bid_orders", "ask_orders",
+	"cmd_queue", "event_queue", "ext_queue",
+}
+
+type row struct {
+	time   time.Time
+	sample Sample
+}
+
+// Recorder periodically samples a Source and appends the samples to CSV files.
+type Recorder struct {
+	cfg   Config
+	src   Source
+	rows  chan row
+	files map[string]*csvFile // keyed by ticker name; "" for the wide file
+
+	samples atomic.Int64
+	dropped atomic.Int64
+	written atomic.Int64
+
+	errMu sync.Mutex
+	err   error
+
+	closed    chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// NewRecorder creates a Recorder and starts sampling.
+func NewRecorder(src Source, cfg Config) *Recorder {
+	def := DefaultConfig()
+	if cfg.Dir == "" {
+		cfg.Dir = def.Dir
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = def.Interval
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = def.FlushInterval
+	}
+	if cfg.RowBuffer <= 0 {
+		cfg.RowBuffer = def.RowBuffer
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = def.Clock
+	}
+	if cfg.Create == nil {
+		cfg.Create = def.Create
+	}
+	if cfg.Rename == nil {
+		cfg.Rename = def.Rename
+	}
+	if cfg.Name == "" {
+		cfg.Name = "stats-" + cfg.Clock.Now().UTC().Format("20060102-150405")
+	}
+
+	r := &Recorder{
+		cfg:    cfg,
+		src:    src,
+		rows:   make(chan row, cfg.RowBuffer),
+		files:  make(map[string]*csvFile),
+		closed: make(chan struct{}),
+	}
+
+	// Create the ticker before returning so callers can advance a manual
+	// clock immediately.
+	ticker := cfg.Clock.NewTicker(cfg.Interval)
+
+	r.wg.Add(2)
+	go r.runSampler(ticker)
+	go r.runWriter()
+
+	return r
+}
+
+func (r *Recorder) runSampler(ticker clock.Ticker) {
+	defer r.wg.Done()
+	defer close(r.rows)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.closed:
+			return
+		case t := <-ticker.C():
+			rw := row{time: t, sample: r.src.Sample()}
+			r.samples.Add(1)
+			select {
+			case r.rows <- rw:
+			default:
+				r.dropped.Add(1)
+			}
+		}
+	}
+}
+
+func (r *Recorder) runWriter() {
+	defer r.wg.Done()
+
+	var lastFlush time.Time
+	for rw := range r.rows {
+		if r.Err() != nil {
+			continue
+		}
+		if err := r.writeRow(rw); err != nil {
+			r.setErr(err)
+			continue
+		}
+		r.written.Add(1)
+		if lastFlush.IsZero() {
+			lastFlush = rw.time
+		}
+		if rw.time.Sub(lastFlush) >= r.cfg.FlushInterval {
+			r.setErr(r.flush())
+			lastFlush = rw.time
+		}
+	}
+
+	for _, cf := range r.files {
+		r.setErr(cf.close())
+	}
+}
+
+func (r *Recorder) writeRow(rw row) error {
+	ts := rw.time.UTC().Format(time.RFC3339)
+	mq := strconv.Itoa(rw.sample.MarketQueue)
+
+	if r.cfg.Layout == LayoutPerTicker {
+		header := append([]string{"time", "ticker", "market_queue"}, tickerColumns...)
+		for _, t := range rw.sample.Tickers {
+			cf, err := r.file(t.Name)
+			if err != nil {
+				return err
+			}
+			record := append([]string{ts, t.Name, mq}, tickerFields(t)...)
+			if err := cf.write(header, record); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	header := []string{"time", "market_queue"}
+	record := []string{ts, mq}
+	for _, t := range rw.sample.Tickers {
+		for _, col := range tickerColumns {
+			header = append(header, t.Name+"."+col)
+		}
+		record = append(record, tickerFields(t)...)
+	}
+	cf, err := r.file("")
+	if err != nil {
+		return err
+	}
+	return cf.write(header, record)
+}
+
+func (r *Recorder) file(ticker string) (*csvFile, error) {
+	if cf, ok := r.files[ticker]; ok {
+		return cf, nil
+	}
+	name := r.cfg.Name + ".csv"
+	if ticker != "" {
+		name = fmt.Sprintf("%s-%s.csv", r.cfg.Name, ticker)
+	}
+	cf, err := newCSVFile(filepath.Join(r.cfg.Dir, name), r.cfg.MaxBytes, r.cfg.Create, r.cfg.Rename)
+	if err != nil {
+		return nil, err
+	}
+	r.files[ticker] = cf
+	return cf, nil
+}
+
+func (r *Recorder) flush() error {
+	for _, cf := range r.files {
+		if err := cf.flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tickerFields formats a ticker sample in tickerColumns order.
+// Missing prices are written as empty cells.
+func tickerFields(t TickerSample) []string {
+	price := func(p int64, ok bool) string {
+		if !ok {
+			return ""
+		}
+		return money.FormatPrice(p, t.Decimals)
+	}
+	return []string{
+		price(int64(t.BidPrice), t.BidOK),
+		price(int64(t.AskPrice), t.AskOK),
+		price(int64(t.LastPrice), t.HasLast),
+		strconv.FormatInt(int64(t.Volume), 10),
+		strconv.Itoa(t.BidOrders),
+		strconv.Itoa(t.AskOrders),
+		strconv.Itoa(t.CommandQueue),
+		strconv.Itoa(t.EventQueue),
+		strconv.Itoa(t.ExternalQueue),
+	}
+}
+
+func (r *Recorder) setErr(err error) {
+	if err == nil {
+		return
+	}
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// Err returns the first write error, if any. Sampling continues after an
+// error but nothing more is written.
+func (r *Recorder) Err() error {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	return r.err
+}
+
+// Samples returns the number of samples taken.
+func (r *Recorder) Samples() int64 {
+	return r.samples.Load()
+}
+
+// Written returns the number of samples written.
+func (r *Recorder) Written() int64 {
+	return r.written.Load()
+}
+
+// Dropped returns the number of samples dropped because the writer fell behind.
+func (r *Recorder) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close stops sampling, writes any buffered samples, and closes the files.
+func (r *Recorder) Close() error {
+	r.closeOnce.Do(func() {
+		close(r.closed)
+	})
+	r.wg.Wait()
+	return r.Err()
+}
@@ -0,0 +1,269 @@
+package stats
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/orderbook/replay"
+)
+
+type fixedSource struct{}
+
+func (fixedSource) Sample() Sample {
+	return Sample{
+		MarketQueue: 1,
+		Tickers: []TickerSample{
+			{Name: "AAPL", Decimals: 2, BidPrice: 17500, BidOK: true, AskPrice: 17510, AskOK: true, Volume: 42},
+			{Name: "MSFT", Decimals: 2, LastPrice: 37500, HasLast: true},
+		},
+	}
+}
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func readCSV(t *testing.T, path string) [][]string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return records
+}
+
+func TestRecorderSamplingCadence(t *testing.T) {
+	clk := clock.NewManual(time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC))
+	dir := t.TempDir()
+	rec := NewRecorder(fixedSource{}, Config{
+		Dir:      dir,
+		Name:     "run",
+		Interval: 5 * time.Second,
+		Clock:    clk,
+	})
+
+	clk.Advance(4 * time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if n := rec.Samples(); n != 0 {
+		t.Fatalf("expected no samples before the first interval, got %d", n)
+	}
+
+	for i := 1; i <= 3; i++ {
+		clk.Advance(time.Second)
+		waitFor(t, func() bool { return rec.Samples() == int64(i) })
+		clk.Advance(4 * time.Second)
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	records := readCSV(t, filepath.Join(dir, "run.csv"))
+	if len(records) != 4 {
+		t.Fatalf("expected header + 3 rows, got %d records", len(records))
+	}
+	header := records[0]
+	if header[0] != "time" || header[2] != "AAPL.bid" || header[11] != "MSFT.bid" {
+		t.Errorf("unexpected header: %v", header)
+	}
+	want := []string{"2024-01-02T09:30:05Z", "1", "175.00", "175.10", "", "42"}
+	for i, w := range want {
+		if records[1][i] != w {
+			t.Errorf("column %s: expected %q, got %q", header[i], w, records[1][i])
+		}
+	}
+	if records[3][0] != "2024-01-02T09:30:15Z" {
+		t.Errorf("expected third sample at 09:30:15, got %s", records[3][0])
+	}
+}
+
+func TestRecorderPerTickerRotation(t *testing.T) {
+	clk := clock.NewManual(time.Unix(0, 0))
+	dir := t.TempDir()
+	rec := NewRecorder(fixedSource{}, Config{
+		Dir:      dir,
+		Name:     "run",
+		Layout:   LayoutPerTicker,
+		Interval: time.Second,
+		MaxBytes: 200,
+		Clock:    clk,
+	})
+
+	numSamples := 10
+	for i := 1; i <= numSamples; i++ {
+		clk.Advance(time.Second)
+		waitFor(t, func() bool { return rec.Written() == int64(i) })
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, "run-AAPL.csv")
+	segs, err := replay.Segments(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(segs) == 0 {
+		t.Fatal("expected the size limit to rotate the file")
+	}
+
+	rows := 0
+	files := []string{path}
+	for _, n := range segs {
+		files = append(files, replay.SegmentName(path, n))
+	}
+	for _, name := range files {
+		records := readCSV(t, name)
+		if records[0][0] != "time" || records[0][1] != "ticker" {
+			t.Errorf("%s: expected every segment to start with the header", name)
+		}
+		rows += len(records) - 1
+		if info, _ := os.Stat(name); info.Size() > 200+100 {
+			t.Errorf("%s: segment grew well past the limit (%d bytes)", name, info.Size())
+		}
+	}
+	if rows != numSamples {
+		t.Errorf("expected %d rows across segments, got %d", numSamples, rows)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "run-MSFT.csv")); err != nil {
+		t.Errorf("expected a file per ticker: %v", err)
+	}
+}
+
+// memFile is an in-memory output file.
+type memFile struct{ bytes.Buffer }
+
+func (*memFile) Close() error { return nil }
+
+func TestRecorderRotatesThroughConfigHooks(t *testing.T) {
+	var mu sync.Mutex
+	files := map[string]*memFile{}
+	clk := clock.NewManual(time.Unix(0, 0))
+	dir := t.TempDir()
+	rec := NewRecorder(fixedSource{}, Config{
+		Dir:      dir,
+		Name:     "run",
+		Interval: time.Second,
+		MaxBytes: 200,
+		Clock:    clk,
+		Create: func(name string) (io.WriteCloser, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			f := &memFile{}
+			files[name] = f
+			return f, nil
+		},
+		Rename: func(oldpath, newpath string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			files[newpath] = files[oldpath]
+			delete(files, oldpath)
+			return nil
+		},
+	})
+
+	for i := 1; i <= 10; i++ {
+		clk.Advance(time.Second)
+		waitFor(t, func() bool { return rec.Written() == int64(i) })
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, "run.csv")
+	mu.Lock()
+	defer mu.Unlock()
+	if files[path] == nil || files[replay.SegmentName(path, 1)] == nil {
+		t.Errorf("expected the active file and a rotated segment in memory, got %d files", len(files))
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected rotation to leave the disk untouched, found %d files", len(entries))
+	}
+}
+
+// slowFile simulates a slow disk.
+type slowFile struct {
+	*os.File
+	delay time.Duration
+}
+
+func (f slowFile) Write(p []byte) (int, error) {
+	time.Sleep(f.delay)
+	return f.File.Write(p)
+}
+
+func TestRecorderSlowDiskDoesNotBlockMarket(t *testing.T) {
+	tickers := []market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}
+	mcfg := marketservice.DefaultConfig()
+	mcfg.DropMarketEvents = false
+	svc := marketservice.NewMarketService(tickers, mcfg)
+	defer svc.Close()
+
+	clk := clock.NewManual(time.Unix(0, 0))
+	rec := NewRecorder(MarketSource(svc), Config{
+		Dir:           t.TempDir(),
+		Name:          "run",
+		Interval:      time.Second,
+		FlushInterval: time.Second,
+		RowBuffer:     1,
+		Clock:         clk,
+		Create: func(name string) (io.WriteCloser, error) {
+			f, err := os.Create(name)
+			return slowFile{File: f, delay: 200 * time.Millisecond}, err
+		},
+	})
+
+	// Drive the sampler well faster than the writer can keep up.
+	numSamples := 20
+	for i := 1; i <= numSamples; i++ {
+		clk.Advance(time.Second)
+		waitFor(t, func() bool { return rec.Samples() == int64(i) })
+	}
+	if rec.Dropped() == 0 {
+		t.Error("expected samples to be dropped while the writer is stalled")
+	}
+
+	// The market event path keeps flowing while the writer is stalled.
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		if _, err := svc.SubmitLimit(ctx, 1, 100, core.SideBuy, core.PriceTicks(100+i), 1); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		<-svc.Events()
+	}
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("market path took %v with a slow stats writer", elapsed)
+	}
+
+	if err := rec.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rec.Written()+rec.Dropped() != rec.Samples() {
+		t.Errorf("expected every sample written or dropped: written=%d dropped=%d samples=%d",
+			rec.Written(), rec.Dropped(), rec.Samples())
+	}
+}
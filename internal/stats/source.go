@@ -0,0 +1,80 @@
+package stats
+
+import (
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// Sample is one observation of the whole market.
+type Sample struct {
+	// MarketQueue is the depth of the consolidated market events channel.
+	MarketQueue int
+	Tickers     []TickerSample
+}
+
+// TickerSample is one observation of a single ticker.
+type TickerSample struct {
+	Name      string
+	Decimals  int8
+	BidPrice  core.PriceTicks
+	BidOK     bool
+	AskPrice  core.PriceTicks
+	AskOK     bool
+	LastPrice core.PriceTicks
+	HasLast   bool
+	Volume    core.Size
+	BidOrders int
+	AskOrders int
+	// Orderbook service channel depths.
+	CommandQueue  int
+	EventQueue    int
+	ExternalQueue int
+}
+
+// Source produces samples for a Recorder.
+type Source interface {
+	Sample() Sample
+}
+
+// MarketSource samples a MarketService. Tickers are reported in ID order.
+func MarketSource(svc *marketservice.MarketService) Source {
+	return marketSource{svc: svc}
+}
+
+type marketSource struct {
+	svc *marketservice.MarketService
+}
+
+func (m marketSource) Sample() Sample {
+	tickers := m.svc.GetTickers()
+
+	snap := m.svc.Snapshot()
+	out := Sample{
+		MarketQueue: m.svc.QueueDepth(),
+		Tickers:     make([]TickerSample, 0, len(tickers)),
+	}
+	for _, t := range tickers {
+		tid := t.TickerID()
+		bp := snap.ByTicker[tid]
+		ts := TickerSample{
+			Name:      t.Name,
+			Decimals:  t.Decimals,
+			BidPrice:  bp.BidPrice,
+			BidOK:     bp.BidOK,
+			AskPrice:  bp.AskPrice,
+			AskOK:     bp.AskOK,
+			LastPrice: bp.LastPrice,
+			HasLast:   bp.HasLast,
+			Volume:    bp.Volume,
+		}
+		ts.BidOrders, _ = m.svc.GetOrderCount(tid, core.SideBuy)
+		ts.AskOrders, _ = m.svc.GetOrderCount(tid, core.SideSell)
+		if q, err := m.svc.GetQueueDepths(tid); err == nil {
+			ts.CommandQueue = q.Commands
+			ts.EventQueue = q.Events
+			ts.ExternalQueue = q.External
+		}
+		out.Tickers = append(out.Tickers, ts)
+	}
+	return out
+}
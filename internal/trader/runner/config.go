@@ -1,6 +1,10 @@
 package runner
 
-import "time"
+import (
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+)
 
 // Config holds configuration for the trader runner.
 type Config struct {
@@ -10,6 +14,9 @@ type Config struct {
 	EventBuffer int
 	// DropEvents determines whether the events channel drops on overflow.
 	DropEvents bool
+	// Clock drives the tick loop and timestamps steps and events. Nil uses
+	// the real clock.
+	Clock clock.Clock
 }
 
 // DefaultConfig returns a Config with reasonable defaults.
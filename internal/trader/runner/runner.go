@@ -4,8 +4,9 @@ import (
 	"context"
 	"sync"
 	"sync/atomic"
-	"time"
 
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/order"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 	"github.com/zappabad/stockcraft/internal/trader"
 	"github.com/zappabad/stockcraft/internal/trader/strategy"
@@ -43,6 +44,9 @@ func NewRunner(
 	if cfg.EventBuffer <= 0 {
 		cfg.EventBuffer = DefaultConfig().EventBuffer
 	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
 
 	r := &Runner{
 		cfg:      cfg,
@@ -55,24 +59,25 @@ func NewRunner(
 		closed:   make(chan struct{}),
 	}
 
+	// Created before the loop starts, so a manual clock advanced right after
+	// NewRunner returns still ticks it
+	ticker := cfg.Clock.NewTicker(cfg.TickInterval)
 	r.wg.Add(1)
-	go r.run()
+	go r.run(ticker)
 
 	return r
 }
 
-func (r *Runner) run() {
+func (r *Runner) run(ticker clock.Ticker) {
 	defer r.wg.Done()
 	defer close(r.events)
-
-	ticker := time.NewTicker(r.cfg.TickInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-r.closed:
 			return
-		case <-ticker.C:
+		case <-ticker.C():
 			r.tick()
 		}
 	}
@@ -82,7 +87,7 @@ func (r *Runner) tick() {
 	ctx, cancel := context.WithTimeout(context.Background(), r.cfg.TickInterval)
 	defer cancel()
 
-	now := time.Now().UnixNano()
+	now := r.cfg.Clock.Now().UnixNano()
 
 	intents, events := r.strategy.Step(ctx, now, r.mr, r.nr)
 
@@ -98,19 +103,27 @@ func (r *Runner) tick() {
 }
 
 func (r *Runner) executeIntent(ctx context.Context, intent trader.OrderIntent) {
-	var err error
+	req := order.Request{
+		TickerID: intent.TickerID,
+		UserID:   core.UserID(r.traderID),
+		Kind:     intent.Kind,
+		Side:     intent.Side,
+		Size:     intent.Size,
+		TIF:      intent.TIF,
+	}
+	if intent.Kind == core.OrderKindLimit {
+		req.Price = intent.Price
+	}
 
-	switch intent.Kind {
-	case core.OrderKindLimit:
-		_, err = r.sender.SubmitLimit(ctx, intent.TickerID, core.UserID(r.traderID), intent.Side, intent.Price, intent.Size)
-	case core.OrderKindMarket:
-		_, err = r.sender.SubmitMarket(ctx, intent.TickerID, core.UserID(r.traderID), intent.Side, intent.Size)
+	ticker, err := order.FindTicker(r.mr.GetTickers(), intent.TickerID)
+	if err == nil {
+		_, err = order.Submit(ctx, r.sender, ticker, req)
 	}
 
 	if err != nil {
 		r.emitEvent(trader.TraderEvent{
 			TraderID: r.traderID,
-			Time:     time.Now().UnixNano(),
+			Time:     r.cfg.Clock.Now().UnixNano(),
 			Type:     trader.TraderEventError,
 			Message:  err.Error(),
 		})
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/order"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/trader"
+	"github.com/zappabad/stockcraft/internal/trader/strategy"
+)
+
+// onceStrategy returns its intents and events on the first step only.
+type onceStrategy struct {
+	once    sync.Once
+	intents []trader.OrderIntent
+	events  []trader.TraderEvent
+}
+
+func (s *onceStrategy) Step(context.Context, int64, strategy.MarketReader, strategy.NewsReader) ([]trader.OrderIntent, []trader.TraderEvent) {
+	var intents []trader.OrderIntent
+	var events []trader.TraderEvent
+	s.once.Do(func() { intents, events = s.intents, s.events })
+	return intents, events
+}
+
+// stepTimes reports the time each step is given.
+type stepTimes chan int64
+
+func (s stepTimes) Step(_ context.Context, now int64, _ strategy.MarketReader, _ strategy.NewsReader) ([]trader.OrderIntent, []trader.TraderEvent) {
+	s <- now
+	return nil, nil
+}
+
+func nextEvent(t *testing.T, r *Runner) trader.TraderEvent {
+	t.Helper()
+	select {
+	case ev := <-r.Events():
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for a trader event")
+		return trader.TraderEvent{}
+	}
+}
+
+func TestRunnerTicksOnClock(t *testing.T) {
+	clk := clock.NewManual(time.Unix(1000, 0))
+	steps := make(stepTimes, 1)
+	r := NewRunner(Config{TickInterval: time.Second, Clock: clk}, 7, steps, nil, nil, nil)
+	defer r.Close()
+
+	for i := range 3 {
+		select {
+		case now := <-steps:
+			t.Fatalf("stepped at %d before the clock moved", now)
+		default:
+		}
+		clk.Advance(time.Second)
+		select {
+		case now := <-steps:
+			if want := time.Unix(1001+int64(i), 0).UnixNano(); now != want {
+				t.Errorf("step %d: expected time %d, got %d", i, want, now)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("step %d: timed out waiting for the tick", i)
+		}
+	}
+}
+
+func TestRunnerReportsValidationCodes(t *testing.T) {
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	ms := marketservice.NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	defer ms.Close()
+
+	clk := clock.NewManual(time.Unix(1000, 0))
+	strat := &onceStrategy{
+		intents: []trader.OrderIntent{
+			{TickerID: 1, Kind: core.OrderKindLimit, Side: core.SideBuy, Price: 100, Size: 0},
+			{TickerID: 9, Kind: core.OrderKindMarket, Side: core.SideBuy, Size: 1},
+			{TickerID: 1, Kind: core.OrderKindLimit, Side: core.SideBuy, Price: 100, Size: 5},
+		},
+		// Emitted after every intent has executed
+		events: []trader.TraderEvent{{TraderID: 7, Type: trader.TraderEventPlacedOrder, Message: "stepped"}},
+	}
+	r := NewRunner(Config{TickInterval: time.Second, EventBuffer: 16, Clock: clk}, 7, strat, ms, nil, ms)
+	defer r.Close()
+	clk.Advance(time.Second)
+
+	for _, code := range []order.Code{order.CodeInvalidSize, order.CodeUnknownTicker} {
+		ev := nextEvent(t, r)
+		if ev.Type != trader.TraderEventError || !strings.HasPrefix(ev.Message, string(code)+": ") {
+			t.Errorf("expected a %s error event, got %+v", code, ev)
+		}
+		if ev.Time != clk.Now().UnixNano() {
+			t.Errorf("expected the error stamped %d by the clock, got %d", clk.Now().UnixNano(), ev.Time)
+		}
+	}
+	if ev := nextEvent(t, r); ev.Message != "stepped" {
+		t.Fatalf("expected the strategy's event, got %+v", ev)
+	}
+
+	// The valid intent still reaches the book
+	if n, _ := ms.GetOrderCount(1, core.SideBuy); n != 1 {
+		t.Errorf("expected the valid intent to rest, got %d orders", n)
+	}
+}
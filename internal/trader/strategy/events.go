@@ -0,0 +1,32 @@
+package strategy
+
+import (
+	"math/rand/v2"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/trader"
+)
+
+// newRand returns the random source of a seeded strategy.
+func newRand(seed uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, seed))
+}
+
+// pickTicker returns a random ticker, or false if there are none.
+func pickTicker(rng *rand.Rand, mr MarketReader) (market.TickerID, bool) {
+	tickers := mr.GetTickers()
+	if len(tickers) == 0 {
+		return 0, false
+	}
+	return tickers[rng.IntN(len(tickers))].TickerID(), true
+}
+
+// placed is the event a strategy publishes for an order it places.
+func placed(id trader.TraderID, now int64, intent trader.OrderIntent) trader.TraderEvent {
+	return trader.TraderEvent{
+		TraderID: id,
+		Time:     now,
+		Type:     trader.TraderEventPlacedOrder,
+		Intent:   &intent,
+	}
+}
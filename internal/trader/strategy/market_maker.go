@@ -0,0 +1,54 @@
+package strategy
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/trader"
+)
+
+// MarketMaker quotes both sides one tick inside the spread on a random
+// ticker, when the spread is wide enough to leave a gap between the quotes.
+type MarketMaker struct {
+	traderID trader.TraderID
+	rng      *rand.Rand
+	// MaxSize bounds the size of each quote (at least 1).
+	MaxSize core.Size
+}
+
+// NewMarketMaker creates a MarketMaker whose choices follow seed.
+func NewMarketMaker(traderID trader.TraderID, seed uint64) *MarketMaker {
+	return &MarketMaker{traderID: traderID, rng: newRand(seed), MaxSize: 5}
+}
+
+// Step implements Strategy.
+func (s *MarketMaker) Step(ctx context.Context, now int64, mr MarketReader, nr NewsReader) ([]trader.OrderIntent, []trader.TraderEvent) {
+	tid, ok := pickTicker(s.rng, mr)
+	if !ok {
+		return nil, nil
+	}
+	bids, err := mr.GetLevels(tid, core.SideBuy)
+	if err != nil || len(bids) == 0 {
+		return nil, nil
+	}
+	asks, err := mr.GetLevels(tid, core.SideSell)
+	if err != nil || len(asks) == 0 {
+		return nil, nil
+	}
+	bid, ask := bids[0].Price+1, asks[0].Price-1
+	if bid >= ask {
+		return nil, nil
+	}
+
+	size := 1 + core.Size(s.rng.Int64N(int64(max(s.MaxSize, 1))))
+	intents := []trader.OrderIntent{
+		{TickerID: tid, Kind: core.OrderKindLimit, Side: core.SideBuy, Price: bid, Size: size},
+		{TickerID: tid, Kind: core.OrderKindLimit, Side: core.SideSell, Price: ask, Size: size},
+	}
+	events := make([]trader.TraderEvent, len(intents))
+	for i, in := range intents {
+		events[i] = placed(s.traderID, now, in)
+	}
+	return intents, events
+}
@@ -0,0 +1,63 @@
+package strategy
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/trader"
+)
+
+// MeanReversion bets on a random ticker's last trade returning to the
+// average of its recent trades: it sells at market when the last trade is
+// more than Band ticks above the average and buys when it is as far below.
+type MeanReversion struct {
+	traderID trader.TraderID
+	rng      *rand.Rand
+	// Lookback is how many trades the average covers (at least 2).
+	Lookback int
+	// Band is how far from the average, in ticks, the last trade must be.
+	Band core.PriceTicks
+	// MaxSize bounds the size of each order (at least 1).
+	MaxSize core.Size
+}
+
+// NewMeanReversion creates a MeanReversion trader whose choices follow seed.
+func NewMeanReversion(traderID trader.TraderID, seed uint64) *MeanReversion {
+	return &MeanReversion{traderID: traderID, rng: newRand(seed), Lookback: 20, Band: 2, MaxSize: 3}
+}
+
+// Step implements Strategy.
+func (s *MeanReversion) Step(ctx context.Context, now int64, mr MarketReader, nr NewsReader) ([]trader.OrderIntent, []trader.TraderEvent) {
+	tid, ok := pickTicker(s.rng, mr)
+	if !ok {
+		return nil, nil
+	}
+	trades, err := mr.GetTradesLast(tid, max(s.Lookback, 2))
+	if err != nil || len(trades) < 2 {
+		return nil, nil
+	}
+
+	var sum core.PriceTicks
+	for _, tr := range trades {
+		sum += tr.Price
+	}
+	avg := sum / core.PriceTicks(len(trades))
+
+	var side core.Side
+	switch last := trades[len(trades)-1].Price; {
+	case last > avg+s.Band:
+		side = core.SideSell
+	case last < avg-s.Band:
+		side = core.SideBuy
+	default:
+		return nil, nil
+	}
+	intent := trader.OrderIntent{
+		TickerID: tid,
+		Kind:     core.OrderKindMarket,
+		Side:     side,
+		Size:     1 + core.Size(s.rng.Int64N(int64(max(s.MaxSize, 1)))),
+	}
+	return []trader.OrderIntent{intent}, []trader.TraderEvent{placed(s.traderID, now, intent)}
+}
@@ -0,0 +1,54 @@
+package strategy
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/trader"
+)
+
+// Momentum buys at market after a random ticker's recent trades rose and
+// sells after they fell.
+type Momentum struct {
+	traderID trader.TraderID
+	rng      *rand.Rand
+	// Lookback is how many trades the move is measured over (at least 2).
+	Lookback int
+	// MaxSize bounds the size of each order (at least 1).
+	MaxSize core.Size
+}
+
+// NewMomentum creates a Momentum trader whose choices follow seed.
+func NewMomentum(traderID trader.TraderID, seed uint64) *Momentum {
+	return &Momentum{traderID: traderID, rng: newRand(seed), Lookback: 10, MaxSize: 3}
+}
+
+// Step implements Strategy.
+func (s *Momentum) Step(ctx context.Context, now int64, mr MarketReader, nr NewsReader) ([]trader.OrderIntent, []trader.TraderEvent) {
+	tid, ok := pickTicker(s.rng, mr)
+	if !ok {
+		return nil, nil
+	}
+	trades, err := mr.GetTradesLast(tid, max(s.Lookback, 2))
+	if err != nil || len(trades) < 2 {
+		return nil, nil
+	}
+
+	var side core.Side
+	switch first, last := trades[0].Price, trades[len(trades)-1].Price; {
+	case last > first:
+		side = core.SideBuy
+	case last < first:
+		side = core.SideSell
+	default:
+		return nil, nil
+	}
+	intent := trader.OrderIntent{
+		TickerID: tid,
+		Kind:     core.OrderKindMarket,
+		Side:     side,
+		Size:     1 + core.Size(s.rng.Int64N(int64(max(s.MaxSize, 1)))),
+	}
+	return []trader.OrderIntent{intent}, []trader.TraderEvent{placed(s.traderID, now, intent)}
+}
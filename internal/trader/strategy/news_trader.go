@@ -0,0 +1,56 @@
+package strategy
+
+import (
+	"context"
+	"math/rand/v2"
+
+	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/trader"
+)
+
+// NewsTrader trades at market on each new headline, buying or selling at
+// random with more size for more severe news. It ignores system news, and
+// market-wide news moves it on a random ticker.
+type NewsTrader struct {
+	traderID trader.TraderID
+	rng      *rand.Rand
+	lastSeen news.NewsID
+}
+
+// NewNewsTrader creates a NewsTrader whose choices follow seed.
+func NewNewsTrader(traderID trader.TraderID, seed uint64) *NewsTrader {
+	return &NewsTrader{traderID: traderID, rng: newRand(seed)}
+}
+
+// Step implements Strategy.
+func (s *NewsTrader) Step(ctx context.Context, now int64, mr MarketReader, nr NewsReader) ([]trader.OrderIntent, []trader.TraderEvent) {
+	latest := nr.Latest(1)
+	if len(latest) == 0 || latest[0].ID == s.lastSeen {
+		return nil, nil
+	}
+	item := latest[0]
+	s.lastSeen = item.ID
+	if item.Source == news.SourceSystem {
+		return nil, nil
+	}
+
+	tid := item.Ticker
+	if tid == 0 {
+		var ok bool
+		if tid, ok = pickTicker(s.rng, mr); !ok {
+			return nil, nil
+		}
+	}
+	side := core.SideBuy
+	if s.rng.IntN(2) == 1 {
+		side = core.SideSell
+	}
+	intent := trader.OrderIntent{
+		TickerID: tid,
+		Kind:     core.OrderKindMarket,
+		Side:     side,
+		Size:     1 + core.Size(max(item.Severity, 0)),
+	}
+	return []trader.OrderIntent{intent}, []trader.TraderEvent{placed(s.traderID, now, intent)}
+}
@@ -2,6 +2,7 @@ package trader
 
 import (
 	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/order"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 )
 
@@ -15,6 +16,7 @@ type OrderIntent struct {
 	Side     core.Side
 	Price    core.PriceTicks // for limit orders only
 	Size     core.Size
+	TIF      order.TimeInForce // GTC when zero
 }
 
 // TraderEventType indicates the type of trader event.
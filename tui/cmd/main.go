@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"time"
@@ -11,12 +12,36 @@ import (
 	"github.com/zappabad/stockcraft/internal/market"
 	marketservice "github.com/zappabad/stockcraft/internal/market/service"
 	"github.com/zappabad/stockcraft/internal/news"
+	"github.com/zappabad/stockcraft/internal/news/scenario"
 	newsservice "github.com/zappabad/stockcraft/internal/news/service"
+	"github.com/zappabad/stockcraft/internal/objective"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/sim"
+	"github.com/zappabad/stockcraft/internal/stats"
 	"github.com/zappabad/stockcraft/tui"
+	"github.com/zappabad/stockcraft/tui/notify"
+	"github.com/zappabad/stockcraft/tui/panels"
 )
 
 func main() {
+	statsDir := flag.String("stats-dir", "", "record per-ticker stats CSVs to this directory")
+	statsInterval := flag.Duration("stats-interval", 5*time.Second, "stats sampling interval")
+	statsPerTicker := flag.Bool("stats-per-ticker", false, "write one stats file per ticker")
+	scenarioPath := flag.String("scenario", "", "play scripted news from this scenario file (reloaded on change)")
+	settingsPath := flag.String("settings", "", "load notification preferences from this JSON file")
+	prefsPath := flag.String("prefs", "", "load display preferences from this JSON file and save changes to it")
+	blockSize := flag.Int64("block-size", 100, "tape size at or above which a trade is a block trade")
+	tapeGradient := flag.Float64("tape-gradient", panels.DefaultSizeGradient, "size ratio to the tape's average at which trades are emphasized, or dimmed at its inverse (0 = off)")
+	neutralDoji := flag.Bool("neutral-doji", true, "draw candles that close at their open as neutral dojis rather than bullish")
+	chartBackfill := flag.Int("chart-backfill", 0, "seed each ticker's chart with this many synthetic candles around its price (0 = off)")
+	levelLinger := flag.Duration("level-linger", 0, "keep order book levels on the ladder, faded, this long after they leave the book (0 = off)")
+	sweepLevels := flag.Int("sweep-levels", 2, "price levels an order must reach before it waits for a second submit (0 = never)")
+	riskChecks := flag.Bool("risk-checks", false, "reject player orders beyond their cash or position")
+	margin := flag.Float64("margin", 0, "with -risk-checks, allow short positions worth up to this multiple of cash (0 = no shorting)")
+	newsVolatility := flag.Float64("news-volatility", sim.DefaultPriceConfig().NewsVolatility, "volatility multiplier added per point of news severity (0 = news does not move volatility)")
+	newsCooldown := flag.Duration("news-cooldown", sim.DefaultPriceConfig().ImpactCooldown, "least time between two news impacts on a ticker (0 = no cooldown)")
+	flag.Parse()
+
 	// Create game configuration
 	cfg := game.DefaultConfig()
 	cfg.Tickers = []market.Ticker{
@@ -27,14 +52,37 @@ func main() {
 		{ID: 5, Name: "TSLA", Decimals: 2},
 	}
 
+	playerUserID := core.UserID(1000) // Player's user ID
+
 	// Create market service
 	marketService := marketservice.NewMarketService(cfg.Tickers, cfg.MarketConfig)
 	defer marketService.Close()
 
+	// Limit the player to their cash and position if enabled
+	if *riskChecks {
+		marketService.SetRiskChecker(marketservice.NewPortfolioRisk(marketService, marketservice.PortfolioRiskConfig{
+			StartingCash:     cfg.StartingEquity,
+			MarginMultiplier: *margin,
+			Users:            []core.UserID{playerUserID},
+		}))
+	}
+
 	// Create news service
 	newsService := newsservice.NewNewsService(cfg.NewsConfig)
 	defer newsService.Close()
 
+	// Record stats if enabled
+	if *statsDir != "" {
+		scfg := stats.DefaultConfig()
+		scfg.Dir = *statsDir
+		scfg.Interval = *statsInterval
+		if *statsPerTicker {
+			scfg.Layout = stats.LayoutPerTicker
+		}
+		rec := stats.NewRecorder(stats.MarketSource(marketService), scfg)
+		defer rec.Close()
+	}
+
 	// Seed some initial orders to create a market
 	seedMarket(marketService, cfg.Tickers)
 
@@ -42,14 +90,86 @@ func main() {
 	seedNews(newsService)
 
 	// Start background trading simulation
-	go simulateTrading(marketService, cfg.Tickers)
+	pcfg := sim.DefaultPriceConfig()
+	pcfg.NewsVolatility = *newsVolatility
+	pcfg.ImpactCooldown = *newsCooldown
+	pcfg.Seed = uint64(time.Now().UnixNano())
+	prices := sim.NewPriceProcess(pcfg)
+	go simulateTrading(marketService, newsService, prices, cfg.Tickers)
 	go simulateNews(newsService)
 
-	// Create and run TUI
-	playerUserID := core.UserID(1000) // Player's user ID
+	// Create the TUI program first so scenario reloads can report to it
 	model := tui.NewModel(marketService, newsService, playerUserID)
-
+	model.SetBlockTradeSize(core.Size(*blockSize))
+	model.SetTapeSizeGradient(*tapeGradient)
+	model.SetNeutralDoji(*neutralDoji)
+	model.SetLevelLinger(*levelLinger)
+	model.SetChartBackfill(*chartBackfill, prices.Step)
+	model.SetSweepWarningLevels(*sweepLevels)
+	if *settingsPath != "" {
+		settings, err := notify.LoadSettings(*settingsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading settings: %v\n", err)
+			os.Exit(1)
+		}
+		model.SetNotificationSettings(settings)
+	}
+	if *prefsPath != "" {
+		prefs, err := tui.LoadPreferences(*prefsPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading preferences: %v\n", err)
+			os.Exit(1)
+		}
+		model.SetPreferences(prefs, *prefsPath)
+	}
 	p := tea.NewProgram(model, tea.WithAltScreen())
+
+	// Play scripted news if a scenario is given
+	if *scenarioPath != "" {
+		scfg := scenario.DefaultConfig()
+		scfg.Path = *scenarioPath
+		scfg.OnReload = func(diff scenario.Diff, err error) {
+			msg := tui.NotifyMsg{Category: notify.CategorySystem, Text: "✓ Scenario reloaded: " + diff.String()}
+			if err != nil {
+				msg = tui.NotifyMsg{Category: notify.CategorySystem, Severity: notify.SeverityError, Text: "❌ Scenario reload failed: " + err.Error()}
+			}
+			go p.Send(msg)
+		}
+		player, err := scenario.NewPlayer(newsService, scfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading scenario: %v\n", err)
+			os.Exit(1)
+		}
+		defer player.Close()
+
+		// Track the scenario's objectives, if any
+		sc, err := scenario.Load(*scenarioPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading scenario: %v\n", err)
+			os.Exit(1)
+		}
+		if len(sc.Objectives) > 0 {
+			ocfg := cfg.ObjectiveConfig
+			ocfg.OnResolve = func(s objective.Status) {
+				msg := tui.NotifyMsg{Category: notify.CategoryAlert, Text: "🎯 Objective met: " + s.Objective.Name}
+				if s.State == objective.StateFailed {
+					msg = tui.NotifyMsg{Category: notify.CategoryAlert, Severity: notify.SeverityWarning, Text: "🎯 Objective failed: " + s.Objective.Name}
+				}
+				go p.Send(msg)
+			}
+			// There is no broker in this build, so broker metrics show as
+			// unavailable.
+			metrics := game.PlayerMetrics(marketService, nil, playerUserID, cfg.StartingEquity)
+			ev, err := objective.NewEvaluator(metrics, sc.Objectives, ocfg)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error in scenario objectives: %v\n", err)
+				os.Exit(1)
+			}
+			defer ev.Close()
+			model.SetObjectives(ev)
+		}
+	}
+
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running TUI: %v\n", err)
 		os.Exit(1)
@@ -109,16 +229,20 @@ func seedNews(newsService *newsservice.NewsService) {
 	}
 }
 
-func simulateTrading(marketService *marketservice.MarketService, tickers []market.Ticker) {
+func simulateTrading(marketService *marketservice.MarketService, newsService *newsservice.NewsService, prices *sim.PriceProcess, tickers []market.Ticker) {
 	ctx := context.Background()
 	traderID := core.UserID(999)
 
 	for {
 		time.Sleep(500 * time.Millisecond)
 
+		// Severe news makes the quotes below wider and the sweeps larger
+		prices.ObserveLatest(newsService.Latest(10))
+
 		// Pick a random ticker
 		ticker := tickers[time.Now().UnixNano()%int64(len(tickers))]
 		tid := ticker.TickerID()
+		mult := prices.Multiplier(tid)
 
 		// Get current levels
 		bids, _ := marketService.GetLevels(tid, core.SideBuy)
@@ -134,29 +258,36 @@ func simulateTrading(marketService *marketservice.MarketService, tickers []marke
 		switch {
 		case action < 3:
 			// Place a new bid slightly below best
-			price := bids[0].Price - core.PriceTicks(time.Now().UnixNano()%30)
+			price := bids[0].Price - abs(prices.Step(tid))
 			size := core.Size(50 + time.Now().UnixNano()%100)
 			marketService.SubmitLimit(ctx, tid, traderID, core.SideBuy, price, size)
 
 		case action < 6:
 			// Place a new ask slightly above best
-			price := asks[0].Price + core.PriceTicks(time.Now().UnixNano()%30)
+			price := asks[0].Price + abs(prices.Step(tid))
 			size := core.Size(50 + time.Now().UnixNano()%100)
 			marketService.SubmitLimit(ctx, tid, traderID, core.SideSell, price, size)
 
 		case action < 8:
 			// Market buy
-			size := core.Size(10 + time.Now().UnixNano()%50)
+			size := core.Size(float64(10+time.Now().UnixNano()%50) * mult)
 			marketService.SubmitMarket(ctx, tid, traderID, core.SideBuy, size)
 
 		default:
 			// Market sell
-			size := core.Size(10 + time.Now().UnixNano()%50)
+			size := core.Size(float64(10+time.Now().UnixNano()%50) * mult)
 			marketService.SubmitMarket(ctx, tid, traderID, core.SideSell, size)
 		}
 	}
 }
 
+func abs(p core.PriceTicks) core.PriceTicks {
+	if p < 0 {
+		return -p
+	}
+	return p
+}
+
 func simulateNews(newsService *newsservice.NewsService) {
 	newsHeadlines := []string{
 		"Breaking: Major acquisition announced in tech sector",
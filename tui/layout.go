@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"slices"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+// layoutCell places a panel in a row with a relative width.
+type layoutCell struct {
+	panel  panels.Panel
+	weight int
+}
+
+// layoutRow is a horizontal strip of panels with a relative height.
+type layoutRow struct {
+	weight int
+	cells  []layoutCell
+}
+
+// layout arranges panels in weighted rows and columns. The last row and the
+// last cell of each row absorb the rounding remainder.
+type layout struct {
+	rows []layoutRow
+
+	// Last composed frame, keyed by area and every panel's version. Only
+	// used when all panels implement panels.Versioned.
+	cacheKey []uint64
+	cached   string
+}
+
+// Render sizes every panel for a width x height area and joins the result.
+// If no panel changed since the last call for the same area, the previous
+// frame is returned without re-joining.
+func (l *layout) Render(width, height int) string {
+	heights := split(height, rowWeights(l.rows))
+
+	// Size every panel first so versions reflect the area being rendered.
+	for i, row := range l.rows {
+		widths := split(width, cellWeights(row.cells))
+		for j, cell := range row.cells {
+			cell.panel.SetSize(widths[j], heights[i])
+		}
+	}
+	key, ok := l.versionKey(width, height)
+	if ok && slices.Equal(key, l.cacheKey) {
+		return l.cached
+	}
+
+	rendered := make([]string, 0, len(l.rows))
+	for _, row := range l.rows {
+		views := make([]string, 0, len(row.cells))
+		for _, cell := range row.cells {
+			views = append(views, cell.panel.View())
+		}
+		rendered = append(rendered, lipgloss.JoinHorizontal(lipgloss.Top, views...))
+	}
+	out := lipgloss.JoinVertical(lipgloss.Left, rendered...)
+	if ok {
+		l.cacheKey, l.cached = key, out
+	}
+	return out
+}
+
+// versionKey returns the area followed by each panel's version, or false if
+// a panel does not report versions.
+func (l *layout) versionKey(width, height int) ([]uint64, bool) {
+	key := []uint64{uint64(width), uint64(height)}
+	for _, row := range l.rows {
+		for _, cell := range row.cells {
+			v, ok := cell.panel.(panels.Versioned)
+			if !ok {
+				return nil, false
+			}
+			key = append(key, v.Version())
+		}
+	}
+	return key, true
+}
+
+func rowWeights(rows []layoutRow) []int {
+	w := make([]int, len(rows))
+	for i, r := range rows {
+		w[i] = r.weight
+	}
+	return w
+}
+
+func cellWeights(cells []layoutCell) []int {
+	w := make([]int, len(cells))
+	for i, c := range cells {
+		w[i] = c.weight
+	}
+	return w
+}
+
+// split divides total by weights; the last share takes the remainder.
+func split(total int, weights []int) []int {
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	out := make([]int, len(weights))
+	if sum == 0 {
+		return out
+	}
+	used := 0
+	for i, w := range weights {
+		if i == len(weights)-1 {
+			out[i] = total - used
+			break
+		}
+		out[i] = total * w / sum
+		used += out[i]
+	}
+	return out
+}
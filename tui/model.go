@@ -2,37 +2,42 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/zappabad/stockcraft/internal/displayid"
 	"github.com/zappabad/stockcraft/internal/market"
 	marketservice "github.com/zappabad/stockcraft/internal/market/service"
 	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/news"
 	newsservice "github.com/zappabad/stockcraft/internal/news/service"
 	newsview "github.com/zappabad/stockcraft/internal/news/view"
+	"github.com/zappabad/stockcraft/internal/objective"
+	"github.com/zappabad/stockcraft/internal/order"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/internal/pubsub"
+	"github.com/zappabad/stockcraft/internal/stats"
+	"github.com/zappabad/stockcraft/tui/notify"
 	"github.com/zappabad/stockcraft/tui/panels"
 	"github.com/zappabad/stockcraft/tui/styles"
 )
 
-// PanelFocus represents which panel is currently focused.
-type PanelFocus int
-
-const (
-	FocusMarket     PanelFocus = 0
-	FocusOrderbook  PanelFocus = 1
-	FocusChart      PanelFocus = 2
-	FocusNews       PanelFocus = 3
-	FocusOrderInput PanelFocus = 4
-)
-
 // Model is the main TUI application model.
 type Model struct {
 	// Services
 	marketService *marketservice.MarketService
 	newsService   *newsservice.NewsService
+	// marketEvents is the model's own subscription to the market's events,
+	// so other consumers can read theirs alongside it
+	marketEvents *pubsub.Subscription[marketview.MarketEvent]
 
 	// Tickers
 	tickers   []market.Ticker
@@ -41,23 +46,74 @@ type Model struct {
 	// User ID for placing orders
 	userID core.UserID
 
+	// Short IDs shown for the user's orders
+	displayIDs *displayid.Allocator
+
+	// Best bid/ask history for the chart's spread mode
+	bbo *stats.BBOHistory
+
 	// Panels
 	marketPanel     *panels.MarketOverviewPanel
 	orderbookPanel  *panels.OrderbookPanel
 	newsPanel       *panels.NewsPanel
 	orderInputPanel *panels.OrderInputPanel
 	chartPanel      *panels.CandlestickPanel
+	fillsPanel      *panels.FillsPanel
+	objectivesPanel *panels.ObjectivesPanel // nil without objectives
+
+	// Player objectives, if the game sets any
+	objectives *objective.Evaluator
 
-	// Focus management
-	focusedPanel PanelFocus
+	// Orders held until their ticker opens
+	queue *orderQueue
+
+	// Orders reaching this many levels wait for a second submit (0 = off),
+	// and the order waiting for it
+	sweepWarnLevels int
+	pendingSweep    *panels.OrderSubmitMsg
+
+	// A submit is waiting for its result; further submits are dropped
+	// until it arrives
+	submitting bool
+
+	// Synthetic candles seeded into each charted ticker (0 = off) and the
+	// price moves they are drawn from
+	backfill     int
+	backfillStep func(market.TickerID) core.PriceTicks
+
+	// Display preferences and the file they are saved to ("" = not saved)
+	prefs     Preferences
+	prefsPath string
+
+	// Panel registry (focus order, key bindings, message routing) and layout
+	registry *panelRegistry
+	layout   layout
 
 	// Window dimensions
 	width  int
 	height int
 
-	// Status
-	statusMsg string
-	ready     bool
+	// Notification routing, history overlay and bell/desktop delivery
+	notifier    *notify.Router
+	showHistory bool
+	platform    *notify.Platform
+
+	ready bool
+
+	// Last composed frame; re-joined only when the panels or status bar change
+	frame frameCache
+}
+
+// chartReplayTrades is how many recent trades the order book tape and the
+// chart are loaded with.
+const chartReplayTrades = 20
+
+// frameCache holds the last full-screen View output and its inputs.
+type frameCache struct {
+	panels string
+	footer string
+	width  int
+	out    string
 }
 
 // NewModel creates a new TUI model.
@@ -76,40 +132,78 @@ func NewModel(marketService *marketservice.MarketService, newsService *newsservi
 	newsPanel := panels.NewNewsPanel()
 	orderInputPanel := panels.NewOrderInputPanel(tickers)
 	chartPanel := panels.NewCandlestickPanel()
+	fillsPanel := panels.NewFillsPanel()
 
 	// Set initial ticker
 	if len(tickers) > 0 {
 		orderbookPanel.SetTicker(tickers[0])
 		chartPanel.SetTicker(tickers[0])
+		fillsPanel.SetTicker(tickers[0])
 	}
 
-	return &Model{
+	m := &Model{
 		marketService:   marketService,
 		newsService:     newsService,
+		marketEvents:    marketService.Subscribe(marketservice.EventFilter{}, pubsub.Drop, 1024),
 		tickers:         tickers,
 		tickerMap:       tickerMap,
 		userID:          userID,
+		displayIDs:      displayid.NewAllocator(displayid.DefaultConfig()),
+		bbo:             stats.NewBBOHistory(600, 250*time.Millisecond),
 		marketPanel:     marketPanel,
 		orderbookPanel:  orderbookPanel,
 		newsPanel:       newsPanel,
 		orderInputPanel: orderInputPanel,
 		chartPanel:      chartPanel,
-		focusedPanel:    FocusOrderInput,
+		fillsPanel:      fillsPanel,
+		queue:           newOrderQueue(),
+		sweepWarnLevels: defaultSweepWarnLevels,
+		registry:        &panelRegistry{},
+		notifier:        notify.NewRouter(notify.DefaultSettings(), notify.DefaultConfig()),
+		platform:        notify.NewPlatform(notify.DefaultPlatformConfig()),
 	}
+
+	// Registration order is the Tab focus order.
+	m.registry.Register(marketPanel)
+	m.registry.Register(orderbookPanel)
+	m.registry.Register(chartPanel)
+	m.registry.Register(newsPanel)
+	m.registry.Register(fillsPanel)
+	m.registry.Register(orderInputPanel)
+	m.registry.Focus(orderInputPanel)
+
+	// Layout:
+	// ┌─────────────────────────────────────────────┐
+	// │  Market Overview  │  Orderbook  │   Chart   │
+	// │                   │             │           │
+	// ├───────────┬───────┴─────┬───────┴───────────┤
+	// │   News    │  My Fills   │    Order Input    │
+	// └───────────┴─────────────┴───────────────────┘
+	m.layout = layout{rows: []layoutRow{
+		{weight: 2, cells: []layoutCell{
+			{panel: marketPanel, weight: 1},
+			{panel: orderbookPanel, weight: 1},
+			{panel: chartPanel, weight: 1},
+		}},
+		{weight: 1, cells: []layoutCell{
+			{panel: newsPanel, weight: 1},
+			{panel: fillsPanel, weight: 1},
+			{panel: orderInputPanel, weight: 2},
+		}},
+	}}
+
+	return m
 }
 
 // Init initializes the model.
 func (m *Model) Init() tea.Cmd {
-	return tea.Batch(
-		m.marketPanel.Init(),
-		m.orderbookPanel.Init(),
-		m.newsPanel.Init(),
-		m.orderInputPanel.Init(),
-		m.chartPanel.Init(),
+	cmds := m.registry.Init()
+	cmds = append(cmds,
 		m.listenMarketEvents(),
 		m.listenNewsEvents(),
 		m.tickRefresh(),
 	)
+	return tea.Batch(cmds...)
 }
 
 // Update handles messages.
@@ -118,32 +212,45 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// The notification history takes over the keyboard while open
+		if m.showHistory {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc", "ctrl+n":
+				m.showHistory = false
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
 
+		// Open the notification history
+		case "ctrl+n":
+			m.showHistory = true
+			return m, nil
+
+		// Cycle the price display: decimal, ticks, currency
+		case "ctrl+p":
+			return m, m.cyclePriceDisplay()
+
+		// Cycle the size unit: shares, lots, notional
+		case "ctrl+s":
+			return m, m.cycleSizeUnit()
+
 		// Cycle focus with tab
 		case "tab":
-			m.cycleFocus()
+			m.registry.FocusNext()
 
 		// Reverse cycle focus with shift+tab
 		case "shift+tab":
-			m.focusedPanel--
-			if m.focusedPanel < 0 {
-				m.focusedPanel = 4
-			}
+			m.registry.FocusPrev()
 
-		// Direct panel focus with F1-F5
-		case "f1":
-			m.setFocus(FocusMarket)
-		case "f2":
-			m.setFocus(FocusOrderbook)
-		case "f3":
-			m.setFocus(FocusNews)
-		case "f4":
-			m.setFocus(FocusOrderInput)
-		case "f5":
-			m.setFocus(FocusChart)
+		// Direct panel focus with each panel's F-key
+		default:
+			m.registry.FocusKey(msg.String())
 		}
 
 	case tea.WindowSizeMsg:
@@ -153,59 +260,87 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ready = true
 
 	case panels.MarketUpdateMsg:
-		m.handleMarketUpdate(msg)
+		cmds = append(cmds, m.handleMarketUpdate(msg))
+
+	case marketEventMsg:
+		cmds = append(cmds, m.handleMarketUpdate(msg.update), m.listenMarketEvents())
 
 	case panels.NewsUpdateMsg:
 		m.newsPanel.AddNews(msg.Item)
+		cmds = append(cmds, m.flashNews(msg.Item), m.listenNewsEvents())
 
 	case panels.TickerSelectedMsg:
 		m.orderbookPanel.SetTicker(msg.Ticker)
-		m.chartPanel.SetTicker(msg.Ticker)
+		m.setChartTicker(msg.Ticker)
+		m.fillsPanel.SetTicker(msg.Ticker)
 		m.updateOrderbookData()
 
 	case panels.OrderSubmitMsg:
-		cmds = append(cmds, m.submitOrder(msg))
+		if m.submitting {
+			break
+		}
+		if cmd, rejected := m.rejectInvalid(msg); rejected {
+			cmds = append(cmds, cmd)
+		} else {
+			cmds = append(cmds, m.submitChecked(msg))
+		}
+
+	case panels.QueueOrderMsg:
+		if cmd, rejected := m.rejectInvalid(msg.Order); rejected {
+			cmds = append(cmds, cmd)
+		} else {
+			cmds = append(cmds, m.queueOrder(msg.Order))
+		}
+
+	case panels.DiscardQueuedMsg:
+		cmds = append(cmds, m.discardQueued(msg.Ticker))
+
+	case panels.CancelOrderMsg:
+		cmds = append(cmds, m.cancelOrder(msg))
 
 	case orderResultMsg:
-		m.statusMsg = msg.message
+		if msg.submit {
+			m.submitting = false
+			m.orderInputPanel.SetSubmitting(false)
+		}
+		cmds = append(cmds, m.Notify(msg.category, msg.severity, msg.message))
+
+	case releasedMsg:
+		for _, r := range msg {
+			cmds = append(cmds, m.Notify(r.category, r.severity, r.message))
+		}
+
+	case NotifyMsg:
+		cmds = append(cmds, m.Notify(msg.Category, msg.Severity, msg.Text))
 
 	case tickMsg:
-		m.updateAllData()
+		cmds = append(cmds, m.updateAllData())
 		cmds = append(cmds, m.tickRefresh())
 	}
 
 	// Update focused panel
-	m.updateFocusedPanel(msg, &cmds)
-
-	return m, tea.Batch(cmds...)
-}
+	bucket := m.orderbookPanel.Bucket()
+	if cmd := m.registry.Update(msg); cmd != nil {
+		cmds = append(cmds, cmd)
+	}
 
-func (m *Model) updateFocusedPanel(msg tea.Msg, cmds *[]tea.Cmd) {
-	var cmd tea.Cmd
+	// Reload levels right away when the orderbook aggregation is toggled
+	if m.orderbookPanel.Bucket() != bucket {
+		m.updateOrderbookData()
+	}
 
-	switch m.focusedPanel {
-	case FocusMarket:
-		m.marketPanel, cmd = m.marketPanel.Update(msg)
-		// Check if selection changed
+	// Follow the market panel's selection
+	if m.registry.Focused() == panels.Panel(m.marketPanel) {
 		selected := m.marketPanel.SelectedTicker()
 		if selected.Name != "" && selected.Name != m.orderbookPanel.Ticker().Name {
 			m.orderbookPanel.SetTicker(selected)
-			m.chartPanel.SetTicker(selected)
+			m.setChartTicker(selected)
+			m.fillsPanel.SetTicker(selected)
 			m.updateOrderbookData()
 		}
-	case FocusOrderbook:
-		m.orderbookPanel, cmd = m.orderbookPanel.Update(msg)
-	case FocusNews:
-		m.newsPanel, cmd = m.newsPanel.Update(msg)
-	case FocusOrderInput:
-		m.orderInputPanel, cmd = m.orderInputPanel.Update(msg)
-	case FocusChart:
-		m.chartPanel, cmd = m.chartPanel.Update(msg)
 	}
 
-	if cmd != nil {
-		*cmds = append(*cmds, cmd)
-	}
+	return m, tea.Batch(cmds...)
 }
 
 // View renders the UI.
@@ -214,90 +349,229 @@ func (m *Model) View() string {
 		return "Initializing..."
 	}
 
-	// Update focus states
-	m.marketPanel.SetFocus(m.focusedPanel == FocusMarket)
-	m.orderbookPanel.SetFocus(m.focusedPanel == FocusOrderbook)
-	m.newsPanel.SetFocus(m.focusedPanel == FocusNews)
-	m.orderInputPanel.SetFocus(m.focusedPanel == FocusOrderInput)
-	m.chartPanel.SetFocus(m.focusedPanel == FocusChart)
+	m.registry.ApplyFocus()
 
-	// Layout:
-	// ┌─────────────────────────────────────────────┐
-	// │  Market Overview  │  Orderbook  │   Chart   │
-	// │                   │             │           │
-	// ├───────────────────┼─────────────┴───────────┤
-	// │      News         │      Order Input        │
-	// └───────────────────┴─────────────────────────┘
-
-	// Calculate column widths
-	leftWidth := m.width / 3
-	middleWidth := m.width / 3
-	rightWidth := m.width - leftWidth - middleWidth
-
-	// Calculate row heights
-	topHeight := (m.height - 3) * 2 / 3 // 2/3 for top row
-	bottomHeight := m.height - topHeight - 3
-
-	// Render top row panels
-	m.marketPanel.SetSize(leftWidth, topHeight)
-	m.orderbookPanel.SetSize(middleWidth, topHeight)
-	m.chartPanel.SetSize(rightWidth, topHeight)
-
-	topRow := lipgloss.JoinHorizontal(lipgloss.Top,
-		m.marketPanel.View(),
-		m.orderbookPanel.View(),
-		m.chartPanel.View(),
-	)
+	// Panels share the screen minus the status bar
+	var panelsView string
+	if m.showHistory {
+		panelsView = renderNotificationHistory(m.notifier.History(), m.width, m.height-3)
+	} else {
+		panelsView = m.layout.Render(m.width, m.height-3)
+	}
 
-	// Render bottom row panels
-	m.newsPanel.SetSize(leftWidth, bottomHeight)
-	m.orderInputPanel.SetSize(m.width-leftWidth, bottomHeight)
+	// Toast line and status bar text
+	toast, hasToast := m.notifier.Toast()
+	status, _ := m.notifier.Status()
+	footer := status.Display()
+	if hasToast {
+		footer += "\x00" + toast.Display()
+	}
 
-	bottomRow := lipgloss.JoinHorizontal(lipgloss.Top,
-		m.newsPanel.View(),
-		m.orderInputPanel.View(),
-	)
+	f := &m.frame
+	if f.out != "" && f.panels == panelsView && f.footer == footer && f.width == m.width {
+		return f.out
+	}
+
+	parts := []string{panelsView}
+	if hasToast {
+		parts = append(parts, renderToast(toast, m.width))
+	}
+	parts = append(parts, m.renderStatusBar(status.Display()))
+
+	*f = frameCache{
+		panels: panelsView,
+		footer: footer,
+		width:  m.width,
+		out:    lipgloss.JoinVertical(lipgloss.Left, parts...),
+	}
+	return f.out
+}
+
+// Notify routes a notification through the notification preferences. Every
+// feature reports to the player through it rather than setting status text
+// directly. The returned command rings the bell and raises a desktop
+// notification if the preferences ask for them.
+func (m *Model) Notify(cat notify.Category, sev notify.Severity, text string) tea.Cmd {
+	return m.deliver(m.notifier.Notify(cat, sev, text))
+}
+
+// flashNews toasts a headline at or above the news flash severity, ringing
+// the bell if the preferences ask for it. Other headlines only reach the
+// news panel.
+func (m *Model) flashNews(item news.NewsItem) tea.Cmd {
+	s := m.notifier.Settings().News
+	if !s.Flashes(item.Severity) {
+		return nil
+	}
+	return m.deliver(m.notifier.Flash(notify.CategoryNews, notify.SeverityWarning, "📰 BREAKING: "+item.Headline, s.Bell))
+}
+
+// deliver returns the command that sends a routed notification to the
+// platform channels, or nil if there is nothing to send.
+func (m *Model) deliver(res notify.Result) tea.Cmd {
+	send := m.platform.Deliver(res, m.notifier.Settings().Desktop)
+	if send == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		send()
+		return nil
+	}
+}
+
+// SetNotificationSettings replaces the per-category notification preferences.
+func (m *Model) SetNotificationSettings(s notify.Settings) {
+	m.notifier.SetSettings(s)
+}
+
+// SetObjectives shows the evaluator's objectives in a panel beside My Fills.
+// Call it before the program starts.
+func (m *Model) SetObjectives(ev *objective.Evaluator) {
+	m.objectives = ev
+	m.objectivesPanel = panels.NewObjectivesPanel()
+	m.objectivesPanel.SetStatuses(ev.Statuses())
+	m.registry.Register(m.objectivesPanel)
+
+	bottom := &m.layout.rows[len(m.layout.rows)-1]
+	last := len(bottom.cells) - 1
+	bottom.cells = slices.Insert(bottom.cells, last, layoutCell{panel: m.objectivesPanel, weight: 1})
+}
+
+// SetBlockTradeSize sets the size at or above which the tape treats a trade
+// as a block trade.
+func (m *Model) SetBlockTradeSize(size core.Size) {
+	m.orderbookPanel.SetBlockSize(size)
+}
+
+// SetTapeSizeGradient sets the size ratio to the tape's average at which
+// trades are shown loud, or quiet at its inverse (0 = off).
+func (m *Model) SetTapeSizeGradient(ratio float64) {
+	m.orderbookPanel.SetSizeGradient(ratio)
+}
+
+// SetNeutralDoji sets whether the chart draws candles that close at their
+// open as neutral dojis or as bullish candles.
+func (m *Model) SetNeutralDoji(neutral bool) {
+	m.chartPanel.SetNeutralDoji(neutral)
+}
+
+// SetChartBackfill seeds the chart of each ticker, when it is selected, with
+// n synthetic candles around the ticker's reference price: its oldest
+// recent trade, or the mid if it has not traded. step draws each candle's
+// price move for a ticker. n = 0 leaves charts blank until trades arrive.
+func (m *Model) SetChartBackfill(n int, step func(market.TickerID) core.PriceTicks) {
+	m.backfill = n
+	m.backfillStep = step
+	m.backfillChart()
+}
+
+// setChartTicker charts a ticker from the market's candle history,
+// backfilling it if that is on and the ticker has not traded.
+func (m *Model) setChartTicker(t market.Ticker) {
+	m.chartPanel.SetTicker(t)
+	m.loadChartCandles()
+	m.backfillChart()
+}
+
+// loadChartCandles loads the charted ticker's candles from the market.
+func (m *Model) loadChartCandles() {
+	t := m.chartPanel.Ticker()
+	if t.Name == "" {
+		return
+	}
+	tid := t.TickerID()
+	candles, err := m.marketService.GetCandles(tid, m.chartPanel.Period(), m.chartPanel.MaxCandles())
+	if err != nil {
+		return // the market does not keep this interval; the chart builds from trades
+	}
+	seen, _ := m.marketService.GetTradesLast(tid, chartReplayTrades)
+	out := make([]panels.Candle, len(candles))
+	for i, c := range candles {
+		out[i] = panels.Candle{Open: c.Open, High: c.High, Low: c.Low, Close: c.Close, Volume: c.Volume, Time: c.Time}
+	}
+	m.chartPanel.LoadCandles(out, seen)
+}
 
-	// Status bar
-	statusBar := m.renderStatusBar()
+// backfillChart seeds the charted ticker's synthetic history, ending where
+// the trades the chart replays from the tape begin.
+func (m *Model) backfillChart() {
+	t := m.chartPanel.Ticker()
+	if m.backfill <= 0 || m.backfillStep == nil || t.Name == "" {
+		return
+	}
+	tid := t.TickerID()
+	end := time.Now().UnixNano()
+	var ref core.PriceTicks
+	if trades, _ := m.marketService.GetTradesLast(tid, chartReplayTrades); len(trades) > 0 {
+		ref, end = trades[0].Price, min(end, trades[0].Time)
+	} else if bp := m.marketService.Snapshot().ByTicker[tid]; bp.BidOK && bp.AskOK {
+		ref = (bp.BidPrice + bp.AskPrice) / 2
+	}
+	m.chartPanel.Backfill(ref, end, m.backfill, func() core.PriceTicks { return m.backfillStep(tid) })
+}
 
-	// Join all rows
-	return lipgloss.JoinVertical(lipgloss.Left, topRow, bottomRow, statusBar)
+// SetLevelLinger sets how long order book levels that leave the book stay
+// on the ladder, faded (0 = not at all).
+func (m *Model) SetLevelLinger(d time.Duration) {
+	m.orderbookPanel.SetLevelLinger(d)
 }
 
-func (m *Model) renderStatusBar() string {
+func (m *Model) renderStatusBar(statusMsg string) string {
 	// Help text
 	help := []string{
-		styles.StatusBarKeyStyle.Render("F1-F5") + styles.StatusBarDescStyle.Render(" panels"),
+		styles.StatusBarKeyStyle.Render(fmt.Sprintf("F1-F%d", m.registry.Len())) + styles.StatusBarDescStyle.Render(" panels"),
 		styles.StatusBarKeyStyle.Render("Tab/Enter") + styles.StatusBarDescStyle.Render(" navigate"),
 		styles.StatusBarKeyStyle.Render("↑↓") + styles.StatusBarDescStyle.Render(" select"),
+		styles.StatusBarKeyStyle.Render("^N") + styles.StatusBarDescStyle.Render(" alerts"),
 		styles.StatusBarKeyStyle.Render("q") + styles.StatusBarDescStyle.Render(" quit"),
 	}
 
-	helpStr := lipgloss.JoinHorizontal(lipgloss.Center, help[0], " │ ", help[1], " │ ", help[2], " │ ", help[3])
+	helpStr := strings.Join(help, " │ ")
 
 	// Status message
 	status := ""
-	if m.statusMsg != "" {
-		status = " │ " + m.statusMsg
+	if statusMsg != "" {
+		status = " │ " + statusMsg
 	}
 
 	return styles.StatusBarStyle.Width(m.width).Render(helpStr + status)
 }
 
-func (m *Model) setFocus(panel PanelFocus) {
-	m.focusedPanel = panel
+func (m *Model) updatePanelSizes() {
+	// Will be updated in View()
 }
 
-func (m *Model) cycleFocus() {
-	m.focusedPanel = (m.focusedPanel + 1) % 5
+// describeImbalance is the alert text for an imbalance event, e.g.
+// "⚖ AAPL book imbalance: bids 80% heavier (900 × 100)".
+func (m *Model) describeImbalance(tid market.TickerID, ev marketview.ImbalanceEvent) string {
+	side := "bids"
+	if ev.Side == core.SideSell {
+		side = "offers"
+	}
+	return fmt.Sprintf("⚖ %s book imbalance: %s %.0f%% heavier (%d × %d)",
+		m.tickerMap[tid].Name, side, math.Abs(ev.Imbalance)*100, ev.BidSize, ev.AskSize)
 }
 
-func (m *Model) updatePanelSizes() {
-	// Will be updated in View()
-}
+func (m *Model) handleMarketUpdate(msg panels.MarketUpdateMsg) tea.Cmd {
+	if msg.Status != nil {
+		return m.setTradingState(msg.Ticker, *msg.Status)
+	}
+	if msg.Imbalance != nil {
+		return m.Notify(notify.CategoryAlert, notify.SeverityWarning, m.describeImbalance(msg.Ticker, *msg.Imbalance))
+	}
+
+	// Track the lifetime of the user's orders for display IDs
+	switch e := msg.Event.(type) {
+	case core.OrderRestedEvent:
+		if e.UserID == m.userID {
+			m.displayIDs.Assign(e.OrderID, msg.Ticker)
+		}
+	case core.OrderRemovedEvent:
+		if e.UserID == m.userID {
+			m.displayIDs.MarkClosed(e.OrderID)
+		}
+	}
 
-func (m *Model) handleMarketUpdate(msg panels.MarketUpdateMsg) {
 	// Update market overview
 	snap := m.marketService.Snapshot()
 	m.marketPanel.SetSnapshot(snap)
@@ -306,9 +580,8 @@ func (m *Model) handleMarketUpdate(msg panels.MarketUpdateMsg) {
 	if ticker, ok := m.tickerMap[msg.Ticker]; ok {
 		if ticker.Name == m.orderbookPanel.Ticker().Name {
 			// Update orderbook
-			bids, _ := m.marketService.GetLevels(msg.Ticker, core.SideBuy)
-			asks, _ := m.marketService.GetLevels(msg.Ticker, core.SideSell)
-			m.orderbookPanel.SetLevels(bids, asks)
+			m.orderbookPanel.SetLevels(m.bookLevels(msg.Ticker))
+			m.orderbookPanel.SetMyOrders(m.myOrderPrices(msg.Ticker))
 
 			// Handle trade events for chart
 			if trade, ok := msg.Event.(core.TradeEvent); ok {
@@ -317,12 +590,15 @@ func (m *Model) handleMarketUpdate(msg panels.MarketUpdateMsg) {
 			}
 		}
 	}
+	return nil
 }
 
-func (m *Model) updateAllData() {
+func (m *Model) updateAllData() tea.Cmd {
 	// Update market snapshot
 	snap := m.marketService.Snapshot()
 	m.marketPanel.SetSnapshot(snap)
+	m.marketPanel.SetNow(time.Now().UnixNano())
+	m.recordBBO(snap)
 
 	// Update orderbook
 	m.updateOrderbookData()
@@ -330,6 +606,65 @@ func (m *Model) updateAllData() {
 	// Update news
 	news := m.newsService.Latest(20)
 	m.newsPanel.SetNews(news)
+
+	// Update objectives
+	if m.objectives != nil {
+		m.objectivesPanel.SetStatuses(m.objectives.Statuses())
+	}
+
+	// Update trading states, releasing queued orders on the open. Polled
+	// as well as evented since market events may be dropped.
+	var cmds []tea.Cmd
+	for _, t := range m.tickers {
+		if st, err := m.marketService.GetTradingState(t.TickerID()); err == nil {
+			cmds = append(cmds, m.setTradingState(t.TickerID(), st))
+		}
+		if pos, err := m.marketService.UserPosition(t.TickerID(), m.userID); err == nil {
+			m.orderInputPanel.SetPosition(t.TickerID(), pos)
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// recordBBO samples every ticker's best bid/ask into the spread history and
+// hands the selected ticker's history to the chart.
+func (m *Model) recordBBO(snap marketview.MarketSnapshot) {
+	now := time.Now().UnixNano()
+	for tid, bp := range snap.ByTicker {
+		m.bbo.Record(tid, stats.BBOSample{
+			Time:     now,
+			BidPrice: bp.BidPrice,
+			BidOK:    bp.BidOK,
+			AskPrice: bp.AskPrice,
+			AskOK:    bp.AskOK,
+		})
+	}
+	if ticker := m.chartPanel.Ticker(); ticker.Name != "" {
+		m.chartPanel.SetSpreadHistory(m.bbo.Last(ticker.TickerID(), 600))
+	}
+}
+
+// myOrderPrices returns the prices of the player's resting orders on a
+// ticker, for the order book markers.
+func (m *Model) myOrderPrices(tid market.TickerID) (bids, asks []core.PriceTicks) {
+	orders, _ := m.marketService.GetUserOrders(tid, m.userID)
+	for _, o := range orders {
+		if o.Side == core.SideBuy {
+			bids = append(bids, o.Price)
+		} else {
+			asks = append(asks, o.Price)
+		}
+	}
+	return bids, asks
+}
+
+// bookLevels returns a ticker's bids and asks at the orderbook panel's
+// current aggregation.
+func (m *Model) bookLevels(tid market.TickerID) (bids, asks []orderbookview.Level) {
+	bucket := m.orderbookPanel.Bucket()
+	bids, _ = m.marketService.GetLevelsBucketed(tid, core.SideBuy, bucket)
+	asks, _ = m.marketService.GetLevelsBucketed(tid, core.SideSell, bucket)
+	return bids, asks
 }
 
 func (m *Model) updateOrderbookData() {
@@ -339,64 +674,204 @@ func (m *Model) updateOrderbookData() {
 	}
 
 	tid := ticker.TickerID()
-	bids, _ := m.marketService.GetLevels(tid, core.SideBuy)
-	asks, _ := m.marketService.GetLevels(tid, core.SideSell)
-	m.orderbookPanel.SetLevels(bids, asks)
+	m.orderbookPanel.SetNow(time.Now().UnixNano())
+	m.orderbookPanel.SetLevels(m.bookLevels(tid))
+	m.orderbookPanel.SetMyOrders(m.myOrderPrices(tid))
 
-	trades, _ := m.marketService.GetTradesLast(tid, 20)
+	trades, _ := m.marketService.GetTradesLast(tid, chartReplayTrades)
 	m.orderbookPanel.SetTrades(trades)
 
 	// Also populate chart with historical trades
 	for _, trade := range trades {
 		m.chartPanel.AddTrade(trade)
 	}
+
+	fills, _ := m.marketService.UserFills(tid, m.userID, 50)
+	m.fillsPanel.SetFills(fills)
+}
+
+// averageFillPrice is the size-weighted average price of fills, rounded
+// toward zero. If the notional overflows an int64 it is averaged in
+// floating point instead.
+func averageFillPrice(fills []core.Fill) int64 {
+	var notional, filled int64
+	exact := true
+	for _, f := range fills {
+		filled += int64(f.Size)
+		n, ok := money.MulChecked(int64(f.Price), int64(f.Size))
+		if ok {
+			notional, ok = money.AddChecked(notional, n)
+		}
+		if !ok {
+			exact = false
+		}
+	}
+	if filled == 0 {
+		return 0
+	}
+	if exact {
+		return notional / filled
+	}
+	var avg float64
+	for _, f := range fills {
+		avg += float64(f.Price) * (float64(f.Size) / float64(filled))
+	}
+	return int64(avg)
+}
+
+// orderRequest is the player's request for an order from the entry panel.
+func (m *Model) orderRequest(sub panels.OrderSubmitMsg) order.Request {
+	req := order.Request{
+		TickerID: sub.Ticker.TickerID(),
+		UserID:   m.userID,
+		Kind:     sub.OrderKind,
+		Side:     sub.Side,
+		Size:     sub.Quantity,
+	}
+	if sub.OrderKind == core.OrderKindLimit {
+		req.Price = sub.Price
+	}
+	return req
+}
+
+// rejectInvalid notifies and returns true if an order fails validation, so
+// it is neither estimated, queued nor sent.
+func (m *Model) rejectInvalid(sub panels.OrderSubmitMsg) (tea.Cmd, bool) {
+	if err := m.orderRequest(sub).Validate(sub.Ticker); err != nil {
+		return m.Notify(notify.CategoryRejection, notify.SeverityError, "❌ Order rejected: "+err.Error()), true
+	}
+	return nil, false
 }
 
-func (m *Model) submitOrder(order panels.OrderSubmitMsg) tea.Cmd {
+// sendOrder submits an order from the order entry panel, disabling its
+// submit button until the result arrives.
+func (m *Model) sendOrder(sub panels.OrderSubmitMsg) tea.Cmd {
+	m.submitting = true
+	m.orderInputPanel.SetSubmitting(true)
+	submit := m.submitOrder(sub)
 	return func() tea.Msg {
-		ctx := context.Background()
-		tid := order.Ticker.TickerID()
+		res := submit().(orderResultMsg)
+		res.submit = true
+		return res
+	}
+}
 
-		var err error
-		var report core.SubmitReport
+func (m *Model) submitOrder(sub panels.OrderSubmitMsg) tea.Cmd {
+	prices := m.prefs.PriceDisplay
+	return func() tea.Msg {
+		ctx := context.Background()
+		tid := sub.Ticker.TickerID()
 
-		if order.OrderKind == core.OrderKindLimit {
-			report, err = m.marketService.SubmitLimit(ctx, tid, m.userID, order.Side, order.Price, order.Quantity)
-		} else {
-			report, err = m.marketService.SubmitMarket(ctx, tid, m.userID, order.Side, order.Quantity)
+		report, err := order.Submit(ctx, m.marketService, sub.Ticker, m.orderRequest(sub))
+		if order.CodeOf(err) != "" || errors.Is(err, marketservice.ErrInsufficientFunds) || errors.Is(err, marketservice.ErrPositionLimit) {
+			return orderResultMsg{category: notify.CategoryRejection, severity: notify.SeverityError, message: "❌ Order rejected: " + err.Error()}
 		}
-
 		if err != nil {
-			return orderResultMsg{message: "❌ Order failed: " + err.Error()}
+			return orderResultMsg{category: notify.CategoryRejection, severity: notify.SeverityError, message: "❌ Order failed: " + err.Error()}
 		}
 
 		// Calculate filled amount from fills
 		var filled core.Size
-		var totalValue int64
 		for _, fill := range report.Fills {
 			filled += fill.Size
-			totalValue += int64(fill.Price) * int64(fill.Size)
+		}
+
+		display := m.displayIDs.Assign(report.OrderID, tid)
+		if !report.Rested {
+			m.displayIDs.MarkClosed(report.OrderID)
+		}
+
+		// Self-trade prevention: own resting orders canceled, or this one
+		stp := ""
+		if len(report.STPCanceled) > 0 {
+			names := make([]string, len(report.STPCanceled))
+			for i, id := range report.STPCanceled {
+				names[i] = m.displayIDs.Display(id)
+				m.displayIDs.MarkClosed(id)
+			}
+			stp = " · self-trade prevention canceled " + strings.Join(names, ", ")
+		}
+		if report.STPTakerCanceled && filled == 0 {
+			return orderResultMsg{category: notify.CategoryRejection, severity: notify.SeverityWarning, message: "⚠ Order canceled by self-trade prevention: it would have traded with your own order"}
+		}
+
+		if report.FullyUnfilled {
+			return orderResultMsg{category: notify.CategoryRejection, severity: notify.SeverityWarning, message: fmt.Sprintf("⚠ No liquidity: nothing filled, %d not placed", report.Remaining) + stp}
 		}
 
 		if filled > 0 {
-			avgPrice := totalValue / int64(filled)
-			return orderResultMsg{message: fmt.Sprintf("✓ Filled %d @ %d", filled, avgPrice)}
+			msg := fmt.Sprintf("✓ Filled %d @ %s", filled, prices.FormatPrice(averageFillPrice(report.Fills), sub.Ticker.Decimals))
+			switch {
+			case report.RestedSize > 0:
+				msg += fmt.Sprintf(", %d resting as %s", report.RestedSize, display)
+			case report.STPTakerCanceled:
+				msg += fmt.Sprintf(", %d canceled by self-trade prevention", report.Remaining)
+			case report.Remaining > 0:
+				msg += fmt.Sprintf(", %d unfilled", report.Remaining)
+			}
+			return orderResultMsg{category: notify.CategoryFill, message: msg + stp}
 		}
-		return orderResultMsg{message: fmt.Sprintf("✓ Order placed (ID: %d)", report.OrderID)}
+		return orderResultMsg{category: notify.CategoryOrder, message: fmt.Sprintf("✓ Order placed (ID: %s)", display) + stp}
 	}
 }
 
+func (m *Model) cancelOrder(msg panels.CancelOrderMsg) tea.Cmd {
+	return func() tea.Msg {
+		ctx := context.Background()
+
+		// Known IDs carry their ticker; raw IDs without a mapping are tried
+		// against every book.
+		tickers := []market.TickerID{}
+		var orderID core.OrderID
+		if e, err := m.displayIDs.Lookup(msg.ID); err == nil {
+			orderID = e.OrderID
+			tickers = append(tickers, e.Ticker)
+		} else {
+			id, err := m.displayIDs.ResolveDisplayID(msg.ID)
+			if err != nil {
+				return orderResultMsg{category: notify.CategoryRejection, severity: notify.SeverityError, message: "❌ Cancel failed: " + err.Error()}
+			}
+			orderID = id
+			for _, t := range m.tickers {
+				tickers = append(tickers, t.TickerID())
+			}
+		}
+
+		err := core.ErrNotFound
+		var report core.CancelReport
+		for _, tid := range tickers {
+			report, err = m.marketService.Cancel(ctx, tid, orderID)
+			if err != core.ErrNotFound {
+				break
+			}
+		}
+		if err != nil {
+			return orderResultMsg{category: notify.CategoryRejection, severity: notify.SeverityError, message: "❌ Cancel failed: " + err.Error()}
+		}
+
+		m.displayIDs.MarkClosed(orderID)
+		return orderResultMsg{category: notify.CategoryOrder, message: fmt.Sprintf("✓ Canceled %s (%d)", m.displayIDs.Display(orderID), report.CanceledSize)}
+	}
+}
+
+// marketEventMsg is a market event read by listenMarketEvents, which is
+// re-armed after each one.
+type marketEventMsg struct {
+	update panels.MarketUpdateMsg
+}
+
 func (m *Model) listenMarketEvents() tea.Cmd {
 	return func() tea.Msg {
-		events := m.marketService.Events()
-		ev, ok := <-events
+		ev, ok := <-m.marketEvents.C()
 		if !ok {
 			return nil
 		}
-		return panels.MarketUpdateMsg{
-			Ticker: ev.Ticker,
-			Event:  ev.Event,
-		}
+		return marketEventMsg{update: panels.MarketUpdateMsg{
+			Ticker:    ev.Ticker,
+			Event:     ev.Event,
+			Status:    ev.Status,
+			Imbalance: ev.Imbalance,
+		}}
 	}
 }
 
@@ -422,7 +897,19 @@ func (m *Model) tickRefresh() tea.Cmd {
 
 // orderResultMsg is sent after an order is processed.
 type orderResultMsg struct {
-	message string
+	category notify.Category
+	severity notify.Severity
+	message  string
+	submit   bool // the result of a sendOrder
+}
+
+// NotifyMsg routes a notification through Model.Notify. Background
+// components outside the model (such as the scenario player) send it through
+// tea.Program.Send.
+type NotifyMsg struct {
+	Category notify.Category
+	Severity notify.Severity
+	Text     string
 }
 
 // Msg types from services for re-export
@@ -0,0 +1,488 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/clock"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/news"
+	newsservice "github.com/zappabad/stockcraft/internal/news/service"
+	"github.com/zappabad/stockcraft/internal/objective"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/tui/notify"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func newTestModel(t testing.TB) *Model {
+	t.Helper()
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+	}
+	// Synchronous, so the books reflect a command as soon as it returns
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	ms := marketservice.NewMarketService(tickers, cfg)
+	t.Cleanup(ms.Close)
+	ns := newsservice.NewNewsService(newsservice.DefaultConfig())
+	t.Cleanup(ns.Close)
+	return NewModel(ms, ns, 1000)
+}
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run with -update to create)", err)
+	}
+	if got != string(want) {
+		t.Errorf("render mismatch for %s\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestModelGoldenRender(t *testing.T) {
+	m := newTestModel(t)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	assertGolden(t, "model_initial", m.View())
+}
+
+func TestModelGoldenRenderAfterFocusChange(t *testing.T) {
+	m := newTestModel(t)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	assertGolden(t, "model_focus_orderbook", m.View())
+
+	m.Update(tea.KeyMsg{Type: tea.KeyF5})
+	assertGolden(t, "model_focus_chart", m.View())
+
+	m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	assertGolden(t, "model_focus_orderbook", m.View())
+}
+
+func TestCancelByDisplayID(t *testing.T) {
+	m := newTestModel(t)
+	aapl := m.tickers[0]
+
+	placed := m.submitOrder(panels.OrderSubmitMsg{
+		Ticker:    aapl,
+		Side:      core.SideBuy,
+		OrderKind: core.OrderKindLimit,
+		Price:     100,
+		Quantity:  10,
+	})().(orderResultMsg)
+	if placed.message != "✓ Order placed (ID: A-1)" {
+		t.Fatalf("unexpected status: %q", placed.message)
+	}
+
+	canceled := m.cancelOrder(panels.CancelOrderMsg{ID: "a-1"})().(orderResultMsg)
+	if canceled.message != "✓ Canceled A-1 (10)" {
+		t.Fatalf("unexpected status: %q", canceled.message)
+	}
+	orders, _ := m.marketService.GetOrders(aapl.TickerID(), core.SideBuy)
+	if len(orders) != 0 {
+		t.Errorf("expected the order to be canceled, got %d resting", len(orders))
+	}
+
+	again := m.cancelOrder(panels.CancelOrderMsg{ID: "A-1"})().(orderResultMsg)
+	if !strings.HasPrefix(again.message, "❌ Cancel failed") {
+		t.Errorf("expected second cancel to fail, got %q", again.message)
+	}
+	unknown := m.cancelOrder(panels.CancelOrderMsg{ID: "A-99"})().(orderResultMsg)
+	if !strings.Contains(unknown.message, "unknown or expired") {
+		t.Errorf("expected unknown ID to fail gracefully, got %q", unknown.message)
+	}
+}
+
+func TestSubmitStatusReportsRestingRemainder(t *testing.T) {
+	m := newTestModel(t)
+	aapl := m.tickers[0]
+	ctx := context.Background()
+	if _, err := m.marketService.SubmitLimit(ctx, aapl.TickerID(), 1, core.SideSell, 100, 60); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := m.submitOrder(panels.OrderSubmitMsg{
+		Ticker:    aapl,
+		Side:      core.SideBuy,
+		OrderKind: core.OrderKindLimit,
+		Price:     100,
+		Quantity:  100,
+	})().(orderResultMsg)
+	if got.message != "✓ Filled 60 @ 1.00, 40 resting as A-1" {
+		t.Errorf("unexpected status: %q", got.message)
+	}
+
+	got = m.submitOrder(panels.OrderSubmitMsg{
+		Ticker:    aapl,
+		Side:      core.SideSell,
+		OrderKind: core.OrderKindMarket,
+		Quantity:  50,
+	})().(orderResultMsg)
+	if got.message != "✓ Filled 40 @ 1.00, 10 unfilled" {
+		t.Errorf("unexpected status: %q", got.message)
+	}
+}
+
+func TestSubmitStatusFollowsPriceDisplay(t *testing.T) {
+	m := newTestModel(t)
+	aapl := m.tickers[0]
+	ctx := context.Background()
+	for _, tc := range []struct {
+		display money.Display
+		want    string
+	}{
+		{money.DisplayDecimal, "✓ Filled 10 @ 1.00, 10 unfilled"},
+		{money.DisplayTicks, "✓ Filled 10 @ 100, 10 unfilled"},
+		{money.DisplayCurrency, "✓ Filled 10 @ $1.00, 10 unfilled"},
+	} {
+		if _, err := m.marketService.SubmitLimit(ctx, aapl.TickerID(), 1, core.SideSell, 100, 10); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		m.SetPriceDisplay(tc.display)
+		got := m.submitOrder(panels.OrderSubmitMsg{
+			Ticker:    aapl,
+			Side:      core.SideBuy,
+			OrderKind: core.OrderKindMarket,
+			Quantity:  20,
+		})().(orderResultMsg)
+		if got.message != tc.want {
+			t.Errorf("%v: expected %q, got %q", tc.display, tc.want, got.message)
+		}
+	}
+}
+
+func TestSubmitStatusReportsSelfTradePrevention(t *testing.T) {
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	cfg.Book.SelfTradePrevention = core.STPCancelResting
+	ms := marketservice.NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	t.Cleanup(ms.Close)
+	ns := newsservice.NewNewsService(newsservice.DefaultConfig())
+	t.Cleanup(ns.Close)
+	m := NewModel(ms, ns, 1000)
+	aapl := m.tickers[0]
+
+	ask := panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideSell, OrderKind: core.OrderKindLimit, Price: 100, Quantity: 5}
+	if got := m.submitOrder(ask)().(orderResultMsg); got.message != "✓ Order placed (ID: A-1)" {
+		t.Fatalf("unexpected status: %q", got.message)
+	}
+	bid := panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideBuy, OrderKind: core.OrderKindLimit, Price: 100, Quantity: 5}
+	if got := m.submitOrder(bid)().(orderResultMsg); got.message != "✓ Order placed (ID: A-2) · self-trade prevention canceled A-1" {
+		t.Errorf("unexpected status: %q", got.message)
+	}
+}
+
+func TestSubmitRejectionCarriesValidationCode(t *testing.T) {
+	m := newTestModel(t)
+	got := m.submitOrder(panels.OrderSubmitMsg{
+		Ticker:    m.tickers[0],
+		Side:      core.SideBuy,
+		OrderKind: core.OrderKindLimit,
+		Price:     100,
+		Quantity:  0,
+	})().(orderResultMsg)
+	if got.category != notify.CategoryRejection || got.message != "❌ Order rejected: INVALID_SIZE: size must be positive, got 0" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestSubmitRejectionCarriesRiskReason(t *testing.T) {
+	m := newTestModel(t)
+	m.marketService.SetRiskChecker(marketservice.NewPortfolioRisk(m.marketService, marketservice.PortfolioRiskConfig{StartingCash: 10}))
+	got := m.submitOrder(panels.OrderSubmitMsg{
+		Ticker:    m.tickers[0],
+		Side:      core.SideSell,
+		OrderKind: core.OrderKindLimit,
+		Price:     100,
+		Quantity:  5,
+	})().(orderResultMsg)
+	if got.category != notify.CategoryRejection || got.message != "❌ Order rejected: position limit: selling 5 with 0 already offered, position is 0" {
+		t.Errorf("unexpected result: %+v", got)
+	}
+}
+
+func TestSubmitDisabledUntilResult(t *testing.T) {
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	ms := marketservice.NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	t.Cleanup(ms.Close)
+	ns := newsservice.NewNewsService(newsservice.DefaultConfig())
+	t.Cleanup(ns.Close)
+	m := NewModel(ms, ns, 1000)
+	m.orderInputPanel.SetSize(60, 30)
+	aapl := m.tickers[0]
+	bid := panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideBuy, OrderKind: core.OrderKindLimit, Price: 100, Quantity: 5}
+
+	_, first := m.Update(bid)
+	if !m.orderInputPanel.Submitting() || !strings.Contains(m.orderInputPanel.View(), "Submitting…") {
+		t.Fatal("expected the submit button to be disabled while the order is in flight")
+	}
+
+	// A second submit before the result arrives is dropped
+	_, second := m.Update(bid)
+	runCmd(second)
+	res := first().(orderResultMsg)
+	if n, _ := ms.GetOrderCount(aapl.TickerID(), core.SideBuy); n != 1 {
+		t.Fatalf("expected one order on the book, got %d", n)
+	}
+
+	m.Update(res)
+	if m.orderInputPanel.Submitting() || strings.Contains(m.orderInputPanel.View(), "Submitting…") {
+		t.Fatal("expected the submit button to be enabled once the result arrives")
+	}
+	_, cmd := m.Update(bid)
+	m.Update(cmd())
+	if n, _ := ms.GetOrderCount(aapl.TickerID(), core.SideBuy); n != 2 {
+		t.Errorf("expected a second order after the result, got %d", n)
+	}
+}
+
+func TestViewCacheInvalidatesOnChange(t *testing.T) {
+	m := newTestModel(t)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	first := m.View()
+	if again := m.View(); again != first {
+		t.Fatal("unchanged frame rendered differently")
+	}
+
+	m.orderbookPanel.SetLevels([]orderbookview.Level{{Price: 12345, Size: 777}}, nil)
+	withLevel := m.View()
+	if withLevel == first || !strings.Contains(withLevel, "123.45") {
+		t.Fatal("orderbook change did not produce a fresh render")
+	}
+
+	m.Update(NotifyMsg{Category: notify.CategorySystem, Text: "reloaded"})
+	withStatus := m.View()
+	if withStatus == withLevel || !strings.Contains(withStatus, "reloaded") {
+		t.Fatal("status change did not produce a fresh render")
+	}
+
+	// Focus styling is colour-only, so check the panel was re-rendered.
+	before := m.marketPanel.Version()
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m.View()
+	if m.marketPanel.Version() == before {
+		t.Fatal("focus change did not invalidate the focused panel")
+	}
+
+	focused := m.View()
+	m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	if m.View() == focused {
+		t.Fatal("resize did not produce a fresh render")
+	}
+}
+
+func TestNotificationsRouteThroughPreferences(t *testing.T) {
+	m := newTestModel(t)
+	var out bytes.Buffer
+	m.platform = notify.NewPlatform(notify.PlatformConfig{Out: &out, Getenv: func(string) string { return "" }})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	// Rejections toast and ring by default
+	_, cmd := m.Update(orderResultMsg{category: notify.CategoryRejection, severity: notify.SeverityError, message: "❌ Order failed: halted"})
+	runCmd(cmd)
+	if bells := strings.Count(out.String(), "\a"); bells != 1 {
+		t.Errorf("expected 1 bell, got %d", bells)
+	}
+	if got := m.View(); strings.Count(got, "Order failed: halted") != 2 {
+		t.Errorf("expected the rejection in the toast and status bar:\n%s", got)
+	}
+
+	// A storm of the same rejection collapses without ringing again
+	for i := 0; i < 4; i++ {
+		_, cmd = m.Update(orderResultMsg{category: notify.CategoryRejection, severity: notify.SeverityError, message: "❌ Order failed: halted"})
+		runCmd(cmd)
+	}
+	if bells := strings.Count(out.String(), "\a"); bells != 1 {
+		t.Errorf("collapsed repeats should not ring, got %d bells", bells)
+	}
+	if got := m.View(); !strings.Contains(got, "Order failed: halted (×5)") {
+		t.Errorf("expected collapsed count in the view:\n%s", got)
+	}
+
+	// Muted categories only reach the history
+	s := notify.DefaultSettings()
+	s.Categories[notify.CategorySystem] = notify.Preference{Delivery: notify.DeliverMute}
+	m.SetNotificationSettings(s)
+	m.Update(NotifyMsg{Category: notify.CategorySystem, Text: "scenario reloaded"})
+	if strings.Contains(m.View(), "scenario reloaded") {
+		t.Error("muted notification should not be shown")
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	history := m.View()
+	if !strings.Contains(history, "scenario reloaded (muted)") || !strings.Contains(history, "Notifications (2)") {
+		t.Errorf("expected muted message in the history view:\n%s", history)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if strings.Contains(m.View(), "Notifications (2)") {
+		t.Error("expected Esc to close the history view")
+	}
+}
+
+func TestNewsFlashFollowsSeverityThreshold(t *testing.T) {
+	m := newTestModel(t)
+	var out bytes.Buffer
+	m.platform = notify.NewPlatform(notify.PlatformConfig{Out: &out, Getenv: func(string) string { return "" }})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	// Closed, so the re-armed news listener returns at once
+	m.newsService.Close()
+
+	s := notify.DefaultSettings()
+	s.News = notify.NewsSettings{FlashSeverity: 2, Bell: true}
+	m.SetNotificationSettings(s)
+
+	_, cmd := m.Update(panels.NewsUpdateMsg{Item: news.NewsItem{Headline: "Quiet session", Severity: 1}})
+	runCmd(cmd)
+	if strings.Contains(m.View(), "BREAKING") || out.Len() != 0 {
+		t.Errorf("severity 1 should neither flash nor ring, got bell output %q:\n%s", out.String(), m.View())
+	}
+
+	_, cmd = m.Update(panels.NewsUpdateMsg{Item: news.NewsItem{Headline: "Exchange halts trading", Severity: 2}})
+	runCmd(cmd)
+	if !strings.Contains(m.View(), "BREAKING: Exchange halts trading") {
+		t.Errorf("expected severity 2 to flash:\n%s", m.View())
+	}
+	if bells := strings.Count(out.String(), "\a"); bells != 1 {
+		t.Errorf("expected 1 bell, got %d", bells)
+	}
+
+	// Without the bell option the flash is silent
+	s.News.Bell = false
+	m.SetNotificationSettings(s)
+	_, cmd = m.Update(panels.NewsUpdateMsg{Item: news.NewsItem{Headline: "Merger announced", Severity: 3}})
+	runCmd(cmd)
+	if !strings.Contains(m.View(), "BREAKING: Merger announced") {
+		t.Errorf("expected severity 3 to flash:\n%s", m.View())
+	}
+	if bells := strings.Count(out.String(), "\a"); bells != 1 {
+		t.Errorf("expected no further bell, got %d", bells)
+	}
+}
+
+// runCmd runs a command and any batched commands it returns.
+func runCmd(cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	if batch, ok := cmd().(tea.BatchMsg); ok {
+		for _, c := range batch {
+			runCmd(c)
+		}
+	}
+}
+
+func TestSetObjectivesAddsPanel(t *testing.T) {
+	m := newTestModel(t)
+	clk := clock.NewManual(time.Unix(0, 0))
+	reg := objective.NewRegistry()
+	reg.Register(objective.Metric{Name: "fills", Value: func(string) (float64, error) { return 2, nil }})
+	ev, err := objective.NewEvaluator(reg, []objective.Objective{
+		{Name: "Trade twice", Expr: "fills >= 2 by 1h"},
+	}, objective.Config{Interval: 1000 * time.Hour, Clock: clk})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ev.Close()
+
+	m.SetObjectives(ev)
+	m.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	out := m.View()
+	if !strings.Contains(out, "Trade twice") || !strings.Contains(out, "PENDING") || !strings.Contains(out, "F1-F7") {
+		t.Fatalf("expected the objectives panel and its key:\n%s", out)
+	}
+
+	ev.Evaluate()
+	m.Update(tickMsg{})
+	if out := m.View(); !strings.Contains(out, "MET") {
+		t.Errorf("expected the tick to refresh objective statuses:\n%s", out)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyF7})
+	if m.registry.Focused() != panels.Panel(m.objectivesPanel) {
+		t.Error("expected F7 to focus the objectives panel")
+	}
+}
+
+func TestAverageFillPriceSurvivesOverflow(t *testing.T) {
+	if got := averageFillPrice([]core.Fill{{Price: 100, Size: 3}, {Price: 103, Size: 1}}); got != 100 {
+		t.Errorf("expected 100, got %d", got)
+	}
+
+	// Price × size wraps an int64; the average must not
+	big := core.PriceTicks(math.MaxInt64 / 4)
+	got := averageFillPrice([]core.Fill{{Price: big, Size: 2}, {Price: big, Size: 2}})
+	if got <= 0 || math.Abs(float64(got-int64(big))) > 1e6 {
+		t.Errorf("expected about %d, got %d", big, got)
+	}
+}
+
+func TestImbalanceAlertNotifies(t *testing.T) {
+	m := newTestModel(t)
+	ev := marketview.ImbalanceEvent{Imbalance: -0.8, Side: core.SideSell, BidSize: 10, AskSize: 90}
+	m.Update(panels.MarketUpdateMsg{Ticker: 1, Imbalance: &ev})
+	h := m.notifier.History()
+	want := "⚖ AAPL book imbalance: offers 80% heavier (10 × 90)"
+	if len(h) == 0 || h[len(h)-1].Text != want || h[len(h)-1].Category != notify.CategoryAlert {
+		t.Errorf("expected alert %q, got %+v", want, h)
+	}
+}
+
+func TestMarketListenerRearms(t *testing.T) {
+	m := newTestModel(t)
+	ctx := context.Background()
+	m.marketService.SubmitLimit(ctx, 1, 2000, core.SideSell, 101, 5)
+	m.marketService.SubmitLimit(ctx, 1, 2000, core.SideSell, 102, 5)
+
+	// Each event read re-arms the listener for the next
+	cmd := m.listenMarketEvents()
+	for i := range 2 {
+		msg, ok := listened(cmd).(marketEventMsg)
+		if !ok {
+			t.Fatalf("event %d: expected a market event, got %T", i, msg)
+		}
+		if _, ok := msg.update.Event.(core.OrderRestedEvent); !ok {
+			t.Errorf("event %d: expected a rested order, got %+v", i, msg.update)
+		}
+		_, cmd = m.Update(msg)
+	}
+
+	// The model's subscription leaves Events to other readers
+	if len(m.marketService.Events()) != 2 {
+		t.Errorf("expected both events still on Events, got %d", len(m.marketService.Events()))
+	}
+}
+
+// listened runs cmd, or the last command of its batch, where Update puts
+// the re-armed listener.
+func listened(cmd tea.Cmd) tea.Msg {
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok && len(batch) > 0 {
+		return batch[len(batch)-1]()
+	}
+	return msg
+}
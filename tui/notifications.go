@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zappabad/stockcraft/tui/notify"
+	"github.com/zappabad/stockcraft/tui/styles"
+)
+
+// renderToast renders the active toast as a full-width line styled by
+// severity.
+func renderToast(n notify.Notification, width int) string {
+	style := styles.ToastInfoStyle
+	switch n.Severity {
+	case notify.SeverityWarning:
+		style = styles.ToastWarningStyle
+	case notify.SeverityError:
+		style = styles.ToastErrorStyle
+	}
+	return style.Width(width).Render(n.Display())
+}
+
+// renderNotificationHistory renders the notification history, newest first,
+// in place of the panels.
+func renderNotificationHistory(items []notify.Notification, width, height int) string {
+	var content strings.Builder
+
+	if len(items) == 0 {
+		content.WriteString(lipgloss.NewStyle().Foreground(styles.TextMutedColor).Render("No notifications yet"))
+	}
+
+	visible := height - 4
+	for i := len(items) - 1; i >= 0 && len(items)-i <= visible; i-- {
+		n := items[i]
+
+		textStyle := styles.NewsNormalStyle
+		if n.Severity > notify.SeverityInfo {
+			textStyle = styles.NewsImportantStyle
+		}
+		text := n.Display()
+		if n.Delivery == notify.DeliverMute {
+			text += " (muted)"
+		}
+
+		line := fmt.Sprintf("%s %-9s %-5s %s",
+			styles.TimeStyle.Render(n.Time.Format("15:04:05")),
+			n.Category,
+			n.Severity,
+			textStyle.Render(text),
+		)
+		content.WriteString(line)
+		if i > 0 && len(items)-i < visible {
+			content.WriteString("\n")
+		}
+	}
+
+	title := styles.RenderTitle(fmt.Sprintf("🔔 Notifications (%d) - Esc to close", len(items)), true)
+	panel := lipgloss.JoinVertical(lipgloss.Left, title, content.String())
+
+	return styles.FocusedPanelStyle.Width(width - 2).Height(height - 2).Render(panel)
+}
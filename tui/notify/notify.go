@@ -0,0 +1,380 @@
+// Package notify routes TUI notifications by category. Each category is
+// shown as a toast, kept to the status bar, or muted, with an optional bell
+// and an optional desktop notification for when the terminal is in the
+// background.
+// Every notification is kept in a bounded history so muted or expired
+// messages can still be reviewed, and repeats of the same message in quick
+// succession collapse into one entry with a count.
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+)
+
+// Category groups notifications that share a routing preference.
+type Category uint8
+
+const (
+	// CategoryOrder reports accepted orders and cancels.
+	CategoryOrder Category = iota
+	// CategoryFill reports the player's fills.
+	CategoryFill
+	// CategoryRejection reports rejected orders and failed cancels.
+	CategoryRejection
+	// CategoryAlert reports price and market alerts.
+	CategoryAlert
+	// CategoryNews reports news headlines.
+	CategoryNews
+	// CategoryBroker reports broker request alarms.
+	CategoryBroker
+	// CategorySystem reports background components such as scenario reloads.
+	CategorySystem
+
+	numCategories
+)
+
+var categoryNames = [numCategories]string{
+	CategoryOrder:     "order",
+	CategoryFill:      "fill",
+	CategoryRejection: "rejection",
+	CategoryAlert:     "alert",
+	CategoryNews:      "news",
+	CategoryBroker:    "broker",
+	CategorySystem:    "system",
+}
+
+// Categories returns every category in display order.
+func Categories() []Category {
+	out := make([]Category, numCategories)
+	for i := range out {
+		out[i] = Category(i)
+	}
+	return out
+}
+
+func (c Category) String() string {
+	if c < numCategories {
+		return categoryNames[c]
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the category by name.
+func (c Category) MarshalText() ([]byte, error) {
+	if c >= numCategories {
+		return nil, fmt.Errorf("notify: unknown category %d", c)
+	}
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText decodes a category name.
+func (c *Category) UnmarshalText(b []byte) error {
+	for i, name := range categoryNames {
+		if name == string(b) {
+			*c = Category(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("notify: unknown category %q", b)
+}
+
+// Severity is how serious a notification is.
+type Severity uint8
+
+const (
+	SeverityInfo Severity = iota
+	SeverityWarning
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityInfo:
+		return "INFO"
+	case SeverityWarning:
+		return "WARN"
+	case SeverityError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// MarshalText encodes the severity as its lower-case name.
+func (s Severity) MarshalText() ([]byte, error) {
+	if s > SeverityError {
+		return nil, fmt.Errorf("notify: unknown severity %d", s)
+	}
+	return []byte(strings.ToLower(s.String())), nil
+}
+
+// UnmarshalText decodes a severity name.
+func (s *Severity) UnmarshalText(b []byte) error {
+	for sev := SeverityInfo; sev <= SeverityError; sev++ {
+		if strings.EqualFold(sev.String(), string(b)) {
+			*s = sev
+			return nil
+		}
+	}
+	return fmt.Errorf("notify: unknown severity %q", b)
+}
+
+// Delivery is where a category's notifications are shown.
+type Delivery uint8
+
+const (
+	// DeliverToast shows the notification prominently until it expires, and
+	// on the status bar.
+	DeliverToast Delivery = iota
+	// DeliverStatus shows the notification on the status bar only.
+	DeliverStatus
+	// DeliverMute records the notification in the history only.
+	DeliverMute
+)
+
+var deliveryNames = [...]string{
+	DeliverToast:  "toast",
+	DeliverStatus: "status",
+	DeliverMute:   "mute",
+}
+
+func (d Delivery) String() string {
+	if int(d) < len(deliveryNames) {
+		return deliveryNames[d]
+	}
+	return "unknown"
+}
+
+// MarshalText encodes the delivery by name.
+func (d Delivery) MarshalText() ([]byte, error) {
+	if int(d) >= len(deliveryNames) {
+		return nil, fmt.Errorf("notify: unknown delivery %d", d)
+	}
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a delivery name.
+func (d *Delivery) UnmarshalText(b []byte) error {
+	for i, name := range deliveryNames {
+		if name == string(b) {
+			*d = Delivery(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("notify: unknown delivery %q", b)
+}
+
+// Notification is one entry in the history. Count is above 1 when repeats
+// were collapsed into it.
+type Notification struct {
+	Time     time.Time
+	Category Category
+	Severity Severity
+	Text     string
+	Count    int
+	Delivery Delivery
+}
+
+// Display returns the text with the repeat count, e.g. "Order failed (×23)".
+func (n Notification) Display() string {
+	if n.Count > 1 {
+		return fmt.Sprintf("%s (×%d)", n.Text, n.Count)
+	}
+	return n.Text
+}
+
+// Config holds router limits.
+type Config struct {
+	// HistorySize is how many notifications are kept for review.
+	HistorySize int
+	// CollapseWindow is how soon a repeat of a category's last message must
+	// arrive to be collapsed into it.
+	CollapseWindow time.Duration
+	// ToastDuration is how long a toast stays up.
+	ToastDuration time.Duration
+	// Clock provides the time; nil means the real clock.
+	Clock clock.Clock
+}
+
+// DefaultConfig returns a Config with reasonable defaults.
+func DefaultConfig() Config {
+	return Config{
+		HistorySize:    100,
+		CollapseWindow: 2 * time.Second,
+		ToastDuration:  4 * time.Second,
+	}
+}
+
+// Result tells the caller how to present a notification.
+type Result struct {
+	Notification Notification
+	// Bell is set when the terminal bell should ring. A collapsed repeat
+	// does not ring again.
+	Bell bool
+	// Desktop is set when the notification should also go to the desktop
+	// channels. Like the bell, a collapsed repeat does not.
+	Desktop bool
+	// Collapsed is set when the notification was merged into the previous
+	// one of its category.
+	Collapsed bool
+}
+
+// Router applies preferences to notifications and keeps their history.
+// It is not safe for concurrent use; the TUI calls it from its update loop.
+type Router struct {
+	cfg      Config
+	settings Settings
+
+	// Ring of the last HistorySize notifications
+	history []Notification
+	start   int
+	count   int
+
+	// History slot of each category's latest notification, for collapsing
+	last map[Category]int
+	// Sequence number of each slot, so overwritten slots are not collapsed into
+	seq     []uint64
+	lastSeq map[Category]uint64
+	next    uint64
+
+	status    Notification
+	hasStatus bool
+	toast     Notification
+	toastEnd  time.Time
+}
+
+// NewRouter creates a router with the given preferences.
+func NewRouter(settings Settings, cfg Config) *Router {
+	def := DefaultConfig()
+	if cfg.HistorySize <= 0 {
+		cfg.HistorySize = def.HistorySize
+	}
+	if cfg.CollapseWindow <= 0 {
+		cfg.CollapseWindow = def.CollapseWindow
+	}
+	if cfg.ToastDuration <= 0 {
+		cfg.ToastDuration = def.ToastDuration
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
+	return &Router{
+		cfg:      cfg,
+		settings: settings.withDefaults(),
+		history:  make([]Notification, cfg.HistorySize),
+		seq:      make([]uint64, cfg.HistorySize),
+		last:     make(map[Category]int),
+		lastSeq:  make(map[Category]uint64),
+	}
+}
+
+// Settings returns the current preferences.
+func (r *Router) Settings() Settings {
+	return r.settings.clone()
+}
+
+// SetSettings replaces the preferences. Missing categories use defaults.
+func (r *Router) SetSettings(s Settings) {
+	r.settings = s.withDefaults()
+}
+
+// Notify records a notification and routes it by its category's preference.
+func (r *Router) Notify(cat Category, sev Severity, text string) Result {
+	return r.route(cat, sev, text, r.settings.Preference(cat))
+}
+
+// Flash records a notification and toasts it whatever its category's
+// delivery, ringing the bell if asked. Breaking news uses it to get past the
+// muted news category.
+func (r *Router) Flash(cat Category, sev Severity, text string, bell bool) Result {
+	pref := r.settings.Preference(cat)
+	pref.Delivery, pref.Bell = DeliverToast, bell
+	return r.route(cat, sev, text, pref)
+}
+
+func (r *Router) route(cat Category, sev Severity, text string, pref Preference) Result {
+	now := r.cfg.Clock.Now()
+
+	n := Notification{
+		Time:     now,
+		Category: cat,
+		Severity: sev,
+		Text:     text,
+		Count:    1,
+		Delivery: pref.Delivery,
+	}
+
+	collapsed := false
+	if i, ok := r.last[cat]; ok && r.seq[i] == r.lastSeq[cat] {
+		prev := r.history[i]
+		if prev.Text == text && prev.Severity == sev && now.Sub(prev.Time) <= r.cfg.CollapseWindow {
+			n.Count = prev.Count + 1
+			r.history[i] = n
+			collapsed = true
+		}
+	}
+	if !collapsed {
+		r.append(n)
+	}
+
+	switch pref.Delivery {
+	case DeliverToast:
+		r.toast, r.toastEnd = n, now.Add(r.cfg.ToastDuration)
+		r.status, r.hasStatus = n, true
+	case DeliverStatus:
+		r.status, r.hasStatus = n, true
+	}
+
+	alert := pref.Delivery != DeliverMute && !collapsed
+	return Result{
+		Notification: n,
+		Bell:         pref.Bell && alert,
+		Desktop:      pref.Desktop && alert && sev >= r.settings.Desktop.MinSeverity,
+		Collapsed:    collapsed,
+	}
+}
+
+func (r *Router) append(n Notification) {
+	size := len(r.history)
+	var i int
+	if r.count < size {
+		i = (r.start + r.count) % size
+		r.count++
+	} else {
+		// overwrite oldest
+		i = r.start
+		r.start = (r.start + 1) % size
+	}
+	r.next++
+	r.history[i] = n
+	r.seq[i] = r.next
+	r.last[n.Category] = i
+	r.lastSeq[n.Category] = r.next
+}
+
+// History returns the recorded notifications, oldest first, including
+// muted ones. Returns a copy.
+func (r *Router) History() []Notification {
+	out := make([]Notification, r.count)
+	for i := range out {
+		out[i] = r.history[(r.start+i)%len(r.history)]
+	}
+	return out
+}
+
+// Toast returns the active toast, if one has not expired.
+func (r *Router) Toast() (Notification, bool) {
+	if r.toast.Count == 0 || !r.cfg.Clock.Now().Before(r.toastEnd) {
+		return Notification{}, false
+	}
+	return r.toast, true
+}
+
+// Status returns the latest notification routed to the status bar.
+func (r *Router) Status() (Notification, bool) {
+	return r.status, r.hasStatus
+}
@@ -0,0 +1,161 @@
+package notify
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+)
+
+func newTestRouter(s Settings) (*Router, *clock.Manual) {
+	clk := clock.NewManual(time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC))
+	cfg := DefaultConfig()
+	cfg.Clock = clk
+	return NewRouter(s, cfg), clk
+}
+
+func TestRouting(t *testing.T) {
+	r, clk := newTestRouter(DefaultSettings())
+
+	res := r.Notify(CategoryOrder, SeverityInfo, "placed")
+	if res.Bell {
+		t.Error("order acknowledgements should not ring")
+	}
+	if st, ok := r.Status(); !ok || st.Text != "placed" {
+		t.Errorf("expected status 'placed', got %+v", st)
+	}
+	if _, ok := r.Toast(); ok {
+		t.Error("status-only category should not toast")
+	}
+
+	res = r.Notify(CategoryRejection, SeverityError, "rejected")
+	if !res.Bell {
+		t.Error("rejections should ring by default")
+	}
+	toast, ok := r.Toast()
+	if !ok || toast.Text != "rejected" {
+		t.Fatalf("expected rejection toast, got %+v", toast)
+	}
+	if st, _ := r.Status(); st.Text != "rejected" {
+		t.Errorf("toasts should also show on the status bar, got %q", st.Text)
+	}
+
+	clk.Advance(DefaultConfig().ToastDuration)
+	if _, ok := r.Toast(); ok {
+		t.Error("toast should expire")
+	}
+	if st, _ := r.Status(); st.Text != "rejected" {
+		t.Errorf("status should outlive the toast, got %q", st.Text)
+	}
+}
+
+func TestMuting(t *testing.T) {
+	s := DefaultSettings()
+	s.Categories[CategoryFill] = Preference{Delivery: DeliverMute, Bell: true}
+	r, _ := newTestRouter(s)
+
+	r.Notify(CategoryOrder, SeverityInfo, "placed")
+	res := r.Notify(CategoryFill, SeverityInfo, "filled 10")
+	if res.Bell {
+		t.Error("muted categories should not ring")
+	}
+	if st, _ := r.Status(); st.Text != "placed" {
+		t.Errorf("muted fill should not replace the status, got %q", st.Text)
+	}
+	if _, ok := r.Toast(); ok {
+		t.Error("muted fill should not toast")
+	}
+
+	h := r.History()
+	if len(h) != 2 || h[1].Text != "filled 10" || h[1].Delivery != DeliverMute {
+		t.Errorf("muted fill should still be in history, got %+v", h)
+	}
+}
+
+func TestHistoryRetention(t *testing.T) {
+	r, clk := newTestRouter(DefaultSettings())
+	for i := 0; i < 150; i++ {
+		r.Notify(CategorySystem, SeverityInfo, fmt.Sprintf("msg %d", i))
+		clk.Advance(time.Second)
+	}
+	h := r.History()
+	if len(h) != 100 {
+		t.Fatalf("expected 100 retained, got %d", len(h))
+	}
+	if h[0].Text != "msg 50" || h[99].Text != "msg 149" {
+		t.Errorf("expected msg 50..149, got %q..%q", h[0].Text, h[99].Text)
+	}
+}
+
+func TestCollapse(t *testing.T) {
+	r, clk := newTestRouter(DefaultSettings())
+
+	first := r.Notify(CategoryRejection, SeverityError, "Order failed")
+	if !first.Bell || first.Collapsed {
+		t.Fatalf("first message should ring and not collapse: %+v", first)
+	}
+	r.Notify(CategoryOrder, SeverityInfo, "other category")
+	for i := 0; i < 22; i++ {
+		clk.Advance(100 * time.Millisecond)
+		res := r.Notify(CategoryRejection, SeverityError, "Order failed")
+		if !res.Collapsed || res.Bell {
+			t.Fatalf("repeat %d should collapse without ringing: %+v", i, res)
+		}
+	}
+
+	h := r.History()
+	if len(h) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(h))
+	}
+	if got := h[0].Display(); got != "Order failed (×23)" {
+		t.Errorf("unexpected collapsed text %q", got)
+	}
+	if toast, _ := r.Toast(); toast.Display() != "Order failed (×23)" {
+		t.Errorf("toast should show the count, got %q", toast.Display())
+	}
+
+	// A different message, or a repeat after the window, starts a new entry.
+	r.Notify(CategoryRejection, SeverityError, "Cancel failed")
+	clk.Advance(3 * time.Second)
+	r.Notify(CategoryRejection, SeverityError, "Cancel failed")
+	if h := r.History(); len(h) != 4 || h[3].Count != 1 {
+		t.Errorf("expected separate entries after text change and window, got %+v", h)
+	}
+}
+
+func TestSettingsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatalf("missing file should yield defaults: %v", err)
+	}
+	if s.Preference(CategoryNews).Delivery != DeliverMute {
+		t.Error("expected default news preference")
+	}
+
+	s = Settings{
+		Categories: map[Category]Preference{
+			CategoryNews: {Delivery: DeliverToast, Bell: true},
+		},
+		Desktop: DesktopSettings{Escape: true, Exec: []string{"notify-send"}, MinSeverity: SeverityWarning},
+	}
+	if err := s.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := loaded.Preference(CategoryNews); p.Delivery != DeliverToast || !p.Bell {
+		t.Errorf("saved preference not loaded: %+v", p)
+	}
+	if p := loaded.Preference(CategoryRejection); p != DefaultSettings().Categories[CategoryRejection] {
+		t.Errorf("missing category should use its default, got %+v", p)
+	}
+	if d := loaded.Desktop; !d.Escape || len(d.Exec) != 1 || d.MinSeverity != SeverityWarning {
+		t.Errorf("desktop settings not loaded: %+v", d)
+	}
+}
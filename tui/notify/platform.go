@@ -0,0 +1,187 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+)
+
+// Terminal is the desktop notification escape a terminal understands.
+type Terminal uint8
+
+const (
+	// TerminalPlain supports only the bell.
+	TerminalPlain Terminal = iota
+	// TerminalOSC9 shows OSC 9 messages (iTerm2, WezTerm, Ghostty).
+	TerminalOSC9
+	// TerminalOSC777 shows OSC 777 notify messages with a title (foot,
+	// VTE-based terminals).
+	TerminalOSC777
+)
+
+// DetectTerminal guesses the terminal from its environment. Anything it does
+// not recognise, including a session inside tmux or screen, which swallow
+// unknown escapes, is TerminalPlain.
+func DetectTerminal(getenv func(string) string) Terminal {
+	if getenv("TMUX") != "" || getenv("STY") != "" {
+		return TerminalPlain
+	}
+	switch getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "ghostty":
+		return TerminalOSC9
+	}
+	if getenv("VTE_VERSION") != "" || strings.HasPrefix(getenv("TERM"), "foot") {
+		return TerminalOSC777
+	}
+	return TerminalPlain
+}
+
+// Escape returns the sequence that shows a notification, or "" for
+// TerminalPlain. Control characters are dropped so text cannot end the
+// sequence early.
+func (t Terminal) Escape(title, body string) string {
+	switch t {
+	case TerminalOSC9:
+		return "\x1b]9;" + sanitize(title+": "+body) + "\a"
+	case TerminalOSC777:
+		return "\x1b]777;notify;" + strings.ReplaceAll(sanitize(title), ";", ",") + ";" + sanitize(body) + "\a"
+	default:
+		return ""
+	}
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || (r >= 0x80 && r < 0xa0) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Runner starts an external command and waits for it. It must give up when
+// ctx is done.
+type Runner interface {
+	Run(ctx context.Context, name string, args ...string) error
+}
+
+type execRunner struct{}
+
+func (execRunner) Run(ctx context.Context, name string, args ...string) error {
+	return exec.CommandContext(ctx, name, args...).Run()
+}
+
+// PlatformConfig holds the outputs and limits of a Platform.
+type PlatformConfig struct {
+	// Out receives the bell and escape sequences; nil means os.Stdout.
+	Out io.Writer
+	// Getenv is used to detect the terminal; nil means os.Getenv.
+	Getenv func(string) string
+	// Runner runs the exec hook; nil means os/exec.
+	Runner Runner
+	// ExecTimeout bounds each run of the exec hook.
+	ExecTimeout time.Duration
+	// ExecInterval is the minimum time between runs of the exec hook.
+	// Notifications inside it skip the hook.
+	ExecInterval time.Duration
+	// Clock provides the time; nil means the real clock.
+	Clock clock.Clock
+}
+
+// DefaultPlatformConfig returns a PlatformConfig with reasonable defaults.
+func DefaultPlatformConfig() PlatformConfig {
+	return PlatformConfig{
+		ExecTimeout:  5 * time.Second,
+		ExecInterval: 30 * time.Second,
+	}
+}
+
+// Platform delivers routed notifications outside the TUI: the terminal bell,
+// a desktop notification escape and a user-configured command.
+type Platform struct {
+	cfg      PlatformConfig
+	terminal Terminal
+
+	mu       sync.Mutex // serializes writes to Out
+	lastExec time.Time
+}
+
+// NewPlatform creates a Platform and detects the terminal.
+func NewPlatform(cfg PlatformConfig) *Platform {
+	def := DefaultPlatformConfig()
+	if cfg.Out == nil {
+		cfg.Out = os.Stdout
+	}
+	if cfg.Getenv == nil {
+		cfg.Getenv = os.Getenv
+	}
+	if cfg.Runner == nil {
+		cfg.Runner = execRunner{}
+	}
+	if cfg.ExecTimeout <= 0 {
+		cfg.ExecTimeout = def.ExecTimeout
+	}
+	if cfg.ExecInterval <= 0 {
+		cfg.ExecInterval = def.ExecInterval
+	}
+	if cfg.Clock == nil {
+		cfg.Clock = clock.Real()
+	}
+	return &Platform{cfg: cfg, terminal: DetectTerminal(cfg.Getenv)}
+}
+
+// Terminal returns the detected terminal.
+func (p *Platform) Terminal() Terminal {
+	return p.terminal
+}
+
+// Deliver decides which channels a routed notification goes to and returns
+// a function that sends it, or nil if there is nothing to send. Call Deliver
+// from the update loop, where the exec hook's rate limit is applied, and run
+// the function off it. The exec hook runs in its own goroutine, so the
+// function never waits for it.
+func (p *Platform) Deliver(res Result, s DesktopSettings) func() {
+	var seq string
+	if res.Bell {
+		seq = "\a"
+	}
+	var hook []string
+	if res.Desktop {
+		title := "stockcraft " + res.Notification.Category.String()
+		body := res.Notification.Text
+		if s.Escape {
+			seq += p.terminal.Escape(title, body)
+		}
+		if len(s.Exec) > 0 {
+			now := p.cfg.Clock.Now()
+			if p.lastExec.IsZero() || now.Sub(p.lastExec) >= p.cfg.ExecInterval {
+				p.lastExec = now
+				hook = append(append(hook, s.Exec...), title, body)
+			}
+		}
+	}
+	if seq == "" && hook == nil {
+		return nil
+	}
+
+	return func() {
+		if seq != "" {
+			p.mu.Lock()
+			io.WriteString(p.cfg.Out, seq)
+			p.mu.Unlock()
+		}
+		if hook != nil {
+			go func() {
+				ctx, cancel := context.WithTimeout(context.Background(), p.cfg.ExecTimeout)
+				defer cancel()
+				p.cfg.Runner.Run(ctx, hook[0], hook[1:]...) // failures are not reported
+			}()
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/clock"
+)
+
+func env(vars map[string]string) func(string) string {
+	return func(k string) string { return vars[k] }
+}
+
+func TestDetectTerminal(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		want Terminal
+	}{
+		{"unknown", map[string]string{"TERM": "xterm-256color"}, TerminalPlain},
+		{"iTerm2", map[string]string{"TERM_PROGRAM": "iTerm.app"}, TerminalOSC9},
+		{"WezTerm", map[string]string{"TERM_PROGRAM": "WezTerm"}, TerminalOSC9},
+		{"VTE", map[string]string{"VTE_VERSION": "7600"}, TerminalOSC777},
+		{"foot", map[string]string{"TERM": "foot-extra"}, TerminalOSC777},
+		{"tmux", map[string]string{"TERM_PROGRAM": "iTerm.app", "TMUX": "/tmp/tmux-1000/default,1,0"}, TerminalPlain},
+		{"screen", map[string]string{"VTE_VERSION": "7600", "STY": "123.pts-0"}, TerminalPlain},
+	}
+	for _, tc := range tests {
+		if got := DetectTerminal(env(tc.vars)); got != tc.want {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestTerminalEscape(t *testing.T) {
+	if got := TerminalPlain.Escape("t", "b"); got != "" {
+		t.Errorf("plain terminals get no escape, got %q", got)
+	}
+	if got := TerminalOSC9.Escape("stockcraft alert", "AAPL halted"); got != "\x1b]9;stockcraft alert: AAPL halted\a" {
+		t.Errorf("unexpected OSC 9: %q", got)
+	}
+	// Control characters cannot end the sequence, and the title cannot add fields
+	if got := TerminalOSC777.Escape("a;b", "x\ay\x1b]z"); got != "\x1b]777;notify;a,b;xy]z\a" {
+		t.Errorf("unexpected OSC 777: %q", got)
+	}
+}
+
+type call struct {
+	name     string
+	args     []string
+	deadline bool
+}
+
+// fakeRunner reports each run on a channel, since the hook runs in its own
+// goroutine.
+type fakeRunner chan call
+
+func (f fakeRunner) Run(ctx context.Context, name string, args ...string) error {
+	_, ok := ctx.Deadline()
+	f <- call{name: name, args: args, deadline: ok}
+	return nil
+}
+
+func newTestPlatform(vars map[string]string) (*Platform, *bytes.Buffer, fakeRunner, *clock.Manual) {
+	var out bytes.Buffer
+	runs := make(fakeRunner, 10)
+	clk := clock.NewManual(time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC))
+	p := NewPlatform(PlatformConfig{Out: &out, Getenv: env(vars), Runner: runs, ExecInterval: time.Minute, Clock: clk})
+	return p, &out, runs, clk
+}
+
+func deliver(p *Platform, res Result, s DesktopSettings) {
+	if send := p.Deliver(res, s); send != nil {
+		send()
+	}
+}
+
+func TestPlatformRouting(t *testing.T) {
+	r, _ := newTestRouter(DefaultSettings())
+	p, out, _, _ := newTestPlatform(map[string]string{"TERM_PROGRAM": "iTerm.app"})
+	desktop := DesktopSettings{Escape: true}
+
+	// Order acknowledgements send nothing
+	if send := p.Deliver(r.Notify(CategoryOrder, SeverityInfo, "placed"), desktop); send != nil {
+		t.Error("expected nothing to send for an order acknowledgement")
+	}
+
+	// Rejections ring but stay off the desktop
+	deliver(p, r.Notify(CategoryRejection, SeverityError, "rejected"), desktop)
+	if out.String() != "\a" {
+		t.Errorf("expected a bell only, got %q", out.String())
+	}
+
+	// Alerts ring and raise a desktop notification
+	out.Reset()
+	deliver(p, r.Notify(CategoryAlert, SeverityWarning, "AAPL halted"), desktop)
+	if want := "\a\x1b]9;stockcraft alert: AAPL halted\a"; out.String() != want {
+		t.Errorf("expected %q, got %q", want, out.String())
+	}
+
+	// A collapsed repeat sends nothing
+	out.Reset()
+	deliver(p, r.Notify(CategoryAlert, SeverityWarning, "AAPL halted"), desktop)
+	if out.Len() != 0 {
+		t.Errorf("collapsed repeat should be silent, got %q", out.String())
+	}
+
+	// The desktop escape can be turned off, leaving the bell
+	deliver(p, r.Notify(CategoryAlert, SeverityWarning, "GOOGL halted"), DesktopSettings{})
+	if out.String() != "\a" {
+		t.Errorf("expected a bell only, got %q", out.String())
+	}
+}
+
+func TestPlatformUnsupportedTerminal(t *testing.T) {
+	r, _ := newTestRouter(DefaultSettings())
+	p, out, _, _ := newTestPlatform(map[string]string{"TERM": "xterm-256color"})
+
+	deliver(p, r.Notify(CategoryBroker, SeverityError, "SLA breached"), DesktopSettings{Escape: true})
+	if out.Len() != 0 {
+		t.Errorf("unsupported terminals should get nothing, got %q", out.String())
+	}
+}
+
+func TestDesktopSeverity(t *testing.T) {
+	s := DefaultSettings()
+	s.Desktop.MinSeverity = SeverityError
+	r, _ := newTestRouter(s)
+
+	if res := r.Notify(CategoryBroker, SeverityWarning, "late"); res.Desktop {
+		t.Error("a warning is below the desktop threshold")
+	}
+	if res := r.Notify(CategoryBroker, SeverityError, "SLA breached"); !res.Desktop {
+		t.Error("an error meets the desktop threshold")
+	}
+
+	s.Categories[CategoryBroker] = Preference{Delivery: DeliverMute, Desktop: true}
+	r.SetSettings(s)
+	if res := r.Notify(CategoryBroker, SeverityError, "muted"); res.Desktop {
+		t.Error("muted categories should not reach the desktop")
+	}
+}
+
+func TestPlatformExecHook(t *testing.T) {
+	r, _ := newTestRouter(DefaultSettings())
+	p, _, runs, clk := newTestPlatform(nil)
+	desktop := DesktopSettings{Exec: []string{"notify-send", "-u", "critical"}}
+
+	wait := func() call {
+		t.Helper()
+		select {
+		case c := <-runs:
+			return c
+		case <-time.After(time.Second):
+			t.Fatal("exec hook did not run")
+			return call{}
+		}
+	}
+
+	deliver(p, r.Notify(CategoryAlert, SeverityError, "margin buy-in"), desktop)
+	c := wait()
+	if c.name != "notify-send" || !slices.Equal(c.args, []string{"-u", "critical", "stockcraft alert", "margin buy-in"}) {
+		t.Errorf("unexpected hook call %+v", c)
+	}
+	if !c.deadline {
+		t.Error("hook should run with a timeout")
+	}
+
+	// A second alert inside the interval skips the hook
+	clk.Advance(30 * time.Second)
+	deliver(p, r.Notify(CategoryAlert, SeverityError, "breaker tripped"), desktop)
+	select {
+	case c := <-runs:
+		t.Errorf("hook should be rate limited, ran %+v", c)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	clk.Advance(30 * time.Second)
+	deliver(p, r.Notify(CategoryAlert, SeverityError, "breaker reset"), desktop)
+	if c := wait(); c.args[len(c.args)-1] != "breaker reset" {
+		t.Errorf("expected the hook after the interval, got %+v", c)
+	}
+}
+
+// blockingRunner never returns until its context is done.
+type blockingRunner chan error
+
+func (b blockingRunner) Run(ctx context.Context, name string, args ...string) error {
+	<-ctx.Done()
+	b <- ctx.Err()
+	return ctx.Err()
+}
+
+func TestPlatformExecHookDoesNotBlock(t *testing.T) {
+	r, _ := newTestRouter(DefaultSettings())
+	done := make(blockingRunner, 1)
+	p := NewPlatform(PlatformConfig{Out: &bytes.Buffer{}, Getenv: env(nil), Runner: done, ExecTimeout: 20 * time.Millisecond})
+
+	send := p.Deliver(r.Notify(CategoryAlert, SeverityError, "stuck"), DesktopSettings{Exec: []string{"sleep"}})
+	start := time.Now()
+	send()
+	if d := time.Since(start); d > 10*time.Millisecond {
+		t.Errorf("send waited %v for the hook", d)
+	}
+	select {
+	case err := <-done:
+		if err != context.DeadlineExceeded {
+			t.Errorf("expected the hook to time out, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("hook was not timed out")
+	}
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"slices"
+)
+
+// Preference is how one category's notifications are delivered.
+type Preference struct {
+	Delivery Delivery `json:"delivery"`
+	Bell     bool     `json:"bell"`
+	// Desktop also sends the notification to the enabled desktop channels.
+	Desktop bool `json:"desktop"`
+}
+
+// DesktopSettings enables the channels that reach the player outside the
+// terminal. Both are off unless configured.
+type DesktopSettings struct {
+	// Escape sends an OSC 9 or OSC 777 notification when the terminal is
+	// known to show one. Other terminals get nothing.
+	Escape bool `json:"escape"`
+	// Exec is a command and its leading arguments, run with the title and
+	// body appended. Empty disables it.
+	Exec []string `json:"exec,omitempty"`
+	// MinSeverity is the lowest severity sent to the desktop.
+	MinSeverity Severity `json:"min_severity"`
+}
+
+// NewsSettings picks the headlines that flash as a breaking-news toast,
+// whatever the news category's delivery. The flash is off unless configured.
+type NewsSettings struct {
+	// FlashSeverity is the lowest headline severity that flashes. 0, the
+	// severity of ordinary news, turns the flash off.
+	FlashSeverity int `json:"flash_severity"`
+	// Bell also rings the terminal bell for a flashed headline.
+	Bell bool `json:"bell"`
+}
+
+// Flashes reports whether a headline of the given severity flashes.
+func (n NewsSettings) Flashes(severity int) bool {
+	return n.FlashSeverity > 0 && severity >= n.FlashSeverity
+}
+
+// Settings holds the per-category preferences. Categories without an entry
+// use DefaultSettings.
+type Settings struct {
+	Categories map[Category]Preference `json:"notifications"`
+	Desktop    DesktopSettings         `json:"desktop"`
+	News       NewsSettings            `json:"news"`
+}
+
+// DefaultSettings returns the built-in preferences: rejections and alerts
+// toast with a bell, fills and broker alarms toast, order acknowledgements
+// and system messages stay on the status bar, and news is muted since the
+// news panel already shows it. Alerts and broker alarms also go to the
+// desktop once a desktop channel is enabled.
+func DefaultSettings() Settings {
+	return Settings{Categories: map[Category]Preference{
+		CategoryOrder:     {Delivery: DeliverStatus},
+		CategoryFill:      {Delivery: DeliverToast},
+		CategoryRejection: {Delivery: DeliverToast, Bell: true},
+		CategoryAlert:     {Delivery: DeliverToast, Bell: true, Desktop: true},
+		CategoryNews:      {Delivery: DeliverMute},
+		CategoryBroker:    {Delivery: DeliverToast, Desktop: true},
+		CategorySystem:    {Delivery: DeliverStatus},
+	}}
+}
+
+// Preference returns the preference for a category.
+func (s Settings) Preference(cat Category) Preference {
+	if p, ok := s.Categories[cat]; ok {
+		return p
+	}
+	return DefaultSettings().Categories[cat]
+}
+
+func (s Settings) clone() Settings {
+	out := Settings{Categories: maps.Clone(s.Categories), Desktop: s.Desktop, News: s.News}
+	out.Desktop.Exec = slices.Clone(s.Desktop.Exec)
+	return out
+}
+
+// withDefaults returns a copy with every missing category filled in.
+func (s Settings) withDefaults() Settings {
+	out := DefaultSettings()
+	maps.Copy(out.Categories, s.Categories)
+	out.Desktop = s.Desktop
+	out.News = s.News
+	out.Desktop.Exec = slices.Clone(s.Desktop.Exec)
+	return out
+}
+
+// LoadSettings reads settings from a JSON file. A missing file yields the
+// defaults; categories missing from the file use their defaults.
+func LoadSettings(path string) (Settings, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return DefaultSettings(), nil
+	}
+	if err != nil {
+		return Settings{}, err
+	}
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Settings{}, fmt.Errorf("notify: %s: %w", path, err)
+	}
+	return s.withDefaults(), nil
+}
+
+// Save writes the settings to a JSON file.
+func (s Settings) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/tui/notify"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+// orderQueue holds orders the player entered while their ticker was not
+// accepting them, until it opens. It is keyed by ticker, so switching the
+// order entry panel to another ticker keeps them.
+type orderQueue struct {
+	orders map[market.TickerID][]panels.OrderSubmitMsg
+}
+
+func newOrderQueue() *orderQueue {
+	return &orderQueue{orders: make(map[market.TickerID][]panels.OrderSubmitMsg)}
+}
+
+// Add holds an order and returns how many are held for its ticker.
+func (q *orderQueue) Add(o panels.OrderSubmitMsg) int {
+	tid := o.Ticker.TickerID()
+	q.orders[tid] = append(q.orders[tid], o)
+	return len(q.orders[tid])
+}
+
+// Take removes and returns a ticker's held orders, oldest first.
+func (q *orderQueue) Take(tid market.TickerID) []panels.OrderSubmitMsg {
+	orders := q.orders[tid]
+	delete(q.orders, tid)
+	return orders
+}
+
+// Len returns how many orders are held for a ticker.
+func (q *orderQueue) Len(tid market.TickerID) int {
+	return len(q.orders[tid])
+}
+
+// describeOrder is a one-line summary of an order for notifications, with
+// its price shown in the given display mode.
+func describeOrder(o panels.OrderSubmitMsg, prices money.Display) string {
+	if o.OrderKind == core.OrderKindMarket {
+		return fmt.Sprintf("%s %d %s MARKET", o.Side, o.Quantity, o.Ticker.Name)
+	}
+	return fmt.Sprintf("%s %d %s @ %s", o.Side, o.Quantity, o.Ticker.Name, prices.FormatPrice(int64(o.Price), o.Ticker.Decimals))
+}
+
+// queueOrder holds an order for its ticker's open.
+func (m *Model) queueOrder(o panels.OrderSubmitMsg) tea.Cmd {
+	n := m.queue.Add(o)
+	m.orderInputPanel.SetQueued(o.Ticker.TickerID(), n)
+	return m.Notify(notify.CategoryOrder, notify.SeverityInfo, fmt.Sprintf("⏸ Queued %s for the open · ctrl+d to discard", describeOrder(o, m.prefs.PriceDisplay)))
+}
+
+// discardQueued drops a ticker's held orders.
+func (m *Model) discardQueued(tid market.TickerID) tea.Cmd {
+	n := len(m.queue.Take(tid))
+	if n == 0 {
+		return nil
+	}
+	m.orderInputPanel.SetQueued(tid, 0)
+	return m.Notify(notify.CategoryOrder, notify.SeverityInfo, fmt.Sprintf("🗑 Discarded %d queued order(s) for %s", n, m.tickerMap[tid].Name))
+}
+
+// setTradingState pushes a ticker's trading state to the order entry panel
+// and, once the ticker is open, releases its held orders. Limit prices are
+// checked against the circuit breaker band as of the release, since it may
+// have moved while the ticker was halted; orders outside it are dropped.
+func (m *Model) setTradingState(tid market.TickerID, st marketview.StatusEvent) tea.Cmd {
+	m.orderInputPanel.SetTradingState(tid, st)
+	if st.Status != market.StatusOpen || m.queue.Len(tid) == 0 {
+		return nil
+	}
+
+	orders := m.queue.Take(tid)
+	m.orderInputPanel.SetQueued(tid, 0)
+	band, _ := m.marketService.GetPriceBand(tid)
+	prices := m.prefs.PriceDisplay
+
+	var cmds []tea.Cmd
+	var release []panels.OrderSubmitMsg
+	for _, o := range orders {
+		if o.OrderKind == core.OrderKindLimit && !band.Contains(o.Price) {
+			d := o.Ticker.Decimals
+			cmds = append(cmds, m.Notify(notify.CategoryRejection, notify.SeverityWarning, fmt.Sprintf("⚠ Queued %s dropped: outside the %s-%s band",
+				describeOrder(o, prices), prices.FormatPrice(int64(band.Low), d), prices.FormatPrice(int64(band.High), d))))
+			continue
+		}
+		cmds = append(cmds, m.Notify(notify.CategoryOrder, notify.SeverityInfo, fmt.Sprintf("▶ %s open: releasing queued %s", o.Ticker.Name, describeOrder(o, prices))))
+		release = append(release, o)
+	}
+	if len(release) > 0 {
+		cmds = append(cmds, m.releaseOrders(release))
+	}
+	return tea.Batch(cmds...)
+}
+
+// releasedMsg carries the results of submitting released orders.
+type releasedMsg []orderResultMsg
+
+// releaseOrders submits orders one after another, in the order they were
+// queued.
+func (m *Model) releaseOrders(orders []panels.OrderSubmitMsg) tea.Cmd {
+	return func() tea.Msg {
+		results := make(releasedMsg, 0, len(orders))
+		for _, o := range orders {
+			results = append(results, m.submitOrder(o)().(orderResultMsg))
+		}
+		return results
+	}
+}
@@ -0,0 +1,251 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	newsservice "github.com/zappabad/stockcraft/internal/news/service"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+// newBandedModel is a test model whose AAPL has a 10% circuit breaker band
+// around 100 ticks.
+func newBandedModel(t *testing.T) *Model {
+	t.Helper()
+	tickers := []market.Ticker{
+		{ID: 1, Name: "AAPL", Decimals: 2},
+		{ID: 2, Name: "GOOGL", Decimals: 2},
+	}
+	cfg := marketservice.DefaultConfig()
+	cfg.CircuitBreaker.LimitPct = 10
+	ms := marketservice.NewMarketService(tickers, cfg)
+	t.Cleanup(ms.Close)
+	if err := ms.SetReferencePrice(1, 100); err != nil {
+		t.Fatal(err)
+	}
+	ns := newsservice.NewNewsService(newsservice.DefaultConfig())
+	t.Cleanup(ns.Close)
+	m := NewModel(ms, ns, 1000)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return m
+}
+
+// drain runs a command and feeds every message it produces back into the
+// model, following batches.
+func drain(m *Model, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	switch msg := cmd().(type) {
+	case nil:
+	case tea.BatchMsg:
+		for _, c := range msg {
+			drain(m, c)
+		}
+	default:
+		_, next := m.Update(msg)
+		drain(m, next)
+	}
+}
+
+func limitBuy(t market.Ticker, price core.PriceTicks, qty core.Size) panels.OrderSubmitMsg {
+	return panels.OrderSubmitMsg{Ticker: t, Side: core.SideBuy, OrderKind: core.OrderKindLimit, Price: price, Quantity: qty}
+}
+
+func restingBids(t *testing.T, m *Model, tid market.TickerID) []core.PriceTicks {
+	t.Helper()
+	// Wait for view update
+	time.Sleep(50 * time.Millisecond)
+	orders, err := m.marketService.GetOrders(tid, core.SideBuy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var prices []core.PriceTicks
+	for _, o := range orders {
+		prices = append(prices, o.Price)
+	}
+	return prices
+}
+
+func TestQueuedOrdersReleaseWhenHaltLifts(t *testing.T) {
+	m := newBandedModel(t)
+	aapl, googl := m.tickers[0], m.tickers[1]
+	ctx := context.Background()
+
+	if _, err := m.marketService.EmergencyStop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	drain(m, m.updateAllData())
+	m.orderInputPanel.SetTicker(aapl)
+	if out := m.orderInputPanel.View(); !strings.Contains(out, "HALTED: emergency stop") {
+		t.Fatalf("expected a halt banner:\n%s", out)
+	}
+
+	// One order inside the band and one the band rejects at release
+	drain(m, func() tea.Msg { return panels.QueueOrderMsg{Order: limitBuy(aapl, 105, 5)} })
+	drain(m, func() tea.Msg { return panels.QueueOrderMsg{Order: limitBuy(aapl, 120, 5)} })
+	if !strings.Contains(m.View(), "Queued BUY 5 AAPL @ 1.20 for the open") {
+		t.Errorf("expected a queued confirmation:\n%s", m.View())
+	}
+	if out := m.orderInputPanel.View(); !strings.Contains(out, "2 queued for open") {
+		t.Errorf("expected the queued count in the panel:\n%s", out)
+	}
+
+	// The queue survives switching ticker and focus away from the panel
+	m.orderInputPanel.SetTicker(googl)
+	m.registry.Focus(m.marketPanel)
+	if out := m.orderInputPanel.View(); strings.Contains(out, "queued for open") {
+		t.Errorf("expected no queued orders shown for GOOGL:\n%s", out)
+	}
+
+	// GOOGL reopening releases nothing
+	if _, err := m.marketService.OpenSession(ctx, googl.TickerID()); err != nil {
+		t.Fatal(err)
+	}
+	drain(m, m.updateAllData())
+	if n := m.queue.Len(aapl.TickerID()); n != 2 {
+		t.Fatalf("expected AAPL's orders still held, got %d", n)
+	}
+
+	if _, err := m.marketService.OpenSession(ctx, aapl.TickerID()); err != nil {
+		t.Fatal(err)
+	}
+	drain(m, m.updateAllData())
+	if prices := restingBids(t, m, aapl.TickerID()); len(prices) != 1 || prices[0] != 105 {
+		t.Errorf("expected only the in-band order to be submitted, got %v", prices)
+	}
+	if m.queue.Len(aapl.TickerID()) != 0 {
+		t.Error("expected the queue to be emptied on release")
+	}
+	history := m.notifier.History()
+	var texts []string
+	for _, n := range history {
+		texts = append(texts, n.Text)
+	}
+	joined := strings.Join(texts, "\n")
+	for _, want := range []string{
+		"▶ AAPL open: releasing queued BUY 5 AAPL @ 1.05",
+		"⚠ Queued BUY 5 AAPL @ 1.20 dropped: outside the 0.90-1.10 band",
+		"✓ Order placed",
+	} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("expected %q in the notifications:\n%s", want, joined)
+		}
+	}
+
+	// The next poll does not release anything twice
+	drain(m, m.updateAllData())
+	if prices := restingBids(t, m, aapl.TickerID()); len(prices) != 1 {
+		t.Errorf("expected one resting order, got %v", prices)
+	}
+}
+
+func TestQueuedMarketOrderReleasesFromPreOpen(t *testing.T) {
+	m := newBandedModel(t)
+	aapl := m.tickers[0]
+	ctx := context.Background()
+
+	// Status events arrive through market updates as well as polling
+	if err := m.marketService.BeginPreOpen(ctx, aapl.TickerID()); err != nil {
+		t.Fatal(err)
+	}
+	st, _ := m.marketService.GetTradingState(aapl.TickerID())
+	drain(m, func() tea.Msg { return panels.MarketUpdateMsg{Ticker: aapl.TickerID(), Status: &st} })
+	m.orderInputPanel.SetTicker(aapl)
+	if out := m.orderInputPanel.View(); !strings.Contains(out, "PRE-OPEN") {
+		t.Fatalf("expected a pre-open banner:\n%s", out)
+	}
+
+	// Liquidity for the market order to take at the open
+	if _, err := m.marketService.SubmitLimit(ctx, aapl.TickerID(), 1, core.SideSell, 100, 10); err != nil {
+		t.Fatal(err)
+	}
+	drain(m, func() tea.Msg {
+		return panels.QueueOrderMsg{Order: panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideBuy, OrderKind: core.OrderKindMarket, Quantity: 4}}
+	})
+
+	// The auction is not open yet
+	drain(m, func() tea.Msg {
+		return panels.MarketUpdateMsg{Ticker: aapl.TickerID(), Status: &marketview.StatusEvent{Status: market.StatusAuction}}
+	})
+	if m.queue.Len(aapl.TickerID()) != 1 {
+		t.Fatal("expected the order held through the auction")
+	}
+
+	if _, err := m.marketService.OpenSession(ctx, aapl.TickerID()); err != nil {
+		t.Fatal(err)
+	}
+	st, _ = m.marketService.GetTradingState(aapl.TickerID())
+	drain(m, func() tea.Msg { return panels.MarketUpdateMsg{Ticker: aapl.TickerID(), Status: &st} })
+	if !strings.Contains(m.View(), "Filled 4 @ 1.00") {
+		t.Errorf("expected the released market order to fill:\n%s", m.View())
+	}
+	if out := m.orderInputPanel.View(); strings.Contains(out, "PRE-OPEN") || strings.Contains(out, "queued") {
+		t.Errorf("expected the banner to clear once open:\n%s", out)
+	}
+}
+
+func TestDiscardQueuedOrders(t *testing.T) {
+	m := newBandedModel(t)
+	aapl := m.tickers[0]
+	ctx := context.Background()
+	if _, err := m.marketService.EmergencyStop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	drain(m, m.updateAllData())
+	drain(m, func() tea.Msg { return panels.QueueOrderMsg{Order: limitBuy(aapl, 100, 1)} })
+
+	// ctrl+d in the focused panel discards the selected ticker's queue
+	m.orderInputPanel.SetTicker(aapl)
+	m.Update(tea.KeyMsg{Type: tea.KeyF4})
+	m.View()
+	_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	drain(m, cmd)
+	if m.queue.Len(aapl.TickerID()) != 0 {
+		t.Fatal("expected the queue to be discarded")
+	}
+	if !strings.Contains(m.View(), "Discarded 1 queued order(s) for AAPL") {
+		t.Errorf("expected a discard confirmation:\n%s", m.View())
+	}
+
+	if _, err := m.marketService.OpenSession(ctx, aapl.TickerID()); err != nil {
+		t.Fatal(err)
+	}
+	drain(m, m.updateAllData())
+	if prices := restingBids(t, m, aapl.TickerID()); len(prices) != 0 {
+		t.Errorf("expected nothing submitted after discarding, got %v", prices)
+	}
+}
+
+func TestModelRejectsNonPositiveOrders(t *testing.T) {
+	m := newBandedModel(t)
+	aapl := m.tickers[0]
+	for _, tt := range []struct {
+		name string
+		msg  tea.Msg
+		want string
+	}{
+		{"zero quantity", limitBuy(aapl, 100, 0), "INVALID_SIZE"},
+		{"negative quantity", panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideSell, OrderKind: core.OrderKindMarket, Quantity: -3}, "INVALID_SIZE"},
+		{"zero price", limitBuy(aapl, 0, 5), "INVALID_PRICE"},
+		{"queued negative price", panels.QueueOrderMsg{Order: limitBuy(aapl, -100, 5)}, "INVALID_PRICE"},
+	} {
+		drain(m, func() tea.Msg { return tt.msg })
+		if out := m.View(); !strings.Contains(out, "Order rejected: "+tt.want) {
+			t.Errorf("%s: expected a %s rejection:\n%s", tt.name, tt.want, out)
+		}
+	}
+	if n := m.queue.Len(aapl.TickerID()); n != 0 {
+		t.Errorf("expected nothing queued, got %d", n)
+	}
+	if bids := restingBids(t, m, aapl.TickerID()); len(bids) != 0 {
+		t.Errorf("expected nothing sent, got bids %v", bids)
+	}
+}
@@ -0,0 +1,32 @@
+package panels
+
+// renderCache memoizes a panel's rendered string. Panels call invalidate
+// whenever something they draw changes (data setters, focus, key handling);
+// View reuses the last render while the version and size are unchanged.
+type renderCache struct {
+	version  uint64
+	rendered uint64 // version of out
+	width    int
+	height   int
+	out      string
+	ok       bool
+}
+
+// invalidate marks the cached render stale.
+func (c *renderCache) invalidate() {
+	c.version++
+}
+
+// get returns the cached render if it is current for the given size.
+func (c *renderCache) get(width, height int) (string, bool) {
+	if !c.ok || c.rendered != c.version || c.width != width || c.height != height {
+		return "", false
+	}
+	return c.out, true
+}
+
+// put stores a render for the current version and size and returns it.
+func (c *renderCache) put(width, height int, out string) string {
+	c.rendered, c.width, c.height, c.out, c.ok = c.version, width, height, out, true
+	return out
+}
@@ -3,13 +3,17 @@ package panels
 import (
 	"fmt"
 	"math"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/money"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/stats"
 	"github.com/zappabad/stockcraft/tui/styles"
 )
 
@@ -21,9 +25,20 @@ type Candle struct {
 	Close  core.PriceTicks
 	Volume core.Size
 	Time   int64
+	// Synthetic marks backfilled history that did not trade.
+	Synthetic bool
 }
 
-// CandlestickPanel displays a candlestick chart.
+// ChartMode selects what the chart panel plots.
+type ChartMode uint8
+
+const (
+	ChartModeCandles ChartMode = iota // OHLC candles from trades
+	ChartModeSpread                   // Mid line with the bid-ask spread as a band
+)
+
+// CandlestickPanel displays a candlestick chart, or the spread history in
+// ChartModeSpread.
 type CandlestickPanel struct {
 	ticker  market.Ticker
 	candles []Candle
@@ -39,13 +54,36 @@ type CandlestickPanel struct {
 
 	// Chart settings
 	maxCandles int
+	mode       ChartMode
+	// neutralDoji draws candles that close at their open in the doji style
+	// instead of as bullish
+	neutralDoji bool
+
+	// Best bid/ask history for the spread chart, oldest first
+	spread     []stats.BBOSample
+	spreadStep int64 // resampling cadence in nanoseconds
+
+	// Time of the newest trade added and the latest maxSameTimeTrades seen
+	// at that time, so trades replayed from the tape are not counted twice
+	lastTradeTime int64
+	atLastTrade   []core.TradeEvent
+
+	display money.Display
+	cache   renderCache
 }
 
+// maxSameTimeTrades bounds the trades AddTrade remembers at the newest
+// timestamp. It only needs to cover a replayed tape, which a clock that
+// stands still, as in tests, could otherwise grow without limit.
+const maxSameTimeTrades = 64
+
 // NewCandlestickPanel creates a new candlestick chart panel.
 func NewCandlestickPanel() *CandlestickPanel {
 	return &CandlestickPanel{
 		candlePeriod: 5e9, // 5 second candles
 		maxCandles:   50,
+		neutralDoji:  true,
+		spreadStep:   1e9, // 1 second spread samples
 	}
 }
 
@@ -55,17 +93,33 @@ func (p *CandlestickPanel) Init() tea.Cmd {
 }
 
 // Update handles messages for the panel.
-func (p *CandlestickPanel) Update(msg tea.Msg) (*CandlestickPanel, tea.Cmd) {
+func (p *CandlestickPanel) Update(msg tea.Msg) (Panel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if !p.focused {
+			return p, nil
+		}
+		if key.Matches(msg, key.NewBinding(key.WithKeys("m"))) {
+			p.CycleMode()
+		}
+	}
 	return p, nil
 }
 
-// View renders the panel.
+// View renders the panel, reusing the last render if nothing changed.
 func (p *CandlestickPanel) View() string {
-	tickerName := "No ticker"
-	if p.ticker.Name != "" {
-		tickerName = p.ticker.Name
+	if out, ok := p.cache.get(p.width, p.height); ok {
+		return out
 	}
+	return p.cache.put(p.width, p.height, p.render())
+}
 
+// Version returns the panel's content version.
+func (p *CandlestickPanel) Version() uint64 {
+	return p.cache.version
+}
+
+func (p *CandlestickPanel) render() string {
 	var content strings.Builder
 
 	// Calculate chart dimensions
@@ -75,13 +129,21 @@ func (p *CandlestickPanel) View() string {
 		chartHeight = 5
 	}
 
-	// Get all candles including current one being built
-	allCandles := p.getAllCandles()
-
-	if len(allCandles) == 0 {
-		content.WriteString(lipgloss.NewStyle().Foreground(styles.TextMutedColor).Render("No trading data yet..."))
+	if p.mode == ChartModeSpread {
+		if len(p.spread) == 0 {
+			content.WriteString(lipgloss.NewStyle().Foreground(styles.TextMutedColor).Render("No quotes yet..."))
+		} else {
+			content.WriteString(p.renderSpreadChart(chartWidth, chartHeight))
+		}
 	} else {
-		content.WriteString(p.renderChart(chartWidth, chartHeight, allCandles))
+		// Get all candles including current one being built
+		allCandles := p.getAllCandles()
+
+		if len(allCandles) == 0 {
+			content.WriteString(lipgloss.NewStyle().Foreground(styles.TextMutedColor).Render("No trading data yet..."))
+		} else {
+			content.WriteString(p.renderChart(chartWidth, chartHeight, allCandles))
+		}
 	}
 
 	// Apply panel styling
@@ -90,7 +152,7 @@ func (p *CandlestickPanel) View() string {
 		panelStyle = styles.FocusedPanelStyle
 	}
 
-	title := styles.RenderTitle(fmt.Sprintf("📉 Chart - %s", tickerName), p.focused)
+	title := styles.RenderTitle(p.Title(), p.focused)
 	panel := lipgloss.JoinVertical(lipgloss.Left, title, content.String())
 
 	return panelStyle.Width(p.width - 2).Height(p.height - 2).Render(panel)
@@ -168,22 +230,14 @@ func (p *CandlestickPanel) renderChart(width, height int, candles []Candle) stri
 	for row := 0; row < chartHeight; row++ {
 		// Price label
 		price := p.yToPrice(row, minPrice, maxPrice, chartHeight)
-		priceLabel := formatPrice(int64(price), p.ticker.Decimals)
+		priceLabel := p.display.FormatPrice(int64(price), p.ticker.Decimals)
 		result.WriteString(styles.ChartAxisStyle.Render(fmt.Sprintf("%8s │", priceLabel)))
 
 		// Render each candle column
 		for _, candle := range displayCandles {
 			char := p.getCandleChar(candle, row, minPrice, maxPrice, chartHeight)
 
-			// Apply color based on bullish/bearish
-			var style lipgloss.Style
-			if candle.Close >= candle.Open {
-				style = styles.CandleUpStyle
-			} else {
-				style = styles.CandleDownStyle
-			}
-
-			result.WriteString(style.Render(string(char)))
+			result.WriteString(p.candleStyle(candle).Render(string(char)))
 			result.WriteString(" ") // Space between candles
 		}
 		result.WriteString("\n")
@@ -212,6 +266,26 @@ func (p *CandlestickPanel) renderChart(width, height int, candles []Candle) stri
 	return result.String()
 }
 
+// isDoji reports whether a candle is drawn as a doji.
+func (p *CandlestickPanel) isDoji(c Candle) bool {
+	return p.neutralDoji && c.Close == c.Open
+}
+
+// candleStyle colors a candle bullish, bearish or, for a doji, neutral.
+// Synthetic candles are drawn muted.
+func (p *CandlestickPanel) candleStyle(c Candle) lipgloss.Style {
+	switch {
+	case c.Synthetic:
+		return styles.CandleSyntheticStyle
+	case p.isDoji(c):
+		return styles.CandleDojiStyle
+	case c.Close >= c.Open:
+		return styles.CandleUpStyle
+	default:
+		return styles.CandleDownStyle
+	}
+}
+
 // getCandleChar returns the character to draw for a candle at a given row
 func (p *CandlestickPanel) getCandleChar(candle Candle, row int, minPrice, maxPrice core.PriceTicks, height int) rune {
 	// Convert row to price level
@@ -237,10 +311,10 @@ func (p *CandlestickPanel) getCandleChar(candle Candle, row int, minPrice, maxPr
 
 	// Check body first (body overwrites wick)
 	if rowPrice <= bodyTop+tolerance && rowPrice >= bodyBottom-tolerance {
-		if candle.Close >= candle.Open {
-			return '┃' // Bullish body (thick)
+		if p.isDoji(candle) {
+			return '┼' // Doji: open and close cross the wick
 		}
-		return '┃' // Bearish body (same char, different color)
+		return '┃' // Bullish and bearish bodies differ only in color
 	}
 
 	// Check upper wick (above body)
@@ -256,6 +330,138 @@ func (p *CandlestickPanel) getCandleChar(candle Candle, row int, minPrice, maxPr
 	return ' ' // Empty space
 }
 
+// spreadColumn is one resampled point of the spread chart.
+type spreadColumn struct {
+	time   int64
+	sample stats.BBOSample
+	ok     bool // a two-sided quote was known at this time
+}
+
+// resampleSpread steps the spread history at the fixed cadence, holding the
+// last known quote, and returns the newest n columns ending at the last sample.
+func (p *CandlestickPanel) resampleSpread(n int) []spreadColumn {
+	if n < 1 || len(p.spread) == 0 {
+		return nil
+	}
+	end := (p.spread[len(p.spread)-1].Time / p.spreadStep) * p.spreadStep
+	start := end - int64(n-1)*p.spreadStep
+
+	cols := make([]spreadColumn, n)
+	j := 0
+	var last stats.BBOSample
+	have := false
+	for i := range cols {
+		t := start + int64(i)*p.spreadStep
+		for j < len(p.spread) && p.spread[j].Time < t+p.spreadStep {
+			last = p.spread[j]
+			have = true
+			j++
+		}
+		cols[i] = spreadColumn{time: t, sample: last, ok: have && last.BidOK && last.AskOK}
+	}
+
+	// Drop leading columns from before the first quote
+	for len(cols) > 0 && cols[0].time+p.spreadStep <= p.spread[0].Time {
+		cols = cols[1:]
+	}
+	return cols
+}
+
+func (p *CandlestickPanel) renderSpreadChart(width, height int) string {
+	// Same geometry as the candle chart: 9 chars for price axis, 1 for separator,
+	// then 2 chars per column
+	chartWidth := width - 10
+	if chartWidth < 10 {
+		chartWidth = 10
+	}
+	cols := p.resampleSpread(chartWidth / 2)
+
+	// Find price range
+	var minPrice, maxPrice core.PriceTicks
+	found := false
+	for _, c := range cols {
+		if !c.ok {
+			continue
+		}
+		if !found || c.sample.BidPrice < minPrice {
+			minPrice = c.sample.BidPrice
+		}
+		if !found || c.sample.AskPrice > maxPrice {
+			maxPrice = c.sample.AskPrice
+		}
+		found = true
+	}
+	if !found {
+		return lipgloss.NewStyle().Foreground(styles.TextMutedColor).Render("No two-sided quotes yet...")
+	}
+
+	// Add padding to price range (10%)
+	priceRange := maxPrice - minPrice
+	if priceRange == 0 {
+		priceRange = 100 // Minimum range
+	}
+	padding := core.PriceTicks(float64(priceRange) * 0.1)
+	if padding < 1 {
+		padding = 1
+	}
+	minPrice -= padding
+	maxPrice += padding
+
+	// Reserve 2 rows for time axis
+	chartHeight := height - 3
+	if chartHeight < 5 {
+		chartHeight = 5
+	}
+
+	var result strings.Builder
+
+	for row := 0; row < chartHeight; row++ {
+		price := p.yToPrice(row, minPrice, maxPrice, chartHeight)
+		priceLabel := p.display.FormatPrice(int64(price), p.ticker.Decimals)
+		result.WriteString(styles.ChartAxisStyle.Render(fmt.Sprintf("%8s │", priceLabel)))
+
+		for _, c := range cols {
+			if !c.ok {
+				result.WriteString("  ")
+				continue
+			}
+			mid, _ := c.sample.Mid()
+			askY := p.priceToY(c.sample.AskPrice, minPrice, maxPrice, chartHeight)
+			bidY := p.priceToY(c.sample.BidPrice, minPrice, maxPrice, chartHeight)
+			midY := p.priceToY(mid, minPrice, maxPrice, chartHeight)
+			switch {
+			case row == midY:
+				result.WriteString(styles.SpreadMidStyle.Render("──"))
+			case row >= askY && row <= bidY:
+				result.WriteString(styles.SpreadBandStyle.Render("░░"))
+			default:
+				result.WriteString("  ")
+			}
+		}
+		result.WriteString("\n")
+	}
+
+	// Bottom border
+	result.WriteString(styles.ChartAxisStyle.Render("─────────┴"))
+	for range cols {
+		result.WriteString(styles.ChartAxisStyle.Render("──"))
+	}
+	result.WriteString("\n")
+
+	// Time axis - seconds of the sample time
+	result.WriteString(styles.ChartAxisStyle.Render("          "))
+	for i, c := range cols {
+		if i == 0 || i == len(cols)-1 || i%5 == 0 {
+			timeStr := time.Unix(0, c.time).UTC().Format("05")
+			result.WriteString(styles.ChartLabelStyle.Render(timeStr))
+		} else {
+			result.WriteString("  ")
+		}
+	}
+
+	return result.String()
+}
+
 func (p *CandlestickPanel) priceToY(price, minPrice, maxPrice core.PriceTicks, height int) int {
 	if maxPrice == minPrice {
 		return height / 2
@@ -279,8 +485,31 @@ func (p *CandlestickPanel) yToPrice(y int, minPrice, maxPrice core.PriceTicks, h
 	return maxPrice - core.PriceTicks(ratio*float64(maxPrice-minPrice))
 }
 
+// Title returns the panel title, including the charted ticker.
+func (p *CandlestickPanel) Title() string {
+	tickerName := "No ticker"
+	if p.ticker.Name != "" {
+		tickerName = p.ticker.Name
+	}
+	if p.mode == ChartModeSpread {
+		return fmt.Sprintf("📉 Spread - %s", tickerName)
+	}
+	if len(p.candles) > 0 && p.candles[0].Synthetic {
+		return fmt.Sprintf("📉 Chart - %s (synthetic history)", tickerName)
+	}
+	return fmt.Sprintf("📉 Chart - %s", tickerName)
+}
+
+// DefaultKey returns the key that focuses the panel.
+func (p *CandlestickPanel) DefaultKey() string {
+	return "f5"
+}
+
 // SetFocus sets the focus state of the panel.
 func (p *CandlestickPanel) SetFocus(focused bool) {
+	if focused != p.focused {
+		p.cache.invalidate()
+	}
 	p.focused = focused
 }
 
@@ -290,15 +519,71 @@ func (p *CandlestickPanel) SetSize(width, height int) {
 	p.height = height
 }
 
+// SetPriceDisplay sets how prices are shown.
+func (p *CandlestickPanel) SetPriceDisplay(d money.Display) {
+	p.cache.invalidate()
+	p.display = d
+}
+
+// SetNeutralDoji sets whether candles that close at their open are drawn as
+// neutral dojis (the default) or as bullish candles.
+func (p *CandlestickPanel) SetNeutralDoji(neutral bool) {
+	p.neutralDoji = neutral
+	p.cache.invalidate()
+}
+
 // SetTicker sets the ticker to chart.
 func (p *CandlestickPanel) SetTicker(ticker market.Ticker) {
 	p.ticker = ticker
 	p.candles = nil
 	p.currentCandle = nil
+	p.spread = nil
+	p.lastTradeTime = 0
+	p.atLastTrade = nil
+	p.cache.invalidate()
+}
+
+// Mode returns the current chart mode.
+func (p *CandlestickPanel) Mode() ChartMode {
+	return p.mode
+}
+
+// CycleMode switches to the next chart mode.
+func (p *CandlestickPanel) CycleMode() {
+	p.cache.invalidate()
+	if p.mode == ChartModeCandles {
+		p.mode = ChartModeSpread
+	} else {
+		p.mode = ChartModeCandles
+	}
+}
+
+// SetSpreadHistory sets the best bid/ask samples plotted in ChartModeSpread,
+// oldest first.
+func (p *CandlestickPanel) SetSpreadHistory(samples []stats.BBOSample) {
+	if slices.Equal(p.spread, samples) {
+		return
+	}
+	p.cache.invalidate()
+	p.spread = samples
 }
 
-// AddTrade processes a trade and updates the candlestick data.
+// AddTrade processes a trade and updates the candlestick data. Trades older
+// than the newest one already added, or repeats of it, are ignored.
 func (p *CandlestickPanel) AddTrade(trade core.TradeEvent) {
+	if trade.Time < p.lastTradeTime || (trade.Time == p.lastTradeTime && slices.Contains(p.atLastTrade, trade)) {
+		return
+	}
+	if trade.Time > p.lastTradeTime {
+		p.lastTradeTime = trade.Time
+		p.atLastTrade = p.atLastTrade[:0]
+	}
+	if len(p.atLastTrade) == maxSameTimeTrades {
+		p.atLastTrade = append(p.atLastTrade[:0], p.atLastTrade[1:]...)
+	}
+	p.atLastTrade = append(p.atLastTrade, trade)
+	p.cache.invalidate()
+
 	// Check if we need to start a new candle
 	candleStart := (trade.Time / p.candlePeriod) * p.candlePeriod
 
@@ -335,13 +620,94 @@ func (p *CandlestickPanel) AddTrade(trade core.TradeEvent) {
 	}
 }
 
+// LoadCandles replaces the chart's candles with history from the market,
+// oldest first, the last being the candle still forming. seen are the
+// tape's latest trades, which the candles already count, so replaying them
+// through AddTrade does not count them twice.
+func (p *CandlestickPanel) LoadCandles(candles []Candle, seen []core.TradeEvent) {
+	p.cache.invalidate()
+	p.candles, p.currentCandle = nil, nil
+	p.lastTradeTime, p.atLastTrade = 0, nil
+	if len(candles) == 0 {
+		return
+	}
+	current := candles[len(candles)-1]
+	p.currentCandle = &current
+	p.candleStart = current.Time
+	done := candles[:len(candles)-1]
+	p.candles = slices.Clone(done[len(done)-min(len(done), p.maxCandles):])
+	for _, tr := range seen {
+		if tr.Time > p.lastTradeTime {
+			p.lastTradeTime = tr.Time
+			p.atLastTrade = p.atLastTrade[:0]
+		}
+		if tr.Time == p.lastTradeTime {
+			p.atLastTrade = append(p.atLastTrade, tr)
+		}
+	}
+}
+
+// Period returns the width of each candle.
+func (p *CandlestickPanel) Period() time.Duration {
+	return time.Duration(p.candlePeriod)
+}
+
+// MaxCandles returns how many finished candles the chart keeps.
+func (p *CandlestickPanel) MaxCandles() int {
+	return p.maxCandles
+}
+
 // SetCandles sets the candle data directly.
 func (p *CandlestickPanel) SetCandles(candles []Candle) {
+	p.cache.invalidate()
 	p.candles = candles
 }
 
+// Backfill seeds an empty chart with n synthetic candles so it is not blank
+// before trading starts. The candles end in the period before the one
+// holding end, the last closing at ref, and walk back from it with step
+// drawing each candle's move; they have no volume and are marked Synthetic.
+// It does nothing once the chart has candles.
+func (p *CandlestickPanel) Backfill(ref core.PriceTicks, end int64, n int, step func() core.PriceTicks) {
+	if n <= 0 || ref <= 0 || len(p.candles) > 0 || p.currentCandle != nil {
+		return
+	}
+	p.cache.invalidate()
+	n = min(n, p.maxCandles)
+	p.candles = syntheticCandles(ref, (end/p.candlePeriod-1)*p.candlePeriod, p.candlePeriod, n, step)
+}
+
+// syntheticCandles returns n candles, the last starting at last and closing
+// at ref, each opening where the one before it closed.
+func syntheticCandles(ref core.PriceTicks, last, period int64, n int, step func() core.PriceTicks) []Candle {
+	candles := make([]Candle, n)
+	closePrice := ref
+	for i := n - 1; i >= 0; i-- {
+		open := max(closePrice-step(), 1)
+		wick := abs(step()) / 2
+		candles[i] = Candle{
+			Open:      open,
+			High:      max(open, closePrice) + wick,
+			Low:       max(min(open, closePrice)-wick, 1),
+			Close:     closePrice,
+			Time:      last - int64(n-1-i)*period,
+			Synthetic: true,
+		}
+		closePrice = open
+	}
+	return candles
+}
+
+func abs(p core.PriceTicks) core.PriceTicks {
+	if p < 0 {
+		return -p
+	}
+	return p
+}
+
 // GenerateSampleCandles generates sample candle data for testing.
 func (p *CandlestickPanel) GenerateSampleCandles(basePrice int64, count int) {
+	p.cache.invalidate()
 	p.candles = nil
 	price := float64(basePrice)
 
@@ -0,0 +1,215 @@
+package panels
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/internal/stats"
+	"github.com/zappabad/stockcraft/tui/styles"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading golden file: %v (run with -update to create)", err)
+	}
+	if got != string(want) {
+		t.Errorf("render mismatch for %s\n--- got ---\n%s\n--- want ---\n%s", name, got, want)
+	}
+}
+
+func TestSpreadChartGoldenRender(t *testing.T) {
+	p := NewCandlestickPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(60, 20)
+	p.SetFocus(true)
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if p.Mode() != ChartModeSpread {
+		t.Fatalf("expected spread mode after 'm', got %d", p.Mode())
+	}
+
+	// A quote that widens, shifts up, then tightens; the gap at 4s-6s is held
+	// at the last known quote.
+	sec := int64(1e9)
+	quote := func(t int64, bid, ask core.PriceTicks) stats.BBOSample {
+		return stats.BBOSample{Time: t * sec, BidPrice: bid, BidOK: true, AskPrice: ask, AskOK: true}
+	}
+	p.SetSpreadHistory([]stats.BBOSample{
+		quote(0, 10000, 10010),
+		quote(1, 9995, 10015),
+		quote(2, 9990, 10020),
+		quote(3, 10000, 10030),
+		quote(7, 10010, 10030),
+		quote(8, 10015, 10025),
+		quote(9, 10018, 10022),
+	})
+	assertGolden(t, "spread_chart", p.View())
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	if p.Mode() != ChartModeCandles {
+		t.Errorf("expected candle mode after second 'm', got %d", p.Mode())
+	}
+}
+
+func TestCandleChartDrawsNeutralDoji(t *testing.T) {
+	p := NewCandlestickPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(40, 16)
+	up := Candle{Open: 10000, High: 10040, Low: 9990, Close: 10030}
+	doji := Candle{Open: 10020, High: 10050, Low: 9980, Close: 10020}
+	down := Candle{Open: 10030, High: 10035, Low: 9985, Close: 10000}
+	p.SetCandles([]Candle{up, doji, down})
+
+	for _, tt := range []struct {
+		candle Candle
+		color  lipgloss.TerminalColor
+	}{{up, styles.BuyColor}, {doji, styles.NeutralColor}, {down, styles.SellColor}} {
+		if got := p.candleStyle(tt.candle).GetForeground(); got != tt.color {
+			t.Errorf("%+v: expected color %v, got %v", tt.candle, tt.color, got)
+		}
+	}
+	view := p.View()
+	if !strings.Contains(view, "┼") {
+		t.Fatalf("expected the doji's body drawn as ┼:\n%s", view)
+	}
+	if !strings.Contains(view, styles.CandleDojiStyle.Render("┼")) {
+		t.Errorf("expected the doji body in the neutral style:\n%s", view)
+	}
+
+	// Turned off, a doji is a bullish candle again
+	p.SetNeutralDoji(false)
+	if got := p.candleStyle(doji).GetForeground(); got != styles.BuyColor {
+		t.Errorf("expected a bullish doji, got %v", got)
+	}
+	if view := p.View(); strings.Contains(view, "┼") {
+		t.Errorf("expected no doji marker when off:\n%s", view)
+	}
+}
+
+func TestCandleChartBackfill(t *testing.T) {
+	p := NewCandlestickPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(60, 20)
+
+	// Each candle draws a move then a wick: moves alternate ±12 ticks with
+	// wicks of 2
+	draws := []core.PriceTicks{12, 4, -12, 4}
+	var n int
+	step := func() core.PriceTicks {
+		n++
+		return draws[(n-1)%len(draws)]
+	}
+	end := int64(1_000 * 5e9)
+	p.Backfill(17500, end+3e9, 30, step)
+
+	candles := p.getAllCandles()
+	if len(candles) != 30 {
+		t.Fatalf("expected 30 candles, got %d", len(candles))
+	}
+	last := candles[len(candles)-1]
+	if last.Close != 17500 {
+		t.Errorf("expected the last candle to close at the reference 17500, got %d", last.Close)
+	}
+	if last.Time != end-5e9 {
+		t.Errorf("expected the last candle in the period before the end, got %d", last.Time)
+	}
+	for i, c := range candles {
+		if !c.Synthetic || c.Volume != 0 {
+			t.Errorf("candle %d: expected synthetic with no volume, got %+v", i, c)
+		}
+		if c.Low > min(c.Open, c.Close) || c.High < max(c.Open, c.Close) {
+			t.Errorf("candle %d: expected the wicks to span the body, got %+v", i, c)
+		}
+		if i > 0 && (c.Open != candles[i-1].Close || c.Time != candles[i-1].Time+5e9) {
+			t.Errorf("candle %d: expected it to open at the previous close one period on, got %+v after %+v", i, c, candles[i-1])
+		}
+		if d := c.Close - 17500; d < -12 || d > 12 {
+			t.Errorf("candle %d: expected it to stay near the reference, got %+v", i, c)
+		}
+	}
+	if got := p.Title(); !strings.Contains(got, "synthetic") {
+		t.Errorf("expected the title to mark the history synthetic, got %q", got)
+	}
+	if got := p.candleStyle(last).GetForeground(); got != styles.TextMutedColor {
+		t.Errorf("expected synthetic candles muted, got %v", got)
+	}
+
+	// Trades follow the history; a chart that has candles is not refilled
+	p.AddTrade(core.TradeEvent{Price: 17510, Size: 5, Time: end + 3e9})
+	if candles := p.getAllCandles(); len(candles) != 31 || candles[30].Synthetic {
+		t.Errorf("expected a real candle after the history, got %d candles", len(candles))
+	}
+	p.Backfill(17000, end+10e9, 5, step)
+	if candles := p.getAllCandles(); len(candles) != 31 {
+		t.Errorf("expected Backfill to leave a charted ticker alone, got %d candles", len(candles))
+	}
+
+	p.SetTicker(market.Ticker{ID: 2, Name: "MSFT", Decimals: 2})
+	if got := p.Title(); strings.Contains(got, "synthetic") {
+		t.Errorf("expected a new ticker to start without history, got %q", got)
+	}
+}
+
+func TestCandleChartLoadCandles(t *testing.T) {
+	p := NewCandlestickPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.AddTrade(core.TradeEvent{Price: 1, Size: 1, Time: 1})
+
+	seen := []core.TradeEvent{
+		{Price: 101, Size: 2, Time: 10e9, MakerOrderID: 1},
+		{Price: 102, Size: 3, Time: 10e9, MakerOrderID: 2},
+	}
+	p.LoadCandles([]Candle{
+		{Open: 100, High: 100, Low: 100, Close: 100, Volume: 4, Time: 0},
+		{Open: 101, High: 102, Low: 101, Close: 102, Volume: 5, Time: 10e9},
+	}, seen)
+
+	// Replaying the tape does not count its trades again
+	for _, tr := range seen {
+		p.AddTrade(tr)
+	}
+	p.AddTrade(core.TradeEvent{Price: 103, Size: 1, Time: 11e9})
+
+	candles := p.getAllCandles()
+	if len(candles) != 2 || candles[0].Close != 100 {
+		t.Fatalf("expected the loaded history to replace the chart, got %+v", candles)
+	}
+	if c := candles[1]; c.Volume != 6 || c.High != 103 || c.Close != 103 {
+		t.Errorf("expected only the new trade added to the forming candle, got %+v", c)
+	}
+}
+
+func TestCandleChartBoundsSameTimeTrades(t *testing.T) {
+	p := NewCandlestickPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+
+	// A clock that stands still stamps every trade alike
+	for i := range 1000 {
+		p.AddTrade(core.TradeEvent{Price: 100, Size: 1, Time: 1, MakerOrderID: core.OrderID(i + 1)})
+	}
+	if n := len(p.atLastTrade); n != maxSameTimeTrades {
+		t.Errorf("expected %d trades remembered at the newest time, got %d", maxSameTimeTrades, n)
+	}
+	if c := p.getAllCandles(); len(c) != 1 || c[0].Volume != 1000 {
+		t.Errorf("expected every trade counted once, got %+v", c)
+	}
+}
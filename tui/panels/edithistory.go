@@ -0,0 +1,53 @@
+package panels
+
+// editHistorySize bounds how many field edits Ctrl+Z can undo.
+const editHistorySize = 10
+
+// fieldEdit is a text field's value before an edit.
+type fieldEdit struct {
+	field  OrderInputField
+	before string
+	typing bool // a run of typed characters
+}
+
+// editHistory is a bounded stack of field edits, newest last. A run of
+// typed characters in one field is a single edit, so undo restores the
+// value from before the run rather than one character at a time.
+type editHistory struct {
+	edits []fieldEdit
+	// sealed stops the next typed character from joining the last run,
+	// e.g. after an undo
+	sealed bool
+}
+
+// record notes that field held before until an edit changed it.
+func (h *editHistory) record(field OrderInputField, before string, typing bool) {
+	if n := len(h.edits); typing && !h.sealed && n > 0 {
+		if last := h.edits[n-1]; last.typing && last.field == field {
+			return
+		}
+	}
+	h.sealed = false
+	if len(h.edits) == editHistorySize {
+		h.edits = append(h.edits[:0], h.edits[1:]...)
+	}
+	h.edits = append(h.edits, fieldEdit{field: field, before: before, typing: typing})
+}
+
+// undo removes the newest edit of field and returns the value it replaced.
+func (h *editHistory) undo(field OrderInputField) (string, bool) {
+	for i := len(h.edits) - 1; i >= 0; i-- {
+		if h.edits[i].field == field {
+			before := h.edits[i].before
+			h.edits = append(h.edits[:i], h.edits[i+1:]...)
+			h.sealed = true
+			return before, true
+		}
+	}
+	return "", false
+}
+
+func (h *editHistory) clear() {
+	h.edits = h.edits[:0]
+	h.sealed = false
+}
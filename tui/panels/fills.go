@@ -0,0 +1,174 @@
+package panels
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/tui/styles"
+)
+
+// FillsPanel displays the player's own fills for the selected ticker,
+// newest first.
+type FillsPanel struct {
+	ticker       market.Ticker
+	fills        []marketview.Fill
+	scrollOffset int
+	focused      bool
+	width        int
+	height       int
+	display      money.Display
+	cache        renderCache
+}
+
+// NewFillsPanel creates a new fills panel.
+func NewFillsPanel() *FillsPanel {
+	return &FillsPanel{}
+}
+
+// Init initializes the panel.
+func (p *FillsPanel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the panel.
+func (p *FillsPanel) Update(msg tea.Msg) (Panel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if !p.focused {
+			return p, nil
+		}
+		p.cache.invalidate()
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if p.scrollOffset > 0 {
+				p.scrollOffset--
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if p.scrollOffset < len(p.fills)-1 {
+				p.scrollOffset++
+			}
+		}
+	}
+	return p, nil
+}
+
+// View renders the panel, reusing the last render if nothing changed.
+func (p *FillsPanel) View() string {
+	if out, ok := p.cache.get(p.width, p.height); ok {
+		return out
+	}
+	return p.cache.put(p.width, p.height, p.render())
+}
+
+// Version returns the panel's content version.
+func (p *FillsPanel) Version() uint64 {
+	return p.cache.version
+}
+
+func (p *FillsPanel) render() string {
+	var content strings.Builder
+
+	if len(p.fills) == 0 {
+		content.WriteString(lipgloss.NewStyle().Foreground(styles.TextMutedColor).Render("No fills yet"))
+	} else {
+		header := fmt.Sprintf("%-8s %-4s %8s %6s %-5s", "Time", "Side", "Price", "Size", "Role")
+		content.WriteString(styles.HeaderStyle.Render(header))
+
+		visible := p.height - 5
+		if visible < 1 {
+			visible = 1
+		}
+
+		// Newest first
+		for i := p.scrollOffset; i < len(p.fills) && i < p.scrollOffset+visible; i++ {
+			f := p.fills[len(p.fills)-1-i]
+
+			sideStyle := styles.BuyStyle
+			if f.Side == core.SideSell {
+				sideStyle = styles.SellStyle
+			}
+			line := fmt.Sprintf("%-8s %s %8s %6d %-5s",
+				time.Unix(0, f.Time).Format("15:04:05"),
+				sideStyle.Render(fmt.Sprintf("%-4s", f.Side)),
+				p.display.FormatPrice(int64(f.Price), p.ticker.Decimals),
+				f.Size,
+				f.Role,
+			)
+			content.WriteString("\n")
+			content.WriteString(line)
+		}
+	}
+
+	// Apply panel styling
+	panelStyle := styles.PanelStyle
+	if p.focused {
+		panelStyle = styles.FocusedPanelStyle
+	}
+
+	title := styles.RenderTitle(p.Title(), p.focused)
+	panel := lipgloss.JoinVertical(lipgloss.Left, title, content.String())
+
+	return panelStyle.Width(p.width - 2).Height(p.height - 2).Render(panel)
+}
+
+// Title returns the panel title.
+func (p *FillsPanel) Title() string {
+	if p.ticker.Name == "" {
+		return "🧾 My Fills"
+	}
+	return fmt.Sprintf("🧾 My Fills - %s", p.ticker.Name)
+}
+
+// DefaultKey returns the key that focuses the panel.
+func (p *FillsPanel) DefaultKey() string {
+	return "f6"
+}
+
+// SetFocus sets the focus state of the panel.
+func (p *FillsPanel) SetFocus(focused bool) {
+	if focused != p.focused {
+		p.cache.invalidate()
+	}
+	p.focused = focused
+}
+
+// SetSize sets the panel dimensions.
+func (p *FillsPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetPriceDisplay sets how prices are shown.
+func (p *FillsPanel) SetPriceDisplay(d money.Display) {
+	p.cache.invalidate()
+	p.display = d
+}
+
+// SetTicker sets the ticker whose fills are shown.
+func (p *FillsPanel) SetTicker(ticker market.Ticker) {
+	p.cache.invalidate()
+	p.ticker = ticker
+	p.fills = nil
+	p.scrollOffset = 0
+}
+
+// SetFills sets the player's fills, oldest first.
+func (p *FillsPanel) SetFills(fills []marketview.Fill) {
+	if slices.Equal(p.fills, fills) {
+		return
+	}
+	p.cache.invalidate()
+	p.fills = fills
+	if p.scrollOffset >= len(p.fills) {
+		p.scrollOffset = 0
+	}
+}
@@ -0,0 +1,44 @@
+package panels
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestFillsPanelShowsNewestFirstWithRole(t *testing.T) {
+	p := NewFillsPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(50, 12)
+
+	p.SetFills([]marketview.Fill{
+		{Side: core.SideSell, Role: marketview.RoleMaker, Price: 10050, Size: 7},
+		{Side: core.SideBuy, Role: marketview.RoleTaker, Price: 10125, Size: 3},
+	})
+	out := p.View()
+
+	taker := strings.Index(out, "101.25")
+	maker := strings.Index(out, "100.50")
+	if taker < 0 || maker < 0 {
+		t.Fatalf("expected both fills in the panel:\n%s", out)
+	}
+	if taker > maker {
+		t.Errorf("expected the newest fill first:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "101.25") && !strings.Contains(line, "TAKER") {
+			t.Errorf("expected taker role on the buy fill: %q", line)
+		}
+		if strings.Contains(line, "100.50") && !strings.Contains(line, "MAKER") {
+			t.Errorf("expected maker role on the sell fill: %q", line)
+		}
+	}
+
+	p.SetTicker(market.Ticker{ID: 2, Name: "GOOGL", Decimals: 2})
+	if out := p.View(); strings.Contains(out, "101.25") {
+		t.Errorf("expected fills to clear on ticker change:\n%s", out)
+	}
+}
@@ -3,12 +3,14 @@ package panels
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/zappabad/stockcraft/internal/market"
 	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 	"github.com/zappabad/stockcraft/tui/styles"
 )
@@ -21,13 +23,27 @@ type MarketOverviewPanel struct {
 	focused       bool
 	width         int
 	height        int
+	cache         renderCache
+
+	// Rows without a trade or book change in staleAfter before now are
+	// dimmed (0 = never)
+	staleAfter time.Duration
+	now        int64
+
+	display  money.Display
+	sizeUnit SizeUnit
 }
 
+// DefaultStaleAfter is how long a ticker can go without updating before its
+// row is dimmed.
+const DefaultStaleAfter = 10 * time.Second
+
 // NewMarketOverviewPanel creates a new market overview panel.
 func NewMarketOverviewPanel(tickers []market.Ticker) *MarketOverviewPanel {
 	return &MarketOverviewPanel{
 		tickers:      tickers,
 		tickerPrices: make(map[market.TickerID]marketview.BestPrices),
+		staleAfter:   DefaultStaleAfter,
 	}
 }
 
@@ -37,12 +53,13 @@ func (p *MarketOverviewPanel) Init() tea.Cmd {
 }
 
 // Update handles messages for the panel.
-func (p *MarketOverviewPanel) Update(msg tea.Msg) (*MarketOverviewPanel, tea.Cmd) {
+func (p *MarketOverviewPanel) Update(msg tea.Msg) (Panel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if !p.focused {
 			return p, nil
 		}
+		p.cache.invalidate()
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
 			if p.selectedIndex > 0 {
@@ -57,8 +74,20 @@ func (p *MarketOverviewPanel) Update(msg tea.Msg) (*MarketOverviewPanel, tea.Cmd
 	return p, nil
 }
 
-// View renders the panel.
+// View renders the panel, reusing the last render if nothing changed.
 func (p *MarketOverviewPanel) View() string {
+	if out, ok := p.cache.get(p.width, p.height); ok {
+		return out
+	}
+	return p.cache.put(p.width, p.height, p.render())
+}
+
+// Version returns the panel's content version.
+func (p *MarketOverviewPanel) Version() uint64 {
+	return p.cache.version
+}
+
+func (p *MarketOverviewPanel) render() string {
 	var content strings.Builder
 
 	// Header
@@ -78,20 +107,28 @@ func (p *MarketOverviewPanel) View() string {
 		askSize := "-"
 
 		if prices.BidOK {
-			bidPrice = formatPrice(int64(prices.BidPrice), ticker.Decimals)
-			bidSize = fmt.Sprintf("%d", prices.BidSize)
+			bidPrice = p.display.FormatPrice(int64(prices.BidPrice), ticker.Decimals)
+			bidSize = p.sizeUnit.FormatSize(ticker, prices.BidPrice, prices.BidSize)
 		}
 		if prices.AskOK {
-			askPrice = formatPrice(int64(prices.AskPrice), ticker.Decimals)
-			askSize = fmt.Sprintf("%d", prices.AskSize)
+			askPrice = p.display.FormatPrice(int64(prices.AskPrice), ticker.Decimals)
+			askSize = p.sizeUnit.FormatSize(ticker, prices.AskPrice, prices.AskSize)
 		}
 
 		row := fmt.Sprintf("%-8s %10s %10s %10s %10s",
 			ticker.Name, bidPrice, bidSize, askPrice, askSize)
+		if prices.Status != market.StatusOpen {
+			row += "  " + prices.Status.String()
+		}
 
 		style := styles.RowStyle
-		if i == p.selectedIndex && p.focused {
+		switch {
+		case i == p.selectedIndex && p.focused:
 			style = styles.SelectedRowStyle
+		case prices.Status != market.StatusOpen:
+			style = styles.NotTradingRowStyle
+		case p.stale(prices, p.now):
+			style = styles.StaleRowStyle
 		}
 		content.WriteString(style.Render(row))
 		if i < len(p.tickers)-1 {
@@ -105,14 +142,27 @@ func (p *MarketOverviewPanel) View() string {
 		panelStyle = styles.FocusedPanelStyle
 	}
 
-	title := styles.RenderTitle("📈 Market Overview", p.focused)
+	title := styles.RenderTitle(p.Title(), p.focused)
 	panel := lipgloss.JoinVertical(lipgloss.Left, title, content.String())
 
 	return panelStyle.Width(p.width - 2).Height(p.height - 2).Render(panel)
 }
 
+// Title returns the panel title.
+func (p *MarketOverviewPanel) Title() string {
+	return "📈 Market Overview"
+}
+
+// DefaultKey returns the key that focuses the panel.
+func (p *MarketOverviewPanel) DefaultKey() string {
+	return "f1"
+}
+
 // SetFocus sets the focus state of the panel.
 func (p *MarketOverviewPanel) SetFocus(focused bool) {
+	if focused != p.focused {
+		p.cache.invalidate()
+	}
 	p.focused = focused
 }
 
@@ -124,16 +174,60 @@ func (p *MarketOverviewPanel) SetSize(width, height int) {
 
 // UpdatePrices updates the prices for a given ticker.
 func (p *MarketOverviewPanel) UpdatePrices(tid market.TickerID, prices marketview.BestPrices) {
+	if old, ok := p.tickerPrices[tid]; ok && old == prices {
+		return
+	}
 	p.tickerPrices[tid] = prices
+	p.cache.invalidate()
 }
 
 // SetSnapshot sets all ticker prices from a market snapshot.
 func (p *MarketOverviewPanel) SetSnapshot(snap marketview.MarketSnapshot) {
 	for tid, prices := range snap.ByTicker {
-		p.tickerPrices[tid] = prices
+		p.UpdatePrices(tid, prices)
 	}
 }
 
+// SetPriceDisplay sets how prices are shown.
+func (p *MarketOverviewPanel) SetPriceDisplay(d money.Display) {
+	p.cache.invalidate()
+	p.display = d
+}
+
+// SetSizeUnit sets how bid and ask sizes are shown.
+func (p *MarketOverviewPanel) SetSizeUnit(u SizeUnit) {
+	p.cache.invalidate()
+	p.sizeUnit = u
+}
+
+// SetStaleAfter sets how long a ticker can go without a trade or book
+// change before its row is dimmed. 0 never dims.
+func (p *MarketOverviewPanel) SetStaleAfter(d time.Duration) {
+	p.staleAfter = d
+	p.cache.invalidate()
+}
+
+// SetNow moves the panel's clock (Unix nanoseconds, the event time base),
+// re-rendering only if a row becomes stale or fresh.
+func (p *MarketOverviewPanel) SetNow(now int64) {
+	for _, prices := range p.tickerPrices {
+		if p.stale(prices, now) != p.stale(prices, p.now) {
+			p.cache.invalidate()
+			break
+		}
+	}
+	p.now = now
+}
+
+// Stale reports whether a ticker's row is dimmed.
+func (p *MarketOverviewPanel) Stale(tid market.TickerID) bool {
+	return p.stale(p.tickerPrices[tid], p.now)
+}
+
+func (p *MarketOverviewPanel) stale(prices marketview.BestPrices, now int64) bool {
+	return p.staleAfter > 0 && now > 0 && now-prices.LastUpdateTime > int64(p.staleAfter)
+}
+
 // SelectedTicker returns the currently selected ticker.
 func (p *MarketOverviewPanel) SelectedTicker() market.Ticker {
 	if p.selectedIndex >= 0 && p.selectedIndex < len(p.tickers) {
@@ -142,31 +236,16 @@ func (p *MarketOverviewPanel) SelectedTicker() market.Ticker {
 	return market.Ticker{}
 }
 
-// Helper function to format price
-func formatPrice(price int64, decimals int8) string {
-	if decimals <= 0 {
-		return fmt.Sprintf("%d", price)
-	}
-	divisor := int64(1)
-	for i := int8(0); i < decimals; i++ {
-		divisor *= 10
-	}
-	whole := price / divisor
-	frac := price % divisor
-	if frac < 0 {
-		frac = -frac
-	}
-	formatStr := fmt.Sprintf("%%d.%%0%dd", decimals)
-	return fmt.Sprintf(formatStr, whole, frac)
-}
-
 // TickerSelectedMsg is sent when a ticker is selected.
 type TickerSelectedMsg struct {
 	Ticker market.Ticker
 }
 
 // MarketUpdateMsg is sent when market data updates.
+// Exactly one of Event and Status is set.
 type MarketUpdateMsg struct {
-	Ticker market.TickerID
-	Event  core.Event
+	Ticker    market.TickerID
+	Event     core.Event
+	Status    *marketview.StatusEvent
+	Imbalance *marketview.ImbalanceEvent
 }
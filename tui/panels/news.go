@@ -2,6 +2,7 @@ package panels
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -21,6 +22,7 @@ type NewsPanel struct {
 	width         int
 	height        int
 	maxItems      int
+	cache         renderCache
 }
 
 // NewNewsPanel creates a new news panel.
@@ -36,12 +38,13 @@ func (p *NewsPanel) Init() tea.Cmd {
 }
 
 // Update handles messages for the panel.
-func (p *NewsPanel) Update(msg tea.Msg) (*NewsPanel, tea.Cmd) {
+func (p *NewsPanel) Update(msg tea.Msg) (Panel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if !p.focused {
 			return p, nil
 		}
+		p.cache.invalidate()
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
 			if p.selectedIndex > 0 {
@@ -65,8 +68,20 @@ func (p *NewsPanel) Update(msg tea.Msg) (*NewsPanel, tea.Cmd) {
 	return p, nil
 }
 
-// View renders the panel.
+// View renders the panel, reusing the last render if nothing changed.
 func (p *NewsPanel) View() string {
+	if out, ok := p.cache.get(p.width, p.height); ok {
+		return out
+	}
+	return p.cache.put(p.width, p.height, p.render())
+}
+
+// Version returns the panel's content version.
+func (p *NewsPanel) Version() uint64 {
+	return p.cache.version
+}
+
+func (p *NewsPanel) render() string {
 	var content strings.Builder
 
 	if len(p.news) == 0 {
@@ -135,14 +150,27 @@ func (p *NewsPanel) View() string {
 		panelStyle = styles.FocusedPanelStyle
 	}
 
-	title := styles.RenderTitle("📰 News", p.focused)
+	title := styles.RenderTitle(p.Title(), p.focused)
 	panel := lipgloss.JoinVertical(lipgloss.Left, title, content.String())
 
 	return panelStyle.Width(p.width - 2).Height(p.height - 2).Render(panel)
 }
 
+// Title returns the panel title.
+func (p *NewsPanel) Title() string {
+	return "📰 News"
+}
+
+// DefaultKey returns the key that focuses the panel.
+func (p *NewsPanel) DefaultKey() string {
+	return "f3"
+}
+
 // SetFocus sets the focus state of the panel.
 func (p *NewsPanel) SetFocus(focused bool) {
+	if focused != p.focused {
+		p.cache.invalidate()
+	}
 	p.focused = focused
 }
 
@@ -154,6 +182,10 @@ func (p *NewsPanel) SetSize(width, height int) {
 
 // SetNews sets the news items.
 func (p *NewsPanel) SetNews(items []news.NewsItem) {
+	if slices.Equal(p.news, items) {
+		return
+	}
+	p.cache.invalidate()
 	p.news = items
 	// Reset selection if out of bounds
 	if p.selectedIndex >= len(p.news) {
@@ -166,6 +198,7 @@ func (p *NewsPanel) SetNews(items []news.NewsItem) {
 
 // AddNews adds a news item to the panel.
 func (p *NewsPanel) AddNews(item news.NewsItem) {
+	p.cache.invalidate()
 	p.news = append(p.news, item)
 	// Keep only maxItems
 	if len(p.news) > p.maxItems {
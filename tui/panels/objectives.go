@@ -0,0 +1,200 @@
+package panels
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/zappabad/stockcraft/internal/objective"
+	"github.com/zappabad/stockcraft/tui/styles"
+)
+
+// ObjectivesPanel displays the player's objectives with a progress bar each
+// and whether they are met or failed.
+type ObjectivesPanel struct {
+	statuses     []objective.Status
+	scrollOffset int
+	focused      bool
+	width        int
+	height       int
+	cache        renderCache
+}
+
+// NewObjectivesPanel creates a new objectives panel.
+func NewObjectivesPanel() *ObjectivesPanel {
+	return &ObjectivesPanel{}
+}
+
+// Init initializes the panel.
+func (p *ObjectivesPanel) Init() tea.Cmd {
+	return nil
+}
+
+// Update handles messages for the panel.
+func (p *ObjectivesPanel) Update(msg tea.Msg) (Panel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if !p.focused {
+			return p, nil
+		}
+		p.cache.invalidate()
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+			if p.scrollOffset > 0 {
+				p.scrollOffset--
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+			if p.scrollOffset < len(p.statuses)-1 {
+				p.scrollOffset++
+			}
+		}
+	}
+	return p, nil
+}
+
+// View renders the panel, reusing the last render if nothing changed.
+func (p *ObjectivesPanel) View() string {
+	if out, ok := p.cache.get(p.width, p.height); ok {
+		return out
+	}
+	return p.cache.put(p.width, p.height, p.render())
+}
+
+// Version returns the panel's content version.
+func (p *ObjectivesPanel) Version() uint64 {
+	return p.cache.version
+}
+
+func (p *ObjectivesPanel) render() string {
+	var content strings.Builder
+
+	if len(p.statuses) == 0 {
+		content.WriteString(lipgloss.NewStyle().Foreground(styles.TextMutedColor).Render("No objectives"))
+	} else {
+		inner := max(p.width-4, 10)
+		barWidth := max(min(inner-16, 20), 4)
+
+		// Two lines per objective
+		visible := max((p.height-3)/2, 1)
+		for i := p.scrollOffset; i < len(p.statuses) && i < p.scrollOffset+visible; i++ {
+			s := p.statuses[i]
+			if i > p.scrollOffset {
+				content.WriteString("\n")
+			}
+
+			state := s.State.String()
+			name := s.Objective.Name
+			if pad := inner - len(state) - 1; len(name) > pad {
+				name = name[:max(pad, 0)]
+			}
+			content.WriteString(fmt.Sprintf("%-*s %s", inner-len(state)-1, name, stateStyle(s.State).Render(state)))
+			content.WriteString("\n")
+
+			filled := int(s.Progress*float64(barWidth) + 0.5)
+			bar := stateStyle(s.State).Render(strings.Repeat("█", filled)) +
+				lipgloss.NewStyle().Foreground(styles.TextMutedColor).Render(strings.Repeat("░", barWidth-filled))
+			detail := fmt.Sprintf("%3.0f%% %s", 100*s.Progress, objectiveDetail(s))
+			content.WriteString(bar + " " + detail)
+		}
+	}
+
+	// Apply panel styling
+	panelStyle := styles.PanelStyle
+	if p.focused {
+		panelStyle = styles.FocusedPanelStyle
+	}
+
+	title := styles.RenderTitle(p.Title(), p.focused)
+	panel := lipgloss.JoinVertical(lipgloss.Left, title, content.String())
+
+	return panelStyle.Width(p.width - 2).Height(p.height - 2).Render(panel)
+}
+
+// objectiveDetail is the metric's value against its target, and the time left
+// while the objective is pending.
+func objectiveDetail(s objective.Status) string {
+	c := s.Condition
+	value := "-"
+	if s.HasValue {
+		value = formatObjectiveValue(s.Value, s.Unit)
+	}
+	out := fmt.Sprintf("%s %s %s", value, c.Op, formatObjectiveValue(c.Target, s.Unit))
+	if s.State == objective.StatePending {
+		out += fmt.Sprintf(" %s %s", c.Mode, s.Remaining().Truncate(time.Second))
+	}
+	return out
+}
+
+func formatObjectiveValue(v float64, unit string) string {
+	switch unit {
+	case "$":
+		return "$" + strconv.FormatFloat(v, 'f', 2, 64)
+	case "%":
+		return strconv.FormatFloat(v, 'f', 2, 64) + "%"
+	default:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+}
+
+func stateStyle(s objective.State) lipgloss.Style {
+	switch s {
+	case objective.StateMet:
+		return styles.BuyStyle
+	case objective.StateFailed:
+		return styles.SellStyle
+	case objective.StateUnavailable:
+		return lipgloss.NewStyle().Foreground(styles.TextMutedColor)
+	default:
+		return lipgloss.NewStyle().Foreground(styles.AccentColor)
+	}
+}
+
+// Title returns the panel title.
+func (p *ObjectivesPanel) Title() string {
+	if len(p.statuses) == 0 {
+		return "🎯 Objectives"
+	}
+	met := 0
+	for _, s := range p.statuses {
+		if s.State == objective.StateMet {
+			met++
+		}
+	}
+	return fmt.Sprintf("🎯 Objectives %d/%d", met, len(p.statuses))
+}
+
+// DefaultKey returns the key that focuses the panel.
+func (p *ObjectivesPanel) DefaultKey() string {
+	return "f7"
+}
+
+// SetFocus sets the focus state of the panel.
+func (p *ObjectivesPanel) SetFocus(focused bool) {
+	if focused != p.focused {
+		p.cache.invalidate()
+	}
+	p.focused = focused
+}
+
+// SetSize sets the panel dimensions.
+func (p *ObjectivesPanel) SetSize(width, height int) {
+	p.width = width
+	p.height = height
+}
+
+// SetStatuses sets the objectives' statuses, in configuration order.
+func (p *ObjectivesPanel) SetStatuses(statuses []objective.Status) {
+	if slices.Equal(p.statuses, statuses) {
+		return
+	}
+	p.cache.invalidate()
+	p.statuses = statuses
+	if p.scrollOffset >= len(p.statuses) {
+		p.scrollOffset = 0
+	}
+}
@@ -0,0 +1,43 @@
+package panels
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zappabad/stockcraft/internal/objective"
+)
+
+func TestObjectivesPanelShowsProgressAndState(t *testing.T) {
+	p := NewObjectivesPanel()
+	p.SetSize(72, 12)
+	if out := p.View(); !strings.Contains(out, "No objectives") {
+		t.Errorf("expected an empty state:\n%s", out)
+	}
+
+	status := func(name, expr string, state objective.State, value, progress float64) objective.Status {
+		c, err := objective.Parse(expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return objective.Status{
+			Objective: objective.Objective{Name: name, Expr: expr},
+			Condition: c, State: state, Value: value, HasValue: true,
+			Progress: progress, Elapsed: 15 * time.Minute, Unit: "$",
+		}
+	}
+	p.SetStatuses([]objective.Status{
+		status("Get rich", "equity >= 110000 at 1h", objective.StatePending, 99000, 0.9),
+		status("Survive", "equity >= 90000 by 10m", objective.StateMet, 99000, 1),
+	})
+	out := p.View()
+
+	for _, want := range []string{"Objectives 1/2", "Get rich", "PENDING", "Survive", "MET", "$99000.00 >= $110000.00 at 45m0s", " 90%", "100%"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the panel:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, ">= $90000.00 by") {
+		t.Errorf("expected no time left on a resolved objective:\n%s", out)
+	}
+}
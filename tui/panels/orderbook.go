@@ -1,13 +1,17 @@
 package panels
 
 import (
+	"cmp"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/money"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
 	"github.com/zappabad/stockcraft/tui/styles"
@@ -24,12 +28,83 @@ type OrderbookPanel struct {
 	width        int
 	height       int
 	maxLevels    int
+
+	// Price aggregation: levels are grouped into buckets of bucketSize ticks
+	// while bucketed is on
+	bucketSize core.PriceTicks
+	bucketed   bool
+
+	// Block trades: prints of at least blockSize are marked or, in
+	// TapeBlocksOnly, the only ones shown
+	blockSize core.Size
+	tapeMode  TapeMode
+
+	// Size gradient: trades at least gradient times the tape's average size
+	// are loud, those at most 1/gradient of it quiet (0 = off)
+	gradient float64
+
+	// Prices of the player's resting orders, marked on their levels while
+	// showMine is on
+	myBids, myAsks []core.PriceTicks
+	showMine       bool
+
+	// Level smoothing: a level that leaves the book stays on the ladder,
+	// faded at size 0, until linger has passed since it left (0 = off).
+	// now is the panel's clock in Unix nanoseconds.
+	linger time.Duration
+	now    int64
+	fading map[levelKey]int64
+
+	display  money.Display
+	sizeUnit SizeUnit
+	cache    renderCache
+}
+
+// TapeMode selects how the recent trades list treats block trades.
+type TapeMode uint8
+
+const (
+	// TapeAll shows every trade alike.
+	TapeAll TapeMode = iota
+	// TapeHighlightBlocks shows every trade and marks block trades.
+	TapeHighlightBlocks
+	// TapeBlocksOnly shows only block trades.
+	TapeBlocksOnly
+)
+
+// DefaultSizeGradient is the size ratio to the tape's average at which a
+// trade turns loud (and its inverse, quiet).
+const DefaultSizeGradient = 3
+
+// levelKey identifies a ladder row.
+type levelKey struct {
+	side  core.Side
+	price core.PriceTicks
 }
 
+// ladderRow is a level on the ladder, live or fading.
+type ladderRow struct {
+	orderbookview.Level
+	fading bool
+}
+
+// tapeWeight is how loudly a trade is shown on the tape.
+type tapeWeight int8
+
+const (
+	tapeQuiet tapeWeight = iota - 1
+	tapeNormal
+	tapeLoud
+)
+
 // NewOrderbookPanel creates a new orderbook panel.
 func NewOrderbookPanel() *OrderbookPanel {
 	return &OrderbookPanel{
-		maxLevels: 10,
+		maxLevels:  10,
+		bucketSize: 10,
+		blockSize:  100,
+		gradient:   DefaultSizeGradient,
+		showMine:   true,
 	}
 }
 
@@ -39,12 +114,13 @@ func (p *OrderbookPanel) Init() tea.Cmd {
 }
 
 // Update handles messages for the panel.
-func (p *OrderbookPanel) Update(msg tea.Msg) (*OrderbookPanel, tea.Cmd) {
+func (p *OrderbookPanel) Update(msg tea.Msg) (Panel, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if !p.focused {
 			return p, nil
 		}
+		p.cache.invalidate()
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
 			if p.scrollOffset > 0 {
@@ -52,20 +128,33 @@ func (p *OrderbookPanel) Update(msg tea.Msg) (*OrderbookPanel, tea.Cmd) {
 			}
 		case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
 			p.scrollOffset++
+		case key.Matches(msg, key.NewBinding(key.WithKeys("b"))):
+			p.bucketed = !p.bucketed
+			p.fading = nil
+		case key.Matches(msg, key.NewBinding(key.WithKeys("t"))):
+			p.tapeMode = (p.tapeMode + 1) % 3
+		case key.Matches(msg, key.NewBinding(key.WithKeys("o"))):
+			p.showMine = !p.showMine
 		}
 	}
 	return p, nil
 }
 
-// View renders the panel.
+// View renders the panel, reusing the last render if nothing changed.
 func (p *OrderbookPanel) View() string {
-	var content strings.Builder
-
-	// Title with ticker name
-	tickerName := "No ticker selected"
-	if p.ticker.Name != "" {
-		tickerName = p.ticker.Name
+	if out, ok := p.cache.get(p.width, p.height); ok {
+		return out
 	}
+	return p.cache.put(p.width, p.height, p.render())
+}
+
+// Version returns the panel's content version.
+func (p *OrderbookPanel) Version() uint64 {
+	return p.cache.version
+}
+
+func (p *OrderbookPanel) render() string {
+	var content strings.Builder
 
 	// Calculate available height for orders
 	availableHeight := p.height - 6 // Account for header, title, borders
@@ -83,11 +172,11 @@ func (p *OrderbookPanel) View() string {
 	content.WriteString("\n")
 
 	// Get levels to display
-	bidsToShow := p.bids
+	bidsToShow := p.ladder(core.SideBuy, p.bids)
 	if len(bidsToShow) > levelsToShow {
 		bidsToShow = bidsToShow[:levelsToShow]
 	}
-	asksToShow := p.asks
+	asksToShow := p.ladder(core.SideSell, p.asks)
 	if len(asksToShow) > levelsToShow {
 		asksToShow = asksToShow[:levelsToShow]
 	}
@@ -104,37 +193,67 @@ func (p *OrderbookPanel) View() string {
 		bidPrice := ""
 		askPrice := ""
 		askSize := ""
+		bidMark, askMark := " ", " "
+		bidStyle, askStyle := styles.BuyStyle, styles.SellStyle
 
 		if i < len(bidsToShow) {
-			bidSize = fmt.Sprintf("%d", bidsToShow[i].Size)
-			bidPrice = formatPrice(int64(bidsToShow[i].Price), p.ticker.Decimals)
+			bidSize = p.sizeUnit.FormatSize(p.ticker, bidsToShow[i].Price, bidsToShow[i].Size)
+			bidPrice = p.display.FormatPrice(int64(bidsToShow[i].Price), p.ticker.Decimals)
+			if p.isMine(core.SideBuy, bidsToShow[i].Price) {
+				bidMark = myOrderMarker
+			}
+			if bidsToShow[i].fading {
+				bidStyle = styles.LevelFadingStyle
+			}
 		}
 		if i < len(asksToShow) {
-			askPrice = formatPrice(int64(asksToShow[i].Price), p.ticker.Decimals)
-			askSize = fmt.Sprintf("%d", asksToShow[i].Size)
+			askPrice = p.display.FormatPrice(int64(asksToShow[i].Price), p.ticker.Decimals)
+			askSize = p.sizeUnit.FormatSize(p.ticker, asksToShow[i].Price, asksToShow[i].Size)
+			if p.isMine(core.SideSell, asksToShow[i].Price) {
+				askMark = myOrderMarker
+			}
+			if asksToShow[i].fading {
+				askStyle = styles.LevelFadingStyle
+			}
 		}
 
 		bidPart := fmt.Sprintf("%10s %8s", bidSize, bidPrice)
 		askPart := fmt.Sprintf("%8s %10s", askPrice, askSize)
 
-		bidStyled := styles.BuyStyle.Render(bidPart)
-		askStyled := styles.SellStyle.Render(askPart)
+		bidStyled := bidStyle.Render(bidPart)
+		askStyled := askStyle.Render(askPart)
 
-		content.WriteString(fmt.Sprintf("%s │ %s\n", bidStyled, askStyled))
+		content.WriteString(fmt.Sprintf("%s%s│%s%s\n", bidStyled, styles.MyOrderStyle.Render(bidMark), styles.MyOrderStyle.Render(askMark), askStyled))
 	}
 
 	// Recent trades section
 	content.WriteString("\n")
-	content.WriteString(styles.HeaderStyle.Render("Recent Trades"))
+	tapeHeader := "Recent Trades"
+	switch p.tapeMode {
+	case TapeHighlightBlocks:
+		tapeHeader += fmt.Sprintf(" (▌ ≥%d)", p.blockSize)
+	case TapeBlocksOnly:
+		tapeHeader += fmt.Sprintf(" (≥%d only)", p.blockSize)
+	}
+	content.WriteString(styles.HeaderStyle.Render(tapeHeader))
 	content.WriteString("\n")
 
 	tradesToShow := p.trades
+	if p.tapeMode == TapeBlocksOnly {
+		tradesToShow = nil
+		for _, trade := range p.trades {
+			if p.isBlock(trade) {
+				tradesToShow = append(tradesToShow, trade)
+			}
+		}
+	}
 	if len(tradesToShow) > 5 {
 		tradesToShow = tradesToShow[len(tradesToShow)-5:]
 	}
 
+	avg := averageTradeSize(p.trades)
 	for _, trade := range tradesToShow {
-		price := formatPrice(int64(trade.Price), p.ticker.Decimals)
+		price := p.display.FormatPrice(int64(trade.Price), p.ticker.Decimals)
 		size := fmt.Sprintf("%d", trade.Size)
 
 		var sideStyle lipgloss.Style
@@ -143,8 +262,22 @@ func (p *OrderbookPanel) View() string {
 		} else {
 			sideStyle = styles.SellStyle
 		}
+		switch p.weight(trade.Size, avg) {
+		case tapeLoud:
+			sideStyle = sideStyle.Inherit(styles.TapeLoudStyle)
+		case tapeQuiet:
+			sideStyle = sideStyle.Inherit(styles.TapeQuietStyle)
+		}
 
 		tradeStr := fmt.Sprintf("%8s @ %8s", size, price)
+		if p.tapeMode == TapeHighlightBlocks {
+			if p.isBlock(trade) {
+				sideStyle = sideStyle.Inherit(styles.BlockTradeStyle)
+				tradeStr = "▌" + tradeStr
+			} else {
+				tradeStr = " " + tradeStr
+			}
+		}
 		content.WriteString(sideStyle.Render(tradeStr))
 		content.WriteString("\n")
 	}
@@ -155,14 +288,34 @@ func (p *OrderbookPanel) View() string {
 		panelStyle = styles.FocusedPanelStyle
 	}
 
-	title := styles.RenderTitle(fmt.Sprintf("📊 Orderbook - %s", tickerName), p.focused)
+	title := styles.RenderTitle(p.Title(), p.focused)
 	panel := lipgloss.JoinVertical(lipgloss.Left, title, content.String())
 
 	return panelStyle.Width(p.width - 2).Height(p.height - 2).Render(panel)
 }
 
+// Title returns the panel title, including the selected ticker.
+func (p *OrderbookPanel) Title() string {
+	tickerName := "No ticker selected"
+	if p.ticker.Name != "" {
+		tickerName = p.ticker.Name
+	}
+	if b := p.Bucket(); b > 0 {
+		return fmt.Sprintf("📊 Orderbook - %s [%s]", tickerName, p.display.FormatPrice(int64(b), p.ticker.Decimals))
+	}
+	return fmt.Sprintf("📊 Orderbook - %s", tickerName)
+}
+
+// DefaultKey returns the key that focuses the panel.
+func (p *OrderbookPanel) DefaultKey() string {
+	return "f2"
+}
+
 // SetFocus sets the focus state of the panel.
 func (p *OrderbookPanel) SetFocus(focused bool) {
+	if focused != p.focused {
+		p.cache.invalidate()
+	}
 	p.focused = focused
 }
 
@@ -174,26 +327,232 @@ func (p *OrderbookPanel) SetSize(width, height int) {
 
 // SetTicker sets the ticker to display.
 func (p *OrderbookPanel) SetTicker(ticker market.Ticker) {
+	p.cache.invalidate()
 	p.ticker = ticker
 	p.bids = nil
 	p.asks = nil
 	p.trades = nil
+	p.fading = nil
 	p.scrollOffset = 0
 }
 
-// SetLevels sets the orderbook levels.
+// SetBucketSize sets the width, in ticks, of the price buckets used when
+// aggregation is toggled on.
+func (p *OrderbookPanel) SetBucketSize(size core.PriceTicks) {
+	p.cache.invalidate()
+	p.bucketSize = size
+}
+
+// SetBucketed turns price aggregation on or off.
+func (p *OrderbookPanel) SetBucketed(on bool) {
+	p.cache.invalidate()
+	p.bucketed = on
+	p.fading = nil
+}
+
+// Bucket returns the active bucket width in ticks, or 0 when the panel shows
+// raw levels. SetLevels should be given levels aggregated to this width.
+func (p *OrderbookPanel) Bucket() core.PriceTicks {
+	if !p.bucketed || p.bucketSize <= 1 {
+		return 0
+	}
+	return p.bucketSize
+}
+
+// SetBlockSize sets the size at or above which a trade counts as a block
+// trade on the tape.
+func (p *OrderbookPanel) SetBlockSize(size core.Size) {
+	p.cache.invalidate()
+	p.blockSize = size
+}
+
+// SetPriceDisplay sets how prices are shown.
+func (p *OrderbookPanel) SetPriceDisplay(d money.Display) {
+	p.cache.invalidate()
+	p.display = d
+}
+
+// SetSizeUnit sets how level sizes are shown. The tape keeps raw sizes.
+func (p *OrderbookPanel) SetSizeUnit(u SizeUnit) {
+	p.cache.invalidate()
+	p.sizeUnit = u
+}
+
+// SetTapeMode sets how the recent trades list treats block trades.
+func (p *OrderbookPanel) SetTapeMode(mode TapeMode) {
+	p.cache.invalidate()
+	p.tapeMode = mode
+}
+
+// TapeMode returns how the recent trades list treats block trades.
+func (p *OrderbookPanel) TapeMode() TapeMode {
+	return p.tapeMode
+}
+
+// SetSizeGradient sets the size ratio to the tape's average at which trades
+// are shown loud, and its inverse at which they are quiet. 0 shows every
+// trade alike.
+func (p *OrderbookPanel) SetSizeGradient(ratio float64) {
+	p.cache.invalidate()
+	p.gradient = ratio
+}
+
+// weight grades a trade's size against the tape's average size.
+func (p *OrderbookPanel) weight(size core.Size, avg float64) tapeWeight {
+	if p.gradient <= 0 || avg <= 0 {
+		return tapeNormal
+	}
+	switch r := float64(size) / avg; {
+	case r >= p.gradient:
+		return tapeLoud
+	case r*p.gradient <= 1:
+		return tapeQuiet
+	}
+	return tapeNormal
+}
+
+func averageTradeSize(trades []core.TradeEvent) float64 {
+	if len(trades) == 0 {
+		return 0
+	}
+	var total float64
+	for _, t := range trades {
+		total += float64(t.Size)
+	}
+	return total / float64(len(trades))
+}
+
+func (p *OrderbookPanel) isBlock(trade core.TradeEvent) bool {
+	return trade.Size >= p.blockSize
+}
+
+// SetLevels sets the orderbook levels. With a linger set, levels missing
+// from the new ones start fading and returning levels stop.
 func (p *OrderbookPanel) SetLevels(bids, asks []orderbookview.Level) {
+	if slices.Equal(p.bids, bids) && slices.Equal(p.asks, asks) {
+		return
+	}
+	p.cache.invalidate()
+	if p.linger > 0 {
+		p.fade(core.SideBuy, p.bids, bids)
+		p.fade(core.SideSell, p.asks, asks)
+	}
 	p.bids = bids
 	p.asks = asks
 }
 
+// fade starts fading the levels of prev missing from next and stops
+// fading those in next.
+func (p *OrderbookPanel) fade(side core.Side, prev, next []orderbookview.Level) {
+	for _, l := range prev {
+		if !slices.ContainsFunc(next, func(n orderbookview.Level) bool { return n.Price == l.Price }) {
+			if p.fading == nil {
+				p.fading = make(map[levelKey]int64)
+			}
+			p.fading[levelKey{side, l.Price}] = p.now
+		}
+	}
+	for _, l := range next {
+		delete(p.fading, levelKey{side, l.Price})
+	}
+}
+
+// ladder merges a side's live levels with its fading ones, best price
+// first.
+func (p *OrderbookPanel) ladder(side core.Side, live []orderbookview.Level) []ladderRow {
+	rows := make([]ladderRow, 0, len(live))
+	for _, l := range live {
+		rows = append(rows, ladderRow{Level: l})
+	}
+	n := len(rows)
+	for k := range p.fading {
+		if k.side == side {
+			rows = append(rows, ladderRow{Level: orderbookview.Level{Price: k.price}, fading: true})
+		}
+	}
+	if len(rows) == n {
+		return rows
+	}
+	slices.SortFunc(rows, func(a, b ladderRow) int {
+		if side == core.SideBuy {
+			return cmp.Compare(b.Price, a.Price)
+		}
+		return cmp.Compare(a.Price, b.Price)
+	})
+	return rows
+}
+
+// SetLevelLinger sets how long a level that leaves the book stays on the
+// ladder, faded, to keep rapidly changing levels from flickering. 0 drops
+// levels as soon as they leave.
+func (p *OrderbookPanel) SetLevelLinger(d time.Duration) {
+	p.cache.invalidate()
+	p.linger = d
+	if d <= 0 {
+		p.fading = nil
+	}
+}
+
+// SetNow moves the panel's clock (Unix nanoseconds, the event time base),
+// dropping fading levels whose linger has passed.
+func (p *OrderbookPanel) SetNow(now int64) {
+	p.now = now
+	for k, since := range p.fading {
+		if now-since >= int64(p.linger) {
+			delete(p.fading, k)
+			p.cache.invalidate()
+		}
+	}
+}
+
+// SetMyOrders sets the prices of the player's resting orders, which are
+// marked on their levels (or buckets) beside the divider.
+func (p *OrderbookPanel) SetMyOrders(bids, asks []core.PriceTicks) {
+	if slices.Equal(p.myBids, bids) && slices.Equal(p.myAsks, asks) {
+		return
+	}
+	p.cache.invalidate()
+	p.myBids = bids
+	p.myAsks = asks
+}
+
+// SetShowMyOrders turns the player's order markers on or off.
+func (p *OrderbookPanel) SetShowMyOrders(on bool) {
+	p.cache.invalidate()
+	p.showMine = on
+}
+
+// myOrderMarker flags a level holding one of the player's orders.
+const myOrderMarker = "◆"
+
+// isMine reports whether the level at price holds one of the player's
+// orders and the markers are shown.
+func (p *OrderbookPanel) isMine(side core.Side, price core.PriceTicks) bool {
+	if !p.showMine {
+		return false
+	}
+	mine := p.myBids
+	if side == core.SideSell {
+		mine = p.myAsks
+	}
+	bucket := p.Bucket()
+	return slices.ContainsFunc(mine, func(m core.PriceTicks) bool {
+		return orderbookview.BucketPrice(side, m, bucket) == price
+	})
+}
+
 // SetTrades sets the recent trades.
 func (p *OrderbookPanel) SetTrades(trades []core.TradeEvent) {
+	if slices.Equal(p.trades, trades) {
+		return
+	}
+	p.cache.invalidate()
 	p.trades = trades
 }
 
 // AddTrade adds a trade to the display.
 func (p *OrderbookPanel) AddTrade(trade core.TradeEvent) {
+	p.cache.invalidate()
 	p.trades = append(p.trades, trade)
 	// Keep only last 20 trades
 	if len(p.trades) > 20 {
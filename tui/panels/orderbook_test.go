@@ -0,0 +1,285 @@
+package panels
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/tui/styles"
+)
+
+// denseBook rests one order per tick on each side around 100.00.
+func denseBook() *orderbookview.BookView {
+	v := orderbookview.NewBookView(10)
+	id := core.OrderID(1)
+	for i := 0; i < 30; i++ {
+		v.Apply(core.OrderRestedEvent{OrderID: id, UserID: 1, Side: core.SideBuy, Price: core.PriceTicks(9999 - i), Size: core.Size(10 + i), Time: 1})
+		id++
+		v.Apply(core.OrderRestedEvent{OrderID: id, UserID: 2, Side: core.SideSell, Price: core.PriceTicks(10001 + i), Size: core.Size(10 + i), Time: 1})
+		id++
+	}
+	return v
+}
+
+func TestOrderbookBucketedRender(t *testing.T) {
+	v := denseBook()
+	p := NewOrderbookPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(50, 20)
+	p.SetFocus(true)
+
+	p.SetLevels(v.LevelsBucketed(core.SideBuy, p.Bucket()), v.LevelsBucketed(core.SideSell, p.Bucket()))
+	assertGolden(t, "orderbook_raw", p.View())
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if p.Bucket() != 10 {
+		t.Fatalf("expected 10-tick buckets after 'b', got %d", p.Bucket())
+	}
+	bids := v.LevelsBucketed(core.SideBuy, p.Bucket())
+	asks := v.LevelsBucketed(core.SideSell, p.Bucket())
+	p.SetLevels(bids, asks)
+	assertGolden(t, "orderbook_bucketed", p.View())
+
+	// Bids round down, asks round up; no size is lost
+	if bids[0].Price != 9990 || asks[0].Price != 10010 {
+		t.Errorf("unexpected best buckets: bid %d, ask %d", bids[0].Price, asks[0].Price)
+	}
+	var raw, agg core.Size
+	for _, l := range v.Levels(core.SideBuy) {
+		raw += l.Size
+	}
+	for _, l := range bids {
+		agg += l.Size
+	}
+	if raw != agg {
+		t.Errorf("bucketed bid size %d != raw %d", agg, raw)
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if p.Bucket() != 0 {
+		t.Errorf("expected raw levels after second 'b', got bucket %d", p.Bucket())
+	}
+}
+
+func TestOrderbookTapeBlockTrades(t *testing.T) {
+	p := NewOrderbookPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(50, 20)
+	p.SetFocus(true)
+	p.SetBlockSize(100)
+	p.AddTrade(core.TradeEvent{Price: 10000, Size: 5, TakerSide: core.SideBuy})
+	p.AddTrade(core.TradeEvent{Price: 10001, Size: 250, TakerSide: core.SideBuy})
+	p.AddTrade(core.TradeEvent{Price: 9999, Size: 40, TakerSide: core.SideSell})
+
+	block := styles.BuyStyle.Inherit(styles.BlockTradeStyle).Render("▌     250 @   100.01")
+	if strings.Contains(p.View(), "▌") {
+		t.Error("expected no block marker with the tape showing all trades alike")
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if p.TapeMode() != TapeHighlightBlocks {
+		t.Fatalf("expected highlight mode after 't', got %d", p.TapeMode())
+	}
+	view := p.View()
+	assertGolden(t, "orderbook_tape_highlight", view)
+	if !strings.Contains(view, block) {
+		t.Errorf("expected the block trade in the highlight style, got:\n%s", view)
+	}
+	// One marker in the header legend, one on the block trade
+	if strings.Count(view, "▌") != 2 {
+		t.Errorf("expected only the block trade to be marked, got:\n%s", view)
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	view = p.View()
+	if !strings.Contains(view, "250 @   100.01") || strings.Contains(view, "5 @   100.00") || strings.Contains(view, "40 @    99.99") {
+		t.Errorf("expected only the block trade in blocks-only mode, got:\n%s", view)
+	}
+
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if p.TapeMode() != TapeAll {
+		t.Errorf("expected 't' to cycle back to all trades, got %d", p.TapeMode())
+	}
+}
+
+func TestOrderbookMarksMyOrders(t *testing.T) {
+	v := denseBook()
+	p := NewOrderbookPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(50, 20)
+	p.SetLevels(v.LevelsBucketed(core.SideBuy, 0), v.LevelsBucketed(core.SideSell, 0))
+	p.SetMyOrders([]core.PriceTicks{9998}, []core.PriceTicks{10003})
+
+	// rowOf returns the ladder row showing a price
+	rowOf := func(price string) string {
+		t.Helper()
+		for _, line := range strings.Split(p.View(), "\n") {
+			if strings.Contains(line, price) {
+				return line
+			}
+		}
+		t.Fatalf("no row shows %s:\n%s", price, p.View())
+		return ""
+	}
+	if row := rowOf("99.98"); !strings.Contains(row, "◆│") || strings.Contains(row, "│◆") {
+		t.Errorf("expected only the bid at 99.98 marked, got %q", row)
+	}
+	if row := rowOf("100.03"); !strings.Contains(row, "│◆") || strings.Contains(row, "◆│") {
+		t.Errorf("expected only the ask at 100.03 marked, got %q", row)
+	}
+	if row := rowOf("99.99"); strings.Contains(row, "◆") {
+		t.Errorf("expected no marker on the top row, got %q", row)
+	}
+
+	// Buckets carry the marker of any order inside them
+	p.SetBucketed(true)
+	p.SetLevels(v.LevelsBucketed(core.SideBuy, p.Bucket()), v.LevelsBucketed(core.SideSell, p.Bucket()))
+	if row := rowOf("99.90"); !strings.Contains(row, "◆│◆") {
+		t.Errorf("expected both best buckets marked, got %q", row)
+	}
+
+	// 'o' hides the markers
+	p.SetFocus(true)
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("o")})
+	if strings.Contains(p.View(), "◆") {
+		t.Errorf("expected no markers after 'o':\n%s", p.View())
+	}
+}
+
+func TestOrderbookTapeSizeGradient(t *testing.T) {
+	p := NewOrderbookPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(50, 20)
+	for _, size := range []core.Size{10, 12, 8, 10} {
+		p.AddTrade(core.TradeEvent{Price: 10000, Size: size, TakerSide: core.SideBuy})
+	}
+	p.AddTrade(core.TradeEvent{Price: 10001, Size: 100, TakerSide: core.SideSell})
+	p.AddTrade(core.TradeEvent{Price: 10000, Size: 1, TakerSide: core.SideBuy})
+
+	// The average is 141 / 6 = 23.5: 100 is over 3× it, 1 under a third
+	avg := averageTradeSize(p.trades)
+	for _, tt := range []struct {
+		size core.Size
+		want tapeWeight
+	}{{100, tapeLoud}, {10, tapeNormal}, {8, tapeNormal}, {1, tapeQuiet}} {
+		if got := p.weight(tt.size, avg); got != tt.want {
+			t.Errorf("size %d against %.1f: expected weight %d, got %d", tt.size, avg, tt.want, got)
+		}
+	}
+
+	view := p.View()
+	loud := styles.SellStyle.Inherit(styles.TapeLoudStyle).Render("     100 @   100.01")
+	quiet := styles.BuyStyle.Inherit(styles.TapeQuietStyle).Render("       1 @   100.00")
+	if !strings.Contains(view, loud) || !strings.Contains(view, quiet) {
+		t.Errorf("expected the large trade loud and the small one quiet, got:\n%s", view)
+	}
+
+	// 0 turns the gradient off
+	p.SetSizeGradient(0)
+	if got := p.weight(100, avg); got != tapeNormal {
+		t.Errorf("expected no gradient when off, got %d", got)
+	}
+}
+
+func TestOrderbookRemovedLevelLingers(t *testing.T) {
+	p := NewOrderbookPanel()
+	p.SetTicker(market.Ticker{ID: 1, Name: "AAPL", Decimals: 2})
+	p.SetSize(50, 20)
+	p.SetLevelLinger(2 * time.Second)
+
+	start := time.Unix(1000, 0).UnixNano()
+	p.SetNow(start)
+	asks := []orderbookview.Level{{Price: 10001, Size: 5}}
+	p.SetLevels([]orderbookview.Level{{Price: 10000, Size: 10}, {Price: 9999, Size: 20}, {Price: 9998, Size: 30}}, asks)
+	p.SetLevels([]orderbookview.Level{{Price: 10000, Size: 10}, {Price: 9998, Size: 30}}, asks)
+
+	fading := styles.LevelFadingStyle.Render(fmt.Sprintf("%10s %8s", "0", "99.99"))
+	rows := func() []string {
+		var out []string
+		for _, line := range strings.Split(p.View(), "\n") {
+			for _, price := range []string{"100.00", "99.99", "99.98"} {
+				if strings.Contains(line, price) {
+					out = append(out, price)
+				}
+			}
+		}
+		return out
+	}
+	if view := p.View(); !strings.Contains(view, fading) {
+		t.Fatalf("expected the removed level faded at size 0, got:\n%s", view)
+	}
+	if got := rows(); !slices.Equal(got, []string{"100.00", "99.99", "99.98"}) {
+		t.Errorf("expected the fading level kept in price order, got %v", got)
+	}
+
+	// Still there just before the linger is up
+	p.SetNow(start + int64(2*time.Second) - 1)
+	if !strings.Contains(p.View(), fading) {
+		t.Errorf("expected the level to linger for the full 2s")
+	}
+
+	p.SetNow(start + int64(2*time.Second))
+	if got := rows(); !slices.Equal(got, []string{"100.00", "99.98"}) {
+		t.Errorf("expected the level gone once the linger passed, got %v", got)
+	}
+
+	// A level coming back stops fading
+	p.SetLevelLinger(time.Second)
+	p.SetLevels([]orderbookview.Level{{Price: 10000, Size: 10}}, asks)
+	p.SetLevels([]orderbookview.Level{{Price: 10000, Size: 10}, {Price: 9998, Size: 7}}, asks)
+	if strings.Contains(p.View(), styles.LevelFadingStyle.Render(fmt.Sprintf("%10s %8s", "0", "99.98"))) {
+		t.Errorf("expected a returning level to stop fading")
+	}
+}
+
+func TestSizeUnitRendersLevel(t *testing.T) {
+	ticker := market.Ticker{ID: 1, Name: "AAPL", Decimals: 2, LotSize: 100}
+	book := NewOrderbookPanel()
+	book.SetTicker(ticker)
+	book.SetSize(50, 20)
+	book.SetLevels([]orderbookview.Level{{Price: 15025, Size: 250}}, nil)
+	overview := NewMarketOverviewPanel([]market.Ticker{ticker})
+	overview.SetSize(60, 10)
+	overview.UpdatePrices(1, marketview.BestPrices{BidPrice: 15025, BidSize: 250, BidOK: true})
+
+	// 250 at 150.25 in each unit, in both panels
+	for _, want := range []struct {
+		unit  SizeUnit
+		shown string
+	}{
+		{SizeShares, " 250 "},
+		{SizeLots, " 2.5 "},
+		{SizeNotional, " 37562.50 "},
+	} {
+		book.SetSizeUnit(want.unit)
+		overview.SetSizeUnit(want.unit)
+		for name, view := range map[string]string{"orderbook": book.View(), "market": overview.View()} {
+			if !strings.Contains(view, want.shown) {
+				t.Errorf("%v: expected the %s panel to show %q, got\n%s", want.unit, name, want.shown, view)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		unit  SizeUnit
+		size  core.Size
+		price core.PriceTicks
+		want  string
+	}{
+		{SizeLots, 300, 15025, "3"},
+		{SizeLots, 1, 15025, "0.01"},
+		{SizeNotional, 100000, 15025, "15.03M"},
+		{SizeNotional, 10_000_000, 15025, "1.50B"},
+	} {
+		if got := tc.unit.FormatSize(ticker, tc.price, tc.size); got != tc.want {
+			t.Errorf("%v.FormatSize(%d @ %d) = %q, want %q", tc.unit, tc.size, tc.price, got, tc.want)
+		}
+	}
+}
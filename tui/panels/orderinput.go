@@ -1,15 +1,20 @@
 package panels
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/cursor"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
 	"github.com/zappabad/stockcraft/internal/orderbook/core"
 	"github.com/zappabad/stockcraft/tui/styles"
 )
@@ -24,14 +29,19 @@ const (
 	FieldPrice
 	FieldQuantity
 	FieldSubmit
+	FieldOrderID
 )
 
+// typeCancel is the typeOptions index of the cancel-by-ID mode.
+const typeCancel = 2
+
 // OrderInputPanel handles order input with autocomplete.
 type OrderInputPanel struct {
 	tickers       []market.Ticker
 	tickerInput   textinput.Model
 	priceInput    textinput.Model
 	quantityInput textinput.Model
+	orderIDInput  textinput.Model
 
 	// Dropdown state
 	showDropdown     bool
@@ -39,9 +49,11 @@ type OrderInputPanel struct {
 	dropdownFiltered []string
 	dropdownIndex    int
 
-	// Side dropdown
+	// Side dropdown. The side defaults from the player's position on the
+	// selected ticker until they pick one.
 	sideOptions []string
 	sideIndex   int
+	sidePicked  bool
 
 	// Order type dropdown
 	typeOptions []string
@@ -53,9 +65,31 @@ type OrderInputPanel struct {
 	// Selected values
 	selectedTicker *market.Ticker
 
+	// Trading state and client-side queued order count per ticker, pushed
+	// by the model
+	states map[market.TickerID]marketview.StatusEvent
+	queued map[market.TickerID]int
+
+	// Player's net position per ticker, pushed by the model
+	positions map[market.TickerID]core.Size
+
+	// Set by the model while a submit waits for its result; the submit
+	// button is disabled meanwhile
+	submitting bool
+
+	// Why the last submit's price was rejected, until the price or ticker
+	// changes, and why its quantity was, until the quantity changes
+	priceErr string
+	qtyErr   string
+
+	// Recent text field edits, for Ctrl+Z
+	edits editHistory
+
 	focused bool
 	width   int
 	height  int
+
+	cache renderCache
 }
 
 // NewOrderInputPanel creates a new order input panel.
@@ -82,16 +116,25 @@ func NewOrderInputPanel(tickers []market.Ticker) *OrderInputPanel {
 	quantityInput.Width = 10
 	quantityInput.CharLimit = 15
 
+	orderIDInput := textinput.New()
+	orderIDInput.Placeholder = "A-1 or raw ID"
+	orderIDInput.Width = 20
+	orderIDInput.CharLimit = 20
+
 	return &OrderInputPanel{
 		tickers:          tickers,
 		tickerInput:      tickerInput,
 		priceInput:       priceInput,
 		quantityInput:    quantityInput,
+		orderIDInput:     orderIDInput,
 		dropdownItems:    tickerNames,
 		dropdownFiltered: tickerNames,
 		sideOptions:      []string{"BUY", "SELL"},
-		typeOptions:      []string{"LIMIT", "MARKET"},
+		typeOptions:      []string{"LIMIT", "MARKET", "CANCEL"},
 		currentField:     FieldTicker,
+		states:           make(map[market.TickerID]marketview.StatusEvent),
+		queued:           make(map[market.TickerID]int),
+		positions:        make(map[market.TickerID]core.Size),
 	}
 }
 
@@ -101,11 +144,25 @@ func (p *OrderInputPanel) Init() tea.Cmd {
 }
 
 // Update handles messages for the panel.
-func (p *OrderInputPanel) Update(msg tea.Msg) (*OrderInputPanel, tea.Cmd) {
+func (p *OrderInputPanel) Update(msg tea.Msg) (Panel, tea.Cmd) {
 	if !p.focused {
 		return p, nil
 	}
 
+	// Keys and cursor blinks always redraw; anything else only if it changed
+	// an input (e.g. a paste).
+	switch msg.(type) {
+	case tea.KeyMsg, cursor.BlinkMsg:
+		p.cache.invalidate()
+	default:
+		before := p.inputValues()
+		defer func() {
+			if p.inputValues() != before {
+				p.cache.invalidate()
+			}
+		}()
+	}
+
 	var cmd tea.Cmd
 
 	switch msg := msg.(type) {
@@ -124,6 +181,9 @@ func (p *OrderInputPanel) Update(msg tea.Msg) (*OrderInputPanel, tea.Cmd) {
 		// Enter to submit or select dropdown
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 			if p.currentField == FieldSubmit {
+				if p.submitting && p.typeIndex != typeCancel {
+					return p, nil
+				}
 				return p, p.submitOrder()
 			}
 			if p.showDropdown && p.currentField == FieldTicker {
@@ -135,6 +195,19 @@ func (p *OrderInputPanel) Update(msg tea.Msg) (*OrderInputPanel, tea.Cmd) {
 			p.nextField()
 			return p, nil
 
+		// Discard orders queued for the selected ticker's open
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+d"))):
+			if p.selectedTicker == nil || p.queued[p.selectedTicker.TickerID()] == 0 {
+				return p, nil
+			}
+			tid := p.selectedTicker.TickerID()
+			return p, func() tea.Msg { return DiscardQueuedMsg{Ticker: tid} }
+
+		// Undo the focused field's last edit
+		case key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+z"))):
+			p.undoEdit()
+			return p, nil
+
 		// Escape to close dropdown
 		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
 			p.showDropdown = false
@@ -152,6 +225,7 @@ func (p *OrderInputPanel) Update(msg tea.Msg) (*OrderInputPanel, tea.Cmd) {
 				if p.sideIndex > 0 {
 					p.sideIndex--
 				}
+				p.sidePicked = true
 				return p, nil
 			}
 			if p.currentField == FieldType {
@@ -172,6 +246,7 @@ func (p *OrderInputPanel) Update(msg tea.Msg) (*OrderInputPanel, tea.Cmd) {
 				if p.sideIndex < len(p.sideOptions)-1 {
 					p.sideIndex++
 				}
+				p.sidePicked = true
 				return p, nil
 			}
 			if p.currentField == FieldType {
@@ -184,26 +259,88 @@ func (p *OrderInputPanel) Update(msg tea.Msg) (*OrderInputPanel, tea.Cmd) {
 	}
 
 	// Update the appropriate text input
-	switch p.currentField {
+	field := p.currentField
+	input := p.fieldInput(field)
+	if input == nil {
+		return p, nil
+	}
+	before := input.Value()
+	*input, cmd = input.Update(msg)
+	if input.Value() == before {
+		return p, cmd
+	}
+	km, ok := msg.(tea.KeyMsg)
+	p.edits.record(field, before, ok && km.Type == tea.KeyRunes && !km.Paste)
+	p.fieldEdited(field)
+	return p, cmd
+}
+
+// fieldInput returns the text input behind a field, or nil for the side,
+// type and submit fields.
+func (p *OrderInputPanel) fieldInput(field OrderInputField) *textinput.Model {
+	switch field {
+	case FieldTicker:
+		return &p.tickerInput
+	case FieldPrice:
+		return &p.priceInput
+	case FieldQuantity:
+		return &p.quantityInput
+	case FieldOrderID:
+		return &p.orderIDInput
+	}
+	return nil
+}
+
+// fieldEdited updates what depends on a text field after its value changed.
+func (p *OrderInputPanel) fieldEdited(field OrderInputField) {
+	switch field {
 	case FieldTicker:
-		p.tickerInput, cmd = p.tickerInput.Update(msg)
 		p.filterDropdown(p.tickerInput.Value())
 		p.showDropdown = len(p.tickerInput.Value()) > 0
-
 	case FieldPrice:
-		p.priceInput, cmd = p.priceInput.Update(msg)
-
+		p.priceErr = ""
 	case FieldQuantity:
-		p.quantityInput, cmd = p.quantityInput.Update(msg)
+		p.qtyErr = ""
 	}
+}
 
-	return p, cmd
+// undoEdit restores the focused field's value from before its last edit.
+func (p *OrderInputPanel) undoEdit() {
+	input := p.fieldInput(p.currentField)
+	if input == nil {
+		return
+	}
+	before, ok := p.edits.undo(p.currentField)
+	if !ok {
+		return
+	}
+	input.SetValue(before)
+	input.CursorEnd()
+	p.fieldEdited(p.currentField)
 }
 
-// View renders the panel.
+// View renders the panel, reusing the last render if nothing changed.
 func (p *OrderInputPanel) View() string {
+	if out, ok := p.cache.get(p.width, p.height); ok {
+		return out
+	}
+	return p.cache.put(p.width, p.height, p.render())
+}
+
+// Version returns the panel's content version.
+func (p *OrderInputPanel) Version() uint64 {
+	return p.cache.version
+}
+
+func (p *OrderInputPanel) render() string {
 	var content strings.Builder
 
+	// Trading state banner for the selected ticker
+	if banner := p.renderBanner(); banner != "" {
+		content.WriteString(banner)
+		content.WriteString("\n")
+	}
+
 	// Ticker field with dropdown
 	content.WriteString(p.renderField("Ticker\n", FieldTicker, p.renderTickerField()))
 	content.WriteString("\n")
@@ -216,22 +353,46 @@ func (p *OrderInputPanel) View() string {
 	content.WriteString(p.renderField("Type", FieldType, p.renderTypeField()))
 	content.WriteString("\n")
 
-	// Price field (only show for limit orders)
-	if p.typeIndex == 0 { // LIMIT
-		content.WriteString(p.renderField("Price", FieldPrice, p.priceInput.View()))
+	if p.typeIndex == typeCancel {
+		// Order ID field (cancel mode)
+		content.WriteString(p.renderField("ID", FieldOrderID, p.orderIDInput.View()))
+		content.WriteString("\n\n")
+	} else {
+		// Price field (only show for limit orders)
+		if p.typeIndex == 0 { // LIMIT
+			content.WriteString(p.renderField("Price", FieldPrice, p.priceInput.View()))
+			content.WriteString("\n")
+			if p.priceErr != "" {
+				content.WriteString(styles.InputErrorStyle.Render("⚠ " + p.priceErr))
+				content.WriteString("\n")
+			}
+		}
+
+		// Quantity field
+		content.WriteString(p.renderField("Qty", FieldQuantity, p.quantityInput.View()))
+		content.WriteString("\n")
+		if p.qtyErr != "" {
+			content.WriteString(styles.InputErrorStyle.Render("⚠ " + p.qtyErr))
+			content.WriteString("\n")
+		}
 		content.WriteString("\n")
 	}
 
-	// Quantity field
-	content.WriteString(p.renderField("Qty", FieldQuantity, p.quantityInput.View()))
-	content.WriteString("\n\n")
-
 	// Submit button
 	submitStyle := styles.InputStyle
 	if p.currentField == FieldSubmit && p.focused {
 		submitStyle = styles.FocusedInputStyle.Bold(true).Foreground(styles.PrimaryColor)
 	}
-	content.WriteString(submitStyle.Render("  [Submit Order]  "))
+	submitLabel := "  [Submit Order]  "
+	if p.typeIndex == typeCancel {
+		submitLabel = "  [Cancel Order]  "
+	} else if p.submitting {
+		submitLabel = "  [Submitting…]  "
+		submitStyle = styles.PlaceholderStyle
+	} else if _, blocked := p.blocked(); blocked {
+		submitLabel = "  [Queue for Open]  "
+	}
+	content.WriteString(submitStyle.Render(submitLabel))
 
 	// Order summary
 	content.WriteString("\n\n")
@@ -243,12 +404,67 @@ func (p *OrderInputPanel) View() string {
 		panelStyle = styles.FocusedPanelStyle
 	}
 
-	title := styles.RenderTitle("📝 Order Entry", p.focused)
+	title := styles.RenderTitle(p.Title(), p.focused)
 	panel := lipgloss.JoinVertical(lipgloss.Left, title, content.String())
 
 	return panelStyle.Width(p.width - 2).Height(p.height - 2).Render(panel)
 }
 
+// renderBanner describes the selected ticker's trading state if it is not
+// open, and its queued orders. It is empty while the ticker trades normally.
+func (p *OrderInputPanel) renderBanner() string {
+	if p.selectedTicker == nil {
+		return ""
+	}
+	tid := p.selectedTicker.TickerID()
+	st := p.states[tid]
+
+	var lines []string
+	if st.Status != market.StatusOpen {
+		line := fmt.Sprintf("%s %s", statusIcon(st.Status), st.Status)
+		if st.Reason != "" {
+			line += ": " + st.Reason
+		}
+		if st.ResumeAt > 0 {
+			line += " · resumes ~" + time.Unix(0, st.ResumeAt).Format("15:04:05")
+		}
+		lines = append(lines, styles.BannerStyle.Render(line))
+		if reason, blocked := p.blocked(); blocked {
+			lines = append(lines, styles.LabelStyle.Render(reason+"; submit queues it for the open"))
+		}
+	}
+	if n := p.queued[tid]; n > 0 {
+		lines = append(lines, styles.LabelStyle.Render(fmt.Sprintf("⏸ %d queued for open · ctrl+d discard", n)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func statusIcon(s market.TradingStatus) string {
+	if s == market.StatusPreOpen {
+		return "⏳"
+	}
+	return "⛔"
+}
+
+// blocked reports whether the selected ticker's trading state rejects the
+// order type being entered, and why. Cancels are always allowed.
+func (p *OrderInputPanel) blocked() (string, bool) {
+	if p.selectedTicker == nil || p.typeIndex == typeCancel {
+		return "", false
+	}
+	switch p.states[p.selectedTicker.TickerID()].Status {
+	case market.StatusHalted:
+		return "New orders are rejected while halted", true
+	case market.StatusAuction:
+		return "New orders are rejected during the auction", true
+	case market.StatusPreOpen:
+		if p.typeIndex == 1 { // MARKET
+			return "Market orders are rejected in pre-open", true
+		}
+	}
+	return "", false
+}
+
 func (p *OrderInputPanel) renderField(label string, field OrderInputField, inputView string) string {
 	labelStyle := styles.LabelStyle
 	if p.currentField == field && p.focused {
@@ -340,6 +556,14 @@ func (p *OrderInputPanel) renderTypeField() string {
 }
 
 func (p *OrderInputPanel) renderOrderSummary() string {
+	if p.typeIndex == typeCancel {
+		id := p.orderIDInput.Value()
+		if id == "" {
+			id = "---"
+		}
+		return styles.HeaderStyle.Render("Order: ") + "CANCEL " + id
+	}
+
 	var parts []string
 
 	// Ticker
@@ -419,15 +643,43 @@ func (p *OrderInputPanel) selectDropdownItem() {
 		p.tickerInput.SetValue(selected)
 
 		// Find and set the actual ticker
-		for i, t := range p.tickers {
+		for _, t := range p.tickers {
 			if t.Name == selected {
-				p.selectedTicker = &p.tickers[i]
+				p.selectTicker(t)
 				break
 			}
 		}
 	}
 }
 
+// selectTicker makes a ticker the selected one. Moving to another ticker
+// drops the player's side choice in favor of the position default.
+func (p *OrderInputPanel) selectTicker(ticker market.Ticker) {
+	if p.selectedTicker == nil || p.selectedTicker.TickerID() != ticker.TickerID() {
+		p.sidePicked = false
+	}
+	p.selectedTicker = &ticker
+	p.priceErr = ""
+	p.applyDefaultSide()
+}
+
+// applyDefaultSide sets the side to SELL when the player is long the
+// selected ticker, so the default order reduces risk, and to BUY otherwise,
+// unless they picked a side.
+func (p *OrderInputPanel) applyDefaultSide() {
+	if p.sidePicked || p.selectedTicker == nil {
+		return
+	}
+	side := 0
+	if p.positions[p.selectedTicker.TickerID()] > 0 {
+		side = 1
+	}
+	if side != p.sideIndex {
+		p.cache.invalidate()
+		p.sideIndex = side
+	}
+}
+
 func (p *OrderInputPanel) nextField() {
 	p.showDropdown = false
 	switch p.currentField {
@@ -438,10 +690,14 @@ func (p *OrderInputPanel) nextField() {
 	case FieldSide:
 		p.currentField = FieldType
 	case FieldType:
-		if p.typeIndex == 0 { // LIMIT
+		switch p.typeIndex {
+		case 0: // LIMIT
 			p.currentField = FieldPrice
 			p.priceInput.Focus()
-		} else {
+		case typeCancel:
+			p.currentField = FieldOrderID
+			p.orderIDInput.Focus()
+		default:
 			p.currentField = FieldQuantity
 			p.quantityInput.Focus()
 		}
@@ -452,6 +708,9 @@ func (p *OrderInputPanel) nextField() {
 	case FieldQuantity:
 		p.currentField = FieldSubmit
 		p.quantityInput.Blur()
+	case FieldOrderID:
+		p.currentField = FieldSubmit
+		p.orderIDInput.Blur()
 	case FieldSubmit:
 		p.currentField = FieldTicker
 		p.tickerInput.Focus()
@@ -480,21 +739,40 @@ func (p *OrderInputPanel) prevField() {
 			p.currentField = FieldType
 		}
 		p.quantityInput.Blur()
+	case FieldOrderID:
+		p.currentField = FieldType
+		p.orderIDInput.Blur()
 	case FieldSubmit:
-		p.currentField = FieldQuantity
-		p.quantityInput.Focus()
+		if p.typeIndex == typeCancel {
+			p.currentField = FieldOrderID
+			p.orderIDInput.Focus()
+		} else {
+			p.currentField = FieldQuantity
+			p.quantityInput.Focus()
+		}
 	}
 }
 
 func (p *OrderInputPanel) submitOrder() tea.Cmd {
+	if p.typeIndex == typeCancel {
+		id := strings.TrimSpace(p.orderIDInput.Value())
+		if id == "" {
+			return nil
+		}
+		return func() tea.Msg {
+			return CancelOrderMsg{ID: id}
+		}
+	}
+
 	// Validate inputs
 	if p.selectedTicker == nil {
 		return nil
 	}
 
-	qty, err := strconv.ParseInt(p.quantityInput.Value(), 10, 64)
-	if err != nil || qty <= 0 {
-		return nil
+	qty, err := p.parseQuantity()
+	if err != nil {
+		p.cache.invalidate()
+		p.qtyErr = err.Error()
 	}
 
 	side := core.SideBuy
@@ -509,26 +787,81 @@ func (p *OrderInputPanel) submitOrder() tea.Cmd {
 
 	var price int64
 	if orderKind == core.OrderKindLimit {
-		price, err = strconv.ParseInt(p.priceInput.Value(), 10, 64)
-		if err != nil || price <= 0 {
-			return nil
+		var perr error
+		if price, perr = p.parsePrice(); perr != nil {
+			p.cache.invalidate()
+			p.priceErr = perr.Error()
+			err = perr
 		}
 	}
+	if err != nil {
+		return nil
+	}
 
-	// Create and return submit command
-	return func() tea.Msg {
-		return OrderSubmitMsg{
-			Ticker:    *p.selectedTicker,
-			Side:      side,
-			OrderKind: orderKind,
-			Price:     core.PriceTicks(price),
-			Quantity:  core.Size(qty),
+	// Create and return submit command, or hold the order for the open if
+	// the ticker would reject it now
+	sub := OrderSubmitMsg{
+		Ticker:    *p.selectedTicker,
+		Side:      side,
+		OrderKind: orderKind,
+		Price:     core.PriceTicks(price),
+		Quantity:  core.Size(qty),
+	}
+	if _, blocked := p.blocked(); blocked {
+		return func() tea.Msg {
+			return QueueOrderMsg{Order: sub}
 		}
 	}
+	return func() tea.Msg {
+		return sub
+	}
+}
+
+// parsePrice reads the price field as a decimal price in the selected
+// ticker's decimals, so "175.00" is 17500 ticks at 2 decimals.
+func (p *OrderInputPanel) parsePrice() (int64, error) {
+	price, err := money.ParsePrice(p.priceInput.Value(), p.selectedTicker.Decimals)
+	if err != nil {
+		return 0, err
+	}
+	if price <= 0 {
+		return 0, fmt.Errorf("%q: price must be positive", strings.TrimSpace(p.priceInput.Value()))
+	}
+	return price, nil
+}
+
+// parseQuantity reads the quantity field as a positive whole number of
+// shares.
+func (p *OrderInputPanel) parseQuantity() (int64, error) {
+	v := strings.TrimSpace(p.quantityInput.Value())
+	if v == "" {
+		return 0, errors.New("quantity is empty")
+	}
+	qty, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q: quantity must be a whole number", v)
+	}
+	if qty <= 0 {
+		return 0, fmt.Errorf("%q: quantity must be positive", v)
+	}
+	return qty, nil
+}
+
+// Title returns the panel title.
+func (p *OrderInputPanel) Title() string {
+	return "📝 Order Entry"
+}
+
+// DefaultKey returns the key that focuses the panel.
+func (p *OrderInputPanel) DefaultKey() string {
+	return "f4"
 }
 
 // SetFocus sets the focus state of the panel.
 func (p *OrderInputPanel) SetFocus(focused bool) {
+	if focused != p.focused {
+		p.cache.invalidate()
+	}
 	p.focused = focused
 	if focused {
 		switch p.currentField {
@@ -538,11 +871,14 @@ func (p *OrderInputPanel) SetFocus(focused bool) {
 			p.priceInput.Focus()
 		case FieldQuantity:
 			p.quantityInput.Focus()
+		case FieldOrderID:
+			p.orderIDInput.Focus()
 		}
 	} else {
 		p.tickerInput.Blur()
 		p.priceInput.Blur()
 		p.quantityInput.Blur()
+		p.orderIDInput.Blur()
 	}
 }
 
@@ -552,20 +888,76 @@ func (p *OrderInputPanel) SetSize(width, height int) {
 	p.height = height
 }
 
+// inputValues returns the text of every input, for change detection.
+func (p *OrderInputPanel) inputValues() [4]string {
+	return [4]string{p.tickerInput.Value(), p.priceInput.Value(), p.quantityInput.Value(), p.orderIDInput.Value()}
+}
+
 // SetTicker pre-fills the ticker field.
 func (p *OrderInputPanel) SetTicker(ticker market.Ticker) {
+	p.cache.invalidate()
 	p.tickerInput.SetValue(ticker.Name)
-	p.selectedTicker = &ticker
+	p.selectTicker(ticker)
+}
+
+// SetPosition sets the player's net position on a ticker (long > 0), which
+// picks the default side.
+func (p *OrderInputPanel) SetPosition(tid market.TickerID, pos core.Size) {
+	if p.positions[tid] == pos {
+		return
+	}
+	p.positions[tid] = pos
+	p.applyDefaultSide()
+}
+
+// SetTradingState sets a ticker's trading state, as reported by
+// MarketService.GetTradingState.
+func (p *OrderInputPanel) SetTradingState(tid market.TickerID, st marketview.StatusEvent) {
+	if p.states[tid] == st {
+		return
+	}
+	p.cache.invalidate()
+	p.states[tid] = st
+}
+
+// SetSubmitting disables the submit button, showing "…", while a submit
+// waits for its result. Cancels stay available.
+func (p *OrderInputPanel) SetSubmitting(on bool) {
+	if p.submitting == on {
+		return
+	}
+	p.cache.invalidate()
+	p.submitting = on
+}
+
+// Submitting reports whether the submit button is disabled.
+func (p *OrderInputPanel) Submitting() bool {
+	return p.submitting
+}
+
+// SetQueued sets how many orders are held for a ticker's open.
+func (p *OrderInputPanel) SetQueued(tid market.TickerID, n int) {
+	if p.queued[tid] == n {
+		return
+	}
+	p.cache.invalidate()
+	p.queued[tid] = n
 }
 
 // Reset clears the input fields.
 func (p *OrderInputPanel) Reset() {
+	p.cache.invalidate()
 	p.tickerInput.SetValue("")
 	p.priceInput.SetValue("")
 	p.quantityInput.SetValue("")
+	p.orderIDInput.SetValue("")
+	p.edits.clear()
+	p.priceErr = ""
+	p.qtyErr = ""
 	p.selectedTicker = nil
 	p.currentField = FieldTicker
 	p.sideIndex = 0
+	p.sidePicked = false
 	p.typeIndex = 0
 	p.showDropdown = false
 }
@@ -578,3 +970,22 @@ type OrderSubmitMsg struct {
 	Price     core.PriceTicks
 	Quantity  core.Size
 }
+
+// QueueOrderMsg is sent instead of OrderSubmitMsg when the ticker's trading
+// state would reject the order, asking for it to be submitted when the
+// ticker opens.
+type QueueOrderMsg struct {
+	Order OrderSubmitMsg
+}
+
+// DiscardQueuedMsg asks for the orders queued for a ticker's open to be
+// dropped.
+type DiscardQueuedMsg struct {
+	Ticker market.TickerID
+}
+
+// CancelOrderMsg is sent when a cancel is submitted. ID is a display ID
+// (e.g. "A-1042") or a raw OrderID.
+type CancelOrderMsg struct {
+	ID string
+}
@@ -0,0 +1,302 @@
+package panels
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+func TestOrderInputBlocksOrdersByTradingState(t *testing.T) {
+	aapl := market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}
+	p := NewOrderInputPanel([]market.Ticker{aapl})
+	p.SetSize(60, 24)
+	p.SetFocus(true)
+	p.SetTicker(aapl)
+	p.priceInput.SetValue("100")
+	p.quantityInput.SetValue("5")
+
+	submit := func(typeIndex int) tea.Msg {
+		t.Helper()
+		p.typeIndex = typeIndex
+		p.currentField = FieldSubmit
+		_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			t.Fatal("expected a command from submit")
+		}
+		return cmd()
+	}
+	const limit, marketType = 0, 1
+
+	// Open: everything goes straight through, with no banner
+	if _, ok := submit(marketType).(OrderSubmitMsg); !ok {
+		t.Error("expected market orders to submit while open")
+	}
+	if out := p.View(); strings.Contains(out, "OPEN") || strings.Contains(out, "Queue for Open") {
+		t.Errorf("expected no banner while open:\n%s", out)
+	}
+
+	// Pre-open: limit orders rest, market orders are queued
+	p.SetTradingState(1, marketview.StatusEvent{Status: market.StatusPreOpen, Reason: "pre-open"})
+	if _, ok := submit(limit).(OrderSubmitMsg); !ok {
+		t.Error("expected limit orders to submit in pre-open")
+	}
+	msg, ok := submit(marketType).(QueueOrderMsg)
+	if !ok || msg.Order.Quantity != 5 || msg.Order.Ticker != aapl {
+		t.Errorf("expected the market order to be queued, got %#v", msg)
+	}
+	if out := p.View(); !strings.Contains(out, "PRE-OPEN: pre-open") || !strings.Contains(out, "Queue for Open") {
+		t.Errorf("expected a pre-open banner and queue action:\n%s", out)
+	}
+
+	// Halted: new orders are queued, cancels still go through
+	resume := time.Date(2026, 1, 2, 15, 4, 5, 0, time.Local)
+	p.SetTradingState(1, marketview.StatusEvent{Status: market.StatusHalted, Reason: "limit move +12.0%", ResumeAt: resume.UnixNano()})
+	if _, ok := submit(limit).(QueueOrderMsg); !ok {
+		t.Error("expected limit orders to be queued while halted")
+	}
+	p.orderIDInput.SetValue("A-1")
+	if _, ok := submit(typeCancel).(CancelOrderMsg); !ok {
+		t.Error("expected cancels to be allowed while halted")
+	}
+	p.typeIndex = limit
+	p.SetQueued(1, 2)
+	out := p.View()
+	for _, want := range []string{"HALTED: limit move +12.0% · resumes ~15:04:05", "2 queued for open"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the panel:\n%s", want, out)
+		}
+	}
+
+	// ctrl+d asks to discard the queue
+	_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
+	if cmd == nil {
+		t.Fatal("expected ctrl+d to discard")
+	}
+	if d, ok := cmd().(DiscardQueuedMsg); !ok || d.Ticker != 1 {
+		t.Errorf("unexpected discard message %#v", d)
+	}
+
+	// Reopening clears the banner
+	p.SetTradingState(1, marketview.StatusEvent{Status: market.StatusOpen, Reason: "cooldown elapsed"})
+	p.SetQueued(1, 0)
+	if _, ok := submit(limit).(OrderSubmitMsg); !ok {
+		t.Error("expected limit orders to submit once reopened")
+	}
+	if out := p.View(); strings.Contains(out, "HALTED") || strings.Contains(out, "queued") {
+		t.Errorf("expected the banner to clear:\n%s", out)
+	}
+}
+
+func TestOrderInputDefaultSideFollowsPosition(t *testing.T) {
+	aapl := market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}
+	googl := market.Ticker{ID: 2, Name: "GOOGL", Decimals: 2}
+	p := NewOrderInputPanel([]market.Ticker{aapl, googl})
+	p.SetSize(60, 24)
+	p.SetFocus(true)
+	side := func() string { return p.sideOptions[p.sideIndex] }
+
+	// Long AAPL defaults to SELL; flat GOOGL to BUY
+	p.SetPosition(1, 50)
+	p.SetTicker(aapl)
+	if side() != "SELL" {
+		t.Errorf("expected SELL while long, got %s", side())
+	}
+	p.SetTicker(googl)
+	if side() != "BUY" {
+		t.Errorf("expected BUY while flat, got %s", side())
+	}
+
+	// A side the player picks sticks through position updates
+	p.SetTicker(aapl)
+	p.currentField = FieldSide
+	p.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	p.SetPosition(1, 60)
+	if side() != "BUY" {
+		t.Errorf("expected the picked side kept, got %s", side())
+	}
+
+	// until the ticker changes or the form resets
+	p.SetTicker(googl)
+	p.SetPosition(2, 10)
+	if side() != "SELL" {
+		t.Errorf("expected the default to return on a new ticker, got %s", side())
+	}
+	p.Reset()
+	p.SetTicker(aapl)
+	p.SetPosition(1, 0)
+	if side() != "BUY" {
+		t.Errorf("expected BUY once flat, got %s", side())
+	}
+}
+
+func TestOrderInputParsesDecimalPrices(t *testing.T) {
+	aapl := market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}
+	btc := market.Ticker{ID: 2, Name: "BTC", Decimals: 4}
+	p := NewOrderInputPanel([]market.Ticker{aapl, btc})
+	p.SetSize(60, 24)
+	p.SetFocus(true)
+	p.quantityInput.SetValue("5")
+
+	submit := func(ticker market.Ticker, price string) tea.Msg {
+		t.Helper()
+		p.SetTicker(ticker)
+		p.priceInput.SetValue(price)
+		p.currentField = FieldSubmit
+		_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if cmd == nil {
+			return nil
+		}
+		return cmd()
+	}
+
+	for _, tt := range []struct {
+		ticker market.Ticker
+		price  string
+		want   core.PriceTicks
+	}{
+		{aapl, "175.00", 17500},
+		{aapl, "175", 17500},
+		{aapl, "175.5", 17550},
+		{aapl, ".05", 5},
+		{aapl, " 99.990 ", 9999},
+		{btc, "0.0001", 1},
+		{btc, "175.25", 1752500},
+	} {
+		msg, ok := submit(tt.ticker, tt.price).(OrderSubmitMsg)
+		if !ok || msg.Price != tt.want {
+			t.Errorf("%s %q: expected %d ticks, got %#v", tt.ticker.Name, tt.price, tt.want, msg)
+		}
+	}
+
+	// Rejected prices submit nothing and say why until the price changes
+	for _, tt := range []struct {
+		price, reason string
+	}{
+		{"175.005", `"175.005": more than 2 decimal places`},
+		{"1,50", "ambiguous"},
+		{"0", "must be positive"},
+		{"abc", "not a decimal number"},
+	} {
+		if msg := submit(aapl, tt.price); msg != nil {
+			t.Errorf("%q: expected no order, got %#v", tt.price, msg)
+		}
+		if out := p.View(); !strings.Contains(out, tt.reason) {
+			t.Errorf("%q: expected %q in the panel:\n%s", tt.price, tt.reason, out)
+		}
+	}
+	p.currentField = FieldPrice
+	p.priceInput.Focus()
+	p.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	if p.priceErr != "" {
+		t.Errorf("expected editing the price to clear the error, got %q", p.priceErr)
+	}
+}
+
+func TestOrderInputUndoRestoresFieldValue(t *testing.T) {
+	aapl := market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}
+	p := NewOrderInputPanel([]market.Ticker{aapl})
+	p.SetSize(60, 24)
+	p.SetFocus(true)
+	p.SetTicker(aapl)
+	focus := func(field OrderInputField) {
+		p.tickerInput.Blur()
+		p.priceInput.Blur()
+		p.quantityInput.Blur()
+		p.currentField = field
+		p.fieldInput(field).Focus()
+	}
+	focus(FieldPrice)
+	keys := func(msgs ...tea.KeyMsg) {
+		for _, msg := range msgs {
+			p.Update(msg)
+		}
+	}
+	typed := func(s string) tea.KeyMsg { return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)} }
+	backspace := tea.KeyMsg{Type: tea.KeyBackspace}
+	undo := tea.KeyMsg{Type: tea.KeyCtrlZ}
+
+	// Typing is one edit; each deletion is another
+	keys(typed("1"), typed("5"), typed("0"), backspace)
+	if got := p.priceInput.Value(); got != "15" {
+		t.Fatalf("expected 15 after typing, got %q", got)
+	}
+	keys(undo)
+	if got := p.priceInput.Value(); got != "150" {
+		t.Errorf("expected undo to restore 150, got %q", got)
+	}
+
+	// Undo only touches the focused field
+	focus(FieldQuantity)
+	keys(typed("7"))
+	focus(FieldPrice)
+	keys(undo)
+	if p.priceInput.Value() != "" || p.quantityInput.Value() != "7" {
+		t.Errorf("expected the price undone to empty and the quantity kept, got %q and %q", p.priceInput.Value(), p.quantityInput.Value())
+	}
+	keys(undo) // nothing left for the price
+	if p.priceInput.Value() != "" {
+		t.Errorf("expected no further undo, got %q", p.priceInput.Value())
+	}
+
+	// The history is bounded
+	for range editHistorySize + 5 {
+		keys(typed("9"), backspace)
+	}
+	if n := len(p.edits.edits); n != editHistorySize {
+		t.Errorf("expected %d edits kept, got %d", editHistorySize, n)
+	}
+}
+
+func TestOrderInputRejectsNonPositiveQuantities(t *testing.T) {
+	aapl := market.Ticker{ID: 1, Name: "AAPL", Decimals: 2}
+	p := NewOrderInputPanel([]market.Ticker{aapl})
+	p.SetSize(60, 30)
+	p.SetFocus(true)
+	p.SetTicker(aapl)
+
+	submit := func(kind int, price, qty string) tea.Cmd {
+		t.Helper()
+		p.typeIndex = kind
+		p.priceInput.SetValue(price)
+		p.quantityInput.SetValue(qty)
+		p.currentField = FieldSubmit
+		_, cmd := p.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return cmd
+	}
+
+	for _, tt := range []struct {
+		name         string
+		kind         int
+		price, qty   string
+		reason, also string
+	}{
+		{"empty quantity", 0, "150", "", "quantity is empty", ""},
+		{"zero quantity", 0, "150", "0", `"0": quantity must be positive`, ""},
+		{"negative quantity", 1, "", "-5", `"-5": quantity must be positive`, ""},
+		{"fractional quantity", 1, "", "1.5", "quantity must be a whole number", ""},
+		{"empty price", 0, "", "5", "price is empty", ""},
+		{"negative price", 0, "-1", "5", "must be positive", ""},
+		{"both", 0, "0", "0", "price must be positive", "quantity must be positive"},
+	} {
+		if cmd := submit(tt.kind, tt.price, tt.qty); cmd != nil {
+			t.Errorf("%s: expected no order, got %#v", tt.name, cmd())
+		}
+		out := p.View()
+		if !strings.Contains(out, tt.reason) || !strings.Contains(out, tt.also) {
+			t.Errorf("%s: expected %q and %q in the panel:\n%s", tt.name, tt.reason, tt.also, out)
+		}
+	}
+
+	// Editing the quantity clears its error
+	p.currentField = FieldQuantity
+	p.quantityInput.Focus()
+	p.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	if p.qtyErr != "" {
+		t.Errorf("expected editing the quantity to clear the error, got %q", p.qtyErr)
+	}
+}
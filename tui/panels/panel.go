@@ -0,0 +1,53 @@
+package panels
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// Panel is the lifecycle every TUI panel implements so the model can drive
+// it generically: initialization, message handling, rendering, focus and
+// sizing.
+//
+// To add a panel, implement this interface and register it with the model;
+// focus cycling, F-key assignment, size propagation and message routing are
+// handled by the registry.
+type Panel interface {
+	// Init returns the panel's initial command, if any.
+	Init() tea.Cmd
+	// Update handles a message routed to the panel.
+	Update(msg tea.Msg) (Panel, tea.Cmd)
+	// View renders the panel at its current size.
+	View() string
+	// SetFocus sets the focus state of the panel.
+	SetFocus(focused bool)
+	// SetSize sets the panel dimensions.
+	SetSize(width, height int)
+	// Title returns the panel title shown in its border.
+	Title() string
+	// DefaultKey returns the preferred key that focuses the panel (e.g. "f1").
+	// An empty or already-taken key lets the registry assign a free one.
+	DefaultKey() string
+}
+
+// Versioned is implemented by panels that cache their render. Version changes
+// whenever the panel's View output may have changed for the same size, so
+// callers can skip re-composing unchanged panels.
+type Versioned interface {
+	Version() uint64
+}
+
+var (
+	_ Panel = (*MarketOverviewPanel)(nil)
+	_ Panel = (*OrderbookPanel)(nil)
+	_ Panel = (*CandlestickPanel)(nil)
+	_ Panel = (*NewsPanel)(nil)
+	_ Panel = (*OrderInputPanel)(nil)
+	_ Panel = (*FillsPanel)(nil)
+	_ Panel = (*ObjectivesPanel)(nil)
+
+	_ Versioned = (*MarketOverviewPanel)(nil)
+	_ Versioned = (*OrderbookPanel)(nil)
+	_ Versioned = (*CandlestickPanel)(nil)
+	_ Versioned = (*NewsPanel)(nil)
+	_ Versioned = (*OrderInputPanel)(nil)
+	_ Versioned = (*FillsPanel)(nil)
+	_ Versioned = (*ObjectivesPanel)(nil)
+)
@@ -0,0 +1,88 @@
+package panels
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+// SizeUnit is how the panels show level sizes. The zero value is
+// SizeShares, the raw size.
+type SizeUnit uint8
+
+const (
+	// SizeShares shows the raw size, e.g. "250".
+	SizeShares SizeUnit = iota
+	// SizeLots shows the size in the ticker's lots, e.g. "2.5" for 250
+	// with a LotSize of 100.
+	SizeLots
+	// SizeNotional shows the size's value at the level's price, e.g.
+	// "37562.50" for 250 at 150.25.
+	SizeNotional
+)
+
+var sizeUnitNames = [...]string{
+	SizeShares:   "shares",
+	SizeLots:     "lots",
+	SizeNotional: "notional",
+}
+
+func (u SizeUnit) String() string {
+	if int(u) < len(sizeUnitNames) {
+		return sizeUnitNames[u]
+	}
+	return "unknown"
+}
+
+// Next returns the unit after u, cycling shares, lots, notional.
+func (u SizeUnit) Next() SizeUnit {
+	return (u + 1) % SizeUnit(len(sizeUnitNames))
+}
+
+// FormatSize renders a level's size in the unit. Lots keep up to two
+// decimals for odd lots; notional switches to M and B suffixes from a
+// million so it fits the size columns.
+func (u SizeUnit) FormatSize(t market.Ticker, price core.PriceTicks, size core.Size) string {
+	switch u {
+	case SizeLots:
+		lot := t.Lot()
+		if int64(size)%lot == 0 {
+			return strconv.FormatInt(int64(size)/lot, 10)
+		}
+		s := strconv.FormatFloat(float64(size)/float64(lot), 'f', 2, 64)
+		return strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	case SizeNotional:
+		v := float64(price) * float64(size) * t.CurrencyPerTick()
+		switch {
+		case v >= 1e9:
+			return fmt.Sprintf("%.2fB", v/1e9)
+		case v >= 1e6:
+			return fmt.Sprintf("%.2fM", v/1e6)
+		}
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return strconv.FormatInt(int64(size), 10)
+	}
+}
+
+// MarshalText encodes the unit by name.
+func (u SizeUnit) MarshalText() ([]byte, error) {
+	if int(u) >= len(sizeUnitNames) {
+		return nil, fmt.Errorf("panels: unknown size unit %d", u)
+	}
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText decodes a unit name.
+func (u *SizeUnit) UnmarshalText(b []byte) error {
+	for i, name := range sizeUnitNames {
+		if name == string(b) {
+			*u = SizeUnit(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("panels: unknown size unit %q", b)
+}
@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/money"
+	"github.com/zappabad/stockcraft/tui/notify"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+// Preferences are the display choices the player changes from the keyboard.
+// They are saved whenever they change, if the model has a preferences file.
+type Preferences struct {
+	// PriceDisplay is how the panels show prices.
+	PriceDisplay money.Display `json:"price_display"`
+	// SizeUnit is how the market and order book panels show level sizes.
+	SizeUnit panels.SizeUnit `json:"size_unit"`
+}
+
+// LoadPreferences reads preferences from a JSON file. A missing file yields
+// the zero Preferences.
+func LoadPreferences(path string) (Preferences, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Preferences{}, nil
+	}
+	if err != nil {
+		return Preferences{}, err
+	}
+	var p Preferences
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Preferences{}, fmt.Errorf("tui: %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Save writes the preferences to a JSON file.
+func (p Preferences) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// SetPreferences applies the preferences and saves later changes to path.
+// An empty path keeps changes for this session only.
+func (m *Model) SetPreferences(p Preferences, path string) {
+	m.prefsPath = path
+	m.SetPriceDisplay(p.PriceDisplay)
+	m.SetSizeUnit(p.SizeUnit)
+}
+
+// Preferences returns the current preferences.
+func (m *Model) Preferences() Preferences {
+	return m.prefs
+}
+
+// SetPriceDisplay sets how every panel shows prices.
+func (m *Model) SetPriceDisplay(d money.Display) {
+	m.prefs.PriceDisplay = d
+	m.marketPanel.SetPriceDisplay(d)
+	m.orderbookPanel.SetPriceDisplay(d)
+	m.chartPanel.SetPriceDisplay(d)
+	m.fillsPanel.SetPriceDisplay(d)
+}
+
+// SetSizeUnit sets how the market and order book panels show level sizes.
+func (m *Model) SetSizeUnit(u panels.SizeUnit) {
+	m.prefs.SizeUnit = u
+	m.marketPanel.SetSizeUnit(u)
+	m.orderbookPanel.SetSizeUnit(u)
+}
+
+// cyclePriceDisplay switches to the next price display mode and saves it.
+func (m *Model) cyclePriceDisplay() tea.Cmd {
+	m.SetPriceDisplay(m.prefs.PriceDisplay.Next())
+	return m.savePreferences("Prices shown as " + m.prefs.PriceDisplay.String())
+}
+
+// cycleSizeUnit switches to the next size unit and saves it.
+func (m *Model) cycleSizeUnit() tea.Cmd {
+	m.SetSizeUnit(m.prefs.SizeUnit.Next())
+	return m.savePreferences("Sizes shown as " + m.prefs.SizeUnit.String())
+}
+
+// savePreferences saves a changed preference, if the model has a file, and
+// reports the change.
+func (m *Model) savePreferences(changed string) tea.Cmd {
+	if m.prefsPath != "" {
+		if err := m.prefs.Save(m.prefsPath); err != nil {
+			return m.Notify(notify.CategorySystem, notify.SeverityError, "❌ Saving preferences failed: "+err.Error())
+		}
+	}
+	return m.Notify(notify.CategorySystem, notify.SeverityInfo, changed)
+}
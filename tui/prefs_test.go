@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/market"
+	marketview "github.com/zappabad/stockcraft/internal/market/view"
+	"github.com/zappabad/stockcraft/internal/money"
+	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+func TestPriceDisplayCyclesAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefs.json")
+	m := newTestModel(t)
+	m.SetPreferences(Preferences{}, path)
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.orderbookPanel.SetLevels([]orderbookview.Level{{Price: 15025, Size: 7}}, nil)
+	m.marketPanel.SetSnapshot(marketview.MarketSnapshot{ByTicker: map[market.TickerID]marketview.BestPrices{
+		1: {BidPrice: 15025, BidSize: 7, BidOK: true},
+	}})
+
+	// The same bid renders in each mode, in both panels
+	for _, want := range []struct {
+		display money.Display
+		shown   string
+		absent  string
+	}{
+		{money.DisplayDecimal, " 150.25", "15025"},
+		{money.DisplayTicks, "15025", "150.25"},
+		{money.DisplayCurrency, "$150.25", "15025"},
+	} {
+		if m.Preferences().PriceDisplay != want.display {
+			t.Fatalf("expected %v, got %v", want.display, m.Preferences().PriceDisplay)
+		}
+		for name, view := range map[string]string{"orderbook": m.orderbookPanel.View(), "market": m.marketPanel.View()} {
+			if !strings.Contains(view, want.shown) || strings.Contains(view, want.absent) {
+				t.Errorf("%v: expected the %s panel to show %q, got\n%s", want.display, name, want.shown, view)
+			}
+		}
+		m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	}
+
+	// The cycle wrapped to decimal; one more press is saved as ticks
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	prefs, err := LoadPreferences(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prefs.PriceDisplay != money.DisplayTicks || m.Preferences() != prefs {
+		t.Errorf("expected ticks saved, got %+v (model %+v)", prefs, m.Preferences())
+	}
+}
+
+func TestSizeUnitCyclesAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prefs.json")
+	m := newTestModel(t)
+	m.SetPreferences(Preferences{}, path)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	prefs, err := LoadPreferences(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prefs.SizeUnit != panels.SizeLots || m.Preferences() != prefs {
+		t.Errorf("expected lots saved, got %+v (model %+v)", prefs, m.Preferences())
+	}
+
+	// A reloaded model shows sizes in the saved unit
+	m = newTestModel(t)
+	m.SetPreferences(prefs, "")
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.marketPanel.SetSnapshot(marketview.MarketSnapshot{ByTicker: map[market.TickerID]marketview.BestPrices{
+		1: {BidPrice: 15025, BidSize: 7, BidOK: true},
+	}})
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if view := m.marketPanel.View(); !strings.Contains(view, " 1051.75 ") {
+		t.Errorf("expected the notional 1051.75 after lots, got\n%s", view)
+	}
+}
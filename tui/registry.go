@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+// maxFunctionKeys is the number of F-keys the registry hands out.
+const maxFunctionKeys = 12
+
+type panelEntry struct {
+	panel panels.Panel
+	key   string
+}
+
+// panelRegistry owns the ordered list of panels. Registration order is the
+// Tab focus order. Each panel is bound to its DefaultKey, or to the first
+// free F-key when that key is empty or already taken.
+type panelRegistry struct {
+	entries []panelEntry
+	focused int
+}
+
+// Register adds a panel and returns the key assigned to focus it.
+func (r *panelRegistry) Register(p panels.Panel) string {
+	key := p.DefaultKey()
+	if key == "" || r.keyTaken(key) {
+		key = ""
+		for i := 1; i <= maxFunctionKeys; i++ {
+			candidate := fmt.Sprintf("f%d", i)
+			if !r.keyTaken(candidate) {
+				key = candidate
+				break
+			}
+		}
+	}
+	r.entries = append(r.entries, panelEntry{panel: p, key: key})
+	return key
+}
+
+func (r *panelRegistry) keyTaken(key string) bool {
+	for _, e := range r.entries {
+		if e.key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of registered panels.
+func (r *panelRegistry) Len() int {
+	return len(r.entries)
+}
+
+// Focused returns the focused panel, or nil if none are registered.
+func (r *panelRegistry) Focused() panels.Panel {
+	if len(r.entries) == 0 {
+		return nil
+	}
+	return r.entries[r.focused].panel
+}
+
+// Focus moves focus to p if it is registered.
+func (r *panelRegistry) Focus(p panels.Panel) {
+	for i, e := range r.entries {
+		if e.panel == p {
+			r.focused = i
+			return
+		}
+	}
+}
+
+// FocusKey moves focus to the panel bound to key. It reports whether a
+// panel was bound to it.
+func (r *panelRegistry) FocusKey(key string) bool {
+	for i, e := range r.entries {
+		if e.key == key {
+			r.focused = i
+			return true
+		}
+	}
+	return false
+}
+
+// FocusNext cycles focus forward.
+func (r *panelRegistry) FocusNext() {
+	if len(r.entries) == 0 {
+		return
+	}
+	r.focused = (r.focused + 1) % len(r.entries)
+}
+
+// FocusPrev cycles focus backward.
+func (r *panelRegistry) FocusPrev() {
+	if len(r.entries) == 0 {
+		return
+	}
+	r.focused--
+	if r.focused < 0 {
+		r.focused = len(r.entries) - 1
+	}
+}
+
+// ApplyFocus pushes the current focus state into every panel.
+func (r *panelRegistry) ApplyFocus() {
+	for i, e := range r.entries {
+		e.panel.SetFocus(i == r.focused)
+	}
+}
+
+// Init batches the Init commands of every panel.
+func (r *panelRegistry) Init() []tea.Cmd {
+	cmds := make([]tea.Cmd, 0, len(r.entries))
+	for _, e := range r.entries {
+		cmds = append(cmds, e.panel.Init())
+	}
+	return cmds
+}
+
+// Update routes msg to the focused panel. Unfocused panels receive their
+// data through the model's Set* calls instead.
+func (r *panelRegistry) Update(msg tea.Msg) tea.Cmd {
+	if len(r.entries) == 0 {
+		return nil
+	}
+	p, cmd := r.entries[r.focused].panel.Update(msg)
+	r.entries[r.focused].panel = p
+	return cmd
+}
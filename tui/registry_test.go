@@ -0,0 +1,69 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+type stubPanel struct {
+	key     string
+	focused bool
+	updates int
+}
+
+func (p *stubPanel) Init() tea.Cmd                              { return nil }
+func (p *stubPanel) Update(msg tea.Msg) (panels.Panel, tea.Cmd) { p.updates++; return p, nil }
+func (p *stubPanel) View() string                               { return "" }
+func (p *stubPanel) SetFocus(focused bool)                      { p.focused = focused }
+func (p *stubPanel) SetSize(width, height int)                  {}
+func (p *stubPanel) Title() string                              { return "stub" }
+func (p *stubPanel) DefaultKey() string                         { return p.key }
+
+func TestRegistryKeyAssignment(t *testing.T) {
+	r := &panelRegistry{}
+	if got := r.Register(&stubPanel{key: "f2"}); got != "f2" {
+		t.Errorf("expected f2, got %q", got)
+	}
+	if got := r.Register(&stubPanel{key: "f2"}); got != "f1" {
+		t.Errorf("expected taken key to fall back to f1, got %q", got)
+	}
+	if got := r.Register(&stubPanel{}); got != "f3" {
+		t.Errorf("expected empty key to get f3, got %q", got)
+	}
+}
+
+func TestRegistryFocusAndRouting(t *testing.T) {
+	r := &panelRegistry{}
+	a, b, c := &stubPanel{key: "f1"}, &stubPanel{key: "f2"}, &stubPanel{key: "f3"}
+	r.Register(a)
+	r.Register(b)
+	r.Register(c)
+
+	r.Focus(c)
+	r.FocusNext()
+	if r.Focused() != panels.Panel(a) {
+		t.Error("expected focus to wrap forward to first panel")
+	}
+	r.FocusPrev()
+	if r.Focused() != panels.Panel(c) {
+		t.Error("expected focus to wrap backward to last panel")
+	}
+	if !r.FocusKey("f2") || r.Focused() != panels.Panel(b) {
+		t.Error("expected f2 to focus second panel")
+	}
+	if r.FocusKey("f9") {
+		t.Error("expected unbound key to be ignored")
+	}
+
+	r.ApplyFocus()
+	if a.focused || !b.focused || c.focused {
+		t.Error("expected only the focused panel to have focus set")
+	}
+
+	r.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if a.updates != 0 || b.updates != 1 || c.updates != 0 {
+		t.Error("expected messages to route to the focused panel only")
+	}
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+)
+
+const benchFrames = 1000
+
+func newBenchModel(b *testing.B) *Model {
+	b.Helper()
+	m := newTestModel(b)
+	ctx := context.Background()
+	for i := 0; i < 10; i++ {
+		m.marketService.SubmitLimit(ctx, 1, 1, core.SideBuy, core.PriceTicks(9990-i*5), core.Size(100+i))
+		m.marketService.SubmitLimit(ctx, 1, 2, core.SideSell, core.PriceTicks(10010+i*5), core.Size(100+i))
+	}
+	waitOrderCount(m, core.SideBuy, 10)
+	waitOrderCount(m, core.SideSell, 10)
+	m.Update(tea.WindowSizeMsg{Width: 160, Height: 48})
+	m.Update(tickMsg{})
+	m.View()
+	return m
+}
+
+// waitOrderCount spins until the book view has caught up with a submit.
+func waitOrderCount(m *Model, side core.Side, want int) {
+	for {
+		if n, _ := m.marketService.GetOrderCount(1, side); n >= want {
+			return
+		}
+		runtime.Gosched()
+	}
+}
+
+// frame runs one refresh tick and renders, like the running program does.
+func frame(m *Model) string {
+	m.Update(tickMsg{})
+	return m.View()
+}
+
+// BenchmarkViewStaticMarket renders frames of a market where nothing changes.
+func BenchmarkViewStaticMarket(b *testing.B) {
+	m := newBenchModel(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for f := 0; f < benchFrames; f++ {
+			frame(m)
+		}
+	}
+}
+
+// BenchmarkViewBusyMarket renders frames with an order joining a visible
+// level before each, so the orderbook changes every frame.
+func BenchmarkViewBusyMarket(b *testing.B) {
+	m := newBenchModel(b)
+	ctx := context.Background()
+	resting := map[core.Side]int{core.SideBuy: 10, core.SideSell: 10}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for f := 0; f < benchFrames; f++ {
+			side := core.SideBuy
+			price := core.PriceTicks(9990 - (f%10)*5)
+			if f%2 == 1 {
+				side = core.SideSell
+				price = core.PriceTicks(10010 + (f%10)*5)
+			}
+			m.marketService.SubmitLimit(ctx, 1, 3, side, price, 1)
+			resting[side]++
+			waitOrderCount(m, side, resting[side])
+			frame(m)
+		}
+	}
+}
@@ -1,8 +1,6 @@
 package styles
 
 import (
-	"fmt"
-
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -62,6 +60,15 @@ var (
 	SelectedRowStyle = lipgloss.NewStyle().
 				Foreground(TextColor).
 				Background(lipgloss.Color("#374151"))
+
+	// StaleRowStyle dims a ticker that has not updated recently.
+	StaleRowStyle = lipgloss.NewStyle().
+			Foreground(TextMutedColor)
+
+	// NotTradingRowStyle flags a ticker that is halted or in its opening
+	// session, so it is not mistaken for a quiet one.
+	NotTradingRowStyle = lipgloss.NewStyle().
+				Foreground(AccentColor)
 )
 
 // Text styles
@@ -75,6 +82,29 @@ var (
 			Bold(true).
 			Foreground(SellColor)
 
+	// Book levels holding the player's orders
+	MyOrderStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(AccentColor)
+
+	// Block trades on the tape, layered over the buy/sell style
+	BlockTradeStyle = lipgloss.NewStyle().
+			Underline(true).
+			Background(lipgloss.Color("#3F3A1D"))
+
+	// Tape trades well above and below the recent average size, layered
+	// over the buy/sell style
+	TapeLoudStyle = lipgloss.NewStyle().
+			Reverse(true)
+	TapeQuietStyle = lipgloss.NewStyle().
+			Faint(true)
+
+	// Order book levels that have left the book but still linger on the
+	// ladder
+	LevelFadingStyle = lipgloss.NewStyle().
+				Foreground(TextMutedColor).
+				Faint(true)
+
 	// Price styles
 	PriceStyle = lipgloss.NewStyle().
 			Foreground(TextColor)
@@ -117,6 +147,17 @@ var (
 	LabelStyle = lipgloss.NewStyle().
 			Foreground(TextSecondaryColor)
 
+	// InputErrorStyle explains why an input was rejected.
+	InputErrorStyle = lipgloss.NewStyle().
+			Foreground(SellColor)
+
+	// BannerStyle flags a ticker that is not trading normally.
+	BannerStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(PanelBackgroundColor).
+			Background(AccentColor).
+			Padding(0, 1)
+
 	PlaceholderStyle = lipgloss.NewStyle().
 				Foreground(TextMutedColor)
 
@@ -147,11 +188,26 @@ var (
 	CandleDownStyle = lipgloss.NewStyle().
 			Foreground(SellColor)
 
+	// CandleDojiStyle draws candles that closed at their open.
+	CandleDojiStyle = lipgloss.NewStyle().
+			Foreground(NeutralColor)
+
+	// CandleSyntheticStyle draws backfilled candles that did not trade.
+	CandleSyntheticStyle = lipgloss.NewStyle().
+				Foreground(TextMutedColor).
+				Faint(true)
+
 	ChartAxisStyle = lipgloss.NewStyle().
 			Foreground(TextMutedColor)
 
 	ChartLabelStyle = lipgloss.NewStyle().
 			Foreground(TextSecondaryColor)
+
+	SpreadMidStyle = lipgloss.NewStyle().
+			Foreground(AccentColor)
+
+	SpreadBandStyle = lipgloss.NewStyle().
+			Foreground(TextMutedColor)
 )
 
 // Status bar styles
@@ -169,6 +225,25 @@ var (
 				Foreground(TextSecondaryColor)
 )
 
+// Notification styles
+var (
+	ToastInfoStyle = lipgloss.NewStyle().
+			Foreground(TextColor).
+			Background(PrimaryColor).
+			Padding(0, 1)
+
+	ToastWarningStyle = lipgloss.NewStyle().
+				Foreground(PanelBackgroundColor).
+				Background(AccentColor).
+				Padding(0, 1)
+
+	ToastErrorStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(TextColor).
+			Background(SellColor).
+			Padding(0, 1)
+)
+
 // Helper function to render a title bar for a panel
 func RenderTitle(title string, focused bool) string {
 	style := TitleStyle
@@ -177,23 +252,3 @@ func RenderTitle(title string, focused bool) string {
 	}
 	return style.Render(title)
 }
-
-// Helper to format price with ticker decimals
-func FormatPrice(price int64, decimals int8) string {
-	if decimals <= 0 {
-		return fmt.Sprintf("%d", price)
-	}
-	// Simple decimal formatting
-	divisor := int64(1)
-	for i := int8(0); i < decimals; i++ {
-		divisor *= 10
-	}
-	whole := price / divisor
-	frac := price % divisor
-	if frac < 0 {
-		frac = -frac
-	}
-
-	formatStr := fmt.Sprintf("%%d.%%0%dd", decimals)
-	return fmt.Sprintf(formatStr, whole, frac)
-}
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"fmt"
+	"math"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	orderbookview "github.com/zappabad/stockcraft/internal/orderbook/view"
+	"github.com/zappabad/stockcraft/tui/notify"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+// defaultSweepWarnLevels is how many price levels an order must reach before
+// it is held for confirmation.
+const defaultSweepWarnLevels = 2
+
+// SetSweepWarningLevels sets how many price levels an order must reach on
+// arrival before it is held with a warning showing its estimated average
+// fill price. Submitting the same order again sends it. 0 turns the warning
+// off.
+func (m *Model) SetSweepWarningLevels(n int) {
+	m.sweepWarnLevels = n
+	m.pendingSweep = nil
+}
+
+// submitChecked submits an order, unless it would sweep the book: then it
+// warns and holds the order until the player submits it again unchanged.
+// Any other order in between drops the held one.
+func (m *Model) submitChecked(sub panels.OrderSubmitMsg) tea.Cmd {
+	if held := m.pendingSweep; held != nil {
+		m.pendingSweep = nil
+		if *held == sub {
+			return m.sendOrder(sub)
+		}
+	}
+
+	est, ok := m.sweepEstimate(sub)
+	if !ok {
+		return m.sendOrder(sub)
+	}
+	m.pendingSweep = &sub
+	d, prices := sub.Ticker.Decimals, m.prefs.PriceDisplay
+	return m.Notify(notify.CategoryRejection, notify.SeverityWarning, fmt.Sprintf("⚠ %s sweeps %d levels to %s, est. avg %s for %d · submit again to confirm",
+		describeOrder(sub, prices), est.Levels, prices.FormatPrice(int64(est.Worst), d), prices.FormatPrice(int64(math.Round(est.AvgPrice)), d), est.Filled))
+}
+
+// sweepEstimate returns the estimate for an order that would reach at least
+// the warning's number of levels.
+func (m *Model) sweepEstimate(sub panels.OrderSubmitMsg) (orderbookview.Estimate, bool) {
+	if m.sweepWarnLevels <= 0 {
+		return orderbookview.Estimate{}, false
+	}
+	tid := sub.Ticker.TickerID()
+	var est orderbookview.Estimate
+	var err error
+	if sub.OrderKind == core.OrderKindMarket {
+		est, err = m.marketService.EstimateMarket(tid, sub.Side, sub.Quantity)
+	} else {
+		est, err = m.marketService.EstimateLimit(tid, sub.Side, sub.Price, sub.Quantity)
+	}
+	if err != nil || est.Levels < m.sweepWarnLevels {
+		return orderbookview.Estimate{}, false
+	}
+	return est, true
+}
@@ -0,0 +1,105 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/zappabad/stockcraft/internal/market"
+	marketservice "github.com/zappabad/stockcraft/internal/market/service"
+	"github.com/zappabad/stockcraft/internal/money"
+	newsservice "github.com/zappabad/stockcraft/internal/news/service"
+	"github.com/zappabad/stockcraft/internal/orderbook/core"
+	"github.com/zappabad/stockcraft/tui/panels"
+)
+
+func TestSweepingOrderWarnsWithEstimatedAverage(t *testing.T) {
+	// Synchronous, so the estimates see each resting order at once
+	cfg := marketservice.DefaultConfig()
+	cfg.Synchronous = true
+	ms := marketservice.NewMarketService([]market.Ticker{{ID: 1, Name: "AAPL", Decimals: 2}}, cfg)
+	t.Cleanup(ms.Close)
+	ns := newsservice.NewNewsService(newsservice.DefaultConfig())
+	t.Cleanup(ns.Close)
+	m := NewModel(ms, ns, 1000)
+	aapl := m.tickers[0]
+	ctx := context.Background()
+	for _, price := range []core.PriceTicks{10000, 10100, 10200} {
+		if _, err := m.marketService.SubmitLimit(ctx, aapl.TickerID(), 1, core.SideSell, price, 10); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	lastNotice := func() string {
+		h := m.notifier.History()
+		if len(h) == 0 {
+			return ""
+		}
+		return h[len(h)-1].Text
+	}
+
+	// A passive limit submits straight away
+	passive := panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideBuy, OrderKind: core.OrderKindLimit, Price: 9900, Quantity: 5}
+	_, cmd := m.Update(passive)
+	if res, ok := cmd().(orderResultMsg); !ok || !strings.HasPrefix(res.message, "✓ Order placed") {
+		t.Fatalf("expected the passive order to be placed, got %+v", res)
+	} else {
+		m.Update(res)
+	}
+	if strings.Contains(lastNotice(), "sweeps") {
+		t.Errorf("passive order should not warn, got %q", lastNotice())
+	}
+
+	// 25 across three levels: (10 × 100.00 + 10 × 101.00 + 5 × 102.00) / 25
+	sweep := panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideBuy, OrderKind: core.OrderKindLimit, Price: 10200, Quantity: 25}
+	m.Update(sweep)
+	want := "⚠ BUY 25 AAPL @ 102.00 sweeps 3 levels to 102.00, est. avg 100.80 for 25 · submit again to confirm"
+	if got := lastNotice(); got != want {
+		t.Errorf("expected warning %q, got %q", want, got)
+	}
+	if n, _ := m.marketService.GetOrderCount(aapl.TickerID(), core.SideSell); n != 3 {
+		t.Fatalf("the warned order should be held, but %d asks remain", n)
+	}
+
+	// Submitting it again sends it
+	_, cmd = m.Update(sweep)
+	if res, ok := cmd().(orderResultMsg); !ok || res.message != "✓ Filled 25 @ 100.80" {
+		t.Errorf("expected the confirmed sweep to fill, got %+v", res)
+	} else {
+		m.Update(res)
+	}
+
+	// Market orders are estimated too, and the warning can be turned off
+	mkt := panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideBuy, OrderKind: core.OrderKindMarket, Quantity: 1}
+	if _, err := m.marketService.SubmitLimit(ctx, aapl.TickerID(), 1, core.SideSell, 10300, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mkt.Quantity = 6 // the 5 left at 102.00, then 103.00
+	m.Update(mkt)
+	if got := lastNotice(); !strings.Contains(got, "MARKET sweeps 2 levels to 103.00, est. avg 102.17 for 6") {
+		t.Errorf("expected a market sweep warning, got %q", got)
+	}
+	m.SetSweepWarningLevels(0)
+	_, cmd = m.Update(mkt)
+	if res, ok := cmd().(orderResultMsg); !ok || !strings.HasPrefix(res.message, "✓ Filled 6") {
+		t.Errorf("expected the unwarned order to fill, got %+v", res)
+	} else {
+		m.Update(res)
+	}
+
+	// The warning shows prices like the confirmation, in the display mode
+	m.SetSweepWarningLevels(2)
+	m.SetPriceDisplay(money.DisplayCurrency)
+	if _, err := m.marketService.SubmitLimit(ctx, aapl.TickerID(), 1, core.SideSell, 10400, 10); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sweep = panels.OrderSubmitMsg{Ticker: aapl, Side: core.SideBuy, OrderKind: core.OrderKindLimit, Price: 10400, Quantity: 12}
+	m.Update(sweep) // the 9 left at 103.00, then 104.00
+	want = "⚠ BUY 12 AAPL @ $104.00 sweeps 2 levels to $104.00, est. avg $103.25 for 12 · submit again to confirm"
+	if got := lastNotice(); got != want {
+		t.Errorf("expected warning %q, got %q", want, got)
+	}
+	_, cmd = m.Update(sweep)
+	if res, ok := cmd().(orderResultMsg); !ok || res.message != "✓ Filled 12 @ $103.25" {
+		t.Errorf("expected the confirmation in the same mode, got %+v", res)
+	}
+}